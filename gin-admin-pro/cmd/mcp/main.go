@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/service"
+)
+
+var env = flag.String("env", "dev", "环境: dev, test, prod")
+
+// MCP 服务 stdio 入口，由 MCP 客户端以子进程方式启动。
+// 凭证（登录令牌或 mcp.apiKeys 中的 API Key）通过环境变量 MCP_TOKEN 传入
+func main() {
+	flag.Parse()
+
+	// 标准输出只用于协议消息，其他输出重定向到标准错误
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	log.SetOutput(os.Stderr)
+
+	// 加载配置
+	var err error
+	if *env == "dev" || *env == "test" || *env == "prod" {
+		err = config.LoadWithEnv(*env)
+	} else {
+		err = config.Load("")
+	}
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	server, cleanup, err := service.NewStdioMCPServer()
+	if err != nil {
+		log.Fatalf("初始化MCP服务失败: %v", err)
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	credential := os.Getenv("MCP_TOKEN")
+	if _, err := server.Authenticate(ctx, credential); err != nil {
+		log.Printf("MCP认证失败，请通过环境变量 MCP_TOKEN 提供登录令牌或 API Key: %v", err)
+		return
+	}
+
+	// 每条消息重新认证，令牌过期或用户被禁用后退出
+	if err := server.ServeStdio(ctx, credential, os.Stdin, stdout); err != nil && ctx.Err() == nil {
+		log.Printf("MCP服务异常退出: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/service"
+)
+
+var (
+	action = flag.String("action", "reindex", "动作: reindex（全量重建索引）, sync（同步积压的变更）")
+	env    = flag.String("env", "dev", "环境: dev, test, prod")
+)
+
+// 全局搜索索引维护入口，首次启用搜索、修改分词器或索引数据异常时执行 reindex
+func main() {
+	flag.Parse()
+
+	// 加载配置
+	var err error
+	if *env == "dev" || *env == "test" || *env == "prod" {
+		err = config.LoadWithEnv(*env)
+	} else {
+		err = config.Load("")
+	}
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	syncer, cleanup, err := service.NewSearchSyncer()
+	if err != nil {
+		log.Fatalf("初始化搜索索引同步失败: %v", err)
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch *action {
+	case "reindex":
+		if err := syncer.Reindex(ctx); err != nil {
+			log.Fatalf("重建搜索索引失败: %v", err)
+		}
+		log.Printf("重建搜索索引完成，别名 %s", syncer.Alias())
+	case "sync":
+		total := 0
+		for {
+			n, err := syncer.ProcessOutbox(ctx)
+			if err != nil {
+				log.Fatalf("同步搜索索引失败: %v", err)
+			}
+			total += n
+			if n == 0 {
+				break
+			}
+		}
+		log.Printf("同步搜索索引完成，处理变更 %d 条", total)
+	default:
+		log.Fatalf("未知动作: %s", *action)
+	}
+}
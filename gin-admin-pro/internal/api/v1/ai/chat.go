@@ -0,0 +1,248 @@
+package ai
+
+import (
+	"context"
+	"io"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	aiservice "gin-admin-pro/internal/service/ai"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+	"gin-admin-pro/plugin/aiusage"
+	"gin-admin-pro/plugin/knowledge"
+	"gin-admin-pro/plugin/prompt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChatController AI 对话控制器
+type ChatController struct {
+	chatService *aiservice.ChatService
+}
+
+// NewChatController 创建 AI 对话控制器实例，aiService 为 nil 时接口返回服务不可用，
+// tools 为 nil 时不向模型提供工具，knowledgeService 为 nil 时不支持知识库问答，usageService 为 nil 时不检查部门预算，
+// promptService 为 nil 时不支持助手，attachmentService 为 nil 时不支持附件
+func NewChatController(aiService aiplugin.AIService, tools *aiservice.ToolService, knowledgeService *knowledge.Service, usageService *aiusage.Service, promptService *prompt.Service, attachmentService *aiattachment.Service) *ChatController {
+	chatService := aiservice.NewChatService(aiService)
+	chatService.SetTools(tools)
+	if knowledgeService != nil {
+		chatService.SetKnowledge(knowledgeService)
+	}
+	if usageService != nil {
+		chatService.SetBudget(usageService)
+	}
+	if promptService != nil {
+		chatService.SetAssistants(promptService)
+	}
+	if attachmentService != nil {
+		chatService.SetAttachments(attachmentService)
+	}
+	return &ChatController{chatService: chatService}
+}
+
+// Chat AI 对话
+// @Summary AI 对话
+// @Description 发送消息，conversationId 为空时创建新对话；stream=true 时以 Server-Sent Events 返回，
+// @Description 事件 message 为增量内容，tool 为执行的工具，done 为完整回复，error 为生成失败；
+// @Description 指定 knowledgeBaseIds 时依据知识库资料回答，引用在 citations 中返回；
+// @Description 部门当月 AI 费用超出预算时拒绝请求或在 budgetWarning 中提醒；
+// @Description 指定 assistantId 时使用助手的提示词、模型、工具和知识库，后续消息沿用对话的助手；
+// @Description attachmentIds 引用上传的附件，图片发送给模型识别，文档正文追加到消息中
+// @Tags AI
+// @Accept json
+// @Produce json,text/event-stream
+// @Param request body ai.ChatReq true "对话请求"
+// @Success 200 {object} response.Response{data=ai.ChatResp}
+// @Failure 400 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/ai/chat [post]
+func (ctrl *ChatController) Chat(c *gin.Context) {
+	var req aiservice.ChatReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if req.Stream {
+		ctrl.stream(c, &req)
+		return
+	}
+
+	resp, err := ctrl.chatService.Chat(c.Request.Context(), c.GetUint("userId"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// stream 以 SSE 推送回复，客户端断开时取消上游请求
+func (ctrl *ChatController) stream(c *gin.Context, req *aiservice.ChatReq) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	events, err := ctrl.chatService.ChatStream(ctx, c.GetUint("userId"), req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	writeEvents(c, ctx, events)
+}
+
+// writeEvents 以 SSE 推送事件，直到事件流关闭或 ctx 取消
+func writeEvents(c *gin.Context, ctx context.Context, events <-chan aiservice.StreamEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 缓冲
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Event, event.Data)
+			return true
+		}
+	})
+}
+
+// AttachmentUpload 上传对话附件
+// @Summary 上传对话附件
+// @Description 上传图片（png、jpeg、gif、webp）或文本文档（txt、md、csv、json、log、html），
+// @Description 返回的附件ID在对话请求的 attachmentIds 中引用，只能由上传者使用
+// @Tags AI
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "附件"
+// @Success 200 {object} response.Response{data=aiattachment.Attachment}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/attachment/upload [post]
+func (ctrl *ChatController) AttachmentUpload(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("file"))
+		return
+	}
+
+	attachment, err := ctrl.chatService.UploadAttachment(c.Request.Context(), c.GetUint("userId"), file)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, attachment)
+}
+
+// ConversationPage 获取对话列表
+// @Summary 获取对话列表
+// @Description 分页获取当前用户的对话，按更新时间倒序；指定 keyword 时按标题和消息内容搜索
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param keyword query string false "搜索关键字"
+// @Success 200 {object} response.Response{data=[]ai.Conversation}
+// @Router /api/v1/ai/conversation/page [get]
+func (ctrl *ChatController) ConversationPage(c *gin.Context) {
+	var req aiservice.ConversationPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	conversations, err := ctrl.chatService.ListConversations(c.Request.Context(), c.GetUint("userId"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, conversations)
+}
+
+// ConversationGet 获取对话详情
+// @Summary 获取对话详情
+// @Description 获取当前用户的对话及其消息
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Param id query string true "对话ID"
+// @Success 200 {object} response.Response{data=ai.Conversation}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/conversation/get [get]
+func (ctrl *ChatController) ConversationGet(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	conversation, err := ctrl.chatService.GetConversation(c.Request.Context(), c.GetUint("userId"), id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, conversation)
+}
+
+// ConversationMessages 分页获取对话消息
+// @Summary 分页获取对话消息
+// @Description 按发送顺序分页获取当前用户对话中的消息
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Param id query string true "对话ID"
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Success 200 {object} response.Response{data=[]ai.Message}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/conversation/messages [get]
+func (ctrl *ChatController) ConversationMessages(c *gin.Context) {
+	var req aiservice.MessagePageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	messages, err := ctrl.chatService.ListMessages(c.Request.Context(), c.GetUint("userId"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, messages)
+}
+
+// ConversationDelete 删除对话
+// @Summary 删除对话
+// @Description 删除当前用户的对话
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Param id query string true "对话ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/conversation/delete [delete]
+func (ctrl *ChatController) ConversationDelete(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.chatService.DeleteConversation(c.Request.Context(), c.GetUint("userId"), id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
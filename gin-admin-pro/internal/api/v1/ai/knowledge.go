@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"strconv"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	aiservice "gin-admin-pro/internal/service/ai"
+	"gin-admin-pro/plugin/knowledge"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KnowledgeController 知识库控制器
+type KnowledgeController struct {
+	knowledgeService *aiservice.KnowledgeService
+}
+
+// NewKnowledgeController 创建知识库控制器实例，knowledgeService 为 nil 时接口返回服务不可用
+func NewKnowledgeController(knowledgeService *knowledge.Service) *KnowledgeController {
+	return &KnowledgeController{
+		knowledgeService: aiservice.NewKnowledgeService(knowledgeService),
+	}
+}
+
+// Page 获取知识库分页列表
+// @Summary 获取知识库分页列表
+// @Description 分页查询知识库
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "知识库名称"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 503 {object} response.Response
+// @Router /api/v1/ai/knowledge/page [get]
+func (ctrl *KnowledgeController) Page(c *gin.Context) {
+	var req aiservice.KnowledgePageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.knowledgeService.GetPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Get 获取知识库详情
+// @Summary 获取知识库详情
+// @Description 根据ID获取知识库
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param id query int true "知识库ID"
+// @Success 200 {object} response.Response{data=knowledge.KnowledgeBase}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/knowledge/get [get]
+func (ctrl *KnowledgeController) Get(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	kb, err := ctrl.knowledgeService.Get(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, kb)
+}
+
+// Create 创建知识库
+// @Summary 创建知识库
+// @Description 创建知识库，使用当前配置的向量模型
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param request body ai.KnowledgeSaveReq true "知识库信息"
+// @Success 200 {object} response.Response{data=int}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/knowledge/create [post]
+func (ctrl *KnowledgeController) Create(c *gin.Context) {
+	var req aiservice.KnowledgeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.knowledgeService.Create(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, id)
+}
+
+// Update 更新知识库
+// @Summary 更新知识库
+// @Description 更新知识库名称、描述和状态，禁用后对话时不再检索
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param request body ai.KnowledgeSaveReq true "知识库信息"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/knowledge/update [put]
+func (ctrl *KnowledgeController) Update(c *gin.Context) {
+	var req aiservice.KnowledgeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if req.ID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.knowledgeService.Update(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除知识库
+// @Summary 删除知识库
+// @Description 删除知识库及其全部文档和索引
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param id query int true "知识库ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/knowledge/delete [delete]
+func (ctrl *KnowledgeController) Delete(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.knowledgeService.Delete(c.Request.Context(), id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DocumentPage 获取知识库文档分页列表
+// @Summary 获取知识库文档分页列表
+// @Description 分页查询知识库中的文档及索引状态
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param knowledgeBaseId query int true "知识库ID"
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "文档名称"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Router /api/v1/ai/knowledge/document/page [get]
+func (ctrl *KnowledgeController) DocumentPage(c *gin.Context) {
+	var req aiservice.KnowledgeDocumentPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.knowledgeService.GetDocumentPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// DocumentUpload 上传知识库文档
+// @Summary 上传知识库文档
+// @Description 上传文档到知识库，保存原文件后切片、向量化并建立索引；支持 txt、md、csv、json、log、html
+// @Tags AI知识库
+// @Accept multipart/form-data
+// @Produce json
+// @Param knowledgeBaseId formData int true "知识库ID"
+// @Param file formData file true "文档"
+// @Success 200 {object} response.Response{data=knowledge.Document}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/knowledge/document/upload [post]
+func (ctrl *KnowledgeController) DocumentUpload(c *gin.Context) {
+	knowledgeBaseID, err := strconv.ParseUint(c.PostForm("knowledgeBaseId"), 10, 64)
+	if err != nil || knowledgeBaseID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("knowledgeBaseId"))
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("file"))
+		return
+	}
+
+	doc, err := ctrl.knowledgeService.UploadDocument(c.Request.Context(), uint(knowledgeBaseID), file, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, doc)
+}
+
+// DocumentDelete 删除知识库文档
+// @Summary 删除知识库文档
+// @Description 删除文档及其索引和原文件
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param id query int true "文档ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/knowledge/document/delete [delete]
+func (ctrl *KnowledgeController) DocumentDelete(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.knowledgeService.DeleteDocument(c.Request.Context(), id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Search 知识库检索测试
+// @Summary 知识库检索测试
+// @Description 返回问题在知识库中命中的切片及相似度，用于调整文档和相似度阈值
+// @Tags AI知识库
+// @Accept json
+// @Produce json
+// @Param request body ai.KnowledgeSearchReq true "检索请求"
+// @Success 200 {object} response.Response{data=[]ai.Citation}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/knowledge/search [post]
+func (ctrl *KnowledgeController) Search(c *gin.Context) {
+	var req aiservice.KnowledgeSearchReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	citations, err := ctrl.knowledgeService.Search(c.Request.Context(), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, citations)
+}
+
+// queryID 读取查询参数中的 id，格式错误时返回参数错误
+func queryID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return 0, false
+	}
+	return uint(id), true
+}
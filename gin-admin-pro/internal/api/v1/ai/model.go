@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+
+	"gin-admin-pro/internal/pkg/response"
+	aiservice "gin-admin-pro/internal/service/ai"
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModelController 模型管理控制器
+type ModelController struct {
+	modelService *aiservice.ModelService
+}
+
+// NewModelController 创建本地模型管理控制器实例，aiService 为 nil 时接口返回服务不可用
+func NewModelController(aiService aiplugin.AIService) *ModelController {
+	return &ModelController{
+		modelService: aiservice.NewModelService(aiService),
+	}
+}
+
+// List 获取本地模型列表
+// @Summary 获取本地模型列表
+// @Description 列出 Ollama 已下载的模型，其他提供商返回不支持
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]ai.LocalModel}
+// @Failure 503 {object} response.Response
+// @Router /api/v1/ai/model/list [get]
+func (ctrl *ModelController) List(c *gin.Context) {
+	models, err := ctrl.modelService.List(c.Request.Context())
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, models)
+}
+
+// Pull 下载模型
+// @Summary 下载模型
+// @Description 通过 Ollama 下载模型，以 Server-Sent Events 返回：事件 progress 为下载进度，
+// @Description done 为下载完成，error 为下载失败；客户端断开时中断下载，再次下载从断点继续
+// @Tags AI
+// @Accept json
+// @Produce text/event-stream
+// @Param request body ai.ModelPullReq true "下载请求"
+// @Success 200 {object} ai.ModelPullDone
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/model/pull [post]
+func (ctrl *ModelController) Pull(c *gin.Context) {
+	var req aiservice.ModelPullReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	events, err := ctrl.modelService.Pull(ctx, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	writeEvents(c, ctx, events)
+}
+
+// Health 获取提供商健康状态
+// @Summary 获取提供商健康状态
+// @Description 检查各 AI 提供商的连通性，配置多提供商路由时同时返回熔断状态（closed、open、half_open）
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]ai.ProviderHealth}
+// @Failure 503 {object} response.Response
+// @Router /api/v1/ai/model/health [get]
+func (ctrl *ModelController) Health(c *gin.Context) {
+	health, err := ctrl.modelService.Health(c.Request.Context())
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, health)
+}
@@ -0,0 +1,387 @@
+package ai
+
+import (
+	"strconv"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	aiservice "gin-admin-pro/internal/service/ai"
+	"gin-admin-pro/plugin/prompt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromptController 提示词模板和助手控制器
+type PromptController struct {
+	promptService *aiservice.PromptService
+}
+
+// NewPromptController 创建提示词控制器实例，promptService 为 nil 时接口返回服务不可用
+func NewPromptController(promptService *prompt.Service) *PromptController {
+	return &PromptController{
+		promptService: aiservice.NewPromptService(promptService),
+	}
+}
+
+// TemplatePage 获取提示词模板分页列表
+// @Summary 获取提示词模板分页列表
+// @Description 分页查询提示词模板
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "模板名称"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Router /api/v1/ai/prompt/template/page [get]
+func (ctrl *PromptController) TemplatePage(c *gin.Context) {
+	var req aiservice.PromptTemplatePageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.promptService.GetTemplatePage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// TemplateGet 获取提示词模板详情
+// @Summary 获取提示词模板详情
+// @Description 根据ID获取提示词模板及当前版本内容
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param id query int true "模板ID"
+// @Success 200 {object} response.Response{data=prompt.Template}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/template/get [get]
+func (ctrl *PromptController) TemplateGet(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	template, err := ctrl.promptService.GetTemplate(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// TemplateCreate 创建提示词模板
+// @Summary 创建提示词模板
+// @Description 创建提示词模板，内容作为第 1 个版本发布；内容中以 {{name}} 引用变量
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param request body ai.PromptTemplateCreateReq true "模板信息"
+// @Success 200 {object} response.Response{data=int}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/prompt/template/create [post]
+func (ctrl *PromptController) TemplateCreate(c *gin.Context) {
+	var req aiservice.PromptTemplateCreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.promptService.CreateTemplate(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, id)
+}
+
+// TemplateUpdate 更新提示词模板
+// @Summary 更新提示词模板
+// @Description 更新模板名称、描述和状态，内容通过发布新版本修改
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param request body ai.PromptTemplateUpdateReq true "模板信息"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/template/update [put]
+func (ctrl *PromptController) TemplateUpdate(c *gin.Context) {
+	var req aiservice.PromptTemplateUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.promptService.UpdateTemplate(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// TemplateDelete 删除提示词模板
+// @Summary 删除提示词模板
+// @Description 删除模板及其全部版本，被助手引用时不能删除
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param id query int true "模板ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/template/delete [delete]
+func (ctrl *PromptController) TemplateDelete(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.promptService.DeleteTemplate(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// VersionList 获取提示词模板版本列表
+// @Summary 获取提示词模板版本列表
+// @Description 获取模板的全部历史版本，按版本号倒序
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param templateId query int true "模板ID"
+// @Success 200 {object} response.Response{data=[]prompt.TemplateVersion}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/version/list [get]
+func (ctrl *PromptController) VersionList(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Query("templateId"), 10, 64)
+	if err != nil || templateID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("templateId"))
+		return
+	}
+
+	versions, err := ctrl.promptService.GetVersions(uint(templateID))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, versions)
+}
+
+// VersionPublish 发布提示词模板新版本
+// @Summary 发布提示词模板新版本
+// @Description 发布新版本并立即生效，跟随当前版本的助手随之更新
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param request body ai.PromptPublishReq true "版本内容"
+// @Success 200 {object} response.Response{data=prompt.TemplateVersion}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/version/publish [post]
+func (ctrl *PromptController) VersionPublish(c *gin.Context) {
+	var req aiservice.PromptPublishReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	version, err := ctrl.promptService.Publish(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, version)
+}
+
+// VersionActivate 切换提示词模板当前版本
+// @Summary 切换提示词模板当前版本
+// @Description 将模板当前版本切换为指定的历史版本，用于回滚
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param request body ai.PromptActivateReq true "版本"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/prompt/version/activate [post]
+func (ctrl *PromptController) VersionActivate(c *gin.Context) {
+	var req aiservice.PromptActivateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.promptService.Activate(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Preview 预览提示词渲染结果
+// @Summary 预览提示词渲染结果
+// @Description 按变量值渲染提示词内容，用于发布前检查
+// @Tags AI提示词
+// @Accept json
+// @Produce json
+// @Param request body ai.PromptPreviewReq true "提示词和变量值"
+// @Success 200 {object} response.Response{data=string}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/prompt/preview [post]
+func (ctrl *PromptController) Preview(c *gin.Context) {
+	var req aiservice.PromptPreviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	content, err := ctrl.promptService.Preview(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, content)
+}
+
+// AssistantPage 获取助手分页列表
+// @Summary 获取助手分页列表
+// @Description 分页查询助手，对话时通过 assistantId 使用
+// @Tags AI助手
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "助手名称"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Router /api/v1/ai/assistant/page [get]
+func (ctrl *PromptController) AssistantPage(c *gin.Context) {
+	var req aiservice.AssistantPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.promptService.GetAssistantPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// AssistantGet 获取助手详情
+// @Summary 获取助手详情
+// @Description 根据ID获取助手
+// @Tags AI助手
+// @Accept json
+// @Produce json
+// @Param id query int true "助手ID"
+// @Success 200 {object} response.Response{data=prompt.Assistant}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/assistant/get [get]
+func (ctrl *PromptController) AssistantGet(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	assistant, err := ctrl.promptService.GetAssistant(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, assistant)
+}
+
+// AssistantCreate 创建助手
+// @Summary 创建助手
+// @Description 组合提示词模板、模型、工具和知识库创建助手
+// @Tags AI助手
+// @Accept json
+// @Produce json
+// @Param request body ai.AssistantSaveReq true "助手信息"
+// @Success 200 {object} response.Response{data=int}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/assistant/create [post]
+func (ctrl *PromptController) AssistantCreate(c *gin.Context) {
+	var req aiservice.AssistantSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.promptService.CreateAssistant(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, id)
+}
+
+// AssistantUpdate 更新助手
+// @Summary 更新助手
+// @Description 更新助手设置，对已有对话的后续消息生效
+// @Tags AI助手
+// @Accept json
+// @Produce json
+// @Param request body ai.AssistantSaveReq true "助手信息"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/assistant/update [put]
+func (ctrl *PromptController) AssistantUpdate(c *gin.Context) {
+	var req aiservice.AssistantSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if req.ID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.promptService.UpdateAssistant(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// AssistantDelete 删除助手
+// @Summary 删除助手
+// @Description 删除助手，使用该助手的对话后续消息将无法发送
+// @Tags AI助手
+// @Accept json
+// @Produce json
+// @Param id query int true "助手ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/assistant/delete [delete]
+func (ctrl *PromptController) AssistantDelete(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.promptService.DeleteAssistant(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,159 @@
+package ai
+
+import (
+	"gin-admin-pro/internal/pkg/response"
+	aiservice "gin-admin-pro/internal/service/ai"
+	"gin-admin-pro/plugin/aiusage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UsageController AI 用量控制器
+type UsageController struct {
+	usageService *aiservice.UsageService
+}
+
+// NewUsageController 创建 AI 用量控制器实例，usageService 为 nil 时接口返回服务不可用
+func NewUsageController(db *gorm.DB, usageService *aiusage.Service) *UsageController {
+	return &UsageController{
+		usageService: aiservice.NewUsageService(db, usageService),
+	}
+}
+
+// Summary 获取用量汇总
+// @Summary 获取 AI 用量汇总
+// @Description 按天或按月汇总用户、部门或模型的调用次数、token 和费用（美元）
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Param groupBy query string true "汇总维度：user/dept/model"
+// @Param period query string false "汇总周期：day/month，默认 day"
+// @Param startDate query string false "开始日期 2006-01-02，默认近 30 天或近 12 个月"
+// @Param endDate query string false "结束日期 2006-01-02，默认今天"
+// @Param userId query int false "用户ID"
+// @Param deptId query int false "部门ID"
+// @Param model query string false "模型"
+// @Success 200 {object} response.Response{data=[]ai.UsageSummaryItem}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/ai/usage/summary [get]
+func (ctrl *UsageController) Summary(c *gin.Context) {
+	var req aiservice.UsageSummaryReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	items, err := ctrl.usageService.Summary(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// Page 获取用量记录分页列表
+// @Summary 获取 AI 用量记录分页列表
+// @Description 分页查询每次模型调用的用户、部门、模型、token、费用和耗时
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param startDate query string false "开始日期 2006-01-02"
+// @Param endDate query string false "结束日期 2006-01-02"
+// @Param userId query int false "用户ID"
+// @Param deptId query int false "部门ID"
+// @Param model query string false "模型"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Router /api/v1/ai/usage/page [get]
+func (ctrl *UsageController) Page(c *gin.Context) {
+	var req aiservice.UsageLogPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.usageService.GetLogPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// BudgetList 获取部门预算列表
+// @Summary 获取部门 AI 预算列表
+// @Description 获取全部部门月度预算及指定月份的已用费用
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Param month query string false "统计月份 2006-01，默认当月"
+// @Success 200 {object} response.Response{data=[]ai.BudgetItem}
+// @Router /api/v1/ai/usage/budget/list [get]
+func (ctrl *UsageController) BudgetList(c *gin.Context) {
+	var req aiservice.BudgetListReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	items, err := ctrl.usageService.GetBudgetList(c.Request.Context(), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// BudgetSave 设置部门预算
+// @Summary 设置部门 AI 预算
+// @Description 设置部门每月 AI 费用上限（美元），超出后拒绝部门成员的请求或仅提醒；部门已有预算时覆盖
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Param request body ai.BudgetSaveReq true "预算信息"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/usage/budget/save [post]
+func (ctrl *UsageController) BudgetSave(c *gin.Context) {
+	var req aiservice.BudgetSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.usageService.SaveBudget(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// BudgetDelete 删除部门预算
+// @Summary 删除部门 AI 预算
+// @Description 删除后部门不再受预算限制
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Param id query int true "预算ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/ai/usage/budget/delete [delete]
+func (ctrl *UsageController) BudgetDelete(c *gin.Context) {
+	id, ok := queryID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.usageService.DeleteBudget(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,200 @@
+package infra
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	configservice "gin-admin-pro/internal/service/infra"
+	"gin-admin-pro/plugin/sysconfig"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigController 参数配置控制器
+type ConfigController struct {
+	configService *configservice.ConfigService
+}
+
+// NewConfigController 创建参数配置控制器实例
+func NewConfigController(configService *sysconfig.Service) *ConfigController {
+	return &ConfigController{
+		configService: configservice.NewConfigService(configService),
+	}
+}
+
+// Page 获取参数配置分页列表
+// @Summary 获取参数配置分页列表
+// @Description 分页查询参数配置
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "参数名称"
+// @Param key query string false "参数键名"
+// @Param type query int false "参数类型：1-内置 2-自定义"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/page [get]
+func (ctrl *ConfigController) Page(c *gin.Context) {
+	var req configservice.ConfigPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.configService.GetPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Get 获取参数配置详情
+// @Summary 获取参数配置详情
+// @Description 根据ID获取参数配置
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param id query int true "参数配置ID"
+// @Success 200 {object} response.Response{data=sysconfig.SysConfig}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/get [get]
+func (ctrl *ConfigController) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	cfg, err := ctrl.configService.GetByID(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, cfg)
+}
+
+// GetValueByKey 根据键名获取参数值
+// @Summary 根据键名获取参数值
+// @Description 根据键名获取可见参数的值
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param key query string true "参数键名"
+// @Success 200 {object} response.Response{data=string}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/get-value-by-key [get]
+func (ctrl *ConfigController) GetValueByKey(c *gin.Context) {
+	key := c.Query("key")
+	if key == "" {
+		response.FailCode(c, errcode.ErrParam.WithParams("key"))
+		return
+	}
+
+	value, err := ctrl.configService.GetValueByKey(key)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, value)
+}
+
+// Create 创建参数配置
+// @Summary 创建参数配置
+// @Description 创建自定义参数配置
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param request body infra.ConfigSaveReq true "创建参数配置请求"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/create [post]
+func (ctrl *ConfigController) Create(c *gin.Context) {
+	var req configservice.ConfigSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.configService.Create(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// Update 更新参数配置
+// @Summary 更新参数配置
+// @Description 更新参数配置，修改后立即生效
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param request body infra.ConfigSaveReq true "更新参数配置请求"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/update [put]
+func (ctrl *ConfigController) Update(c *gin.Context) {
+	var req configservice.ConfigSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.configService.Update(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除参数配置
+// @Summary 删除参数配置
+// @Description 删除自定义参数配置，内置参数不可删除
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Param id query int true "参数配置ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/infra/config/delete [delete]
+func (ctrl *ConfigController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.configService.Delete(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RefreshCache 刷新参数缓存
+// @Summary 刷新参数缓存
+// @Description 清空参数缓存，下次读取时从数据库加载
+// @Tags 参数配置
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/v1/infra/config/refresh-cache [post]
+func (ctrl *ConfigController) RefreshCache(c *gin.Context) {
+	if err := ctrl.configService.RefreshCache(); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -101,7 +101,7 @@ func (ctrl *FileController) UploadMultiple(c *gin.Context) {
 func (ctrl *FileController) Delete(c *gin.Context) {
 	var req DeleteFileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -2,6 +2,7 @@ package system
 
 import (
 	"gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/response"
 	deptservice "gin-admin-pro/internal/service/system"
 	"strconv"
@@ -35,13 +36,13 @@ func NewDeptController(deptDAO *system.DeptDAO) *DeptController {
 func (ctrl *DeptController) List(c *gin.Context) {
 	var req system.DeptListReq
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	depts, err := ctrl.deptService.GetList(&req)
 	if err != nil {
-		response.Error(c, "获取部门列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -62,13 +63,13 @@ func (ctrl *DeptController) Get(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "部门ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	dept, err := ctrl.deptService.GetByID(uint(id))
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -88,7 +89,7 @@ func (ctrl *DeptController) Get(c *gin.Context) {
 func (ctrl *DeptController) Create(c *gin.Context) {
 	var req system.CreateDeptReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -97,7 +98,7 @@ func (ctrl *DeptController) Create(c *gin.Context) {
 
 	id, err := ctrl.deptService.Create(&req, userID)
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -119,7 +120,7 @@ func (ctrl *DeptController) Create(c *gin.Context) {
 func (ctrl *DeptController) Update(c *gin.Context) {
 	var req system.UpdateDeptReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -128,7 +129,7 @@ func (ctrl *DeptController) Update(c *gin.Context) {
 
 	err := ctrl.deptService.Update(&req, userID)
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -149,13 +150,13 @@ func (ctrl *DeptController) Delete(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "部门ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	err = ctrl.deptService.Delete(uint(id))
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -174,7 +175,7 @@ func (ctrl *DeptController) Delete(c *gin.Context) {
 func (ctrl *DeptController) ListAllSimple(c *gin.Context) {
 	depts, err := ctrl.deptService.GetAllSimpleList()
 	if err != nil {
-		response.Error(c, "获取部门列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -195,13 +196,13 @@ func (ctrl *DeptController) GetUsers(c *gin.Context) {
 	deptIdStr := c.Query("deptId")
 	deptID, err := strconv.ParseUint(deptIdStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "部门ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("deptId"))
 		return
 	}
 
 	users, err := ctrl.deptService.GetUsersByDept(uint(deptID))
 	if err != nil {
-		response.Error(c, "获取部门用户失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -0,0 +1,222 @@
+package system
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	dictservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/dict"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DictDataController 字典数据控制器
+type DictDataController struct {
+	dictService *dictservice.DictService
+}
+
+// NewDictDataController 创建字典数据控制器实例
+func NewDictDataController(dictService *dict.Service) *DictDataController {
+	return &DictDataController{
+		dictService: dictservice.NewDictService(dictService),
+	}
+}
+
+// Page 获取字典数据分页列表
+// @Summary 获取字典数据分页列表
+// @Description 分页查询字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param dictType query string false "字典类型"
+// @Param label query string false "字典标签"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/page [get]
+func (ctrl *DictDataController) Page(c *gin.Context) {
+	var req dictservice.DictDataPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.dictService.GetDataPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Get 获取字典数据详情
+// @Summary 获取字典数据详情
+// @Description 根据ID获取字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param id query int true "字典数据ID"
+// @Success 200 {object} response.Response{data=dict.DictData}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/get [get]
+func (ctrl *DictDataController) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	dictData, err := ctrl.dictService.GetData(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dictData)
+}
+
+// SimpleList 获取全部字典数据精简列表
+// @Summary 获取全部字典数据精简列表
+// @Description 前端启动时全量加载的字典数据，仅包含启用的类型和数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dict.DictDataSimple}
+// @Router /api/v1/system/dict-data/simple-list [get]
+func (ctrl *DictDataController) SimpleList(c *gin.Context) {
+	list, err := ctrl.dictService.GetDataSimpleList()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// ListByType 获取指定类型的字典数据
+// @Summary 获取指定类型的字典数据
+// @Description 根据字典类型获取启用的字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param type query string true "字典类型"
+// @Success 200 {object} response.Response{data=[]dict.DictData}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/type [get]
+func (ctrl *DictDataController) ListByType(c *gin.Context) {
+	dictType := c.Query("type")
+	if dictType == "" {
+		response.FailCode(c, errcode.ErrParam.WithParams("dictType"))
+		return
+	}
+
+	list, err := ctrl.dictService.GetDataByType(dictType)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Create 创建字典数据
+// @Summary 创建字典数据
+// @Description 创建新的字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param request body system.DictDataSaveReq true "创建字典数据请求"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/create [post]
+func (ctrl *DictDataController) Create(c *gin.Context) {
+	var req dictservice.DictDataSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.dictService.CreateData(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// Update 更新字典数据
+// @Summary 更新字典数据
+// @Description 更新字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param request body system.DictDataSaveReq true "更新字典数据请求"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/update [put]
+func (ctrl *DictDataController) Update(c *gin.Context) {
+	var req dictservice.DictDataSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if req.ID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.dictService.UpdateData(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除字典数据
+// @Summary 删除字典数据
+// @Description 删除字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param id query int true "字典数据ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-data/delete [delete]
+func (ctrl *DictDataController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.dictService.DeleteData(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RefreshCache 刷新字典缓存
+// @Summary 刷新字典缓存
+// @Description 清除并重新加载字典缓存
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/v1/system/dict-data/refresh-cache [post]
+func (ctrl *DictDataController) RefreshCache(c *gin.Context) {
+	if err := ctrl.dictService.RefreshCache(); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
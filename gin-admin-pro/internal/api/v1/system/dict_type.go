@@ -0,0 +1,179 @@
+package system
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	dictservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/dict"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DictTypeController 字典类型控制器
+type DictTypeController struct {
+	dictService *dictservice.DictService
+}
+
+// NewDictTypeController 创建字典类型控制器实例
+func NewDictTypeController(dictService *dict.Service) *DictTypeController {
+	return &DictTypeController{
+		dictService: dictservice.NewDictService(dictService),
+	}
+}
+
+// Page 获取字典类型分页列表
+// @Summary 获取字典类型分页列表
+// @Description 分页查询字典类型
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param name query string false "字典名称"
+// @Param type query string false "字典类型"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-type/page [get]
+func (ctrl *DictTypeController) Page(c *gin.Context) {
+	var req dictservice.DictTypePageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.dictService.GetTypePage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Get 获取字典类型详情
+// @Summary 获取字典类型详情
+// @Description 根据ID获取字典类型
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param id query int true "字典类型ID"
+// @Success 200 {object} response.Response{data=dict.DictType}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-type/get [get]
+func (ctrl *DictTypeController) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	dictType, err := ctrl.dictService.GetType(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dictType)
+}
+
+// ListAllSimple 获取字典类型精简列表
+// @Summary 获取字典类型精简列表
+// @Description 获取启用的字典类型，用于下拉选择
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dict.DictType}
+// @Router /api/v1/system/dict-type/list-all-simple [get]
+func (ctrl *DictTypeController) ListAllSimple(c *gin.Context) {
+	list, err := ctrl.dictService.GetTypeSimpleList()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Create 创建字典类型
+// @Summary 创建字典类型
+// @Description 创建新的字典类型
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param request body system.DictTypeSaveReq true "创建字典类型请求"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-type/create [post]
+func (ctrl *DictTypeController) Create(c *gin.Context) {
+	var req dictservice.DictTypeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.dictService.CreateType(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// Update 更新字典类型
+// @Summary 更新字典类型
+// @Description 更新字典类型，修改类型标识时同步更新字典数据
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param request body system.DictTypeSaveReq true "更新字典类型请求"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-type/update [put]
+func (ctrl *DictTypeController) Update(c *gin.Context) {
+	var req dictservice.DictTypeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if req.ID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.dictService.UpdateType(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除字典类型
+// @Summary 删除字典类型
+// @Description 删除字典类型，类型下存在字典数据时无法删除
+// @Tags 字典管理
+// @Accept json
+// @Produce json
+// @Param id query int true "字典类型ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/dict-type/delete [delete]
+func (ctrl *DictTypeController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.dictService.DeleteType(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
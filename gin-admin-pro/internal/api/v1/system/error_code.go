@@ -0,0 +1,240 @@
+package system
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	errorcodeservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/errorcode"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCodeController 错误码控制器
+type ErrorCodeController struct {
+	errorCodeService *errorcodeservice.ErrorCodeService
+}
+
+// NewErrorCodeController 创建错误码控制器实例
+func NewErrorCodeController(errorCodeService *errorcode.Service) *ErrorCodeController {
+	return &ErrorCodeController{
+		errorCodeService: errorcodeservice.NewErrorCodeService(errorCodeService),
+	}
+}
+
+// Page 获取错误码分页列表
+// @Summary 获取错误码分页列表
+// @Description 分页查询错误码
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param type query string false "错误类型"
+// @Param name query string false "错误名称"
+// @Param status query int false "状态：0-禁用 1-启用"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/error-code/page [get]
+func (ctrl *ErrorCodeController) Page(c *gin.Context) {
+	var req errorcodeservice.ErrorCodePageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.errorCodeService.GetPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Get 获取错误码详情
+// @Summary 获取错误码详情
+// @Description 根据ID获取错误码
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param id query int true "错误码ID"
+// @Success 200 {object} response.Response{data=errorcode.ErrorCode}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/system/error-code/get [get]
+func (ctrl *ErrorCodeController) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	errorCode, err := ctrl.errorCodeService.Get(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, errorCode)
+}
+
+// Types 获取错误类型选项
+// @Summary 获取错误类型选项
+// @Description 获取错误类型及支持的语言列表
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Router /api/v1/system/error-code/types [get]
+func (ctrl *ErrorCodeController) Types(c *gin.Context) {
+	response.Success(c, map[string]interface{}{
+		"types":   ctrl.errorCodeService.GetTypes(),
+		"locales": ctrl.errorCodeService.GetLocales(),
+	})
+}
+
+// Create 创建错误码
+// @Summary 创建错误码
+// @Description 创建新的错误码，message 为默认语言的消息模板
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param request body system.ErrorCodeSaveReq true "创建错误码请求"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/error-code/create [post]
+func (ctrl *ErrorCodeController) Create(c *gin.Context) {
+	var req errorcodeservice.ErrorCodeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	id, err := ctrl.errorCodeService.Create(&req, c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// Update 更新错误码
+// @Summary 更新错误码
+// @Description 更新错误码，错误码值变更时多语言消息随之迁移
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param request body system.ErrorCodeSaveReq true "更新错误码请求"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/error-code/update [put]
+func (ctrl *ErrorCodeController) Update(c *gin.Context) {
+	var req errorcodeservice.ErrorCodeSaveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if req.ID == 0 {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.errorCodeService.Update(&req, c.GetUint("userId")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除错误码
+// @Summary 删除错误码
+// @Description 删除错误码及其多语言消息
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param id query int true "错误码ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/system/error-code/delete [delete]
+func (ctrl *ErrorCodeController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
+		return
+	}
+
+	if err := ctrl.errorCodeService.Delete(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Messages 获取错误码的多语言消息
+// @Summary 获取错误码的多语言消息
+// @Description 获取错误码在非默认语言下的消息模板
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param code query int true "错误码"
+// @Success 200 {object} response.Response{data=[]errorcode.ErrorCodeMessage}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/error-code/messages [get]
+func (ctrl *ErrorCodeController) Messages(c *gin.Context) {
+	code, err := strconv.Atoi(c.Query("code"))
+	if err != nil {
+		response.FailCode(c, errcode.ErrParam.WithParams("code"))
+		return
+	}
+
+	messages, err := ctrl.errorCodeService.GetMessages(code)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, messages)
+}
+
+// SaveMessages 保存错误码的多语言消息
+// @Summary 保存错误码的多语言消息
+// @Description 按语言保存消息模板，消息为空时删除该语言
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Param request body system.ErrorCodeMessagesReq true "多语言消息"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/error-code/messages [put]
+func (ctrl *ErrorCodeController) SaveMessages(c *gin.Context) {
+	var req errorcodeservice.ErrorCodeMessagesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.errorCodeService.SaveMessages(&req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RefreshCache 刷新错误码消息缓存
+// @Summary 刷新错误码消息缓存
+// @Description 清除当前实例的错误码消息缓存，下次使用时重新加载
+// @Tags 错误码管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/v1/system/error-code/refresh-cache [post]
+func (ctrl *ErrorCodeController) RefreshCache(c *gin.Context) {
+	ctrl.errorCodeService.RefreshCache()
+	response.Success(c, nil)
+}
@@ -2,6 +2,7 @@ package system
 
 import (
 	"gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/response"
 	menuservice "gin-admin-pro/internal/service/system"
 	"strconv"
@@ -36,13 +37,13 @@ func NewMenuController(menuDAO *system.MenuDAO) *MenuController {
 func (ctrl *MenuController) List(c *gin.Context) {
 	var req system.MenuListReq
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	menus, err := ctrl.menuService.GetList(&req)
 	if err != nil {
-		response.Error(c, "获取菜单列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -63,13 +64,13 @@ func (ctrl *MenuController) Get(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "菜单ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	menu, err := ctrl.menuService.GetByID(uint(id))
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -89,7 +90,7 @@ func (ctrl *MenuController) Get(c *gin.Context) {
 func (ctrl *MenuController) Create(c *gin.Context) {
 	var req system.CreateMenuReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -98,7 +99,7 @@ func (ctrl *MenuController) Create(c *gin.Context) {
 
 	id, err := ctrl.menuService.Create(&req, userID)
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -120,7 +121,7 @@ func (ctrl *MenuController) Create(c *gin.Context) {
 func (ctrl *MenuController) Update(c *gin.Context) {
 	var req system.UpdateMenuReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -129,7 +130,7 @@ func (ctrl *MenuController) Update(c *gin.Context) {
 
 	err := ctrl.menuService.Update(&req, userID)
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -150,13 +151,13 @@ func (ctrl *MenuController) Delete(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "菜单ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	err = ctrl.menuService.Delete(uint(id))
 	if err != nil {
-		response.Error(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -175,7 +176,7 @@ func (ctrl *MenuController) Delete(c *gin.Context) {
 func (ctrl *MenuController) ListAllSimple(c *gin.Context) {
 	menus, err := ctrl.menuService.GetAllSimpleList()
 	if err != nil {
-		response.Error(c, "获取菜单列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -197,7 +198,7 @@ func (ctrl *MenuController) ListUserPermissions(c *gin.Context) {
 
 	menus, err := ctrl.menuService.GetUserMenus(userID)
 	if err != nil {
-		response.Error(c, "获取用户菜单权限失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/pkg/response"
+	recycleservice "gin-admin-pro/internal/service/system"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecycleBinController 回收站控制器
+type RecycleBinController struct {
+	recycleBinService *recycleservice.RecycleBinService
+}
+
+// NewRecycleBinController 创建回收站控制器实例
+func NewRecycleBinController(recycleBinDAO *system.RecycleBinDAO) *RecycleBinController {
+	return &RecycleBinController{
+		recycleBinService: recycleservice.NewRecycleBinService(recycleBinDAO, nil),
+	}
+}
+
+// Types 获取回收站类型列表
+// @Summary 获取回收站类型列表
+// @Description 获取支持回收站的业务类型
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]recyclebin.TypeOption}
+// @Router /api/v1/system/recycle-bin/types [get]
+func (ctrl *RecycleBinController) Types(c *gin.Context) {
+	response.Success(c, ctrl.recycleBinService.GetTypes())
+}
+
+// Page 获取回收站分页列表
+// @Summary 获取回收站分页列表
+// @Description 分页查询指定类型的已删除记录
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Param type query string true "类型：user/role/dept/menu"
+// @Param keyword query string false "关键字"
+// @Param pageNo query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Success 200 {object} response.Response{data=model.PageResp}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/recycle-bin/page [get]
+func (ctrl *RecycleBinController) Page(c *gin.Context) {
+	var req system.RecycleBinPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	page, err := ctrl.recycleBinService.GetPage(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// Restore 恢复已删除记录
+// @Summary 恢复已删除记录
+// @Description 恢复回收站中的记录，恢复前会检查依赖数据（如用户所属部门）是否存在
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Param request body system.RecycleBinReq true "恢复请求"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/recycle-bin/restore [put]
+func (ctrl *RecycleBinController) Restore(c *gin.Context) {
+	var req system.RecycleBinReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	if err := ctrl.recycleBinService.Restore(&req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Purge 彻底删除记录
+// @Summary 彻底删除记录
+// @Description 从回收站中彻底删除记录，删除后无法恢复
+// @Tags 回收站
+// @Accept json
+// @Produce json
+// @Param request body system.RecycleBinReq true "彻底删除请求"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/recycle-bin/purge [delete]
+func (ctrl *RecycleBinController) Purge(c *gin.Context) {
+	var req system.RecycleBinReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	count, err := ctrl.recycleBinService.Purge(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"count": count,
+	})
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"gin-admin-pro/internal/dao/system"
 	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/response"
 	roleservice "gin-admin-pro/internal/service/system"
 	"strconv"
@@ -40,13 +41,13 @@ func NewRoleController(roleDAO *system.RoleDAO) *RoleController {
 func (ctrl *RoleController) Page(c *gin.Context) {
 	var req system.RolePageReq
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	page, err := ctrl.roleService.GetPage(&req)
 	if err != nil {
-		response.Error(c, "查询失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -66,23 +67,19 @@ func (ctrl *RoleController) Page(c *gin.Context) {
 func (ctrl *RoleController) Get(c *gin.Context) {
 	idStr := c.Query("id")
 	if idStr == "" {
-		response.BadRequest(c, "角色ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "角色ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	role, err := ctrl.roleService.GetByID(uint(id))
 	if err != nil {
-		if err == roleservice.ErrRoleNotFound {
-			response.NotFound(c, "角色不存在")
-			return
-		}
-		response.Error(c, "查询失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -101,7 +98,7 @@ func (ctrl *RoleController) Get(c *gin.Context) {
 func (ctrl *RoleController) ListAllSimple(c *gin.Context) {
 	roles, err := ctrl.roleService.GetAllSimple()
 	if err != nil {
-		response.Error(c, "查询失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -121,7 +118,7 @@ func (ctrl *RoleController) ListAllSimple(c *gin.Context) {
 func (ctrl *RoleController) Create(c *gin.Context) {
 	var req system.RoleCreateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -139,22 +136,14 @@ func (ctrl *RoleController) Create(c *gin.Context) {
 	// 获取当前操作用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 	createBy := userID.(uint)
 
 	err := ctrl.roleService.Create(&req, createBy)
 	if err != nil {
-		if err == roleservice.ErrRoleCodeExists {
-			response.BadRequest(c, "角色代码已存在")
-			return
-		}
-		if err == roleservice.ErrInvalidDataScope {
-			response.BadRequest(c, "无效的数据权限范围")
-			return
-		}
-		response.Error(c, "创建失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -174,33 +163,21 @@ func (ctrl *RoleController) Create(c *gin.Context) {
 func (ctrl *RoleController) Update(c *gin.Context) {
 	var req system.RoleUpdateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	// 获取当前操作用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 	updateBy := userID.(uint)
 
 	err := ctrl.roleService.Update(&req, updateBy)
 	if err != nil {
-		if err == roleservice.ErrRoleNotFound {
-			response.NotFound(c, "角色不存在")
-			return
-		}
-		if err == roleservice.ErrRoleCodeExists {
-			response.BadRequest(c, "角色代码已存在")
-			return
-		}
-		if err == roleservice.ErrInvalidDataScope {
-			response.BadRequest(c, "无效的数据权限范围")
-			return
-		}
-		response.Error(c, "更新失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -220,31 +197,19 @@ func (ctrl *RoleController) Update(c *gin.Context) {
 func (ctrl *RoleController) Delete(c *gin.Context) {
 	idStr := c.Query("id")
 	if idStr == "" {
-		response.BadRequest(c, "角色ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "角色ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	err = ctrl.roleService.Delete(uint(id))
 	if err != nil {
-		if err == roleservice.ErrRoleNotFound {
-			response.NotFound(c, "角色不存在")
-			return
-		}
-		if err == roleservice.ErrRoleIsBuiltin {
-			response.BadRequest(c, "内置角色，无法删除")
-			return
-		}
-		if err == roleservice.ErrRoleHasUsers {
-			response.BadRequest(c, "角色下存在用户，无法删除")
-			return
-		}
-		response.Error(c, "删除失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -265,29 +230,25 @@ func (ctrl *RoleController) Delete(c *gin.Context) {
 func (ctrl *RoleController) AssignMenuPermissions(c *gin.Context) {
 	roleIdStr := c.Param("roleId")
 	if roleIdStr == "" {
-		response.BadRequest(c, "角色ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("roleId"))
 		return
 	}
 
 	roleId, err := strconv.ParseUint(roleIdStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "角色ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("roleId"))
 		return
 	}
 
 	var req model.DeleteBatchReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	err = ctrl.roleService.AssignMenuPermissions(uint(roleId), req.IDs)
 	if err != nil {
-		if err == roleservice.ErrRoleNotFound {
-			response.NotFound(c, "角色不存在")
-			return
-		}
-		response.Error(c, "分配失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -307,23 +268,19 @@ func (ctrl *RoleController) AssignMenuPermissions(c *gin.Context) {
 func (ctrl *RoleController) GetMenuPermissions(c *gin.Context) {
 	roleIdStr := c.Param("roleId")
 	if roleIdStr == "" {
-		response.BadRequest(c, "角色ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("roleId"))
 		return
 	}
 
 	roleId, err := strconv.ParseUint(roleIdStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "角色ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("roleId"))
 		return
 	}
 
 	menuIDs, err := ctrl.roleService.GetMenuPermissions(uint(roleId))
 	if err != nil {
-		if err == roleservice.ErrRoleNotFound {
-			response.NotFound(c, "角色不存在")
-			return
-		}
-		response.Error(c, "查询失败："+err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"gin-admin-pro/internal/pkg/response"
+	searchservice "gin-admin-pro/internal/service/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchController 全局搜索控制器
+type SearchController struct {
+	searchService *searchservice.Service
+}
+
+// NewSearchController 创建全局搜索控制器实例，searchService 为 nil 时接口返回服务不可用
+func NewSearchController(searchService *searchservice.Service) *SearchController {
+	return &SearchController{
+		searchService: searchService,
+	}
+}
+
+// Search 全局搜索
+// @Summary 全局搜索
+// @Description 按关键词搜索用户、部门、菜单、字典数据和操作日志，只返回当前用户有菜单权限和数据权限的结果
+// @Tags 全局搜索
+// @Accept json
+// @Produce json
+// @Param keyword query string true "关键词"
+// @Param types query []string false "数据类型：user/dept/menu/dict_data/oper_log，为空时搜索全部" collectionFormat(multi)
+// @Param pageNo query int false "页码，最大 200"
+// @Param pageSize query int false "每页条数，最大 50"
+// @Success 200 {object} response.Response{data=search.SearchResult}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/system/search [get]
+func (ctrl *SearchController) Search(c *gin.Context) {
+	var req searchservice.SearchReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	result, err := ctrl.searchService.Search(c.Request.Context(), c.GetUint("userId"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Types 可搜索的数据类型
+// @Summary 可搜索的数据类型
+// @Description 获取当前用户有权限搜索的数据类型
+// @Tags 全局搜索
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]map[string]string}
+// @Router /api/v1/system/search/types [get]
+func (ctrl *SearchController) Types(c *gin.Context) {
+	types, err := ctrl.searchService.Types(c.Request.Context(), c.GetUint("userId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, types)
+}
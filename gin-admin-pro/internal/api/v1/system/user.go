@@ -3,6 +3,7 @@ package system
 import (
 	"gin-admin-pro/internal/dao/system"
 	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/response"
 	"gin-admin-pro/internal/pkg/token"
 	userservice "gin-admin-pro/internal/service/system"
@@ -43,13 +44,13 @@ func NewUserController(userDAO *system.UserDAO, tokenSvc *token.TokenService) *U
 func (ctrl *UserController) Page(c *gin.Context) {
 	var req system.UserPageReq
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	page, err := ctrl.userService.GetPage(&req)
 	if err != nil {
-		response.Error(c, "查询用户列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -69,23 +70,19 @@ func (ctrl *UserController) Page(c *gin.Context) {
 func (ctrl *UserController) Get(c *gin.Context) {
 	idStr := c.Query("id")
 	if idStr == "" {
-		response.BadRequest(c, "用户ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "用户ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	user, err := ctrl.userService.GetByID(uint(id))
 	if err != nil {
-		if err == userservice.ErrUserNotFound {
-			response.NotFound(c, "用户不存在")
-			return
-		}
-		response.Error(c, "获取用户详情失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -105,33 +102,21 @@ func (ctrl *UserController) Get(c *gin.Context) {
 func (ctrl *UserController) Create(c *gin.Context) {
 	var req system.CreateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	// 获取当前操作用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 
 	id, err := ctrl.userService.Create(&req, userID.(uint))
 	if err != nil {
-		switch err {
-		case userservice.ErrUsernameExists:
-			response.BadRequest(c, "用户名已存在")
-			return
-		case userservice.ErrEmailExists:
-			response.BadRequest(c, "邮箱已存在")
-			return
-		case userservice.ErrMobileExists:
-			response.BadRequest(c, "手机号已存在")
-			return
-		default:
-			response.Error(c, "创建用户失败")
-			return
-		}
+		response.Fail(c, err)
+		return
 	}
 
 	response.Success(c, id)
@@ -150,33 +135,21 @@ func (ctrl *UserController) Create(c *gin.Context) {
 func (ctrl *UserController) Update(c *gin.Context) {
 	var req system.UpdateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	// 获取当前操作用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 
 	err := ctrl.userService.Update(&req, userID.(uint))
 	if err != nil {
-		switch err {
-		case userservice.ErrUserNotFound:
-			response.NotFound(c, "用户不存在")
-			return
-		case userservice.ErrEmailExists:
-			response.BadRequest(c, "邮箱已存在")
-			return
-		case userservice.ErrMobileExists:
-			response.BadRequest(c, "手机号已存在")
-			return
-		default:
-			response.Error(c, "更新用户失败")
-			return
-		}
+		response.Fail(c, err)
+		return
 	}
 
 	response.Success(c, nil)
@@ -195,23 +168,19 @@ func (ctrl *UserController) Update(c *gin.Context) {
 func (ctrl *UserController) Delete(c *gin.Context) {
 	idStr := c.Query("id")
 	if idStr == "" {
-		response.BadRequest(c, "用户ID不能为空")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "用户ID格式错误")
+		response.FailCode(c, errcode.ErrParam.WithParams("id"))
 		return
 	}
 
 	err = ctrl.userService.Delete(uint(id))
 	if err != nil {
-		if err == userservice.ErrUserNotFound {
-			response.NotFound(c, "用户不存在")
-			return
-		}
-		response.Error(c, "删除用户失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -231,22 +200,18 @@ func (ctrl *UserController) Delete(c *gin.Context) {
 func (ctrl *UserController) DeleteBatch(c *gin.Context) {
 	var req model.DeleteBatchReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	if len(req.IDs) == 0 {
-		response.BadRequest(c, "请选择要删除的用户")
+		response.FailCode(c, errcode.ErrParam.WithParams("ids"))
 		return
 	}
 
 	err := ctrl.userService.DeleteBatch(req.IDs)
 	if err != nil {
-		if err == userservice.ErrUserNotFound {
-			response.NotFound(c, "用户不存在")
-			return
-		}
-		response.Error(c, "批量删除用户失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -266,17 +231,13 @@ func (ctrl *UserController) DeleteBatch(c *gin.Context) {
 func (ctrl *UserController) UpdatePassword(c *gin.Context) {
 	var req system.UpdatePasswordReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	err := ctrl.userService.UpdatePassword(&req, 0) // 管理员重置密码不需要记录操作人
 	if err != nil {
-		if err == userservice.ErrUserNotFound {
-			response.NotFound(c, "用户不存在")
-			return
-		}
-		response.Error(c, "重置密码失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -296,24 +257,20 @@ func (ctrl *UserController) UpdatePassword(c *gin.Context) {
 func (ctrl *UserController) UpdateStatus(c *gin.Context) {
 	var req system.UpdateStatusReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	// 获取当前操作用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 
 	err := ctrl.userService.UpdateStatus(&req, userID.(uint))
 	if err != nil {
-		if err == userservice.ErrUserNotFound {
-			response.NotFound(c, "用户不存在")
-			return
-		}
-		response.Error(c, "修改用户状态失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -337,7 +294,7 @@ func (ctrl *UserController) SimpleList(c *gin.Context) {
 	if deptIDStr != "" {
 		id, err := strconv.ParseUint(deptIDStr, 10, 32)
 		if err != nil {
-			response.BadRequest(c, "部门ID格式错误")
+			response.FailCode(c, errcode.ErrParam.WithParams("deptId"))
 			return
 		}
 		deptIDUint := uint(id)
@@ -346,7 +303,7 @@ func (ctrl *UserController) SimpleList(c *gin.Context) {
 
 	users, err := ctrl.userService.GetSimpleList(deptID)
 	if err != nil {
-		response.Error(c, "获取用户列表失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -366,7 +323,7 @@ func (ctrl *UserController) SimpleList(c *gin.Context) {
 func (ctrl *UserController) Login(c *gin.Context) {
 	var req userservice.LoginReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
@@ -375,17 +332,8 @@ func (ctrl *UserController) Login(c *gin.Context) {
 
 	loginResp, err := ctrl.userService.Login(&req, clientIP)
 	if err != nil {
-		switch err {
-		case userservice.ErrInvalidCredentials:
-			response.BadRequest(c, "用户名或密码错误")
-			return
-		case userservice.ErrUserDisabled:
-			response.Forbidden(c, "用户已被禁用")
-			return
-		default:
-			response.Error(c, "登录失败")
-			return
-		}
+		response.Fail(c, err)
+		return
 	}
 
 	response.Success(c, loginResp)
@@ -405,7 +353,7 @@ func (ctrl *UserController) Logout(c *gin.Context) {
 	// 从请求头获取token
 	token := c.GetHeader("Authorization")
 	if token == "" {
-		response.BadRequest(c, "token不能为空")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 
@@ -416,7 +364,7 @@ func (ctrl *UserController) Logout(c *gin.Context) {
 
 	err := ctrl.userService.Logout(token)
 	if err != nil {
-		response.Error(c, "登出失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -436,13 +384,13 @@ func (ctrl *UserController) Logout(c *gin.Context) {
 func (ctrl *UserController) RefreshToken(c *gin.Context) {
 	var req model.RefreshTokenReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误："+err.Error())
+		response.BindError(c, err)
 		return
 	}
 
 	loginResp, err := ctrl.userService.RefreshToken(req.RefreshToken)
 	if err != nil {
-		response.BadRequest(c, "刷新token失败")
+		response.FailCode(c, errcode.ErrTokenInvalid)
 		return
 	}
 
@@ -463,13 +411,13 @@ func (ctrl *UserController) GetPermissionInfo(c *gin.Context) {
 	// 获取当前用户ID
 	userID, exists := c.Get("userId")
 	if !exists {
-		response.Unauthorized(c, "未获取到用户信息")
+		response.FailCode(c, errcode.ErrLoginRequired)
 		return
 	}
 
 	userInfo, err := ctrl.userService.GetUserInfo(userID.(uint))
 	if err != nil {
-		response.Error(c, "获取用户信息失败")
+		response.Fail(c, err)
 		return
 	}
 
@@ -23,6 +23,9 @@ func NewDeptDAO(db *gorm.DB) *DeptDAO {
 type DeptListReq struct {
 	Name   string `json:"name"`
 	Status *int   `json:"status"`
+
+	// IDs 限定部门范围，如数据权限可见的部门，nil 表示不限制
+	IDs []uint `json:"-"`
 }
 
 // DeptResp 部门响应
@@ -36,7 +39,7 @@ type DeptResp struct {
 	LeaderName string     `json:"leaderName"`
 	Phone      string     `json:"phone"`
 	Email      string     `json:"email"`
-	Status     int        `json:"status"`
+	Status     int        `json:"status" dict:"system_status"`
 	Children   []DeptResp `json:"children"`
 	CreateTime int64      `json:"createTime"`
 }
@@ -51,7 +54,7 @@ type DeptDetailResp struct {
 	Leader   uint   `json:"leader"`
 	Phone    string `json:"phone"`
 	Email    string `json:"email"`
-	Status   int    `json:"status"`
+	Status   int    `json:"status" dict:"system_status"`
 	Remark   string `json:"remark"`
 }
 
@@ -108,6 +111,60 @@ func (dao *DeptDAO) GetList(req *DeptListReq) ([]DeptResp, error) {
 	return dao.buildDeptTree(depts, 0), nil
 }
 
+// GetFlatList 获取部门平铺列表，按排序返回
+func (dao *DeptDAO) GetFlatList(req *DeptListReq) ([]system.Dept, error) {
+	query := dao.db.Model(&system.Dept{})
+
+	// 名称模糊查询
+	if req.Name != "" {
+		query = query.Where("name LIKE ?", "%"+req.Name+"%")
+	}
+
+	// 状态查询
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	// 部门范围
+	if req.IDs != nil {
+		query = query.Where("id IN ?", req.IDs)
+	}
+
+	var depts []system.Dept
+	if err := query.Order("sort ASC, id ASC").Find(&depts).Error; err != nil {
+		return nil, err
+	}
+	return depts, nil
+}
+
+// GetChildIDs 获取部门及其所有下级部门ID
+func (dao *DeptDAO) GetChildIDs(deptIDs []uint) ([]uint, error) {
+	var depts []system.Dept
+	if err := dao.db.Model(&system.Dept{}).Select("id, parent_id").Find(&depts).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint, len(depts))
+	for _, dept := range depts {
+		children[dept.ParentID] = append(children[dept.ParentID], dept.ID)
+	}
+
+	seen := make(map[uint]bool, len(deptIDs))
+	result := make([]uint, 0, len(deptIDs))
+	queue := append([]uint(nil), deptIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result, nil
+}
+
 // GetAllSimpleList 获取所有部门简单列表
 func (dao *DeptDAO) GetAllSimpleList() ([]DeptSimpleResp, error) {
 	var depts []system.Dept
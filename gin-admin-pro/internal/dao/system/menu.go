@@ -38,7 +38,7 @@ type MenuResp struct {
 	Icon          string     `json:"icon"`
 	Type          int        `json:"type"`
 	Perms         string     `json:"perms"`
-	Status        int        `json:"status"`
+	Status        int        `json:"status" dict:"system_status"`
 	Visible       int        `json:"visible"`
 	KeepAlive     int        `json:"keepAlive"`
 	AlwaysShow    int        `json:"alwaysShow"`
@@ -59,7 +59,7 @@ type MenuDetailResp struct {
 	Icon          string `json:"icon"`
 	Type          int    `json:"type"`
 	Perms         string `json:"perms"`
-	Status        int    `json:"status"`
+	Status        int    `json:"status" dict:"system_status"`
 	Visible       int    `json:"visible"`
 	KeepAlive     int    `json:"keepAlive"`
 	AlwaysShow    int    `json:"alwaysShow"`
@@ -0,0 +1,208 @@
+package system
+
+import (
+	"errors"
+	"gin-admin-pro/internal/model/system"
+	"gin-admin-pro/plugin/recyclebin"
+
+	"gorm.io/gorm"
+)
+
+// 回收站恢复和彻底删除时的依赖检查错误
+var (
+	ErrRestoreDeptMissing       = errors.New("所属部门不存在或已删除，请先恢复部门")
+	ErrRestoreParentDeptMissing = errors.New("上级部门不存在或已删除，请先恢复上级部门")
+	ErrRestoreDeptNameExists    = errors.New("同级下已存在相同名称的部门")
+	ErrRestoreParentMenuMissing = errors.New("上级菜单不存在或已删除，请先恢复上级菜单")
+	ErrPurgeDeptHasChildren     = errors.New("部门下存在子部门，无法彻底删除")
+	ErrPurgeDeptHasUsers        = errors.New("部门下存在用户，无法彻底删除")
+	ErrPurgeMenuHasChildren     = errors.New("菜单下存在子菜单，无法彻底删除")
+)
+
+// RecycleBinDAO 回收站数据访问层
+type RecycleBinDAO struct {
+	db *gorm.DB
+}
+
+// NewRecycleBinDAO 创建回收站DAO实例
+func NewRecycleBinDAO(db *gorm.DB) *RecycleBinDAO {
+	return &RecycleBinDAO{db: db}
+}
+
+// RecycleBinPageReq 回收站分页请求
+type RecycleBinPageReq struct {
+	Type     string `form:"type" binding:"required"`
+	Keyword  string `form:"keyword"`
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+}
+
+// RecycleBinReq 回收站恢复/彻底删除请求
+type RecycleBinReq struct {
+	Type string `json:"type" binding:"required"`
+	IDs  []uint `json:"ids" binding:"required"`
+}
+
+// GetDB 获取数据库连接
+func (dao *RecycleBinDAO) GetDB() *gorm.DB {
+	return dao.db
+}
+
+// Entries 系统模块回收站注册项：用户、角色、部门、菜单
+func (dao *RecycleBinDAO) Entries() []*recyclebin.Entry {
+	return []*recyclebin.Entry{
+		{
+			Name:         "user",
+			Title:        "用户",
+			Model:        func() interface{} { return &system.User{} },
+			LabelColumn:  "username",
+			CheckRestore: dao.checkUserRestore,
+			BeforePurge: func(tx *gorm.DB, ids []uint) error {
+				if err := tx.Exec("DELETE FROM user_role WHERE user_id IN ?", ids).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM user_post WHERE user_id IN ?", ids).Error
+			},
+		},
+		{
+			Name:        "role",
+			Title:       "角色",
+			Model:       func() interface{} { return &system.Role{} },
+			LabelColumn: "name",
+			BeforePurge: func(tx *gorm.DB, ids []uint) error {
+				if err := tx.Exec("DELETE FROM user_role WHERE role_id IN ?", ids).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("DELETE FROM role_menu WHERE role_id IN ?", ids).Error; err != nil {
+					return err
+				}
+				return deleteRoleDept(tx, "role_id", ids)
+			},
+		},
+		{
+			Name:         "dept",
+			Title:        "部门",
+			Model:        func() interface{} { return &system.Dept{} },
+			LabelColumn:  "name",
+			CheckRestore: dao.checkDeptRestore,
+			BeforePurge: func(tx *gorm.DB, ids []uint) error {
+				if err := checkNoChildren(tx, &system.Dept{}, ids, ErrPurgeDeptHasChildren); err != nil {
+					return err
+				}
+				if err := checkNoUsers(tx, ids); err != nil {
+					return err
+				}
+				return deleteRoleDept(tx, "dept_id", ids)
+			},
+		},
+		{
+			Name:         "menu",
+			Title:        "菜单",
+			Model:        func() interface{} { return &system.Menu{} },
+			LabelColumn:  "name",
+			CheckRestore: dao.checkMenuRestore,
+			BeforePurge: func(tx *gorm.DB, ids []uint) error {
+				if err := checkNoChildren(tx, &system.Menu{}, ids, ErrPurgeMenuHasChildren); err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM role_menu WHERE menu_id IN ?", ids).Error
+			},
+		},
+	}
+}
+
+// checkUserRestore 恢复用户前检查所属部门是否存在
+func (dao *RecycleBinDAO) checkUserRestore(tx *gorm.DB, id uint) error {
+	var user system.User
+	if err := tx.Unscoped().Select("id, dept_id").First(&user, id).Error; err != nil {
+		return err
+	}
+	if user.DeptID == 0 {
+		return nil
+	}
+	return checkParentExists(tx, &system.Dept{}, user.DeptID, ErrRestoreDeptMissing)
+}
+
+// checkDeptRestore 恢复部门前检查上级部门是否存在，且同级下名称不重复
+func (dao *RecycleBinDAO) checkDeptRestore(tx *gorm.DB, id uint) error {
+	var dept system.Dept
+	if err := tx.Unscoped().Select("id, parent_id, name").First(&dept, id).Error; err != nil {
+		return err
+	}
+	if dept.ParentID != 0 {
+		if err := checkParentExists(tx, &system.Dept{}, dept.ParentID, ErrRestoreParentDeptMissing); err != nil {
+			return err
+		}
+	}
+
+	var count int64
+	err := tx.Model(&system.Dept{}).
+		Where("name = ? AND parent_id = ?", dept.Name, dept.ParentID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRestoreDeptNameExists
+	}
+	return nil
+}
+
+// checkMenuRestore 恢复菜单前检查上级菜单是否存在
+func (dao *RecycleBinDAO) checkMenuRestore(tx *gorm.DB, id uint) error {
+	var menu system.Menu
+	if err := tx.Unscoped().Select("id, parent_id").First(&menu, id).Error; err != nil {
+		return err
+	}
+	if menu.ParentID == 0 {
+		return nil
+	}
+	return checkParentExists(tx, &system.Menu{}, menu.ParentID, ErrRestoreParentMenuMissing)
+}
+
+// checkParentExists 检查依赖的记录存在且未被删除
+func checkParentExists(tx *gorm.DB, model interface{}, id uint, notFound error) error {
+	var count int64
+	if err := tx.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// checkNoChildren 检查记录下不存在子节点（包括回收站中的子节点）
+func checkNoChildren(tx *gorm.DB, model interface{}, ids []uint, exists error) error {
+	var count int64
+	err := tx.Unscoped().Model(model).
+		Where("parent_id IN ? AND id NOT IN ?", ids, ids).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return exists
+	}
+	return nil
+}
+
+// checkNoUsers 检查部门下不存在用户（包括回收站中的用户），否则这些用户将无法恢复
+func checkNoUsers(tx *gorm.DB, deptIDs []uint) error {
+	var count int64
+	if err := tx.Unscoped().Model(&system.User{}).Where("dept_id IN ?", deptIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPurgeDeptHasUsers
+	}
+	return nil
+}
+
+// deleteRoleDept 删除角色自定义数据权限中的部门关联，未启用数据权限时关联表不存在
+func deleteRoleDept(tx *gorm.DB, column string, ids []uint) error {
+	if !tx.Migrator().HasTable("role_dept") {
+		return nil
+	}
+	return tx.Exec("DELETE FROM role_dept WHERE "+column+" IN ?", ids).Error
+}
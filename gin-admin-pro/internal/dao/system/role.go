@@ -33,8 +33,8 @@ type RolePageResp struct {
 	Code       string    `json:"code"`
 	Name       string    `json:"name"`
 	Sort       int       `json:"sort"`
-	DataScope  int       `json:"dataScope"`
-	Status     int       `json:"status"`
+	DataScope  int       `json:"dataScope" dict:"data_scope"`
+	Status     int       `json:"status" dict:"system_status"`
 	Type       int       `json:"type"`
 	Remark     string    `json:"remark"`
 	CreateTime time.Time `json:"createTime"`
@@ -47,8 +47,8 @@ type RoleDetailResp struct {
 	Code       string    `json:"code"`
 	Name       string    `json:"name"`
 	Sort       int       `json:"sort"`
-	DataScope  int       `json:"dataScope"`
-	Status     int       `json:"status"`
+	DataScope  int       `json:"dataScope" dict:"data_scope"`
+	Status     int       `json:"status" dict:"system_status"`
 	Type       int       `json:"type"`
 	Remark     string    `json:"remark"`
 	CreateTime time.Time `json:"createTime"`
@@ -22,11 +22,17 @@ func NewUserDAO(db *gorm.DB) *UserDAO {
 type UserPageReq struct {
 	model.PageReq
 	Username   string   `json:"username"`
+	Nickname   string   `json:"nickname"`
 	Mobile     string   `json:"mobile"`
 	Email      string   `json:"email"`
 	Status     *int     `json:"status"`
 	DeptID     *uint    `json:"deptId"`
 	CreateTime []string `json:"createTime"`
+
+	// DeptIDs 限定部门范围，如某部门及其下级部门
+	DeptIDs []uint `json:"-"`
+	// Scopes 附加查询条件，如数据权限
+	Scopes []func(*gorm.DB) *gorm.DB `json:"-"`
 }
 
 // UserPageResp 用户分页查询响应
@@ -39,7 +45,7 @@ type UserPageResp struct {
 	Email      string     `json:"email"`
 	Mobile     string     `json:"mobile"`
 	Avatar     string     `json:"avatar"`
-	Status     int        `json:"status"`
+	Status     int        `json:"status" dict:"system_status"`
 	LoginIP    string     `json:"loginIp"`
 	LoginDate  *time.Time `json:"loginDate"`
 	CreateTime time.Time  `json:"createTime"`
@@ -56,7 +62,7 @@ type UserDetailResp struct {
 	Email    string `json:"email"`
 	Mobile   string `json:"mobile"`
 	Avatar   string `json:"avatar"`
-	Status   int    `json:"status"`
+	Status   int    `json:"status" dict:"system_status"`
 	Remark   string `json:"remark"`
 }
 
@@ -71,7 +77,7 @@ type UserSimpleResp struct {
 type CreateReq struct {
 	Username string `json:"username" binding:"required"`
 	Nickname string `json:"nickname" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password"` // 为空时使用参数配置 system.user.init-password
 	DeptID   uint   `json:"deptId" binding:"required"`
 	PostIDs  []uint `json:"postIds"`
 	Email    string `json:"email"`
@@ -119,6 +125,11 @@ func (dao *UserDAO) GetPage(req *UserPageReq) ([]UserPageResp, int64, error) {
 		query = query.Where("username LIKE ?", "%"+req.Username+"%")
 	}
 
+	// 昵称模糊查询
+	if req.Nickname != "" {
+		query = query.Where("nickname LIKE ?", "%"+req.Nickname+"%")
+	}
+
 	// 手机号模糊查询
 	if req.Mobile != "" {
 		query = query.Where("mobile LIKE ?", "%"+req.Mobile+"%")
@@ -138,6 +149,14 @@ func (dao *UserDAO) GetPage(req *UserPageReq) ([]UserPageResp, int64, error) {
 	if req.DeptID != nil {
 		query = query.Where("dept_id = ?", *req.DeptID)
 	}
+	if req.DeptIDs != nil {
+		query = query.Where("dept_id IN ?", req.DeptIDs)
+	}
+
+	// 数据权限等附加条件
+	if len(req.Scopes) > 0 {
+		query = query.Scopes(req.Scopes...)
+	}
 
 	// 创建时间范围查询
 	if len(req.CreateTime) == 2 {
@@ -225,6 +244,19 @@ func (dao *UserDAO) GetByUsername(username string) (*system.User, error) {
 	return &user, nil
 }
 
+// GetWithPermissions 获取用户及其启用的角色和角色菜单，用于计算权限标识
+func (dao *UserDAO) GetWithPermissions(userID uint) (*system.User, error) {
+	var user system.User
+	err := dao.db.
+		Preload("Roles", "status = ?", 1).
+		Preload("Roles.Menus", "status = ?", 1).
+		First(&user, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Create 创建用户
 func (dao *UserDAO) Create(req *CreateReq, createBy uint) (uint, error) {
 	user := system.User{
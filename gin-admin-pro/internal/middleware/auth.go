@@ -1,12 +1,15 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/jwt"
+	"gin-admin-pro/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	jwtlib "github.com/golang-jwt/jwt/v5"
 )
 
 // Auth JWT 认证中间件
@@ -15,22 +18,14 @@ func Auth() gin.HandlerFunc {
 		// 获取 Authorization 头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "未提供认证信息",
-				"data":    nil,
-			})
+			response.FailCode(c, errcode.ErrLoginRequired)
 			c.Abort()
 			return
 		}
 
 		// 检查 Bearer 格式
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "认证格式错误",
-				"data":    nil,
-			})
+			response.FailCode(c, errcode.ErrTokenInvalid)
 			c.Abort()
 			return
 		}
@@ -38,11 +33,7 @@ func Auth() gin.HandlerFunc {
 		// 提取 Token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "认证信息为空",
-				"data":    nil,
-			})
+			response.FailCode(c, errcode.ErrLoginRequired)
 			c.Abort()
 			return
 		}
@@ -50,13 +41,11 @@ func Auth() gin.HandlerFunc {
 		// 解析 Token
 		claims, err := jwt.ParseToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "认证信息无效",
-				"data": gin.H{
-					"error": err.Error(),
-				},
-			})
+			if errors.Is(err, jwtlib.ErrTokenExpired) {
+				response.FailCode(c, errcode.ErrTokenExpired)
+			} else {
+				response.FailCode(c, errcode.ErrTokenInvalid)
+			}
 			c.Abort()
 			return
 		}
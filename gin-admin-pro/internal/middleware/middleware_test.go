@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestNotFound = errors.New("test record not found")
+
+func init() {
+	errcode.Register(errTestNotFound, errcode.ErrDataNotFound.WithParams("test"))
+}
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	config.GlobalConfig = &config.Config{Server: config.ServerConfig{Mode: "release"}}
+
+	r := gin.New()
+	r.Use(Recovery(), ErrorHandler())
+	r.GET("/auth", Auth(), func(c *gin.Context) { response.Success(c, nil) })
+	r.GET("/admin", func(c *gin.Context) { c.Set("userId", uint(2)) }, AdminOnly(), func(c *gin.Context) {
+		response.Success(c, nil)
+	})
+	r.GET("/mapped", func(c *gin.Context) { _ = c.Error(errTestNotFound) })
+	r.GET("/plain", func(c *gin.Context) { _ = c.Error(errors.New("boom")) })
+	r.GET("/panic", func(c *gin.Context) { panic("unexpected") })
+	return r
+}
+
+func perform(t *testing.T, r *gin.Engine, path string, header map[string]string) (int, response.Response) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+func TestAuthEnvelope(t *testing.T) {
+	r := newTestRouter()
+
+	status, resp := perform(t, r, "/auth", nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, errcode.ErrLoginRequired.Code, resp.Code)
+	assert.NotEmpty(t, resp.Msg)
+
+	status, resp = perform(t, r, "/auth", map[string]string{"Authorization": "Basic abc"})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, errcode.ErrTokenInvalid.Code, resp.Code)
+
+	status, resp = perform(t, r, "/admin", map[string]string{"Accept-Language": "en-US"})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, errcode.ErrPermissionDenied.Code, resp.Code)
+	assert.Equal(t, "Permission denied", resp.Msg)
+}
+
+func TestErrorHandler(t *testing.T) {
+	r := newTestRouter()
+
+	status, resp := perform(t, r, "/mapped", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, errcode.ErrDataNotFound.Code, resp.Code)
+	assert.Equal(t, "数据不存在：test", resp.Msg)
+
+	// 未注册的错误不向客户端暴露原始信息
+	status, resp = perform(t, r, "/plain", nil)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, errcode.ErrUnknown.Code, resp.Code)
+	assert.NotContains(t, resp.Msg, "boom")
+}
+
+func TestRecovery(t *testing.T) {
+	r := newTestRouter()
+
+	status, resp := perform(t, r, "/panic", nil)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, errcode.ErrUnknown.Code, resp.Code)
+	assert.Nil(t, resp.Data)
+}
@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"net/http"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,11 +21,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		// 检查用户是否已认证
 		userID, exists := c.Get("userId")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "用户未认证",
-				"data":    nil,
-			})
+			response.FailCode(c, errcode.ErrLoginRequired)
 			c.Abort()
 			return
 		}
@@ -48,14 +45,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		}
 
 		if !hasRole {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "权限不足",
-				"data": gin.H{
-					"required": roles,
-					"current":  userRoles,
-				},
-			})
+			response.FailCode(c, errcode.ErrPermissionDenied)
 			c.Abort()
 			return
 		}
@@ -70,11 +60,7 @@ func RequirePermission(permissions ...string) gin.HandlerFunc {
 		// 检查用户是否已认证
 		userID, exists := c.Get("userId")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "用户未认证",
-				"data":    nil,
-			})
+			response.FailCode(c, errcode.ErrLoginRequired)
 			c.Abort()
 			return
 		}
@@ -98,14 +84,7 @@ func RequirePermission(permissions ...string) gin.HandlerFunc {
 		}
 
 		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "权限不足",
-				"data": gin.H{
-					"required": permissions,
-					"current":  userPermissions,
-				},
-			})
+			response.FailCode(c, errcode.ErrPermissionDenied)
 			c.Abort()
 			return
 		}
@@ -1,18 +1,20 @@
 package middleware
 
 import (
-	"net/http"
+	"log"
+	"strconv"
 	"time"
 
 	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
-	"github.com/ulule/limiter/v3/drivers/middleware/stdlib"
 	"github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
-// RateLimit 创建限流中间件
+// RateLimit 创建限流中间件，按客户端 IP 限流
 func RateLimit() gin.HandlerFunc {
 	cfg := config.GetConfig()
 
@@ -34,24 +36,26 @@ func RateLimit() gin.HandlerFunc {
 	// 创建限流器
 	instance := limiter.New(store, rate)
 
-	// 返回限流中间件
-	middleware := stdlib.NewMiddleware(instance)
-
 	return func(c *gin.Context) {
-		middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			c.Request = r
+		context, err := instance.Get(c.Request.Context(), c.ClientIP())
+		if err != nil {
+			// 限流存储异常时放行，避免影响正常请求
+			log.Printf("Rate limiter error: %v", err)
 			c.Next()
-		})).ServeHTTP(c.Writer, c.Request)
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(context.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(context.Remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(context.Reset, 10))
 
 		// 检查是否被限流
-		if c.Writer.Status() == http.StatusTooManyRequests {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"code":    429,
-				"message": "请求过于频繁，请稍后再试",
-				"data":    nil,
-			})
+		if context.Reached {
+			response.FailCode(c, errcode.ErrRateLimitExceeded)
 			c.Abort()
 			return
 		}
+
+		c.Next()
 	}
 }
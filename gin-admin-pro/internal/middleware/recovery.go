@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"gin-admin-pro/internal/pkg/config"
-	"net/http"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	"log"
 	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
@@ -15,24 +17,16 @@ func Recovery() gin.HandlerFunc {
 			if err := recover(); err != nil {
 				// 记录堆栈信息
 				stack := debug.Stack()
+				log.Printf("Panic recovered: %s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, err, stack)
 
-				// 在开发模式下，返回详细的错误信息
+				// 在开发模式下，返回详细的错误信息；生产模式下只返回错误码
 				if config.GetConfig().Server.Mode == "debug" {
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"code":    500,
-						"message": "服务器内部错误",
-						"data": gin.H{
-							"error": err,
-							"stack": string(stack),
-						},
+					response.FailCodeWithData(c, errcode.ErrUnknown, gin.H{
+						"error": err,
+						"stack": string(stack),
 					})
 				} else {
-					// 在生产模式下，只返回简单的错误信息
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"code":    500,
-						"message": "服务器内部错误",
-						"data":    nil,
-					})
+					response.FailCode(c, errcode.ErrUnknown)
 				}
 
 				// 终止请求处理
@@ -45,37 +39,21 @@ func Recovery() gin.HandlerFunc {
 }
 
 // ErrorHandler 全局错误处理中间件
+// 控制器通过 c.Error(err) 上报错误后直接返回，由该中间件统一响应：
+// 错误码或已注册的服务错误（如 system.ErrUserNotFound）按错误码响应，其余按服务器内部错误响应
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		// 检查是否有错误
-		if len(c.Errors) > 0 {
-			// 获取最后一个错误
-			err := c.Errors.Last().Err
+		// 没有错误或已经写入响应时不处理
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
 
-			// 根据错误类型返回不同的响应
-			switch e := err.(type) {
-			case *gin.Error:
-				// Gin 错误
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"code":    500,
-					"message": e.Error(),
-					"data":    nil,
-				})
-			default:
-				// 其他错误
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"code":    500,
-					"message": "请求处理失败",
-					"data": gin.H{
-						"error": e.Error(),
-					},
-				})
-			}
+		// 获取最后一个错误
+		response.Fail(c, c.Errors.Last().Err)
 
-			// 终止请求处理
-			c.Abort()
-		}
+		// 终止请求处理
+		c.Abort()
 	}
 }
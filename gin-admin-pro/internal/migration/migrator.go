@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"gin-admin-pro/internal/model"
 	"gin-admin-pro/internal/model/system"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/errorcode"
+	"gin-admin-pro/plugin/operlog"
+	"gin-admin-pro/plugin/sysconfig"
 	"gorm.io/gorm"
 	"log"
 )
@@ -35,6 +39,16 @@ func (m *Migrator) AutoMigrate() error {
 		&system.UserRole{},
 		&system.RoleMenu{},
 		&system.UserPost{},
+
+		// 字典
+		&dict.DictType{},
+		&dict.DictData{},
+		&errorcode.ErrorCode{},
+		&errorcode.ErrorCodeMessage{},
+
+		// 基础设施
+		&sysconfig.SysConfig{},
+		&operlog.OperLog{},
 	}
 
 	// 执行迁移
@@ -225,6 +239,30 @@ func (m *Migrator) insertInitialData() error {
 		log.Println("插入根部门成功")
 	}
 
+	// 插入内置参数配置
+	for _, item := range sysconfig.GetBuiltinConfigs() {
+		if err := m.db.Model(&sysconfig.SysConfig{}).Where("config_key = ?", item.ConfigKey).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			cfg := item
+			if err := m.db.Create(&cfg).Error; err != nil {
+				return err
+			}
+			log.Printf("插入参数配置 %s 成功", item.ConfigKey)
+		}
+	}
+
+	// 插入默认字典
+	if err := dict.NewPlugin(m.db, &dict.Config{Enabled: true}).Init(); err != nil {
+		return err
+	}
+
+	// 插入预定义错误码及多语言消息
+	if err := errorcode.NewPlugin(m.db, nil).Init(); err != nil {
+		return err
+	}
+
 	log.Println("初始数据插入完成")
 	return nil
 }
@@ -234,6 +272,11 @@ func (m *Migrator) DropAllTables() error {
 	log.Println("警告：正在删除所有表...")
 
 	tables := []string{
+		"infra_config",
+		"system_dict_data",
+		"system_dict_type",
+		"system_error_code_message",
+		"system_error_code",
 		"user_post",
 		"role_menu",
 		"user_role",
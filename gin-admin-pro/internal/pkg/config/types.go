@@ -15,6 +15,8 @@ type Config struct {
 	CORS      CORSConfig      `yaml:"cors" json:"cors"`
 	RateLimit RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
 	Upload    UploadConfig    `yaml:"upload" json:"upload"`
+	MCP       MCPConfig       `yaml:"mcp" json:"mcp"`
+	Search    SearchConfig    `yaml:"search" json:"search"`
 }
 
 // ServerConfig 服务器配置
@@ -110,6 +112,205 @@ type AIConfig struct {
 	Model       string  `yaml:"model" json:"model"`
 	MaxTokens   int     `yaml:"maxTokens" json:"maxTokens"`
 	Temperature float64 `yaml:"temperature" json:"temperature"`
+	// SystemPrompt 默认系统提示词
+	SystemPrompt string `yaml:"systemPrompt" json:"systemPrompt"`
+	// Timeout 请求超时时间（秒）
+	Timeout int `yaml:"timeout" json:"timeout"`
+	// MaxHistoryLength 每次请求最多携带的历史消息数，0 表示使用默认值，负数表示只受模型上下文窗口限制
+	MaxHistoryLength int `yaml:"maxHistoryLength" json:"maxHistoryLength"`
+	// SummaryMaxTokens 较早消息摘要的最大 token 数，0 表示使用默认值，负数表示不生成摘要、直接丢弃较早的消息
+	SummaryMaxTokens int `yaml:"summaryMaxTokens" json:"summaryMaxTokens"`
+	// RateLimit 每个用户每分钟最多请求次数，0 表示使用默认值，负数表示不限制
+	RateLimit int `yaml:"rateLimit" json:"rateLimit"`
+	// DailyTokenLimit 每个用户每日 token 上限，0 表示使用默认值，负数表示不限制
+	DailyTokenLimit int `yaml:"dailyTokenLimit" json:"dailyTokenLimit"`
+	// EnableCostLimit 是否按 DailyCostLimit 限制每个用户每日费用
+	EnableCostLimit bool `yaml:"enableCostLimit" json:"enableCostLimit"`
+	// DailyCostLimit 每个用户每日费用上限（美元）
+	DailyCostLimit float64 `yaml:"dailyCostLimit" json:"dailyCostLimit"`
+	// EnableAudit 是否记录提问和回复审计日志
+	EnableAudit bool `yaml:"enableAudit" json:"enableAudit"`
+	// BlockedKeywords 敏感关键词，提问命中时拒绝，回复命中时替换为 ***
+	BlockedKeywords []string `yaml:"blockedKeywords" json:"blockedKeywords"`
+	// Gateways OpenAI 兼容网关，provider 填写网关名称即可使用（名称按小写匹配）
+	Gateways map[string]AIGatewayConfig `yaml:"gateways" json:"gateways"`
+	// Routes 多提供商路由，配置后忽略 provider、apiKey、baseUrl，按模型、标签和权重选择提供商并自动故障转移
+	Routes []AIRouteConfig `yaml:"routes" json:"routes"`
+	// CircuitBreaker 多提供商路由的熔断配置
+	CircuitBreaker AICircuitBreakerConfig `yaml:"circuitBreaker" json:"circuitBreaker"`
+	// EnableTools 是否允许模型调用系统工具（查询用户、部门、字典），按当前用户的权限和数据权限执行
+	EnableTools bool `yaml:"enableTools" json:"enableTools"`
+	// MaxToolIterations 单次对话最多的工具调用轮数，0 表示使用默认值
+	MaxToolIterations int `yaml:"maxToolIterations" json:"maxToolIterations"`
+	// Embedding 向量模型，知识库和语义缓存共用
+	Embedding AIEmbeddingConfig `yaml:"embedding" json:"embedding"`
+	// Knowledge 知识库配置，知识库和文档信息保存在 database.postgresql 中，切片和向量按 store 保存到 pgvector 或 Milvus
+	Knowledge AIKnowledgeConfig `yaml:"knowledge" json:"knowledge"`
+	// Cache 响应缓存，缓存保存在 Redis 中，多实例共享
+	Cache AICacheConfig `yaml:"cache" json:"cache"`
+	// Voice 小智设备语音网关，设备通过 WebSocket 连接 /xiaozhi/v1/
+	Voice AIVoiceConfig `yaml:"voice" json:"voice"`
+	// Attachment 对话附件，图片发送给模型识别（需使用支持视觉的模型），原文件保存在 OSS 中
+	Attachment AIAttachmentConfig `yaml:"attachment" json:"attachment"`
+}
+
+// AIAttachmentConfig 对话附件配置，未配置的数值项使用插件默认值
+type AIAttachmentConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// MaxImageSize 图片的最大字节数
+	MaxImageSize int64 `yaml:"maxImageSize" json:"maxImageSize"`
+	// MaxDocumentSize 文档的最大字节数
+	MaxDocumentSize int64 `yaml:"maxDocumentSize" json:"maxDocumentSize"`
+	// MaxDocumentRunes 文档正文随消息发送的最大字符数，超出部分截断
+	MaxDocumentRunes int `yaml:"maxDocumentRunes" json:"maxDocumentRunes"`
+}
+
+// AIVoiceConfig 语音网关配置，未配置的数值项使用插件默认值
+type AIVoiceConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Tokens 设备访问令牌，设备以 Authorization: Bearer <token> 携带，启用时必须配置
+	Tokens []string `yaml:"tokens" json:"tokens"`
+	// UserID 语音对话所属用户，用于限流、配额、部门预算、用量统计和保存对话记录，启用时必须配置
+	UserID uint `yaml:"userId" json:"userId"`
+	// SystemPrompt 语音对话的系统提示词，为空时使用插件默认的简短口语化提示词
+	SystemPrompt string `yaml:"systemPrompt" json:"systemPrompt"`
+	// Model 对话模型，为空时使用 ai.model
+	Model string `yaml:"model" json:"model"`
+	// SilenceMs 自动模式下检测到语音后静音多少毫秒视为说完
+	SilenceMs int `yaml:"silenceMs" json:"silenceMs"`
+	// VADMinBytes 按 Opus 包大小判断语音的阈值（每 20ms 音频的字节数），负数表示不启用 VAD，设备需使用手动模式
+	VADMinBytes int `yaml:"vadMinBytes" json:"vadMinBytes"`
+	// ASR OpenAI 兼容语音识别接口
+	ASR AIVoiceASRConfig `yaml:"asr" json:"asr"`
+	// TTS OpenAI 兼容语音合成接口
+	TTS AIVoiceTTSConfig `yaml:"tts" json:"tts"`
+}
+
+// AIVoiceASRConfig 语音识别配置
+type AIVoiceASRConfig struct {
+	BaseURL string `yaml:"baseUrl" json:"baseUrl"`
+	// APIKey 为空时使用 ai.apiKey
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+	Model  string `yaml:"model" json:"model"`
+	// Language 语言代码，如 zh，为空时自动识别
+	Language string `yaml:"language" json:"language"`
+	// Timeout 请求超时时间（秒）
+	Timeout int `yaml:"timeout" json:"timeout"`
+}
+
+// AIVoiceTTSConfig 语音合成配置
+type AIVoiceTTSConfig struct {
+	BaseURL string `yaml:"baseUrl" json:"baseUrl"`
+	// APIKey 为空时使用 ai.apiKey
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+	Model  string `yaml:"model" json:"model"`
+	Voice  string `yaml:"voice" json:"voice"`
+	// Speed 语速 0.25-4，0 表示使用接口默认值
+	Speed float64 `yaml:"speed" json:"speed"`
+	// Timeout 请求超时时间（秒）
+	Timeout int `yaml:"timeout" json:"timeout"`
+}
+
+// AICacheConfig 响应缓存配置，只缓存非流式且未执行工具的回复
+type AICacheConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// TTL 缓存时间（秒），0 表示使用默认值
+	TTL int `yaml:"ttl" json:"ttl"`
+	// MaxEntries 每个语义分组最多记录的提问数，0 表示使用默认值
+	MaxEntries int `yaml:"maxEntries" json:"maxEntries"`
+	// Semantic 是否启用语义缓存：上下文相同且提问相似度不低于 threshold 时返回已缓存的回复，使用 ai.embedding 配置的向量模型
+	Semantic bool `yaml:"semantic" json:"semantic"`
+	// Threshold 语义缓存的最低余弦相似度，0 表示使用默认值
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+}
+
+// AIKnowledgeConfig 知识库配置，使用 ai.embedding 配置的向量模型，未配置的数值项使用插件默认值
+type AIKnowledgeConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// ChunkSize 文档切片的最大字符数
+	ChunkSize int `yaml:"chunkSize" json:"chunkSize"`
+	// ChunkOverlap 相邻切片重叠的字符数
+	ChunkOverlap int `yaml:"chunkOverlap" json:"chunkOverlap"`
+	// TopK 对话时检索的切片数
+	TopK int `yaml:"topK" json:"topK"`
+	// MinScore 最低相似度，低于该值的切片不作为参考资料
+	MinScore float64 `yaml:"minScore" json:"minScore"`
+	// MaxFileSize 上传文档的最大字节数
+	MaxFileSize int64 `yaml:"maxFileSize" json:"maxFileSize"`
+	// IndexType 向量索引类型：hnsw/ivfflat
+	IndexType string `yaml:"indexType" json:"indexType"`
+	// Store 切片向量存储：pgvector（database.postgresql，需安装 pgvector 扩展，默认）/milvus（database.milvus）
+	Store string `yaml:"store" json:"store"`
+	// Collection 切片所在的向量集合，为空时使用 ai_knowledge_vector
+	Collection string `yaml:"collection" json:"collection"`
+}
+
+// AIEmbeddingConfig 向量模型配置
+type AIEmbeddingConfig struct {
+	// Provider 向量模型提供商：openai（OpenAI 兼容接口）/hash（本地特征哈希，仅用于开发测试）
+	Provider string `yaml:"provider" json:"provider"`
+	// BaseURL 为空时使用 OpenAI 官方地址
+	BaseURL string `yaml:"baseUrl" json:"baseUrl"`
+	// APIKey 为空时使用 ai.apiKey
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+	Model  string `yaml:"model" json:"model"`
+	// Dimension 向量维度，需与模型输出一致，修改后需重建知识库
+	Dimension int `yaml:"dimension" json:"dimension"`
+	// BatchSize 单次请求的最大文本数
+	BatchSize int `yaml:"batchSize" json:"batchSize"`
+	// Timeout 请求超时时间（秒）
+	Timeout int `yaml:"timeout" json:"timeout"`
+}
+
+// AIRouteConfig 多提供商路由中的一个提供商
+type AIRouteConfig struct {
+	// Name 路由名称，默认同 provider，同一提供商配置多次时需区分
+	Name     string `yaml:"name" json:"name"`
+	Provider string `yaml:"provider" json:"provider"`
+	APIKey   string `yaml:"apiKey" json:"apiKey"`
+	BaseURL  string `yaml:"baseUrl" json:"baseUrl"`
+	// Model 请求未指定模型时使用，为空时使用提供商默认模型
+	Model string `yaml:"model" json:"model"`
+	// Models 可服务的模型，为空时使用提供商的模型目录
+	Models []string `yaml:"models" json:"models"`
+	// Tags 路由标签，请求携带标签时只选择包含全部标签的提供商
+	Tags []string `yaml:"tags" json:"tags"`
+	// Weight 权重，0 按 1 处理
+	Weight int `yaml:"weight" json:"weight"`
+}
+
+// AICircuitBreakerConfig 熔断配置
+type AICircuitBreakerConfig struct {
+	// FailureThreshold 连续失败多少次后熔断，0 表示使用默认值
+	FailureThreshold int `yaml:"failureThreshold" json:"failureThreshold"`
+	// OpenTimeout 熔断时长（秒），到期后放行一个探测请求，0 表示使用默认值
+	OpenTimeout int `yaml:"openTimeout" json:"openTimeout"`
+}
+
+// AIGatewayConfig OpenAI 兼容网关配置
+type AIGatewayConfig struct {
+	BaseURL string `yaml:"baseUrl" json:"baseUrl"`
+	// APIKey 为空时使用 ai.apiKey，均为空时不发送认证头
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+	// AuthHeader 认证请求头，默认 Authorization
+	AuthHeader string `yaml:"authHeader" json:"authHeader"`
+	// AuthScheme 认证值前缀，Authorization 默认 Bearer，其他请求头默认无前缀
+	AuthScheme string            `yaml:"authScheme" json:"authScheme"`
+	Headers    map[string]string `yaml:"headers" json:"headers"`
+	// Model 默认模型，为空时使用 models 中的第一个
+	Model  string           `yaml:"model" json:"model"`
+	Models []AIGatewayModel `yaml:"models" json:"models"`
+}
+
+// AIGatewayModel 网关模型目录，价格单位为美元/1K tokens，用于费用统计和限额
+type AIGatewayModel struct {
+	ID          string   `yaml:"id" json:"id"`
+	Name        string   `yaml:"name" json:"name"`
+	MaxTokens   int      `yaml:"maxTokens" json:"maxTokens"`
+	ContextSize int      `yaml:"contextSize" json:"contextSize"`
+	InputCost   float64  `yaml:"inputCost" json:"inputCost"`
+	OutputCost  float64  `yaml:"outputCost" json:"outputCost"`
+	Features    []string `yaml:"features" json:"features"`
 }
 
 // JWTConfig JWT配置
@@ -155,3 +356,36 @@ type UploadConfig struct {
 	Path         string   `yaml:"path" json:"path"`
 	URLPrefix    string   `yaml:"urlPrefix" json:"urlPrefix"`
 }
+
+// MCPConfig MCP 服务配置，AI 客户端通过 MCP 查询用户、部门、角色、字典和操作日志
+type MCPConfig struct {
+	// Enabled 是否开放 Streamable HTTP 接口 /mcp，stdio 方式通过 cmd/mcp 启动，不受此项影响
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// APIKeys 长期有效的 API Key，按绑定用户的角色权限和数据权限执行工具；也可直接使用登录令牌
+	APIKeys []MCPAPIKeyConfig `yaml:"apiKeys" json:"apiKeys"`
+	// AllowedOrigins 允许的浏览器来源，请求带 Origin 头且不在其中时拒绝
+	AllowedOrigins []string `yaml:"allowedOrigins" json:"allowedOrigins"`
+}
+
+// MCPAPIKeyConfig MCP API Key
+type MCPAPIKeyConfig struct {
+	// Name 用途说明，如使用者或客户端名称
+	Name   string `yaml:"name" json:"name"`
+	Key    string `yaml:"key" json:"key"`
+	UserID uint   `yaml:"userId" json:"userId"`
+}
+
+// SearchConfig 全局搜索配置，使用 database.elasticsearch 连接 Elasticsearch
+type SearchConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// IndexPrefix 索引前缀，搜索使用别名 <indexPrefix>_search
+	IndexPrefix string `yaml:"indexPrefix" json:"indexPrefix"`
+	// Analyzer 标题和内容的分词器，安装 IK 插件后可使用 ik_max_word，修改后需重建索引
+	Analyzer string `yaml:"analyzer" json:"analyzer"`
+	// SyncInterval 同步变更的间隔，单位秒
+	SyncInterval int `yaml:"syncInterval" json:"syncInterval"`
+	// BatchSize 每批同步的记录数
+	BatchSize int `yaml:"batchSize" json:"batchSize"`
+	// OutboxRetention 已同步变更的保留时间，单位小时，应大于重建索引的耗时
+	OutboxRetention int `yaml:"outboxRetention" json:"outboxRetention"`
+}
@@ -0,0 +1,145 @@
+package errcode
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"gin-admin-pro/plugin/errorcode"
+)
+
+// ErrorCode 业务错误码，service 层直接返回，由 response 包按请求语言解析消息
+type ErrorCode struct {
+	// Code 业务错误码，对应 system_error_code.code
+	Code int
+	// HTTPStatus 响应的 HTTP 状态码
+	HTTPStatus int
+	// Params 消息模板参数
+	Params []interface{}
+	// cause 原始错误，仅用于日志排查，不返回给客户端
+	cause error
+}
+
+// New 创建错误码
+func New(code, httpStatus int) *ErrorCode {
+	return &ErrorCode{Code: code, HTTPStatus: httpStatus}
+}
+
+// 预定义错误码，与 plugin/errorcode 中的错误码一一对应
+var (
+	ErrUnknown            = New(errorcode.CodeUnknownError, http.StatusInternalServerError)
+	ErrParam              = New(errorcode.CodeParamError, http.StatusBadRequest)
+	ErrDataNotFound       = New(errorcode.CodeDataNotFound, http.StatusNotFound)
+	ErrDataExists         = New(errorcode.CodeDataExists, http.StatusBadRequest)
+	ErrOperationFailed    = New(errorcode.CodeOperationFailed, http.StatusInternalServerError)
+	ErrPermissionDenied   = New(errorcode.CodePermissionDenied, http.StatusForbidden)
+	ErrTokenExpired       = New(errorcode.CodeTokenExpired, http.StatusUnauthorized)
+	ErrTokenInvalid       = New(errorcode.CodeTokenInvalid, http.StatusUnauthorized)
+	ErrRateLimitExceeded  = New(errorcode.CodeRateLimitExceeded, http.StatusTooManyRequests)
+	ErrUserNotFound       = New(errorcode.CodeUserNotFound, http.StatusNotFound)
+	ErrUserExists         = New(errorcode.CodeUserExists, http.StatusBadRequest)
+	ErrUserDisabled       = New(errorcode.CodeUserDisabled, http.StatusForbidden)
+	ErrPasswordError      = New(errorcode.CodePasswordError, http.StatusBadRequest)
+	ErrAccountLocked      = New(errorcode.CodeAccountLocked, http.StatusForbidden)
+	ErrLoginRequired      = New(errorcode.CodeLoginRequired, http.StatusUnauthorized)
+	ErrInvalidCredentials = New(errorcode.CodeInvalidCredentials, http.StatusBadRequest)
+	ErrRoleNotFound       = New(errorcode.CodeRoleNotFound, http.StatusNotFound)
+	ErrRoleExists         = New(errorcode.CodeRoleExists, http.StatusBadRequest)
+	ErrRoleInUse          = New(errorcode.CodeRoleInUse, http.StatusBadRequest)
+	ErrRoleBuiltin        = New(errorcode.CodeRoleBuiltin, http.StatusBadRequest)
+	ErrPermissionNotFound = New(errorcode.CodePermissionNotFound, http.StatusNotFound)
+	ErrPermissionExists   = New(errorcode.CodePermissionExists, http.StatusBadRequest)
+	ErrBusiness           = New(errorcode.CodeBusinessError, http.StatusBadRequest)
+	ErrDataInvalid        = New(errorcode.CodeDataInvalid, http.StatusBadRequest)
+	ErrConfig             = New(errorcode.CodeConfigError, http.StatusInternalServerError)
+	ErrServiceUnavailable = New(errorcode.CodeServiceUnavailable, http.StatusServiceUnavailable)
+
+	ErrDeptParentSelf           = New(errorcode.CodeDeptParentSelf, http.StatusBadRequest)
+	ErrDeptCircularParent       = New(errorcode.CodeDeptCircularParent, http.StatusBadRequest)
+	ErrDeptCannotDelete         = New(errorcode.CodeDeptCannotDelete, http.StatusBadRequest)
+	ErrMenuParentSelf           = New(errorcode.CodeMenuParentSelf, http.StatusBadRequest)
+	ErrMenuCircularParent       = New(errorcode.CodeMenuCircularParent, http.StatusBadRequest)
+	ErrMenuCannotDelete         = New(errorcode.CodeMenuCannotDelete, http.StatusBadRequest)
+	ErrDictTypeHasData          = New(errorcode.CodeDictTypeHasData, http.StatusBadRequest)
+	ErrRestoreDeptMissing       = New(errorcode.CodeRestoreDeptMissing, http.StatusBadRequest)
+	ErrRestoreParentDeptMissing = New(errorcode.CodeRestoreParentDeptMissing, http.StatusBadRequest)
+	ErrRestoreDeptNameExists    = New(errorcode.CodeRestoreDeptNameExists, http.StatusBadRequest)
+	ErrRestoreParentMenuMissing = New(errorcode.CodeRestoreParentMenuMissing, http.StatusBadRequest)
+	ErrPurgeDeptHasChildren     = New(errorcode.CodePurgeDeptHasChildren, http.StatusBadRequest)
+	ErrPurgeDeptHasUsers        = New(errorcode.CodePurgeDeptHasUsers, http.StatusBadRequest)
+	ErrPurgeMenuHasChildren     = New(errorcode.CodePurgeMenuHasChildren, http.StatusBadRequest)
+)
+
+// WithParams 返回带消息参数的副本，如 ErrDataNotFound.WithParams("用户")
+func (e *ErrorCode) WithParams(params ...interface{}) *ErrorCode {
+	clone := *e
+	clone.Params = params
+	return &clone
+}
+
+// Wrap 返回携带原始错误的副本，errors.Is/As 可继续匹配原始错误
+func (e *ErrorCode) Wrap(err error) *ErrorCode {
+	clone := *e
+	clone.cause = err
+	return &clone
+}
+
+// Error 返回默认语言的错误消息，不访问数据库
+func (e *ErrorCode) Error() string {
+	message := errorcode.FormatMessage(errorcode.DefaultMessage(e.Code), e.Params...)
+	if e.cause != nil {
+		return message + ": " + e.cause.Error()
+	}
+	return message
+}
+
+// Unwrap 返回原始错误
+func (e *ErrorCode) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同即视为同一错误，忽略参数和原始错误
+func (e *ErrorCode) Is(target error) bool {
+	t, ok := target.(*ErrorCode)
+	return ok && t.Code == e.Code
+}
+
+// mapping 已注册的哨兵错误与错误码的映射
+type mapping struct {
+	err  error
+	code *ErrorCode
+}
+
+var (
+	mappingsMu sync.RWMutex
+	mappings   []mapping
+)
+
+// Register 注册哨兵错误对应的错误码，如 Register(system.ErrUserNotFound, ErrUserNotFound)，
+// 使 service 层已有的 errors.New 错误无需修改即可按错误码响应
+func Register(err error, code *ErrorCode) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	mappings = append(mappings, mapping{err: err, code: code})
+}
+
+// From 从错误链中提取错误码：优先取链中的 ErrorCode，其次匹配已注册的哨兵错误
+func From(err error) (*ErrorCode, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var e *ErrorCode
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return m.code.Wrap(err), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,320 @@
+package response
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DictTag 字典翻译结构体标签
+//
+//	Status    int `json:"status" dict:"system_status"`            // 输出 status 与 statusLabel
+//	DataScope int `json:"dataScope" dict:"data_scope,scopeName"` // 自定义标签字段名
+const DictTag = "dict"
+
+// DictTranslator 字典翻译函数，根据字典类型和值返回标签
+type DictTranslator func(dictType, value string) (string, error)
+
+var dictTranslator atomic.Value
+
+// SetDictTranslator 设置字典翻译函数，传入 nil 时关闭翻译
+func SetDictTranslator(fn DictTranslator) {
+	dictTranslator.Store(fn)
+}
+
+// getDictTranslator 获取字典翻译函数
+func getDictTranslator() DictTranslator {
+	fn, _ := dictTranslator.Load().(DictTranslator)
+	return fn
+}
+
+// TranslateDict 按 dict 标签为数据追加标签字段（如 status -> statusLabel）。
+// 包含 dict 标签的结构体转换为 map 输出，其余数据原样返回；未设置翻译函数时直接返回原数据。
+func TranslateDict(data interface{}) interface{} {
+	fn := getDictTranslator()
+	if fn == nil || data == nil {
+		return data
+	}
+	t := &dictTranslation{translate: fn, labels: make(map[string]string)}
+	if result, changed := t.value(reflect.ValueOf(data)); changed {
+		return result
+	}
+	return data
+}
+
+// dictTranslation 单次响应的翻译上下文，同一字典值只查询一次
+type dictTranslation struct {
+	translate DictTranslator
+	labels    map[string]string
+}
+
+// label 查询字典标签，未找到时返回空字符串
+func (t *dictTranslation) label(dictType, value string) string {
+	key := dictType + "\x00" + value
+	if label, ok := t.labels[key]; ok {
+		return label
+	}
+	label, err := t.translate(dictType, value)
+	if err != nil {
+		label = ""
+	}
+	t.labels[key] = label
+	return label
+}
+
+// value 递归翻译，第二个返回值表示是否发生了转换
+func (t *dictTranslation) value(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() || !mayContainDict(v.Type()) {
+		return nil, false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil, false
+		}
+		return t.value(v.Elem())
+	case reflect.Struct:
+		return t.structValue(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, false
+		}
+		list := make([]interface{}, v.Len())
+		changed := false
+		for i := 0; i < v.Len(); i++ {
+			item, ok := t.value(v.Index(i))
+			if ok {
+				changed = true
+			} else {
+				item = v.Index(i).Interface()
+			}
+			list[i] = item
+		}
+		return list, changed
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		result := make(map[string]interface{}, v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			item, ok := t.value(iter.Value())
+			if ok {
+				changed = true
+			} else {
+				item = iter.Value().Interface()
+			}
+			result[iter.Key().String()] = item
+		}
+		return result, changed
+	}
+	return nil, false
+}
+
+// structValue 将结构体按 json 标签展开为 map，并追加字典标签字段
+func (t *dictTranslation) structValue(v reflect.Value) (interface{}, bool) {
+	result := make(map[string]interface{})
+	changed := t.fillStruct(v, result)
+	if !changed {
+		return nil, false
+	}
+	return result, true
+}
+
+// fillStruct 填充结构体字段，匿名嵌入的结构体字段提升到外层（外层同名字段优先）
+func (t *dictTranslation) fillStruct(v reflect.Value, result map[string]interface{}) bool {
+	changed := false
+	var embedded []reflect.Value
+
+	for _, f := range cachedFields(v.Type()) {
+		fv := v.Field(f.index)
+		if f.embedded {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			embedded = append(embedded, fv)
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		item, ok := t.value(fv)
+		if ok {
+			changed = true
+		} else {
+			item = fv.Interface()
+		}
+		result[f.name] = item
+
+		if f.dictType != "" {
+			changed = true
+			if value, ok := dictValueString(fv); ok {
+				result[f.labelName] = t.label(f.dictType, value)
+			} else {
+				result[f.labelName] = ""
+			}
+		}
+	}
+
+	for _, ev := range embedded {
+		inner := make(map[string]interface{})
+		if t.fillStruct(ev, inner) {
+			changed = true
+		}
+		for k, item := range inner {
+			if _, exists := result[k]; !exists {
+				result[k] = item
+			}
+		}
+	}
+	return changed
+}
+
+// dictField 结构体字段的 json/dict 元信息
+type dictField struct {
+	index     int
+	name      string
+	omitEmpty bool
+	embedded  bool
+	dictType  string
+	labelName string
+}
+
+var (
+	fieldCache sync.Map // reflect.Type -> []dictField
+	typeCache  sync.Map // reflect.Type -> bool
+)
+
+// cachedFields 解析并缓存结构体的可导出字段
+func cachedFields(typ reflect.Type) []dictField {
+	if cached, ok := fieldCache.Load(typ); ok {
+		return cached.([]dictField)
+	}
+
+	fields := make([]dictField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, dictField{index: i, embedded: true})
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := dictField{
+			index:     i,
+			name:      name,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		}
+		if dictTag := sf.Tag.Get(DictTag); dictTag != "" {
+			dictType, labelName, _ := strings.Cut(dictTag, ",")
+			if labelName == "" {
+				labelName = name + "Label"
+			}
+			field.dictType = dictType
+			field.labelName = labelName
+		}
+		fields = append(fields, field)
+	}
+
+	fieldCache.Store(typ, fields)
+	return fields
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// mayContainDict 判断类型中是否可能包含 dict 标签（interface 需在运行时判断）
+func mayContainDict(typ reflect.Type) bool {
+	if cached, ok := typeCache.Load(typ); ok {
+		return cached.(bool)
+	}
+	result := computeMayContainDict(typ, make(map[reflect.Type]bool))
+	typeCache.Store(typ, result)
+	return result
+}
+
+// computeMayContainDict 深度优先遍历类型，visiting 用于跳过自引用类型（如树形菜单）
+func computeMayContainDict(typ reflect.Type, visiting map[reflect.Type]bool) bool {
+	if cached, ok := typeCache.Load(typ); ok {
+		return cached.(bool)
+	}
+	if visiting[typ] {
+		return false
+	}
+	visiting[typ] = true
+
+	if typ.Implements(jsonMarshalerType) || typ.Implements(textMarshalerType) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return computeMayContainDict(typ.Elem(), visiting)
+	case reflect.Struct:
+		for _, f := range cachedFields(typ) {
+			if f.dictType != "" || computeMayContainDict(typ.Field(f.index).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dictValueString 将字段值转为字典值字符串，nil 指针返回 false
+func dictValueString(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+// isEmptyValue 与 encoding/json 的 omitempty 判断保持一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBase struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createTime"`
+}
+
+type testRole struct {
+	testBase
+	Name      string     `json:"name"`
+	DataScope int        `json:"dataScope" dict:"data_scope,dataScopeName"`
+	Status    int        `json:"status" dict:"system_status"`
+	Parent    *testRole  `json:"parent,omitempty"`
+	Children  []testRole `json:"children,omitempty"`
+	secret    string
+}
+
+type testPage struct {
+	List  interface{} `json:"list"`
+	Total int64       `json:"total"`
+}
+
+type testPlain struct {
+	Name string `json:"name"`
+}
+
+func testTranslator(calls *int) DictTranslator {
+	labels := map[string]string{
+		"system_status:1": "启用",
+		"system_status:0": "禁用",
+		"data_scope:1":    "全部数据权限",
+	}
+	return func(dictType, value string) (string, error) {
+		*calls++
+		if label, ok := labels[dictType+":"+value]; ok {
+			return label, nil
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestTranslateDict(t *testing.T) {
+	calls := 0
+	SetDictTranslator(testTranslator(&calls))
+	defer SetDictTranslator(nil)
+
+	role := testRole{
+		testBase:  testBase{ID: 7},
+		Name:      "admin",
+		DataScope: 1,
+		Status:    1,
+		Children:  []testRole{{Name: "child", DataScope: 9, Status: 0}},
+		secret:    "hidden",
+	}
+
+	result := toJSONMap(t, TranslateDict(&role))
+	assert.Equal(t, float64(7), result["id"])
+	assert.Contains(t, result, "createTime")
+	assert.Equal(t, "admin", result["name"])
+	assert.Equal(t, float64(1), result["status"])
+	assert.Equal(t, "启用", result["statusLabel"])
+	assert.Equal(t, "全部数据权限", result["dataScopeName"])
+	assert.NotContains(t, result, "parent")
+	assert.NotContains(t, result, "secret")
+
+	children := result["children"].([]interface{})
+	require.Len(t, children, 1)
+	child := children[0].(map[string]interface{})
+	assert.Equal(t, "禁用", child["statusLabel"])
+	assert.Equal(t, "", child["dataScopeName"])
+}
+
+func TestTranslateDictPage(t *testing.T) {
+	calls := 0
+	SetDictTranslator(testTranslator(&calls))
+	defer SetDictTranslator(nil)
+
+	page := &testPage{
+		List:  []testRole{{Status: 1}, {Status: 1}, {Status: 0}},
+		Total: 3,
+	}
+	result := toJSONMap(t, TranslateDict(page))
+	list := result["list"].([]interface{})
+	require.Len(t, list, 3)
+	assert.Equal(t, "启用", list[0].(map[string]interface{})["statusLabel"])
+	assert.Equal(t, "禁用", list[2].(map[string]interface{})["statusLabel"])
+	assert.Equal(t, float64(3), result["total"])
+
+	// 同一次响应内相同字典值只查询一次
+	assert.Equal(t, 3, calls)
+}
+
+func TestTranslateDictPassthrough(t *testing.T) {
+	plain := &testPlain{Name: "plain"}
+	page := &testPage{List: []testPlain{{Name: "a"}}}
+
+	SetDictTranslator(nil)
+	assert.Same(t, plain, TranslateDict(plain))
+
+	calls := 0
+	SetDictTranslator(testTranslator(&calls))
+	defer SetDictTranslator(nil)
+
+	assert.Same(t, plain, TranslateDict(plain))
+	assert.Same(t, page, TranslateDict(page))
+	assert.Nil(t, TranslateDict(nil))
+	assert.Equal(t, "text", TranslateDict("text"))
+	assert.Equal(t, 0, calls)
+}
+
+func TestSuccessWithDictLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	SetDictTranslator(testTranslator(&calls))
+	defer SetDictTranslator(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	Success(c, testRole{Status: 1})
+
+	var resp struct {
+		Code int                    `json:"code"`
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 0, resp.Code)
+	assert.Equal(t, "启用", resp.Data["statusLabel"])
+}
@@ -0,0 +1,74 @@
+package response
+
+import (
+	"log"
+	"sync/atomic"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/validate"
+	"gin-admin-pro/plugin/errorcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errorCodeService atomic.Pointer[errorcode.Service]
+	// fallbackErrorCodeService 未设置错误码服务时使用内置消息
+	fallbackErrorCodeService = errorcode.NewService(nil, nil)
+)
+
+// SetErrorCodeService 设置错误码服务，用于解析错误码消息
+func SetErrorCodeService(service *errorcode.Service) {
+	errorCodeService.Store(service)
+}
+
+// getErrorCodeService 获取错误码服务
+func getErrorCodeService() *errorcode.Service {
+	if service := errorCodeService.Load(); service != nil {
+		return service
+	}
+	return fallbackErrorCodeService
+}
+
+// Locale 根据请求头 Accept-Language 匹配响应语言
+func Locale(c *gin.Context) string {
+	return getErrorCodeService().MatchLocale(c.GetHeader("Accept-Language"))
+}
+
+// Message 按请求语言获取错误码消息
+func Message(c *gin.Context, code int, params ...interface{}) string {
+	return getErrorCodeService().
+		WithLocale(c.GetHeader("Accept-Language")).
+		GetErrorMessageWithParams(code, params...)
+}
+
+// FailCode 错误码响应，HTTP 状态码取自错误码定义
+func FailCode(c *gin.Context, e *errcode.ErrorCode) {
+	FailCodeWithData(c, e, nil)
+}
+
+// FailCodeWithData 带数据的错误码响应，如字段校验错误明细
+func FailCodeWithData(c *gin.Context, e *errcode.ErrorCode, data interface{}) {
+	c.JSON(e.HTTPStatus, Response{
+		Code: e.Code,
+		Msg:  Message(c, e.Code, e.Params...),
+		Data: data,
+	})
+}
+
+// BindError 参数绑定/校验错误响应，msg 为合并后的字段消息，data 为字段级错误列表
+func BindError(c *gin.Context, err error) {
+	fields := validate.Translate(err, Locale(c))
+	FailCodeWithData(c, errcode.ErrParam.WithParams(validate.Message(fields)), fields)
+}
+
+// Fail 错误响应，错误链中包含错误码时按错误码响应；否则记录日志并返回未知错误，
+// 不向客户端暴露数据库等内部错误信息
+func Fail(c *gin.Context, err error) {
+	if e, ok := errcode.From(err); ok {
+		FailCode(c, e)
+		return
+	}
+	log.Printf("Request error: %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	FailCode(c, errcode.ErrUnknown)
+}
@@ -0,0 +1,68 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-admin-pro/internal/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performFail(t *testing.T, acceptLanguage string, err error) (int, Response) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptLanguage != "" {
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+	}
+
+	Fail(c, err)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestFailWithErrorCode(t *testing.T) {
+	err := errcode.ErrDataNotFound.WithParams("用户")
+
+	status, resp := performFail(t, "", err)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, errcode.ErrDataNotFound.Code, resp.Code)
+	assert.Equal(t, "数据不存在：用户", resp.Msg)
+
+	status, resp = performFail(t, "en-US,en;q=0.9", errcode.ErrPermissionDenied)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Permission denied", resp.Msg)
+
+	// 包装在错误链中的错误码同样生效
+	wrapped := errors.Join(errors.New("context"), errcode.ErrTokenExpired)
+	status, resp = performFail(t, "zh-CN", wrapped)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, errcode.ErrTokenExpired.Code, resp.Code)
+}
+
+func TestFailWithPlainError(t *testing.T) {
+	status, resp := performFail(t, "", errors.New("boom"))
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, errcode.ErrUnknown.Code, resp.Code)
+	assert.Equal(t, "未知错误，请联系管理员", resp.Msg)
+	assert.NotContains(t, resp.Msg, "boom")
+}
+
+func TestErrorCodeIs(t *testing.T) {
+	cause := errors.New("record not found")
+	err := errcode.ErrUserNotFound.WithParams("admin").Wrap(cause)
+
+	assert.ErrorIs(t, err, errcode.ErrUserNotFound)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, errcode.ErrRoleNotFound)
+	assert.Equal(t, "用户不存在: record not found", err.Error())
+}
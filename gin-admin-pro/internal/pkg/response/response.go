@@ -13,12 +13,12 @@ type Response struct {
 	Data interface{} `json:"data"`
 }
 
-// Success 成功响应
+// Success 成功响应，带 dict 标签的字段会追加对应的字典标签
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Code: 0,
 		Msg:  "操作成功",
-		Data: data,
+		Data: TranslateDict(data),
 	})
 }
 
@@ -72,6 +72,6 @@ func Custom(c *gin.Context, code int, msg string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Code: code,
 		Msg:  msg,
-		Data: data,
+		Data: TranslateDict(data),
 	})
 }
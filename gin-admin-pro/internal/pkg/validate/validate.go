@@ -0,0 +1,166 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// FieldError 字段级校验错误
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名，取 json/form 标签
+	Message string `json:"message"` // 已翻译的错误消息
+}
+
+var (
+	once       sync.Once
+	initErr    error
+	translator *ut.UniversalTranslator
+)
+
+// Init 为 gin 的校验器注册字段名解析与中英文翻译，重复调用只执行一次
+func Init() error {
+	once.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			initErr = errors.New("binding 校验器不是 go-playground/validator")
+			return
+		}
+
+		// 错误消息中使用 json/form 标签作为字段名，与前端字段保持一致
+		v.RegisterTagNameFunc(fieldName)
+
+		zhLocale := zh.New()
+		translator = ut.New(zhLocale, zhLocale, en.New())
+
+		zhTrans, _ := translator.GetTranslator("zh")
+		if err := zhtranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+			initErr = err
+			return
+		}
+		enTrans, _ := translator.GetTranslator("en")
+		if err := entranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+			initErr = err
+			return
+		}
+	})
+	return initErr
+}
+
+// fieldName 解析字段名：json 标签 -> form 标签 -> 结构体字段名
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Translate 将 ShouldBind 返回的错误翻译为字段级错误，locale 如 zh-CN、en-US。
+// 非校验类错误（如 JSON 格式错误、类型不匹配）同样转换为可读消息。
+func Translate(err error, locale string) []FieldError {
+	if err == nil {
+		return nil
+	}
+	english := isEnglish(locale)
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		trans := getTranslator(english)
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			message := fe.Error()
+			if trans != nil {
+				message = fe.Translate(trans)
+			}
+			fields = append(fields, FieldError{Field: fieldPath(fe), Message: message})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		message := fmt.Sprintf("%s 类型错误，应为 %s", typeErr.Field, typeErr.Type.String())
+		if english {
+			message = fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type.String())
+		}
+		return []FieldError{{Field: typeErr.Field, Message: message}}
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		message := fmt.Sprintf("数值格式错误：%s", numErr.Num)
+		if english {
+			message = fmt.Sprintf("invalid number: %s", numErr.Num)
+		}
+		return []FieldError{{Message: message}}
+	}
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return []FieldError{{Message: pick(english, "请求体不是合法的 JSON", "request body is not valid JSON")}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Message: pick(english, "请求体不能为空", "request body is empty")}}
+	}
+
+	return []FieldError{{Message: err.Error()}}
+}
+
+// Message 将字段错误合并为一条消息
+func Message(fields []FieldError) string {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// fieldPath 去掉顶层结构体名，如 UserCreateReq.dept.name -> dept.name
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// getTranslator 获取翻译器，未初始化时先初始化
+func getTranslator(english bool) ut.Translator {
+	if err := Init(); err != nil || translator == nil {
+		return nil
+	}
+	trans, _ := translator.GetTranslator(pick(english, "zh", "en"))
+	return trans
+}
+
+// isEnglish 判断语言是否为英文
+func isEnglish(locale string) bool {
+	return strings.HasPrefix(strings.ToLower(locale), "en")
+}
+
+// pick 按语言选择文案
+func pick(english bool, zhText, enText string) string {
+	if english {
+		return enText
+	}
+	return zhText
+}
@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDept struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type testUserReq struct {
+	Username string   `json:"username" binding:"required,min=4"`
+	Status   int      `json:"status" binding:"oneof=0 1"`
+	Email    string   `json:"email" binding:"omitempty,email"`
+	Dept     testDept `json:"dept"`
+}
+
+func bindJSON(t *testing.T, body string) error {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req testUserReq
+	return c.ShouldBindJSON(&req)
+}
+
+func TestTranslateValidationErrors(t *testing.T) {
+	require.NoError(t, Init())
+
+	err := bindJSON(t, `{"username":"ab","status":3,"email":"bad"}`)
+	require.Error(t, err)
+
+	fields := Translate(err, "zh-CN")
+	require.Len(t, fields, 4)
+
+	byField := make(map[string]string)
+	for _, f := range fields {
+		byField[f.Field] = f.Message
+	}
+	assert.Contains(t, byField["username"], "username")
+	assert.Contains(t, byField["status"], "[0 1]")
+	assert.Contains(t, byField, "email")
+	assert.Contains(t, byField["dept.name"], "必填")
+
+	fields = Translate(err, "en-US")
+	for _, f := range fields {
+		if f.Field == "dept.name" {
+			assert.Equal(t, "name is a required field", f.Message)
+		}
+	}
+	assert.NotEmpty(t, Message(fields))
+}
+
+func TestTranslateDecodeErrors(t *testing.T) {
+	require.NoError(t, Init())
+
+	fields := Translate(bindJSON(t, `{"username":"admin","status":"x"}`), "zh-CN")
+	require.Len(t, fields, 1)
+	assert.Equal(t, "status", fields[0].Field)
+	assert.Contains(t, fields[0].Message, "int")
+
+	fields = Translate(bindJSON(t, `{bad json`), "en-US")
+	require.Len(t, fields, 1)
+	assert.Equal(t, "request body is not valid JSON", fields[0].Message)
+
+	fields = Translate(bindJSON(t, ``), "zh-CN")
+	require.Len(t, fields, 1)
+	assert.Equal(t, "请求体不能为空", fields[0].Message)
+
+	assert.Nil(t, Translate(nil, "zh-CN"))
+}
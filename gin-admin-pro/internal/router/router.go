@@ -1,13 +1,17 @@
 package router
 
 import (
+	apiai "gin-admin-pro/internal/api/v1/ai"
 	apinfra "gin-admin-pro/internal/api/v1/infra"
 	apisystem "gin-admin-pro/internal/api/v1/system"
 	apidao "gin-admin-pro/internal/dao/system"
 	"gin-admin-pro/internal/middleware"
 	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/internal/pkg/response"
+	"gin-admin-pro/internal/pkg/validate"
 	"gin-admin-pro/internal/service"
-	"net/http"
+	"log"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -27,6 +31,7 @@ func InitRouter() *gin.Engine {
 	// 添加中间件
 	r.Use(gin.Logger())
 	r.Use(middleware.Recovery())        // 自定义异常处理中间件
+	r.Use(middleware.ErrorHandler())    // 统一错误处理中间件
 	r.Use(middleware.RateLimit())       // 限流中间件
 	r.Use(middleware.OperationLogger()) // 操作日志中间件
 
@@ -35,18 +40,34 @@ func InitRouter() *gin.Engine {
 		r.Use(corsMiddleware(cfg.CORS))
 	}
 
+	// 参数校验错误翻译为字段级消息
+	if err := validate.Init(); err != nil {
+		log.Printf("Failed to init validator translations: %v", err)
+	}
+
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    0,
-			"message": "服务正常",
-			"data": gin.H{
-				"status": "ok",
-				"server": cfg.Server.Name,
-			},
+		response.Success(c, gin.H{
+			"status": "ok",
+			"server": cfg.Server.Name,
 		})
 	})
 
+	// 小智设备语音网关（WebSocket），设备以令牌认证，不使用用户登录态
+	if service.Services.VoiceServer != nil {
+		r.GET("/xiaozhi/v1/", gin.WrapH(service.Services.VoiceServer))
+	}
+
+	// MCP 服务（Streamable HTTP），以登录令牌或 API Key 认证，按用户权限执行工具
+	if service.Services.MCPServer != nil {
+		r.Any("/mcp", gin.WrapH(service.Services.MCPServer))
+	}
+
+	// 未匹配的路由同样使用统一响应结构
+	r.NoRoute(func(c *gin.Context) {
+		response.FailCode(c, errcode.ErrDataNotFound.WithParams(c.Request.URL.Path))
+	})
+
 	// API 分组
 	api := r.Group("/api")
 	{
@@ -60,12 +81,18 @@ func InitRouter() *gin.Engine {
 				roleDAO := apidao.NewRoleDAO(service.Services.MySQLClient.GetDB())
 				menuDAO := apidao.NewMenuDAO(service.Services.MySQLClient.GetDB())
 				deptDAO := apidao.NewDeptDAO(service.Services.MySQLClient.GetDB())
+				recycleBinDAO := apidao.NewRecycleBinDAO(service.Services.MySQLClient.GetDB())
 
 				// 初始化控制器
 				userCtrl := apisystem.NewUserController(userDAO, service.Services.TokenService)
 				roleCtrl := apisystem.NewRoleController(roleDAO)
 				menuCtrl := apisystem.NewMenuController(menuDAO)
 				deptCtrl := apisystem.NewDeptController(deptDAO)
+				recycleBinCtrl := apisystem.NewRecycleBinController(recycleBinDAO)
+				dictTypeCtrl := apisystem.NewDictTypeController(service.Services.DictService)
+				dictDataCtrl := apisystem.NewDictDataController(service.Services.DictService)
+				errorCodeCtrl := apisystem.NewErrorCodeController(service.Services.ErrorCodeService)
+				searchCtrl := apisystem.NewSearchController(service.Services.SearchService)
 
 				// 用户管理路由（需要认证）
 				user := system.Group("/user")
@@ -124,6 +151,65 @@ func InitRouter() *gin.Engine {
 					dept.GET("/users", deptCtrl.GetUsers)                           // 实现获取部门用户
 				}
 
+				// 字典类型路由（需要认证）
+				dictType := system.Group("/dict-type")
+				dictType.Use(middleware.Auth()) // 认证中间件
+				{
+					dictType.GET("/page", dictTypeCtrl.Page)                                // 字典类型分页
+					dictType.GET("/get", dictTypeCtrl.Get)                                  // 字典类型详情
+					dictType.GET("/list-all-simple", dictTypeCtrl.ListAllSimple)            // 字典类型精简列表
+					dictType.POST("/create", middleware.AdminOnly(), dictTypeCtrl.Create)   // 创建字典类型（仅管理员）
+					dictType.PUT("/update", middleware.AdminOnly(), dictTypeCtrl.Update)    // 更新字典类型（仅管理员）
+					dictType.DELETE("/delete", middleware.AdminOnly(), dictTypeCtrl.Delete) // 删除字典类型（仅管理员）
+				}
+
+				// 字典数据路由（需要认证）
+				dictData := system.Group("/dict-data")
+				dictData.Use(middleware.Auth()) // 认证中间件
+				{
+					dictData.GET("/page", dictDataCtrl.Page)                                           // 字典数据分页
+					dictData.GET("/get", dictDataCtrl.Get)                                             // 字典数据详情
+					dictData.GET("/simple-list", dictDataCtrl.SimpleList)                              // 全部字典数据（前端启动加载）
+					dictData.GET("/type", dictDataCtrl.ListByType)                                     // 指定类型的字典数据
+					dictData.POST("/create", middleware.AdminOnly(), dictDataCtrl.Create)              // 创建字典数据（仅管理员）
+					dictData.PUT("/update", middleware.AdminOnly(), dictDataCtrl.Update)               // 更新字典数据（仅管理员）
+					dictData.DELETE("/delete", middleware.AdminOnly(), dictDataCtrl.Delete)            // 删除字典数据（仅管理员）
+					dictData.POST("/refresh-cache", middleware.AdminOnly(), dictDataCtrl.RefreshCache) // 刷新字典缓存（仅管理员）
+				}
+
+				// 错误码路由（需要认证，仅管理员）
+				errorCode := system.Group("/error-code")
+				errorCode.Use(middleware.Auth(), middleware.AdminOnly())
+				{
+					errorCode.GET("/page", errorCodeCtrl.Page)                   // 错误码分页
+					errorCode.GET("/get", errorCodeCtrl.Get)                     // 错误码详情
+					errorCode.GET("/types", errorCodeCtrl.Types)                 // 错误类型及支持的语言
+					errorCode.POST("/create", errorCodeCtrl.Create)              // 创建错误码
+					errorCode.PUT("/update", errorCodeCtrl.Update)               // 更新错误码
+					errorCode.DELETE("/delete", errorCodeCtrl.Delete)            // 删除错误码
+					errorCode.GET("/messages", errorCodeCtrl.Messages)           // 获取多语言消息
+					errorCode.PUT("/messages", errorCodeCtrl.SaveMessages)       // 保存多语言消息
+					errorCode.POST("/refresh-cache", errorCodeCtrl.RefreshCache) // 刷新消息缓存
+				}
+
+				// 全局搜索路由（需要认证，按用户的菜单权限和数据权限过滤结果）
+				search := system.Group("/search")
+				search.Use(middleware.Auth()) // 认证中间件
+				{
+					search.GET("", searchCtrl.Search)      // 全局搜索
+					search.GET("/types", searchCtrl.Types) // 可搜索的数据类型
+				}
+
+				// 回收站路由（需要认证，仅管理员）
+				recycleBin := system.Group("/recycle-bin")
+				recycleBin.Use(middleware.Auth(), middleware.AdminOnly())
+				{
+					recycleBin.GET("/types", recycleBinCtrl.Types)     // 获取回收站类型
+					recycleBin.GET("/page", recycleBinCtrl.Page)       // 分页查询已删除记录
+					recycleBin.PUT("/restore", recycleBinCtrl.Restore) // 恢复记录
+					recycleBin.DELETE("/purge", recycleBinCtrl.Purge)  // 彻底删除记录
+				}
+
 				// 认证路由（不需要认证）
 				auth := system.Group("/auth")
 				{
@@ -139,6 +225,19 @@ func InitRouter() *gin.Engine {
 				// 初始化文件控制器
 				fileCtrl := apinfra.NewFileController(service.Services.OSSStorage)
 
+				// 参数配置
+				configCtrl := apinfra.NewConfigController(service.Services.ConfigService)
+				configGroup := infra.Group("/config")
+				{
+					configGroup.GET("/page", middleware.AdminOnly(), configCtrl.Page)                   // 参数配置分页（仅管理员，含不可见参数）
+					configGroup.GET("/get", middleware.AdminOnly(), configCtrl.Get)                     // 参数配置详情（仅管理员，含不可见参数）
+					configGroup.GET("/get-value-by-key", configCtrl.GetValueByKey)                      // 根据键名获取参数值
+					configGroup.POST("/create", middleware.AdminOnly(), configCtrl.Create)              // 创建参数配置（仅管理员）
+					configGroup.PUT("/update", middleware.AdminOnly(), configCtrl.Update)               // 更新参数配置（仅管理员）
+					configGroup.DELETE("/delete", middleware.AdminOnly(), configCtrl.Delete)            // 删除参数配置（仅管理员）
+					configGroup.POST("/refresh-cache", middleware.AdminOnly(), configCtrl.RefreshCache) // 刷新参数缓存（仅管理员）
+				}
+
 				file := infra.Group("/file")
 				{
 					file.POST("/upload", fileCtrl.Upload)                  // 上传单个文件
@@ -148,10 +247,83 @@ func InitRouter() *gin.Engine {
 			}
 
 			// AI 模块（需要认证）
+			chatCtrl := apiai.NewChatController(service.Services.AIService, service.Services.AIToolService, service.Services.KnowledgeService, service.Services.AIUsageService, service.Services.PromptService, service.Services.AIAttachmentService)
+			modelCtrl := apiai.NewModelController(service.Services.AIService)
+			knowledgeCtrl := apiai.NewKnowledgeController(service.Services.KnowledgeService)
+			usageCtrl := apiai.NewUsageController(service.Services.MySQLClient.GetDB(), service.Services.AIUsageService)
+			promptCtrl := apiai.NewPromptController(service.Services.PromptService)
 			ai := v1.Group("/ai")
 			ai.Use(middleware.Auth()) // 认证中间件
 			{
-				ai.POST("/chat", nil) // TODO: 实现AI对话
+				ai.POST("/chat", chatCtrl.Chat)                          // AI对话，stream=true 时以 SSE 返回
+				ai.POST("/attachment/upload", chatCtrl.AttachmentUpload) // 上传对话附件（图片、文本文档）
+
+				conversation := ai.Group("/conversation")
+				{
+					conversation.GET("/page", chatCtrl.ConversationPage)         // 获取对话列表
+					conversation.GET("/get", chatCtrl.ConversationGet)           // 获取对话详情
+					conversation.GET("/messages", chatCtrl.ConversationMessages) // 分页获取对话消息
+					conversation.DELETE("/delete", chatCtrl.ConversationDelete)  // 删除对话
+				}
+
+				// 本地模型管理（仅管理员）
+				model := ai.Group("/model")
+				model.Use(middleware.AdminOnly())
+				{
+					model.GET("/list", modelCtrl.List)     // 获取本地模型列表
+					model.POST("/pull", modelCtrl.Pull)    // 下载模型，以 SSE 返回进度
+					model.GET("/health", modelCtrl.Health) // 获取提供商健康状态
+				}
+
+				// 知识库（查询和检索测试对所有用户开放，维护仅管理员）
+				knowledgeGroup := ai.Group("/knowledge")
+				{
+					knowledgeGroup.GET("/page", knowledgeCtrl.Page)                                                 // 获取知识库列表
+					knowledgeGroup.GET("/get", knowledgeCtrl.Get)                                                   // 获取知识库详情
+					knowledgeGroup.POST("/create", middleware.AdminOnly(), knowledgeCtrl.Create)                    // 创建知识库（仅管理员）
+					knowledgeGroup.PUT("/update", middleware.AdminOnly(), knowledgeCtrl.Update)                     // 更新知识库（仅管理员）
+					knowledgeGroup.DELETE("/delete", middleware.AdminOnly(), knowledgeCtrl.Delete)                  // 删除知识库（仅管理员）
+					knowledgeGroup.GET("/document/page", knowledgeCtrl.DocumentPage)                                // 获取文档列表
+					knowledgeGroup.POST("/document/upload", middleware.AdminOnly(), knowledgeCtrl.DocumentUpload)   // 上传文档并建立索引（仅管理员）
+					knowledgeGroup.DELETE("/document/delete", middleware.AdminOnly(), knowledgeCtrl.DocumentDelete) // 删除文档（仅管理员）
+					knowledgeGroup.POST("/search", knowledgeCtrl.Search)                                            // 检索测试
+				}
+
+				// 提示词模板（仅管理员）
+				promptGroup := ai.Group("/prompt")
+				promptGroup.Use(middleware.AdminOnly())
+				{
+					promptGroup.GET("/template/page", promptCtrl.TemplatePage)        // 获取模板列表
+					promptGroup.GET("/template/get", promptCtrl.TemplateGet)          // 获取模板详情
+					promptGroup.POST("/template/create", promptCtrl.TemplateCreate)   // 创建模板
+					promptGroup.PUT("/template/update", promptCtrl.TemplateUpdate)    // 更新模板
+					promptGroup.DELETE("/template/delete", promptCtrl.TemplateDelete) // 删除模板
+					promptGroup.GET("/version/list", promptCtrl.VersionList)          // 获取模板版本列表
+					promptGroup.POST("/version/publish", promptCtrl.VersionPublish)   // 发布新版本
+					promptGroup.POST("/version/activate", promptCtrl.VersionActivate) // 切换当前版本
+					promptGroup.POST("/preview", promptCtrl.Preview)                  // 预览渲染结果
+				}
+
+				// 助手（查询对所有用户开放，维护仅管理员）
+				assistant := ai.Group("/assistant")
+				{
+					assistant.GET("/page", promptCtrl.AssistantPage)                                // 获取助手列表
+					assistant.GET("/get", promptCtrl.AssistantGet)                                  // 获取助手详情
+					assistant.POST("/create", middleware.AdminOnly(), promptCtrl.AssistantCreate)   // 创建助手（仅管理员）
+					assistant.PUT("/update", middleware.AdminOnly(), promptCtrl.AssistantUpdate)    // 更新助手（仅管理员）
+					assistant.DELETE("/delete", middleware.AdminOnly(), promptCtrl.AssistantDelete) // 删除助手（仅管理员）
+				}
+
+				// 用量统计和部门预算（仅管理员）
+				usage := ai.Group("/usage")
+				usage.Use(middleware.AdminOnly())
+				{
+					usage.GET("/summary", usageCtrl.Summary)               // 按用户、部门、模型汇总每日或每月用量
+					usage.GET("/page", usageCtrl.Page)                     // 获取用量记录列表
+					usage.GET("/budget/list", usageCtrl.BudgetList)        // 获取部门预算及已用费用
+					usage.POST("/budget/save", usageCtrl.BudgetSave)       // 设置部门预算
+					usage.DELETE("/budget/delete", usageCtrl.BudgetDelete) // 删除部门预算
+				}
 			}
 		}
 	}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAttachmentDisabled 对话附件未启用
+	ErrAttachmentDisabled = errors.New("对话附件未启用")
+	// ErrAttachmentNotFound 附件不存在或不属于当前用户
+	ErrAttachmentNotFound = errors.New("附件不存在")
+)
+
+// metadataAttachments 消息元数据中保存的附件引用
+const metadataAttachments = "attachments"
+
+// documentFormat 文档附件随消息发送的格式
+const documentFormat = "\n\n附件《%s》内容：\n%s"
+
+// AttachmentStore 对话附件存储，对话时按消息引用的附件ID加载图片和文档正文
+type AttachmentStore interface {
+	Upload(ctx context.Context, filename string, reader io.Reader, userID uint) (*aiattachment.Attachment, error)
+	GetAttachments(ctx context.Context, userID uint, ids []uint) ([]aiattachment.Attachment, error)
+	LoadImage(attachment *aiattachment.Attachment) ([]byte, error)
+}
+
+// AttachmentRef 消息引用的附件，保存在消息元数据中，前端据此展示附件
+type AttachmentRef struct {
+	ID   uint   `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// UploadAttachment 上传对话附件，返回的附件ID在对话请求的 attachmentIds 中引用
+func (s *ChatService) UploadAttachment(ctx context.Context, userID uint, file *multipart.FileHeader) (*aiattachment.Attachment, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+	if s.attachments == nil {
+		return nil, ErrAttachmentDisabled
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return s.attachments.Upload(ctx, file.Filename, src, userID)
+}
+
+// loadAttachments 获取用户上传的附件，有附件不存在时返回 ErrAttachmentNotFound
+func (s *ChatService) loadAttachments(ctx context.Context, userID uint, ids []uint) ([]aiattachment.Attachment, error) {
+	if s.attachments == nil {
+		return nil, ErrAttachmentDisabled
+	}
+	attachments, err := s.attachments.GetAttachments(ctx, userID, ids)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachments, err
+}
+
+// attach 将附件加入发送给模型的消息：图片从对象存储读取后内联发送，文档正文追加到消息内容
+func (s *ChatService) attach(message *aiplugin.Message, attachments []aiattachment.Attachment) error {
+	for i := range attachments {
+		attachment := &attachments[i]
+		switch attachment.Type {
+		case aiattachment.TypeImage:
+			data, err := s.attachments.LoadImage(attachment)
+			if err != nil {
+				return err
+			}
+			message.Images = append(message.Images, aiplugin.Image{MimeType: attachment.ContentType, Data: data})
+		case aiattachment.TypeDocument:
+			message.Content += fmt.Sprintf(documentFormat, attachment.Name, attachment.Content)
+		}
+	}
+	return nil
+}
+
+// attachHistory 重新加载历史消息引用的附件，附件已删除或读取失败时只发送文本
+func (s *ChatService) attachHistory(ctx context.Context, userID uint, message *aiplugin.Message, metadata map[string]interface{}) {
+	refs := attachmentRefs(metadata)
+	if len(refs) == 0 || s.attachments == nil {
+		return
+	}
+	ids := make([]uint, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+	attachments, err := s.attachments.GetAttachments(ctx, userID, ids)
+	if err != nil {
+		return
+	}
+	attached := *message
+	if err := s.attach(&attached, attachments); err == nil {
+		*message = attached
+	}
+}
+
+// attachmentMetadata 生成消息元数据中的附件引用，以 JSON 字符串保存以便各存储原样读回
+func attachmentMetadata(attachments []aiattachment.Attachment) map[string]interface{} {
+	refs := make([]AttachmentRef, len(attachments))
+	for i, attachment := range attachments {
+		refs[i] = AttachmentRef{ID: attachment.ID, Type: attachment.Type, Name: attachment.Name, URL: attachment.FileURL}
+	}
+	encoded, _ := json.Marshal(refs)
+	return map[string]interface{}{metadataAttachments: string(encoded)}
+}
+
+// attachmentRefs 读取消息元数据中的附件引用
+func attachmentRefs(metadata map[string]interface{}) []AttachmentRef {
+	encoded, ok := metadata[metadataAttachments].(string)
+	if !ok {
+		return nil
+	}
+	var refs []AttachmentRef
+	_ = json.Unmarshal([]byte(encoded), &refs)
+	return refs
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// attachmentStub 内存中的附件存储，图片内容为固定字节
+type attachmentStub struct {
+	attachments map[uint]aiattachment.Attachment
+	loads       int
+}
+
+func (s *attachmentStub) Upload(ctx context.Context, filename string, reader io.Reader, userID uint) (*aiattachment.Attachment, error) {
+	return nil, aiattachment.ErrUnsupportedType
+}
+
+func (s *attachmentStub) GetAttachments(ctx context.Context, userID uint, ids []uint) ([]aiattachment.Attachment, error) {
+	var list []aiattachment.Attachment
+	for _, id := range ids {
+		attachment, ok := s.attachments[id]
+		if !ok || attachment.CreateBy != userID {
+			return nil, gorm.ErrRecordNotFound
+		}
+		list = append(list, attachment)
+	}
+	return list, nil
+}
+
+func (s *attachmentStub) LoadImage(attachment *aiattachment.Attachment) ([]byte, error) {
+	s.loads++
+	return []byte("png"), nil
+}
+
+func TestChatAttachments(t *testing.T) {
+	var received [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, decodeMessages(t, r))
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"是空指针"}}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = server.URL
+	cfg.EnableImageInput = true
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+
+	store := &attachmentStub{attachments: map[uint]aiattachment.Attachment{
+		1: {ID: 1, Type: aiattachment.TypeImage, Name: "报错.png", ContentType: "image/png", CreateBy: 1},
+		2: {ID: 2, Type: aiattachment.TypeDocument, Name: "error.log", Content: "panic: nil pointer", CreateBy: 1},
+		3: {ID: 3, Type: aiattachment.TypeImage, Name: "other.png", ContentType: "image/png", CreateBy: 2},
+	}}
+	svc := NewChatService(aiService)
+	ctx := context.Background()
+
+	_, err = svc.Chat(ctx, 1, &ChatReq{AttachmentIDs: []uint{1}})
+	assert.ErrorIs(t, err, ErrAttachmentDisabled)
+
+	svc.SetAttachments(store)
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "看图", AttachmentIDs: []uint{3}})
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+
+	// 图片作为图片片段发送，文档正文追加到消息内容，对话标题取自首个附件
+	first, err := svc.Chat(ctx, 1, &ChatReq{AttachmentIDs: []uint{1, 2}})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	last := received[0][len(received[0])-1]
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "text", "text": "\n\n附件《error.log》内容：\npanic: nil pointer"},
+		map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "data:image/png;base64,cG5n"}},
+	}, last["content"])
+
+	// 保存的消息只有用户输入和附件引用
+	conversation, err := svc.GetConversation(ctx, 1, first.ConversationID)
+	require.NoError(t, err)
+	assert.Equal(t, "报错.png", conversation.Title)
+	assert.Empty(t, conversation.Messages[0].Content)
+	refs := attachmentRefs(conversation.Messages[0].Metadata)
+	require.Len(t, refs, 2)
+	assert.Equal(t, AttachmentRef{ID: 1, Type: aiattachment.TypeImage, Name: "报错.png"}, refs[0])
+
+	// 后续消息重新发送历史中的图片
+	_, err = svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "怎么修复"})
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	assert.IsType(t, []interface{}{}, received[1][1]["content"])
+	assert.Equal(t, "怎么修复", received[1][3]["content"])
+	assert.Equal(t, 2, store.loads)
+}
@@ -0,0 +1,657 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+	"gin-admin-pro/plugin/aiusage"
+	"gin-admin-pro/plugin/prompt"
+
+	"gorm.io/gorm"
+)
+
+// ErrAIDisabled AI 功能未启用
+var ErrAIDisabled = errors.New("AI 功能未启用")
+
+// titleMaxRunes 根据首条消息生成对话标题时的最大字符数
+const titleMaxRunes = 30
+
+// 对话元数据中保存的助手和提示词变量
+const (
+	metadataAssistantID = "assistantId"
+	metadataVariables   = "assistantVariables"
+)
+
+// 流式事件类型
+const (
+	EventMessage = "message" // 增量内容
+	EventDone    = "done"    // 回复完成
+	EventError   = "error"   // 回复出错
+	EventTool    = "tool"    // 执行了工具
+)
+
+// ChatReq 对话请求
+type ChatReq struct {
+	ConversationID string  `json:"conversationId"`                                   // 对话ID，为空时创建新对话
+	Content        string  `json:"content" binding:"required_without=AttachmentIDs"` // 用户消息，带附件时可为空
+	Model          string  `json:"model"`                                            // 模型，为空时使用默认模型
+	MaxTokens      int     `json:"maxTokens" binding:"omitempty,min=1"`              // 最大生成 token 数
+	Temperature    float64 `json:"temperature" binding:"omitempty,min=0,max=2"`      // 采样温度
+	Stream         bool    `json:"stream"`                                           // 是否以 SSE 流式返回
+	// KnowledgeBaseIDs 检索的知识库，回复依据检索到的资料并标注引用
+	KnowledgeBaseIDs []uint `json:"knowledgeBaseIds"`
+	// AssistantID 使用的助手，新对话时指定，后续消息沿用对话的助手
+	AssistantID uint `json:"assistantId"`
+	// Variables 助手提示词变量，新对话时指定，后续消息可覆盖
+	Variables map[string]string `json:"variables"`
+	// AttachmentIDs 消息引用的附件，图片发送给模型识别，文档正文追加到消息中
+	AttachmentIDs []uint `json:"attachmentIds" binding:"max=10"`
+}
+
+// ConversationPageReq 对话分页请求
+type ConversationPageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Keyword  string `form:"keyword"` // 按标题和消息内容搜索
+}
+
+// MessagePageReq 消息分页请求
+type MessagePageReq struct {
+	ID       string `form:"id" binding:"required"` // 对话ID
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+}
+
+// ChatResp 对话响应
+type ChatResp struct {
+	ConversationID string         `json:"conversationId"`
+	MessageID      string         `json:"messageId"`
+	Content        string         `json:"content"`
+	Model          string         `json:"model"`
+	Finish         string         `json:"finish"`
+	Usage          aiplugin.Usage `json:"usage"`
+	ToolSteps      []ToolStep     `json:"toolSteps,omitempty"`     // 回复前执行的工具
+	Citations      []Citation     `json:"citations,omitempty"`     // 回复引用的知识库资料
+	BudgetWarning  string         `json:"budgetWarning,omitempty"` // 部门预算即将或已经用完时的提醒
+}
+
+// ToolStep 回复过程中执行的一次工具调用
+type ToolStep struct {
+	ConversationID string                 `json:"conversationId,omitempty"`
+	Name           string                 `json:"name"`
+	Arguments      map[string]interface{} `json:"arguments"`
+	Error          string                 `json:"error,omitempty"`
+}
+
+// StreamDelta 流式增量内容
+type StreamDelta struct {
+	ConversationID string `json:"conversationId"`
+	Delta          string `json:"delta"`
+}
+
+// StreamError 流式错误
+type StreamError struct {
+	ConversationID string `json:"conversationId"`
+	Message        string `json:"message"`
+}
+
+// StreamEvent 流式事件，Event 为 SSE 事件名
+type StreamEvent struct {
+	Event string
+	Data  interface{}
+}
+
+// ChatService AI 对话服务层，对话归属于当前登录用户
+type ChatService struct {
+	aiService   aiplugin.AIService
+	tools       *ToolService
+	knowledge   KnowledgeRetriever
+	budget      BudgetChecker
+	assistants  AssistantResolver
+	attachments AttachmentStore
+}
+
+// NewChatService 创建 AI 对话服务实例，aiService 为 nil 表示未启用 AI
+func NewChatService(aiService aiplugin.AIService) *ChatService {
+	return &ChatService{aiService: aiService}
+}
+
+// SetTools 设置 AI 工具服务，设置后模型可调用当前用户有权限的工具
+func (s *ChatService) SetTools(tools *ToolService) {
+	s.tools = tools
+}
+
+// SetKnowledge 设置知识库检索，设置后请求可指定知识库
+func (s *ChatService) SetKnowledge(retriever KnowledgeRetriever) {
+	s.knowledge = retriever
+}
+
+// SetBudget 设置部门预算检查，设置后部门当月费用超出预算时按预算设置拒绝请求或提醒
+func (s *ChatService) SetBudget(checker BudgetChecker) {
+	s.budget = checker
+}
+
+// SetAssistants 设置助手解析，设置后请求可指定助手
+func (s *ChatService) SetAssistants(resolver AssistantResolver) {
+	s.assistants = resolver
+}
+
+// SetAttachments 设置对话附件存储，设置后可上传附件并在消息中引用
+func (s *ChatService) SetAttachments(store AttachmentStore) {
+	s.attachments = store
+}
+
+// Chat 发送消息并等待完整回复
+func (s *ChatService) Chat(ctx context.Context, userID uint, req *ChatReq) (*ChatResp, error) {
+	warning, err := s.checkBudget(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, chatReq, assistant, err := s.prepare(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	discard := s.discardTurn(ctx, req, chatReq)
+	if ctx, err = s.prepareTools(ctx, userID, chatReq, assistant); err != nil {
+		return nil, discard(err)
+	}
+	citations, err := s.prepareKnowledge(ctx, req, chatReq, assistant)
+	if err != nil {
+		return nil, discard(err)
+	}
+
+	resp, err := s.aiService.Chat(ctx, chatReq)
+	if err != nil {
+		return nil, discard(wrapProviderErr(err))
+	}
+	if resp.Error != nil {
+		return nil, discard(wrapProviderErr(resp.Error))
+	}
+
+	reply := &aiplugin.Message{
+		Role:      "assistant",
+		Content:   resp.Message.Content,
+		TokenUsed: resp.Usage.TotalTokens,
+	}
+	if err := s.aiService.AddMessage(ctx, conversation.ID, reply); err != nil {
+		return nil, err
+	}
+
+	return &ChatResp{
+		ConversationID: conversation.ID,
+		MessageID:      reply.ID,
+		Content:        reply.Content,
+		Model:          resp.Model,
+		Finish:         resp.Finish,
+		Usage:          resp.Usage,
+		ToolSteps:      toolSteps(resp.Metadata),
+		Citations:      citations,
+		BudgetWarning:  warning,
+	}, nil
+}
+
+// ChatStream 发送消息并以事件流返回回复。ctx 取消（如客户端断开）时上游请求随之取消，
+// 已生成的部分回复仍会保存到对话中。
+func (s *ChatService) ChatStream(ctx context.Context, userID uint, req *ChatReq) (<-chan StreamEvent, error) {
+	warning, err := s.checkBudget(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, chatReq, assistant, err := s.prepare(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	discard := s.discardTurn(ctx, req, chatReq)
+	if ctx, err = s.prepareTools(ctx, userID, chatReq, assistant); err != nil {
+		return nil, discard(err)
+	}
+	citations, err := s.prepareKnowledge(ctx, req, chatReq, assistant)
+	if err != nil {
+		return nil, discard(err)
+	}
+
+	chunks, err := s.aiService.ChatStream(ctx, chatReq)
+	if err != nil {
+		return nil, discard(wrapProviderErr(err))
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+
+		send := func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var (
+			content strings.Builder
+			finish  string
+			usage   aiplugin.Usage
+			steps   []ToolStep
+			failed  bool
+		)
+		for chunk := range chunks {
+			if chunk.Error != nil {
+				failed = true
+				send(StreamEvent{Event: EventError, Data: StreamError{
+					ConversationID: conversation.ID,
+					Message:        chunk.Error.Message,
+				}})
+				break
+			}
+			if chunk.Finish != "" {
+				finish = chunk.Finish
+			}
+			if chunk.Usage.TotalTokens > 0 {
+				usage = chunk.Usage
+			}
+			if call, ok := chunk.Metadata["toolCall"].(aiplugin.FunctionCall); ok {
+				step := newToolStep(call)
+				step.ConversationID = conversation.ID
+				steps = append(steps, step)
+				if !send(StreamEvent{Event: EventTool, Data: step}) {
+					break
+				}
+				continue
+			}
+			if chunk.Delta == "" {
+				continue
+			}
+			content.WriteString(chunk.Delta)
+			if !send(StreamEvent{Event: EventMessage, Data: StreamDelta{
+				ConversationID: conversation.ID,
+				Delta:          chunk.Delta,
+			}}) {
+				break
+			}
+		}
+
+		if content.Len() == 0 {
+			return
+		}
+
+		// 客户端断开后 ctx 已取消，保存回复时不再继承取消信号
+		reply := &aiplugin.Message{
+			Role:      "assistant",
+			Content:   content.String(),
+			TokenUsed: usage.TotalTokens,
+		}
+		interrupted := failed || ctx.Err() != nil
+		if interrupted {
+			reply.Metadata = map[string]interface{}{"interrupted": true}
+		}
+		if err := s.aiService.AddMessage(context.WithoutCancel(ctx), conversation.ID, reply); err != nil || interrupted {
+			return
+		}
+
+		if finish == "" {
+			finish = "stop"
+		}
+		send(StreamEvent{Event: EventDone, Data: ChatResp{
+			ConversationID: conversation.ID,
+			MessageID:      reply.ID,
+			Content:        reply.Content,
+			Model:          chatReq.Model,
+			Finish:         finish,
+			Usage:          usage,
+			ToolSteps:      steps,
+			Citations:      citations,
+			BudgetWarning:  warning,
+		}})
+	}()
+
+	return events, nil
+}
+
+// ListConversations 获取当前用户的对话列表，按更新时间倒序，指定关键字时搜索标题和消息内容
+func (s *ChatService) ListConversations(ctx context.Context, userID uint, req *ConversationPageReq) ([]*aiplugin.Conversation, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+
+	limit, offset := pageLimit(req.PageNo, req.PageSize)
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		return s.aiService.SearchConversations(ctx, formatUserID(userID), keyword, limit, offset)
+	}
+	return s.aiService.ListConversations(ctx, formatUserID(userID), limit, offset)
+}
+
+// ListMessages 分页获取当前用户对话中的消息，按发送顺序
+func (s *ChatService) ListMessages(ctx context.Context, userID uint, req *MessagePageReq) ([]*aiplugin.Message, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+	if _, err := s.getOwnedConversation(ctx, userID, req.ID); err != nil {
+		return nil, err
+	}
+
+	limit, offset := pageLimit(req.PageNo, req.PageSize)
+	return s.aiService.GetMessages(ctx, req.ID, limit, offset)
+}
+
+// GetConversation 获取当前用户的对话详情（含消息）
+func (s *ChatService) GetConversation(ctx context.Context, userID uint, conversationID string) (*aiplugin.Conversation, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+	return s.getOwnedConversation(ctx, userID, conversationID)
+}
+
+// DeleteConversation 删除当前用户的对话
+func (s *ChatService) DeleteConversation(ctx context.Context, userID uint, conversationID string) error {
+	if s.aiService == nil {
+		return ErrAIDisabled
+	}
+	if _, err := s.getOwnedConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+	return s.aiService.DeleteConversation(ctx, conversationID)
+}
+
+// prepare 获取或创建对话，解析助手，保存用户消息并构建发送给模型的请求
+func (s *ChatService) prepare(ctx context.Context, userID uint, req *ChatReq) (*aiplugin.Conversation, *aiplugin.ChatRequest, *prompt.ResolvedAssistant, error) {
+	if s.aiService == nil {
+		return nil, nil, nil, ErrAIDisabled
+	}
+
+	var (
+		conversation *aiplugin.Conversation
+		err          error
+	)
+	assistantID, variables := req.AssistantID, req.Variables
+	if req.ConversationID != "" {
+		if conversation, err = s.getOwnedConversation(ctx, userID, req.ConversationID); err != nil {
+			return nil, nil, nil, err
+		}
+		assistantID, variables = conversationAssistant(conversation, req)
+	}
+	assistant, err := s.resolveAssistant(ctx, assistantID, variables)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var attachments []aiattachment.Attachment
+	if len(req.AttachmentIDs) > 0 {
+		if attachments, err = s.loadAttachments(ctx, userID, req.AttachmentIDs); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if conversation == nil {
+		title := generateTitle(req.Content)
+		if title == "" && len(attachments) > 0 {
+			title = generateTitle(attachments[0].Name)
+		}
+		metadata := map[string]interface{}{"title": title}
+		if assistant != nil {
+			metadata[metadataAssistantID] = strconv.FormatUint(uint64(assistant.ID), 10)
+			if len(variables) > 0 {
+				encoded, _ := json.Marshal(variables)
+				metadata[metadataVariables] = string(encoded)
+			}
+		}
+		if conversation, err = s.aiService.CreateConversation(ctx, formatUserID(userID), metadata); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	history, err := s.aiService.GetMessages(ctx, conversation.ID, math.MaxInt32, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// 携带消息ID，历史超出上下文窗口时 AI 服务据此记录摘要覆盖的范围
+	messages := make([]aiplugin.Message, 0, len(history)+1)
+	for _, message := range history {
+		converted := aiplugin.Message{ID: message.ID, Role: message.Role, Content: message.Content}
+		s.attachHistory(ctx, userID, &converted, message.Metadata)
+		messages = append(messages, converted)
+	}
+
+	current := aiplugin.Message{Role: "user", Content: req.Content}
+	if err := s.attach(&current, attachments); err != nil {
+		return nil, nil, nil, err
+	}
+	userMessage := &aiplugin.Message{Role: "user", Content: req.Content}
+	if len(attachments) > 0 {
+		userMessage.Metadata = attachmentMetadata(attachments)
+	}
+	if err := s.aiService.AddMessage(ctx, conversation.ID, userMessage); err != nil {
+		return nil, nil, nil, err
+	}
+	current.ID = userMessage.ID
+	messages = append(messages, current)
+
+	chatReq := &aiplugin.ChatRequest{
+		ConversationID: conversation.ID,
+		Messages:       messages,
+		Model:          req.Model,
+		MaxTokens:      req.MaxTokens,
+		Temperature:    req.Temperature,
+		Stream:         req.Stream,
+		UserID:         formatUserID(userID),
+	}
+	if assistant != nil {
+		// 请求中的模型参数优先于助手设置
+		chatReq.SystemPrompt = assistant.SystemPrompt
+		if chatReq.Model == "" {
+			chatReq.Model = assistant.Model
+		}
+		if chatReq.MaxTokens == 0 {
+			chatReq.MaxTokens = assistant.MaxTokens
+		}
+		if chatReq.Temperature == 0 {
+			chatReq.Temperature = assistant.Temperature
+		}
+	}
+	return conversation, chatReq, assistant, nil
+}
+
+// discardTurn 返回请求失败时的清理函数，删除本轮保存的提问，本轮新建的对话整体删除。
+// 被内容过滤、限流或预算拒绝的提问若留在对话中，下一轮会作为历史发送给模型。
+func (s *ChatService) discardTurn(ctx context.Context, req *ChatReq, chatReq *aiplugin.ChatRequest) func(error) error {
+	// 客户端断开后 ctx 已取消，清理时不再继承取消信号
+	ctx = context.WithoutCancel(ctx)
+	conversationID := chatReq.ConversationID
+	messageID := chatReq.Messages[len(chatReq.Messages)-1].ID
+	return func(err error) error {
+		var cleanupErr error
+		if req.ConversationID == "" {
+			cleanupErr = s.aiService.DeleteConversation(ctx, conversationID)
+		} else {
+			cleanupErr = s.aiService.DeleteMessage(ctx, conversationID, messageID)
+		}
+		if cleanupErr != nil {
+			log.Printf("删除未发送的提问失败: %v", cleanupErr)
+		}
+		return err
+	}
+}
+
+// resolveAssistant 解析助手，未指定助手时返回 nil
+func (s *ChatService) resolveAssistant(ctx context.Context, assistantID uint, variables map[string]string) (*prompt.ResolvedAssistant, error) {
+	if assistantID == 0 {
+		return nil, nil
+	}
+	if s.assistants == nil {
+		return nil, ErrPromptDisabled
+	}
+	assistant, err := s.assistants.ResolveAssistant(ctx, assistantID, variables)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAssistantNotFound
+	}
+	return assistant, err
+}
+
+// conversationAssistant 读取对话创建时指定的助手和变量，请求中的变量覆盖同名变量
+func conversationAssistant(conversation *aiplugin.Conversation, req *ChatReq) (uint, map[string]string) {
+	id, _ := conversation.Metadata[metadataAssistantID].(string)
+	assistantID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+
+	variables := make(map[string]string)
+	if encoded, ok := conversation.Metadata[metadataVariables].(string); ok {
+		_ = json.Unmarshal([]byte(encoded), &variables)
+	}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+	return uint(assistantID), variables
+}
+
+// prepareTools 启用工具时向请求声明当前用户有权限的工具，使用助手时只声明助手的工具，返回携带调用用户的上下文
+func (s *ChatService) prepareTools(ctx context.Context, userID uint, chatReq *aiplugin.ChatRequest, assistant *prompt.ResolvedAssistant) (context.Context, error) {
+	if s.tools == nil || (assistant != nil && len(assistant.Tools) == 0) {
+		return ctx, nil
+	}
+	ctx, definitions, err := s.tools.prepare(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if assistant != nil {
+		definitions = slices.DeleteFunc(definitions, func(definition aiplugin.FunctionDefinition) bool {
+			return !slices.Contains(assistant.Tools, definition.Name)
+		})
+	}
+	chatReq.Functions = definitions
+	return ctx, nil
+}
+
+// prepareKnowledge 请求或助手指定知识库时按用户问题检索资料，作为系统消息放在问题之前，返回引用
+func (s *ChatService) prepareKnowledge(ctx context.Context, req *ChatReq, chatReq *aiplugin.ChatRequest, assistant *prompt.ResolvedAssistant) ([]Citation, error) {
+	knowledgeBaseIDs := slices.Clone(req.KnowledgeBaseIDs)
+	if assistant != nil {
+		for _, id := range assistant.KnowledgeBaseIDs {
+			if !slices.Contains(knowledgeBaseIDs, id) {
+				knowledgeBaseIDs = append(knowledgeBaseIDs, id)
+			}
+		}
+	}
+	if len(knowledgeBaseIDs) == 0 || strings.TrimSpace(req.Content) == "" {
+		return nil, nil
+	}
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+
+	results, err := s.knowledge.Retrieve(ctx, knowledgeBaseIDs, req.Content)
+	if err != nil {
+		return nil, wrapProviderErr(err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	citations := newCitations(results)
+	last := len(chatReq.Messages) - 1
+	messages := make([]aiplugin.Message, 0, len(chatReq.Messages)+1)
+	messages = append(messages, chatReq.Messages[:last]...)
+	messages = append(messages, aiplugin.Message{Role: "system", Content: buildKnowledgePrompt(citations)})
+	chatReq.Messages = append(messages, chatReq.Messages[last])
+	return citations, nil
+}
+
+// checkBudget 检查用户所属部门当月预算，超出且设置为拒绝时返回错误，达到提醒比例时返回提醒
+func (s *ChatService) checkBudget(ctx context.Context, userID uint) (string, error) {
+	if s.budget == nil {
+		return "", nil
+	}
+	status, err := s.budget.CheckBudget(ctx, userID)
+	if err != nil || status == nil {
+		return "", err
+	}
+	if status.Blocked() {
+		return "", aiusage.ErrBudgetExceeded
+	}
+	if !status.Warning {
+		return "", nil
+	}
+	return fmt.Sprintf("部门本月 AI 费用已使用 $%.2f，预算 $%.2f", status.Spent, status.Budget.MonthlyLimit), nil
+}
+
+// toolSteps 从回复元数据中读取执行过的工具
+func toolSteps(metadata map[string]interface{}) []ToolStep {
+	calls, _ := metadata["toolSteps"].([]aiplugin.FunctionCall)
+	steps := make([]ToolStep, 0, len(calls))
+	for _, call := range calls {
+		steps = append(steps, newToolStep(call))
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	return steps
+}
+
+// newToolStep 将函数调用转换为工具步骤，不返回工具结果以免泄露过多数据
+func newToolStep(call aiplugin.FunctionCall) ToolStep {
+	return ToolStep{Name: call.Name, Arguments: call.Arguments, Error: call.Error}
+}
+
+// getOwnedConversation 获取对话并校验归属，不属于当前用户时按不存在处理
+func (s *ChatService) getOwnedConversation(ctx context.Context, userID uint, conversationID string) (*aiplugin.Conversation, error) {
+	conversation, err := s.aiService.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.UserID != formatUserID(userID) {
+		return nil, aiplugin.ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+// wrapProviderErr 将模型提供商错误转换为错误码
+func wrapProviderErr(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if _, ok := errcode.From(err); ok {
+		return err
+	}
+
+	var apiErr *aiplugin.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		return errcode.ErrRateLimitExceeded.Wrap(err)
+	}
+	return errcode.ErrServiceUnavailable.Wrap(err)
+}
+
+// pageLimit 将页码转换为 limit/offset，每页默认 20 条、最多 100 条
+func pageLimit(pageNo, pageSize int) (int, int) {
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return pageSize, (pageNo - 1) * pageSize
+}
+
+// formatUserID 对话中的用户ID为字符串
+func formatUserID(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+// generateTitle 使用首条消息生成对话标题
+func generateTitle(content string) string {
+	title := strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(title) <= titleMaxRunes {
+		return title
+	}
+	return string([]rune(title)[:titleMaxRunes]) + "..."
+}
@@ -0,0 +1,304 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService 创建指向模拟 OpenAI 接口的 AI 服务
+func newTestService(t *testing.T, handler http.HandlerFunc) (*ChatService, *aiplugin.DefaultAIService) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = server.URL
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+
+	return NewChatService(aiService), aiService
+}
+
+// decodeMessages 读取请求中的消息列表
+func decodeMessages(t *testing.T, r *http.Request) []map[string]interface{} {
+	var body struct {
+		Messages []map[string]interface{} `json:"messages"`
+		Stream   bool                     `json:"stream"`
+	}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body.Messages
+}
+
+func TestChat(t *testing.T) {
+	var received [][]map[string]interface{}
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		received = append(received, decodeMessages(t, r))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"reply %d"}}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`, len(received))
+	})
+	ctx := context.Background()
+
+	first, err := svc.Chat(ctx, 1, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.ConversationID)
+	assert.Equal(t, "reply 1", first.Content)
+	assert.Equal(t, 8, first.Usage.TotalTokens)
+
+	second, err := svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "继续"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ConversationID, second.ConversationID)
+
+	// 第二次请求携带系统提示词和完整历史
+	require.Len(t, received, 2)
+	roles := make([]interface{}, 0, len(received[1]))
+	for _, message := range received[1] {
+		roles = append(roles, message["role"])
+	}
+	assert.Equal(t, []interface{}{"system", "user", "assistant", "user"}, roles)
+
+	conversation, err := svc.GetConversation(ctx, 1, first.ConversationID)
+	require.NoError(t, err)
+	assert.Equal(t, "你好", conversation.Title)
+	assert.Len(t, conversation.Messages, 4)
+}
+
+func TestChatConversationOwnership(t *testing.T) {
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+	ctx := context.Background()
+
+	resp, err := svc.Chat(ctx, 1, &ChatReq{Content: "hello"})
+	require.NoError(t, err)
+
+	_, err = svc.Chat(ctx, 2, &ChatReq{ConversationID: resp.ConversationID, Content: "hi"})
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrDataNotFound.Code, e.Code)
+
+	assert.Error(t, svc.DeleteConversation(ctx, 2, resp.ConversationID))
+	list, err := svc.ListConversations(ctx, 2, &ConversationPageReq{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, svc.DeleteConversation(ctx, 1, resp.ConversationID))
+}
+
+func TestChatProviderError(t *testing.T) {
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"code":"rate_limit_exceeded","message":"slow down"}}`)
+	})
+
+	_, err := svc.Chat(context.Background(), 1, &ChatReq{Content: "hello"})
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrRateLimitExceeded.Code, e.Code)
+}
+
+func TestChatDisabled(t *testing.T) {
+	svc := NewChatService(nil)
+
+	_, err := svc.Chat(context.Background(), 1, &ChatReq{Content: "hello"})
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrServiceUnavailable.Code, e.Code)
+}
+
+func TestChatStream(t *testing.T) {
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"你", "好"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	ctx := context.Background()
+
+	events, err := svc.ChatStream(ctx, 1, &ChatReq{Content: "hi", Stream: true})
+	require.NoError(t, err)
+
+	var (
+		deltas []string
+		done   *ChatResp
+	)
+	for event := range events {
+		switch event.Event {
+		case EventMessage:
+			deltas = append(deltas, event.Data.(StreamDelta).Delta)
+		case EventDone:
+			resp := event.Data.(ChatResp)
+			done = &resp
+		default:
+			t.Fatalf("unexpected event %s", event.Event)
+		}
+	}
+
+	assert.Equal(t, []string{"你", "好"}, deltas)
+	require.NotNil(t, done)
+	assert.Equal(t, "你好", done.Content)
+	assert.Equal(t, "stop", done.Finish)
+
+	conversation, err := svc.GetConversation(ctx, 1, done.ConversationID)
+	require.NoError(t, err)
+	require.Len(t, conversation.Messages, 2)
+	assert.Equal(t, "你好", conversation.Messages[1].Content)
+}
+
+func TestChatStreamCancel(t *testing.T) {
+	upstreamClosed := make(chan struct{})
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+		close(upstreamClosed)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := svc.ChatStream(ctx, 1, &ChatReq{Content: "hi", Stream: true})
+	require.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, EventMessage, event.Event)
+	conversationID := event.Data.(StreamDelta).ConversationID
+
+	// 模拟客户端断开
+	cancel()
+	select {
+	case <-upstreamClosed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream request was not cancelled")
+	}
+	for range events {
+	}
+
+	// 已生成的部分回复仍然保存
+	conversation, err := svc.GetConversation(context.Background(), 1, conversationID)
+	require.NoError(t, err)
+	require.Len(t, conversation.Messages, 2)
+	assert.Equal(t, "partial", conversation.Messages[1].Content)
+	assert.Equal(t, true, conversation.Messages[1].Metadata["interrupted"])
+}
+
+func TestListConversationsAndMessages(t *testing.T) {
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+	ctx := context.Background()
+
+	first, err := svc.Chat(ctx, 1, &ChatReq{Content: "部署流程"})
+	require.NoError(t, err)
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "周报模板"})
+	require.NoError(t, err)
+
+	list, err := svc.ListConversations(ctx, 1, &ConversationPageReq{Keyword: "部署"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, first.ConversationID, list[0].ID)
+
+	messages, err := svc.ListMessages(ctx, 1, &MessagePageReq{ID: first.ConversationID, PageNo: 2, PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "assistant", messages[0].Role)
+
+	_, err = svc.ListMessages(ctx, 2, &MessagePageReq{ID: first.ConversationID})
+	assert.ErrorIs(t, err, aiplugin.ErrConversationNotFound)
+}
+
+func TestChatGuardrails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = server.URL
+	cfg.RateLimit.Requests = 2
+	cfg.ContentFilter.Enabled = true
+	cfg.ContentFilter.Keywords = []string{"工资表"}
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+	svc := NewChatService(aiService)
+	ctx := context.Background()
+
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "把工资表发我"})
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrDataInvalid.Code, e.Code)
+
+	// 被拦截的请求不计入限流
+	for i := 0; i < 2; i++ {
+		_, err = svc.Chat(ctx, 1, &ChatReq{Content: "hello"})
+		require.NoError(t, err)
+	}
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "hello"})
+	e, ok = errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrRateLimitExceeded.Code, e.Code)
+}
+
+func TestChatBlockedNotKept(t *testing.T) {
+	var received [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, decodeMessages(t, r))
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = server.URL
+	cfg.ContentFilter.Enabled = true
+	cfg.ContentFilter.Keywords = []string{"工资表"}
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+	svc := NewChatService(aiService)
+	ctx := context.Background()
+
+	first, err := svc.Chat(ctx, 1, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+
+	// 被拦截的提问不保存，下一轮不会作为历史发送给模型
+	_, err = svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "把工资表发我"})
+	require.Error(t, err)
+	_, err = svc.ChatStream(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "把工资表发我", Stream: true})
+	require.Error(t, err)
+	_, err = svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "继续"})
+	require.NoError(t, err)
+
+	require.Len(t, received, 2)
+	for _, message := range received[1] {
+		assert.NotContains(t, message["content"], "工资表")
+	}
+	conversation, err := svc.GetConversation(ctx, 1, first.ConversationID)
+	require.NoError(t, err)
+	assert.Len(t, conversation.Messages, 4)
+
+	// 新对话的首条提问被拦截时不留下空对话
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "工资表"})
+	require.Error(t, err)
+	list, err := svc.ListConversations(ctx, 1, &ConversationPageReq{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+	"gin-admin-pro/plugin/aiusage"
+	"gin-admin-pro/plugin/knowledge"
+	"gin-admin-pro/plugin/prompt"
+)
+
+// 注册 AI 模块哨兵错误对应的错误码
+func init() {
+	errcode.Register(ErrAIDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(aiplugin.ErrServiceNotReady, errcode.ErrServiceUnavailable)
+	errcode.Register(aiplugin.ErrStreamingDisabled, errcode.ErrBusiness.WithParams("未启用流式响应"))
+	errcode.Register(aiplugin.ErrImageInputDisabled, errcode.ErrBusiness.WithParams("未启用图片输入"))
+	errcode.Register(aiplugin.ErrConversationNotFound, errcode.ErrDataNotFound.WithParams("对话"))
+	errcode.Register(aiplugin.ErrMessageNotFound, errcode.ErrDataNotFound.WithParams("消息"))
+	errcode.Register(aiplugin.ErrRateLimited, errcode.ErrRateLimitExceeded)
+	errcode.Register(aiplugin.ErrTokenQuotaExceeded, errcode.ErrBusiness.WithParams("今日 AI 用量已达上限"))
+	errcode.Register(aiplugin.ErrCostQuotaExceeded, errcode.ErrBusiness.WithParams("今日 AI 费用已达上限"))
+	errcode.Register(aiplugin.ErrContentBlocked, errcode.ErrDataInvalid.WithParams("消息包含敏感内容"))
+	errcode.Register(aiplugin.ErrNoAvailableProvider, errcode.ErrServiceUnavailable)
+	errcode.Register(aiplugin.ErrToolIterationsExceeded, errcode.ErrBusiness.WithParams("AI 工具调用次数超过上限"))
+	errcode.Register(ErrModelManageUnsupported, errcode.ErrBusiness.WithParams("当前提供商不支持模型管理"))
+	errcode.Register(ErrKnowledgeDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(ErrKnowledgeBaseNotFound, errcode.ErrDataNotFound.WithParams("知识库"))
+	errcode.Register(ErrKnowledgeDocumentNotFound, errcode.ErrDataNotFound.WithParams("知识库文档"))
+	errcode.Register(knowledge.ErrUnsupportedDocument, errcode.ErrDataInvalid.WithParams("仅支持 txt、md、csv、json、log、html 格式的文档"))
+	errcode.Register(knowledge.ErrDocumentTooLarge, errcode.ErrDataInvalid.WithParams("文档超过大小限制"))
+	errcode.Register(knowledge.ErrEmptyDocument, errcode.ErrDataInvalid.WithParams("文档没有可索引的文本"))
+	errcode.Register(knowledge.ErrInvalidEncoding, errcode.ErrDataInvalid.WithParams("文档需为 UTF-8 编码"))
+	errcode.Register(ErrUsageDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(ErrBudgetNotFound, errcode.ErrDataNotFound.WithParams("部门预算"))
+	errcode.Register(ErrDeptNotFound, errcode.ErrDataNotFound.WithParams("部门"))
+	errcode.Register(ErrInvalidDateRange, errcode.ErrDataInvalid.WithParams("开始日期不能晚于结束日期"))
+	errcode.Register(aiusage.ErrBudgetExceeded, errcode.ErrBusiness.WithParams("部门本月 AI 预算已用完"))
+	errcode.Register(ErrPromptDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(ErrPromptTemplateNotFound, errcode.ErrDataNotFound.WithParams("提示词模板"))
+	errcode.Register(ErrAssistantNotFound, errcode.ErrDataNotFound.WithParams("助手"))
+	errcode.Register(prompt.ErrVersionNotFound, errcode.ErrDataNotFound.WithParams("提示词模板版本"))
+	errcode.Register(prompt.ErrTemplateInUse, errcode.ErrBusiness.WithParams("提示词模板已被助手使用"))
+	errcode.Register(prompt.ErrAssistantDisabled, errcode.ErrBusiness.WithParams("助手已禁用"))
+	errcode.Register(prompt.ErrMissingVariable, errcode.ErrDataInvalid.WithParams("缺少必填的提示词变量"))
+	errcode.Register(ErrAttachmentDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(ErrAttachmentNotFound, errcode.ErrDataNotFound.WithParams("附件"))
+	errcode.Register(aiattachment.ErrUnsupportedType, errcode.ErrDataInvalid.WithParams("仅支持 png、jpeg、gif、webp 图片和 txt、md、csv、json、log、html 文档"))
+	errcode.Register(aiattachment.ErrFileTooLarge, errcode.ErrDataInvalid.WithParams("附件超过大小限制"))
+}
@@ -0,0 +1,237 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/plugin/knowledge"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrKnowledgeDisabled 知识库未启用
+	ErrKnowledgeDisabled = errors.New("知识库未启用")
+	// ErrKnowledgeBaseNotFound 知识库不存在
+	ErrKnowledgeBaseNotFound = errors.New("知识库不存在")
+	// ErrKnowledgeDocumentNotFound 知识库文档不存在
+	ErrKnowledgeDocumentNotFound = errors.New("知识库文档不存在")
+)
+
+// KnowledgePageReq 知识库分页请求
+type KnowledgePageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Name     string `form:"name"`
+	Status   *int   `form:"status"`
+}
+
+// KnowledgeSaveReq 知识库创建/更新请求
+type KnowledgeSaveReq struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Status      int    `json:"status" binding:"oneof=0 1"`
+}
+
+// KnowledgeDocumentPageReq 知识库文档分页请求
+type KnowledgeDocumentPageReq struct {
+	KnowledgeBaseID uint   `form:"knowledgeBaseId" binding:"required"`
+	PageNo          int    `form:"pageNo"`
+	PageSize        int    `form:"pageSize"`
+	Name            string `form:"name"`
+}
+
+// KnowledgeSearchReq 知识库检索测试请求
+type KnowledgeSearchReq struct {
+	KnowledgeBaseIDs []uint `json:"knowledgeBaseIds" binding:"required,min=1"`
+	Query            string `json:"query" binding:"required"`
+}
+
+// Citation 回复引用的知识库切片，Index 与参考资料中的编号 [n] 对应
+type Citation struct {
+	Index           int     `json:"index"`
+	KnowledgeBaseID uint    `json:"knowledgeBaseId"`
+	DocumentID      uint    `json:"documentId"`
+	DocumentName    string  `json:"documentName"`
+	Content         string  `json:"content"`
+	Score           float64 `json:"score"`
+}
+
+// KnowledgeRetriever 知识库检索，对话时按用户问题检索参考资料
+type KnowledgeRetriever interface {
+	Retrieve(ctx context.Context, knowledgeBaseIDs []uint, query string) ([]knowledge.SearchResult, error)
+}
+
+// KnowledgeService 知识库服务层
+type KnowledgeService struct {
+	knowledge *knowledge.Service
+}
+
+// NewKnowledgeService 创建知识库服务实例，knowledgeService 为 nil 表示未启用知识库
+func NewKnowledgeService(knowledgeService *knowledge.Service) *KnowledgeService {
+	return &KnowledgeService{knowledge: knowledgeService}
+}
+
+// GetPage 获取知识库分页列表
+func (s *KnowledgeService) GetPage(req *KnowledgePageReq) (*model.PageResp, error) {
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+	limit, _ := pageLimit(req.PageNo, req.PageSize)
+	status := -1
+	if req.Status != nil {
+		status = *req.Status
+	}
+	list, total, err := s.knowledge.GetKnowledgeBases(max(req.PageNo, 1), limit, req.Name, status)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// Get 获取知识库详情
+func (s *KnowledgeService) Get(id uint) (*knowledge.KnowledgeBase, error) {
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+	kb, err := s.knowledge.GetKnowledgeBase(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKnowledgeBaseNotFound
+	}
+	return kb, err
+}
+
+// Create 创建知识库
+func (s *KnowledgeService) Create(req *KnowledgeSaveReq, operatorID uint) (uint, error) {
+	if s.knowledge == nil {
+		return 0, ErrKnowledgeDisabled
+	}
+	kb := &knowledge.KnowledgeBase{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		CreateBy:    operatorID,
+		UpdateBy:    operatorID,
+	}
+	if err := s.knowledge.CreateKnowledgeBase(kb); err != nil {
+		return 0, err
+	}
+	return kb.ID, nil
+}
+
+// Update 更新知识库
+func (s *KnowledgeService) Update(req *KnowledgeSaveReq, operatorID uint) error {
+	if s.knowledge == nil {
+		return ErrKnowledgeDisabled
+	}
+	err := s.knowledge.UpdateKnowledgeBase(&knowledge.KnowledgeBase{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		UpdateBy:    operatorID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrKnowledgeBaseNotFound
+	}
+	return err
+}
+
+// Delete 删除知识库及其全部文档
+func (s *KnowledgeService) Delete(ctx context.Context, id uint) error {
+	if s.knowledge == nil {
+		return ErrKnowledgeDisabled
+	}
+	err := s.knowledge.DeleteKnowledgeBase(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrKnowledgeBaseNotFound
+	}
+	return err
+}
+
+// GetDocumentPage 获取知识库文档分页列表
+func (s *KnowledgeService) GetDocumentPage(req *KnowledgeDocumentPageReq) (*model.PageResp, error) {
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+	limit, _ := pageLimit(req.PageNo, req.PageSize)
+	list, total, err := s.knowledge.GetDocuments(req.KnowledgeBaseID, max(req.PageNo, 1), limit, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// UploadDocument 上传文档到知识库并建立索引
+func (s *KnowledgeService) UploadDocument(ctx context.Context, knowledgeBaseID uint, file *multipart.FileHeader, operatorID uint) (*knowledge.Document, error) {
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, errors.New("打开文件失败")
+	}
+	defer src.Close()
+
+	doc, err := s.knowledge.UploadDocument(ctx, knowledgeBaseID, file.Filename, src, file.Header.Get("Content-Type"), operatorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKnowledgeBaseNotFound
+	}
+	return doc, err
+}
+
+// DeleteDocument 删除知识库文档
+func (s *KnowledgeService) DeleteDocument(ctx context.Context, id uint) error {
+	if s.knowledge == nil {
+		return ErrKnowledgeDisabled
+	}
+	err := s.knowledge.DeleteDocument(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrKnowledgeDocumentNotFound
+	}
+	return err
+}
+
+// Search 检索测试，返回问题命中的切片，用于调整文档和相似度阈值
+func (s *KnowledgeService) Search(ctx context.Context, req *KnowledgeSearchReq) ([]Citation, error) {
+	if s.knowledge == nil {
+		return nil, ErrKnowledgeDisabled
+	}
+	results, err := s.knowledge.Retrieve(ctx, req.KnowledgeBaseIDs, req.Query)
+	if err != nil {
+		return nil, wrapProviderErr(err)
+	}
+	return newCitations(results), nil
+}
+
+// newCitations 将检索结果转换为引用，编号从 1 开始
+func newCitations(results []knowledge.SearchResult) []Citation {
+	citations := make([]Citation, len(results))
+	for i, result := range results {
+		citations[i] = Citation{
+			Index:           i + 1,
+			KnowledgeBaseID: result.KnowledgeBaseID,
+			DocumentID:      result.DocumentID,
+			DocumentName:    result.DocumentName,
+			Content:         result.Content,
+			Score:           result.Score,
+		}
+	}
+	return citations
+}
+
+// buildKnowledgePrompt 将引用拼接为参考资料提示词，要求模型依据资料回答并标注来源编号
+func buildKnowledgePrompt(citations []Citation) string {
+	var builder strings.Builder
+	builder.WriteString("请根据以下参考资料回答用户的问题，并在引用处用 [编号] 标注来源。")
+	builder.WriteString("如果参考资料中没有相关内容，请说明资料中未提及，不要编造。\n\n参考资料：\n")
+	for _, citation := range citations {
+		fmt.Fprintf(&builder, "\n[%d] 《%s》\n%s\n", citation.Index, citation.DocumentName, citation.Content)
+	}
+	return builder.String()
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/knowledge"
+	"gin-admin-pro/plugin/vectorstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatWithKnowledge(t *testing.T) {
+	var received [][]map[string]interface{}
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, decodeMessages(t, r))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"发票需在一个月内提交 [1]"}}]}`)
+	})
+	ctx := context.Background()
+
+	// 未启用知识库时携带知识库ID报错
+	_, err := svc.Chat(ctx, 1, &ChatReq{Content: "报销发票要多久内提交？", KnowledgeBaseIDs: []uint{1}})
+	assert.ErrorIs(t, err, ErrKnowledgeDisabled)
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrServiceUnavailable.Code, e.Code)
+
+	engine := knowledge.NewEngine(aiplugin.NewHashEmbedder(512), vectorstore.NewMemoryStore(), &knowledge.Config{ChunkSize: 40, TopK: 2, MinScore: 0.1})
+	require.NoError(t, engine.Migrate(ctx))
+	_, err = engine.Index(ctx, &knowledge.Document{ID: 7, KnowledgeBaseID: 1, Name: "员工手册.md"},
+		"请假流程：员工请假需提前在系统提交申请，由部门负责人审批。\n\n报销流程：发票需在一个月内提交财务部审核。")
+	require.NoError(t, err)
+	svc.SetKnowledge(engine)
+
+	resp, err := svc.Chat(ctx, 1, &ChatReq{Content: "报销发票要多久内提交？", KnowledgeBaseIDs: []uint{1}})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Citations)
+	assert.Equal(t, 1, resp.Citations[0].Index)
+	assert.Equal(t, uint(7), resp.Citations[0].DocumentID)
+	assert.Contains(t, resp.Citations[0].Content, "报销流程")
+
+	// 参考资料作为系统消息插入在用户问题之前
+	require.Len(t, received, 1)
+	messages := received[0]
+	require.GreaterOrEqual(t, len(messages), 2)
+	reference := messages[len(messages)-2]
+	assert.Equal(t, "system", reference["role"])
+	assert.Contains(t, reference["content"], "[1] 《员工手册.md》")
+	assert.Equal(t, "报销发票要多久内提交？", messages[len(messages)-1]["content"])
+
+	// 参考资料不写入对话历史
+	conversation, err := svc.GetConversation(ctx, 1, resp.ConversationID)
+	require.NoError(t, err)
+	assert.Len(t, conversation.Messages, 2)
+}
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"gin-admin-pro/internal/pkg/jwt"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/mcp"
+
+	"gorm.io/gorm"
+)
+
+// MCPInstructions initialize 时返回给 MCP 客户端的使用说明
+const MCPInstructions = "后台管理系统的只读查询工具：用户、部门、角色、字典和操作日志。" +
+	"工具按当前用户的角色权限和数据权限执行，只能看到有权限的数据。"
+
+// ListTools 列出上下文中调用用户有权限使用的工具，实现 mcp.ToolProvider
+func (s *ToolService) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return nil, ErrToolCallerMissing
+	}
+
+	definitions := s.registry.Definitions(func(tool *aiplugin.Tool) bool {
+		return caller.HasPermission(tool.Permission)
+	})
+	tools := make([]mcp.Tool, len(definitions))
+	for i, definition := range definitions {
+		tools[i] = mcp.Tool{
+			Name:        definition.Name,
+			Description: definition.Description,
+			InputSchema: definition.Parameters,
+		}
+	}
+	return tools, nil
+}
+
+// CallTool 校验参数和权限后执行工具，实现 mcp.ToolProvider
+func (s *ToolService) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	if _, ok := s.registry.Get(name); !ok {
+		return nil, fmt.Errorf("%w: %s", mcp.ErrToolNotFound, name)
+	}
+
+	result := s.registry.Execute(ctx, &aiplugin.FunctionCall{Name: name, Arguments: args})
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	return result.Result, nil
+}
+
+// NewMCPAuthenticator 创建 MCP 认证：凭证为配置的 API Key 或登录令牌，
+// apiKeys 为 API Key 到绑定用户ID的映射，认证后按该用户的角色权限和数据权限执行工具；
+// 令牌过期或用户不存在时返回 mcp.ErrUnauthorized，用户已禁用时返回 mcp.ErrForbidden
+func NewMCPAuthenticator(tools *ToolService, apiKeys map[string]uint) mcp.Authenticator {
+	return func(ctx context.Context, credential string) (context.Context, error) {
+		userID, ok := matchAPIKey(apiKeys, credential)
+		if !ok {
+			claims, err := jwt.ParseToken(credential)
+			if err != nil {
+				return nil, mcp.ErrUnauthorized
+			}
+			userID = claims.UserID
+		}
+
+		caller, err := tools.loadCaller(userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, mcp.ErrUnauthorized
+		}
+		if errors.Is(err, ErrCallerDisabled) {
+			return nil, fmt.Errorf("%w: %w", mcp.ErrForbidden, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return WithCaller(ctx, caller), nil
+	}
+}
+
+// matchAPIKey 按常量时间比较查找 API Key 绑定的用户
+func matchAPIKey(apiKeys map[string]uint, credential string) (uint, bool) {
+	for key, userID := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(credential)) == 1 {
+			return userID, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-admin-pro/plugin/mcp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callMCP 以 API Key 调用 MCP 服务端，返回响应
+func callMCP(server *mcp.Server, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestMCPTools(t *testing.T) {
+	tools := newTestToolService(t)
+	server := mcp.NewServer(mcp.Implementation{Name: "test", Version: "1"}, tools, NewMCPAuthenticator(tools, map[string]uint{
+		"admin-key":  1,
+		"common-key": 2,
+	}))
+
+	// 只列出调用用户有权限的工具
+	w := callMCP(server, "admin-key", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Contains(t, w.Body.String(), `"count_users"`)
+	w = callMCP(server, "common-key", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.NotContains(t, w.Body.String(), `"count_users"`)
+	assert.Contains(t, w.Body.String(), `"now"`)
+
+	// 按 API Key 绑定的用户执行
+	w = callMCP(server, "admin-key", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"count_users","arguments":{"dept":"销售部"}}}`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{"content":[{"type":"text","text":"{\"caller\":1,\"dept\":\"销售部\",\"total\":2}"}]}}`, w.Body.String())
+
+	// 执行时再次校验权限，参数按 Schema 校验
+	w = callMCP(server, "common-key", `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"count_users","arguments":{}}}`)
+	assert.Contains(t, w.Body.String(), `"isError":true`)
+	assert.Contains(t, w.Body.String(), "没有权限：system:user:list")
+	w = callMCP(server, "admin-key", `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"count_users","arguments":{"dept":1}}}`)
+	assert.Contains(t, w.Body.String(), "invalid arguments")
+
+	w = callMCP(server, "admin-key", `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"drop_users"}}`)
+	assert.Contains(t, w.Body.String(), `"code":-32602`)
+}
+
+func TestMCPAuthenticator(t *testing.T) {
+	tools := newTestToolService(t)
+	authenticate := NewMCPAuthenticator(tools, map[string]uint{"key": 1})
+
+	ctx, err := authenticate(context.Background(), "key")
+	require.NoError(t, err)
+	caller, ok := CallerFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, uint(1), caller.UserID)
+
+	disabled := NewMCPAuthenticator(NewToolService(func(userID uint) (*Caller, error) {
+		return nil, ErrCallerDisabled
+	}), map[string]uint{"key": 3})
+	_, err = disabled(context.Background(), "key")
+	assert.ErrorIs(t, err, ErrCallerDisabled)
+	assert.ErrorIs(t, err, mcp.ErrForbidden)
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+)
+
+// ErrModelManageUnsupported 当前提供商不支持管理模型
+var ErrModelManageUnsupported = aiplugin.ErrModelManageUnsupported
+
+// 模型下载事件类型
+const (
+	EventProgress = "progress" // 下载进度
+)
+
+// ModelPullReq 模型下载请求
+type ModelPullReq struct {
+	Name string `json:"name" binding:"required"` // 模型名称，如 qwen2.5:7b
+}
+
+// ModelPullDone 模型下载完成
+type ModelPullDone struct {
+	Name string `json:"name"`
+}
+
+// providerHolder 可获取模型提供商的 AI 服务
+type providerHolder interface {
+	Provider() aiplugin.AIProvider
+}
+
+// providerHealthChecker 可检查提供商健康状态的 AI 服务
+type providerHealthChecker interface {
+	ProviderHealth(ctx context.Context) []aiplugin.ProviderHealth
+}
+
+// ModelService 模型管理服务层，下载和列出模型仅支持 Ollama 等可管理模型的提供商
+type ModelService struct {
+	aiService aiplugin.AIService
+}
+
+// NewModelService 创建模型管理服务实例，aiService 为 nil 表示未启用 AI
+func NewModelService(aiService aiplugin.AIService) *ModelService {
+	return &ModelService{aiService: aiService}
+}
+
+// List 列出已下载的模型
+func (s *ModelService) List(ctx context.Context) ([]aiplugin.LocalModel, error) {
+	manager, err := s.manager()
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := manager.ListModels(ctx)
+	if err != nil {
+		return nil, wrapProviderErr(err)
+	}
+	return models, nil
+}
+
+// Pull 下载模型并以事件流返回进度。下载耗时较长，ctx 取消（如客户端断开）时中断下载，
+// 再次下载会从断点继续
+func (s *ModelService) Pull(ctx context.Context, req *ModelPullReq) (<-chan StreamEvent, error) {
+	manager, err := s.manager()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+
+		send := func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		err := manager.PullModel(ctx, req.Name, func(progress *aiplugin.PullProgress) {
+			send(StreamEvent{Event: EventProgress, Data: progress})
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				send(StreamEvent{Event: EventError, Data: StreamError{Message: err.Error()}})
+			}
+			return
+		}
+		send(StreamEvent{Event: EventDone, Data: ModelPullDone{Name: req.Name}})
+	}()
+
+	return events, nil
+}
+
+// Health 检查各提供商的健康状态和熔断状态
+func (s *ModelService) Health(ctx context.Context) ([]aiplugin.ProviderHealth, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+
+	checker, ok := s.aiService.(providerHealthChecker)
+	if !ok {
+		return nil, ErrModelManageUnsupported
+	}
+	return checker.ProviderHealth(ctx), nil
+}
+
+// manager 获取当前提供商的模型管理能力
+func (s *ModelService) manager() (aiplugin.ModelManager, error) {
+	if s.aiService == nil {
+		return nil, ErrAIDisabled
+	}
+
+	holder, ok := s.aiService.(providerHolder)
+	if !ok {
+		return nil, ErrModelManageUnsupported
+	}
+	manager, ok := holder.Provider().(aiplugin.ModelManager)
+	if !ok {
+		return nil, ErrModelManageUnsupported
+	}
+	return manager, nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"qwen2.5:7b","size":4683087332,"details":{"family":"qwen2"}}]}`)
+		case "/api/pull":
+			fmt.Fprint(w, "{\"status\":\"pulling manifest\"}\n{\"status\":\"success\"}\n")
+		}
+	}))
+	defer server.Close()
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.Provider = "ollama"
+	cfg.BaseURL = server.URL
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+	svc := NewModelService(aiService)
+	ctx := context.Background()
+
+	models, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "qwen2.5:7b", models[0].Name)
+
+	events, err := svc.Pull(ctx, &ModelPullReq{Name: "qwen2.5:7b"})
+	require.NoError(t, err)
+	var names []string
+	for event := range events {
+		names = append(names, event.Event)
+	}
+	assert.Equal(t, []string{EventProgress, EventProgress, EventDone}, names)
+}
+
+func TestModelServiceUnsupported(t *testing.T) {
+	_, aiService := newTestService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := NewModelService(aiService).List(context.Background())
+	assert.ErrorIs(t, err, ErrModelManageUnsupported)
+
+	_, err = NewModelService(nil).List(context.Background())
+	assert.ErrorIs(t, err, ErrAIDisabled)
+}
+
+func TestModelServiceHealth(t *testing.T) {
+	_, aiService := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	health, err := NewModelService(aiService).Health(context.Background())
+	require.NoError(t, err)
+	require.Len(t, health, 1)
+	assert.Equal(t, "openai", health[0].Provider)
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, aiplugin.CircuitClosed, health[0].Circuit)
+}
@@ -0,0 +1,319 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/plugin/prompt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPromptDisabled 提示词管理未启用
+	ErrPromptDisabled = errors.New("提示词管理未启用")
+	// ErrPromptTemplateNotFound 提示词模板不存在
+	ErrPromptTemplateNotFound = errors.New("提示词模板不存在")
+	// ErrAssistantNotFound 助手不存在
+	ErrAssistantNotFound = errors.New("助手不存在")
+)
+
+// PromptTemplatePageReq 提示词模板分页请求
+type PromptTemplatePageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Name     string `form:"name"`
+	Status   *int   `form:"status"`
+}
+
+// PromptTemplateCreateReq 提示词模板创建请求，内容作为第 1 个版本发布
+type PromptTemplateCreateReq struct {
+	Name        string            `json:"name" binding:"required,max=100"`
+	Description string            `json:"description" binding:"max=500"`
+	Content     string            `json:"content" binding:"required"`
+	Variables   []prompt.Variable `json:"variables"` // 变量定义，内容中引用但未定义的变量自动补充为可选变量
+	Status      int               `json:"status" binding:"oneof=0 1"`
+	Remark      string            `json:"remark" binding:"max=500"` // 版本说明
+}
+
+// PromptTemplateUpdateReq 提示词模板更新请求，内容通过发布新版本修改
+type PromptTemplateUpdateReq struct {
+	ID          uint   `json:"id" binding:"required"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Status      int    `json:"status" binding:"oneof=0 1"`
+}
+
+// PromptPublishReq 发布提示词模板新版本请求
+type PromptPublishReq struct {
+	ID        uint              `json:"id" binding:"required"` // 模板ID
+	Content   string            `json:"content" binding:"required"`
+	Variables []prompt.Variable `json:"variables"`
+	Remark    string            `json:"remark" binding:"max=500"`
+}
+
+// PromptActivateReq 切换提示词模板当前版本请求
+type PromptActivateReq struct {
+	ID      uint `json:"id" binding:"required"`      // 模板ID
+	Version int  `json:"version" binding:"required"` // 版本号
+}
+
+// PromptPreviewReq 提示词渲染预览请求
+type PromptPreviewReq struct {
+	Content   string            `json:"content" binding:"required"`
+	Variables []prompt.Variable `json:"variables"`
+	Values    map[string]string `json:"values"`
+}
+
+// AssistantPageReq 助手分页请求
+type AssistantPageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Name     string `form:"name"`
+	Status   *int   `form:"status"`
+}
+
+// AssistantSaveReq 助手创建/更新请求
+type AssistantSaveReq struct {
+	ID               uint              `json:"id"`
+	Name             string            `json:"name" binding:"required,max=100"`
+	Description      string            `json:"description" binding:"max=500"`
+	Avatar           string            `json:"avatar" binding:"max=512"`
+	TemplateID       uint              `json:"templateId" binding:"required"`
+	TemplateVersion  int               `json:"templateVersion" binding:"min=0"` // 固定使用的模板版本，0 表示跟随当前版本
+	Variables        map[string]string `json:"variables"`
+	Model            string            `json:"model" binding:"max=100"`
+	Temperature      float64           `json:"temperature" binding:"omitempty,min=0,max=2"`
+	MaxTokens        int               `json:"maxTokens" binding:"omitempty,min=1"`
+	Tools            []string          `json:"tools"` // 可调用的工具名称
+	KnowledgeBaseIDs []uint            `json:"knowledgeBaseIds"`
+	Status           int               `json:"status" binding:"oneof=0 1"`
+}
+
+// AssistantResolver 助手解析，对话时按助手ID获取提示词、模型、工具和知识库
+type AssistantResolver interface {
+	ResolveAssistant(ctx context.Context, id uint, values map[string]string) (*prompt.ResolvedAssistant, error)
+}
+
+// PromptService 提示词模板和助手服务层
+type PromptService struct {
+	prompt *prompt.Service
+}
+
+// NewPromptService 创建提示词服务实例，promptService 为 nil 表示未启用 AI
+func NewPromptService(promptService *prompt.Service) *PromptService {
+	return &PromptService{prompt: promptService}
+}
+
+// GetTemplatePage 获取提示词模板分页列表
+func (s *PromptService) GetTemplatePage(req *PromptTemplatePageReq) (*model.PageResp, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	limit, _ := pageLimit(req.PageNo, req.PageSize)
+	status := -1
+	if req.Status != nil {
+		status = *req.Status
+	}
+	list, total, err := s.prompt.GetTemplates(max(req.PageNo, 1), limit, req.Name, status)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetTemplate 获取提示词模板详情
+func (s *PromptService) GetTemplate(id uint) (*prompt.Template, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	template, err := s.prompt.GetTemplate(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromptTemplateNotFound
+	}
+	return template, err
+}
+
+// CreateTemplate 创建提示词模板
+func (s *PromptService) CreateTemplate(req *PromptTemplateCreateReq, operatorID uint) (uint, error) {
+	if s.prompt == nil {
+		return 0, ErrPromptDisabled
+	}
+	template := &prompt.Template{
+		Name:        req.Name,
+		Description: req.Description,
+		Content:     req.Content,
+		Variables:   req.Variables,
+		Status:      req.Status,
+		CreateBy:    operatorID,
+		UpdateBy:    operatorID,
+	}
+	if err := s.prompt.CreateTemplate(template, req.Remark); err != nil {
+		return 0, err
+	}
+	return template.ID, nil
+}
+
+// UpdateTemplate 更新提示词模板
+func (s *PromptService) UpdateTemplate(req *PromptTemplateUpdateReq, operatorID uint) error {
+	if s.prompt == nil {
+		return ErrPromptDisabled
+	}
+	err := s.prompt.UpdateTemplate(&prompt.Template{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		UpdateBy:    operatorID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromptTemplateNotFound
+	}
+	return err
+}
+
+// DeleteTemplate 删除提示词模板及其全部版本
+func (s *PromptService) DeleteTemplate(id uint) error {
+	if s.prompt == nil {
+		return ErrPromptDisabled
+	}
+	err := s.prompt.DeleteTemplate(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromptTemplateNotFound
+	}
+	return err
+}
+
+// GetVersions 获取提示词模板的全部版本
+func (s *PromptService) GetVersions(templateID uint) ([]prompt.TemplateVersion, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	if _, err := s.GetTemplate(templateID); err != nil {
+		return nil, err
+	}
+	return s.prompt.GetVersions(templateID)
+}
+
+// Publish 发布提示词模板新版本并立即生效
+func (s *PromptService) Publish(req *PromptPublishReq, operatorID uint) (*prompt.TemplateVersion, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	version, err := s.prompt.PublishVersion(req.ID, req.Content, req.Variables, req.Remark, operatorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromptTemplateNotFound
+	}
+	return version, err
+}
+
+// Activate 切换提示词模板当前版本
+func (s *PromptService) Activate(req *PromptActivateReq, operatorID uint) error {
+	if s.prompt == nil {
+		return ErrPromptDisabled
+	}
+	err := s.prompt.ActivateVersion(req.ID, req.Version, operatorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromptTemplateNotFound
+	}
+	return err
+}
+
+// Preview 按变量值渲染提示词，用于发布前检查
+func (s *PromptService) Preview(req *PromptPreviewReq) (string, error) {
+	return prompt.Render(req.Content, prompt.MergeVariables(req.Content, req.Variables), req.Values)
+}
+
+// GetAssistantPage 获取助手分页列表
+func (s *PromptService) GetAssistantPage(req *AssistantPageReq) (*model.PageResp, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	limit, _ := pageLimit(req.PageNo, req.PageSize)
+	status := -1
+	if req.Status != nil {
+		status = *req.Status
+	}
+	list, total, err := s.prompt.GetAssistants(max(req.PageNo, 1), limit, req.Name, status)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetAssistant 获取助手详情
+func (s *PromptService) GetAssistant(id uint) (*prompt.Assistant, error) {
+	if s.prompt == nil {
+		return nil, ErrPromptDisabled
+	}
+	assistant, err := s.prompt.GetAssistant(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAssistantNotFound
+	}
+	return assistant, err
+}
+
+// CreateAssistant 创建助手
+func (s *PromptService) CreateAssistant(req *AssistantSaveReq, operatorID uint) (uint, error) {
+	if s.prompt == nil {
+		return 0, ErrPromptDisabled
+	}
+	assistant := newAssistant(req, operatorID)
+	assistant.CreateBy = operatorID
+	err := s.prompt.CreateAssistant(assistant)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrPromptTemplateNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return assistant.ID, nil
+}
+
+// UpdateAssistant 更新助手
+func (s *PromptService) UpdateAssistant(req *AssistantSaveReq, operatorID uint) error {
+	if s.prompt == nil {
+		return ErrPromptDisabled
+	}
+	if _, err := s.GetAssistant(req.ID); err != nil {
+		return err
+	}
+	err := s.prompt.UpdateAssistant(newAssistant(req, operatorID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromptTemplateNotFound
+	}
+	return err
+}
+
+// DeleteAssistant 删除助手
+func (s *PromptService) DeleteAssistant(id uint) error {
+	if s.prompt == nil {
+		return ErrPromptDisabled
+	}
+	err := s.prompt.DeleteAssistant(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAssistantNotFound
+	}
+	return err
+}
+
+// newAssistant 将请求转换为助手
+func newAssistant(req *AssistantSaveReq, operatorID uint) *prompt.Assistant {
+	return &prompt.Assistant{
+		ID:               req.ID,
+		Name:             req.Name,
+		Description:      req.Description,
+		Avatar:           req.Avatar,
+		TemplateID:       req.TemplateID,
+		TemplateVersion:  req.TemplateVersion,
+		Variables:        req.Variables,
+		Model:            req.Model,
+		Temperature:      req.Temperature,
+		MaxTokens:        req.MaxTokens,
+		Tools:            req.Tools,
+		KnowledgeBaseIDs: req.KnowledgeBaseIDs,
+		Status:           req.Status,
+		UpdateBy:         operatorID,
+	}
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gin-admin-pro/plugin/prompt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// assistantStub 按固定模板渲染的助手解析
+type assistantStub struct {
+	assistant *prompt.Assistant
+	content   string
+	variables []prompt.Variable
+}
+
+func (a *assistantStub) ResolveAssistant(ctx context.Context, id uint, values map[string]string) (*prompt.ResolvedAssistant, error) {
+	if id != a.assistant.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	systemPrompt, err := prompt.Render(a.content, a.variables, values)
+	if err != nil {
+		return nil, err
+	}
+	return &prompt.ResolvedAssistant{Assistant: a.assistant, SystemPrompt: systemPrompt, Version: 1}, nil
+}
+
+func TestChatWithAssistant(t *testing.T) {
+	type request struct {
+		Model    string                   `json:"model"`
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	var received []request
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		var body request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = append(received, body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+	ctx := context.Background()
+
+	// 未设置助手解析时不能指定助手
+	_, err := svc.Chat(ctx, 1, &ChatReq{Content: "你好", AssistantID: 7})
+	assert.ErrorIs(t, err, ErrPromptDisabled)
+
+	stub := &assistantStub{
+		assistant: &prompt.Assistant{ID: 7, Model: "gpt-4o-mini", Status: 1},
+		content:   "你是{{company}}的{{role}}。",
+		variables: []prompt.Variable{{Name: "company", Required: true}, {Name: "role", Default: "客服"}},
+	}
+	svc.SetAssistants(stub)
+
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "你好", AssistantID: 8})
+	assert.ErrorIs(t, err, ErrAssistantNotFound)
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "你好", AssistantID: 7})
+	assert.ErrorIs(t, err, prompt.ErrMissingVariable)
+	require.Empty(t, received)
+
+	// 使用助手的系统提示词和模型
+	first, err := svc.Chat(ctx, 1, &ChatReq{Content: "你好", AssistantID: 7, Variables: map[string]string{"company": "示例科技"}})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, "gpt-4o-mini", received[0].Model)
+	assert.Equal(t, "system", received[0].Messages[0]["role"])
+	assert.Equal(t, "你是示例科技的客服。", received[0].Messages[0]["content"])
+
+	// 后续消息沿用对话的助手和变量，请求中的变量覆盖同名变量
+	_, err = svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "继续", Variables: map[string]string{"role": "顾问"}})
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	assert.Equal(t, "你是示例科技的顾问。", received[1].Messages[0]["content"])
+
+	// 请求指定的模型优先
+	_, err = svc.Chat(ctx, 1, &ChatReq{ConversationID: first.ConversationID, Content: "再来", Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", received[2].Model)
+	assert.Equal(t, "你是示例科技的客服。", received[2].Messages[0]["content"])
+
+	// 不使用助手的对话使用默认系统提示词
+	_, err = svc.Chat(ctx, 1, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+	assert.Equal(t, "You are a helpful AI assistant.", received[3].Messages[0]["content"])
+}
@@ -0,0 +1,383 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	systemdao "gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/model/system"
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/dataperm"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/operlog"
+
+	"gorm.io/gorm"
+)
+
+// 内置工具返回的最大记录数，避免结果过长占满上下文
+const (
+	toolDefaultLimit = 10
+	toolMaxLimit     = 50
+)
+
+// 工具结果中翻译为标签的字典类型
+const (
+	statusDictType    = "system_status"
+	dataScopeDictType = "data_scope"
+)
+
+// toolDateLayout 工具日期参数的格式
+const toolDateLayout = "2006-01-02"
+
+// systemTools 系统管理内置工具，查询时按调用用户的数据权限过滤
+type systemTools struct {
+	userDAO  *systemdao.UserDAO
+	deptDAO  *systemdao.DeptDAO
+	roleDAO  *systemdao.RoleDAO
+	operLog  *operlog.Service
+	dataPerm *dataperm.Service
+	dict     *dict.Service
+}
+
+// RegisterSystemTools 注册系统管理内置工具：查询用户、部门、角色、字典标签和操作日志
+func RegisterSystemTools(tools *ToolService, db *gorm.DB, dictService *dict.Service) error {
+	t := &systemTools{
+		userDAO:  systemdao.NewUserDAO(db),
+		deptDAO:  systemdao.NewDeptDAO(db),
+		roleDAO:  systemdao.NewRoleDAO(db),
+		operLog:  operlog.NewService(db, nil),
+		dataPerm: dataperm.NewService(db),
+		dict:     dictService,
+	}
+
+	for _, tool := range []*aiplugin.Tool{
+		{
+			Name:        "query_users",
+			Description: "查询用户列表和数量。可按用户名、昵称、部门名称（包含下级部门）和状态筛选，只返回当前用户数据权限范围内的用户。只需要数量时 limit 传 0",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"username":  map[string]interface{}{"type": "string", "description": "用户名，模糊匹配"},
+					"nickname":  map[string]interface{}{"type": "string", "description": "昵称/姓名，模糊匹配"},
+					"dept_name": map[string]interface{}{"type": "string", "description": "部门名称，模糊匹配，包含下级部门"},
+					"status":    map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1}, "description": "状态：0-禁用 1-启用"},
+					"limit":     map[string]interface{}{"type": "integer", "description": "返回的用户数，默认 10，最多 50"},
+				},
+			},
+			Permission: "system:user:list",
+			Handler:    t.queryUsers,
+		},
+		{
+			Name:        "list_departments",
+			Description: "查询部门列表，可按名称和状态筛选，只返回当前用户数据权限范围内的部门",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":   map[string]interface{}{"type": "string", "description": "部门名称，模糊匹配"},
+					"status": map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1}, "description": "状态：0-禁用 1-启用"},
+				},
+			},
+			Permission: "system:dept:list",
+			Handler:    t.listDepartments,
+		},
+		{
+			Name:        "get_dict_label",
+			Description: "查询字典标签，如 system_status 的 0 表示禁用。指定 value 时返回对应标签，否则返回该字典类型的全部值和标签",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"dict_type": map[string]interface{}{"type": "string", "description": "字典类型，如 system_status"},
+					"value":     map[string]interface{}{"type": "string", "description": "字典值"},
+				},
+				"required": []string{"dict_type"},
+			},
+			Handler: t.getDictLabel,
+		},
+		{
+			Name:        "list_roles",
+			Description: "查询角色列表，可按名称、编码和状态筛选，返回角色的数据权限范围",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":   map[string]interface{}{"type": "string", "description": "角色名称，模糊匹配"},
+					"code":   map[string]interface{}{"type": "string", "description": "角色编码，模糊匹配"},
+					"status": map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1}, "description": "状态：0-禁用 1-启用"},
+					"limit":  map[string]interface{}{"type": "integer", "description": "返回的角色数，默认 10，最多 50"},
+				},
+			},
+			Permission: "system:role:list",
+			Handler:    t.listRoles,
+		},
+		{
+			Name:        "search_operation_logs",
+			Description: "按操作时间倒序查询操作日志，可按模块、操作人、业务类型、状态和日期范围筛选。只需要数量时 limit 传 0",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title":         map[string]interface{}{"type": "string", "description": "操作模块，模糊匹配"},
+					"oper_name":     map[string]interface{}{"type": "string", "description": "操作人员用户名，模糊匹配"},
+					"business_type": map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1, 2, 3, 4, 5, 6}, "description": "业务类型：0-其它 1-新增 2-修改 3-删除 4-授权 5-导出 6-导入"},
+					"status":        map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1}, "description": "操作状态：0-正常 1-异常"},
+					"start_date":    map[string]interface{}{"type": "string", "description": "开始日期，格式 YYYY-MM-DD"},
+					"end_date":      map[string]interface{}{"type": "string", "description": "结束日期，格式 YYYY-MM-DD，包含当天"},
+					"limit":         map[string]interface{}{"type": "integer", "description": "返回的日志数，默认 10，最多 50"},
+				},
+			},
+			Permission: "monitor:operlog:list",
+			Handler:    t.searchOperationLogs,
+		},
+	} {
+		if err := tools.Register(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryUsers 查询用户
+func (t *systemTools) queryUsers(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return nil, ErrToolCallerMissing
+	}
+
+	req := &systemdao.UserPageReq{
+		PageReq:  model.PageReq{PageNo: 1, PageSize: limitArg(args)},
+		Username: stringArg(args, "username"),
+		Nickname: stringArg(args, "nickname"),
+		Status:   intArg(args, "status"),
+		Scopes: []func(*gorm.DB) *gorm.DB{func(db *gorm.DB) *gorm.DB {
+			return t.dataPerm.BuildDataScopeSQL(db, caller.UserID, "dept_id", "id")
+		}},
+	}
+
+	if deptName := stringArg(args, "dept_name"); deptName != "" {
+		depts, err := t.visibleDepts(caller, &systemdao.DeptListReq{Name: deptName})
+		if err != nil {
+			return nil, err
+		}
+		if len(depts) == 0 {
+			return map[string]interface{}{"total": 0, "message": "没有找到名称包含「" + deptName + "」的部门"}, nil
+		}
+		deptIDs := make([]uint, len(depts))
+		for i, dept := range depts {
+			deptIDs[i] = dept.ID
+		}
+		if req.DeptIDs, err = t.deptDAO.GetChildIDs(deptIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	users, total, err := t.userDAO.GetPage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(users))
+	for i, user := range users {
+		result[i] = map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Username,
+			"nickname": user.Nickname,
+			"deptName": user.DeptName,
+			"status":   t.statusLabel(user.Status),
+		}
+	}
+	return map[string]interface{}{"total": total, "users": result}, nil
+}
+
+// listDepartments 查询部门
+func (t *systemTools) listDepartments(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return nil, ErrToolCallerMissing
+	}
+
+	depts, err := t.visibleDepts(caller, &systemdao.DeptListReq{
+		Name:   stringArg(args, "name"),
+		Status: intArg(args, "status"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(depts))
+	for i, dept := range depts {
+		result[i] = map[string]interface{}{
+			"id":       dept.ID,
+			"name":     dept.Name,
+			"parentId": dept.ParentID,
+			"status":   t.statusLabel(dept.Status),
+		}
+	}
+	return map[string]interface{}{"total": len(result), "departments": result}, nil
+}
+
+// getDictLabel 查询字典标签
+func (t *systemTools) getDictLabel(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	dictType := stringArg(args, "dict_type")
+	if value := stringArg(args, "value"); value != "" {
+		label, err := t.dict.GetDictLabelByValue(dictType, value)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return map[string]interface{}{"message": "字典中没有该值"}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value, "label": label}, nil
+	}
+
+	data, err := t.dict.GetDictDataByType(dictType)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]map[string]string, len(data))
+	for i, item := range data {
+		items[i] = map[string]string{"value": item.Value, "label": item.Label}
+	}
+	return map[string]interface{}{"dictType": dictType, "items": items}, nil
+}
+
+// listRoles 查询角色
+func (t *systemTools) listRoles(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	roles, total, err := t.roleDAO.GetPage(&systemdao.RolePageReq{
+		PageReq: model.PageReq{PageNo: 1, PageSize: limitArg(args)},
+		Name:    stringArg(args, "name"),
+		Code:    stringArg(args, "code"),
+		Status:  intArg(args, "status"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(roles))
+	for i, role := range roles {
+		result[i] = map[string]interface{}{
+			"id":        role.ID,
+			"code":      role.Code,
+			"name":      role.Name,
+			"dataScope": t.dictLabel(dataScopeDictType, role.DataScope),
+			"status":    t.statusLabel(role.Status),
+			"remark":    role.Remark,
+		}
+	}
+	return map[string]interface{}{"total": total, "roles": result}, nil
+}
+
+// searchOperationLogs 查询操作日志，不返回请求参数和响应内容
+func (t *systemTools) searchOperationLogs(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	startDate, endDate := stringArg(args, "start_date"), stringArg(args, "end_date")
+	var operTime string
+	if startDate != "" || endDate != "" {
+		if startDate == "" {
+			startDate = time.Unix(0, 0).Format(toolDateLayout)
+		}
+		if endDate == "" {
+			endDate = time.Now().Format(toolDateLayout)
+		}
+		for _, date := range []string{startDate, endDate} {
+			if _, err := time.Parse(toolDateLayout, date); err != nil {
+				return nil, fmt.Errorf("日期 %s 格式错误，应为 YYYY-MM-DD", date)
+			}
+		}
+		operTime = startDate + "," + endDate
+	}
+
+	var businessType, status string
+	if value := intArg(args, "business_type"); value != nil {
+		businessType = strconv.Itoa(*value)
+	}
+	if value := intArg(args, "status"); value != nil {
+		status = strconv.Itoa(*value)
+	}
+
+	logs, total, err := t.operLog.GetOperLogs(1, limitArg(args), stringArg(args, "title"), stringArg(args, "oper_name"), businessType, status, operTime)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(logs))
+	for i, log := range logs {
+		result[i] = map[string]interface{}{
+			"id":           log.ID,
+			"title":        log.Title,
+			"businessType": operlog.GetBusinessTypeName(log.BusinessType),
+			"operName":     log.OperName,
+			"deptName":     log.DeptName,
+			"method":       log.RequestMethod,
+			"url":          log.OperUrl,
+			"ip":           log.OperIp,
+			"status":       operlog.GetStatusName(log.Status),
+			"errorMsg":     log.ErrorMsg,
+			"operTime":     log.OperTime,
+			"costTime":     log.CostTime,
+		}
+	}
+	return map[string]interface{}{"total": total, "logs": result}, nil
+}
+
+// visibleDepts 查询调用用户数据权限范围内的部门，仅本人数据权限时只能看到本部门
+func (t *systemTools) visibleDepts(caller *Caller, req *systemdao.DeptListReq) ([]system.Dept, error) {
+	scope, err := t.dataPerm.GetDataScope(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scope {
+	case dataperm.DataScopeAll:
+	case dataperm.DataScopeSelf:
+		req.IDs = []uint{caller.DeptID}
+	default:
+		ids, err := t.dataPerm.GetDataScopeDeptIDs(caller.UserID)
+		if err != nil {
+			return nil, err
+		}
+		req.IDs = append([]uint{}, ids...)
+	}
+	return t.deptDAO.GetFlatList(req)
+}
+
+// statusLabel 状态值转换为字典标签
+func (t *systemTools) statusLabel(status int) string {
+	return t.dictLabel(statusDictType, status)
+}
+
+// dictLabel 字典值转换为标签，字典中没有该值时返回原值
+func (t *systemTools) dictLabel(dictType string, value int) string {
+	text := strconv.Itoa(value)
+	if t.dict == nil {
+		return text
+	}
+	if label, err := t.dict.GetDictLabelByValue(dictType, text); err == nil {
+		return label
+	}
+	return text
+}
+
+// stringArg 读取字符串参数
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// limitArg 读取返回记录数参数，未传时为默认值，超出上限时取上限
+func limitArg(args map[string]interface{}) int {
+	if value, ok := args["limit"].(float64); ok {
+		return min(max(int(value), 0), toolMaxLimit)
+	}
+	return toolDefaultLimit
+}
+
+// intArg 读取整数参数，未传时返回 nil
+func intArg(args map[string]interface{}, name string) *int {
+	value, ok := args[name].(float64)
+	if !ok {
+		return nil
+	}
+	result := int(value)
+	return &result
+}
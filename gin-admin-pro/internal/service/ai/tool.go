@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	systemdao "gin-admin-pro/internal/dao/system"
+	systemservice "gin-admin-pro/internal/service/system"
+	aiplugin "gin-admin-pro/plugin/ai"
+)
+
+var (
+	// ErrToolCallerMissing 工具执行时上下文中没有调用用户
+	ErrToolCallerMissing = errors.New("未获取到当前用户，无法执行工具")
+	// ErrCallerDisabled 调用用户已被禁用
+	ErrCallerDisabled = systemservice.ErrUserDisabled
+)
+
+// Caller 调用工具的用户，工具按其角色权限和数据权限执行
+type Caller = systemservice.UserPermissions
+
+// CallerLoader 根据用户ID加载调用工具的用户
+type CallerLoader func(userID uint) (*Caller, error)
+
+// NewCallerLoader 创建从数据库加载用户角色和菜单权限标识的 CallerLoader，用户已禁用时返回 ErrCallerDisabled
+func NewCallerLoader(userDAO *systemdao.UserDAO) CallerLoader {
+	return CallerLoader(systemservice.NewPermissionLoader(userDAO))
+}
+
+// callerKey 上下文中调用用户的键
+type callerKey struct{}
+
+// WithCaller 将调用用户放入上下文，工具处理函数通过 CallerFromContext 获取
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext 获取上下文中的调用用户
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok
+}
+
+// ToolService AI 工具服务，只向模型提供当前用户有权限的工具，执行时再次校验权限
+type ToolService struct {
+	registry   *aiplugin.ToolRegistry
+	loadCaller CallerLoader
+}
+
+// NewToolService 创建 AI 工具服务实例
+func NewToolService(loadCaller CallerLoader) *ToolService {
+	return &ToolService{
+		registry:   aiplugin.NewToolRegistry(),
+		loadCaller: loadCaller,
+	}
+}
+
+// Registry 工具注册表，需设置到 AI 服务中才会执行工具
+func (s *ToolService) Registry() *aiplugin.ToolRegistry {
+	return s.registry
+}
+
+// Register 注册工具，执行前校验调用用户的权限标识
+func (s *ToolService) Register(tool *aiplugin.Tool) error {
+	handler := tool.Handler
+	permission := tool.Permission
+	tool.Handler = func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		caller, ok := CallerFromContext(ctx)
+		if !ok {
+			return nil, ErrToolCallerMissing
+		}
+		if !caller.HasPermission(permission) {
+			return nil, fmt.Errorf("没有权限：%s", permission)
+		}
+		return handler(ctx, args)
+	}
+	return s.registry.Register(tool)
+}
+
+// prepare 加载当前用户，返回携带用户的上下文和其有权限使用的工具定义
+func (s *ToolService) prepare(ctx context.Context, userID uint) (context.Context, []aiplugin.FunctionDefinition, error) {
+	caller, err := s.loadCaller(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definitions := s.registry.Definitions(func(tool *aiplugin.Tool) bool {
+		return caller.HasPermission(tool.Permission)
+	})
+	return WithCaller(ctx, caller), definitions, nil
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newToolTestService 创建启用工具的对话服务，handler 接收请求声明的工具名称
+func newToolTestService(t *testing.T, tools *ToolService, handler func(w http.ResponseWriter, toolNames []string, messages []map[string]interface{})) *ChatService {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+			Tools    []struct {
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tools"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		names := make([]string, 0, len(body.Tools))
+		for _, tool := range body.Tools {
+			names = append(names, tool.Function.Name)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, names, body.Messages)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := aiplugin.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = server.URL
+	cfg.EnableFunctionCalling = true
+	aiService, err := aiplugin.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	aiService.SetToolRegistry(tools.Registry())
+	require.NoError(t, aiService.Initialize(cfg))
+
+	svc := NewChatService(aiService)
+	svc.SetTools(tools)
+	return svc
+}
+
+// newTestToolService 注册按部门统计用户的工具和无需权限的工具，用户 1 为管理员，用户 2 无权限
+func newTestToolService(t *testing.T) *ToolService {
+	tools := NewToolService(func(userID uint) (*Caller, error) {
+		if userID == 1 {
+			return &Caller{UserID: 1, Roles: []string{"admin"}}, nil
+		}
+		return &Caller{UserID: userID, Roles: []string{"common"}, Permissions: []string{"system:dept:list"}}, nil
+	})
+	require.NoError(t, tools.Register(&aiplugin.Tool{
+		Name:       "count_users",
+		Permission: "system:user:list",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"dept": map[string]interface{}{"type": "string"}},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			caller, _ := CallerFromContext(ctx)
+			return map[string]interface{}{"dept": args["dept"], "total": 2, "caller": caller.UserID}, nil
+		},
+	}))
+	require.NoError(t, tools.Register(&aiplugin.Tool{
+		Name: "now",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "2024-01-01", nil
+		},
+	}))
+	return tools
+}
+
+func TestCallerHasPermission(t *testing.T) {
+	caller := &Caller{Roles: []string{"common"}, Permissions: []string{"system:user:list"}}
+	assert.True(t, caller.HasPermission(""))
+	assert.True(t, caller.HasPermission("system:user:list"))
+	assert.False(t, caller.HasPermission("system:dept:list"))
+
+	assert.True(t, (&Caller{Roles: []string{"super_admin"}}).HasPermission("system:dept:list"))
+	assert.True(t, (&Caller{Permissions: []string{"*:*:*"}}).HasPermission("system:dept:list"))
+}
+
+func TestChatWithTools(t *testing.T) {
+	var offered [][]string
+	svc := newToolTestService(t, newTestToolService(t), func(w http.ResponseWriter, toolNames []string, messages []map[string]interface{}) {
+		offered = append(offered, toolNames)
+		if messages[len(messages)-1]["role"] == "tool" {
+			assert.JSONEq(t, `{"dept":"销售部","total":2,"caller":1}`, messages[len(messages)-1]["content"].(string))
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"销售部有 2 个禁用用户"}}],"usage":{"total_tokens":20}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"count_users","arguments":"{\"dept\":\"销售部\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"total_tokens":10}}`)
+	})
+
+	resp, err := svc.Chat(context.Background(), 1, &ChatReq{Content: "销售部有多少禁用用户"})
+	require.NoError(t, err)
+	assert.Equal(t, "销售部有 2 个禁用用户", resp.Content)
+	assert.Equal(t, 30, resp.Usage.TotalTokens)
+	require.Len(t, resp.ToolSteps, 1)
+	assert.Equal(t, "count_users", resp.ToolSteps[0].Name)
+	assert.Equal(t, "销售部", resp.ToolSteps[0].Arguments["dept"])
+	assert.Equal(t, [][]string{{"count_users", "now"}, {"count_users", "now"}}, offered)
+
+	// 只保存最终回复，不保存工具调用过程
+	conversation, err := svc.GetConversation(context.Background(), 1, resp.ConversationID)
+	require.NoError(t, err)
+	assert.Len(t, conversation.Messages, 2)
+}
+
+func TestChatWithToolsPermission(t *testing.T) {
+	var offered []string
+	svc := newToolTestService(t, newTestToolService(t), func(w http.ResponseWriter, toolNames []string, messages []map[string]interface{}) {
+		offered = toolNames
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"没有权限查询用户"}}]}`)
+	})
+
+	// 无权限的工具不提供给模型
+	resp, err := svc.Chat(context.Background(), 2, &ChatReq{Content: "销售部有多少用户"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"now"}, offered)
+	assert.Empty(t, resp.ToolSteps)
+}
+
+func TestToolServicePermissionCheck(t *testing.T) {
+	tools := newTestToolService(t)
+
+	// 执行时再次校验权限，模型伪造未提供的工具调用也不会执行
+	ctx := WithCaller(context.Background(), &Caller{UserID: 2})
+	result := tools.Registry().Execute(ctx, &aiplugin.FunctionCall{Name: "count_users"})
+	assert.Contains(t, result.Error, "没有权限")
+
+	result = tools.Registry().Execute(context.Background(), &aiplugin.FunctionCall{Name: "now"})
+	assert.Contains(t, result.Error, ErrToolCallerMissing.Error())
+}
@@ -0,0 +1,327 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/model/system"
+	"gin-admin-pro/plugin/aiusage"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUsageDisabled AI 用量统计未启用
+	ErrUsageDisabled = errors.New("AI 用量统计未启用")
+	// ErrBudgetNotFound 部门预算不存在
+	ErrBudgetNotFound = errors.New("部门预算不存在")
+	// ErrDeptNotFound 部门不存在
+	ErrDeptNotFound = errors.New("部门不存在")
+	// ErrInvalidDateRange 开始日期晚于结束日期
+	ErrInvalidDateRange = errors.New("开始日期不能晚于结束日期")
+)
+
+// dateLayout 查询参数中的日期格式
+const dateLayout = "2006-01-02"
+
+// UsageSummaryReq 用量汇总请求，日期范围包含首尾两天
+type UsageSummaryReq struct {
+	GroupBy   string `form:"groupBy" binding:"required,oneof=user dept model"`
+	Period    string `form:"period" binding:"omitempty,oneof=day month"`
+	StartDate string `form:"startDate" binding:"omitempty,datetime=2006-01-02"` // 为空时按天统计近 30 天、按月统计近 12 个月
+	EndDate   string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`   // 为空时截至今天
+	UserID    uint   `form:"userId"`
+	DeptID    uint   `form:"deptId"`
+	Model     string `form:"model"`
+}
+
+// UsageLogPageReq 用量记录分页请求
+type UsageLogPageReq struct {
+	PageNo    int    `form:"pageNo"`
+	PageSize  int    `form:"pageSize"`
+	StartDate string `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
+	UserID    uint   `form:"userId"`
+	DeptID    uint   `form:"deptId"`
+	Model     string `form:"model"`
+}
+
+// BudgetListReq 部门预算列表请求
+type BudgetListReq struct {
+	Month string `form:"month" binding:"omitempty,datetime=2006-01"` // 统计月份，为空时为当月
+}
+
+// BudgetSaveReq 部门预算设置请求，部门已有预算时覆盖
+type BudgetSaveReq struct {
+	DeptID       uint    `json:"deptId" binding:"required"`
+	MonthlyLimit float64 `json:"monthlyLimit" binding:"gt=0"`                // 每月费用上限，单位美元
+	Action       string  `json:"action" binding:"required,oneof=block warn"` // 超出后拒绝请求或仅提醒
+	WarnRatio    float64 `json:"warnRatio" binding:"omitempty,gt=0,lte=1"`   // 费用达到预算的该比例时提醒
+	Remark       string  `json:"remark" binding:"max=500"`
+}
+
+// UsageSummaryItem 用量汇总项，附带用户和部门名称
+type UsageSummaryItem struct {
+	aiusage.SummaryRow
+	UserName string `json:"userName,omitempty"`
+	DeptName string `json:"deptName,omitempty"`
+}
+
+// UsageLogItem 用量记录，附带用户和部门名称
+type UsageLogItem struct {
+	aiusage.UsageLog
+	UserName string `json:"userName"`
+	DeptName string `json:"deptName"`
+}
+
+// BudgetItem 部门预算及当月使用情况
+type BudgetItem struct {
+	*aiusage.BudgetStatus
+	DeptName string `json:"deptName"`
+}
+
+// BudgetChecker 部门预算检查，对话前检查调用用户所属部门当月预算
+type BudgetChecker interface {
+	CheckBudget(ctx context.Context, userID uint) (*aiusage.BudgetStatus, error)
+}
+
+// UsageService AI 用量服务层，按用户、部门、模型统计费用并管理部门月度预算
+type UsageService struct {
+	db    *gorm.DB
+	usage *aiusage.Service
+}
+
+// NewUsageService 创建 AI 用量服务实例，usageService 为 nil 表示未启用 AI
+func NewUsageService(db *gorm.DB, usageService *aiusage.Service) *UsageService {
+	return &UsageService{db: db, usage: usageService}
+}
+
+// NewDeptResolver 从用户表查询用户所属部门
+func NewDeptResolver(db *gorm.DB) aiusage.DeptResolver {
+	return func(ctx context.Context, userID uint) (uint, error) {
+		var user system.User
+		err := db.WithContext(ctx).Select("dept_id").Where("id = ?", userID).Take(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return user.DeptID, err
+	}
+}
+
+// Summary 按天或按月汇总用户、部门或模型的用量
+func (s *UsageService) Summary(req *UsageSummaryReq) ([]UsageSummaryItem, error) {
+	if s.usage == nil {
+		return nil, ErrUsageDisabled
+	}
+	period := req.Period
+	if period == "" {
+		period = aiusage.PeriodDay
+	}
+	start, end, err := dateRange(req.StartDate, req.EndDate, period)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.usage.Summary(&aiusage.SummaryQuery{
+		LogQuery: aiusage.LogQuery{UserID: req.UserID, DeptID: req.DeptID, Model: req.Model, Start: start, End: end},
+		GroupBy:  req.GroupBy,
+		Period:   period,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(rows))
+	deptIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		userIDs = append(userIDs, row.UserID)
+		deptIDs = append(deptIDs, row.DeptID)
+	}
+	userNames, deptNames := s.userNames(userIDs), s.deptNames(deptIDs)
+
+	items := make([]UsageSummaryItem, len(rows))
+	for i, row := range rows {
+		items[i] = UsageSummaryItem{SummaryRow: row, UserName: userNames[row.UserID], DeptName: deptNames[row.DeptID]}
+	}
+	return items, nil
+}
+
+// GetLogPage 分页查询用量记录
+func (s *UsageService) GetLogPage(req *UsageLogPageReq) (*model.PageResp, error) {
+	if s.usage == nil {
+		return nil, ErrUsageDisabled
+	}
+	var start, end time.Time
+	if req.StartDate != "" || req.EndDate != "" {
+		var err error
+		if start, end, err = dateRange(req.StartDate, req.EndDate, aiusage.PeriodDay); err != nil {
+			return nil, err
+		}
+	}
+
+	limit, _ := pageLimit(req.PageNo, req.PageSize)
+	logs, total, err := s.usage.GetLogs(&aiusage.LogQuery{
+		UserID: req.UserID, DeptID: req.DeptID, Model: req.Model, Start: start, End: end,
+	}, max(req.PageNo, 1), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(logs))
+	deptIDs := make([]uint, 0, len(logs))
+	for _, entry := range logs {
+		userIDs = append(userIDs, entry.UserID)
+		deptIDs = append(deptIDs, entry.DeptID)
+	}
+	userNames, deptNames := s.userNames(userIDs), s.deptNames(deptIDs)
+
+	list := make([]UsageLogItem, len(logs))
+	for i, entry := range logs {
+		list[i] = UsageLogItem{UsageLog: entry, UserName: userNames[entry.UserID], DeptName: deptNames[entry.DeptID]}
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetBudgetList 获取全部部门预算及指定月份的使用情况
+func (s *UsageService) GetBudgetList(ctx context.Context, req *BudgetListReq) ([]BudgetItem, error) {
+	if s.usage == nil {
+		return nil, ErrUsageDisabled
+	}
+	month := time.Now()
+	if req.Month != "" {
+		var err error
+		if month, err = time.ParseInLocation("2006-01", req.Month, time.Local); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses, err := s.usage.BudgetStatuses(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	deptIDs := make([]uint, len(statuses))
+	for i, status := range statuses {
+		deptIDs[i] = status.Budget.DeptID
+	}
+	deptNames := s.deptNames(deptIDs)
+
+	items := make([]BudgetItem, len(statuses))
+	for i, status := range statuses {
+		items[i] = BudgetItem{BudgetStatus: status, DeptName: deptNames[status.Budget.DeptID]}
+	}
+	return items, nil
+}
+
+// SaveBudget 设置部门预算
+func (s *UsageService) SaveBudget(req *BudgetSaveReq, operatorID uint) error {
+	if s.usage == nil {
+		return ErrUsageDisabled
+	}
+	var count int64
+	if err := s.db.Model(&system.Dept{}).Where("id = ?", req.DeptID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrDeptNotFound
+	}
+	return s.usage.SaveBudget(&aiusage.DeptBudget{
+		DeptID:       req.DeptID,
+		MonthlyLimit: req.MonthlyLimit,
+		Action:       req.Action,
+		WarnRatio:    req.WarnRatio,
+		Remark:       req.Remark,
+		CreateBy:     operatorID,
+		UpdateBy:     operatorID,
+	})
+}
+
+// DeleteBudget 删除部门预算
+func (s *UsageService) DeleteBudget(id uint) error {
+	if s.usage == nil {
+		return ErrUsageDisabled
+	}
+	err := s.usage.DeleteBudget(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrBudgetNotFound
+	}
+	return err
+}
+
+// userNames 查询用户昵称，昵称为空时使用用户名
+func (s *UsageService) userNames(ids []uint) map[uint]string {
+	names := make(map[uint]string)
+	var users []system.User
+	if err := s.db.Select("id, username, nickname").Where("id IN ?", nonZero(ids)).Find(&users).Error; err != nil {
+		return names
+	}
+	for _, user := range users {
+		names[user.ID] = user.Nickname
+		if user.Nickname == "" {
+			names[user.ID] = user.Username
+		}
+	}
+	return names
+}
+
+// deptNames 查询部门名称
+func (s *UsageService) deptNames(ids []uint) map[uint]string {
+	names := make(map[uint]string)
+	var depts []system.Dept
+	if err := s.db.Select("id, name").Where("id IN ?", nonZero(ids)).Find(&depts).Error; err != nil {
+		return names
+	}
+	for _, dept := range depts {
+		names[dept.ID] = dept.Name
+	}
+	return names
+}
+
+// nonZero 去除零值和重复的ID，为空时返回 [0] 以保证 IN 条件有效
+func nonZero(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	if len(result) == 0 {
+		return []uint{0}
+	}
+	return result
+}
+
+// dateRange 将包含首尾的日期范围转换为 [start, end)，开始日期为空时按周期取近 30 天或近 12 个月
+func dateRange(startDate, endDate, period string) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if endDate != "" {
+		day, err := time.ParseInLocation(dateLayout, endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = day.AddDate(0, 0, 1)
+	}
+
+	var start time.Time
+	switch {
+	case startDate != "":
+		day, err := time.ParseInLocation(dateLayout, startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = day
+	case period == aiusage.PeriodMonth:
+		last := end.AddDate(0, 0, -1)
+		start = time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -11, 0)
+	default:
+		start = end.AddDate(0, 0, -30)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return start, end, nil
+}
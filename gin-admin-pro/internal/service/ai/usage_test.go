@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/aiusage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetStub 返回固定预算状态的预算检查
+type budgetStub struct {
+	status *aiusage.BudgetStatus
+}
+
+func (b *budgetStub) CheckBudget(ctx context.Context, userID uint) (*aiusage.BudgetStatus, error) {
+	return b.status, nil
+}
+
+func TestChatBudget(t *testing.T) {
+	calls := 0
+	svc, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+	budget := &budgetStub{}
+	svc.SetBudget(budget)
+	ctx := context.Background()
+
+	// 部门未设置预算
+	resp, err := svc.Chat(ctx, 1, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.BudgetWarning)
+
+	// 达到提醒比例时回复附带提醒
+	budget.status = &aiusage.BudgetStatus{
+		Budget:  &aiusage.DeptBudget{MonthlyLimit: 100, Action: aiusage.BudgetActionBlock},
+		Spent:   85,
+		Warning: true,
+	}
+	resp, err = svc.Chat(ctx, 1, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+	assert.Equal(t, "部门本月 AI 费用已使用 $85.00，预算 $100.00", resp.BudgetWarning)
+
+	// 超出预算时拒绝请求，不调用模型也不创建对话
+	budget.status.Spent = 100
+	budget.status.Exceeded = true
+	_, err = svc.Chat(ctx, 2, &ChatReq{Content: "你好"})
+	assert.ErrorIs(t, err, aiusage.ErrBudgetExceeded)
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrBusiness.Code, e.Code)
+	_, err = svc.ChatStream(ctx, 2, &ChatReq{Content: "你好", Stream: true})
+	assert.ErrorIs(t, err, aiusage.ErrBudgetExceeded)
+	assert.Equal(t, 2, calls)
+	conversations, err := svc.ListConversations(ctx, 2, &ConversationPageReq{})
+	require.NoError(t, err)
+	assert.Empty(t, conversations)
+
+	// 仅提醒的预算超出后仍可对话
+	budget.status.Budget.Action = aiusage.BudgetActionWarn
+	resp, err = svc.Chat(ctx, 2, &ChatReq{Content: "你好"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.BudgetWarning)
+}
+
+func TestDateRange(t *testing.T) {
+	start, end, err := dateRange("2026-03-01", "2026-03-31", aiusage.PeriodDay)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local), end)
+
+	// 按月统计默认近 12 个月，从当月往前 11 个月的 1 日开始
+	start, end, err = dateRange("", "2026-03-15", aiusage.PeriodMonth)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local), end)
+
+	start, end, err = dateRange("", "2026-03-15", aiusage.PeriodDay)
+	require.NoError(t, err)
+	assert.Equal(t, end.AddDate(0, 0, -30), start)
+
+	_, _, err = dateRange("2026-03-02", "2026-03-01", aiusage.PeriodDay)
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
@@ -1,13 +1,40 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 
+	systemdao "gin-admin-pro/internal/dao/system"
 	"gin-admin-pro/internal/pkg/config"
+	"gin-admin-pro/internal/pkg/response"
 	"gin-admin-pro/internal/pkg/token"
+	aiservice "gin-admin-pro/internal/service/ai"
+	searchservice "gin-admin-pro/internal/service/search"
+	systemservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/aiattachment"
+	"gin-admin-pro/plugin/aiusage"
+	"gin-admin-pro/plugin/cron"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/errorcode"
+	"gin-admin-pro/plugin/knowledge"
+	"gin-admin-pro/plugin/mcp"
+	"gin-admin-pro/plugin/milvus"
+	"gin-admin-pro/plugin/mongodb"
 	"gin-admin-pro/plugin/mysql"
 	"gin-admin-pro/plugin/oss"
+	"gin-admin-pro/plugin/postgresql"
+	"gin-admin-pro/plugin/prompt"
+	"gin-admin-pro/plugin/recyclebin"
 	"gin-admin-pro/plugin/redis"
+	"gin-admin-pro/plugin/sysconfig"
+	"gin-admin-pro/plugin/vectorstore"
+	"gin-admin-pro/plugin/voice"
 )
 
 // Services 全局服务实例
@@ -18,7 +45,38 @@ type ServiceContainer struct {
 	TokenService *token.TokenService
 	RedisClient  *redis.Client
 	MySQLClient  *mysql.Client
-	OSSStorage   oss.OSSInterface
+	// MongoClient MongoDB客户端，用于持久化AI对话，未配置时为 nil
+	MongoClient *mongodb.Client
+	OSSStorage  oss.OSSInterface
+	CronManager *cron.CronManager
+	// ConfigService 运行时参数配置，业务代码应通过它读取可在线修改的参数
+	ConfigService *sysconfig.Service
+	// DictService 字典服务（Redis缓存）
+	DictService *dict.Service
+	// ErrorCodeService 错误码服务（多语言消息）
+	ErrorCodeService *errorcode.Service
+	// AIService AI 对话服务，配置未启用时为 nil
+	AIService ai.AIService
+	// AIToolService AI 工具服务，未启用工具时为 nil
+	AIToolService *aiservice.ToolService
+	// PostgreSQLClient PostgreSQL客户端，用于知识库向量检索，未启用知识库时为 nil
+	PostgreSQLClient *postgresql.Client
+	// KnowledgeService 知识库服务，未启用知识库时为 nil
+	KnowledgeService *knowledge.Service
+	// AIUsageService AI 用量和部门预算服务，未启用 AI 时为 nil
+	AIUsageService *aiusage.Service
+	// PromptService 提示词模板和助手服务，未启用 AI 时为 nil
+	PromptService *prompt.Service
+	// AIAttachmentService 对话附件服务，未启用附件时为 nil
+	AIAttachmentService *aiattachment.Service
+	// VoiceServer 小智设备语音网关，未启用语音时为 nil
+	VoiceServer *voice.Server
+	// MCPServer MCP 服务端，未启用 MCP 时为 nil
+	MCPServer *mcp.Server
+	// SearchService 全局搜索服务，未启用搜索时为 nil
+	SearchService *searchservice.Service
+
+	cancel context.CancelFunc
 }
 
 // InitServices 初始化服务
@@ -43,21 +101,7 @@ func InitServices() error {
 	tokenService := token.NewTokenService(redisClient)
 
 	// 初始化MySQL客户端
-	mysqlConfig := &mysql.Config{
-		Host:         cfg.Database.MySQL.Host,
-		Port:         cfg.Database.MySQL.Port,
-		Username:     cfg.Database.MySQL.Username,
-		Password:     cfg.Database.MySQL.Password,
-		Database:     cfg.Database.MySQL.Database,
-		Charset:      cfg.Database.MySQL.Charset,
-		ParseTime:    cfg.Database.MySQL.ParseTime,
-		Loc:          cfg.Database.MySQL.Loc,
-		MaxIdleConns: cfg.Database.MySQL.MaxIdleConns,
-		MaxOpenConns: cfg.Database.MySQL.MaxOpenConns,
-		MaxLifetime:  cfg.Database.MySQL.ConnMaxLifetime,
-	}
-
-	mysqlClient, err := mysql.NewClient(mysqlConfig)
+	mysqlClient, err := mysql.NewClient(newMySQLConfig(cfg.Database.MySQL))
 	if err != nil {
 		return fmt.Errorf("初始化MySQL客户端失败: %w", err)
 	}
@@ -68,22 +112,577 @@ func InitServices() error {
 		return fmt.Errorf("初始化OSS存储失败: %w", err)
 	}
 
+	// 初始化参数配置服务，并订阅其他实例的变更通知
+	ctx, cancel := context.WithCancel(context.Background())
+	configService := sysconfig.NewService(mysqlClient.GetDB(), redisClient, sysconfig.DefaultConfig())
+	if err := configService.StartNotifyListener(ctx); err != nil {
+		cancel()
+		return fmt.Errorf("订阅参数变更通知失败: %w", err)
+	}
+	sysconfig.SetDefaultService(configService)
+
+	// 初始化字典服务
+	dictPlugin := dict.NewPlugin(mysqlClient.GetDB(), nil)
+	dictPlugin.SetCache(redis.NewRedisCache(redisClient))
+	dictService := dictPlugin.GetService()
+	// 响应中带 dict 标签的字段自动追加字典标签
+	response.SetDictTranslator(dictService.GetDictLabelByValue)
+
+	// 初始化错误码服务，response 按请求语言解析错误码消息
+	errorCodeService := errorcode.NewPlugin(mysqlClient.GetDB(), nil).GetService()
+	response.SetErrorCodeService(errorCodeService)
+
+	// 初始化定时任务管理器
+	cronManager, err := initCronManager(mysqlClient)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("初始化定时任务失败: %w", err)
+	}
+
+	// 初始化AI服务，配置了 MongoDB 时对话持久化到 MongoDB
+	var (
+		aiService         ai.AIService
+		aiToolService     *aiservice.ToolService
+		mongoClient       *mongodb.Client
+		postgresClient    *postgresql.Client
+		knowledgeService  *knowledge.Service
+		aiUsageService    *aiusage.Service
+		promptService     *prompt.Service
+		attachmentService *aiattachment.Service
+		voiceServer       *voice.Server
+	)
+	if cfg.AI.Enabled {
+		if cfg.Database.MongoDB.URI != "" {
+			mongoClient, err = initMongoClient(cfg.Database.MongoDB)
+			if err != nil {
+				cancel()
+				return fmt.Errorf("初始化MongoDB客户端失败: %w", err)
+			}
+		}
+
+		if cfg.AI.EnableTools {
+			aiToolService, err = initAIToolService(mysqlClient, dictService)
+			if err != nil {
+				cancel()
+				return fmt.Errorf("初始化AI工具失败: %w", err)
+			}
+		}
+
+		if cfg.AI.Knowledge.Enabled {
+			postgresClient, knowledgeService, err = initKnowledgeService(cfg.Database, cfg.AI, ossStorage)
+			if err != nil {
+				cancel()
+				return fmt.Errorf("初始化AI知识库失败: %w", err)
+			}
+		}
+
+		aiUsageService, err = initAIUsageService(mysqlClient)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("初始化AI用量统计失败: %w", err)
+		}
+
+		promptService = prompt.NewService(mysqlClient.GetDB())
+		if err = promptService.Migrate(); err != nil {
+			cancel()
+			return fmt.Errorf("初始化AI提示词失败: %w", err)
+		}
+
+		if cfg.AI.Attachment.Enabled {
+			attachmentService = aiattachment.NewService(mysqlClient.GetDB(), ossStorage, &aiattachment.Config{
+				MaxImageSize:     cfg.AI.Attachment.MaxImageSize,
+				MaxDocumentSize:  cfg.AI.Attachment.MaxDocumentSize,
+				MaxDocumentRunes: cfg.AI.Attachment.MaxDocumentRunes,
+			})
+			if err = attachmentService.Migrate(); err != nil {
+				cancel()
+				return fmt.Errorf("初始化AI对话附件失败: %w", err)
+			}
+		}
+
+		defaultAIService, err := initAIService(ctx, cfg.AI, mongoClient, redisClient, aiToolService, aiUsageService)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("初始化AI服务失败: %w", err)
+		}
+		aiService = defaultAIService
+
+		if cfg.AI.Voice.Enabled {
+			if voiceServer, err = initVoiceServer(cfg.AI, aiService); err != nil {
+				cancel()
+				return fmt.Errorf("初始化语音网关失败: %w", err)
+			}
+		}
+	}
+
+	// 初始化MCP服务，复用AI工具服务的工具和权限校验
+	var mcpServer *mcp.Server
+	if cfg.MCP.Enabled {
+		mcpTools := aiToolService
+		if mcpTools == nil {
+			if mcpTools, err = initAIToolService(mysqlClient, dictService); err != nil {
+				cancel()
+				return fmt.Errorf("初始化MCP工具失败: %w", err)
+			}
+		}
+		mcpServer = initMCPServer(cfg.MCP, mcpTools)
+	}
+
+	// 初始化全局搜索，数据变更经发件箱同步到 Elasticsearch
+	var searchService *searchservice.Service
+	if cfg.Search.Enabled {
+		if searchService, err = initSearchService(ctx, cfg, mysqlClient); err != nil {
+			cancel()
+			return fmt.Errorf("初始化全局搜索失败: %w", err)
+		}
+	}
+
 	// 设置全局服务实例
 	Services = &ServiceContainer{
-		TokenService: tokenService,
-		RedisClient:  redisClient,
-		MySQLClient:  mysqlClient,
-		OSSStorage:   ossStorage,
+		TokenService:        tokenService,
+		RedisClient:         redisClient,
+		MySQLClient:         mysqlClient,
+		MongoClient:         mongoClient,
+		OSSStorage:          ossStorage,
+		CronManager:         cronManager,
+		ConfigService:       configService,
+		DictService:         dictService,
+		ErrorCodeService:    errorCodeService,
+		AIService:           aiService,
+		AIToolService:       aiToolService,
+		PostgreSQLClient:    postgresClient,
+		KnowledgeService:    knowledgeService,
+		AIUsageService:      aiUsageService,
+		PromptService:       promptService,
+		AIAttachmentService: attachmentService,
+		VoiceServer:         voiceServer,
+		MCPServer:           mcpServer,
+		SearchService:       searchService,
+		cancel:              cancel,
 	}
 
 	return nil
 }
 
+// newMySQLConfig 将应用配置转换为 MySQL 客户端配置
+func newMySQLConfig(mysqlConfig config.MySQLConfig) *mysql.Config {
+	return &mysql.Config{
+		Host:         mysqlConfig.Host,
+		Port:         mysqlConfig.Port,
+		Username:     mysqlConfig.Username,
+		Password:     mysqlConfig.Password,
+		Database:     mysqlConfig.Database,
+		Charset:      mysqlConfig.Charset,
+		ParseTime:    mysqlConfig.ParseTime,
+		Loc:          mysqlConfig.Loc,
+		MaxIdleConns: mysqlConfig.MaxIdleConns,
+		MaxOpenConns: mysqlConfig.MaxOpenConns,
+		MaxLifetime:  mysqlConfig.ConnMaxLifetime,
+	}
+}
+
+// initCronManager 初始化定时任务管理器并注册系统任务
+func initCronManager(mysqlClient *mysql.Client) (*cron.CronManager, error) {
+	cronManager, err := cron.NewCronManager(cron.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := cronManager.Initialize(); err != nil {
+		return nil, err
+	}
+	if err := cronManager.Start(); err != nil {
+		return nil, err
+	}
+
+	// 回收站自动清理，保留天数可通过参数配置在线修改
+	recycleBinConfig := recyclebin.DefaultConfig()
+	recycleBinService := systemservice.NewRecycleBinService(systemdao.NewRecycleBinDAO(mysqlClient.GetDB()), recycleBinConfig)
+	recycleBinService.GetBinService().SetRetentionFunc(func() int {
+		return sysconfig.GetInt(sysconfig.KeyRecycleBinRetention, recycleBinConfig.RetentionDays)
+	})
+	if err := recyclebin.RegisterCronJob(cronManager, recycleBinService.GetBinService()); err != nil {
+		return nil, err
+	}
+
+	return cronManager, nil
+}
+
+// initMongoClient 初始化MongoDB客户端
+func initMongoClient(mongoConfig config.MongoDBConfig) (*mongodb.Client, error) {
+	clientConfig := mongodb.DefaultConfig()
+	clientConfig.URI = mongoConfig.URI
+	if mongoConfig.Database != "" {
+		clientConfig.Database = mongoConfig.Database
+	}
+	if mongoConfig.Timeout > 0 {
+		clientConfig.Timeout = mongoConfig.Timeout
+	}
+	return mongodb.NewClient(clientConfig)
+}
+
+// initAIService 根据应用配置创建并启动AI服务，未配置的项使用插件默认值。
+// 限流和配额计数保存在 Redis 中，多实例共享
+// mongoClient 不为 nil 时对话存储到 MongoDB，否则保存在内存中，重启后丢失。
+// toolService 不为 nil 时启用函数调用，模型可执行其中注册的工具。
+// usageService 记录每次模型调用的用量，用于按部门分摊费用，并在调用前检查部门预算。
+func initAIService(ctx context.Context, aiConfig config.AIConfig, mongoClient *mongodb.Client, redisClient *redis.Client, toolService *aiservice.ToolService, usageService *aiusage.Service) (*ai.DefaultAIService, error) {
+	pluginConfig := ai.DefaultConfig()
+	pluginConfig.Enabled = aiConfig.Enabled
+	pluginConfig.Provider = aiConfig.Provider
+	pluginConfig.APIKey = aiConfig.APIKey
+	pluginConfig.BaseURL = aiConfig.BaseURL
+	if aiConfig.Model != "" {
+		pluginConfig.Model = aiConfig.Model
+	} else if aiConfig.Provider != "openai" {
+		// 未指定模型时使用各提供商的默认模型，而不是 OpenAI 的默认模型
+		pluginConfig.Model = ""
+	}
+	if aiConfig.MaxTokens > 0 {
+		pluginConfig.MaxTokens = aiConfig.MaxTokens
+	}
+	if aiConfig.Temperature > 0 {
+		pluginConfig.Temperature = aiConfig.Temperature
+	}
+	if aiConfig.SystemPrompt != "" {
+		pluginConfig.SystemPrompt = aiConfig.SystemPrompt
+	}
+	if aiConfig.Timeout > 0 {
+		pluginConfig.Timeout = time.Duration(aiConfig.Timeout) * time.Second
+	}
+	if aiConfig.MaxHistoryLength > 0 {
+		pluginConfig.MaxHistoryLength = aiConfig.MaxHistoryLength
+	} else if aiConfig.MaxHistoryLength < 0 {
+		pluginConfig.MaxHistoryLength = 0
+	}
+	if aiConfig.SummaryMaxTokens > 0 {
+		pluginConfig.SummaryMaxTokens = aiConfig.SummaryMaxTokens
+	} else if aiConfig.SummaryMaxTokens < 0 {
+		pluginConfig.EnableSummary = false
+	}
+	if aiConfig.RateLimit > 0 {
+		pluginConfig.RateLimit.Requests = aiConfig.RateLimit
+	} else if aiConfig.RateLimit < 0 {
+		pluginConfig.RateLimit.Enabled = false
+	}
+	if aiConfig.DailyTokenLimit > 0 {
+		pluginConfig.DailyTokenLimit = aiConfig.DailyTokenLimit
+	} else if aiConfig.DailyTokenLimit < 0 {
+		pluginConfig.DailyTokenLimit = 0
+	}
+	pluginConfig.EnableCostLimit = aiConfig.EnableCostLimit
+	pluginConfig.DailyCostLimit = aiConfig.DailyCostLimit
+	pluginConfig.Audit.Enabled = aiConfig.EnableAudit
+	pluginConfig.EnableVoiceInput = aiConfig.Voice.Enabled
+	pluginConfig.EnableImageInput = aiConfig.Attachment.Enabled
+	if len(aiConfig.BlockedKeywords) > 0 {
+		pluginConfig.ContentFilter.Enabled = true
+		pluginConfig.ContentFilter.Keywords = aiConfig.BlockedKeywords
+	}
+
+	if len(aiConfig.Gateways) > 0 {
+		pluginConfig.Gateways = make(map[string]*ai.GatewayConfig, len(aiConfig.Gateways))
+		for name, gateway := range aiConfig.Gateways {
+			pluginConfig.Gateways[name] = convertGatewayConfig(gateway)
+		}
+	}
+
+	for _, route := range aiConfig.Routes {
+		pluginConfig.Routes = append(pluginConfig.Routes, ai.RouteConfig{
+			Name:     route.Name,
+			Provider: route.Provider,
+			APIKey:   route.APIKey,
+			BaseURL:  route.BaseURL,
+			Model:    route.Model,
+			Models:   route.Models,
+			Tags:     route.Tags,
+			Weight:   route.Weight,
+		})
+	}
+	if aiConfig.CircuitBreaker.FailureThreshold > 0 {
+		pluginConfig.CircuitBreaker.FailureThreshold = aiConfig.CircuitBreaker.FailureThreshold
+	}
+	if aiConfig.CircuitBreaker.OpenTimeout > 0 {
+		pluginConfig.CircuitBreaker.OpenTimeout = time.Duration(aiConfig.CircuitBreaker.OpenTimeout) * time.Second
+	}
+
+	if aiConfig.Cache.Enabled {
+		pluginConfig.Cache.Enabled = true
+		if aiConfig.Cache.TTL > 0 {
+			pluginConfig.Cache.TTL = time.Duration(aiConfig.Cache.TTL) * time.Second
+		}
+		pluginConfig.Cache.Semantic.Enabled = aiConfig.Cache.Semantic
+		if aiConfig.Cache.Threshold > 0 {
+			pluginConfig.Cache.Semantic.Threshold = aiConfig.Cache.Threshold
+		}
+	}
+
+	pluginConfig.EnableFunctionCalling = toolService != nil
+	if aiConfig.MaxToolIterations > 0 {
+		pluginConfig.MaxToolIterations = aiConfig.MaxToolIterations
+	}
+
+	aiService, err := ai.NewDefaultAIService(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	if toolService != nil {
+		aiService.SetToolRegistry(toolService.Registry())
+	}
+	if mongoClient != nil {
+		store, err := ai.NewMongoConversationStore(ctx, mongoClient)
+		if err != nil {
+			return nil, err
+		}
+		aiService.SetConversationStore(store)
+	} else {
+		log.Println("MongoDB 未配置，AI 对话保存在内存中，重启后丢失")
+	}
+	aiService.SetLimitStore(ai.NewRedisLimitStore(redisClient))
+	aiService.SetUsageRecorder(usageService)
+	// 部门预算在模型调用前检查，语音网关等直接调用 AI 服务的入口同样生效
+	aiService.AddMiddleware(aiusage.NewBudgetMiddleware(usageService))
+	if aiConfig.Cache.Enabled {
+		aiService.SetResponseCache(ai.NewRedisResponseCache(redisClient, aiConfig.Cache.MaxEntries))
+		if aiConfig.Cache.Semantic {
+			embedder, err := initEmbedder(aiConfig)
+			if err != nil {
+				return nil, err
+			}
+			aiService.SetEmbedder(embedder)
+		}
+	}
+	if err := aiService.Initialize(pluginConfig); err != nil {
+		return nil, err
+	}
+	if err := aiService.Start(); err != nil {
+		return nil, err
+	}
+	return aiService, nil
+}
+
+// initAIToolService 创建 AI 工具服务并注册系统工具，工具按调用用户的角色权限和数据权限执行
+func initAIToolService(mysqlClient *mysql.Client, dictService *dict.Service) (*aiservice.ToolService, error) {
+	db := mysqlClient.GetDB()
+	toolService := aiservice.NewToolService(aiservice.NewCallerLoader(systemdao.NewUserDAO(db)))
+	if err := aiservice.RegisterSystemTools(toolService, db, dictService); err != nil {
+		return nil, err
+	}
+	return toolService, nil
+}
+
+// initAIUsageService 创建 AI 用量服务并建表，用量记录调用用户所属部门
+func initAIUsageService(mysqlClient *mysql.Client) (*aiusage.Service, error) {
+	db := mysqlClient.GetDB()
+	usageService := aiusage.NewService(db, aiservice.NewDeptResolver(db))
+	if err := usageService.Migrate(); err != nil {
+		return nil, err
+	}
+	return usageService, nil
+}
+
+// initKnowledgeService 连接 PostgreSQL 并创建知识库服务，知识库和文档信息保存到 PostgreSQL，
+// 文档原文件保存到 OSS，切片和向量按 ai.knowledge.store 保存到 pgvector 或 Milvus
+func initKnowledgeService(dbConfig config.DatabaseConfig, aiConfig config.AIConfig, ossStorage oss.OSSInterface) (*postgresql.Client, *knowledge.Service, error) {
+	embedder, err := initEmbedder(aiConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pgConfig := dbConfig.PostgreSQL
+
+	clientConfig := postgresql.DefaultConfig()
+	clientConfig.Host = pgConfig.Host
+	clientConfig.Port = pgConfig.Port
+	clientConfig.Database = pgConfig.Database
+	clientConfig.Username = pgConfig.Username
+	clientConfig.Password = pgConfig.Password
+	if pgConfig.SSLMode != "" {
+		clientConfig.SSLMode = pgConfig.SSLMode
+	}
+	if pgConfig.MaxIdleConns > 0 {
+		clientConfig.MaxIdleConns = pgConfig.MaxIdleConns
+	}
+	if pgConfig.MaxOpenConns > 0 {
+		clientConfig.MaxOpenConns = pgConfig.MaxOpenConns
+	}
+	// 知识库只依赖 vector 扩展，其他扩展按 database.postgresql.extensions 启用
+	clientConfig.Extensions = []postgresql.ExtensionConfig{{Name: "vector", Enabled: true}}
+	for _, name := range pgConfig.Extensions {
+		if name != "vector" {
+			clientConfig.Extensions = append(clientConfig.Extensions, postgresql.ExtensionConfig{Name: name, Enabled: true})
+		}
+	}
+	client, err := postgresql.NewClient(clientConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	knowledgeConfig := aiConfig.Knowledge
+	var store vectorstore.VectorStore
+	switch knowledgeConfig.Store {
+	case "", "pgvector":
+		store = client.VectorStore()
+	case "milvus":
+		milvusConfig := milvus.DefaultConfig()
+		if dbConfig.Milvus.Host != "" {
+			milvusConfig.Address = dbConfig.Milvus.Host
+		}
+		if dbConfig.Milvus.Port > 0 {
+			milvusConfig.Port = dbConfig.Milvus.Port
+		}
+		if dbConfig.Milvus.Database != "" {
+			milvusConfig.Database = dbConfig.Milvus.Database
+		}
+		milvusClient, err := milvus.NewClient(milvusConfig)
+		if err == nil {
+			err = milvusClient.Ping()
+		}
+		if err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("连接Milvus失败: %w", err)
+		}
+		store = milvusClient.VectorStore()
+	default:
+		client.Close()
+		return nil, nil, fmt.Errorf("不支持的知识库向量存储: %s", knowledgeConfig.Store)
+	}
+	engine := knowledge.NewEngine(embedder, store, &knowledge.Config{
+		ChunkSize:    knowledgeConfig.ChunkSize,
+		ChunkOverlap: knowledgeConfig.ChunkOverlap,
+		TopK:         knowledgeConfig.TopK,
+		MinScore:     knowledgeConfig.MinScore,
+		MaxFileSize:  knowledgeConfig.MaxFileSize,
+		IndexType:    knowledgeConfig.IndexType,
+		Collection:   knowledgeConfig.Collection,
+	})
+	knowledgeService := knowledge.NewService(client.GetDB(), engine, ossStorage)
+	if err := knowledgeService.Migrate(); err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return client, knowledgeService, nil
+}
+
+// initEmbedder 根据配置创建向量模型，未配置 apiKey 时使用 ai.apiKey
+func initEmbedder(aiConfig config.AIConfig) (ai.Embedder, error) {
+	embeddingConfig := aiConfig.Embedding
+	switch embeddingConfig.Provider {
+	case "hash":
+		return ai.NewHashEmbedder(embeddingConfig.Dimension), nil
+	case "", "openai":
+		apiKey := embeddingConfig.APIKey
+		if apiKey == "" {
+			apiKey = aiConfig.APIKey
+		}
+		return ai.NewOpenAIEmbedder(&ai.EmbeddingConfig{
+			BaseURL:   embeddingConfig.BaseURL,
+			APIKey:    apiKey,
+			Model:     embeddingConfig.Model,
+			Dimension: embeddingConfig.Dimension,
+			BatchSize: embeddingConfig.BatchSize,
+			Timeout:   time.Duration(embeddingConfig.Timeout) * time.Second,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的向量模型提供商: %s", embeddingConfig.Provider)
+	}
+}
+
+// initVoiceServer 创建小智设备语音网关，语音识别和合成使用 OpenAI 兼容接口，未配置 apiKey 时使用 ai.apiKey；
+// 设备令牌和对话所属用户必须配置，避免网关对外开放或以管理员身份对话
+func initVoiceServer(aiConfig config.AIConfig, aiService ai.AIService) (*voice.Server, error) {
+	voiceConfig := aiConfig.Voice
+	var tokens []string
+	for _, token := range voiceConfig.Tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("ai.voice.tokens 未配置设备令牌")
+	}
+	if voiceConfig.UserID == 0 {
+		return nil, errors.New("ai.voice.userId 未配置语音对话所属用户")
+	}
+
+	asrConfig, ttsConfig := voiceConfig.ASR, voiceConfig.TTS
+	if asrConfig.APIKey == "" {
+		asrConfig.APIKey = aiConfig.APIKey
+	}
+	if ttsConfig.APIKey == "" {
+		ttsConfig.APIKey = aiConfig.APIKey
+	}
+	asr := voice.NewOpenAIASR(voice.OpenAIASRConfig{
+		BaseURL:  asrConfig.BaseURL,
+		APIKey:   asrConfig.APIKey,
+		Model:    asrConfig.Model,
+		Language: asrConfig.Language,
+		Timeout:  time.Duration(asrConfig.Timeout) * time.Second,
+	})
+	tts := voice.NewOpenAITTS(voice.OpenAITTSConfig{
+		BaseURL: ttsConfig.BaseURL,
+		APIKey:  ttsConfig.APIKey,
+		Model:   ttsConfig.Model,
+		Voice:   ttsConfig.Voice,
+		Speed:   ttsConfig.Speed,
+		Timeout: time.Duration(ttsConfig.Timeout) * time.Second,
+	})
+	var vad voice.VAD
+	if voiceConfig.VADMinBytes >= 0 {
+		vad = voice.NewPacketSizeVAD(voiceConfig.VADMinBytes)
+	}
+
+	serverConfig := &voice.Config{
+		Tokens:         tokens,
+		UserID:         strconv.FormatUint(uint64(voiceConfig.UserID), 10),
+		SystemPrompt:   voiceConfig.SystemPrompt,
+		Model:          voiceConfig.Model,
+		SilenceTimeout: time.Duration(voiceConfig.SilenceMs) * time.Millisecond,
+	}
+	return voice.NewServer(aiService, asr, tts, vad, serverConfig), nil
+}
+
+// convertGatewayConfig 将应用配置中的网关转换为插件配置
+func convertGatewayConfig(gateway config.AIGatewayConfig) *ai.GatewayConfig {
+	pluginGateway := &ai.GatewayConfig{
+		BaseURL:    gateway.BaseURL,
+		APIKey:     gateway.APIKey,
+		AuthHeader: gateway.AuthHeader,
+		AuthScheme: gateway.AuthScheme,
+		Headers:    gateway.Headers,
+		Model:      gateway.Model,
+	}
+	for _, model := range gateway.Models {
+		pluginGateway.Models = append(pluginGateway.Models, ai.GatewayModel{
+			ID:          model.ID,
+			Name:        model.Name,
+			MaxTokens:   model.MaxTokens,
+			ContextSize: model.ContextSize,
+			InputCost:   model.InputCost,
+			OutputCost:  model.OutputCost,
+			Features:    model.Features,
+		})
+	}
+	return pluginGateway
+}
+
 // CleanupServices 清理服务
 func CleanupServices() error {
 	var err error
 
 	if Services != nil {
+		if Services.cancel != nil {
+			Services.cancel()
+		}
+		if Services.AIService != nil {
+			if aiErr := Services.AIService.Stop(); aiErr != nil {
+				err = aiErr
+			}
+		}
+		if Services.CronManager != nil {
+			if cronErr := Services.CronManager.Stop(); cronErr != nil {
+				err = cronErr
+			}
+		}
 		if Services.RedisClient != nil {
 			if redisErr := Services.RedisClient.Close(); redisErr != nil {
 				err = redisErr
@@ -94,6 +693,16 @@ func CleanupServices() error {
 				err = mysqlErr
 			}
 		}
+		if Services.PostgreSQLClient != nil {
+			if pgErr := Services.PostgreSQLClient.Close(); pgErr != nil {
+				err = pgErr
+			}
+		}
+		if Services.MongoClient != nil {
+			if mongoErr := Services.MongoClient.Close(); mongoErr != nil {
+				err = mongoErr
+			}
+		}
 	}
 
 	return err
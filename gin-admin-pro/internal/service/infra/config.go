@@ -0,0 +1,113 @@
+package infra
+
+import (
+	"errors"
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/sysconfig"
+)
+
+// ErrConfigInvisible 参数配置不可见
+var ErrConfigInvisible = errors.New("参数配置不可见")
+
+// ConfigPageReq 参数配置分页请求
+type ConfigPageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Name     string `form:"name"`
+	Key      string `form:"key"`
+	Type     int    `form:"type"`
+}
+
+// ConfigSaveReq 参数配置创建/更新请求
+type ConfigSaveReq struct {
+	ID       int    `json:"id"`
+	Category string `json:"category" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Key      string `json:"key" binding:"required"`
+	Value    string `json:"value"`
+	Visible  bool   `json:"visible"`
+	Remark   string `json:"remark"`
+}
+
+// ConfigService 参数配置服务层
+type ConfigService struct {
+	configService *sysconfig.Service
+}
+
+// NewConfigService 创建参数配置服务实例
+func NewConfigService(configService *sysconfig.Service) *ConfigService {
+	return &ConfigService{configService: configService}
+}
+
+// GetPage 获取参数配置分页列表
+func (s *ConfigService) GetPage(req *ConfigPageReq) (*model.PageResp, error) {
+	list, total, err := s.configService.GetConfigPage(req.PageNo, req.PageSize, req.Name, req.Key, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetByID 获取参数配置详情
+func (s *ConfigService) GetByID(id int) (*sysconfig.SysConfig, error) {
+	return s.configService.GetConfigByID(id)
+}
+
+// GetValueByKey 根据键名获取参数值，仅允许读取可见参数
+func (s *ConfigService) GetValueByKey(key string) (string, error) {
+	cfg, err := s.configService.GetConfigByKey(key)
+	if err != nil {
+		return "", err
+	}
+	if !cfg.Visible {
+		return "", ErrConfigInvisible
+	}
+	return s.configService.GetValue(key)
+}
+
+// Create 创建参数配置
+func (s *ConfigService) Create(req *ConfigSaveReq, operatorID uint) (int, error) {
+	cfg := &sysconfig.SysConfig{
+		Category:  req.Category,
+		Type:      sysconfig.TypeCustom,
+		Name:      req.Name,
+		ConfigKey: req.Key,
+		Value:     req.Value,
+		Visible:   req.Visible,
+		Remark:    req.Remark,
+		CreateBy:  int(operatorID),
+		UpdateBy:  int(operatorID),
+	}
+	if err := s.configService.CreateConfig(cfg); err != nil {
+		return 0, err
+	}
+	return cfg.ID, nil
+}
+
+// Update 更新参数配置
+func (s *ConfigService) Update(req *ConfigSaveReq, operatorID uint) error {
+	if req.ID == 0 {
+		return errcode.ErrParam.WithParams("id")
+	}
+	return s.configService.UpdateConfig(&sysconfig.SysConfig{
+		ID:        req.ID,
+		Category:  req.Category,
+		Name:      req.Name,
+		ConfigKey: req.Key,
+		Value:     req.Value,
+		Visible:   req.Visible,
+		Remark:    req.Remark,
+		UpdateBy:  int(operatorID),
+	})
+}
+
+// Delete 删除参数配置
+func (s *ConfigService) Delete(id int) error {
+	return s.configService.DeleteConfig(id)
+}
+
+// RefreshCache 刷新参数缓存
+func (s *ConfigService) RefreshCache() error {
+	return s.configService.RefreshCache()
+}
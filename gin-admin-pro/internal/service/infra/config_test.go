@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"net/http"
+	"testing"
+
+	"gin-admin-pro/internal/pkg/errcode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigUpdateRequiresID(t *testing.T) {
+	err := NewConfigService(nil).Update(&ConfigSaveReq{Key: "sys.test"}, 1)
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrParam.Code, e.Code)
+	assert.Equal(t, http.StatusBadRequest, e.HTTPStatus)
+}
@@ -0,0 +1,14 @@
+package infra
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/sysconfig"
+)
+
+// init 注册基础设施服务错误对应的错误码，供 response.Fail 与 middleware.ErrorHandler 使用
+func init() {
+	errcode.Register(ErrConfigInvisible, errcode.ErrPermissionDenied)
+	errcode.Register(sysconfig.ErrConfigNotFound, errcode.ErrDataNotFound.WithParams("参数配置"))
+	errcode.Register(sysconfig.ErrConfigKeyExists, errcode.ErrDataExists.WithParams("参数键名"))
+	errcode.Register(sysconfig.ErrConfigBuiltin, errcode.ErrBusiness.WithParams("内置参数不允许删除或修改键名"))
+}
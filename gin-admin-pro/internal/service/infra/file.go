@@ -84,7 +84,7 @@ func (s *FileService) UploadMultipleFiles(files []*multipart.FileHeader) ([]*Upl
 	}
 
 	if len(errors) > 0 {
-		return results, fmt.Errorf(strings.Join(errors, "; "))
+		return results, fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
 
 	return results, nil
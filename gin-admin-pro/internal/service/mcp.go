@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+
+	"gin-admin-pro/internal/pkg/config"
+	aiservice "gin-admin-pro/internal/service/ai"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/mcp"
+	"gin-admin-pro/plugin/mysql"
+)
+
+// mcpServerVersion MCP 服务端版本，工具增减时更新
+const mcpServerVersion = "1.0.0"
+
+// initMCPServer 创建 MCP 服务端，工具来自 AI 工具服务，认证支持配置的 API Key 和登录令牌
+func initMCPServer(mcpConfig config.MCPConfig, tools *aiservice.ToolService) *mcp.Server {
+	apiKeys := make(map[string]uint, len(mcpConfig.APIKeys))
+	for _, apiKey := range mcpConfig.APIKeys {
+		if apiKey.Key != "" && apiKey.UserID > 0 {
+			apiKeys[apiKey.Key] = apiKey.UserID
+		}
+	}
+
+	name := config.GetConfig().Server.Name
+	if name == "" {
+		name = "gin-admin-pro"
+	}
+	server := mcp.NewServer(mcp.Implementation{Name: name, Version: mcpServerVersion}, tools, aiservice.NewMCPAuthenticator(tools, apiKeys))
+	server.SetInstructions(aiservice.MCPInstructions)
+	server.SetAllowedOrigins(mcpConfig.AllowedOrigins)
+	return server
+}
+
+// NewStdioMCPServer 为 stdio 方式创建 MCP 服务端，只连接 MySQL，不启动定时任务等后台服务。
+// SQL 日志默认写入标准输出，会破坏 stdio 协议，因此关闭 SQL 日志。返回的 cleanup 用于关闭数据库连接
+func NewStdioMCPServer() (*mcp.Server, func(), error) {
+	cfg := config.GetConfig()
+
+	mysqlConfig := newMySQLConfig(cfg.Database.MySQL)
+	mysqlConfig.LogLevel = "silent"
+	mysqlClient, err := mysql.NewClient(mysqlConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化MySQL客户端失败: %w", err)
+	}
+
+	// 字典使用进程内缓存，不依赖 Redis
+	dictService := dict.NewPlugin(mysqlClient.GetDB(), nil).GetService()
+	tools, err := initAIToolService(mysqlClient, dictService)
+	if err != nil {
+		mysqlClient.Close()
+		return nil, nil, fmt.Errorf("初始化MCP工具失败: %w", err)
+	}
+	return initMCPServer(cfg.MCP, tools), func() { mysqlClient.Close() }, nil
+}
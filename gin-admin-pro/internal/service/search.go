@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-admin-pro/internal/pkg/config"
+	searchservice "gin-admin-pro/internal/service/search"
+	"gin-admin-pro/plugin/elasticsearch"
+	"gin-admin-pro/plugin/mysql"
+)
+
+// initSearchService 初始化全局搜索：注册变更回调、创建索引并启动同步任务，ctx 取消时同步任务退出
+func initSearchService(ctx context.Context, cfg *config.Config, mysqlClient *mysql.Client) (*searchservice.Service, error) {
+	syncer, client, err := newSearchSyncer(cfg, mysqlClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := searchservice.RegisterCallbacks(mysqlClient.GetDB(), searchservice.DefaultEntities()); err != nil {
+		return nil, fmt.Errorf("注册搜索同步回调失败: %w", err)
+	}
+	if err := syncer.EnsureIndex(ctx); err != nil {
+		return nil, fmt.Errorf("创建搜索索引失败: %w", err)
+	}
+	go syncer.Run(ctx)
+
+	return searchservice.NewService(client, searchservice.DefaultEntities(), searchservice.NewViewerLoader(mysqlClient.GetDB()), newSearchConfig(cfg.Search)), nil
+}
+
+// newSearchSyncer 创建 Elasticsearch 客户端和索引同步，并创建发件箱表
+func newSearchSyncer(cfg *config.Config, mysqlClient *mysql.Client) (*searchservice.Syncer, *elasticsearch.Client, error) {
+	esConfig := elasticsearch.DefaultConfig()
+	esConfig.Enabled = true
+	esConfig.Addresses = cfg.Database.Elasticsearch.URLs
+	esConfig.Username = cfg.Database.Elasticsearch.Username
+	esConfig.Password = cfg.Database.Elasticsearch.Password
+	client, err := elasticsearch.NewClient(esConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化Elasticsearch客户端失败: %w", err)
+	}
+
+	syncer := searchservice.NewSyncer(mysqlClient.GetDB(), client, searchservice.DefaultEntities(), newSearchConfig(cfg.Search))
+	if err := syncer.Migrate(); err != nil {
+		return nil, nil, fmt.Errorf("创建搜索发件箱表失败: %w", err)
+	}
+	return syncer, client, nil
+}
+
+// newSearchConfig 将应用配置转换为搜索配置，未配置的项使用默认值
+func newSearchConfig(searchConfig config.SearchConfig) *searchservice.Config {
+	return &searchservice.Config{
+		IndexPrefix:     searchConfig.IndexPrefix,
+		Analyzer:        searchConfig.Analyzer,
+		SyncInterval:    time.Duration(searchConfig.SyncInterval) * time.Second,
+		BatchSize:       searchConfig.BatchSize,
+		OutboxRetention: time.Duration(searchConfig.OutboxRetention) * time.Hour,
+	}
+}
+
+// NewSearchSyncer 为重建索引等命令创建索引同步，只连接 MySQL 和 Elasticsearch。
+// 返回的 cleanup 用于关闭数据库连接
+func NewSearchSyncer() (*searchservice.Syncer, func(), error) {
+	cfg := config.GetConfig()
+	mysqlClient, err := mysql.NewClient(newMySQLConfig(cfg.Database.MySQL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化MySQL客户端失败: %w", err)
+	}
+	syncer, _, err := newSearchSyncer(cfg, mysqlClient)
+	if err != nil {
+		mysqlClient.Close()
+		return nil, nil, err
+	}
+	return syncer, func() { mysqlClient.Close() }, nil
+}
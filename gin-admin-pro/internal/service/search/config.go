@@ -0,0 +1,58 @@
+package search
+
+import "time"
+
+// Config 全局搜索配置
+type Config struct {
+	// IndexPrefix 索引前缀，搜索使用别名 <IndexPrefix>_search，重建索引时切换到新的索引
+	IndexPrefix string
+	// Analyzer 标题和内容字段的分词器，安装 IK 插件后可使用 ik_max_word
+	Analyzer string
+	// SyncInterval 发件箱轮询间隔
+	SyncInterval time.Duration
+	// BatchSize 每批同步和重建索引的记录数
+	BatchSize int
+	// OutboxRetention 已同步的发件箱记录保留时间，重建索引时重放这段时间内的变更
+	OutboxRetention time.Duration
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		IndexPrefix:     "gin_admin",
+		Analyzer:        "standard",
+		SyncInterval:    2 * time.Second,
+		BatchSize:       500,
+		OutboxRetention: 24 * time.Hour,
+	}
+}
+
+// normalize 未设置的项使用默认值
+func (c *Config) normalize() *Config {
+	defaults := DefaultConfig()
+	if c == nil {
+		return defaults
+	}
+	cfg := *c
+	if cfg.IndexPrefix == "" {
+		cfg.IndexPrefix = defaults.IndexPrefix
+	}
+	if cfg.Analyzer == "" {
+		cfg.Analyzer = defaults.Analyzer
+	}
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = defaults.SyncInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.OutboxRetention <= 0 {
+		cfg.OutboxRetention = defaults.OutboxRetention
+	}
+	return &cfg
+}
+
+// alias 搜索别名
+func (c *Config) alias() string {
+	return c.IndexPrefix + "_search"
+}
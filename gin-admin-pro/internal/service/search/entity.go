@@ -0,0 +1,287 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-admin-pro/internal/model/system"
+	"gin-admin-pro/plugin/dataperm"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/operlog"
+
+	"gorm.io/gorm"
+)
+
+// 可搜索的数据类型
+const (
+	TypeUser     = "user"
+	TypeDept     = "dept"
+	TypeMenu     = "menu"
+	TypeDictData = "dict_data"
+	TypeOperLog  = "oper_log"
+)
+
+// 菜单类型，只索引目录和菜单，按钮不参与搜索
+const (
+	menuTypeDir  = 1
+	menuTypeMenu = 2
+)
+
+// Document 索引中的文档，各类数据统一为标题、内容和用于权限过滤的字段
+type Document struct {
+	Type    string `json:"type"`
+	ID      uint   `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// DeptID 所属部门，用户为所在部门，部门为自身，用于数据权限过滤
+	DeptID uint `json:"deptId,omitempty"`
+	// DeptName 部门名称，操作日志只记录了部门名称，按名称过滤数据权限
+	DeptName string `json:"deptName,omitempty"`
+	// Owner 数据所属用户名，用户为自身，操作日志为操作人，用于仅本人数据权限
+	Owner string `json:"owner,omitempty"`
+	// Path 菜单路由地址
+	Path   string    `json:"path,omitempty"`
+	Status int       `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// docID 索引中的文档ID
+func (d *Document) docID() string {
+	return docID(d.Type, d.ID)
+}
+
+func docID(entityType string, id uint) string {
+	return fmt.Sprintf("%s:%d", entityType, id)
+}
+
+// Entity 可搜索的数据类型
+type Entity struct {
+	Type string
+	Name string
+	// Model 数据模型，该表的新增、修改、删除写入发件箱
+	Model interface{}
+	// Permission 搜索该类型需要的权限标识，为空时不限制
+	Permission string
+	// Load 按ID读取需要索引的文档，不存在或不需要索引的记录不返回，同步时删除其文档
+	Load func(db *gorm.DB, ids []uint) ([]Document, error)
+	// Related 其他类型的数据变更后需要重新索引的本类型记录，如部门改名后其下用户的部门名称
+	Related map[string]func(db *gorm.DB, ids []uint) ([]uint, error)
+	// Filter 返回搜索用户可见本类型文档的过滤条件，ok 为 false 表示不可见
+	Filter func(viewer *Viewer) (filters []interface{}, ok bool)
+}
+
+// DefaultEntities 全局搜索的数据类型：用户、部门、菜单、字典数据和操作日志
+func DefaultEntities() []*Entity {
+	return []*Entity{
+		{
+			Type:       TypeUser,
+			Name:       "用户",
+			Model:      &system.User{},
+			Permission: "system:user:list",
+			Load:       loadUsers,
+			Related: map[string]func(db *gorm.DB, ids []uint) ([]uint, error){
+				TypeDept: func(db *gorm.DB, ids []uint) ([]uint, error) {
+					var userIDs []uint
+					err := db.Model(&system.User{}).Where("dept_id IN ?", ids).Pluck("id", &userIDs).Error
+					return userIDs, err
+				},
+			},
+			Filter: func(viewer *Viewer) ([]interface{}, bool) {
+				return viewer.deptFilter("deptId", term("id", viewer.UserID))
+			},
+		},
+		{
+			Type:       TypeDept,
+			Name:       "部门",
+			Model:      &system.Dept{},
+			Permission: "system:dept:list",
+			Load:       loadDepts,
+			Filter: func(viewer *Viewer) ([]interface{}, bool) {
+				// 仅本人数据权限时只能看到本部门，与部门列表一致
+				if viewer.DataScope == dataperm.DataScopeSelf && viewer.DeptID == 0 {
+					return nil, false
+				}
+				return viewer.deptFilter("deptId", term("deptId", viewer.DeptID))
+			},
+		},
+		{
+			Type:  TypeMenu,
+			Name:  "菜单",
+			Model: &system.Menu{},
+			Load:  loadMenus,
+			Filter: func(viewer *Viewer) ([]interface{}, bool) {
+				// 只能搜索到角色已分配的菜单
+				if viewer.IsSuper() {
+					return nil, true
+				}
+				if len(viewer.MenuIDs) == 0 {
+					return nil, false
+				}
+				return []interface{}{terms("id", viewer.MenuIDs)}, true
+			},
+		},
+		{
+			Type:       TypeDictData,
+			Name:       "字典数据",
+			Model:      &dict.DictData{},
+			Permission: "system:dict:list",
+			Load:       loadDictData,
+			Filter: func(viewer *Viewer) ([]interface{}, bool) {
+				return nil, true
+			},
+		},
+		{
+			Type:       TypeOperLog,
+			Name:       "操作日志",
+			Model:      &operlog.OperLog{},
+			Permission: "monitor:operlog:list",
+			Load:       loadOperLogs,
+			Filter: func(viewer *Viewer) ([]interface{}, bool) {
+				if viewer.DataScope == dataperm.DataScopeSelf {
+					return []interface{}{term("owner", viewer.Username)}, true
+				}
+				if viewer.DataScope == dataperm.DataScopeAll {
+					return nil, true
+				}
+				if len(viewer.DeptNames) == 0 {
+					return nil, false
+				}
+				return []interface{}{terms("deptName", viewer.DeptNames)}, true
+			},
+		},
+	}
+}
+
+// loadUsers 读取用户，内容包含用户名、手机号、邮箱和部门名称
+func loadUsers(db *gorm.DB, ids []uint) ([]Document, error) {
+	var users []system.User
+	if err := db.Preload("Dept").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(users))
+	for i, user := range users {
+		var deptName string
+		if user.Dept != nil {
+			deptName = user.Dept.Name
+		}
+		title := user.Nickname
+		if title == "" {
+			title = user.Username
+		}
+		docs[i] = Document{
+			Type:     TypeUser,
+			ID:       user.ID,
+			Title:    title,
+			Content:  joinFields(user.Username, user.Mobile, user.Email, deptName),
+			DeptID:   user.DeptID,
+			DeptName: deptName,
+			Owner:    user.Username,
+			Status:   user.Status,
+			Time:     user.CreatedAt,
+		}
+	}
+	return docs, nil
+}
+
+// loadDepts 读取部门
+func loadDepts(db *gorm.DB, ids []uint) ([]Document, error) {
+	var depts []system.Dept
+	if err := db.Where("id IN ?", ids).Find(&depts).Error; err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(depts))
+	for i, dept := range depts {
+		docs[i] = Document{
+			Type:     TypeDept,
+			ID:       dept.ID,
+			Title:    dept.Name,
+			Content:  joinFields(dept.Phone, dept.Email, dept.Remark),
+			DeptID:   dept.ID,
+			DeptName: dept.Name,
+			Status:   dept.Status,
+			Time:     dept.CreatedAt,
+		}
+	}
+	return docs, nil
+}
+
+// loadMenus 读取启用的目录和菜单，内容包含路由地址、组件和权限标识
+func loadMenus(db *gorm.DB, ids []uint) ([]Document, error) {
+	var menus []system.Menu
+	err := db.Where("id IN ? AND type IN ? AND status = ?", ids, []int{menuTypeDir, menuTypeMenu}, 1).Find(&menus).Error
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(menus))
+	for i, menu := range menus {
+		docs[i] = Document{
+			Type:    TypeMenu,
+			ID:      menu.ID,
+			Title:   menu.Name,
+			Content: joinFields(menu.Path, menu.Component, menu.Perms),
+			Path:    menu.Path,
+			Status:  menu.Status,
+			Time:    menu.CreatedAt,
+		}
+	}
+	return docs, nil
+}
+
+// loadDictData 读取字典数据，内容包含字典类型和字典值
+func loadDictData(db *gorm.DB, ids []uint) ([]Document, error) {
+	var data []dict.DictData
+	if err := db.Where("id IN ?", ids).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(data))
+	for i, item := range data {
+		docs[i] = Document{
+			Type:    TypeDictData,
+			ID:      uint(item.ID),
+			Title:   item.Label,
+			Content: joinFields(item.DictType, item.Value, item.Remark),
+			Status:  item.Status,
+			Time:    item.CreatedAt,
+		}
+	}
+	return docs, nil
+}
+
+// loadOperLogs 读取操作日志，不索引请求参数和响应内容
+func loadOperLogs(db *gorm.DB, ids []uint) ([]Document, error) {
+	var logs []operlog.OperLog
+	if err := db.Where("id IN ?", ids).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(logs))
+	for i, log := range logs {
+		docs[i] = Document{
+			Type:     TypeOperLog,
+			ID:       log.ID,
+			Title:    log.Title,
+			Content:  joinFields(log.OperName, log.RequestMethod, log.OperUrl, log.ErrorMsg),
+			DeptName: log.DeptName,
+			Owner:    log.OperName,
+			Status:   log.Status,
+			Time:     log.OperTime,
+		}
+	}
+	return docs, nil
+}
+
+// joinFields 以空格连接非空字段
+func joinFields(fields ...string) string {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != "" {
+			values = append(values, field)
+		}
+	}
+	return strings.Join(values, " ")
+}
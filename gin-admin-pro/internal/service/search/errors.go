@@ -0,0 +1,11 @@
+package search
+
+import (
+	"gin-admin-pro/internal/pkg/errcode"
+)
+
+// 注册全局搜索哨兵错误对应的错误码
+func init() {
+	errcode.Register(ErrSearchDisabled, errcode.ErrServiceUnavailable)
+	errcode.Register(ErrInvalidType, errcode.ErrParam.WithParams("types"))
+}
@@ -0,0 +1,139 @@
+package search
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbox 搜索发件箱，业务数据变更时在同一事务中写入，由同步任务读取后更新索引
+type Outbox struct {
+	ID       uint   `gorm:"primarykey" json:"id"`
+	Entity   string `gorm:"size:32;not null" json:"entity"`
+	EntityID uint   `gorm:"not null" json:"entityId"`
+	// ProcessedAt 同步时间，未同步时为空
+	ProcessedAt *time.Time `gorm:"index" json:"processedAt"`
+	CreatedAt   time.Time  `gorm:"index" json:"createdAt"`
+}
+
+// TableName 设置表名
+func (Outbox) TableName() string {
+	return "search_outbox"
+}
+
+// 发件箱回调名称
+const (
+	callbackCreate = "search:outbox_create"
+	callbackUpdate = "search:outbox_update"
+	callbackDelete = "search:outbox_delete"
+)
+
+// RegisterCallbacks 注册 GORM 回调，entities 对应表的新增、修改、删除在同一事务中写入发件箱。
+// 修改和删除在执行前按主键或查询条件确定受影响的记录；原生 SQL（Exec、Raw）不经过回调，需重建索引
+func RegisterCallbacks(db *gorm.DB, entities []*Entity) error {
+	tables := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(entity.Model); err != nil {
+			return err
+		}
+		tables[stmt.Schema.Table] = entity.Type
+	}
+
+	record := func(collect func(db *gorm.DB) []uint) func(db *gorm.DB) {
+		return func(db *gorm.DB) {
+			if db.Error != nil || db.Statement.Schema == nil {
+				return
+			}
+			entityType, ok := tables[db.Statement.Schema.Table]
+			if !ok {
+				return
+			}
+			ids := collect(db)
+			if len(ids) == 0 {
+				return
+			}
+			rows := make([]Outbox, len(ids))
+			for i, id := range ids {
+				rows[i] = Outbox{Entity: entityType, EntityID: id}
+			}
+			if err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&rows).Error; err != nil {
+				_ = db.AddError(err)
+			}
+		}
+	}
+
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register(callbackCreate, record(primaryKeys)); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:begin_transaction").Before("gorm:update").
+		Register(callbackUpdate, record(affectedIDs)); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:begin_transaction").Before("gorm:delete").
+		Register(callbackDelete, record(affectedIDs))
+}
+
+// affectedIDs 修改或删除影响的记录：模型带主键时取主键，否则按查询条件查出（包含已软删除的记录）
+func affectedIDs(db *gorm.DB) []uint {
+	if ids := primaryKeys(db); len(ids) > 0 {
+		return ids
+	}
+
+	stmt := db.Statement
+	where, ok := stmt.Clauses["WHERE"]
+	field := stmt.Schema.PrioritizedPrimaryField
+	if !ok || field == nil {
+		return nil
+	}
+	var ids []uint
+	if err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(stmt.Table).Clauses(where.Expression).Pluck(field.DBName, &ids).Error; err != nil {
+		_ = db.AddError(err)
+		return nil
+	}
+	return ids
+}
+
+// primaryKeys 读取语句中模型的主键，模型为切片时读取每一项
+func primaryKeys(db *gorm.DB) []uint {
+	stmt := db.Statement
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil
+	}
+
+	var ids []uint
+	add := func(value reflect.Value) {
+		if v, zero := field.ValueOf(stmt.Context, value); !zero {
+			if id, ok := toID(v); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	switch value := reflect.Indirect(stmt.ReflectValue); value.Kind() {
+	case reflect.Struct:
+		add(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := reflect.Indirect(value.Index(i)); item.Kind() == reflect.Struct {
+				add(item)
+			}
+		}
+	}
+	return ids
+}
+
+// toID 将整数主键转换为 uint
+func toID(value interface{}) (uint, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(v.Int()), v.Int() > 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(v.Uint()), v.Uint() > 0
+	}
+	return 0, false
+}
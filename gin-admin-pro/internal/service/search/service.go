@@ -0,0 +1,238 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gin-admin-pro/plugin/elasticsearch"
+)
+
+var (
+	// ErrSearchDisabled 全局搜索未启用
+	ErrSearchDisabled = errors.New("全局搜索未启用")
+	// ErrInvalidType 不支持的搜索类型
+	ErrInvalidType = errors.New("不支持的搜索类型")
+)
+
+// 搜索结果高亮标签
+const (
+	highlightPreTag  = "<em>"
+	highlightPostTag = "</em>"
+)
+
+// SearchReq 全局搜索请求
+type SearchReq struct {
+	Keyword string `form:"keyword" binding:"required,max=100"`
+	// Types 搜索的数据类型，为空时搜索全部有权限的类型
+	Types    []string `form:"types"`
+	PageNo   int      `form:"pageNo" binding:"omitempty,min=1,max=200"`
+	PageSize int      `form:"pageSize" binding:"omitempty,min=1,max=50"`
+}
+
+// SearchResult 全局搜索结果
+type SearchResult struct {
+	Total int `json:"total"`
+	// Counts 各类型的命中数，用于前端分类标签
+	Counts map[string]int `json:"counts"`
+	List   []SearchItem   `json:"list"`
+}
+
+// SearchItem 搜索结果项
+type SearchItem struct {
+	Type     string `json:"type"`
+	TypeName string `json:"typeName"`
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	// Highlight 命中关键词的片段，原文已做 HTML 转义，关键词使用 <em> 标签包裹
+	Highlight string    `json:"highlight,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Service 全局搜索服务层，按用户的菜单权限和数据权限过滤结果
+type Service struct {
+	client     *elasticsearch.Client
+	entities   []*Entity
+	loadViewer ViewerLoader
+	config     *Config
+}
+
+// NewService 创建全局搜索服务，client 为 nil 或未启用时搜索返回 ErrSearchDisabled
+func NewService(client *elasticsearch.Client, entities []*Entity, loadViewer ViewerLoader, config *Config) *Service {
+	return &Service{
+		client:     client,
+		entities:   entities,
+		loadViewer: loadViewer,
+		config:     config.normalize(),
+	}
+}
+
+// Search 搜索用户有权查看的数据，未启用搜索（s 为 nil）时返回 ErrSearchDisabled
+func (s *Service) Search(ctx context.Context, userID uint, req *SearchReq) (*SearchResult, error) {
+	if s == nil || s.client == nil || !s.client.IsEnabled() {
+		return nil, ErrSearchDisabled
+	}
+
+	entities, err := s.selectEntities(req.Types)
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := s.loadViewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个类型一个条件：类型匹配且满足该类型的权限过滤，任一条件满足即可见
+	var visible []interface{}
+	names := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		if !viewer.HasPermission(entity.Permission) {
+			continue
+		}
+		filters, ok := entity.Filter(viewer)
+		if !ok {
+			continue
+		}
+		must := append([]interface{}{term("type", entity.Type)}, filters...)
+		visible = append(visible, map[string]interface{}{"bool": map[string]interface{}{"filter": must}})
+		names[entity.Type] = entity.Name
+	}
+
+	result := &SearchResult{Counts: map[string]int{}, List: []SearchItem{}}
+	if len(visible) == 0 {
+		return result, nil
+	}
+
+	pageNo, pageSize := req.PageNo, req.PageSize
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	resp, err := s.client.Search(ctx, s.config.alias(), &elasticsearch.SearchRequest{
+		Query: map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":    strings.TrimSpace(req.Keyword),
+						"fields":   []string{"title^3", "content"},
+						"operator": "and",
+					},
+				},
+				"filter": map[string]interface{}{
+					"bool": map[string]interface{}{"should": visible, "minimum_should_match": 1},
+				},
+			},
+		},
+		From: (pageNo - 1) * pageSize,
+		Size: pageSize,
+		Sort: []interface{}{"_score", map[string]interface{}{"time": "desc"}},
+		Highlight: &elasticsearch.Highlight{
+			Fields:            []string{"title", "content"},
+			PreTags:           []string{highlightPreTag},
+			PostTags:          []string{highlightPostTag},
+			FragmentSize:      100,
+			NumberOfFragments: 1,
+			// 昵称、操作地址等由用户填写，转义原文后片段才能作为 HTML 渲染
+			Encoder: "html",
+		},
+		Aggregations: map[string]interface{}{
+			"types": map[string]interface{}{"terms": map[string]interface{}{"field": "type", "size": len(entities)}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Total = resp.Hits.Total.Value
+	buckets, err := resp.Terms("types")
+	if err != nil {
+		return nil, err
+	}
+	for _, bucket := range buckets {
+		if entityType, ok := bucket.Key.(string); ok {
+			result.Counts[entityType] = bucket.DocCount
+		}
+	}
+	for i := range resp.Hits.Hits {
+		hit := &resp.Hits.Hits[i]
+		var doc Document
+		if err := hit.Decode(&doc); err != nil {
+			return nil, err
+		}
+		item := SearchItem{
+			Type:     doc.Type,
+			TypeName: names[doc.Type],
+			ID:       doc.ID,
+			Title:    doc.Title,
+			Content:  doc.Content,
+			Path:     doc.Path,
+			Time:     doc.Time,
+		}
+		if fragments := hit.Highlight["title"]; len(fragments) > 0 {
+			item.Highlight = fragments[0]
+		} else if fragments := hit.Highlight["content"]; len(fragments) > 0 {
+			item.Highlight = fragments[0]
+		}
+		result.List = append(result.List, item)
+	}
+	return result, nil
+}
+
+// Types 用户可搜索的数据类型
+func (s *Service) Types(ctx context.Context, userID uint) ([]map[string]string, error) {
+	if s == nil {
+		return nil, ErrSearchDisabled
+	}
+	viewer, err := s.loadViewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]map[string]string, 0, len(s.entities))
+	for _, entity := range s.entities {
+		if !viewer.HasPermission(entity.Permission) {
+			continue
+		}
+		if _, ok := entity.Filter(viewer); ok {
+			types = append(types, map[string]string{"type": entity.Type, "name": entity.Name})
+		}
+	}
+	return types, nil
+}
+
+// selectEntities 按请求的类型筛选，types 可以是多个参数或逗号分隔
+func (s *Service) selectEntities(types []string) ([]*Entity, error) {
+	var requested []string
+	for _, value := range types {
+		for _, entityType := range strings.Split(value, ",") {
+			if entityType = strings.TrimSpace(entityType); entityType != "" {
+				requested = append(requested, entityType)
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return s.entities, nil
+	}
+
+	byType := make(map[string]*Entity, len(s.entities))
+	for _, entity := range s.entities {
+		byType[entity.Type] = entity
+	}
+	selected := make([]*Entity, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, entityType := range requested {
+		entity, ok := byType[entityType]
+		if !ok {
+			return nil, ErrInvalidType
+		}
+		if !seen[entityType] {
+			seen[entityType] = true
+			selected = append(selected, entity)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	systemservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/dataperm"
+	"gin-admin-pro/plugin/elasticsearch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeES 启动假 Elasticsearch，记录请求体并返回 handler 的响应
+func newFakeES(t *testing.T, handler func(path string, body []byte) string) (*elasticsearch.Client, *[]string) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.Method+" "+r.URL.Path+"\n"+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, handler(r.URL.Path, body))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := elasticsearch.DefaultConfig()
+	cfg.Addresses = []string{server.URL}
+	client, err := elasticsearch.NewClient(cfg)
+	require.NoError(t, err)
+	return client, &bodies
+}
+
+// superAdmin 超级管理员的角色权限
+var superAdmin = systemservice.UserPermissions{UserID: 1, Roles: []string{"super_admin"}}
+
+// staticViewer 返回固定搜索用户的 ViewerLoader
+func staticViewer(viewer *Viewer) ViewerLoader {
+	return func(ctx context.Context, userID uint) (*Viewer, error) {
+		viewer.UserID = userID
+		return viewer, nil
+	}
+}
+
+const searchResponse = `{
+	"took": 3,
+	"hits": {
+		"total": {"value": 2, "relation": "eq"},
+		"hits": [
+			{"_id": "user:7", "_score": 2.1, "_source": {"type":"user","id":7,"title":"张三","content":"zhangsan 13800000000 销售部","status":1,"time":"2024-01-02T00:00:00Z"},
+			 "highlight": {"title": ["<em>张三</em>"]}},
+			{"_id": "menu:3", "_score": 1.2, "_source": {"type":"menu","id":3,"title":"用户管理","content":"/system/user","path":"/system/user","status":1,"time":"2024-01-01T00:00:00Z"},
+			 "highlight": {"content": ["<em>张三</em>相关"]}}
+		]
+	},
+	"aggregations": {"types": {"buckets": [{"key":"user","doc_count":1},{"key":"menu","doc_count":1}]}}
+}`
+
+func TestSearch(t *testing.T) {
+	client, bodies := newFakeES(t, func(path string, body []byte) string { return searchResponse })
+	svc := NewService(client, DefaultEntities(), staticViewer(&Viewer{
+		UserPermissions: systemservice.UserPermissions{
+			Username:    "lisi",
+			DeptID:      2,
+			Permissions: []string{"system:user:list", "monitor:operlog:list"},
+			MenuIDs:     []uint{1, 3},
+		},
+		DataScope: dataperm.DataScopeDeptChild,
+		DeptIDs:   []uint{2, 5},
+		DeptNames: []string{"销售部", "销售一组"},
+	}), nil)
+
+	result, err := svc.Search(context.Background(), 9, &SearchReq{Keyword: " 张三 ", PageNo: 2, PageSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, map[string]int{"user": 1, "menu": 1}, result.Counts)
+	require.Len(t, result.List, 2)
+	assert.Equal(t, SearchItem{Type: "user", TypeName: "用户", ID: 7, Title: "张三", Content: "zhangsan 13800000000 销售部",
+		Highlight: "<em>张三</em>", Time: result.List[0].Time}, result.List[0])
+	assert.Equal(t, "/system/user", result.List[1].Path)
+	assert.Equal(t, "<em>张三</em>相关", result.List[1].Highlight)
+
+	require.Len(t, *bodies, 1)
+	assert.Contains(t, (*bodies)[0], "POST /gin_admin_search/_search\n")
+	var req map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte((*bodies)[0][len("POST /gin_admin_search/_search\n"):]), &req))
+	assert.EqualValues(t, 5, req["from"])
+	assert.EqualValues(t, 5, req["size"])
+
+	// 只有有权限的类型参与搜索，并带上各自的数据权限条件；部门和字典数据无权限
+	query, _ := json.Marshal(req["query"])
+	assert.JSONEq(t, `{"bool":{
+		"must":{"multi_match":{"query":"张三","fields":["title^3","content"],"operator":"and"}},
+		"filter":{"bool":{"minimum_should_match":1,"should":[
+			{"bool":{"filter":[{"term":{"type":"user"}},{"terms":{"deptId":[2,5]}}]}},
+			{"bool":{"filter":[{"term":{"type":"menu"}},{"terms":{"id":[1,3]}}]}},
+			{"bool":{"filter":[{"term":{"type":"oper_log"}},{"terms":{"deptName":["销售部","销售一组"]}}]}}
+		]}}
+	}}`, string(query))
+}
+
+func TestSearchHighlightEscaped(t *testing.T) {
+	// encoder 为 html 时 Elasticsearch 先转义原文再加高亮标签
+	client, bodies := newFakeES(t, func(path string, body []byte) string {
+		return `{
+			"hits": {
+				"total": {"value": 1, "relation": "eq"},
+				"hits": [{"_id": "user:8", "_score": 1, "_source": {"type":"user","id":8,"title":"<script>alert(1)</script>","status":1},
+					"highlight": {"title": ["&lt;<em>script</em>&gt;alert(1)&lt;&#x2F;script&gt;"]}}]
+			},
+			"aggregations": {"types": {"buckets": []}}
+		}`
+	})
+	svc := NewService(client, DefaultEntities(), staticViewer(&Viewer{UserPermissions: superAdmin}), nil)
+
+	result, err := svc.Search(context.Background(), 1, &SearchReq{Keyword: "script"})
+	require.NoError(t, err)
+	require.Len(t, result.List, 1)
+	assert.Equal(t, "&lt;<em>script</em>&gt;alert(1)&lt;&#x2F;script&gt;", result.List[0].Highlight)
+
+	require.Len(t, *bodies, 1)
+	var req map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte((*bodies)[0][len("POST /gin_admin_search/_search\n"):]), &req))
+	highlight, _ := req["highlight"].(map[string]interface{})
+	assert.Equal(t, "html", highlight["encoder"])
+}
+
+func TestSearchScopes(t *testing.T) {
+	var query string
+	client, _ := newFakeES(t, func(path string, body []byte) string {
+		var req map[string]interface{}
+		_ = json.Unmarshal(body, &req)
+		data, _ := json.Marshal(req["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"])
+		query = string(data)
+		return `{"hits":{"total":{"value":0},"hits":[]}}`
+	})
+
+	// 超级管理员全部数据权限时不加过滤条件
+	svc := NewService(client, DefaultEntities(), staticViewer(&Viewer{UserPermissions: superAdmin, DataScope: dataperm.DataScopeAll}), nil)
+	_, err := svc.Search(context.Background(), 1, &SearchReq{Keyword: "a", Types: []string{"user,dept", "menu"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{"minimum_should_match":1,"should":[
+		{"bool":{"filter":[{"term":{"type":"user"}}]}},
+		{"bool":{"filter":[{"term":{"type":"dept"}}]}},
+		{"bool":{"filter":[{"term":{"type":"menu"}}]}}
+	]}}`, query)
+
+	// 仅本人数据权限
+	svc = NewService(client, DefaultEntities(), staticViewer(&Viewer{
+		UserPermissions: systemservice.UserPermissions{
+			Username:    "lisi",
+			DeptID:      2,
+			Permissions: []string{"system:user:list", "system:dept:list", "monitor:operlog:list"},
+		},
+		DataScope: dataperm.DataScopeSelf,
+	}), nil)
+	_, err = svc.Search(context.Background(), 9, &SearchReq{Keyword: "a"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{"minimum_should_match":1,"should":[
+		{"bool":{"filter":[{"term":{"type":"user"}},{"term":{"id":9}}]}},
+		{"bool":{"filter":[{"term":{"type":"dept"}},{"term":{"deptId":2}}]}},
+		{"bool":{"filter":[{"term":{"type":"oper_log"}},{"term":{"owner":"lisi"}}]}}
+	]}}`, query)
+}
+
+func TestSearchNothingVisible(t *testing.T) {
+	client, bodies := newFakeES(t, func(path string, body []byte) string { return searchResponse })
+	svc := NewService(client, DefaultEntities(), staticViewer(&Viewer{DataScope: dataperm.DataScopeDept}), nil)
+
+	result, err := svc.Search(context.Background(), 9, &SearchReq{Keyword: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Total)
+	assert.Empty(t, result.List)
+	assert.Empty(t, *bodies)
+
+	types, err := svc.Types(context.Background(), 9)
+	require.NoError(t, err)
+	assert.Empty(t, types)
+}
+
+func TestSearchErrors(t *testing.T) {
+	svc := NewService(nil, DefaultEntities(), staticViewer(&Viewer{UserPermissions: superAdmin}), nil)
+	_, err := svc.Search(context.Background(), 1, &SearchReq{Keyword: "a"})
+	assert.ErrorIs(t, err, ErrSearchDisabled)
+
+	client, _ := newFakeES(t, func(path string, body []byte) string { return searchResponse })
+	svc = NewService(client, DefaultEntities(), staticViewer(&Viewer{UserPermissions: superAdmin, DataScope: dataperm.DataScopeAll}), nil)
+	_, err = svc.Search(context.Background(), 1, &SearchReq{Keyword: "a", Types: []string{"user", "role"}})
+	assert.ErrorIs(t, err, ErrInvalidType)
+
+	types, err := svc.Types(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, types, 5)
+}
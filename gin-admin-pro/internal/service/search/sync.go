@@ -0,0 +1,306 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gin-admin-pro/plugin/elasticsearch"
+
+	"gorm.io/gorm"
+)
+
+// Syncer 索引同步：读取发件箱增量更新索引，以及全量重建索引
+type Syncer struct {
+	db       *gorm.DB
+	client   *elasticsearch.Client
+	entities map[string]*Entity
+	order    []string
+	config   *Config
+}
+
+// NewSyncer 创建索引同步实例，config 为 nil 时使用默认配置
+func NewSyncer(db *gorm.DB, client *elasticsearch.Client, entities []*Entity, config *Config) *Syncer {
+	s := &Syncer{
+		db:       db,
+		client:   client,
+		entities: make(map[string]*Entity, len(entities)),
+		config:   config.normalize(),
+	}
+	for _, entity := range entities {
+		s.entities[entity.Type] = entity
+		s.order = append(s.order, entity.Type)
+	}
+	return s
+}
+
+// Migrate 创建发件箱表
+func (s *Syncer) Migrate() error {
+	return s.db.AutoMigrate(&Outbox{})
+}
+
+// Alias 搜索别名
+func (s *Syncer) Alias() string {
+	return s.config.alias()
+}
+
+// EnsureIndex 别名不存在时创建索引并指向它，已有数据需执行重建索引导入
+func (s *Syncer) EnsureIndex(ctx context.Context) error {
+	exists, err := s.client.IndexExists(ctx, s.Alias())
+	if err != nil || exists {
+		return err
+	}
+	body := s.indexBody()
+	body["aliases"] = map[string]interface{}{s.Alias(): map[string]interface{}{}}
+	err = s.client.CreateIndex(ctx, s.newIndexName(), body)
+	if errors.Is(err, elasticsearch.ErrConflict) {
+		return nil
+	}
+	return err
+}
+
+// Run 按配置的间隔同步发件箱，直到 ctx 取消
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// 积压时连续处理，直到不足一批
+		for {
+			n, err := s.ProcessOutbox(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("同步搜索索引失败: %v", err)
+				}
+				break
+			}
+			if n < s.config.BatchSize {
+				break
+			}
+		}
+		if err := s.purgeOutbox(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("清理搜索发件箱失败: %v", err)
+		}
+	}
+}
+
+// ProcessOutbox 同步一批未处理的发件箱记录，返回处理的记录数。
+// 同步读取记录的当前状态，多个实例重复处理同一记录不影响结果
+func (s *Syncer) ProcessOutbox(ctx context.Context) (int, error) {
+	var rows []Outbox
+	err := s.db.WithContext(ctx).Where("processed_at IS NULL").Order("id").Limit(s.config.BatchSize).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	if err := s.apply(ctx, s.Alias(), rows); err != nil {
+		return 0, err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	err = s.db.WithContext(ctx).Model(&Outbox{}).Where("id IN ?", ids).Update("processed_at", time.Now()).Error
+	return len(rows), err
+}
+
+// Sync 立即同步指定记录的索引，记录不存在时删除其文档
+func (s *Syncer) Sync(ctx context.Context, entityType string, ids []uint) error {
+	rows := make([]Outbox, len(ids))
+	for i, id := range ids {
+		rows[i] = Outbox{Entity: entityType, EntityID: id}
+	}
+	return s.apply(ctx, s.Alias(), rows)
+}
+
+// Reindex 全量重建索引：写入新索引后切换别名并删除旧索引，最后重放重建期间的变更
+func (s *Syncer) Reindex(ctx context.Context) error {
+	started := time.Now()
+	index := s.newIndexName()
+	if err := s.client.CreateIndex(ctx, index, s.indexBody()); err != nil {
+		return fmt.Errorf("创建索引失败: %w", err)
+	}
+
+	// 切换别名前失败时删除未完成的新索引，避免残留
+	old, err := s.switchToNewIndex(ctx, index)
+	if err != nil {
+		if delErr := s.client.DeleteIndex(context.WithoutCancel(ctx), index); delErr != nil {
+			log.Printf("删除未完成的搜索索引 %s 失败: %v", index, delErr)
+		}
+		return err
+	}
+	for _, name := range old {
+		if name != index {
+			if err := s.client.DeleteIndex(ctx, name); err != nil {
+				log.Printf("删除旧搜索索引 %s 失败: %v", name, err)
+			}
+		}
+	}
+
+	// 重建期间同步任务写入的是旧索引，切换后按发件箱重放这段时间的变更
+	var rows []Outbox
+	if err := s.db.WithContext(ctx).Where("created_at >= ?", started).Find(&rows).Error; err != nil {
+		return err
+	}
+	return s.apply(ctx, s.Alias(), rows)
+}
+
+// switchToNewIndex 写入全部文档到新索引并将别名切换到新索引，返回切换前别名指向的索引
+func (s *Syncer) switchToNewIndex(ctx context.Context, index string) ([]string, error) {
+	indexer := s.client.NewBulkIndexer(s.config.BatchSize)
+	for _, entityType := range s.order {
+		entity := s.entities[entityType]
+		var afterID uint
+		for {
+			var ids []uint
+			err := s.db.WithContext(ctx).Model(entity.Model).Where("id > ?", afterID).Order("id").Limit(s.config.BatchSize).Pluck("id", &ids).Error
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			afterID = ids[len(ids)-1]
+
+			docs, err := entity.Load(s.db.WithContext(ctx), ids)
+			if err != nil {
+				return nil, fmt.Errorf("读取%s失败: %w", entity.Name, err)
+			}
+			for i := range docs {
+				if err := indexer.Add(ctx, elasticsearch.BulkAction{Op: elasticsearch.BulkIndex, Index: index, ID: docs[i].docID(), Doc: &docs[i]}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := indexer.Flush(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.client.Refresh(ctx, index); err != nil {
+		return nil, err
+	}
+
+	old, err := s.client.GetAliasIndices(ctx, s.Alias())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.client.SwitchAlias(ctx, s.Alias(), index); err != nil {
+		return nil, fmt.Errorf("切换别名失败: %w", err)
+	}
+	return old, nil
+}
+
+// apply 按发件箱记录更新索引：存在的记录写入文档，不存在的删除文档，并重新索引受影响的关联记录
+func (s *Syncer) apply(ctx context.Context, index string, rows []Outbox) error {
+	changed := make(map[string]map[uint]bool)
+	add := func(entityType string, id uint) {
+		if changed[entityType] == nil {
+			changed[entityType] = make(map[uint]bool)
+		}
+		changed[entityType][id] = true
+	}
+	for _, row := range rows {
+		if _, ok := s.entities[row.Entity]; ok {
+			add(row.Entity, row.EntityID)
+		}
+	}
+
+	for _, entity := range s.entities {
+		for source, related := range entity.Related {
+			ids := keys(changed[source])
+			if len(ids) == 0 {
+				continue
+			}
+			relatedIDs, err := related(s.db.WithContext(ctx), ids)
+			if err != nil {
+				return err
+			}
+			for _, id := range relatedIDs {
+				add(entity.Type, id)
+			}
+		}
+	}
+
+	var actions []elasticsearch.BulkAction
+	for _, entityType := range s.order {
+		ids := keys(changed[entityType])
+		if len(ids) == 0 {
+			continue
+		}
+		docs, err := s.entities[entityType].Load(s.db.WithContext(ctx), ids)
+		if err != nil {
+			return err
+		}
+
+		found := make(map[uint]bool, len(docs))
+		for i := range docs {
+			found[docs[i].ID] = true
+			actions = append(actions, elasticsearch.BulkAction{Op: elasticsearch.BulkIndex, Index: index, ID: docs[i].docID(), Doc: &docs[i]})
+		}
+		for _, id := range ids {
+			if !found[id] {
+				actions = append(actions, elasticsearch.BulkAction{Op: elasticsearch.BulkDelete, Index: index, ID: docID(entityType, id)})
+			}
+		}
+	}
+
+	for start := 0; start < len(actions); start += s.config.BatchSize {
+		end := min(start+s.config.BatchSize, len(actions))
+		if _, err := s.client.Bulk(ctx, actions[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeOutbox 删除超过保留时间的已同步记录
+func (s *Syncer) purgeOutbox(ctx context.Context) error {
+	before := time.Now().Add(-s.config.OutboxRetention)
+	return s.db.WithContext(ctx).Where("processed_at IS NOT NULL AND processed_at < ?", before).Delete(&Outbox{}).Error
+}
+
+// indexBody 索引设置和映射
+func (s *Syncer) indexBody() map[string]interface{} {
+	text := map[string]interface{}{"type": "text", "analyzer": s.config.Analyzer}
+	keyword := map[string]interface{}{"type": "keyword"}
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": "strict",
+			"properties": map[string]interface{}{
+				"type":     keyword,
+				"id":       map[string]interface{}{"type": "long"},
+				"title":    text,
+				"content":  text,
+				"deptId":   map[string]interface{}{"type": "long"},
+				"deptName": keyword,
+				"owner":    keyword,
+				"path":     map[string]interface{}{"type": "keyword", "index": false},
+				"status":   map[string]interface{}{"type": "integer"},
+				"time":     map[string]interface{}{"type": "date"},
+			},
+		},
+	}
+}
+
+// newIndexName 带时间戳的索引名，别名指向当前使用的索引
+func (s *Syncer) newIndexName() string {
+	return fmt.Sprintf("%s_%s", s.Alias(), time.Now().Format("20060102150405"))
+}
+
+// keys 集合中的ID
+func keys(set map[uint]bool) []uint {
+	ids := make([]uint, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
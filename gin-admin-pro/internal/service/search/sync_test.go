@@ -0,0 +1,136 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gin-admin-pro/internal/model/system"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB 不连接数据库的 GORM 实例，只生成 SQL
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+// noteEntity 测试用的数据类型，只有 ID 为 1 的记录存在
+func noteEntity() *Entity {
+	return &Entity{
+		Type:  "note",
+		Name:  "笔记",
+		Model: &system.Dept{},
+		Load: func(db *gorm.DB, ids []uint) ([]Document, error) {
+			var docs []Document
+			for _, id := range ids {
+				if id == 1 {
+					docs = append(docs, Document{Type: "note", ID: 1, Title: "第一条"})
+				}
+			}
+			return docs, nil
+		},
+		Filter: func(viewer *Viewer) ([]interface{}, bool) { return nil, true },
+	}
+}
+
+func TestSync(t *testing.T) {
+	client, bodies := newFakeES(t, func(path string, body []byte) string {
+		return `{"errors":false,"items":[]}`
+	})
+	syncer := NewSyncer(newDryRunDB(t), client, []*Entity{noteEntity()}, &Config{IndexPrefix: "test"})
+	assert.Equal(t, "test_search", syncer.Alias())
+
+	// 存在的记录写入文档，不存在的删除文档
+	require.NoError(t, syncer.Sync(context.Background(), "note", []uint{1, 2}))
+	require.Len(t, *bodies, 1)
+	lines := strings.Split(strings.TrimSpace((*bodies)[0]), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "POST /_bulk", lines[0])
+	assert.JSONEq(t, `{"index":{"_index":"test_search","_id":"note:1"}}`, lines[1])
+	assert.Contains(t, lines[2], `"title":"第一条"`)
+	assert.JSONEq(t, `{"delete":{"_index":"test_search","_id":"note:2"}}`, lines[3])
+
+	// 未注册的类型忽略
+	require.NoError(t, syncer.Sync(context.Background(), "unknown", []uint{1}))
+	assert.Len(t, *bodies, 1)
+}
+
+func TestEnsureIndex(t *testing.T) {
+	client, bodies := newFakeES(t, func(path string, body []byte) string { return `{"acknowledged":true}` })
+	syncer := NewSyncer(newDryRunDB(t), client, DefaultEntities(), &Config{Analyzer: "ik_max_word"})
+
+	// 别名已存在时不创建索引
+	require.NoError(t, syncer.EnsureIndex(context.Background()))
+	require.Len(t, *bodies, 1)
+	assert.Equal(t, "HEAD /gin_admin_search\n", (*bodies)[0])
+
+	body := syncer.indexBody()
+	properties := body["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "text", "analyzer": "ik_max_word"}, properties["title"])
+	assert.True(t, strings.HasPrefix(syncer.newIndexName(), "gin_admin_search_"))
+}
+
+func TestReindexCleanup(t *testing.T) {
+	client, bodies := newFakeES(t, func(path string, body []byte) string {
+		// 查询别名返回无法解析的响应，使重建在切换别名前失败
+		if strings.HasPrefix(path, "/_alias/") {
+			return `not json`
+		}
+		return `{"acknowledged":true}`
+	})
+	syncer := NewSyncer(newDryRunDB(t), client, []*Entity{noteEntity()}, &Config{IndexPrefix: "test"})
+
+	require.Error(t, syncer.Reindex(context.Background()))
+	require.NotEmpty(t, *bodies)
+	created := strings.SplitN((*bodies)[0], "\n", 2)[0]
+	require.True(t, strings.HasPrefix(created, "PUT /test_search_"), created)
+	index := strings.TrimPrefix(created, "PUT ")
+
+	// 未切换别名的新索引被删除
+	last := (*bodies)[len(*bodies)-1]
+	assert.Equal(t, "DELETE "+index+"\n", last)
+}
+
+func TestOutboxCallbacks(t *testing.T) {
+	db := newDryRunDB(t)
+	require.NoError(t, RegisterCallbacks(db, DefaultEntities()))
+
+	// 记录写入发件箱的语句
+	var outbox []Outbox
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(db *gorm.DB) {
+		if rows, ok := db.Statement.Dest.(*[]Outbox); ok {
+			outbox = append(outbox, *rows...)
+		}
+	}))
+
+	users := []system.User{{Username: "a"}, {Username: "b"}}
+	users[0].ID, users[1].ID = 3, 4
+	require.NoError(t, db.Create(&users).Error)
+
+	dept := system.Dept{}
+	dept.ID = 5
+	require.NoError(t, db.Model(&dept).Update("name", "研发部").Error)
+
+	// 不在搜索范围内的表不写入发件箱
+	role := system.Role{}
+	role.ID = 6
+	require.NoError(t, db.Model(&role).Update("name", "x").Error)
+
+	require.Len(t, outbox, 3)
+	assert.Equal(t, []Outbox{{Entity: TypeUser, EntityID: 3}, {Entity: TypeUser, EntityID: 4}, {Entity: TypeDept, EntityID: 5}},
+		[]Outbox{
+			{Entity: outbox[0].Entity, EntityID: outbox[0].EntityID},
+			{Entity: outbox[1].Entity, EntityID: outbox[1].EntityID},
+			{Entity: outbox[2].Entity, EntityID: outbox[2].EntityID},
+		})
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+
+	systemdao "gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/model/system"
+	systemservice "gin-admin-pro/internal/service/system"
+	"gin-admin-pro/plugin/dataperm"
+
+	"gorm.io/gorm"
+)
+
+// Viewer 搜索用户，按其菜单权限和数据权限过滤搜索结果；超级管理员拥有全部菜单和权限标识，数据权限仍按角色配置
+type Viewer struct {
+	systemservice.UserPermissions
+	// DataScope 数据权限范围，见 dataperm.DataScopeAll 等
+	DataScope int
+	// DeptIDs、DeptNames 数据权限范围内的部门，全部数据权限和仅本人数据权限时为空
+	DeptIDs   []uint
+	DeptNames []string
+}
+
+// deptFilter 按部门过滤的数据权限条件，仅本人数据权限时使用 self 条件
+func (v *Viewer) deptFilter(field string, self interface{}) ([]interface{}, bool) {
+	switch v.DataScope {
+	case dataperm.DataScopeAll:
+		return nil, true
+	case dataperm.DataScopeSelf:
+		return []interface{}{self}, true
+	}
+	if len(v.DeptIDs) == 0 {
+		return nil, false
+	}
+	return []interface{}{terms(field, v.DeptIDs)}, true
+}
+
+// ViewerLoader 根据用户ID加载搜索用户
+type ViewerLoader func(ctx context.Context, userID uint) (*Viewer, error)
+
+// NewViewerLoader 创建从数据库加载用户角色菜单和数据权限的 ViewerLoader，用户已禁用时返回 systemservice.ErrUserDisabled
+func NewViewerLoader(db *gorm.DB) ViewerLoader {
+	loadPermissions := systemservice.NewPermissionLoader(systemdao.NewUserDAO(db))
+	dataPerm := dataperm.NewService(db)
+	return func(ctx context.Context, userID uint) (*Viewer, error) {
+		permissions, err := loadPermissions(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		viewer := &Viewer{UserPermissions: *permissions}
+		if viewer.DataScope, err = dataPerm.GetDataScope(userID); err != nil {
+			return nil, err
+		}
+		if viewer.DataScope == dataperm.DataScopeAll || viewer.DataScope == dataperm.DataScopeSelf {
+			return viewer, nil
+		}
+		if viewer.DeptIDs, err = dataPerm.GetDataScopeDeptIDs(userID); err != nil {
+			return nil, err
+		}
+		if len(viewer.DeptIDs) > 0 {
+			err = db.WithContext(ctx).Model(&system.Dept{}).Where("id IN ?", viewer.DeptIDs).Pluck("name", &viewer.DeptNames).Error
+		}
+		return viewer, err
+	}
+}
+
+// term 精确匹配条件
+func term(field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}
+}
+
+// terms 多值匹配条件
+func terms(field string, values interface{}) map[string]interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}
+}
@@ -8,6 +8,16 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrDeptIDRequired     = errors.New("部门ID不能为空")
+	ErrDeptNotFound       = errors.New("部门不存在")
+	ErrDeptNameRequired   = errors.New("部门名称不能为空")
+	ErrDeptNameExists     = errors.New("同级下已存在相同名称的部门")
+	ErrDeptParentSelf     = errors.New("不能将部门设置为自己的子部门")
+	ErrDeptCircularParent = errors.New("不能将部门设置为自己的子部门，会造成循环引用")
+	ErrDeptCannotDelete   = errors.New("部门不存在或存在子部门，无法删除")
+)
+
 // DeptService 部门服务层
 type DeptService struct {
 	deptDAO *system.DeptDAO
@@ -26,13 +36,13 @@ func (s *DeptService) GetList(req *system.DeptListReq) ([]system.DeptResp, error
 // GetByID 根据ID获取部门详情
 func (s *DeptService) GetByID(id uint) (*system.DeptDetailResp, error) {
 	if id == 0 {
-		return nil, errors.New("部门ID不能为空")
+		return nil, ErrDeptIDRequired
 	}
 
 	dept, err := s.deptDAO.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("部门不存在")
+			return nil, ErrDeptNotFound
 		}
 		return nil, err
 	}
@@ -44,7 +54,7 @@ func (s *DeptService) GetByID(id uint) (*system.DeptDetailResp, error) {
 func (s *DeptService) Create(req *system.CreateDeptReq, createBy uint) (uint, error) {
 	// 参数验证
 	if req.Name == "" {
-		return 0, errors.New("部门名称不能为空")
+		return 0, ErrDeptNameRequired
 	}
 
 	// 检查同级下部门名称是否重复
@@ -53,7 +63,7 @@ func (s *DeptService) Create(req *system.CreateDeptReq, createBy uint) (uint, er
 		return 0, err
 	}
 	if exists {
-		return 0, errors.New("同级下已存在相同名称的部门")
+		return 0, ErrDeptNameExists
 	}
 
 	// 如果没有指定排序，自动获取最大排序值+1
@@ -77,21 +87,21 @@ func (s *DeptService) Create(req *system.CreateDeptReq, createBy uint) (uint, er
 // Update 更新部门
 func (s *DeptService) Update(req *system.UpdateDeptReq, updateBy uint) error {
 	if req.ID == 0 {
-		return errors.New("部门ID不能为空")
+		return ErrDeptIDRequired
 	}
 
 	// 获取原部门信息
 	dept, err := s.deptDAO.GetByID(req.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("部门不存在")
+			return ErrDeptNotFound
 		}
 		return err
 	}
 
 	// 检查是否将部门设置为自己的子部门
 	if req.ParentID != nil && *req.ParentID == req.ID {
-		return errors.New("不能将部门设置为自己的子部门")
+		return ErrDeptParentSelf
 	}
 
 	// 检查是否造成循环引用
@@ -118,7 +128,7 @@ func (s *DeptService) Update(req *system.UpdateDeptReq, updateBy uint) error {
 			return err
 		}
 		if exists {
-			return errors.New("同级下已存在相同名称的部门")
+			return ErrDeptNameExists
 		}
 	}
 
@@ -133,13 +143,13 @@ func (s *DeptService) Update(req *system.UpdateDeptReq, updateBy uint) error {
 // Delete 删除部门
 func (s *DeptService) Delete(id uint) error {
 	if id == 0 {
-		return errors.New("部门ID不能为空")
+		return ErrDeptIDRequired
 	}
 
 	err := s.deptDAO.Delete(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("部门不存在或存在子部门，无法删除")
+			return ErrDeptCannotDelete
 		}
 		return err
 	}
@@ -155,7 +165,7 @@ func (s *DeptService) GetAllSimpleList() ([]system.DeptSimpleResp, error) {
 // GetUsersByDept 获取部门下的用户列表
 func (s *DeptService) GetUsersByDept(deptID uint) ([]usermodel.User, error) {
 	if deptID == 0 {
-		return nil, errors.New("部门ID不能为空")
+		return nil, ErrDeptIDRequired
 	}
 
 	return s.deptDAO.GetUsersByDept(deptID)
@@ -172,7 +182,7 @@ func (s *DeptService) checkCircularReference(deptID, parentID uint) error {
 	// 检查当前部门是否在父级部门链中
 	for _, parent := range parentChain {
 		if parent.ID == deptID {
-			return errors.New("不能将部门设置为自己的子部门，会造成循环引用")
+			return ErrDeptCircularParent
 		}
 	}
 
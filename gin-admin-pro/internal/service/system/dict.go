@@ -0,0 +1,233 @@
+package system
+
+import (
+	"errors"
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/plugin/dict"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDictTypeNotFound = errors.New("字典类型不存在")
+	ErrDictDataNotFound = errors.New("字典数据不存在")
+)
+
+// DictTypePageReq 字典类型分页请求
+type DictTypePageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Name     string `form:"name"`
+	Type     string `form:"type"`
+	Status   *int   `form:"status"`
+}
+
+// DictTypeSaveReq 字典类型创建/更新请求
+type DictTypeSaveReq struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name" binding:"required"`
+	Type   string `json:"type" binding:"required"`
+	Status int    `json:"status" binding:"oneof=0 1"`
+	Remark string `json:"remark"`
+}
+
+// DictDataPageReq 字典数据分页请求
+type DictDataPageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	DictType string `form:"dictType"`
+	Label    string `form:"label"`
+	Status   *int   `form:"status"`
+}
+
+// DictDataSaveReq 字典数据创建/更新请求
+type DictDataSaveReq struct {
+	ID       int    `json:"id"`
+	DictSort int    `json:"sort"`
+	Label    string `json:"label" binding:"required"`
+	Value    string `json:"value" binding:"required"`
+	DictType string `json:"dictType" binding:"required"`
+	Status   int    `json:"status" binding:"oneof=0 1"`
+	Remark   string `json:"remark"`
+}
+
+// DictService 字典服务层
+type DictService struct {
+	dictService *dict.Service
+}
+
+// NewDictService 创建字典服务实例
+func NewDictService(dictService *dict.Service) *DictService {
+	return &DictService{dictService: dictService}
+}
+
+// GetTypePage 获取字典类型分页列表
+func (s *DictService) GetTypePage(req *DictTypePageReq) (*model.PageResp, error) {
+	pageNo, pageSize := normalizeDictPage(req.PageNo, req.PageSize)
+	list, total, err := s.dictService.GetDictTypes(pageNo, pageSize, req.Name, req.Type, statusOrAll(req.Status))
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetType 获取字典类型详情
+func (s *DictService) GetType(id int) (*dict.DictType, error) {
+	dictType, err := s.dictService.GetDictTypeByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDictTypeNotFound
+		}
+		return nil, err
+	}
+	return dictType, nil
+}
+
+// GetTypeSimpleList 获取启用的字典类型精简列表
+func (s *DictService) GetTypeSimpleList() ([]dict.DictType, error) {
+	return s.dictService.GetDictTypesSimple()
+}
+
+// CreateType 创建字典类型
+func (s *DictService) CreateType(req *DictTypeSaveReq, operatorID uint) (int, error) {
+	dictType := &dict.DictType{
+		Name:     req.Name,
+		Type:     req.Type,
+		Status:   req.Status,
+		Remark:   req.Remark,
+		CreateBy: operatorID,
+		UpdateBy: operatorID,
+	}
+	if err := s.dictService.CreateDictType(dictType); err != nil {
+		return 0, err
+	}
+	return dictType.ID, nil
+}
+
+// UpdateType 更新字典类型
+func (s *DictService) UpdateType(req *DictTypeSaveReq, operatorID uint) error {
+	err := s.dictService.UpdateDictType(&dict.DictType{
+		ID:       req.ID,
+		Name:     req.Name,
+		Type:     req.Type,
+		Status:   req.Status,
+		Remark:   req.Remark,
+		UpdateBy: operatorID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDictTypeNotFound
+	}
+	return err
+}
+
+// DeleteType 删除字典类型
+func (s *DictService) DeleteType(id int) error {
+	if _, err := s.GetType(id); err != nil {
+		return err
+	}
+	return s.dictService.DeleteDictType(id)
+}
+
+// GetDataPage 获取字典数据分页列表
+func (s *DictService) GetDataPage(req *DictDataPageReq) (*model.PageResp, error) {
+	pageNo, pageSize := normalizeDictPage(req.PageNo, req.PageSize)
+	list, total, err := s.dictService.GetDictDataList(pageNo, pageSize, req.DictType, req.Label, statusOrAll(req.Status))
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// GetData 获取字典数据详情
+func (s *DictService) GetData(id int) (*dict.DictData, error) {
+	dictData, err := s.dictService.GetDictDataByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDictDataNotFound
+		}
+		return nil, err
+	}
+	return dictData, nil
+}
+
+// GetDataSimpleList 获取全部启用的字典数据精简列表
+func (s *DictService) GetDataSimpleList() ([]dict.DictDataSimple, error) {
+	return s.dictService.GetAllDictDataSimple()
+}
+
+// GetDataByType 获取指定类型的启用字典数据
+func (s *DictService) GetDataByType(dictType string) ([]dict.DictData, error) {
+	return s.dictService.GetDictDataByType(dictType)
+}
+
+// CreateData 创建字典数据
+func (s *DictService) CreateData(req *DictDataSaveReq, operatorID uint) (int, error) {
+	dictData := &dict.DictData{
+		DictSort: req.DictSort,
+		Label:    req.Label,
+		Value:    req.Value,
+		DictType: req.DictType,
+		Status:   req.Status,
+		Remark:   req.Remark,
+		CreateBy: operatorID,
+		UpdateBy: operatorID,
+	}
+	if err := s.dictService.CreateDictData(dictData); err != nil {
+		return 0, err
+	}
+	return dictData.ID, nil
+}
+
+// UpdateData 更新字典数据
+func (s *DictService) UpdateData(req *DictDataSaveReq, operatorID uint) error {
+	err := s.dictService.UpdateDictData(&dict.DictData{
+		ID:       req.ID,
+		DictSort: req.DictSort,
+		Label:    req.Label,
+		Value:    req.Value,
+		DictType: req.DictType,
+		Status:   req.Status,
+		Remark:   req.Remark,
+		UpdateBy: operatorID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDictDataNotFound
+	}
+	return err
+}
+
+// DeleteData 删除字典数据
+func (s *DictService) DeleteData(id int) error {
+	err := s.dictService.DeleteDictData(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDictDataNotFound
+	}
+	return err
+}
+
+// RefreshCache 刷新字典缓存
+func (s *DictService) RefreshCache() error {
+	return s.dictService.RefreshDictCache()
+}
+
+// normalizeDictPage 规范分页参数
+func normalizeDictPage(pageNo, pageSize int) (int, int) {
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return pageNo, pageSize
+}
+
+// statusOrAll 未指定状态时查询全部（插件约定 -1 表示全部）
+func statusOrAll(status *int) int {
+	if status == nil {
+		return -1
+	}
+	return *status
+}
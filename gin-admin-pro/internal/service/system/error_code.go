@@ -0,0 +1,156 @@
+package system
+
+import (
+	"errors"
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/errorcode"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// ErrorCodePageReq 错误码分页请求
+type ErrorCodePageReq struct {
+	PageNo   int    `form:"pageNo"`
+	PageSize int    `form:"pageSize"`
+	Type     string `form:"type"`
+	Name     string `form:"name"`
+	Status   *int   `form:"status"`
+}
+
+// ErrorCodeSaveReq 错误码创建/更新请求
+type ErrorCodeSaveReq struct {
+	ID       int    `json:"id"`
+	Type     string `json:"type" binding:"required"`
+	Code     int    `json:"code" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Message  string `json:"message" binding:"required"`
+	Solution string `json:"solution"`
+	Status   int    `json:"status" binding:"oneof=0 1"`
+	Remark   string `json:"remark"`
+}
+
+// ErrorCodeMessagesReq 错误码多语言消息保存请求
+type ErrorCodeMessagesReq struct {
+	Code     int               `json:"code" binding:"required"`
+	Messages map[string]string `json:"messages" binding:"required"` // 语言 -> 消息模板，消息为空表示删除
+}
+
+// ErrorCodeService 错误码服务层
+type ErrorCodeService struct {
+	errorCodeService *errorcode.Service
+}
+
+// NewErrorCodeService 创建错误码服务实例
+func NewErrorCodeService(errorCodeService *errorcode.Service) *ErrorCodeService {
+	return &ErrorCodeService{errorCodeService: errorCodeService}
+}
+
+// GetPage 获取错误码分页列表
+func (s *ErrorCodeService) GetPage(req *ErrorCodePageReq) (*model.PageResp, error) {
+	pageNo, pageSize := normalizeDictPage(req.PageNo, req.PageSize)
+	status := ""
+	if req.Status != nil {
+		status = strconv.Itoa(*req.Status)
+	}
+
+	list, total, err := s.errorCodeService.GetErrorCodes(pageNo, pageSize, req.Type, req.Name, status)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageResp{List: list, Total: total}, nil
+}
+
+// Get 获取错误码详情
+func (s *ErrorCodeService) Get(id int) (*errorcode.ErrorCode, error) {
+	errorCode, err := s.errorCodeService.GetErrorCodeByID(id)
+	if err != nil {
+		return nil, wrapErrorCodeErr(err)
+	}
+	return errorCode, nil
+}
+
+// GetTypes 获取错误类型选项
+func (s *ErrorCodeService) GetTypes() []map[string]interface{} {
+	return errorcode.GetErrorTypeOptions()
+}
+
+// GetLocales 获取支持的语言列表
+func (s *ErrorCodeService) GetLocales() []string {
+	return s.errorCodeService.GetLocales()
+}
+
+// Create 创建错误码
+func (s *ErrorCodeService) Create(req *ErrorCodeSaveReq, operatorID uint) (int, error) {
+	errorCode := &errorcode.ErrorCode{
+		Type:     req.Type,
+		Code:     req.Code,
+		Name:     req.Name,
+		Message:  req.Message,
+		Solution: req.Solution,
+		Status:   req.Status,
+		Remark:   req.Remark,
+		CreateBy: operatorID,
+		UpdateBy: operatorID,
+	}
+	if err := s.errorCodeService.CreateErrorCode(errorCode); err != nil {
+		return 0, wrapErrorCodeErr(err)
+	}
+	return errorCode.ID, nil
+}
+
+// Update 更新错误码
+func (s *ErrorCodeService) Update(req *ErrorCodeSaveReq, operatorID uint) error {
+	errorCode, err := s.Get(req.ID)
+	if err != nil {
+		return err
+	}
+
+	errorCode.Type = req.Type
+	errorCode.Code = req.Code
+	errorCode.Name = req.Name
+	errorCode.Message = req.Message
+	errorCode.Solution = req.Solution
+	errorCode.Status = req.Status
+	errorCode.Remark = req.Remark
+	errorCode.UpdateBy = operatorID
+
+	return wrapErrorCodeErr(s.errorCodeService.UpdateErrorCode(errorCode))
+}
+
+// Delete 删除错误码
+func (s *ErrorCodeService) Delete(id int) error {
+	return wrapErrorCodeErr(s.errorCodeService.DeleteErrorCode(id))
+}
+
+// GetMessages 获取错误码的多语言消息
+func (s *ErrorCodeService) GetMessages(code int) ([]errorcode.ErrorCodeMessage, error) {
+	return s.errorCodeService.GetErrorCodeMessages(code)
+}
+
+// SaveMessages 保存错误码的多语言消息
+func (s *ErrorCodeService) SaveMessages(req *ErrorCodeMessagesReq) error {
+	return wrapErrorCodeErr(s.errorCodeService.SaveErrorCodeMessages(req.Code, req.Messages))
+}
+
+// RefreshCache 刷新错误码消息缓存
+func (s *ErrorCodeService) RefreshCache() {
+	s.errorCodeService.InvalidateCache()
+}
+
+// wrapErrorCodeErr 将插件错误转换为业务错误码
+func wrapErrorCodeErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return errcode.ErrDataNotFound.WithParams("错误码")
+	case errors.Is(err, errorcode.ErrCodeExists):
+		return errcode.ErrDataExists.WithParams("错误码").Wrap(err)
+	case errors.Is(err, errorcode.ErrInvalidLocale):
+		return errcode.ErrParam.WithParams("locale").Wrap(err)
+	default:
+		return err
+	}
+}
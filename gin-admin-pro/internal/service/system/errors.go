@@ -0,0 +1,66 @@
+package system
+
+import (
+	systemdao "gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/recyclebin"
+)
+
+// init 注册系统管理服务错误对应的错误码，供 response.Fail 与 middleware.ErrorHandler 使用
+func init() {
+	// 用户
+	errcode.Register(ErrUserNotFound, errcode.ErrUserNotFound)
+	errcode.Register(ErrUsernameExists, errcode.ErrUserExists)
+	errcode.Register(ErrEmailExists, errcode.ErrDataExists.WithParams("邮箱"))
+	errcode.Register(ErrMobileExists, errcode.ErrDataExists.WithParams("手机号"))
+	errcode.Register(ErrPasswordIncorrect, errcode.ErrPasswordError)
+	errcode.Register(ErrUserDisabled, errcode.ErrUserDisabled)
+	errcode.Register(ErrInvalidCredentials, errcode.ErrInvalidCredentials)
+
+	// 角色
+	errcode.Register(ErrRoleNotFound, errcode.ErrRoleNotFound)
+	errcode.Register(ErrRoleCodeExists, errcode.ErrRoleExists)
+	errcode.Register(ErrRoleHasUsers, errcode.ErrRoleInUse)
+	errcode.Register(ErrRoleIsBuiltin, errcode.ErrRoleBuiltin)
+	errcode.Register(ErrInvalidDataScope, errcode.ErrDataInvalid.WithParams("dataScope"))
+
+	// 部门
+	errcode.Register(ErrDeptIDRequired, errcode.ErrParam.WithParams("id"))
+	errcode.Register(ErrDeptNotFound, errcode.ErrDataNotFound.WithParams("部门"))
+	errcode.Register(ErrDeptNameRequired, errcode.ErrParam.WithParams("name"))
+	errcode.Register(ErrDeptNameExists, errcode.ErrDataExists.WithParams("部门名称"))
+
+	// 菜单
+	errcode.Register(ErrMenuIDRequired, errcode.ErrParam.WithParams("id"))
+	errcode.Register(ErrMenuNotFound, errcode.ErrDataNotFound.WithParams("菜单"))
+	errcode.Register(ErrMenuNameRequired, errcode.ErrParam.WithParams("name"))
+	errcode.Register(ErrMenuTypeInvalid, errcode.ErrDataInvalid.WithParams("type"))
+	errcode.Register(ErrMenuNameExists, errcode.ErrDataExists.WithParams("菜单名称"))
+	errcode.Register(ErrMenuUserIDRequired, errcode.ErrParam.WithParams("userId"))
+	errcode.Register(ErrDeptParentSelf, errcode.ErrDeptParentSelf)
+	errcode.Register(ErrDeptCircularParent, errcode.ErrDeptCircularParent)
+	errcode.Register(ErrDeptCannotDelete, errcode.ErrDeptCannotDelete)
+	errcode.Register(ErrMenuParentSelf, errcode.ErrMenuParentSelf)
+	errcode.Register(ErrMenuCircularParent, errcode.ErrMenuCircularParent)
+	errcode.Register(ErrMenuCannotDelete, errcode.ErrMenuCannotDelete)
+
+	// 字典
+	errcode.Register(ErrDictTypeNotFound, errcode.ErrDataNotFound.WithParams("字典类型"))
+	errcode.Register(ErrDictDataNotFound, errcode.ErrDataNotFound.WithParams("字典数据"))
+	errcode.Register(dict.ErrDictTypeExists, errcode.ErrDataExists.WithParams("字典类型"))
+	errcode.Register(dict.ErrDictTypeHasData, errcode.ErrDictTypeHasData)
+	errcode.Register(dict.ErrDictTypeUnavailable, errcode.ErrDataInvalid.WithParams("字典类型"))
+
+	// 回收站
+	errcode.Register(recyclebin.ErrTypeNotRegistered, errcode.ErrParam.WithParams("type"))
+	errcode.Register(recyclebin.ErrEmptyIDs, errcode.ErrParam.WithParams("ids"))
+	errcode.Register(recyclebin.ErrRecordNotDeleted, errcode.ErrDataNotFound.WithParams("已删除记录"))
+	errcode.Register(systemdao.ErrRestoreDeptMissing, errcode.ErrRestoreDeptMissing)
+	errcode.Register(systemdao.ErrRestoreParentDeptMissing, errcode.ErrRestoreParentDeptMissing)
+	errcode.Register(systemdao.ErrRestoreDeptNameExists, errcode.ErrRestoreDeptNameExists)
+	errcode.Register(systemdao.ErrRestoreParentMenuMissing, errcode.ErrRestoreParentMenuMissing)
+	errcode.Register(systemdao.ErrPurgeDeptHasChildren, errcode.ErrPurgeDeptHasChildren)
+	errcode.Register(systemdao.ErrPurgeDeptHasUsers, errcode.ErrPurgeDeptHasUsers)
+	errcode.Register(systemdao.ErrPurgeMenuHasChildren, errcode.ErrPurgeMenuHasChildren)
+}
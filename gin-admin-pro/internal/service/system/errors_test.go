@@ -0,0 +1,73 @@
+package system
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	systemdao "gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/pkg/errcode"
+	"gin-admin-pro/plugin/dict"
+	"gin-admin-pro/plugin/errorcode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceErrorsRegistered(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   *errcode.ErrorCode
+		status int
+	}{
+		{ErrUserNotFound, errcode.ErrUserNotFound, http.StatusNotFound},
+		{ErrUserDisabled, errcode.ErrUserDisabled, http.StatusForbidden},
+		{ErrRoleIsBuiltin, errcode.ErrRoleBuiltin, http.StatusBadRequest},
+		{ErrDeptNotFound, errcode.ErrDataNotFound, http.StatusNotFound},
+		{ErrDeptCannotDelete, errcode.ErrDeptCannotDelete, http.StatusBadRequest},
+		{ErrMenuIDRequired, errcode.ErrParam, http.StatusBadRequest},
+		{ErrMenuNameExists, errcode.ErrDataExists, http.StatusBadRequest},
+		// 包装后的错误同样可以匹配
+		{fmt.Errorf("update: %w", ErrMenuCircularParent), errcode.ErrMenuCircularParent, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		e, ok := errcode.From(tt.err)
+		require.True(t, ok, tt.err.Error())
+		assert.Equal(t, tt.code.Code, e.Code, tt.err.Error())
+		assert.Equal(t, tt.status, e.HTTPStatus, tt.err.Error())
+	}
+}
+
+func TestBusinessErrorsLocalized(t *testing.T) {
+	enUS := errorcode.NewService(nil, nil).WithLocale(errorcode.LocaleEnUS)
+
+	// 业务错误使用独立的错误码，不把中文错误信息作为参数填入其他语言的消息
+	for _, err := range []error{
+		ErrDeptCircularParent,
+		ErrMenuCannotDelete,
+		dict.ErrDictTypeHasData,
+		systemdao.ErrRestoreDeptNameExists,
+		systemdao.ErrPurgeDeptHasUsers,
+	} {
+		e, ok := errcode.From(err)
+		require.True(t, ok, err.Error())
+		assert.NotEqual(t, errcode.ErrBusiness.Code, e.Code, err.Error())
+		assert.Empty(t, e.Params, err.Error())
+
+		message := enUS.GetErrorMessageWithParams(e.Code, e.Params...)
+		assert.NotContains(t, message, err.Error())
+		assert.Regexp(t, `^[ -~]+$`, message, err.Error())
+	}
+}
+
+func TestSaveMessagesInvalidLocale(t *testing.T) {
+	service := NewErrorCodeService(errorcode.NewService(nil, nil))
+
+	err := service.SaveMessages(&ErrorCodeMessagesReq{Code: errorcode.CodeParamError, Messages: map[string]string{"xx-<b>": "message"}})
+	e, ok := errcode.From(err)
+	require.True(t, ok)
+	assert.Equal(t, errcode.ErrParam.Code, e.Code)
+	assert.Equal(t, []interface{}{"locale"}, e.Params)
+	assert.Equal(t, http.StatusBadRequest, e.HTTPStatus)
+}
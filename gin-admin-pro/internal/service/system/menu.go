@@ -7,6 +7,18 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrMenuIDRequired     = errors.New("菜单ID不能为空")
+	ErrMenuNotFound       = errors.New("菜单不存在")
+	ErrMenuNameRequired   = errors.New("菜单名称不能为空")
+	ErrMenuTypeInvalid    = errors.New("菜单类型不正确")
+	ErrMenuNameExists     = errors.New("同级下已存在相同名称的菜单")
+	ErrMenuParentSelf     = errors.New("不能将菜单设置为自己的子菜单")
+	ErrMenuCircularParent = errors.New("不能将菜单设置为自己的子菜单，会造成循环引用")
+	ErrMenuCannotDelete   = errors.New("菜单不存在或存在子菜单，无法删除")
+	ErrMenuUserIDRequired = errors.New("用户ID不能为空")
+)
+
 // MenuService 菜单服务层
 type MenuService struct {
 	menuDAO *system.MenuDAO
@@ -25,13 +37,13 @@ func (s *MenuService) GetList(req *system.MenuListReq) ([]system.MenuResp, error
 // GetByID 根据ID获取菜单详情
 func (s *MenuService) GetByID(id uint) (*system.MenuDetailResp, error) {
 	if id == 0 {
-		return nil, errors.New("菜单ID不能为空")
+		return nil, ErrMenuIDRequired
 	}
 
 	menu, err := s.menuDAO.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("菜单不存在")
+			return nil, ErrMenuNotFound
 		}
 		return nil, err
 	}
@@ -43,10 +55,10 @@ func (s *MenuService) GetByID(id uint) (*system.MenuDetailResp, error) {
 func (s *MenuService) Create(req *system.CreateMenuReq, createBy uint) (uint, error) {
 	// 参数验证
 	if req.Name == "" {
-		return 0, errors.New("菜单名称不能为空")
+		return 0, ErrMenuNameRequired
 	}
 	if req.Type < 1 || req.Type > 3 {
-		return 0, errors.New("菜单类型不正确")
+		return 0, ErrMenuTypeInvalid
 	}
 
 	// 检查同级下菜单名称是否重复
@@ -55,7 +67,7 @@ func (s *MenuService) Create(req *system.CreateMenuReq, createBy uint) (uint, er
 		return 0, err
 	}
 	if exists {
-		return 0, errors.New("同级下已存在相同名称的菜单")
+		return 0, ErrMenuNameExists
 	}
 
 	// 如果没有指定排序，自动获取最大排序值+1
@@ -73,21 +85,21 @@ func (s *MenuService) Create(req *system.CreateMenuReq, createBy uint) (uint, er
 // Update 更新菜单
 func (s *MenuService) Update(req *system.UpdateMenuReq, updateBy uint) error {
 	if req.ID == 0 {
-		return errors.New("菜单ID不能为空")
+		return ErrMenuIDRequired
 	}
 
 	// 获取原菜单信息
 	menu, err := s.menuDAO.GetByID(req.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("菜单不存在")
+			return ErrMenuNotFound
 		}
 		return err
 	}
 
 	// 检查是否将菜单设置为自己的子菜单
 	if req.ParentID != nil && *req.ParentID == req.ID {
-		return errors.New("不能将菜单设置为自己的子菜单")
+		return ErrMenuParentSelf
 	}
 
 	// 检查是否造成循环引用
@@ -114,7 +126,7 @@ func (s *MenuService) Update(req *system.UpdateMenuReq, updateBy uint) error {
 			return err
 		}
 		if exists {
-			return errors.New("同级下已存在相同名称的菜单")
+			return ErrMenuNameExists
 		}
 	}
 
@@ -124,13 +136,13 @@ func (s *MenuService) Update(req *system.UpdateMenuReq, updateBy uint) error {
 // Delete 删除菜单
 func (s *MenuService) Delete(id uint) error {
 	if id == 0 {
-		return errors.New("菜单ID不能为空")
+		return ErrMenuIDRequired
 	}
 
 	err := s.menuDAO.Delete(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("菜单不存在或存在子菜单，无法删除")
+			return ErrMenuCannotDelete
 		}
 		return err
 	}
@@ -146,7 +158,7 @@ func (s *MenuService) GetAllSimpleList() ([]system.MenuSimpleResp, error) {
 // GetUserMenus 获取用户菜单列表
 func (s *MenuService) GetUserMenus(userID uint) ([]system.MenuResp, error) {
 	if userID == 0 {
-		return nil, errors.New("用户ID不能为空")
+		return nil, ErrMenuUserIDRequired
 	}
 
 	return s.menuDAO.GetUserMenus(userID)
@@ -163,7 +175,7 @@ func (s *MenuService) checkCircularReference(menuID, parentID uint) error {
 	// 检查当前菜单是否在父级菜单链中
 	for _, parent := range parentChain {
 		if parent.ID == menuID {
-			return errors.New("不能将菜单设置为自己的子菜单，会造成循环引用")
+			return ErrMenuCircularParent
 		}
 	}
 
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"slices"
+
+	"gin-admin-pro/internal/dao/system"
+)
+
+// SuperRoles 拥有全部权限的角色，与管理员接口的角色一致
+var SuperRoles = []string{"super_admin", "admin"}
+
+// AllPermission 表示全部权限的权限标识
+const AllPermission = "*:*:*"
+
+// UserPermissions 用户的角色和菜单权限，AI 工具、MCP 和全局搜索按其校验权限
+type UserPermissions struct {
+	UserID   uint
+	Username string
+	DeptID   uint
+	// Roles 角色编码
+	Roles []string
+	// Permissions 角色菜单的权限标识
+	Permissions []string
+	// MenuIDs 角色已分配的菜单，已去重
+	MenuIDs []uint
+}
+
+// IsSuper 是否为超级管理员：拥有超级管理员角色或全部权限标识
+func (p *UserPermissions) IsSuper() bool {
+	for _, role := range p.Roles {
+		if slices.Contains(SuperRoles, role) {
+			return true
+		}
+	}
+	return slices.Contains(p.Permissions, AllPermission)
+}
+
+// HasPermission 是否拥有权限标识，permission 为空表示不限制
+func (p *UserPermissions) HasPermission(permission string) bool {
+	return permission == "" || p.IsSuper() || slices.Contains(p.Permissions, permission)
+}
+
+// PermissionLoader 根据用户ID加载用户权限
+type PermissionLoader func(userID uint) (*UserPermissions, error)
+
+// NewPermissionLoader 创建从数据库加载用户角色和菜单权限的 PermissionLoader，用户已禁用时返回 ErrUserDisabled
+func NewPermissionLoader(userDAO *system.UserDAO) PermissionLoader {
+	return func(userID uint) (*UserPermissions, error) {
+		user, err := userDAO.GetWithPermissions(userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.Status != 1 {
+			return nil, ErrUserDisabled
+		}
+
+		permissions := &UserPermissions{UserID: user.ID, Username: user.Username, DeptID: user.DeptID}
+		menuIDs := make(map[uint]bool)
+		for _, role := range user.Roles {
+			permissions.Roles = append(permissions.Roles, role.Code)
+			for _, menu := range role.Menus {
+				if menu.Perms != "" && !slices.Contains(permissions.Permissions, menu.Perms) {
+					permissions.Permissions = append(permissions.Permissions, menu.Perms)
+				}
+				if !menuIDs[menu.ID] {
+					menuIDs[menu.ID] = true
+					permissions.MenuIDs = append(permissions.MenuIDs, menu.ID)
+				}
+			}
+		}
+		return permissions, nil
+	}
+}
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"gin-admin-pro/internal/dao/system"
+	"gin-admin-pro/internal/model"
+	"gin-admin-pro/plugin/recyclebin"
+)
+
+// RecycleBinService 回收站服务层
+type RecycleBinService struct {
+	recycleBinDAO *system.RecycleBinDAO
+	bin           *recyclebin.Service
+}
+
+// NewRecycleBinService 创建回收站服务实例，并注册系统模块的用户、角色、部门、菜单
+func NewRecycleBinService(recycleBinDAO *system.RecycleBinDAO, cfg *recyclebin.Config) *RecycleBinService {
+	registry := recyclebin.NewRegistry()
+	for _, entry := range recycleBinDAO.Entries() {
+		// 注册项均为内置常量，重复注册属于编码错误
+		if err := registry.Register(entry); err != nil {
+			panic(err)
+		}
+	}
+
+	return &RecycleBinService{
+		recycleBinDAO: recycleBinDAO,
+		bin:           recyclebin.NewService(recycleBinDAO.GetDB(), cfg, registry),
+	}
+}
+
+// GetBinService 获取回收站插件服务（用于注册自动清理任务）
+func (s *RecycleBinService) GetBinService() *recyclebin.Service {
+	return s.bin
+}
+
+// GetTypes 获取回收站支持的类型
+func (s *RecycleBinService) GetTypes() []recyclebin.TypeOption {
+	return s.bin.GetTypeOptions()
+}
+
+// GetPage 分页获取回收站记录
+func (s *RecycleBinService) GetPage(req *system.RecycleBinPageReq) (*model.PageResp, error) {
+	records, total, err := s.bin.GetDeletedPage(req.Type, req.PageNo, req.PageSize, req.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PageResp{
+		List:  records,
+		Total: total,
+	}, nil
+}
+
+// Restore 恢复记录
+func (s *RecycleBinService) Restore(req *system.RecycleBinReq) error {
+	return s.bin.Restore(req.Type, req.IDs)
+}
+
+// Purge 彻底删除记录
+func (s *RecycleBinService) Purge(req *system.RecycleBinReq) (int64, error) {
+	return s.bin.Purge(req.Type, req.IDs)
+}
@@ -5,7 +5,9 @@ import (
 	"gin-admin-pro/internal/dao/system"
 	"gin-admin-pro/internal/model"
 	sysmodel "gin-admin-pro/internal/model/system"
+	"gin-admin-pro/internal/pkg/errcode"
 	"gin-admin-pro/internal/pkg/token"
+	"gin-admin-pro/plugin/sysconfig"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -63,7 +65,7 @@ type UserInfo struct {
 	Email      string `json:"email"`
 	Mobile     string `json:"mobile"`
 	Avatar     string `json:"avatar"`
-	Status     int    `json:"status"`
+	Status     int    `json:"status" dict:"system_status"`
 	CreateTime string `json:"createTime"`
 }
 
@@ -133,8 +135,17 @@ func (s *UserService) Create(req *system.CreateReq, operatorID uint) (uint, erro
 		}
 	}
 
+	// 未指定密码时使用参数配置中的初始密码
+	password := req.Password
+	if password == "" {
+		password = sysconfig.GetString(sysconfig.KeyUserInitPassword, "")
+		if password == "" {
+			return 0, errcode.ErrParam.WithParams("password")
+		}
+	}
+
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return 0, err
 	}
@@ -369,36 +369,6 @@ func TestOpenAIProviderGetModelInfo(t *testing.T) {
 	assert.Nil(t, info)
 }
 
-func TestStubProviders(t *testing.T) {
-	providers := []AIProvider{
-		NewClaudeProvider(),
-		NewDeepSeekProvider(),
-		NewQwenProvider(),
-		NewOllamaProvider(),
-	}
-
-	for _, provider := range providers {
-		assert.NotNil(t, provider)
-
-		// Test initialize
-		err := provider.Initialize(DefaultConfig())
-		assert.NoError(t, err)
-
-		// Test validate
-		err = provider.Validate()
-		assert.NoError(t, err)
-
-		// Test get models
-		models := provider.GetModels()
-		assert.NotEmpty(t, models)
-
-		// Test health check
-		err = provider.HealthCheck(context.Background())
-		assert.Error(t, err) // Should return not implemented error
-		assert.Contains(t, err.Error(), "not implemented")
-	}
-}
-
 func TestMessage(t *testing.T) {
 	message := &Message{
 		ID:        "test-id",
@@ -0,0 +1,723 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// claudeDefaultMaxTokens Messages API 要求必须指定 max_tokens，未配置时使用该值
+const claudeDefaultMaxTokens = 4096
+
+// claudeDefaultModel 未配置模型时使用
+const claudeDefaultModel = "claude-sonnet-4-5"
+
+// ClaudeProvider Anthropic Messages API 提供商实现
+type ClaudeProvider struct {
+	client       *http.Client
+	streamClient *http.Client
+	config       *Config
+}
+
+// NewClaudeProvider 创建Claude提供商
+func NewClaudeProvider() AIProvider {
+	return &ClaudeProvider{
+		client: &http.Client{
+			Timeout: time.Second * 30,
+		},
+		streamClient: &http.Client{},
+	}
+}
+
+// claudeRequest Messages API 请求体
+type claudeRequest struct {
+	Model       string          `json:"model"`
+	MaxTokens   int             `json:"max_tokens"`
+	System      string          `json:"system,omitempty"`
+	Messages    []claudeMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	Tools       []claudeTool    `json:"tools,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+	Metadata    *claudeMetadata `json:"metadata,omitempty"`
+}
+
+// claudeMetadata 请求元数据，user_id 用于提供商侧的滥用检测
+type claudeMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// claudeMessage 对话消息，content 由内容块组成
+type claudeMessage struct {
+	Role    string               `json:"role"`
+	Content []claudeContentBlock `json:"content"`
+}
+
+// claudeContentBlock 内容块：text、image、tool_use、tool_result
+type claudeContentBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// image
+	Source *claudeImageSource `json:"source,omitempty"`
+
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// claudeImageSource 图片来源，type 为 url 或 base64
+type claudeImageSource struct {
+	Type      string `json:"type"`
+	URL       string `json:"url,omitempty"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+// claudeTool 工具定义
+type claudeTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// claudeResponse Messages API 响应体
+type claudeResponse struct {
+	ID         string               `json:"id"`
+	Type       string               `json:"type"`
+	Role       string               `json:"role"`
+	Model      string               `json:"model"`
+	Content    []claudeContentBlock `json:"content"`
+	StopReason string               `json:"stop_reason"`
+	Usage      claudeUsage          `json:"usage"`
+	Error      *claudeError         `json:"error,omitempty"`
+}
+
+// claudeUsage 用量，缓存命中和写入的 token 同样计入输入
+type claudeUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// claudeError 错误信息
+type claudeError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// claudeStreamEvent 流式事件，不同事件类型使用不同字段
+type claudeStreamEvent struct {
+	Type         string              `json:"type"`
+	Index        int                 `json:"index"`
+	Message      *claudeResponse     `json:"message,omitempty"`
+	ContentBlock *claudeContentBlock `json:"content_block,omitempty"`
+	Delta        *claudeStreamDelta  `json:"delta,omitempty"`
+	Usage        *claudeUsage        `json:"usage,omitempty"`
+	Error        *claudeError        `json:"error,omitempty"`
+}
+
+// claudeStreamDelta 流式增量：text_delta、input_json_delta 或 message_delta 的停止原因
+type claudeStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	PartialJSON string `json:"partial_json"`
+	StopReason  string `json:"stop_reason"`
+}
+
+// Initialize 初始化提供商
+func (p *ClaudeProvider) Initialize(config *Config) error {
+	p.config = config
+
+	// 设置客户端超时，流式请求只限制等待响应头的时间，整体时长由 ctx 控制
+	p.client.Timeout = config.Timeout
+	p.streamClient.Transport = &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: config.Timeout,
+	}
+
+	if config.APIKey == "" {
+		return fmt.Errorf("Claude API key is required")
+	}
+
+	return nil
+}
+
+// Validate 验证提供商
+func (p *ClaudeProvider) Validate() error {
+	if p.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if p.config.APIKey == "" {
+		return fmt.Errorf("API key is required")
+	}
+	return nil
+}
+
+// Chat 聊天接口
+func (p *ClaudeProvider) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	resp, err := p.do(ctx, p.client, p.buildRequest(request, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &ChatResponse{Error: p.parseError(resp, body), Timestamp: time.Now()}, nil
+	}
+
+	var result claudeResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	return p.convertResponse(&result), nil
+}
+
+// ChatStream 流式聊天接口
+func (p *ClaudeProvider) ChatStream(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+	resp, err := p.do(ctx, p.streamClient, p.buildRequest(request, true))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, p.parseError(resp, body)
+	}
+
+	respChan := make(chan *ChatResponse, 100)
+	go func() {
+		defer close(respChan)
+		defer resp.Body.Close()
+
+		p.handleStreamResponse(ctx, resp.Body, respChan)
+	}()
+
+	return respChan, nil
+}
+
+// GetModels 获取可用模型
+func (p *ClaudeProvider) GetModels() []string {
+	return []string{
+		"claude-sonnet-4-5",
+		"claude-opus-4-1",
+		"claude-haiku-4-5",
+		"claude-3-5-haiku-latest",
+	}
+}
+
+// GetModelInfo 获取模型信息，费用为每千 token 美元
+func (p *ClaudeProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	models := map[string]*ModelInfo{
+		"claude-sonnet-4-5": {
+			ID:          "claude-sonnet-4-5",
+			Name:        "Claude Sonnet 4.5",
+			Provider:    "claude",
+			MaxTokens:   64000,
+			InputCost:   0.003,
+			OutputCost:  0.015,
+			Features:    []string{"chat", "image", "function_calling"},
+			ContextSize: 200000,
+			CreatedAt:   time.Now(),
+		},
+		"claude-opus-4-1": {
+			ID:          "claude-opus-4-1",
+			Name:        "Claude Opus 4.1",
+			Provider:    "claude",
+			MaxTokens:   32000,
+			InputCost:   0.015,
+			OutputCost:  0.075,
+			Features:    []string{"chat", "image", "function_calling"},
+			ContextSize: 200000,
+			CreatedAt:   time.Now(),
+		},
+		"claude-haiku-4-5": {
+			ID:          "claude-haiku-4-5",
+			Name:        "Claude Haiku 4.5",
+			Provider:    "claude",
+			MaxTokens:   64000,
+			InputCost:   0.001,
+			OutputCost:  0.005,
+			Features:    []string{"chat", "image", "function_calling"},
+			ContextSize: 200000,
+			CreatedAt:   time.Now(),
+		},
+		"claude-3-5-haiku-latest": {
+			ID:          "claude-3-5-haiku-latest",
+			Name:        "Claude Haiku 3.5",
+			Provider:    "claude",
+			MaxTokens:   8192,
+			InputCost:   0.0008,
+			OutputCost:  0.004,
+			Features:    []string{"chat", "image", "function_calling"},
+			ContextSize: 200000,
+			CreatedAt:   time.Now(),
+		},
+	}
+
+	info, exists := models[model]
+	if !exists {
+		return nil, fmt.Errorf("model not found: %s", model)
+	}
+
+	return info, nil
+}
+
+// GetUsage 获取使用统计，Messages API 不提供用量查询，用量由响应中的 usage 按请求累计
+func (p *ClaudeProvider) GetUsage(ctx context.Context, startTime, endTime time.Time) (*Usage, error) {
+	return nil, fmt.Errorf("usage query is not supported by claude provider")
+}
+
+// GetCost 获取成本统计
+func (p *ClaudeProvider) GetCost(ctx context.Context, startTime, endTime time.Time) (float64, error) {
+	return 0, fmt.Errorf("cost query is not supported by claude provider")
+}
+
+// HealthCheck 健康检查
+func (p *ClaudeProvider) HealthCheck(ctx context.Context) error {
+	resp, err := p.Chat(ctx, &ChatRequest{
+		Messages:  []Message{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("API health check failed: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("API health check failed: %w", resp.Error)
+	}
+	return nil
+}
+
+// 私有方法
+
+// do 发送请求
+func (p *ClaudeProvider) do(ctx context.Context, client *http.Client, body *claudeRequest) (*http.Response, error) {
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint(), bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+
+	apiVersion := "2023-06-01"
+	if p.config.Claude != nil && p.config.Claude.APIVersion != "" {
+		apiVersion = p.config.Claude.APIVersion
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.config.APIKey)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// endpoint Messages API 地址，兼容以 /v1 结尾的 baseUrl
+func (p *ClaudeProvider) endpoint() string {
+	baseURL := strings.TrimSuffix(p.config.GetEffectiveBaseURL(), "/")
+	if strings.HasSuffix(baseURL, "/v1") {
+		return baseURL + "/messages"
+	}
+	return baseURL + "/v1/messages"
+}
+
+// buildRequest 构建请求：system 消息合并到 system 参数，相邻同角色消息合并为一条
+func (p *ClaudeProvider) buildRequest(request *ChatRequest, stream bool) *claudeRequest {
+	req := &claudeRequest{
+		Model:     p.getModel(request.Model),
+		MaxTokens: p.getMaxTokens(request.MaxTokens),
+		Stream:    stream,
+	}
+
+	// 温度和 top_p 只设置其一
+	if request.TopP > 0 && request.TopP < 1 {
+		topP := request.TopP
+		req.TopP = &topP
+	} else {
+		temperature := p.config.Temperature
+		if request.Temperature > 0 {
+			temperature = request.Temperature
+		}
+		// Messages API 的温度范围为 0~1
+		if temperature > 1 {
+			temperature = 1
+		}
+		req.Temperature = &temperature
+	}
+
+	var systemParts []string
+	if request.SystemPrompt != "" {
+		systemParts = append(systemParts, request.SystemPrompt)
+	} else if p.config.SystemPrompt != "" {
+		systemParts = append(systemParts, p.config.SystemPrompt)
+	}
+
+	for _, msg := range request.Messages {
+		if msg.Role == "system" {
+			systemParts = append(systemParts, msg.Content)
+			continue
+		}
+
+		role, blocks := p.convertMessage(msg)
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == role {
+			req.Messages[n-1].Content = append(req.Messages[n-1].Content, blocks...)
+			continue
+		}
+		req.Messages = append(req.Messages, claudeMessage{Role: role, Content: blocks})
+	}
+	req.System = strings.Join(systemParts, "\n\n")
+
+	for _, fn := range request.Functions {
+		schema := fn.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		req.Tools = append(req.Tools, claudeTool{
+			Name:        fn.Name,
+			Description: fn.Description,
+			InputSchema: schema,
+		})
+	}
+
+	if request.UserID != "" {
+		req.Metadata = &claudeMetadata{UserID: request.UserID}
+	}
+
+	return req
+}
+
+// convertMessage 转换单条消息：函数结果作为 user 角色的 tool_result，函数调用作为 assistant 的 tool_use
+func (p *ClaudeProvider) convertMessage(msg Message) (string, []claudeContentBlock) {
+	if msg.Role == "function" || msg.Role == "tool" {
+		block := claudeContentBlock{Type: "tool_result", Content: msg.Content}
+		if msg.FunctionCall != nil {
+			block.ToolUseID = msg.FunctionCall.ID
+			if block.Content == "" {
+				block.Content = functionResultText(msg.FunctionCall)
+			}
+			block.IsError = msg.FunctionCall.Error != ""
+		}
+		return "user", []claudeContentBlock{block}
+	}
+
+	role := "user"
+	if msg.Role == "assistant" {
+		role = "assistant"
+	}
+
+	var blocks []claudeContentBlock
+	for _, image := range messageImages(msg) {
+		source := &claudeImageSource{Type: "url", URL: image.URL}
+		if mediaType, data, ok := image.inline(); ok {
+			source = &claudeImageSource{Type: "base64", MediaType: mediaType, Data: data}
+		}
+		blocks = append(blocks, claudeContentBlock{Type: "image", Source: source})
+	}
+	if msg.Content != "" {
+		blocks = append(blocks, claudeContentBlock{Type: "text", Text: msg.Content})
+	}
+	if msg.FunctionCall != nil && role == "assistant" {
+		input, err := json.Marshal(msg.FunctionCall.Arguments)
+		if err != nil || msg.FunctionCall.Arguments == nil {
+			input = []byte("{}")
+		}
+		blocks = append(blocks, claudeContentBlock{
+			Type:  "tool_use",
+			ID:    msg.FunctionCall.ID,
+			Name:  msg.FunctionCall.Name,
+			Input: input,
+		})
+	}
+	return role, blocks
+}
+
+// functionResultText 将函数执行结果转换为文本
+func functionResultText(call *FunctionCall) string {
+	if call.Error != "" {
+		return call.Error
+	}
+	if text, ok := call.Result.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(call.Result)
+	if err != nil {
+		return fmt.Sprintf("%v", call.Result)
+	}
+	return string(data)
+}
+
+// convertResponse 转换响应：文本块拼接为消息内容，首个 tool_use 作为函数调用，多个时全部放入 Metadata["toolCalls"]
+func (p *ClaudeProvider) convertResponse(result *claudeResponse) *ChatResponse {
+	var (
+		content strings.Builder
+		calls   []FunctionCall
+	)
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			calls = append(calls, FunctionCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: parseToolInput(block.Input),
+			})
+		}
+	}
+
+	resp := &ChatResponse{
+		ID: result.ID,
+		Message: Message{
+			Role:      "assistant",
+			Content:   content.String(),
+			Timestamp: time.Now(),
+		},
+		Usage:     convertClaudeUsage(result.Usage),
+		Model:     result.Model,
+		Finish:    convertStopReason(result.StopReason),
+		Done:      true,
+		Timestamp: time.Now(),
+	}
+	if len(calls) > 0 {
+		resp.Message.FunctionCall = &calls[0]
+	}
+	if len(calls) > 1 {
+		resp.Metadata = map[string]interface{}{"toolCalls": calls}
+	}
+	return resp
+}
+
+// parseError 解析错误响应，429、5xx 和 529 过载可重试，retry-after 头给出等待时间
+func (p *ClaudeProvider) parseError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		Code:       fmt.Sprintf("HTTP_%d", resp.StatusCode),
+		Message:    string(body),
+		StatusCode: resp.StatusCode,
+		Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+
+	var result claudeResponse
+	if err := json.Unmarshal(body, &result); err == nil && result.Error != nil {
+		apiErr.Code = result.Error.Type
+		apiErr.Type = result.Error.Type
+		apiErr.Message = result.Error.Message
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+// handleStreamResponse 处理流式事件，ctx 取消后不再发送
+func (p *ClaudeProvider) handleStreamResponse(ctx context.Context, body io.Reader, respChan chan<- *ChatResponse) {
+	send := func(resp *ChatResponse) bool {
+		select {
+		case respChan <- resp:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var (
+		messageID string
+		model     string
+		usage     claudeUsage
+		// 按内容块序号累计 tool_use 的参数片段
+		toolCalls = make(map[int]*FunctionCall)
+		toolInput = make(map[int]*strings.Builder)
+	)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var event claudeStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line[5:])), &event); err != nil {
+			continue
+		}
+
+		var resp *ChatResponse
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				messageID = event.Message.ID
+				model = event.Message.Model
+				usage = event.Message.Usage
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				toolCalls[event.Index] = &FunctionCall{ID: event.ContentBlock.ID, Name: event.ContentBlock.Name}
+				toolInput[event.Index] = &strings.Builder{}
+			}
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				resp = p.streamChunk(messageID, model)
+				resp.Delta = event.Delta.Text
+				resp.Message.Content = event.Delta.Text
+			case "input_json_delta":
+				if input, ok := toolInput[event.Index]; ok {
+					input.WriteString(event.Delta.PartialJSON)
+				}
+			}
+		case "content_block_stop":
+			call, ok := toolCalls[event.Index]
+			if !ok {
+				continue
+			}
+			call.Arguments = parseToolInput(json.RawMessage(toolInput[event.Index].String()))
+			delete(toolCalls, event.Index)
+			delete(toolInput, event.Index)
+			resp = p.streamChunk(messageID, model)
+			resp.Message.FunctionCall = call
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+			resp = p.streamChunk(messageID, model)
+			resp.Usage = convertClaudeUsage(usage)
+			if event.Delta != nil {
+				resp.Finish = convertStopReason(event.Delta.StopReason)
+			}
+		case "message_stop":
+			send(&ChatResponse{ID: messageID, Model: model, Stream: true, Done: true, Timestamp: time.Now()})
+			return
+		case "error":
+			apiErr := &APIError{Code: "stream_error", Message: "stream error"}
+			if event.Error != nil {
+				apiErr.Code = event.Error.Type
+				apiErr.Type = event.Error.Type
+				apiErr.Message = event.Error.Message
+				apiErr.Retryable = event.Error.Type == "overloaded_error" || event.Error.Type == "api_error"
+			}
+			send(&ChatResponse{ID: messageID, Model: model, Error: apiErr, Stream: true, Done: true, Timestamp: time.Now()})
+			return
+		}
+
+		if resp != nil && !send(resp) {
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		send(&ChatResponse{
+			Error:  &APIError{Code: "stream_error", Message: err.Error()},
+			Stream: true,
+			Done:   true,
+		})
+	}
+}
+
+// streamChunk 创建流式分片
+func (p *ClaudeProvider) streamChunk(messageID, model string) *ChatResponse {
+	return &ChatResponse{
+		ID:    messageID,
+		Model: model,
+		Message: Message{
+			Role:      "assistant",
+			Timestamp: time.Now(),
+			Streaming: true,
+		},
+		Stream:    true,
+		Timestamp: time.Now(),
+	}
+}
+
+// getModel 获取模型：请求指定 > 配置 > 默认模型
+func (p *ClaudeProvider) getModel(model string) string {
+	if model != "" {
+		return model
+	}
+	if p.config.Model != "" {
+		return p.config.Model
+	}
+	return claudeDefaultModel
+}
+
+// getMaxTokens 获取最大输出 token 数，优先使用请求、Claude 专属配置、通用配置
+func (p *ClaudeProvider) getMaxTokens(maxTokens int) int {
+	if maxTokens > 0 {
+		return maxTokens
+	}
+	if p.config.Claude != nil && p.config.Claude.MaxTokens > 0 {
+		return p.config.Claude.MaxTokens
+	}
+	if p.config.MaxTokens > 0 {
+		return p.config.MaxTokens
+	}
+	return claudeDefaultMaxTokens
+}
+
+// parseToolInput 解析工具参数，空参数或格式错误时返回空对象
+func parseToolInput(input json.RawMessage) map[string]interface{} {
+	arguments := map[string]interface{}{}
+	if len(input) > 0 {
+		_ = json.Unmarshal(input, &arguments)
+	}
+	return arguments
+}
+
+// convertClaudeUsage 转换用量
+func convertClaudeUsage(usage claudeUsage) Usage {
+	prompt := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	return Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      prompt + usage.OutputTokens,
+	}
+}
+
+// convertStopReason 将停止原因转换为统一的 Finish 取值
+func convertStopReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "function_call"
+	case "refusal":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
@@ -0,0 +1,335 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// claudeTextStream 录制的纯文本流式响应
+const claudeTextStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_01XFDUDYJgAACzvnptvVoYEL","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":25,"cache_read_input_tokens":10,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"你好"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"，世界"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":15}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// claudeToolStream 录制的工具调用流式响应
+const claudeToolStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_014p7gG3wDgGV9EUtLvnow3U","type":"message","role":"assistant","model":"claude-sonnet-4-5","stop_sequence":null,"usage":{"input_tokens":472,"output_tokens":2},"content":[],"stop_reason":null}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"我来查询天气。"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_01T1x1fJ34qAmk2tNTrN7Up6","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"location\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":" \"上海\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":89}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// claudeErrorStream 录制的中途过载错误
+const claudeErrorStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_02","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","usage":{"input_tokens":5,"output_tokens":1}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"部分"}}
+
+event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+
+`
+
+// newClaudeTestProvider 创建指向模拟服务的 Claude 提供商
+func newClaudeTestProvider(t *testing.T, handler http.HandlerFunc) *ClaudeProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.Provider = "claude"
+	config.APIKey = "sk-ant-test"
+	config.BaseURL = server.URL
+	config.Model = "claude-sonnet-4-5"
+
+	provider := NewClaudeProvider().(*ClaudeProvider)
+	require.NoError(t, provider.Initialize(config))
+	return provider
+}
+
+// replayStream 按事件回放录制的流式响应
+func replayStream(stream string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, stream)
+	}
+}
+
+// collectStream 读取全部流式分片
+func collectStream(t *testing.T, chunks <-chan *ChatResponse) []*ChatResponse {
+	var result []*ChatResponse
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return result
+			}
+			result = append(result, chunk)
+		case <-timeout:
+			t.Fatal("stream did not finish")
+		}
+	}
+}
+
+func TestClaudeProviderChat(t *testing.T) {
+	var body claudeRequest
+	provider := newClaudeTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-ant-test", r.Header.Get("x-api-key"))
+		assert.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		fmt.Fprint(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-sonnet-4-5",
+			"content":[{"type":"text","text":"需要查询两个城市。"},
+				{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"location":"北京"}},
+				{"type":"tool_use","id":"toolu_2","name":"get_weather","input":{"location":"上海"}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":100,"cache_creation_input_tokens":20,"output_tokens":30}}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{
+		UserID: "1",
+		Messages: []Message{
+			{Role: "system", Content: "回答使用中文"},
+			{Role: "user", Content: "北京天气"},
+			{Role: "assistant", FunctionCall: &FunctionCall{ID: "toolu_0", Name: "get_weather", Arguments: map[string]interface{}{"location": "北京"}}},
+			{Role: "function", FunctionCall: &FunctionCall{ID: "toolu_0", Name: "get_weather", Result: map[string]interface{}{"temp": 20}}},
+			{Role: "user", Content: "再查上海", ImageURL: "https://example.com/map.png"},
+		},
+		Functions: []FunctionDefinition{{Name: "get_weather", Description: "查询天气"}},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+
+	// 请求：系统提示词合并、相邻 user 消息合并、工具结果和工具定义
+	assert.Equal(t, "claude-sonnet-4-5", body.Model)
+	assert.Equal(t, 4096, body.MaxTokens)
+	assert.Equal(t, "You are a helpful AI assistant.\n\n回答使用中文", body.System)
+	require.Len(t, body.Messages, 3)
+	assert.Equal(t, "user", body.Messages[0].Role)
+	assert.Equal(t, "tool_use", body.Messages[1].Content[0].Type)
+	assert.JSONEq(t, `{"location":"北京"}`, string(body.Messages[1].Content[0].Input))
+	assert.Equal(t, "user", body.Messages[2].Role)
+	require.Len(t, body.Messages[2].Content, 3)
+	assert.Equal(t, "tool_result", body.Messages[2].Content[0].Type)
+	assert.Equal(t, "toolu_0", body.Messages[2].Content[0].ToolUseID)
+	assert.JSONEq(t, `{"temp":20}`, body.Messages[2].Content[0].Content)
+	assert.Equal(t, "image", body.Messages[2].Content[1].Type)
+	assert.Equal(t, "https://example.com/map.png", body.Messages[2].Content[1].Source.URL)
+	require.Len(t, body.Tools, 1)
+	assert.Equal(t, "object", body.Tools[0].InputSchema["type"])
+	assert.Equal(t, "1", body.Metadata.UserID)
+
+	// 响应：文本、工具调用和用量
+	assert.Equal(t, "需要查询两个城市。", resp.Message.Content)
+	assert.Equal(t, "function_call", resp.Finish)
+	require.NotNil(t, resp.Message.FunctionCall)
+	assert.Equal(t, "toolu_1", resp.Message.FunctionCall.ID)
+	assert.Equal(t, "北京", resp.Message.FunctionCall.Arguments["location"])
+	assert.Len(t, resp.Metadata["toolCalls"], 2)
+	assert.Equal(t, Usage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150}, resp.Usage)
+}
+
+func TestClaudeProviderChatError(t *testing.T) {
+	provider := newClaudeTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("retry-after", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "rate_limit_error", resp.Error.Code)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Error.StatusCode)
+	assert.True(t, resp.Error.Retryable)
+	assert.Equal(t, 12*time.Second, resp.Error.RetryAfter)
+
+	overloaded := newClaudeTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	})
+	_, err = overloaded.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "overloaded_error", apiErr.Code)
+	assert.True(t, apiErr.Retryable)
+
+	invalid := newClaudeTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`)
+	})
+	resp, err = invalid.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	assert.False(t, resp.Error.Retryable)
+	assert.Equal(t, "max_tokens: field required", resp.Error.Message)
+}
+
+func TestClaudeProviderStream(t *testing.T) {
+	provider := newClaudeTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		var body claudeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+		replayStream(claudeTextStream)(w, r)
+	})
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+
+	var (
+		content string
+		last    *ChatResponse
+		usage   Usage
+		finish  string
+	)
+	for _, chunk := range collectStream(t, chunks) {
+		content += chunk.Delta
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+		if chunk.Finish != "" {
+			finish = chunk.Finish
+		}
+		last = chunk
+	}
+
+	assert.Equal(t, "你好，世界", content)
+	assert.Equal(t, "stop", finish)
+	assert.Equal(t, Usage{PromptTokens: 35, CompletionTokens: 15, TotalTokens: 50}, usage)
+	require.NotNil(t, last)
+	assert.True(t, last.Done)
+	assert.Equal(t, "msg_01XFDUDYJgAACzvnptvVoYEL", last.ID)
+}
+
+func TestClaudeProviderStreamToolUse(t *testing.T) {
+	provider := newClaudeTestProvider(t, replayStream(claudeToolStream))
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{
+		Messages:  []Message{{Role: "user", Content: "上海天气"}},
+		Functions: []FunctionDefinition{{Name: "get_weather", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	require.NoError(t, err)
+
+	var (
+		content string
+		call    *FunctionCall
+		finish  string
+	)
+	for _, chunk := range collectStream(t, chunks) {
+		content += chunk.Delta
+		if chunk.Message.FunctionCall != nil {
+			call = chunk.Message.FunctionCall
+		}
+		if chunk.Finish != "" {
+			finish = chunk.Finish
+		}
+	}
+
+	assert.Equal(t, "我来查询天气。", content)
+	assert.Equal(t, "function_call", finish)
+	require.NotNil(t, call)
+	assert.Equal(t, "toolu_01T1x1fJ34qAmk2tNTrN7Up6", call.ID)
+	assert.Equal(t, "get_weather", call.Name)
+	assert.Equal(t, map[string]interface{}{"location": "上海"}, call.Arguments)
+}
+
+func TestClaudeProviderStreamError(t *testing.T) {
+	provider := newClaudeTestProvider(t, replayStream(claudeErrorStream))
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+
+	result := collectStream(t, chunks)
+	require.Len(t, result, 2)
+	assert.Equal(t, "部分", result[0].Delta)
+	require.NotNil(t, result[1].Error)
+	assert.Equal(t, "overloaded_error", result[1].Error.Code)
+	assert.True(t, result[1].Error.Retryable)
+	assert.True(t, result[1].Done)
+}
+
+func TestClaudeProviderEndpoint(t *testing.T) {
+	provider := NewClaudeProvider().(*ClaudeProvider)
+	config := DefaultConfig()
+	config.Provider = "claude"
+	config.APIKey = "sk-ant-test"
+
+	config.BaseURL = ""
+	require.NoError(t, provider.Initialize(config))
+	assert.Equal(t, "https://api.anthropic.com/v1/messages", provider.endpoint())
+
+	config.BaseURL = "https://gateway.example.com/anthropic/v1/"
+	assert.Equal(t, "https://gateway.example.com/anthropic/v1/messages", provider.endpoint())
+
+	assert.Error(t, NewClaudeProvider().Initialize(DefaultConfig()))
+}
+
+func TestClaudeProviderGetModelInfo(t *testing.T) {
+	provider := NewClaudeProvider()
+	for _, model := range provider.GetModels() {
+		info, err := provider.GetModelInfo(model)
+		require.NoError(t, err)
+		assert.Equal(t, "claude", info.Provider)
+		assert.Greater(t, info.InputCost, 0.0)
+	}
+
+	_, err := provider.GetModelInfo("gpt-4")
+	assert.Error(t, err)
+}
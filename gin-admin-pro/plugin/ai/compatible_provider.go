@@ -0,0 +1,750 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CompatibleSpec OpenAI 兼容接口的厂商描述，内置厂商和 YAML 配置的网关都通过它接入
+type CompatibleSpec struct {
+	Name         string            // 提供商名称
+	BaseURL      string            // 默认 API 地址，未配置 baseUrl 时使用
+	AuthHeader   string            // 认证请求头，默认 Authorization
+	AuthScheme   string            // 认证值前缀，AuthHeader 为 Authorization 时默认 Bearer
+	Headers      map[string]string // 额外请求头
+	DefaultModel string            // 未配置 model 时使用的模型
+	Models       []ModelInfo       // 模型目录及价格（美元/1K tokens）
+	KeyOptional  bool              // 是否允许不配置 API Key，如内网网关
+
+	// setup 根据厂商专属配置调整提供商，如额外请求头、备用 API Key
+	setup func(p *CompatibleProvider, config *Config)
+}
+
+// CompatibleProvider OpenAI 兼容接口（/chat/completions）提供商实现
+type CompatibleProvider struct {
+	client       *http.Client
+	streamClient *http.Client
+	spec         *CompatibleSpec
+	config       *Config
+
+	// 以下字段在 Initialize 时根据配置确定
+	apiKey  string
+	model   string
+	headers map[string]string
+}
+
+// NewCompatibleProvider 根据厂商描述创建 OpenAI 兼容提供商
+func NewCompatibleProvider(spec *CompatibleSpec) *CompatibleProvider {
+	return &CompatibleProvider{
+		client: &http.Client{
+			Timeout: time.Second * 30,
+		},
+		streamClient: &http.Client{},
+		spec:         spec,
+	}
+}
+
+// Initialize 初始化提供商
+func (p *CompatibleProvider) Initialize(config *Config) error {
+	p.config = config
+
+	// 流式请求只限制等待响应头的时间，整体时长由 ctx 控制
+	p.client.Timeout = config.Timeout
+	p.streamClient.Transport = &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: config.Timeout,
+	}
+
+	p.apiKey = config.APIKey
+	p.model = config.Model
+	if p.model == "" {
+		p.model = p.spec.DefaultModel
+	}
+	p.headers = make(map[string]string, len(p.spec.Headers))
+	for key, value := range p.spec.Headers {
+		p.headers[key] = value
+	}
+	if p.spec.setup != nil {
+		p.spec.setup(p, config)
+	}
+
+	// 验证配置
+	if p.apiKey == "" && !p.spec.KeyOptional {
+		return fmt.Errorf("%s API key is required", p.spec.Name)
+	}
+
+	return nil
+}
+
+// Validate 验证提供商
+func (p *CompatibleProvider) Validate() error {
+	if p.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if p.apiKey == "" && !p.spec.KeyOptional {
+		return fmt.Errorf("API key is required")
+	}
+	return nil
+}
+
+// Chat 聊天接口
+func (p *CompatibleProvider) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	resp, err := p.do(ctx, p.client, http.MethodPost, "/chat/completions", p.buildChatRequest(request))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+
+	// 解析响应
+	return p.parseResponse(resp, respBody)
+}
+
+// ChatStream 流式聊天接口
+func (p *CompatibleProvider) ChatStream(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+	// 启用流式响应
+	streamRequest := *request
+	streamRequest.Stream = true
+
+	resp, err := p.do(ctx, p.streamClient, http.MethodPost, "/chat/completions", p.buildChatRequest(&streamRequest))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		errResp, err := p.parseResponse(resp, body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errResp.Error
+	}
+
+	// 创建响应通道
+	respChan := make(chan *ChatResponse, 100)
+
+	// 启动goroutine处理流式响应，ctx 取消时关闭响应体以中断上游请求
+	go func() {
+		defer close(respChan)
+		defer resp.Body.Close()
+
+		p.handleStreamResponse(ctx, resp.Body, respChan)
+	}()
+
+	return respChan, nil
+}
+
+// GetModels 获取模型目录
+func (p *CompatibleProvider) GetModels() []string {
+	models := make([]string, 0, len(p.spec.Models))
+	for _, model := range p.spec.Models {
+		models = append(models, model.ID)
+	}
+	if len(models) == 0 && p.spec.DefaultModel != "" {
+		models = append(models, p.spec.DefaultModel)
+	}
+	return models
+}
+
+// GetModelInfo 获取模型信息，model 为空时使用默认模型
+func (p *CompatibleProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	if model == "" {
+		model = p.getModel("")
+	}
+	for _, info := range p.spec.Models {
+		if info.ID == model {
+			info := info
+			info.Provider = p.spec.Name
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("model not found: %s", model)
+}
+
+// GetUsage 获取使用统计，兼容接口不提供用量查询，用量以每次响应返回的为准
+func (p *CompatibleProvider) GetUsage(ctx context.Context, startTime, endTime time.Time) (*Usage, error) {
+	return nil, fmt.Errorf("%s usage query is not supported", p.spec.Name)
+}
+
+// GetCost 获取成本统计
+func (p *CompatibleProvider) GetCost(ctx context.Context, startTime, endTime time.Time) (float64, error) {
+	return 0, fmt.Errorf("%s cost query is not supported", p.spec.Name)
+}
+
+// HealthCheck 健康检查，请求 /models 验证地址和 API Key，不消耗 token
+func (p *CompatibleProvider) HealthCheck(ctx context.Context) error {
+	resp, err := p.do(ctx, p.client, http.MethodGet, "/models", nil)
+	if err != nil {
+		return fmt.Errorf("API health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		errResp, err := p.parseResponse(resp, body)
+		if err != nil {
+			return fmt.Errorf("API health check failed: %w", err)
+		}
+		return fmt.Errorf("API health check failed: %w", errResp.Error)
+	}
+	return nil
+}
+
+// 私有方法
+
+// do 发送请求，body 为 nil 时不携带请求体
+func (p *CompatibleProvider) do(ctx context.Context, client *http.Client, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reqBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request failed: %w", err)
+		}
+		reader = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL()+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+
+	// 设置请求头
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.apiKey != "" {
+		req.Header.Set(p.authHeader(), p.authValue())
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	// 执行请求
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// baseURL API 地址，配置的 baseUrl 优先
+func (p *CompatibleProvider) baseURL() string {
+	baseURL := p.spec.BaseURL
+	if p.config != nil && p.config.BaseURL != "" {
+		baseURL = p.config.BaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// authHeader 认证请求头
+func (p *CompatibleProvider) authHeader() string {
+	if p.spec.AuthHeader == "" {
+		return "Authorization"
+	}
+	return p.spec.AuthHeader
+}
+
+// authValue 认证值，Authorization 头默认使用 Bearer 前缀，其他请求头默认直接使用 API Key
+func (p *CompatibleProvider) authValue() string {
+	scheme := p.spec.AuthScheme
+	if scheme == "" && strings.EqualFold(p.authHeader(), "Authorization") {
+		scheme = "Bearer"
+	}
+	if scheme == "" {
+		return p.apiKey
+	}
+	return scheme + " " + p.apiKey
+}
+
+// buildChatRequest 构建聊天请求
+func (p *CompatibleProvider) buildChatRequest(request *ChatRequest) map[string]interface{} {
+	req := map[string]interface{}{
+		"model":    p.getModel(request.Model),
+		"messages": p.convertMessages(request.Messages),
+		"stream":   request.Stream,
+	}
+
+	// 流式响应默认不返回用量，需显式开启以便配额统计
+	if request.Stream {
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 添加可选参数
+	if request.MaxTokens > 0 {
+		req["max_tokens"] = request.MaxTokens
+	} else if maxTokens := p.config.GetEffectiveMaxTokens(); maxTokens > 0 {
+		req["max_tokens"] = maxTokens
+	}
+
+	if request.Temperature > 0 {
+		req["temperature"] = request.Temperature
+	} else {
+		req["temperature"] = p.config.Temperature
+	}
+
+	if request.TopP > 0 {
+		req["top_p"] = request.TopP
+	} else if p.config.TopP > 0 {
+		req["top_p"] = p.config.TopP
+	}
+
+	if request.UserID != "" {
+		req["user"] = request.UserID
+	}
+
+	// OpenAI 没有独立的 system 参数，系统提示词作为首条 system 消息发送
+	systemPrompt := request.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = p.config.SystemPrompt
+	}
+	if systemPrompt != "" && (len(request.Messages) == 0 || request.Messages[0].Role != "system") {
+		messages := req["messages"].([]map[string]interface{})
+		req["messages"] = append([]map[string]interface{}{{"role": "system", "content": systemPrompt}}, messages...)
+	}
+
+	// 函数调用，兼容厂商普遍只支持 tools 格式
+	if len(request.Functions) > 0 {
+		tools := make([]map[string]interface{}, 0, len(request.Functions))
+		for _, fn := range request.Functions {
+			tools = append(tools, map[string]interface{}{"type": "function", "function": fn})
+		}
+		req["tools"] = tools
+		req["tool_choice"] = "auto"
+	}
+
+	return req
+}
+
+// convertMessages 转换消息格式：带图片的消息内容转换为文本和图片片段，
+// 助手的函数调用转换为 tool_calls，函数结果转换为 tool 消息
+func (p *CompatibleProvider) convertMessages(messages []Message) []map[string]interface{} {
+	converted := make([]map[string]interface{}, len(messages))
+	for i, msg := range messages {
+		convertedMsg := map[string]interface{}{
+			"role":    msg.Role,
+			"content": msg.Content,
+		}
+		if images := messageImages(msg); len(images) > 0 {
+			convertedMsg["content"] = contentParts(msg.Content, images)
+		}
+
+		if call := msg.FunctionCall; call != nil {
+			switch msg.Role {
+			case "function", "tool":
+				convertedMsg["role"] = "tool"
+				convertedMsg["tool_call_id"] = call.ID
+				if msg.Content == "" {
+					convertedMsg["content"] = functionResultText(call)
+				}
+			default:
+				arguments, _ := json.Marshal(call.Arguments)
+				convertedMsg["tool_calls"] = []map[string]interface{}{{
+					"id":   call.ID,
+					"type": "function",
+					"function": map[string]interface{}{
+						"name":      call.Name,
+						"arguments": string(arguments),
+					},
+				}}
+			}
+		}
+
+		converted[i] = convertedMsg
+	}
+	return converted
+}
+
+// contentParts 将文本和图片转换为内容片段，图片以 URL 或 data URL 发送
+func contentParts(text string, images []Image) []map[string]interface{} {
+	parts := make([]map[string]interface{}, 0, len(images)+1)
+	if text != "" {
+		parts = append(parts, map[string]interface{}{"type": "text", "text": text})
+	}
+	for _, image := range images {
+		parts = append(parts, map[string]interface{}{
+			"type":      "image_url",
+			"image_url": map[string]interface{}{"url": image.dataURL()},
+		})
+	}
+	return parts
+}
+
+// getModel 获取模型：请求指定 > 配置 > 厂商默认
+func (p *CompatibleProvider) getModel(model string) string {
+	if model != "" {
+		return model
+	}
+	if p.model != "" {
+		return p.model
+	}
+	return p.spec.DefaultModel
+}
+
+// parseResponse 解析响应
+func (p *CompatibleProvider) parseResponse(resp *http.Response, body []byte) (*ChatResponse, error) {
+	// 检查HTTP状态
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			Code:       fmt.Sprintf("HTTP_%d", resp.StatusCode),
+			Message:    string(body),
+			StatusCode: resp.StatusCode,
+			Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+		// 错误体格式为 {"error": {"code", "message"}}
+		var errBody struct {
+			Error struct {
+				Code    interface{} `json:"code"`
+				Message string      `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errBody); err == nil && errBody.Error.Message != "" {
+			apiErr.Message = errBody.Error.Message
+			if code, ok := errBody.Error.Code.(string); ok && code != "" {
+				apiErr.Code = code
+			}
+		}
+		return &ChatResponse{Error: apiErr}, nil
+	}
+
+	// 解析JSON响应
+	var rawResp map[string]interface{}
+	if err := json.Unmarshal(body, &rawResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+
+	// 检查API错误
+	if errObj, exists := rawResp["error"]; exists && errObj != nil {
+		return &ChatResponse{
+			Error: p.parseAPIError(errObj),
+		}, nil
+	}
+
+	// 解析成功响应
+	return p.parseSuccessResponse(rawResp)
+}
+
+// parseAPIError 解析API错误
+func (p *CompatibleProvider) parseAPIError(errObj interface{}) *APIError {
+	errMap, ok := errObj.(map[string]interface{})
+	if !ok {
+		return &APIError{
+			Code:    "unknown",
+			Message: fmt.Sprintf("%v", errObj),
+		}
+	}
+
+	code, _ := errMap["code"].(string)
+	message, _ := errMap["message"].(string)
+
+	return &APIError{
+		Code:      code,
+		Message:   message,
+		Retryable: p.isRetryableError(code),
+	}
+}
+
+// parseSuccessResponse 解析成功响应
+func (p *CompatibleProvider) parseSuccessResponse(resp map[string]interface{}) (*ChatResponse, error) {
+	choices, exists := resp["choices"].([]interface{})
+	if !exists || len(choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice, _ := choices[0].(map[string]interface{})
+	message, _ := choice["message"].(map[string]interface{})
+
+	// 构建消息内容
+	content, _ := message["content"].(string)
+	role, _ := message["role"].(string)
+	finish, _ := choice["finish_reason"].(string)
+
+	// 解析函数调用，优先 tool_calls，兼容旧版 function_call
+	functionCall := parseToolCalls(message["tool_calls"])
+	if functionCall == nil {
+		if fnMap, ok := message["function_call"].(map[string]interface{}); ok {
+			name, _ := fnMap["name"].(string)
+			functionCall = &FunctionCall{Name: name, Arguments: parseArguments(fnMap["arguments"])}
+		}
+	}
+
+	model, _ := resp["model"].(string)
+	if model == "" {
+		model = p.getModel("")
+	}
+
+	return &ChatResponse{
+		ID: fmt.Sprintf("chat_%d", time.Now().Unix()),
+		Message: Message{
+			Role:         role,
+			Content:      content,
+			FunctionCall: functionCall,
+			Timestamp:    time.Now(),
+		},
+		Usage:  parseUsage(resp["usage"]),
+		Model:  model,
+		Finish: convertFinishReason(finish),
+		Stream: false,
+		Done:   true,
+	}, nil
+}
+
+// streamToolCall 流式响应中按 index 累积的工具调用
+type streamToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// handleStreamResponse 处理流式响应，ctx 取消后不再发送
+func (p *CompatibleProvider) handleStreamResponse(ctx context.Context, body io.ReadCloser, respChan chan<- *ChatResponse) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	send := func(resp *ChatResponse) bool {
+		select {
+		case respChan <- resp:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	toolCalls := make(map[int]*streamToolCall)
+	for {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil && ctx.Err() == nil {
+				// 发送错误
+				send(&ChatResponse{
+					Error: &APIError{
+						Code:    "stream_error",
+						Message: err.Error(),
+					},
+					Stream: true,
+					Done:   true,
+				})
+			}
+			return
+		}
+
+		line := scanner.Text()
+
+		// 跳过空行和注释（部分网关用于保活）
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		// 解析SSE格式，冒号后的空格可省略
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+
+		if data == "[DONE]" {
+			// 流结束
+			send(&ChatResponse{
+				Stream: true,
+				Done:   true,
+			})
+			return
+		}
+
+		// 解析JSON数据
+		var streamResp map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			continue // 跳过错误数据
+		}
+
+		// 流中途的错误
+		if errObj, exists := streamResp["error"]; exists && errObj != nil {
+			apiErr := p.parseAPIError(errObj)
+			if apiErr.Code == "" {
+				apiErr.Code = "stream_error"
+			}
+			send(&ChatResponse{Error: apiErr, Stream: true, Done: true})
+			return
+		}
+
+		// 转换为ChatResponse
+		if resp := p.convertStreamResponse(streamResp, toolCalls); resp != nil {
+			if !send(resp) {
+				return
+			}
+		}
+	}
+}
+
+// convertStreamResponse 转换流式响应，开启 include_usage 后最后一个分片只包含用量。
+// 工具调用的参数分多个分片返回，累积到结束时一并输出
+func (p *CompatibleProvider) convertStreamResponse(resp map[string]interface{}, toolCalls map[int]*streamToolCall) *ChatResponse {
+	usage := parseUsage(resp["usage"])
+	choices, exists := resp["choices"].([]interface{})
+	if !exists || len(choices) == 0 {
+		if usage.TotalTokens == 0 {
+			return nil
+		}
+		return &ChatResponse{
+			ID:        fmt.Sprintf("stream_%d", time.Now().UnixNano()),
+			Usage:     usage,
+			Stream:    true,
+			Timestamp: time.Now(),
+		}
+	}
+
+	choice, _ := choices[0].(map[string]interface{})
+	delta, _ := choice["delta"].(map[string]interface{})
+
+	content, _ := delta["content"].(string)
+	role, _ := delta["role"].(string)
+	finish, _ := choice["finish_reason"].(string)
+	model, _ := resp["model"].(string)
+
+	calls, _ := delta["tool_calls"].([]interface{})
+	for _, item := range calls {
+		callMap, _ := item.(map[string]interface{})
+		index, _ := callMap["index"].(float64)
+		call, exists := toolCalls[int(index)]
+		if !exists {
+			call = &streamToolCall{}
+			toolCalls[int(index)] = call
+		}
+		if id, _ := callMap["id"].(string); id != "" {
+			call.id = id
+		}
+		fn, _ := callMap["function"].(map[string]interface{})
+		if name, _ := fn["name"].(string); name != "" {
+			call.name = name
+		}
+		if arguments, _ := fn["arguments"].(string); arguments != "" {
+			call.arguments.WriteString(arguments)
+		}
+	}
+
+	// 只有工具调用参数的分片不单独输出
+	if content == "" && finish == "" && usage.TotalTokens == 0 && len(calls) > 0 {
+		return nil
+	}
+
+	var functionCall *FunctionCall
+	if finish != "" && len(toolCalls) > 0 {
+		functionCall = collectToolCalls(toolCalls)
+	}
+
+	return &ChatResponse{
+		ID: fmt.Sprintf("stream_%d", time.Now().UnixNano()),
+		Message: Message{
+			Role:         role,
+			Content:      content,
+			FunctionCall: functionCall,
+			Timestamp:    time.Now(),
+			Streaming:    true,
+		},
+		Usage:  usage,
+		Model:  model,
+		Finish: convertFinishReason(finish),
+		Stream: true,
+		Delta:  content,
+		Done:   false,
+	}
+}
+
+// collectToolCalls 取 index 最小的工具调用
+func collectToolCalls(toolCalls map[int]*streamToolCall) *FunctionCall {
+	indexes := make([]int, 0, len(toolCalls))
+	for index := range toolCalls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	call := toolCalls[indexes[0]]
+	return &FunctionCall{
+		ID:        call.id,
+		Name:      call.name,
+		Arguments: parseArguments(call.arguments.String()),
+	}
+}
+
+// parseToolCalls 解析 tool_calls，取第一个工具调用
+func parseToolCalls(value interface{}) *FunctionCall {
+	calls, _ := value.([]interface{})
+	if len(calls) == 0 {
+		return nil
+	}
+
+	callMap, _ := calls[0].(map[string]interface{})
+	fn, _ := callMap["function"].(map[string]interface{})
+	id, _ := callMap["id"].(string)
+	name, _ := fn["name"].(string)
+	return &FunctionCall{ID: id, Name: name, Arguments: parseArguments(fn["arguments"])}
+}
+
+// parseArguments 解析函数参数，标准格式为 JSON 字符串，部分网关直接返回对象
+func parseArguments(value interface{}) map[string]interface{} {
+	switch arguments := value.(type) {
+	case map[string]interface{}:
+		return arguments
+	case string:
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(arguments), &parsed); err == nil {
+			return parsed
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// convertFinishReason 转换结束原因，工具调用统一为 function_call
+func convertFinishReason(reason string) string {
+	if reason == "tool_calls" {
+		return "function_call"
+	}
+	return reason
+}
+
+// parseUsage 解析用量字段，缺失时返回零值
+func parseUsage(usageObj interface{}) Usage {
+	var usage Usage
+	usageMap, ok := usageObj.(map[string]interface{})
+	if !ok {
+		return usage
+	}
+	if promptTokens, ok := usageMap["prompt_tokens"].(float64); ok {
+		usage.PromptTokens = int(promptTokens)
+	}
+	if completionTokens, ok := usageMap["completion_tokens"].(float64); ok {
+		usage.CompletionTokens = int(completionTokens)
+	}
+	if totalTokens, ok := usageMap["total_tokens"].(float64); ok {
+		usage.TotalTokens = int(totalTokens)
+	}
+	return usage
+}
+
+// isRetryableError 检查是否为可重试错误
+func (p *CompatibleProvider) isRetryableError(code string) bool {
+	retryableCodes := []string{
+		"rate_limit_exceeded",
+		"insufficient_quota",
+		"engine_overloaded",
+	}
+
+	for _, retryableCode := range retryableCodes {
+		if code == retryableCode {
+			return true
+		}
+	}
+
+	return false
+}
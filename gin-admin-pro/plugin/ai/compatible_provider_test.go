@@ -0,0 +1,238 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compatibleToolStream 录制的工具调用流式响应，参数分多个分片返回
+const compatibleToolStream = `data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_0_1a2b","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"上海\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[{"index":0,"delta":{"content":""},"finish_reason":"tool_calls"}]}
+
+data: {"id":"chatcmpl-1","model":"deepseek-chat","choices":[],"usage":{"prompt_tokens":120,"completion_tokens":18,"total_tokens":138}}
+
+data: [DONE]
+
+`
+
+// newCompatibleTestProvider 创建指向模拟服务的兼容提供商
+func newCompatibleTestProvider(t *testing.T, provider AIProvider, configure func(*Config), handler http.HandlerFunc) AIProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.Model = ""
+	if configure != nil {
+		configure(config)
+	}
+	require.NoError(t, provider.Initialize(config))
+	return provider
+}
+
+func TestDeepSeekProviderChat(t *testing.T) {
+	provider := newCompatibleTestProvider(t, NewDeepSeekProvider(), func(config *Config) {
+		config.Provider = "deepseek"
+		config.APIKey = "sk-deepseek"
+	}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-deepseek", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "deepseek-chat", body["model"])
+		assert.Equal(t, "u1", body["user"])
+
+		tools := body["tools"].([]interface{})
+		require.Len(t, tools, 1)
+		assert.Equal(t, "function", tools[0].(map[string]interface{})["type"])
+
+		// 函数调用历史：参数为 JSON 字符串，结果为 tool 消息
+		messages := body["messages"].([]interface{})
+		require.Len(t, messages, 4)
+		assistant := messages[2].(map[string]interface{})
+		call := assistant["tool_calls"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "call_1", call["id"])
+		assert.Equal(t, `{"location":"北京"}`, call["function"].(map[string]interface{})["arguments"])
+		tool := messages[3].(map[string]interface{})
+		assert.Equal(t, "tool", tool["role"])
+		assert.Equal(t, "call_1", tool["tool_call_id"])
+		assert.Equal(t, `{"weather":"晴"}`, tool["content"])
+
+		fmt.Fprint(w, `{"id":"chatcmpl-2","model":"deepseek-chat","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_2","type":"function","function":{"name":"get_weather","arguments":"{\"location\":\"上海\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":50,"completion_tokens":10,"total_tokens":60}}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{
+		UserID: "u1",
+		Messages: []Message{
+			{Role: "user", Content: "北京天气"},
+			{Role: "assistant", FunctionCall: &FunctionCall{ID: "call_1", Name: "get_weather", Arguments: map[string]interface{}{"location": "北京"}}},
+			{Role: "function", FunctionCall: &FunctionCall{ID: "call_1", Name: "get_weather", Result: map[string]string{"weather": "晴"}}},
+		},
+		Functions: []FunctionDefinition{{Name: "get_weather", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "deepseek-chat", resp.Model)
+	assert.Equal(t, "function_call", resp.Finish)
+	require.NotNil(t, resp.Message.FunctionCall)
+	assert.Equal(t, "call_2", resp.Message.FunctionCall.ID)
+	assert.Equal(t, "上海", resp.Message.FunctionCall.Arguments["location"])
+	assert.Equal(t, 60, resp.Usage.TotalTokens)
+}
+
+func TestDeepSeekProviderStreamToolCall(t *testing.T) {
+	provider := newCompatibleTestProvider(t, NewDeepSeekProvider(), func(config *Config) {
+		config.Provider = "deepseek"
+		config.APIKey = "sk-deepseek"
+	}, replayStream(compatibleToolStream))
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "上海天气"}}})
+	require.NoError(t, err)
+
+	var call *FunctionCall
+	var usage Usage
+	for _, chunk := range collectStream(t, chunks) {
+		require.Nil(t, chunk.Error)
+		if chunk.Message.FunctionCall != nil {
+			call = chunk.Message.FunctionCall
+			assert.Equal(t, "function_call", chunk.Finish)
+		}
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+	}
+	require.NotNil(t, call)
+	assert.Equal(t, "call_0_1a2b", call.ID)
+	assert.Equal(t, "get_weather", call.Name)
+	assert.Equal(t, "上海", call.Arguments["location"])
+	assert.Equal(t, 138, usage.TotalTokens)
+}
+
+func TestQwenProvider(t *testing.T) {
+	provider := newCompatibleTestProvider(t, NewQwenProvider(), func(config *Config) {
+		config.Provider = "qwen"
+		config.Qwen.DashScopeAPIKey = "sk-dashscope"
+		config.Qwen.Model = "qwen-plus"
+	}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-dashscope", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "qwen-plus", body["model"])
+
+		fmt.Fprint(w, `{"model":"qwen-plus","choices":[{"message":{"role":"assistant","content":"你好"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "你好", resp.Message.Content)
+
+	info, err := provider.GetModelInfo("")
+	require.NoError(t, err)
+	assert.Equal(t, "qwen-plus", info.ID)
+	assert.Equal(t, "qwen", info.Provider)
+	assert.Greater(t, info.OutputCost, info.InputCost)
+
+	// 默认地址为 DashScope 兼容模式
+	config := DefaultConfig()
+	config.Provider = "qwen"
+	assert.Equal(t, "https://dashscope.aliyuncs.com/compatible-mode/v1", config.GetEffectiveBaseURL())
+	assert.NoError(t, NewQwenProvider().Initialize(&Config{Provider: "qwen", Qwen: &QwenConfig{DashScopeAPIKey: "sk"}}))
+	assert.Error(t, NewQwenProvider().Initialize(&Config{Provider: "qwen"}))
+}
+
+func TestCompatibleProviderError(t *testing.T) {
+	provider := newCompatibleTestProvider(t, NewDeepSeekProvider(), func(config *Config) {
+		config.APIKey = "sk-deepseek"
+	}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprint(w, `{"error":{"message":"Insufficient Balance","type":"unknown_error","param":null,"code":"invalid_request_error"}}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "invalid_request_error", resp.Error.Code)
+	assert.Equal(t, "Insufficient Balance", resp.Error.Message)
+	assert.Equal(t, http.StatusPaymentRequired, resp.Error.StatusCode)
+	assert.False(t, resp.Error.Retryable)
+
+	err = provider.HealthCheck(context.Background())
+	assert.ErrorContains(t, err, "Insufficient Balance")
+}
+
+func TestGatewayProvider(t *testing.T) {
+	gateway := &GatewayConfig{
+		AuthHeader: "api-key",
+		APIKey:     "gw-key",
+		Headers:    map[string]string{"X-Tenant": "acme"},
+		Models: []GatewayModel{
+			{ID: "Qwen/Qwen2.5-72B-Instruct", InputCost: 0.0005, OutputCost: 0.001, ContextSize: 32768},
+			{ID: "deepseek-ai/DeepSeek-V3"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gw-key", r.Header.Get("api-key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant"))
+
+		switch r.URL.Path {
+		case "/v1/models":
+			fmt.Fprint(w, `{"data":[]}`)
+		case "/v1/chat/completions":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "Qwen/Qwen2.5-72B-Instruct", body["model"])
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keep-alive\n\ndata:{\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	gateway.BaseURL = server.URL + "/v1/"
+
+	// 网关只需 YAML 配置，通用 apiKey 可为空
+	config := DefaultConfig()
+	config.Enabled = true
+	config.Provider = "siliconflow"
+	config.Model = ""
+	config.Gateways = map[string]*GatewayConfig{"siliconflow": gateway}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, gateway.BaseURL, config.GetEffectiveBaseURL())
+
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	provider := service.Provider()
+	assert.Equal(t, []string{"Qwen/Qwen2.5-72B-Instruct", "deepseek-ai/DeepSeek-V3"}, provider.GetModels())
+	assert.NoError(t, provider.HealthCheck(context.Background()))
+
+	info, err := provider.GetModelInfo("")
+	require.NoError(t, err)
+	assert.Equal(t, "siliconflow", info.Provider)
+	assert.Equal(t, 0.001, info.OutputCost)
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", collectDeltas(chunks))
+
+	config.Provider = "unknown"
+	config.APIKey = "sk-test"
+	_, err = NewDefaultAIService(config)
+	assert.ErrorContains(t, err, "unsupported provider")
+}
@@ -24,8 +24,13 @@ type Config struct {
 	Timeout      time.Duration `yaml:"timeout" mapstructure:"timeout"`
 
 	// 历史记录配置
+	// MaxHistoryLength 每次请求最多携带的历史消息数，0 表示只受模型上下文窗口限制
 	MaxHistoryLength int  `yaml:"maxHistoryLength" mapstructure:"maxHistoryLength"`
 	EnableHistory    bool `yaml:"enableHistory" mapstructure:"enableHistory"`
+	// EnableSummary 历史超出限制时由模型将较早的消息概括为摘要并保存到对话，关闭时直接丢弃
+	EnableSummary bool `yaml:"enableSummary" mapstructure:"enableSummary"`
+	// SummaryMaxTokens 摘要的最大 token 数
+	SummaryMaxTokens int `yaml:"summaryMaxTokens" mapstructure:"summaryMaxTokens"`
 
 	// 并发配置
 	MaxConcurrentRequests int           `yaml:"maxConcurrentRequests" mapstructure:"maxConcurrentRequests"`
@@ -38,14 +43,23 @@ type Config struct {
 	MaxRetries int           `yaml:"maxRetries" mapstructure:"maxRetries"`
 	RetryDelay time.Duration `yaml:"retryDelay" mapstructure:"retryDelay"`
 
-	// 成本控制
-	DailyTokenLimit int  `yaml:"dailyTokenLimit" mapstructure:"dailyTokenLimit"`
-	EnableCostLimit bool `yaml:"enableCostLimit" mapstructure:"enableCostLimit"`
+	// 成本控制：按用户每日累计，0 表示不限制
+	DailyTokenLimit int     `yaml:"dailyTokenLimit" mapstructure:"dailyTokenLimit"`
+	EnableCostLimit bool    `yaml:"enableCostLimit" mapstructure:"enableCostLimit"`
+	DailyCostLimit  float64 `yaml:"dailyCostLimit" mapstructure:"dailyCostLimit"` // 美元
+
+	// 安全防护
+	RateLimit     RateLimitConfig     `yaml:"rateLimit" mapstructure:"rateLimit"`
+	Audit         AuditConfig         `yaml:"audit" mapstructure:"audit"`
+	ContentFilter ContentFilterConfig `yaml:"contentFilter" mapstructure:"contentFilter"`
 
 	// 功能配置
 	EnableFunctionCalling bool `yaml:"enableFunctionCalling" mapstructure:"enableFunctionCalling"`
-	EnableImageInput      bool `yaml:"enableImageInput" mapstructure:"enableImageInput"`
-	EnableVoiceInput      bool `yaml:"enableVoiceInput" mapstructure:"enableVoiceInput"`
+	// MaxToolIterations 单次对话最多执行的工具调用轮数，防止模型反复调用工具
+	MaxToolIterations int `yaml:"maxToolIterations" mapstructure:"maxToolIterations"`
+	// EnableImageInput 是否接受图片输入，未启用时带图片的请求返回 ErrImageInputDisabled，模型需支持视觉
+	EnableImageInput bool `yaml:"enableImageInput" mapstructure:"enableImageInput"`
+	EnableVoiceInput bool `yaml:"enableVoiceInput" mapstructure:"enableVoiceInput"`
 
 	// 提供商特定配置
 	OpenAI   *OpenAIConfig   `yaml:"openai" mapstructure:"openai"`
@@ -54,6 +68,13 @@ type Config struct {
 	Qwen     *QwenConfig     `yaml:"qwen" mapstructure:"qwen"`
 	Ollama   *OllamaConfig   `yaml:"ollama" mapstructure:"ollama"`
 
+	// Gateways OpenAI 兼容网关，Provider 为网关名称时使用
+	Gateways map[string]*GatewayConfig `yaml:"gateways" mapstructure:"gateways"`
+
+	// Routes 多提供商路由，配置后按模型、标签和权重选择提供商并故障转移，Provider 不再使用
+	Routes         []RouteConfig        `yaml:"routes" mapstructure:"routes"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker" mapstructure:"circuitBreaker"`
+
 	// 监控配置
 	Metrics struct {
 		Enabled bool     `yaml:"enabled" mapstructure:"enabled"`
@@ -62,11 +83,42 @@ type Config struct {
 	} `yaml:"metrics" mapstructure:"metrics"`
 
 	// 缓存配置
-	Cache struct {
-		Enabled bool          `yaml:"enabled" mapstructure:"enabled"`
-		TTL     time.Duration `yaml:"ttl" mapstructure:"ttl"`
-		MaxSize int           `yaml:"maxSize" mapstructure:"maxSize"`
-	} `yaml:"cache" mapstructure:"cache"`
+	Cache CacheConfig `yaml:"cache" mapstructure:"cache"`
+}
+
+// CacheConfig 响应缓存配置，只缓存非流式且未执行工具的回复
+type CacheConfig struct {
+	Enabled bool          `yaml:"enabled" mapstructure:"enabled"`
+	TTL     time.Duration `yaml:"ttl" mapstructure:"ttl"`
+	// MaxSize 内存缓存最多缓存的响应数，也是每个语义分组最多记录的提问数
+	MaxSize  int                 `yaml:"maxSize" mapstructure:"maxSize"`
+	Semantic SemanticCacheConfig `yaml:"semantic" mapstructure:"semantic"`
+}
+
+// SemanticCacheConfig 语义缓存配置，上下文相同且提问向量相似度不低于 Threshold 时返回已缓存的回复，需设置向量模型
+type SemanticCacheConfig struct {
+	Enabled   bool    `yaml:"enabled" mapstructure:"enabled"`
+	Threshold float64 `yaml:"threshold" mapstructure:"threshold"`
+}
+
+// RateLimitConfig 按用户限流配置，Window 内最多 Requests 次请求
+type RateLimitConfig struct {
+	Enabled  bool          `yaml:"enabled" mapstructure:"enabled"`
+	Requests int           `yaml:"requests" mapstructure:"requests"`
+	Window   time.Duration `yaml:"window" mapstructure:"window"`
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	Enabled          bool `yaml:"enabled" mapstructure:"enabled"`
+	MaxContentLength int  `yaml:"maxContentLength" mapstructure:"maxContentLength"` // 记录的提问和回复最大字符数，0 表示不截断
+}
+
+// ContentFilterConfig 关键词内容过滤配置，提问命中时拒绝请求，回复命中时替换为 Replacement
+type ContentFilterConfig struct {
+	Enabled     bool     `yaml:"enabled" mapstructure:"enabled"`
+	Keywords    []string `yaml:"keywords" mapstructure:"keywords"`
+	Replacement string   `yaml:"replacement" mapstructure:"replacement"`
 }
 
 // OpenAIConfig OpenAI配置
@@ -98,20 +150,42 @@ type QwenConfig struct {
 type OllamaConfig struct {
 	Host          string  `yaml:"host" mapstructure:"host"`
 	Port          int     `yaml:"port" mapstructure:"port"`
-	Model         string  `yaml:"model" mapstructure:"model"`
-	KeepAlive     bool    `yaml:"keepAlive" mapstructure:"keepAlive"`
-	NumPredict    int     `yaml:"numPredict" mapstructure:"numPredict"`
-	NumCtx        int     `yaml:"numCtx" mapstructure:"numCtx"`
+	Model         string  `yaml:"model" mapstructure:"model"`           // 未配置通用 model 时使用
+	KeepAlive     bool    `yaml:"keepAlive" mapstructure:"keepAlive"`   // 模型常驻内存，否则空闲 5 分钟后卸载
+	NumPredict    int     `yaml:"numPredict" mapstructure:"numPredict"` // 最大生成 token 数，0 表示使用 maxTokens
+	NumCtx        int     `yaml:"numCtx" mapstructure:"numCtx"`         // 上下文窗口大小
 	RepeatPenalty float64 `yaml:"repeatPenalty" mapstructure:"repeatPenalty"`
 }
 
+// GatewayConfig OpenAI 兼容网关配置
+type GatewayConfig struct {
+	BaseURL    string            `yaml:"baseUrl" mapstructure:"baseUrl"`
+	APIKey     string            `yaml:"apiKey" mapstructure:"apiKey"`         // 为空时使用通用 apiKey，均为空时不发送认证头
+	AuthHeader string            `yaml:"authHeader" mapstructure:"authHeader"` // 认证请求头，默认 Authorization
+	AuthScheme string            `yaml:"authScheme" mapstructure:"authScheme"` // 认证值前缀，Authorization 默认 Bearer，其他请求头默认无前缀
+	Headers    map[string]string `yaml:"headers" mapstructure:"headers"`
+	Model      string            `yaml:"model" mapstructure:"model"` // 默认模型，为空时使用 Models 中的第一个
+	Models     []GatewayModel    `yaml:"models" mapstructure:"models"`
+}
+
+// GatewayModel 网关模型目录，价格单位为美元/1K tokens
+type GatewayModel struct {
+	ID          string   `yaml:"id" mapstructure:"id"`
+	Name        string   `yaml:"name" mapstructure:"name"`
+	MaxTokens   int      `yaml:"maxTokens" mapstructure:"maxTokens"`
+	ContextSize int      `yaml:"contextSize" mapstructure:"contextSize"`
+	InputCost   float64  `yaml:"inputCost" mapstructure:"inputCost"`
+	OutputCost  float64  `yaml:"outputCost" mapstructure:"outputCost"`
+	Features    []string `yaml:"features" mapstructure:"features"`
+}
+
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
 		Enabled:               true,
 		Provider:              "openai",
 		APIKey:                "",
-		BaseURL:               "https://api.openai.com/v1",
+		BaseURL:               "", // 为空时使用提供商默认地址
 		Model:                 "gpt-3.5-turbo",
 		MaxTokens:             2048,
 		Temperature:           0.7,
@@ -120,6 +194,8 @@ func DefaultConfig() *Config {
 		Timeout:               time.Second * 30,
 		MaxHistoryLength:      20,
 		EnableHistory:         true,
+		EnableSummary:         true,
+		SummaryMaxTokens:      500,
 		MaxConcurrentRequests: 10,
 		RequestTimeout:        time.Second * 60,
 		EnableStreaming:       true,
@@ -127,10 +203,31 @@ func DefaultConfig() *Config {
 		RetryDelay:            time.Second * 2,
 		DailyTokenLimit:       100000,
 		EnableCostLimit:       false,
+		DailyCostLimit:        0,
 		EnableFunctionCalling: false,
+		MaxToolIterations:     5,
 		EnableImageInput:      false,
 		EnableVoiceInput:      false,
 
+		// 安全防护默认配置
+		RateLimit: RateLimitConfig{
+			Enabled:  true,
+			Requests: 20,
+			Window:   time.Minute,
+		},
+		Audit: AuditConfig{
+			Enabled:          true,
+			MaxContentLength: 500,
+		},
+		ContentFilter: ContentFilterConfig{
+			Enabled:     false,
+			Replacement: "***",
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+
 		// 提供商默认配置
 		OpenAI: &OpenAIConfig{
 			Organization: "",
@@ -152,7 +249,7 @@ func DefaultConfig() *Config {
 			Port:          11434,
 			Model:         "llama2",
 			KeepAlive:     true,
-			NumPredict:    128,
+			NumPredict:    0,
 			NumCtx:        2048,
 			RepeatPenalty: 1.1,
 		},
@@ -169,14 +266,14 @@ func DefaultConfig() *Config {
 		},
 
 		// 缓存默认配置
-		Cache: struct {
-			Enabled bool          `yaml:"enabled" mapstructure:"enabled"`
-			TTL     time.Duration `yaml:"ttl" mapstructure:"ttl"`
-			MaxSize int           `yaml:"maxSize" mapstructure:"maxSize"`
-		}{
+		Cache: CacheConfig{
 			Enabled: false,
 			TTL:     time.Hour,
 			MaxSize: 1000,
+			Semantic: SemanticCacheConfig{
+				Enabled:   false,
+				Threshold: 0.95,
+			},
 		},
 	}
 }
@@ -232,13 +329,16 @@ func (c *Config) GetEffectiveBaseURL() string {
 	case "deepseek":
 		return "https://api.deepseek.com"
 	case "qwen":
-		return "https://dashscope.aliyuncs.com/api/v1"
+		return "https://dashscope.aliyuncs.com/compatible-mode/v1"
 	case "ollama":
 		if c.Ollama != nil {
 			return fmt.Sprintf("http://%s:%d", c.Ollama.Host, c.Ollama.Port)
 		}
 		return "http://localhost:11434"
 	default:
+		if gateway, exists := c.Gateways[c.Provider]; exists && gateway != nil {
+			return gateway.BaseURL
+		}
 		return ""
 	}
 }
@@ -249,12 +349,18 @@ func (c *Config) Validate() error {
 		return nil
 	}
 
-	if c.Provider == "" {
-		return fmt.Errorf("AI provider is required")
-	}
+	if len(c.Routes) > 0 {
+		if err := c.validateRoutes(); err != nil {
+			return err
+		}
+	} else {
+		if c.Provider == "" {
+			return fmt.Errorf("AI provider is required")
+		}
 
-	if c.Provider != "ollama" && c.APIKey == "" {
-		return fmt.Errorf("API key is required for provider %s", c.Provider)
+		if c.requiresAPIKey() && c.APIKey == "" {
+			return fmt.Errorf("API key is required for provider %s", c.Provider)
+		}
 	}
 
 	if c.MaxTokens <= 0 {
@@ -277,5 +383,50 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("maxConcurrentRequests must be greater than 0")
 	}
 
+	if c.RateLimit.Enabled && (c.RateLimit.Requests <= 0 || c.RateLimit.Window <= 0) {
+		return fmt.Errorf("rateLimit requests and window must be greater than 0")
+	}
+
+	if c.EnableCostLimit && c.DailyCostLimit <= 0 {
+		return fmt.Errorf("dailyCostLimit must be greater than 0 when cost limit is enabled")
+	}
+
+	return nil
+}
+
+// validateRoutes 验证多提供商路由，各路由使用自己的 apiKey
+func (c *Config) validateRoutes() error {
+	names := make(map[string]bool, len(c.Routes))
+	for i, route := range c.Routes {
+		if route.Provider == "" {
+			return fmt.Errorf("routes[%d]: provider is required", i)
+		}
+		name := routeName(route)
+		if names[name] {
+			return fmt.Errorf("routes[%d]: duplicate route name %s", i, name)
+		}
+		names[name] = true
+		if route.Weight < 0 {
+			return fmt.Errorf("routes[%d]: weight must not be negative", i)
+		}
+	}
+
+	if c.CircuitBreaker.FailureThreshold <= 0 || c.CircuitBreaker.OpenTimeout <= 0 {
+		return fmt.Errorf("circuitBreaker failureThreshold and openTimeout must be greater than 0")
+	}
 	return nil
 }
+
+// requiresAPIKey 提供商是否必须配置通用 apiKey：Ollama 和网关不需要，Qwen 可使用 dashScopeApiKey
+func (c *Config) requiresAPIKey() bool {
+	switch c.Provider {
+	case "ollama":
+		return false
+	case "qwen":
+		return c.Qwen == nil || c.Qwen.DashScopeAPIKey == ""
+	}
+	if gateway, exists := c.Gateways[c.Provider]; exists && gateway != nil {
+		return false
+	}
+	return true
+}
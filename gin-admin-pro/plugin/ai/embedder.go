@@ -0,0 +1,273 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Embedder 文本向量化接口，用于知识库检索和语义缓存
+type Embedder interface {
+	// Embed 批量向量化文本，返回的向量与输入顺序一致
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Dimension 向量维度
+	Dimension() int
+	// Model 向量模型名称，模型不同的向量不能混用
+	Model() string
+}
+
+// EmbeddingConfig OpenAI 兼容向量接口（/embeddings）配置
+type EmbeddingConfig struct {
+	BaseURL   string        `yaml:"baseUrl" json:"baseUrl"`
+	APIKey    string        `yaml:"apiKey" json:"apiKey"`
+	Model     string        `yaml:"model" json:"model"`
+	Dimension int           `yaml:"dimension" json:"dimension"` // 向量维度，需与模型输出一致
+	BatchSize int           `yaml:"batchSize" json:"batchSize"` // 单次请求的最大文本数
+	Timeout   time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// DefaultEmbeddingConfig 默认向量配置，使用 OpenAI text-embedding-3-small
+func DefaultEmbeddingConfig() *EmbeddingConfig {
+	return &EmbeddingConfig{
+		BaseURL:   "https://api.openai.com/v1",
+		Model:     "text-embedding-3-small",
+		Dimension: 1536,
+		BatchSize: 64,
+		Timeout:   30 * time.Second,
+	}
+}
+
+// OpenAIEmbedder OpenAI 兼容向量接口实现，适用于 OpenAI、通义千问、硅基流动等
+type OpenAIEmbedder struct {
+	client *http.Client
+	config *EmbeddingConfig
+}
+
+// NewOpenAIEmbedder 创建 OpenAI 兼容向量化实例，未配置的项使用默认值
+func NewOpenAIEmbedder(config *EmbeddingConfig) (*OpenAIEmbedder, error) {
+	defaults := DefaultEmbeddingConfig()
+	if config == nil {
+		config = defaults
+	}
+	merged := *config
+	if merged.BaseURL == "" {
+		merged.BaseURL = defaults.BaseURL
+	}
+	if merged.Model == "" {
+		merged.Model = defaults.Model
+		if merged.Dimension == 0 {
+			merged.Dimension = defaults.Dimension
+		}
+	}
+	if merged.BatchSize <= 0 {
+		merged.BatchSize = defaults.BatchSize
+	}
+	if merged.Timeout <= 0 {
+		merged.Timeout = defaults.Timeout
+	}
+	if merged.Dimension <= 0 {
+		return nil, fmt.Errorf("embedding dimension is required for model %s", merged.Model)
+	}
+
+	return &OpenAIEmbedder{
+		client: &http.Client{Timeout: merged.Timeout},
+		config: &merged,
+	}, nil
+}
+
+// Dimension 向量维度
+func (e *OpenAIEmbedder) Dimension() int {
+	return e.config.Dimension
+}
+
+// Model 向量模型名称
+func (e *OpenAIEmbedder) Model() string {
+	return e.config.Model
+}
+
+// Embed 批量向量化文本，超过 BatchSize 时分多次请求
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.config.BatchSize {
+		end := min(start+e.config.BatchSize, len(texts))
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// embedBatch 请求一次 /embeddings 接口
+func (e *OpenAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBytes, err := json.Marshal(map[string]interface{}{
+		"model": e.config.Model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(e.config.BaseURL, "/")+"/embeddings", bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Error *struct {
+			Code    interface{} `json:"code"`
+			Message string      `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != nil {
+		apiErr := &APIError{
+			Code:       fmt.Sprintf("HTTP_%d", resp.StatusCode),
+			Message:    string(body),
+			StatusCode: resp.StatusCode,
+			Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+		if result.Error != nil && result.Error.Message != "" {
+			apiErr.Message = result.Error.Message
+			if code, ok := result.Error.Code.(string); ok && code != "" {
+				apiErr.Code = code
+			}
+		}
+		return nil, apiErr
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding count mismatch: want %d, got %d", len(texts), len(result.Data))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		if len(item.Embedding) != e.config.Dimension {
+			return nil, fmt.Errorf("embedding dimension mismatch: want %d, got %d", e.config.Dimension, len(item.Embedding))
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// HashEmbedder 本地哈希向量化，将词和中文二元组哈希到固定维度并归一化。
+// 结果确定且不依赖外部服务，用于测试和离线环境，只能匹配字面相近的文本
+type HashEmbedder struct {
+	dimension int
+}
+
+// NewHashEmbedder 创建本地哈希向量化实例，dimension 不大于 0 时使用 256
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	if dimension <= 0 {
+		dimension = 256
+	}
+	return &HashEmbedder{dimension: dimension}
+}
+
+// Dimension 向量维度
+func (e *HashEmbedder) Dimension() int {
+	return e.dimension
+}
+
+// Model 向量模型名称
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", e.dimension)
+}
+
+// Embed 批量向量化文本
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed 向量化单条文本，英文和数字按词，中文按单字和相邻二字
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dimension)
+	add := func(token string) {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+		// 低位决定维度，最高位决定符号，减少哈希冲突带来的偏差
+		if sum>>63 == 0 {
+			vector[sum%uint64(e.dimension)]++
+		} else {
+			vector[sum%uint64(e.dimension)]--
+		}
+	}
+
+	var (
+		word strings.Builder
+		prev rune
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			add(word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			add(string(r))
+			if prev != 0 {
+				add(string([]rune{prev, r}))
+			}
+			prev = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+		prev = 0
+	}
+	flush()
+
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value * value)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+	return vector
+}
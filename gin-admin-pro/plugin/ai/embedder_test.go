@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "bge-m3", body.Model)
+		batches = append(batches, body.Input)
+
+		if body.Input[0] == "fail" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"code":"rate_limit","message":"slow down"}}`)
+			return
+		}
+		// 乱序返回，按 index 还原顺序
+		var data []string
+		for i := len(body.Input) - 1; i >= 0; i-- {
+			data = append(data, fmt.Sprintf(`{"index":%d,"embedding":[%d,0]}`, i, len(body.Input[i])))
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(data, ","))
+	}))
+	defer server.Close()
+
+	embedder, err := NewOpenAIEmbedder(&EmbeddingConfig{BaseURL: server.URL + "/", APIKey: "sk-test", Model: "bge-m3", Dimension: 2, BatchSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, embedder.Dimension())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {2, 0}, {3, 0}}, vectors)
+	assert.Equal(t, [][]string{{"a", "bb"}, {"ccc"}}, batches)
+
+	_, err = embedder.Embed(context.Background(), []string{"fail"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "rate_limit", apiErr.Code)
+	assert.True(t, apiErr.Retryable)
+
+	// 维度与配置不一致时报错
+	embedder, err = NewOpenAIEmbedder(&EmbeddingConfig{BaseURL: server.URL, APIKey: "sk-test", Model: "bge-m3", Dimension: 3})
+	require.NoError(t, err)
+	_, err = embedder.Embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "dimension mismatch")
+
+	_, err = NewOpenAIEmbedder(&EmbeddingConfig{Model: "custom"})
+	assert.Error(t, err)
+}
+
+func TestHashEmbedder(t *testing.T) {
+	embedder := NewHashEmbedder(128)
+	vectors, err := embedder.Embed(context.Background(), []string{"如何重置密码", "如何重置密码", "Reset the password", ""})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+	assert.Len(t, vectors[0], 128)
+	assert.Equal(t, vectors[0], vectors[1])
+	assert.Equal(t, "hash-128", embedder.Model())
+
+	var norm float32
+	for _, value := range vectors[2] {
+		norm += value * value
+	}
+	assert.InDelta(t, 1, norm, 1e-5)
+	assert.Equal(t, make([]float32, 128), vectors[3])
+}
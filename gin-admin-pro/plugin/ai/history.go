@@ -0,0 +1,257 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// contextUsageRatio 历史消息最多占用上下文窗口的比例，为 token 估算误差留出余量
+const contextUsageRatio = 0.9
+
+// summaryMessageMaxRunes 生成摘要时单条消息最多保留的字符数
+const summaryMessageMaxRunes = 1000
+
+// summarySystemPrompt 生成对话摘要的系统提示词
+const summarySystemPrompt = "你负责为对话生成摘要。请用简洁的中文概括对话要点，保留用户的身份、需求、已确认的事实、数字和结论以及尚未解决的问题，" +
+	"不要添加对话中没有的内容，直接输出摘要正文。"
+
+// summaryMessagePrefix 摘要插入请求时的前缀
+const summaryMessagePrefix = "以下是本次对话较早内容的摘要：\n"
+
+// fitContext 按 MaxHistoryLength 和模型上下文窗口裁剪历史消息。
+// 开头的系统消息、最后一条消息及其前面紧邻的系统消息（如知识库引用）始终保留；
+// 需要裁掉的较早消息在启用摘要且消息带 ID 时由模型概括为摘要并保存到对话，否则直接丢弃。
+// 返回裁剪后的请求副本和生成摘要消耗的用量，无需裁剪时返回原请求
+func (s *DefaultAIService) fitContext(ctx context.Context, req *ChatRequest) (*ChatRequest, Usage) {
+	messages := req.Messages
+	head := 0
+	for head < len(messages) && messages[head].Role == "system" {
+		head++
+	}
+	tail := len(messages) - 1
+	for tail > head && messages[tail-1].Role == "system" {
+		tail--
+	}
+	if tail <= head {
+		return req, Usage{}
+	}
+
+	history := messages[head:tail]
+	drop := s.historyOverflow(req, history, messages[:head], messages[tail:])
+	if drop == 0 {
+		return req, Usage{}
+	}
+
+	summary, drop, usage := s.summarizeHistory(ctx, req, history, drop)
+
+	fitted := *req
+	fitted.Messages = make([]Message, 0, len(messages)-drop+1)
+	fitted.Messages = append(fitted.Messages, messages[:head]...)
+	if summary != "" {
+		if head > 0 {
+			fitted.Messages = append(fitted.Messages, Message{Role: "system", Content: summaryMessagePrefix + summary})
+		} else {
+			// 首条消息为 system 时提供商不再添加系统提示词，没有系统消息时摘要并入系统提示词
+			fitted.SystemPrompt = strings.TrimSpace(s.systemPrompt(req) + "\n\n" + summaryMessagePrefix + summary)
+		}
+	}
+	fitted.Messages = append(fitted.Messages, history[drop:]...)
+	fitted.Messages = append(fitted.Messages, messages[tail:]...)
+	return &fitted, usage
+}
+
+// historyOverflow 计算需要从最早开始裁掉的历史消息数：超出 MaxHistoryLength 的部分，
+// 以及上下文窗口扣除回复、系统提示词、保留消息、函数定义和摘要后放不下的部分
+func (s *DefaultAIService) historyOverflow(req *ChatRequest, history, leading, trailing []Message) int {
+	drop := 0
+	if s.config.MaxHistoryLength > 0 && len(history) > s.config.MaxHistoryLength {
+		drop = len(history) - s.config.MaxHistoryLength
+	}
+
+	info, err := s.modelInfo(req.Model)
+	if err != nil || info.ContextSize <= 0 {
+		return drop
+	}
+
+	budget := int(float64(info.ContextSize) * contextUsageRatio)
+	if req.MaxTokens > 0 {
+		budget -= req.MaxTokens
+	} else {
+		budget -= s.config.MaxTokens
+	}
+	budget -= EstimateTokens(s.systemPrompt(req))
+	budget -= estimateMessagesTokens(leading) + estimateMessagesTokens(trailing)
+	if len(req.Functions) > 0 {
+		if functions, err := json.Marshal(req.Functions); err == nil {
+			budget -= EstimateTokens(string(functions))
+		}
+	}
+	if s.config.EnableSummary {
+		budget -= s.config.SummaryMaxTokens + estimateMessagesTokens([]Message{{Content: summaryMessagePrefix}})
+	}
+
+	// 从最新的消息往前保留，直到超出预算
+	kept, used := 0, 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += estimateMessagesTokens(history[i : i+1])
+		if used > budget {
+			break
+		}
+		kept++
+	}
+	if overflow := len(history) - kept; overflow > drop {
+		drop = overflow
+	}
+	return drop
+}
+
+// systemPrompt 请求实际使用的系统提示词
+func (s *DefaultAIService) systemPrompt(req *ChatRequest) string {
+	if req.SystemPrompt != "" {
+		return req.SystemPrompt
+	}
+	return s.config.SystemPrompt
+}
+
+// summarizeHistory 返回概括前 drop 条以上历史消息的摘要和实际裁掉的消息数。
+// 已保存的摘要覆盖了需要裁掉的消息时直接使用；否则连同剩余消息的一半一起重新概括，
+// 避免之后每次对话都要生成摘要。无法生成摘要时只丢弃消息
+func (s *DefaultAIService) summarizeHistory(ctx context.Context, req *ChatRequest, history []Message, drop int) (string, int, Usage) {
+	if !s.config.EnableSummary || req.ConversationID == "" {
+		return "", drop, Usage{}
+	}
+
+	var previous *ConversationSummary
+	covered := 0
+	if conversation, err := s.store.GetConversation(ctx, req.ConversationID); err == nil && conversation.Summary != nil {
+		for i, message := range history {
+			if message.ID != "" && message.ID == conversation.Summary.LastMessageID {
+				previous, covered = conversation.Summary, i+1
+				break
+			}
+		}
+	}
+	if previous != nil && covered >= drop {
+		return previous.Content, covered, Usage{}
+	}
+
+	target := drop + (len(history)-drop)/2
+	for _, message := range history[covered:target] {
+		if message.ID == "" {
+			// 消息未持久化时无法标记摘要覆盖的范围
+			return "", drop, Usage{}
+		}
+	}
+
+	previousContent := ""
+	messageCount := 0
+	if previous != nil {
+		previousContent = previous.Content
+		messageCount = previous.MessageCount
+	}
+	content, usage, err := s.summarize(ctx, req, previousContent, history[covered:target])
+	if err != nil {
+		log.Printf("Summarize conversation %s failed: %v", req.ConversationID, err)
+		if previous != nil && covered > 0 {
+			// 沿用旧摘要，超出部分直接丢弃
+			return previous.Content, drop, usage
+		}
+		return "", drop, usage
+	}
+
+	summary := &ConversationSummary{
+		Content:       content,
+		LastMessageID: history[target-1].ID,
+		MessageCount:  messageCount + target - covered,
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.store.SaveSummary(ctx, req.ConversationID, summary); err != nil {
+		log.Printf("Save summary of conversation %s failed: %v", req.ConversationID, err)
+	}
+	return content, target, usage
+}
+
+// summarize 调用模型将之前的摘要和后续消息合并为新的摘要
+func (s *DefaultAIService) summarize(ctx context.Context, req *ChatRequest, previous string, messages []Message) (string, Usage, error) {
+	var transcript strings.Builder
+	for _, message := range messages {
+		content := []rune(message.Content)
+		if len(content) > summaryMessageMaxRunes {
+			content = append(content[:summaryMessageMaxRunes], '…')
+		}
+		fmt.Fprintf(&transcript, "%s：%s\n", summaryRoleName(message.Role), string(content))
+	}
+
+	var prompt string
+	if previous != "" {
+		prompt = "之前的对话摘要：\n" + previous + "\n\n后续的对话：\n" + transcript.String() + "\n请输出合并后的完整摘要。"
+	} else {
+		prompt = "请概括以下对话：\n" + transcript.String()
+	}
+
+	resp, err := s.provider.Chat(ctx, &ChatRequest{
+		Model:        req.Model,
+		Messages:     []Message{{Role: "user", Content: prompt}},
+		MaxTokens:    s.config.SummaryMaxTokens,
+		SystemPrompt: summarySystemPrompt,
+		UserID:       req.UserID,
+		Tags:         req.Tags,
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if resp.Error != nil {
+		return "", resp.Usage, resp.Error
+	}
+	content := strings.TrimSpace(resp.Message.Content)
+	if content == "" {
+		return "", resp.Usage, errors.New("empty summary")
+	}
+	return content, resp.Usage, nil
+}
+
+// summaryRoleName 摘要对话记录中的角色名称
+func summaryRoleName(role string) string {
+	switch role {
+	case "user":
+		return "用户"
+	case "assistant":
+		return "助手"
+	case "system":
+		return "系统"
+	default:
+		return role
+	}
+}
+
+// streamWithContext 裁剪上下文后流式对话，生成摘要的用量计入回复的用量
+func (s *DefaultAIService) streamWithContext(ctx context.Context, req *ChatRequest) (<-chan *ChatResponse, error) {
+	req, usage := s.fitContext(ctx, req)
+	chunks, err := s.streamWithTools(ctx, req)
+	if err != nil || usage.TotalTokens == 0 {
+		return chunks, err
+	}
+
+	out := make(chan *ChatResponse)
+	go func() {
+		defer close(out)
+		for chunk := range chunks {
+			if chunk.Usage.TotalTokens > 0 {
+				chunk.Usage = addUsage(usage, chunk.Usage)
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				for range chunks {
+				}
+				return
+			}
+		}
+	}()
+	return out, nil
+}
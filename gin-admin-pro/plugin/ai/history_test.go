@@ -0,0 +1,161 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyRecorder 记录模型收到的对话请求和摘要请求
+type historyRecorder struct {
+	mu        sync.Mutex
+	chats     [][]map[string]interface{}
+	summaries []string
+}
+
+// newHistoryTestService 创建限制历史消息数的服务，摘要请求返回固定摘要
+func newHistoryTestService(t *testing.T, maxHistory int) (*DefaultAIService, *historyRecorder) {
+	recorder := &historyRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		if body.Messages[0]["content"] == summarySystemPrompt {
+			recorder.summaries = append(recorder.summaries, body.Messages[1]["content"].(string))
+			fmt.Fprintf(w, `{"model":"deepseek-chat","choices":[{"message":{"role":"assistant","content":"摘要%d"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, len(recorder.summaries))
+			return
+		}
+		recorder.chats = append(recorder.chats, body.Messages)
+		fmt.Fprint(w, `{"model":"deepseek-chat","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.Provider = "deepseek"
+	config.APIKey = "sk-test"
+	config.BaseURL = server.URL
+	config.Model = ""
+	config.MaxHistoryLength = maxHistory
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	require.NoError(t, service.Initialize(config))
+	return service, recorder
+}
+
+// addTurns 向对话追加 n 轮问答，返回对话的全部消息
+func addTurns(t *testing.T, service *DefaultAIService, conversationID string, n int) []Message {
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		for _, role := range []string{"user", "assistant"} {
+			require.NoError(t, service.AddMessage(ctx, conversationID, &Message{Role: role, Content: fmt.Sprintf("%s %d", role, i)}))
+		}
+	}
+	stored, err := service.GetMessages(ctx, conversationID, 1000, 0)
+	require.NoError(t, err)
+	messages := make([]Message, len(stored))
+	for i, message := range stored {
+		messages[i] = Message{ID: message.ID, Role: message.Role, Content: message.Content}
+	}
+	return messages
+}
+
+func TestChatSummarizesOldHistory(t *testing.T) {
+	service, recorder := newHistoryTestService(t, 4)
+	ctx := context.Background()
+	conversation, err := service.CreateConversation(ctx, "u1", nil)
+	require.NoError(t, err)
+
+	// 10 条历史超出 4 条的限制：概括前 8 条（需裁掉的 6 条加剩余的一半），保留最近 2 条
+	history := addTurns(t, service, conversation.ID, 5)
+	resp, err := service.Chat(ctx, &ChatRequest{
+		ConversationID: conversation.ID,
+		UserID:         "u1",
+		Messages:       append(history, Message{Role: "user", Content: "question"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 22, resp.Usage.TotalTokens, "summary usage is counted")
+
+	require.Len(t, recorder.summaries, 1)
+	assert.Contains(t, recorder.summaries[0], "用户：user 0")
+	assert.Contains(t, recorder.summaries[0], "助手：assistant 3")
+	assert.NotContains(t, recorder.summaries[0], "user 4")
+
+	// 摘要并入系统提示词
+	chat := recorder.chats[0]
+	require.Len(t, chat, 4)
+	assert.Equal(t, "system", chat[0]["role"])
+	assert.Equal(t, "You are a helpful AI assistant.\n\n"+summaryMessagePrefix+"摘要1", chat[0]["content"])
+	assert.Equal(t, "user 4", chat[1]["content"])
+	assert.Equal(t, "question", chat[3]["content"])
+
+	stored, err := service.GetConversation(ctx, conversation.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Summary)
+	assert.Equal(t, "摘要1", stored.Summary.Content)
+	assert.Equal(t, history[7].ID, stored.Summary.LastMessageID)
+	assert.Equal(t, 8, stored.Summary.MessageCount)
+
+	// 已有摘要覆盖需裁掉的消息时直接使用
+	history = addTurns(t, service, conversation.ID, 1)
+	_, err = service.Chat(ctx, &ChatRequest{ConversationID: conversation.ID, Messages: append(history, Message{Role: "user", Content: "again"})})
+	require.NoError(t, err)
+	assert.Len(t, recorder.summaries, 1)
+	assert.Len(t, recorder.chats[1], 6)
+
+	// 超出摘要覆盖的范围后连同旧摘要重新概括
+	history = addTurns(t, service, conversation.ID, 1)
+	_, err = service.Chat(ctx, &ChatRequest{ConversationID: conversation.ID, Messages: append(history, Message{Role: "user", Content: "more"})})
+	require.NoError(t, err)
+	require.Len(t, recorder.summaries, 2)
+	assert.Contains(t, recorder.summaries[1], "之前的对话摘要：\n摘要1")
+	assert.Contains(t, recorder.chats[2][0]["content"], summaryMessagePrefix+"摘要2")
+
+	stored, err = service.GetConversation(ctx, conversation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 12, stored.Summary.MessageCount)
+}
+
+func TestChatDropsHistoryWithoutConversation(t *testing.T) {
+	service, recorder := newHistoryTestService(t, 2)
+
+	messages := []Message{{Role: "system", Content: "persona"}}
+	for i := 0; i < 3; i++ {
+		messages = append(messages, Message{Role: "user", Content: fmt.Sprintf("q%d", i)}, Message{Role: "assistant", Content: fmt.Sprintf("a%d", i)})
+	}
+	messages = append(messages, Message{Role: "system", Content: "references"}, Message{Role: "user", Content: "last"})
+
+	_, err := service.Chat(context.Background(), &ChatRequest{Messages: messages})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.summaries)
+
+	// 开头的系统消息和最后一条消息前的系统消息始终保留
+	var contents []string
+	for _, message := range recorder.chats[0] {
+		contents = append(contents, message["content"].(string))
+	}
+	assert.Equal(t, []string{"persona", "q2", "a2", "references", "last"}, contents)
+}
+
+func TestHistoryOverflowContextWindow(t *testing.T) {
+	service, _ := newHistoryTestService(t, 0)
+
+	// deepseek-chat 上下文 131072 tokens，每条约 40000 tokens 时只能保留最近 2 条
+	long := strings.Repeat("字", 40000)
+	history := []Message{{Content: long}, {Content: long}, {Content: long}, {Content: long}}
+	assert.Equal(t, 2, service.historyOverflow(&ChatRequest{}, history, nil, []Message{{Content: "last"}}))
+
+	// 回复预留的 token 也计入
+	assert.Equal(t, 3, service.historyOverflow(&ChatRequest{MaxTokens: 40000}, history, nil, nil))
+}
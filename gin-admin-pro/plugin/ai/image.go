@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// imageTokenEstimate 每张图片估算的 token 数，按 OpenAI 高精度模式下 1024x1024 的图片计算，用于上下文裁剪
+const imageTokenEstimate = 765
+
+// messageImages 返回消息的全部图片，ImageURL 排在最前
+func messageImages(msg Message) []Image {
+	if msg.ImageURL == "" {
+		return msg.Images
+	}
+	return append([]Image{{URL: msg.ImageURL}}, msg.Images...)
+}
+
+// hasImages 消息中是否包含图片
+func hasImages(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.ImageURL != "" || len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// inline 返回图片的 MIME 类型和 base64 数据，URL 为 data URL 时从中解析，只有普通 URL 时返回 false
+func (img Image) inline() (string, string, bool) {
+	if len(img.Data) > 0 {
+		mimeType := img.MimeType
+		if mimeType == "" {
+			mimeType = http.DetectContentType(img.Data)
+		}
+		return mimeType, base64.StdEncoding.EncodeToString(img.Data), true
+	}
+
+	rest, ok := strings.CutPrefix(img.URL, "data:")
+	if !ok {
+		return "", "", false
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", "", false
+	}
+	mimeType, ok := strings.CutSuffix(meta, ";base64")
+	if !ok {
+		return "", "", false
+	}
+	return mimeType, data, true
+}
+
+// dataURL 返回图片地址，内联数据转换为 data URL
+func (img Image) dataURL() string {
+	mimeType, data, ok := img.inline()
+	if !ok || len(img.Data) == 0 {
+		return img.URL
+	}
+	return "data:" + mimeType + ";base64," + data
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader PNG 文件头，用于识别图片类型
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func TestImageInline(t *testing.T) {
+	mimeType, data, ok := Image{Data: pngHeader}.inline()
+	require.True(t, ok)
+	assert.Equal(t, "image/png", mimeType)
+	assert.Equal(t, "iVBORw0KGgo=", data)
+	assert.Equal(t, "data:image/png;base64,iVBORw0KGgo=", Image{Data: pngHeader}.dataURL())
+
+	mimeType, data, ok = Image{URL: "data:image/jpeg;base64,/9j/4A=="}.inline()
+	require.True(t, ok)
+	assert.Equal(t, "image/jpeg", mimeType)
+	assert.Equal(t, "/9j/4A==", data)
+
+	_, _, ok = Image{URL: "https://example.com/a.png"}.inline()
+	assert.False(t, ok)
+	assert.Equal(t, "https://example.com/a.png", Image{URL: "https://example.com/a.png"}.dataURL())
+}
+
+func TestProviderImageMessages(t *testing.T) {
+	msg := Message{
+		Role:     "user",
+		Content:  "这个报错是什么意思",
+		ImageURL: "https://example.com/a.png",
+		Images:   []Image{{Data: pngHeader, MimeType: "image/png"}},
+	}
+
+	// OpenAI 兼容接口：文本和图片片段，内联图片为 data URL
+	compatible := NewDeepSeekProvider().(*CompatibleProvider)
+	data, err := json.Marshal(compatible.convertMessages([]Message{msg})[0]["content"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type":"text","text":"这个报错是什么意思"},
+		{"type":"image_url","image_url":{"url":"https://example.com/a.png"}},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}
+	]`, string(data))
+
+	// Claude：URL 和 base64 来源的图片块在文本之前
+	_, blocks := (&ClaudeProvider{}).convertMessage(msg)
+	require.Len(t, blocks, 3)
+	assert.Equal(t, &claudeImageSource{Type: "url", URL: "https://example.com/a.png"}, blocks[0].Source)
+	assert.Equal(t, &claudeImageSource{Type: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="}, blocks[1].Source)
+	assert.Equal(t, "text", blocks[2].Type)
+
+	// Ollama：只发送 base64 图片
+	assert.Equal(t, []string{"iVBORw0KGgo="}, convertOllamaMessage(msg).Images)
+}
+
+func TestImageInputDisabled(t *testing.T) {
+	service, _ := newHistoryTestService(t, 0)
+	request := &ChatRequest{Messages: []Message{{Role: "user", Content: "看图", Images: []Image{{Data: pngHeader}}}}}
+
+	_, err := service.Chat(context.Background(), request)
+	assert.ErrorIs(t, err, ErrImageInputDisabled)
+	_, err = service.ChatStream(context.Background(), request)
+	assert.ErrorIs(t, err, ErrImageInputDisabled)
+
+	service.config.EnableImageInput = true
+	resp, err := service.Chat(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Message.Content)
+}
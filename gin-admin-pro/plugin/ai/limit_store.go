@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gin-admin-pro/plugin/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// LimitStore 限流和配额计数存储，多实例部署时应使用 Redis 等共享存储
+type LimitStore interface {
+	// IncrBy 累加计数并返回累加后的值，键首次创建时设置过期时间
+	IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// Get 获取计数，键不存在时返回 0
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// MemoryLimitStore 内存计数存储，仅在单实例内生效
+type MemoryLimitStore struct {
+	mu      sync.Mutex
+	entries map[string]*limitEntry
+}
+
+type limitEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryLimitStore 创建内存计数存储
+func NewMemoryLimitStore() *MemoryLimitStore {
+	return &MemoryLimitStore{
+		entries: make(map[string]*limitEntry),
+	}
+}
+
+// IncrBy 累加计数
+func (s *MemoryLimitStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, exists := s.entries[key]
+	if !exists || now.After(entry.expiresAt) {
+		s.cleanup(now)
+		entry = &limitEntry{expiresAt: now.Add(ttl)}
+		s.entries[key] = entry
+	}
+	entry.value += delta
+	return entry.value, nil
+}
+
+// Get 获取计数
+func (s *MemoryLimitStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return 0, nil
+	}
+	return entry.value, nil
+}
+
+// cleanup 清理过期计数，调用方需持有锁
+func (s *MemoryLimitStore) cleanup(now time.Time) {
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// RedisLimitStore 基于 Redis 的计数存储，多实例共享限流和配额
+type RedisLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisLimitStore 创建 Redis 计数存储
+func NewRedisLimitStore(client *redis.Client) *RedisLimitStore {
+	return &RedisLimitStore{client: client}
+}
+
+// IncrBy 累加计数，键首次创建时设置过期时间
+func (s *RedisLimitStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	value, err := s.client.GetClient().IncrBy(ctx, key, delta).Result()
+	if err != nil {
+		return 0, err
+	}
+	if value == delta {
+		if err := s.client.Expire(ctx, key, ttl); err != nil {
+			return value, err
+		}
+	}
+	return value, nil
+}
+
+// Get 获取计数
+func (s *RedisLimitStore) Get(ctx context.Context, key string) (int64, error) {
+	value, err := s.client.Get(ctx, key)
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,762 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// chainChat 按顺序包裹中间件，第一个中间件位于最外层
+func chainChat(middlewares []Middleware, handler ChatHandler) ChatHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+			return middleware.Chat(ctx, request, next)
+		}
+	}
+	return handler
+}
+
+// chainStream 按顺序包裹流式中间件，第一个中间件位于最外层
+func chainStream(middlewares []Middleware, handler StreamHandler) StreamHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+			return middleware.ChatStream(ctx, request, next)
+		}
+	}
+	return handler
+}
+
+// relayStream 转发流式响应：handle 处理每个分片并返回需要下发的分片，
+// 上游关闭后调用 finish 下发剩余分片；ctx 取消后停止下发并排空上游，避免阻塞提供商协程
+func relayStream(ctx context.Context, in <-chan *ChatResponse, handle func(*ChatResponse) []*ChatResponse, finish func() []*ChatResponse) <-chan *ChatResponse {
+	out := make(chan *ChatResponse)
+	go func() {
+		defer close(out)
+
+		send := func(chunks []*ChatResponse) bool {
+			for _, chunk := range chunks {
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for chunk := range in {
+			if !send(handle(chunk)) {
+				go func() {
+					for range in {
+					}
+				}()
+				break
+			}
+		}
+		if finish != nil {
+			send(finish())
+		}
+	}()
+	return out
+}
+
+// lastUserContent 获取请求中最后一条用户消息
+func lastUserContent(request *ChatRequest) string {
+	for i := len(request.Messages) - 1; i >= 0; i-- {
+		if request.Messages[i].Role == "user" {
+			return request.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// ===== 限流 =====
+
+// RateLimitMiddleware 按用户固定窗口限流，未携带 UserID 的内部调用不受限制
+type RateLimitMiddleware struct {
+	store    LimitStore
+	requests int64
+	window   time.Duration
+}
+
+// NewRateLimitMiddleware 创建限流中间件，每个用户在 window 内最多 requests 次请求
+func NewRateLimitMiddleware(store LimitStore, requests int, window time.Duration) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		store:    store,
+		requests: int64(requests),
+		window:   window,
+	}
+}
+
+// Chat 限流检查
+func (m *RateLimitMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	if err := m.allow(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+	return next(ctx, request)
+}
+
+// ChatStream 限流检查
+func (m *RateLimitMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	if err := m.allow(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+	return next(ctx, request)
+}
+
+// allow 计数并判断是否超限，计数存储故障时放行
+func (m *RateLimitMiddleware) allow(ctx context.Context, userID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	windowStart := time.Now().Truncate(m.window).Unix()
+	key := fmt.Sprintf("ai:ratelimit:%s:%d", userID, windowStart)
+	count, err := m.store.IncrBy(ctx, key, 1, m.window)
+	if err != nil {
+		log.Printf("AI rate limit counter failed: %v", err)
+		return nil
+	}
+	if count > m.requests {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// ===== 配额 =====
+
+// quotaTTL 每日用量计数保留时间，跨越时区边界时仍可读取当天数据
+const quotaTTL = 48 * time.Hour
+
+// QuotaMiddleware 按用户每日 token 和费用配额限制，请求前检查已用量，完成后累加本次用量
+type QuotaMiddleware struct {
+	store      LimitStore
+	tokenLimit int64
+	costLimit  float64
+	modelInfo  func(model string) (*ModelInfo, error)
+}
+
+// NewQuotaMiddleware 创建配额中间件，tokenLimit 或 costLimit 为 0 时不限制对应项；
+// modelInfo 用于在提供商未返回费用时按模型单价计算
+func NewQuotaMiddleware(store LimitStore, tokenLimit int, costLimit float64, modelInfo func(model string) (*ModelInfo, error)) *QuotaMiddleware {
+	return &QuotaMiddleware{
+		store:      store,
+		tokenLimit: int64(tokenLimit),
+		costLimit:  costLimit,
+		modelInfo:  modelInfo,
+	}
+}
+
+// Chat 检查配额并记录用量
+func (m *QuotaMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	if err := m.check(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	resp, err := next(ctx, request)
+	if err == nil && resp != nil && resp.Error == nil && !isCachedResponse(resp) {
+		model := resp.Model
+		if model == "" {
+			model = request.Model
+		}
+		m.record(ctx, request, model, resp.Message.Content, resp.Usage)
+	}
+	return resp, err
+}
+
+// ChatStream 检查配额，流结束后按累计内容记录用量，客户端中途断开也计入
+func (m *QuotaMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	if err := m.check(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	chunks, err := next(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		content strings.Builder
+		usage   Usage
+		model   = request.Model
+	)
+	return relayStream(ctx, chunks, func(chunk *ChatResponse) []*ChatResponse {
+		content.WriteString(chunk.Delta)
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+		// 多提供商路由时以实际响应的模型计费
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		return []*ChatResponse{chunk}
+	}, func() []*ChatResponse {
+		if content.Len() > 0 || usage.TotalTokens > 0 {
+			m.record(context.WithoutCancel(ctx), request, model, content.String(), usage)
+		}
+		return nil
+	}), nil
+}
+
+// check 判断用户当天用量是否已达上限，计数存储故障时放行
+func (m *QuotaMiddleware) check(ctx context.Context, userID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	tokenKey, costKey := quotaKeys(userID)
+	if m.tokenLimit > 0 {
+		used, err := m.store.Get(ctx, tokenKey)
+		if err != nil {
+			log.Printf("AI token quota counter failed: %v", err)
+		} else if used >= m.tokenLimit {
+			return ErrTokenQuotaExceeded
+		}
+	}
+	if m.costLimit > 0 {
+		used, err := m.store.Get(ctx, costKey)
+		if err != nil {
+			log.Printf("AI cost quota counter failed: %v", err)
+		} else if float64(used)/1e6 >= m.costLimit {
+			return ErrCostQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// record 累加本次用量，费用以百万分之一美元为单位计数
+func (m *QuotaMiddleware) record(ctx context.Context, request *ChatRequest, model, completion string, usage Usage) {
+	if request.UserID == "" {
+		return
+	}
+
+	usage = completeUsage(request, model, completion, usage, m.modelInfo)
+	tokenKey, costKey := quotaKeys(request.UserID)
+	if _, err := m.store.IncrBy(ctx, tokenKey, int64(usage.TotalTokens), quotaTTL); err != nil {
+		log.Printf("AI token quota record failed: %v", err)
+	}
+	if microCost := int64(math.Ceil(usage.Cost * 1e6)); microCost > 0 {
+		if _, err := m.store.IncrBy(ctx, costKey, microCost, quotaTTL); err != nil {
+			log.Printf("AI cost quota record failed: %v", err)
+		}
+	}
+}
+
+// completeUsage 补全用量：提供商未返回 token 数时按内容估算，未返回费用时按模型单价计算
+func completeUsage(request *ChatRequest, model, completion string, usage Usage, modelInfo func(model string) (*ModelInfo, error)) Usage {
+	if usage.TotalTokens == 0 {
+		usage.PromptTokens = estimateMessagesTokens(request.Messages) + EstimateTokens(request.SystemPrompt)
+		usage.CompletionTokens = EstimateTokens(completion)
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if usage.Cost == 0 && modelInfo != nil {
+		if info, err := modelInfo(model); err == nil {
+			usage.Cost = float64(usage.PromptTokens)/1000*info.InputCost + float64(usage.CompletionTokens)/1000*info.OutputCost
+		}
+	}
+	return usage
+}
+
+// isCachedResponse 回复是否来自响应缓存，缓存命中不调用提供商，不计用量
+func isCachedResponse(resp *ChatResponse) bool {
+	_, cached := resp.Metadata["cache"]
+	return cached
+}
+
+// quotaKeys 用户当天的 token 和费用计数键
+func quotaKeys(userID string) (string, string) {
+	day := time.Now().Format("20060102")
+	return fmt.Sprintf("ai:quota:tokens:%s:%s", userID, day), fmt.Sprintf("ai:quota:cost:%s:%s", userID, day)
+}
+
+// ===== 审计 =====
+
+// AuditRecord 审计记录
+type AuditRecord struct {
+	UserID         string        `json:"userId"`
+	ConversationID string        `json:"conversationId,omitempty"`
+	Model          string        `json:"model,omitempty"`
+	Stream         bool          `json:"stream"`
+	Prompt         string        `json:"prompt"`
+	Response       string        `json:"response"`
+	Usage          Usage         `json:"usage"`
+	Duration       time.Duration `json:"duration"`
+	Error          string        `json:"error,omitempty"`
+	Timestamp      time.Time     `json:"timestamp"`
+}
+
+// AuditLogger 审计日志记录器
+type AuditLogger interface {
+	Log(ctx context.Context, record *AuditRecord)
+}
+
+// logAuditLogger 以 JSON 格式写入标准日志
+type logAuditLogger struct{}
+
+// Log 写入审计记录
+func (logAuditLogger) Log(ctx context.Context, record *AuditRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("AI audit marshal failed: %v", err)
+		return
+	}
+	log.Printf("AI audit: %s", data)
+}
+
+// AuditMiddleware 审计中间件，记录每次请求的提问、回复、用量和错误，被其他中间件拦截的请求同样记录
+type AuditMiddleware struct {
+	logger    AuditLogger
+	maxLength int
+}
+
+// NewAuditMiddleware 创建审计中间件，maxLength 为提问和回复保留的最大字符数，0 表示不截断
+func NewAuditMiddleware(logger AuditLogger, maxLength int) *AuditMiddleware {
+	return &AuditMiddleware{
+		logger:    logger,
+		maxLength: maxLength,
+	}
+}
+
+// Chat 记录审计日志
+func (m *AuditMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	record := m.newRecord(request)
+	resp, err := next(ctx, request)
+
+	if resp != nil {
+		record.Response = m.truncate(resp.Message.Content)
+		record.Usage = resp.Usage
+		if resp.Model != "" {
+			record.Model = resp.Model
+		}
+		if resp.Error != nil {
+			record.Error = resp.Error.Error()
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	m.log(ctx, record)
+	return resp, err
+}
+
+// ChatStream 流结束后记录审计日志
+func (m *AuditMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	record := m.newRecord(request)
+	chunks, err := next(ctx, request)
+	if err != nil {
+		record.Error = err.Error()
+		m.log(ctx, record)
+		return nil, err
+	}
+
+	var content strings.Builder
+	return relayStream(ctx, chunks, func(chunk *ChatResponse) []*ChatResponse {
+		content.WriteString(chunk.Delta)
+		if chunk.Usage.TotalTokens > 0 {
+			record.Usage = chunk.Usage
+		}
+		if chunk.Error != nil {
+			record.Error = chunk.Error.Error()
+		}
+		return []*ChatResponse{chunk}
+	}, func() []*ChatResponse {
+		record.Response = m.truncate(content.String())
+		if record.Error == "" && ctx.Err() != nil {
+			record.Error = ctx.Err().Error()
+		}
+		m.log(context.WithoutCancel(ctx), record)
+		return nil
+	}), nil
+}
+
+// newRecord 根据请求创建审计记录
+func (m *AuditMiddleware) newRecord(request *ChatRequest) *AuditRecord {
+	return &AuditRecord{
+		UserID:         request.UserID,
+		ConversationID: request.ConversationID,
+		Model:          request.Model,
+		Stream:         request.Stream,
+		Prompt:         m.truncate(lastUserContent(request)),
+		Timestamp:      time.Now(),
+	}
+}
+
+// log 补充耗时并写入审计记录
+func (m *AuditMiddleware) log(ctx context.Context, record *AuditRecord) {
+	record.Duration = time.Since(record.Timestamp)
+	m.logger.Log(ctx, record)
+}
+
+// truncate 按字符截断内容
+func (m *AuditMiddleware) truncate(content string) string {
+	if m.maxLength <= 0 {
+		return content
+	}
+	runes := []rune(content)
+	if len(runes) <= m.maxLength {
+		return content
+	}
+	return string(runes[:m.maxLength]) + "..."
+}
+
+// ===== 用量计量 =====
+
+// UsageRecord 一次模型调用的用量，费用单位为美元
+type UsageRecord struct {
+	UserID           string        `json:"userId"`
+	ConversationID   string        `json:"conversationId,omitempty"`
+	Provider         string        `json:"provider"`
+	Model            string        `json:"model"`
+	Stream           bool          `json:"stream"`
+	Cached           bool          `json:"cached"`
+	PromptTokens     int           `json:"promptTokens"`
+	CompletionTokens int           `json:"completionTokens"`
+	TotalTokens      int           `json:"totalTokens"`
+	Cost             float64       `json:"cost"`
+	Latency          time.Duration `json:"latency"`
+	Error            string        `json:"error,omitempty"`
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// UsageRecorder 用量记录器，用于持久化用量和按部门分摊费用
+type UsageRecorder interface {
+	Record(ctx context.Context, record *UsageRecord)
+}
+
+// UsageMiddleware 用量计量中间件，记录每次调用的提供商、模型、token、费用和耗时，失败的调用同样记录
+type UsageMiddleware struct {
+	recorder  UsageRecorder
+	provider  string
+	model     string
+	modelInfo func(model string) (*ModelInfo, error)
+}
+
+// NewUsageMiddleware 创建用量计量中间件，provider、model 为回复未标明提供商和模型时使用的名称；
+// modelInfo 用于在提供商未返回费用时按模型单价计算
+func NewUsageMiddleware(recorder UsageRecorder, provider, model string, modelInfo func(model string) (*ModelInfo, error)) *UsageMiddleware {
+	return &UsageMiddleware{
+		recorder:  recorder,
+		provider:  provider,
+		model:     model,
+		modelInfo: modelInfo,
+	}
+}
+
+// Chat 记录用量
+func (m *UsageMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	record := m.newRecord(request)
+	resp, err := next(ctx, request)
+
+	var completion string
+	if resp != nil {
+		completion = resp.Message.Content
+		m.applyResponse(record, resp)
+		record.Cached = isCachedResponse(resp)
+		if resp.Error != nil {
+			record.Error = resp.Error.Error()
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	m.finish(ctx, request, record, completion)
+	return resp, err
+}
+
+// ChatStream 流结束后记录用量，客户端中途断开也计入
+func (m *UsageMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	record := m.newRecord(request)
+	chunks, err := next(ctx, request)
+	if err != nil {
+		record.Error = err.Error()
+		m.finish(ctx, request, record, "")
+		return nil, err
+	}
+
+	var content strings.Builder
+	return relayStream(ctx, chunks, func(chunk *ChatResponse) []*ChatResponse {
+		content.WriteString(chunk.Delta)
+		m.applyResponse(record, chunk)
+		if chunk.Error != nil {
+			record.Error = chunk.Error.Error()
+		}
+		return []*ChatResponse{chunk}
+	}, func() []*ChatResponse {
+		if record.Error == "" && ctx.Err() != nil {
+			record.Error = ctx.Err().Error()
+		}
+		m.finish(context.WithoutCancel(ctx), request, record, content.String())
+		return nil
+	}), nil
+}
+
+// newRecord 根据请求创建用量记录
+func (m *UsageMiddleware) newRecord(request *ChatRequest) *UsageRecord {
+	return &UsageRecord{
+		UserID:         request.UserID,
+		ConversationID: request.ConversationID,
+		Provider:       m.provider,
+		Model:          request.Model,
+		Stream:         request.Stream,
+		Timestamp:      time.Now(),
+	}
+}
+
+// applyResponse 记录回复中的模型、提供商和用量，多提供商路由时以实际响应的提供商和模型计费
+func (m *UsageMiddleware) applyResponse(record *UsageRecord, resp *ChatResponse) {
+	if resp.Model != "" {
+		record.Model = resp.Model
+	}
+	if provider, ok := resp.Metadata["provider"].(string); ok && provider != "" {
+		record.Provider = provider
+	}
+	if resp.Usage.TotalTokens > 0 {
+		record.PromptTokens = resp.Usage.PromptTokens
+		record.CompletionTokens = resp.Usage.CompletionTokens
+		record.TotalTokens = resp.Usage.TotalTokens
+		record.Cost = resp.Usage.Cost
+	}
+}
+
+// finish 补全用量和耗时并写入记录；缓存命中和失败且无输出的调用不估算用量
+func (m *UsageMiddleware) finish(ctx context.Context, request *ChatRequest, record *UsageRecord, completion string) {
+	record.Latency = time.Since(record.Timestamp)
+	if record.Model == "" {
+		record.Model = m.model
+	}
+	if !record.Cached && (record.Error == "" || completion != "" || record.TotalTokens > 0) {
+		usage := completeUsage(request, record.Model, completion, Usage{
+			PromptTokens:     record.PromptTokens,
+			CompletionTokens: record.CompletionTokens,
+			TotalTokens:      record.TotalTokens,
+			Cost:             record.Cost,
+		}, m.modelInfo)
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+		record.TotalTokens = usage.TotalTokens
+		record.Cost = usage.Cost
+	}
+	m.recorder.Record(ctx, record)
+}
+
+// ===== 内容过滤 =====
+
+// ContentFilterMiddleware 关键词内容过滤，任一用户消息命中时拒绝请求，回复中的关键词替换后返回
+type ContentFilterMiddleware struct {
+	filter *keywordFilter
+}
+
+// NewContentFilterMiddleware 创建内容过滤中间件，关键词忽略大小写
+func NewContentFilterMiddleware(keywords []string, replacement string) *ContentFilterMiddleware {
+	return &ContentFilterMiddleware{
+		filter: newKeywordFilter(keywords, replacement),
+	}
+}
+
+// Chat 过滤提问和回复
+func (m *ContentFilterMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	if m.blocked(request) {
+		return nil, ErrContentBlocked
+	}
+
+	resp, err := next(ctx, request)
+	if err == nil && resp != nil {
+		resp.Message.Content = m.filter.mask(resp.Message.Content)
+	}
+	return resp, err
+}
+
+// ChatStream 过滤提问和流式回复，跨分片的关键词同样会被替换
+func (m *ContentFilterMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	if m.blocked(request) {
+		return nil, ErrContentBlocked
+	}
+
+	chunks, err := next(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	masker := &streamMasker{filter: m.filter}
+	flush := func() []*ChatResponse {
+		if rest := masker.flush(); rest != "" {
+			return []*ChatResponse{{
+				Message:   Message{Role: "assistant", Content: rest, Streaming: true},
+				Stream:    true,
+				Delta:     rest,
+				Timestamp: time.Now(),
+			}}
+		}
+		return nil
+	}
+	return relayStream(ctx, chunks, func(chunk *ChatResponse) []*ChatResponse {
+		if chunk.Done || chunk.Error != nil {
+			return append(flush(), chunk)
+		}
+		if chunk.Delta == "" {
+			return []*ChatResponse{chunk}
+		}
+
+		masked := *chunk
+		masked.Delta = masker.write(chunk.Delta)
+		masked.Message.Content = masked.Delta
+		return []*ChatResponse{&masked}
+	}, flush), nil
+}
+
+// blocked 是否有用户消息命中关键词。历史消息也要检查，否则被拒绝或修改关键词前保存的提问会随历史发送给模型
+func (m *ContentFilterMiddleware) blocked(request *ChatRequest) bool {
+	for _, message := range request.Messages {
+		if message.Role == "user" && m.filter.contains(message.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+// keywordFilter 关键词匹配器，同一位置优先匹配最长关键词
+type keywordFilter struct {
+	keywords    [][]rune
+	maxLength   int
+	replacement string
+}
+
+// newKeywordFilter 创建关键词匹配器
+func newKeywordFilter(keywords []string, replacement string) *keywordFilter {
+	filter := &keywordFilter{replacement: replacement}
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		runes := lowerRunes([]rune(keyword))
+		filter.keywords = append(filter.keywords, runes)
+		if len(runes) > filter.maxLength {
+			filter.maxLength = len(runes)
+		}
+	}
+	sort.SliceStable(filter.keywords, func(i, j int) bool {
+		return len(filter.keywords[i]) > len(filter.keywords[j])
+	})
+	return filter
+}
+
+// find 查找不重叠的关键词位置
+func (f *keywordFilter) find(text []rune) [][2]int {
+	var matches [][2]int
+	lower := lowerRunes(text)
+	for i := 0; i < len(lower); {
+		matched := false
+		for _, keyword := range f.keywords {
+			if hasRunePrefix(lower[i:], keyword) {
+				matches = append(matches, [2]int{i, i + len(keyword)})
+				i += len(keyword)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return matches
+}
+
+// contains 判断文本是否包含关键词
+func (f *keywordFilter) contains(text string) bool {
+	return len(f.keywords) > 0 && len(f.find([]rune(text))) > 0
+}
+
+// mask 替换文本中的关键词
+func (f *keywordFilter) mask(text string) string {
+	if len(f.keywords) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	return f.replace(runes, f.find(runes))
+}
+
+// replace 按匹配位置替换关键词
+func (f *keywordFilter) replace(text []rune, matches [][2]int) string {
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		builder.WriteString(string(text[last:match[0]]))
+		builder.WriteString(f.replacement)
+		last = match[1]
+	}
+	builder.WriteString(string(text[last:]))
+	return builder.String()
+}
+
+// streamMasker 流式关键词替换，保留末尾可能构成关键词前缀的字符等待后续分片
+type streamMasker struct {
+	filter  *keywordFilter
+	pending []rune
+}
+
+// write 写入增量内容，返回可以安全下发的部分
+func (m *streamMasker) write(delta string) string {
+	m.pending = append(m.pending, []rune(delta)...)
+	if len(m.filter.keywords) == 0 {
+		return m.flush()
+	}
+
+	cut := len(m.pending) - (m.filter.maxLength - 1)
+	if cut <= 0 {
+		return ""
+	}
+
+	var matches [][2]int
+	for _, match := range m.filter.find(m.pending) {
+		if match[0] >= cut {
+			break
+		}
+		// 跨越截断点的关键词已完整出现，随本次一起下发
+		if match[1] > cut {
+			cut = match[1]
+		}
+		matches = append(matches, match)
+	}
+
+	out := m.filter.replace(m.pending[:cut], matches)
+	m.pending = append([]rune(nil), m.pending[cut:]...)
+	return out
+}
+
+// flush 下发全部剩余内容
+func (m *streamMasker) flush() string {
+	out := m.filter.replace(m.pending, m.filter.find(m.pending))
+	m.pending = nil
+	return out
+}
+
+// lowerRunes 逐字符转小写，保持字符位置不变
+func lowerRunes(text []rune) []rune {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+// hasRunePrefix 判断 text 是否以 prefix 开头
+func hasRunePrefix(text, prefix []rune) bool {
+	if len(text) < len(prefix) {
+		return false
+	}
+	for i, r := range prefix {
+		if text[i] != r {
+			return false
+		}
+	}
+	return true
+}
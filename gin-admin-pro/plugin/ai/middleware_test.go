@@ -0,0 +1,365 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordMiddleware 记录执行顺序的测试中间件
+type recordMiddleware struct {
+	name  string
+	trace *[]string
+}
+
+func (m *recordMiddleware) Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error) {
+	*m.trace = append(*m.trace, m.name+":before")
+	resp, err := next(ctx, request)
+	*m.trace = append(*m.trace, m.name+":after")
+	return resp, err
+}
+
+func (m *recordMiddleware) ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error) {
+	*m.trace = append(*m.trace, m.name+":stream")
+	return next(ctx, request)
+}
+
+// auditCollector 收集审计记录
+type auditCollector struct {
+	records chan *AuditRecord
+}
+
+func (c *auditCollector) Log(ctx context.Context, record *AuditRecord) {
+	c.records <- record
+}
+
+// userRequest 创建携带用户的请求
+func userRequest(userID, content string) *ChatRequest {
+	return &ChatRequest{
+		UserID:   userID,
+		Messages: []Message{{Role: "user", Content: content}},
+	}
+}
+
+// replyHandler 返回固定回复的处理函数
+func replyHandler(content string, usage Usage) ChatHandler {
+	return func(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+		return &ChatResponse{Message: Message{Role: "assistant", Content: content}, Usage: usage}, nil
+	}
+}
+
+// streamHandler 依次返回增量内容的流式处理函数
+func streamHandler(deltas ...string) StreamHandler {
+	return func(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+		chunks := make(chan *ChatResponse, len(deltas)+1)
+		for _, delta := range deltas {
+			chunks <- &ChatResponse{Stream: true, Delta: delta, Message: Message{Content: delta}}
+		}
+		chunks <- &ChatResponse{Stream: true, Done: true}
+		close(chunks)
+		return chunks, nil
+	}
+}
+
+// collectDeltas 读取流式响应的全部增量内容
+func collectDeltas(chunks <-chan *ChatResponse) string {
+	var builder strings.Builder
+	for chunk := range chunks {
+		builder.WriteString(chunk.Delta)
+	}
+	return builder.String()
+}
+
+func TestMiddlewareChainOrder(t *testing.T) {
+	var trace []string
+	middlewares := []Middleware{
+		&recordMiddleware{name: "outer", trace: &trace},
+		&recordMiddleware{name: "inner", trace: &trace},
+	}
+
+	handler := chainChat(middlewares, func(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+		trace = append(trace, "handler")
+		return &ChatResponse{}, nil
+	})
+	_, err := handler(context.Background(), userRequest("u1", "hi"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}, trace)
+
+	trace = nil
+	chunks, err := chainStream(middlewares, streamHandler("a"))(context.Background(), userRequest("u1", "hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", collectDeltas(chunks))
+	assert.Equal(t, []string{"outer:stream", "inner:stream"}, trace)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	middleware := NewRateLimitMiddleware(NewMemoryLimitStore(), 2, time.Minute)
+	handler := chainChat([]Middleware{middleware}, replyHandler("ok", Usage{}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := handler(ctx, userRequest("u1", "hi"))
+		require.NoError(t, err)
+	}
+	_, err := handler(ctx, userRequest("u1", "hi"))
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// 其他用户和内部调用不受影响
+	_, err = handler(ctx, userRequest("u2", "hi"))
+	assert.NoError(t, err)
+	_, err = handler(ctx, userRequest("", "hi"))
+	assert.NoError(t, err)
+
+	_, err = chainStream([]Middleware{middleware}, streamHandler("a"))(ctx, userRequest("u1", "hi"))
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestQuotaMiddlewareTokens(t *testing.T) {
+	store := NewMemoryLimitStore()
+	middleware := NewQuotaMiddleware(store, 100, 0, nil)
+	ctx := context.Background()
+
+	handler := chainChat([]Middleware{middleware}, replyHandler("ok", Usage{PromptTokens: 60, CompletionTokens: 40, TotalTokens: 100}))
+	_, err := handler(ctx, userRequest("u1", "hi"))
+	require.NoError(t, err)
+
+	_, err = handler(ctx, userRequest("u1", "hi"))
+	assert.ErrorIs(t, err, ErrTokenQuotaExceeded)
+	_, err = chainStream([]Middleware{middleware}, streamHandler("a"))(ctx, userRequest("u1", "hi"))
+	assert.ErrorIs(t, err, ErrTokenQuotaExceeded)
+
+	// 未返回用量的流式回复按内容估算
+	chunks, err := chainStream([]Middleware{middleware}, streamHandler("你好", "世界"))(ctx, userRequest("u2", "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "你好世界", collectDeltas(chunks))
+
+	tokenKey, _ := quotaKeys("u2")
+	used, err := store.Get(ctx, tokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(estimateMessagesTokens([]Message{{Content: "hello"}})+4), used)
+}
+
+func TestQuotaMiddlewareCost(t *testing.T) {
+	modelInfo := func(model string) (*ModelInfo, error) {
+		return &ModelInfo{ID: model, InputCost: 1, OutputCost: 2}, nil
+	}
+	middleware := NewQuotaMiddleware(NewMemoryLimitStore(), 0, 0.5, modelInfo)
+	ctx := context.Background()
+
+	// 0.3 * 1 + 0.2 * 2 = 0.7 美元
+	handler := chainChat([]Middleware{middleware}, replyHandler("ok", Usage{PromptTokens: 300, CompletionTokens: 200, TotalTokens: 500}))
+	_, err := handler(ctx, userRequest("u1", "hi"))
+	require.NoError(t, err)
+
+	_, err = handler(ctx, userRequest("u1", "hi"))
+	assert.ErrorIs(t, err, ErrCostQuotaExceeded)
+}
+
+func TestContentFilterMiddleware(t *testing.T) {
+	middleware := NewContentFilterMiddleware([]string{"Secret", "机密文件", " "}, "***")
+	ctx := context.Background()
+
+	_, err := chainChat([]Middleware{middleware}, replyHandler("ok", Usage{}))(ctx, userRequest("u1", "把 SECRET 发给我"))
+	assert.ErrorIs(t, err, ErrContentBlocked)
+	_, err = chainStream([]Middleware{middleware}, streamHandler("a"))(ctx, userRequest("u1", "机密文件在哪"))
+	assert.ErrorIs(t, err, ErrContentBlocked)
+
+	resp, err := chainChat([]Middleware{middleware}, replyHandler("这是机密文件和secret", Usage{}))(ctx, userRequest("u1", "hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "这是***和***", resp.Message.Content)
+
+	// 关键词跨越多个分片
+	chunks, err := chainStream([]Middleware{middleware}, streamHandler("这是机", "密", "文件，", "sec", "ret!"))(ctx, userRequest("u1", "hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "这是***，***!", collectDeltas(chunks))
+
+	// 历史中的用户消息同样检查，助手回复不检查
+	history := userRequest("u1", "hi")
+	history.Messages = append([]Message{{Role: "user", Content: "机密文件在哪"}, {Role: "assistant", Content: "secret"}}, history.Messages...)
+	_, err = chainChat([]Middleware{middleware}, replyHandler("ok", Usage{}))(ctx, history)
+	assert.ErrorIs(t, err, ErrContentBlocked)
+	_, err = chainStream([]Middleware{middleware}, streamHandler("a"))(ctx, history)
+	assert.ErrorIs(t, err, ErrContentBlocked)
+
+	history.Messages = history.Messages[1:]
+	_, err = chainChat([]Middleware{middleware}, replyHandler("ok", Usage{}))(ctx, history)
+	assert.NoError(t, err)
+}
+
+func TestStreamMasker(t *testing.T) {
+	masker := &streamMasker{filter: newKeywordFilter([]string{"ab", "abcd"}, "#")}
+
+	var out strings.Builder
+	for _, delta := range []string{"xa", "b", "c", "dyab", "z"} {
+		out.WriteString(masker.write(delta))
+	}
+	out.WriteString(masker.flush())
+	assert.Equal(t, "x#y#z", out.String())
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	collector := &auditCollector{records: make(chan *AuditRecord, 4)}
+	middleware := NewAuditMiddleware(collector, 5)
+	ctx := context.Background()
+
+	_, err := chainChat([]Middleware{middleware}, replyHandler("一二三四五六", Usage{TotalTokens: 9}))(ctx, userRequest("u1", "hello world"))
+	require.NoError(t, err)
+	record := <-collector.records
+	assert.Equal(t, "u1", record.UserID)
+	assert.Equal(t, "hello...", record.Prompt)
+	assert.Equal(t, "一二三四五...", record.Response)
+	assert.Equal(t, 9, record.Usage.TotalTokens)
+
+	// 被内层中间件拦截的请求同样记录
+	blocked := NewContentFilterMiddleware([]string{"secret"}, "***")
+	_, err = chainChat([]Middleware{middleware, blocked}, replyHandler("ok", Usage{}))(ctx, userRequest("u1", "secret"))
+	require.Error(t, err)
+	record = <-collector.records
+	assert.Equal(t, ErrContentBlocked.Error(), record.Error)
+
+	chunks, err := chainStream([]Middleware{middleware}, streamHandler("a", "b"))(ctx, userRequest("u1", "hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "ab", collectDeltas(chunks))
+	record = <-collector.records
+	assert.Equal(t, "ab", record.Response)
+}
+
+// usageCollector 收集用量记录
+type usageCollector struct {
+	records chan *UsageRecord
+}
+
+func (c *usageCollector) Record(ctx context.Context, record *UsageRecord) {
+	c.records <- record
+}
+
+func TestUsageMiddleware(t *testing.T) {
+	collector := &usageCollector{records: make(chan *UsageRecord, 4)}
+	modelInfo := func(model string) (*ModelInfo, error) {
+		return &ModelInfo{ID: model, InputCost: 1, OutputCost: 2}, nil
+	}
+	middleware := NewUsageMiddleware(collector, "openai", "gpt-4o", modelInfo)
+	ctx := context.Background()
+
+	// 0.3 * 1 + 0.2 * 2 = 0.7 美元，回复标明的提供商和模型优先
+	handler := func(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+		return &ChatResponse{
+			Model:    "deepseek-chat",
+			Message:  Message{Content: "ok"},
+			Usage:    Usage{PromptTokens: 300, CompletionTokens: 200, TotalTokens: 500},
+			Metadata: map[string]interface{}{"provider": "deepseek"},
+		}, nil
+	}
+	_, err := chainChat([]Middleware{middleware}, handler)(ctx, userRequest("7", "hi"))
+	require.NoError(t, err)
+	record := <-collector.records
+	assert.Equal(t, "7", record.UserID)
+	assert.Equal(t, "deepseek", record.Provider)
+	assert.Equal(t, "deepseek-chat", record.Model)
+	assert.Equal(t, 500, record.TotalTokens)
+	assert.InDelta(t, 0.7, record.Cost, 1e-9)
+	assert.False(t, record.Cached)
+
+	// 缓存命中记录调用但不计用量
+	cached := func(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+		return &ChatResponse{Message: Message{Content: "ok"}, Metadata: map[string]interface{}{"cache": "hit"}}, nil
+	}
+	_, err = chainChat([]Middleware{middleware}, cached)(ctx, userRequest("7", "hi"))
+	require.NoError(t, err)
+	record = <-collector.records
+	assert.True(t, record.Cached)
+	assert.Equal(t, "openai", record.Provider)
+	assert.Equal(t, "gpt-4o", record.Model)
+	assert.Zero(t, record.TotalTokens)
+	assert.Zero(t, record.Cost)
+
+	// 失败的调用记录错误
+	blocked := NewContentFilterMiddleware([]string{"secret"}, "***")
+	_, err = chainChat([]Middleware{middleware, blocked}, replyHandler("ok", Usage{}))(ctx, userRequest("7", "secret"))
+	require.Error(t, err)
+	record = <-collector.records
+	assert.Equal(t, ErrContentBlocked.Error(), record.Error)
+	assert.Zero(t, record.TotalTokens)
+
+	// 未返回用量的流式回复按内容估算
+	chunks, err := chainStream([]Middleware{middleware}, streamHandler("你好", "世界"))(ctx, userRequest("7", "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "你好世界", collectDeltas(chunks))
+	record = <-collector.records
+	assert.Equal(t, estimateMessagesTokens([]Message{{Content: "hello"}}), record.PromptTokens)
+	assert.Equal(t, 4, record.CompletionTokens)
+	assert.Positive(t, record.Cost)
+}
+
+func TestRelayStreamCancel(t *testing.T) {
+	in := make(chan *ChatResponse)
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	out := relayStream(ctx, in, func(chunk *ChatResponse) []*ChatResponse {
+		return []*ChatResponse{chunk}
+	}, func() []*ChatResponse {
+		close(finished)
+		return nil
+	})
+
+	in <- &ChatResponse{Delta: "a"}
+	assert.Equal(t, "a", (<-out).Delta)
+
+	// 下游不再读取，取消后上游仍可继续写入直到关闭
+	cancel()
+	in <- &ChatResponse{Delta: "b"}
+	in <- &ChatResponse{Delta: "c"}
+	close(in)
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not finish after cancel")
+	}
+}
+
+func TestDefaultAIServiceMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	config.RateLimit.Requests = 1
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	store := NewMemoryLimitStore()
+	service.SetLimitStore(store)
+	service.SetAuditLogger(&auditCollector{records: make(chan *AuditRecord, 4)})
+
+	var trace []string
+	service.AddMiddleware(&recordMiddleware{name: "custom", trace: &trace})
+	require.NoError(t, service.Initialize(config))
+	ctx := context.Background()
+
+	chunks, err := service.ChatStream(ctx, userRequest("u1", "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hi", collectDeltas(chunks))
+	assert.Equal(t, []string{"custom:stream"}, trace)
+
+	// 配额按提供商返回的用量累计
+	tokenKey, _ := quotaKeys("u1")
+	used, err := store.Get(ctx, tokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), used)
+
+	_, err = service.ChatStream(ctx, userRequest("u1", "hello"))
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
@@ -0,0 +1,667 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModelManager 支持管理本地模型的提供商
+type ModelManager interface {
+	// ListModels 列出已下载的模型
+	ListModels(ctx context.Context) ([]LocalModel, error)
+	// PullModel 下载模型，progress 接收下载进度，返回 nil 表示下载完成
+	PullModel(ctx context.Context, name string, progress func(*PullProgress)) error
+}
+
+// LocalModel 本地模型
+type LocalModel struct {
+	Name              string    `json:"name"`
+	Size              int64     `json:"size"`
+	Digest            string    `json:"digest"`
+	Family            string    `json:"family"`
+	ParameterSize     string    `json:"parameterSize"`
+	QuantizationLevel string    `json:"quantizationLevel"`
+	ModifiedAt        time.Time `json:"modifiedAt"`
+}
+
+// PullProgress 模型下载进度
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// OllamaProvider Ollama 本地模型提供商实现
+type OllamaProvider struct {
+	client       *http.Client
+	streamClient *http.Client
+	config       *Config
+
+	// 模型信息缓存，避免每次请求都查询 /api/show
+	mu        sync.RWMutex
+	modelInfo map[string]*ModelInfo
+}
+
+// NewOllamaProvider 创建Ollama提供商
+func NewOllamaProvider() AIProvider {
+	return &OllamaProvider{
+		client: &http.Client{
+			Timeout: time.Second * 30,
+		},
+		streamClient: &http.Client{},
+		modelInfo:    make(map[string]*ModelInfo),
+	}
+}
+
+// ollamaChatRequest /api/chat 请求体
+type ollamaChatRequest struct {
+	Model     string                 `json:"model"`
+	Messages  []ollamaMessage        `json:"messages"`
+	Stream    bool                   `json:"stream"`
+	Tools     []ollamaTool           `json:"tools,omitempty"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive interface{}            `json:"keep_alive,omitempty"`
+}
+
+// ollamaMessage 对话消息
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// ollamaTool 工具定义
+type ollamaTool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// ollamaToolCall 工具调用
+type ollamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaChatResponse /api/chat 响应，流式时每行一个，最后一行 done 为 true 并携带用量
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// Initialize 初始化提供商，本地模型不需要 API Key
+func (p *OllamaProvider) Initialize(config *Config) error {
+	p.config = config
+
+	// 流式请求只限制等待响应头的时间，整体时长由 ctx 控制
+	p.client.Timeout = config.Timeout
+	p.streamClient.Transport = &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: config.Timeout,
+	}
+
+	return nil
+}
+
+// Validate 验证提供商
+func (p *OllamaProvider) Validate() error {
+	if p.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	return nil
+}
+
+// Chat 聊天接口
+func (p *OllamaProvider) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	resp, err := p.post(ctx, p.client, "/api/chat", p.buildChatRequest(request, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &ChatResponse{Error: parseOllamaError(resp.StatusCode, body), Timestamp: time.Now()}, nil
+	}
+
+	var result ollamaChatResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+
+	chatResp := &ChatResponse{
+		ID: fmt.Sprintf("chat_%d", time.Now().UnixNano()),
+		Message: Message{
+			Role:         "assistant",
+			Content:      result.Message.Content,
+			FunctionCall: convertOllamaToolCall(result.Message.ToolCalls),
+			Timestamp:    time.Now(),
+		},
+		Usage:     ollamaUsage(&result),
+		Model:     result.Model,
+		Finish:    convertOllamaDoneReason(&result),
+		Done:      true,
+		Timestamp: time.Now(),
+	}
+	return chatResp, nil
+}
+
+// ChatStream 流式聊天接口，响应为逐行 JSON
+func (p *OllamaProvider) ChatStream(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+	resp, err := p.post(ctx, p.streamClient, "/api/chat", p.buildChatRequest(request, true))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseOllamaError(resp.StatusCode, body)
+	}
+
+	respChan := make(chan *ChatResponse, 100)
+	go func() {
+		defer close(respChan)
+		defer resp.Body.Close()
+
+		p.handleStreamResponse(ctx, resp.Body, respChan)
+	}()
+
+	return respChan, nil
+}
+
+// GetModels 获取已下载的模型，服务不可用时返回配置的模型
+func (p *OllamaProvider) GetModels() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	models, err := p.ListModels(ctx)
+	if err != nil || len(models) == 0 {
+		return []string{p.getModel("")}
+	}
+
+	names := make([]string, 0, len(models))
+	for _, model := range models {
+		names = append(names, model.Name)
+	}
+	return names
+}
+
+// GetModelInfo 通过 /api/show 获取模型信息，本地模型不计费
+func (p *OllamaProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	model = p.getModel(model)
+
+	p.mu.RLock()
+	info, exists := p.modelInfo[model]
+	p.mu.RUnlock()
+	if exists {
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	resp, err := p.post(ctx, p.client, "/api/show", map[string]interface{}{"model": model})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseOllamaError(resp.StatusCode, body)
+	}
+
+	var result struct {
+		Details struct {
+			Family string `json:"family"`
+		} `json:"details"`
+		ModelInfo    map[string]interface{} `json:"model_info"`
+		Capabilities []string               `json:"capabilities"`
+		ModifiedAt   time.Time              `json:"modified_at"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+
+	// 上下文长度位于 "<架构>.context_length"
+	contextSize := 0
+	for key, value := range result.ModelInfo {
+		if length, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			contextSize = int(length)
+			break
+		}
+	}
+	if p.config.Ollama != nil && p.config.Ollama.NumCtx > 0 && (contextSize == 0 || p.config.Ollama.NumCtx < contextSize) {
+		contextSize = p.config.Ollama.NumCtx
+	}
+
+	features := []string{"chat"}
+	for _, capability := range result.Capabilities {
+		switch capability {
+		case "tools":
+			features = append(features, "function_calling")
+		case "vision":
+			features = append(features, "image")
+		}
+	}
+
+	info = &ModelInfo{
+		ID:          model,
+		Name:        model,
+		Provider:    "ollama",
+		MaxTokens:   p.getNumPredict(0),
+		Features:    features,
+		ContextSize: contextSize,
+		CreatedAt:   result.ModifiedAt,
+	}
+
+	p.mu.Lock()
+	p.modelInfo[model] = info
+	p.mu.Unlock()
+	return info, nil
+}
+
+// GetUsage 获取使用统计，本地模型不提供用量查询
+func (p *OllamaProvider) GetUsage(ctx context.Context, startTime, endTime time.Time) (*Usage, error) {
+	return &Usage{}, nil
+}
+
+// GetCost 获取成本统计，本地模型不计费
+func (p *OllamaProvider) GetCost(ctx context.Context, startTime, endTime time.Time) (float64, error) {
+	return 0, nil
+}
+
+// HealthCheck 健康检查
+func (p *OllamaProvider) HealthCheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL()+"/api/version", nil)
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama health check failed: %w", parseOllamaError(resp.StatusCode, body))
+	}
+	return nil
+}
+
+// ListModels 列出已下载的模型
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]LocalModel, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL()+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseOllamaError(resp.StatusCode, body)
+	}
+
+	var result struct {
+		Models []struct {
+			Name       string    `json:"name"`
+			Size       int64     `json:"size"`
+			Digest     string    `json:"digest"`
+			ModifiedAt time.Time `json:"modified_at"`
+			Details    struct {
+				Family            string `json:"family"`
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+
+	models := make([]LocalModel, 0, len(result.Models))
+	for _, model := range result.Models {
+		models = append(models, LocalModel{
+			Name:              model.Name,
+			Size:              model.Size,
+			Digest:            model.Digest,
+			Family:            model.Details.Family,
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+			ModifiedAt:        model.ModifiedAt,
+		})
+	}
+	return models, nil
+}
+
+// PullModel 下载模型，进度逐行返回，ctx 取消时中断下载（再次下载会从断点继续）
+func (p *OllamaProvider) PullModel(ctx context.Context, name string, progress func(*PullProgress)) error {
+	resp, err := p.post(ctx, p.streamClient, "/api/pull", map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return parseOllamaError(resp.StatusCode, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		if line.Error != "" {
+			return &APIError{Code: "pull_failed", Message: line.Error}
+		}
+		if progress != nil {
+			progress(&line.PullProgress)
+		}
+		if line.Status == "success" {
+			p.mu.Lock()
+			delete(p.modelInfo, name)
+			p.mu.Unlock()
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read pull progress failed: %w", err)
+	}
+	return fmt.Errorf("pull model %s interrupted", name)
+}
+
+// 私有方法
+
+// post 发送 JSON 请求
+func (p *OllamaProvider) post(ctx context.Context, client *http.Client, path string, body interface{}) (*http.Response, error) {
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL()+path, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// baseURL Ollama 服务地址
+func (p *OllamaProvider) baseURL() string {
+	return strings.TrimSuffix(p.config.GetEffectiveBaseURL(), "/")
+}
+
+// buildChatRequest 构建聊天请求
+func (p *OllamaProvider) buildChatRequest(request *ChatRequest, stream bool) *ollamaChatRequest {
+	req := &ollamaChatRequest{
+		Model:  p.getModel(request.Model),
+		Stream: stream,
+		Options: map[string]interface{}{
+			"num_predict": p.getNumPredict(request.MaxTokens),
+		},
+	}
+
+	temperature := p.config.Temperature
+	if request.Temperature > 0 {
+		temperature = request.Temperature
+	}
+	req.Options["temperature"] = temperature
+	if request.TopP > 0 {
+		req.Options["top_p"] = request.TopP
+	} else if p.config.TopP > 0 {
+		req.Options["top_p"] = p.config.TopP
+	}
+
+	if ollama := p.config.Ollama; ollama != nil {
+		if ollama.NumCtx > 0 {
+			req.Options["num_ctx"] = ollama.NumCtx
+		}
+		if ollama.RepeatPenalty > 0 {
+			req.Options["repeat_penalty"] = ollama.RepeatPenalty
+		}
+		// -1 表示常驻内存
+		if ollama.KeepAlive {
+			req.KeepAlive = -1
+		}
+	}
+
+	// 系统提示词作为首条 system 消息
+	systemPrompt := request.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = p.config.SystemPrompt
+	}
+	if systemPrompt != "" && (len(request.Messages) == 0 || request.Messages[0].Role != "system") {
+		req.Messages = append(req.Messages, ollamaMessage{Role: "system", Content: systemPrompt})
+	}
+
+	for _, msg := range request.Messages {
+		req.Messages = append(req.Messages, convertOllamaMessage(msg))
+	}
+
+	for _, fn := range request.Functions {
+		req.Tools = append(req.Tools, ollamaTool{Type: "function", Function: fn})
+	}
+
+	return req
+}
+
+// convertOllamaMessage 转换消息：图片以 base64 发送（Ollama 不支持图片 URL，只有 URL 的图片被忽略），
+// 函数结果使用 tool 角色，助手的函数调用转换为 tool_calls
+func convertOllamaMessage(msg Message) ollamaMessage {
+	converted := ollamaMessage{Role: msg.Role, Content: msg.Content}
+	for _, image := range messageImages(msg) {
+		if _, data, ok := image.inline(); ok {
+			converted.Images = append(converted.Images, data)
+		}
+	}
+	if msg.FunctionCall == nil {
+		return converted
+	}
+
+	switch msg.Role {
+	case "function", "tool":
+		converted.Role = "tool"
+		converted.ToolName = msg.FunctionCall.Name
+		if converted.Content == "" {
+			converted.Content = functionResultText(msg.FunctionCall)
+		}
+	case "assistant":
+		var call ollamaToolCall
+		call.Function.Name = msg.FunctionCall.Name
+		call.Function.Arguments = msg.FunctionCall.Arguments
+		converted.ToolCalls = []ollamaToolCall{call}
+	}
+	return converted
+}
+
+// handleStreamResponse 处理逐行 JSON 流，ctx 取消后不再发送
+func (p *OllamaProvider) handleStreamResponse(ctx context.Context, body io.Reader, respChan chan<- *ChatResponse) {
+	send := func(resp *ChatResponse) bool {
+		select {
+		case respChan <- resp:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line ollamaChatResponse
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+
+		if line.Error != "" {
+			send(&ChatResponse{
+				Error:  &APIError{Code: "stream_error", Message: line.Error},
+				Stream: true,
+				Done:   true,
+			})
+			return
+		}
+
+		chunk := &ChatResponse{
+			ID:    fmt.Sprintf("stream_%d", time.Now().UnixNano()),
+			Model: line.Model,
+			Message: Message{
+				Role:         "assistant",
+				Content:      line.Message.Content,
+				FunctionCall: convertOllamaToolCall(line.Message.ToolCalls),
+				Timestamp:    time.Now(),
+				Streaming:    true,
+			},
+			Stream:    true,
+			Delta:     line.Message.Content,
+			Timestamp: time.Now(),
+		}
+		if line.Done {
+			chunk.Usage = ollamaUsage(&line)
+			chunk.Finish = convertOllamaDoneReason(&line)
+		}
+		if !send(chunk) {
+			return
+		}
+
+		if line.Done {
+			send(&ChatResponse{Model: line.Model, Stream: true, Done: true, Timestamp: time.Now()})
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		send(&ChatResponse{
+			Error:  &APIError{Code: "stream_error", Message: err.Error()},
+			Stream: true,
+			Done:   true,
+		})
+	}
+}
+
+// getModel 获取模型：请求指定 > 通用配置 > Ollama 配置
+func (p *OllamaProvider) getModel(model string) string {
+	if model != "" {
+		return model
+	}
+	if p.config.Model != "" {
+		return p.config.Model
+	}
+	if p.config.Ollama != nil {
+		return p.config.Ollama.Model
+	}
+	return ""
+}
+
+// getNumPredict 获取最大生成 token 数：请求指定 > Ollama 配置 > 通用配置
+func (p *OllamaProvider) getNumPredict(maxTokens int) int {
+	if maxTokens > 0 {
+		return maxTokens
+	}
+	if p.config.Ollama != nil && p.config.Ollama.NumPredict != 0 {
+		return p.config.Ollama.NumPredict
+	}
+	return p.config.MaxTokens
+}
+
+// parseOllamaError 解析错误响应，响应体格式为 {"error": "..."}
+func parseOllamaError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		Code:       fmt.Sprintf("HTTP_%d", statusCode),
+		Message:    string(body),
+		StatusCode: statusCode,
+		Retryable:  statusCode == http.StatusTooManyRequests || statusCode >= 500,
+	}
+
+	var result struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err == nil && result.Error != "" {
+		apiErr.Message = result.Error
+	}
+	if statusCode == http.StatusNotFound {
+		apiErr.Code = "model_not_found"
+	}
+	return apiErr
+}
+
+// convertOllamaToolCall 取第一个工具调用，Ollama 不分配调用 ID
+func convertOllamaToolCall(calls []ollamaToolCall) *FunctionCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	return &FunctionCall{
+		Name:      calls[0].Function.Name,
+		Arguments: calls[0].Function.Arguments,
+	}
+}
+
+// ollamaUsage 转换用量
+func ollamaUsage(resp *ollamaChatResponse) Usage {
+	return Usage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+}
+
+// convertOllamaDoneReason 转换结束原因
+func convertOllamaDoneReason(resp *ollamaChatResponse) string {
+	if len(resp.Message.ToolCalls) > 0 {
+		return "function_call"
+	}
+	switch resp.DoneReason {
+	case "":
+		return ""
+	case "length":
+		return "length"
+	default:
+		return "stop"
+	}
+}
@@ -0,0 +1,231 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ollamaTextStream 录制的流式响应，每行一个 JSON
+const ollamaTextStream = `{"model":"qwen2.5:7b","created_at":"2025-06-01T08:00:00.1Z","message":{"role":"assistant","content":"你好"},"done":false}
+{"model":"qwen2.5:7b","created_at":"2025-06-01T08:00:00.2Z","message":{"role":"assistant","content":"，世界"},"done":false}
+{"model":"qwen2.5:7b","created_at":"2025-06-01T08:00:00.3Z","message":{"role":"assistant","content":""},"done_reason":"stop","done":true,"total_duration":512000000,"prompt_eval_count":26,"eval_count":12}
+`
+
+// ollamaPullStream 录制的模型下载进度
+const ollamaPullStream = `{"status":"pulling manifest"}
+{"status":"pulling 2bada8a74506","digest":"sha256:2bada8a74506","total":4683073184,"completed":1048576}
+{"status":"pulling 2bada8a74506","digest":"sha256:2bada8a74506","total":4683073184,"completed":4683073184}
+{"status":"verifying sha256 digest"}
+{"status":"writing manifest"}
+{"status":"success"}
+`
+
+// newOllamaTestProvider 创建指向模拟服务的 Ollama 提供商
+func newOllamaTestProvider(t *testing.T, handler http.HandlerFunc) *OllamaProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.Provider = "ollama"
+	config.BaseURL = server.URL
+	config.Model = ""
+	config.Ollama.Model = "qwen2.5:7b"
+
+	provider := NewOllamaProvider().(*OllamaProvider)
+	require.NoError(t, provider.Initialize(config))
+	return provider
+}
+
+func TestOllamaProviderChat(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "qwen2.5:7b", body["model"])
+		assert.Equal(t, false, body["stream"])
+		assert.Equal(t, float64(-1), body["keep_alive"])
+
+		options := body["options"].(map[string]interface{})
+		assert.Equal(t, float64(2048), options["num_ctx"])
+		assert.Equal(t, float64(100), options["num_predict"])
+
+		messages := body["messages"].([]interface{})
+		require.Len(t, messages, 4)
+		assert.Equal(t, "system", messages[0].(map[string]interface{})["role"])
+		assistant := messages[2].(map[string]interface{})
+		assert.Equal(t, "get_weather", assistant["tool_calls"].([]interface{})[0].(map[string]interface{})["function"].(map[string]interface{})["name"])
+		tool := messages[3].(map[string]interface{})
+		assert.Equal(t, "tool", tool["role"])
+		assert.Equal(t, "晴", tool["content"])
+
+		fmt.Fprint(w, `{"model":"qwen2.5:7b","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"location":"上海"}}}]},"done_reason":"stop","done":true,"prompt_eval_count":30,"eval_count":8}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{
+		MaxTokens: 100,
+		Messages: []Message{
+			{Role: "user", Content: "上海天气"},
+			{Role: "assistant", FunctionCall: &FunctionCall{Name: "get_weather", Arguments: map[string]interface{}{"location": "上海"}}},
+			{Role: "function", FunctionCall: &FunctionCall{Name: "get_weather", Result: "晴"}},
+		},
+		Functions: []FunctionDefinition{{Name: "get_weather", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	require.NotNil(t, resp.Message.FunctionCall)
+	assert.Equal(t, "上海", resp.Message.FunctionCall.Arguments["location"])
+	assert.Equal(t, "function_call", resp.Finish)
+	assert.Equal(t, Usage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38}, resp.Usage)
+}
+
+func TestOllamaProviderChatError(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model \"llama9\" not found, try pulling it first"}`)
+	})
+
+	resp, err := provider.Chat(context.Background(), &ChatRequest{Model: "llama9", Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "model_not_found", resp.Error.Code)
+	assert.Equal(t, http.StatusNotFound, resp.Error.StatusCode)
+	assert.Contains(t, resp.Error.Message, "try pulling it first")
+
+	_, err = provider.ChatStream(context.Background(), &ChatRequest{Model: "llama9", Messages: []Message{{Role: "user", Content: "hi"}}})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "model_not_found", apiErr.Code)
+}
+
+func TestOllamaProviderStream(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, true, body["stream"])
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprint(w, ollamaTextStream)
+	})
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+
+	var content string
+	var last *ChatResponse
+	for _, chunk := range collectStream(t, chunks) {
+		require.Nil(t, chunk.Error)
+		content += chunk.Delta
+		if chunk.Finish != "" {
+			assert.Equal(t, "stop", chunk.Finish)
+			assert.Equal(t, 38, chunk.Usage.TotalTokens)
+		}
+		last = chunk
+	}
+	assert.Equal(t, "你好，世界", content)
+	require.NotNil(t, last)
+	assert.True(t, last.Done)
+}
+
+func TestOllamaProviderStreamError(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"model":"qwen2.5:7b","message":{"role":"assistant","content":"部分"},"done":false}
+{"error":"an error was encountered while running the model"}
+`)
+	})
+
+	chunks, err := provider.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+
+	result := collectStream(t, chunks)
+	require.Len(t, result, 2)
+	assert.Equal(t, "部分", result[0].Delta)
+	require.NotNil(t, result[1].Error)
+	assert.Contains(t, result[1].Error.Message, "running the model")
+}
+
+func TestOllamaProviderModels(t *testing.T) {
+	shows := 0
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"qwen2.5:7b","model":"qwen2.5:7b","modified_at":"2025-05-20T10:00:00Z","size":4683087332,"digest":"845dbda0ea48","details":{"format":"gguf","family":"qwen2","parameter_size":"7.6B","quantization_level":"Q4_K_M"}},{"name":"llava:latest","size":4733363377,"digest":"8dd30f6b0cb1","details":{"family":"llama"}}]}`)
+		case "/api/show":
+			shows++
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "qwen2.5:7b", body["model"])
+			fmt.Fprint(w, `{"details":{"family":"qwen2"},"model_info":{"general.architecture":"qwen2","qwen2.context_length":32768},"capabilities":["completion","tools"]}`)
+		case "/api/version":
+			fmt.Fprint(w, `{"version":"0.9.0"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	models, err := provider.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, "7.6B", models[0].ParameterSize)
+	assert.Equal(t, "Q4_K_M", models[0].QuantizationLevel)
+	assert.Equal(t, []string{"qwen2.5:7b", "llava:latest"}, provider.GetModels())
+
+	// 上下文长度不超过配置的 numCtx，结果被缓存
+	for i := 0; i < 2; i++ {
+		info, err := provider.GetModelInfo("")
+		require.NoError(t, err)
+		assert.Equal(t, "ollama", info.Provider)
+		assert.Equal(t, 2048, info.ContextSize)
+		assert.Contains(t, info.Features, "function_calling")
+		assert.Zero(t, info.InputCost)
+	}
+	assert.Equal(t, 1, shows)
+
+	assert.NoError(t, provider.HealthCheck(context.Background()))
+}
+
+func TestOllamaProviderModelsUnavailable(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":"internal error"}`)
+	})
+
+	assert.Equal(t, []string{"qwen2.5:7b"}, provider.GetModels())
+	assert.Error(t, provider.HealthCheck(context.Background()))
+}
+
+func TestOllamaProviderPullModel(t *testing.T) {
+	provider := newOllamaTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/pull", r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body["model"] == "missing" {
+			fmt.Fprint(w, `{"status":"pulling manifest"}
+{"error":"pull model manifest: file does not exist"}
+`)
+			return
+		}
+		fmt.Fprint(w, ollamaPullStream)
+	})
+
+	var progress []*PullProgress
+	err := provider.PullModel(context.Background(), "qwen2.5:7b", func(p *PullProgress) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, err)
+	require.Len(t, progress, 6)
+	assert.Equal(t, int64(4683073184), progress[2].Completed)
+	assert.Equal(t, "success", progress[5].Status)
+
+	err = provider.PullModel(context.Background(), "missing", nil)
+	assert.ErrorContains(t, err, "file does not exist")
+}
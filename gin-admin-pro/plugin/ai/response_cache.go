@@ -0,0 +1,406 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"gin-admin-pro/plugin/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CacheResult 缓存查询结果，用于统计命中率
+type CacheResult string
+
+const (
+	// CacheHit 请求完全相同，命中缓存
+	CacheHit CacheResult = "hit"
+	// CacheSemanticHit 提问语义相近，命中语义缓存
+	CacheSemanticHit CacheResult = "semanticHit"
+	// CacheMiss 未命中
+	CacheMiss CacheResult = "miss"
+)
+
+// ResponseCache 对话响应缓存，多实例部署时应使用 Redis 等共享存储。
+// 语义缓存按 scope（除最后一条提问外的请求内容）分组记录提问向量，只在上下文相同的请求之间匹配。
+type ResponseCache interface {
+	// Get 获取缓存的响应，不存在或已过期时返回 nil
+	Get(ctx context.Context, key string) (*ChatResponse, error)
+	// Set 缓存响应
+	Set(ctx context.Context, key string, response *ChatResponse, ttl time.Duration) error
+	// AddVector 记录缓存键对应的提问向量
+	AddVector(ctx context.Context, scope, key string, vector []float32, ttl time.Duration) error
+	// Nearest 返回 scope 内与提问向量最相似的缓存键及余弦相似度，没有记录时返回空键
+	Nearest(ctx context.Context, scope string, vector []float32) (string, float64, error)
+	// Record 记录一次查询结果
+	Record(ctx context.Context, result CacheResult) error
+	// Stats 获取缓存统计
+	Stats(ctx context.Context) (*CacheStats, error)
+}
+
+// cacheKeyMessage 参与缓存键计算的消息字段，不含 ID、时间等每次请求都不同的字段
+type cacheKeyMessage struct {
+	Role         string        `json:"role"`
+	Content      string        `json:"content"`
+	ImageURL     string        `json:"imageUrl,omitempty"`
+	Images       []Image       `json:"images,omitempty"`
+	AudioURL     string        `json:"audioUrl,omitempty"`
+	FunctionCall *FunctionCall `json:"functionCall,omitempty"`
+}
+
+// cacheKeyPayload 缓存键的规范化内容，JSON 序列化时 map 按键排序，相同请求得到相同的哈希
+type cacheKeyPayload struct {
+	Model        string               `json:"model"`
+	SystemPrompt string               `json:"systemPrompt,omitempty"`
+	Messages     []cacheKeyMessage    `json:"messages"`
+	Temperature  float64              `json:"temperature"`
+	TopP         float64              `json:"topP"`
+	MaxTokens    int                  `json:"maxTokens"`
+	Functions    []FunctionDefinition `json:"functions,omitempty"`
+}
+
+// CacheKey 计算请求的缓存键：模型、系统提示词、消息、采样参数和函数定义的规范化哈希
+func CacheKey(model string, request *ChatRequest) string {
+	return hashCachePayload(newCacheKeyPayload(model, request, request.Messages))
+}
+
+// cacheScope 计算语义缓存分组：最后一条消息之外的请求内容哈希，返回分组和最后一条提问。
+// 最后一条不是纯文本的用户提问时不使用语义缓存，返回空提问。
+func cacheScope(model string, request *ChatRequest) (string, string) {
+	if len(request.Messages) == 0 {
+		return "", ""
+	}
+	last := request.Messages[len(request.Messages)-1]
+	if last.Role != "user" || last.Content == "" || len(messageImages(last)) > 0 || last.AudioURL != "" {
+		return "", ""
+	}
+	return hashCachePayload(newCacheKeyPayload(model, request, request.Messages[:len(request.Messages)-1])), last.Content
+}
+
+func newCacheKeyPayload(model string, request *ChatRequest, messages []Message) *cacheKeyPayload {
+	payload := &cacheKeyPayload{
+		Model:        model,
+		SystemPrompt: request.SystemPrompt,
+		Messages:     make([]cacheKeyMessage, len(messages)),
+		Temperature:  request.Temperature,
+		TopP:         request.TopP,
+		MaxTokens:    request.MaxTokens,
+		Functions:    request.Functions,
+	}
+	for i, message := range messages {
+		payload.Messages[i] = cacheKeyMessage{
+			Role:         message.Role,
+			Content:      message.Content,
+			ImageURL:     message.ImageURL,
+			Images:       message.Images,
+			AudioURL:     message.AudioURL,
+			FunctionCall: message.FunctionCall,
+		}
+	}
+	return payload
+}
+
+func hashCachePayload(payload *cacheKeyPayload) string {
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cosineSimilarity 计算两个向量的余弦相似度，维度不同或存在零向量时返回 0
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// newCacheStats 根据计数计算命中率
+func newCacheStats(hits, semanticHits, misses int64, count int) *CacheStats {
+	stats := &CacheStats{
+		Hits:         hits,
+		SemanticHits: semanticHits,
+		Misses:       misses,
+		Count:        count,
+	}
+	if total := hits + semanticHits + misses; total > 0 {
+		stats.HitRatio = float64(hits+semanticHits) / float64(total)
+	}
+	return stats
+}
+
+// MemoryResponseCache 内存响应缓存，仅在单实例内生效
+type MemoryResponseCache struct {
+	mu         sync.Mutex
+	maxSize    int
+	responses  map[string]*memoryCachedResponse
+	vectors    map[string][]*memoryCachedVector
+	hits       int64
+	semantic   int64
+	misses     int64
+	memoryUsed int64
+}
+
+type memoryCachedResponse struct {
+	response  *ChatResponse
+	size      int64
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+type memoryCachedVector struct {
+	key       string
+	vector    []float32
+	expiresAt time.Time
+}
+
+// NewMemoryResponseCache 创建内存响应缓存，maxSize 为最多缓存的响应数
+func NewMemoryResponseCache(maxSize int) *MemoryResponseCache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	return &MemoryResponseCache{
+		maxSize:   maxSize,
+		responses: make(map[string]*memoryCachedResponse),
+		vectors:   make(map[string][]*memoryCachedVector),
+	}
+}
+
+// Get 获取缓存的响应
+func (c *MemoryResponseCache) Get(ctx context.Context, key string) (*ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.responses[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+	return entry.response, nil
+}
+
+// Set 缓存响应，超出容量时淘汰最早缓存的响应
+func (c *MemoryResponseCache) Set(ctx context.Context, key string, response *ChatResponse, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, exists := c.responses[key]; !exists && len(c.responses) >= c.maxSize {
+		c.evict(now)
+	}
+	if old, exists := c.responses[key]; exists {
+		c.memoryUsed -= old.size
+	}
+	size := int64(len(response.Message.Content))
+	c.responses[key] = &memoryCachedResponse{response: response, size: size, createdAt: now, expiresAt: now.Add(ttl)}
+	c.memoryUsed += size
+	return nil
+}
+
+// evict 清理过期响应，仍超出容量时淘汰最早缓存的一项，调用方需持有锁
+func (c *MemoryResponseCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.responses {
+		if now.After(entry.expiresAt) {
+			c.memoryUsed -= entry.size
+			delete(c.responses, key)
+			continue
+		}
+		if oldestKey == "" || entry.createdAt.Before(oldest) {
+			oldestKey, oldest = key, entry.createdAt
+		}
+	}
+	if len(c.responses) >= c.maxSize && oldestKey != "" {
+		c.memoryUsed -= c.responses[oldestKey].size
+		delete(c.responses, oldestKey)
+	}
+}
+
+// AddVector 记录提问向量，每个分组最多保留 maxSize 条
+func (c *MemoryResponseCache) AddVector(ctx context.Context, scope, key string, vector []float32, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entries := c.vectors[scope][:0]
+	for _, entry := range c.vectors[scope] {
+		if entry.key != key && now.Before(entry.expiresAt) {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) >= c.maxSize {
+		entries = entries[1:]
+	}
+	c.vectors[scope] = append(entries, &memoryCachedVector{key: key, vector: vector, expiresAt: now.Add(ttl)})
+	return nil
+}
+
+// Nearest 返回分组内最相似的缓存键
+func (c *MemoryResponseCache) Nearest(ctx context.Context, scope string, vector []float32) (string, float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var bestKey string
+	var bestScore float64
+	for _, entry := range c.vectors[scope] {
+		if now.After(entry.expiresAt) {
+			continue
+		}
+		if score := cosineSimilarity(vector, entry.vector); bestKey == "" || score > bestScore {
+			bestKey, bestScore = entry.key, score
+		}
+	}
+	return bestKey, bestScore, nil
+}
+
+// Record 记录查询结果
+func (c *MemoryResponseCache) Record(ctx context.Context, result CacheResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch result {
+	case CacheHit:
+		c.hits++
+	case CacheSemanticHit:
+		c.semantic++
+	case CacheMiss:
+		c.misses++
+	}
+	return nil
+}
+
+// Stats 获取缓存统计
+func (c *MemoryResponseCache) Stats(ctx context.Context) (*CacheStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := newCacheStats(c.hits, c.semantic, c.misses, len(c.responses))
+	stats.MemoryUsage = c.memoryUsed
+	return stats, nil
+}
+
+// RedisResponseCache 基于 Redis 的响应缓存，多实例共享缓存和统计
+type RedisResponseCache struct {
+	client     *redis.Client
+	prefix     string
+	maxVectors int
+}
+
+// NewRedisResponseCache 创建 Redis 响应缓存，maxVectors 为每个语义分组最多保留的提问向量数
+func NewRedisResponseCache(client *redis.Client, maxVectors int) *RedisResponseCache {
+	if maxVectors <= 0 {
+		maxVectors = 1000
+	}
+	return &RedisResponseCache{client: client, prefix: "ai:cache:", maxVectors: maxVectors}
+}
+
+// Get 获取缓存的响应
+func (c *RedisResponseCache) Get(ctx context.Context, key string) (*ChatResponse, error) {
+	data, err := c.client.Get(ctx, c.prefix+"response:"+key)
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var response ChatResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Set 缓存响应
+func (c *RedisResponseCache) Set(ctx context.Context, key string, response *ChatResponse, ttl time.Duration) error {
+	return c.client.SetJSON(ctx, c.prefix+"response:"+key, response, ttl)
+}
+
+// AddVector 记录提问向量，分组超出上限时随机淘汰一条
+func (c *RedisResponseCache) AddVector(ctx context.Context, scope, key string, vector []float32, ttl time.Duration) error {
+	data, err := json.Marshal(vector)
+	if err != nil {
+		return err
+	}
+	vectorKey := c.prefix + "vector:" + scope
+	cmd := c.client.GetClient()
+	if size, err := cmd.HLen(ctx, vectorKey).Result(); err == nil && size >= int64(c.maxVectors) {
+		if fields, err := cmd.HRandField(ctx, vectorKey, 1).Result(); err == nil && len(fields) > 0 {
+			cmd.HDel(ctx, vectorKey, fields...)
+		}
+	}
+	if err := c.client.HSet(ctx, vectorKey, key, data); err != nil {
+		return err
+	}
+	return c.client.Expire(ctx, vectorKey, ttl)
+}
+
+// Nearest 返回分组内最相似的缓存键，同时清理响应已过期的向量
+func (c *RedisResponseCache) Nearest(ctx context.Context, scope string, vector []float32) (string, float64, error) {
+	vectorKey := c.prefix + "vector:" + scope
+	entries, err := c.client.HGetAll(ctx, vectorKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var bestKey string
+	var bestScore float64
+	for key, data := range entries {
+		var stored []float32
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			continue
+		}
+		if score := cosineSimilarity(vector, stored); bestKey == "" || score > bestScore {
+			bestKey, bestScore = key, score
+		}
+	}
+	if bestKey != "" {
+		if exists, err := c.client.Exists(ctx, c.prefix+"response:"+bestKey); err == nil && exists == 0 {
+			_ = c.client.HDel(ctx, vectorKey, bestKey)
+			return "", 0, nil
+		}
+	}
+	return bestKey, bestScore, nil
+}
+
+// Record 记录查询结果
+func (c *RedisResponseCache) Record(ctx context.Context, result CacheResult) error {
+	return c.client.GetClient().HIncrBy(ctx, c.prefix+"stats", string(result), 1).Err()
+}
+
+// Stats 获取缓存统计，缓存数量通过扫描响应键得到
+func (c *RedisResponseCache) Stats(ctx context.Context) (*CacheStats, error) {
+	counts, err := c.client.HGetAll(ctx, c.prefix+"stats")
+	if err != nil {
+		return nil, err
+	}
+	parse := func(result CacheResult) int64 {
+		value, _ := strconv.ParseInt(counts[string(result)], 10, 64)
+		return value
+	}
+
+	count := 0
+	iter := c.client.GetClient().Scan(ctx, 0, c.prefix+"response:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return newCacheStats(parse(CacheHit), parse(CacheSemanticHit), parse(CacheMiss), count), nil
+}
@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := func() *ChatRequest {
+		return &ChatRequest{
+			SystemPrompt: "You are a helpful AI assistant.",
+			Messages:     []Message{{ID: "1", Role: "user", Content: "如何重置密码", Timestamp: time.Now()}},
+			Temperature:  0.7,
+		}
+	}
+	key := CacheKey("gpt-4o", base())
+
+	// 消息 ID、时间、用户等不影响缓存键
+	same := base()
+	same.Messages[0].ID = "2"
+	same.UserID = "42"
+	assert.Equal(t, key, CacheKey("gpt-4o", same))
+
+	// 同一系统提示词下不同的问题、模型、温度、函数得到不同的缓存键
+	question := base()
+	question.Messages[0].Content = "如何修改头像"
+	temperature := base()
+	temperature.Temperature = 0.2
+	functions := base()
+	functions.Functions = []FunctionDefinition{{Name: "query_users"}}
+	for _, other := range []string{CacheKey("gpt-4o", question), CacheKey("gpt-4o-mini", base()), CacheKey("gpt-4o", temperature), CacheKey("gpt-4o", functions)} {
+		assert.NotEqual(t, key, other)
+	}
+
+	// 语义缓存分组不含最后一条提问
+	scope, text := cacheScope("gpt-4o", base())
+	questionScope, _ := cacheScope("gpt-4o", question)
+	assert.Equal(t, "如何重置密码", text)
+	assert.Equal(t, scope, questionScope)
+
+	image := base()
+	image.Messages[0].ImageURL = "https://example.com/a.png"
+	_, text = cacheScope("gpt-4o", image)
+	assert.Empty(t, text)
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	cache := NewMemoryResponseCache(2)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", &ChatResponse{Message: Message{Content: "A"}}, time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", &ChatResponse{Message: Message{Content: "B"}}, time.Minute))
+	require.NoError(t, cache.Set(ctx, "c", &ChatResponse{Message: Message{Content: "C"}}, time.Minute))
+	evicted, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, evicted)
+	cached, err := cache.Get(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "C", cached.Message.Content)
+
+	require.NoError(t, cache.Set(ctx, "expired", &ChatResponse{}, -time.Second))
+	cached, err = cache.Get(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+
+	require.NoError(t, cache.AddVector(ctx, "scope", "b", []float32{1, 0}, time.Minute))
+	require.NoError(t, cache.AddVector(ctx, "scope", "c", []float32{0, 1}, time.Minute))
+	key, score, err := cache.Nearest(ctx, "scope", []float32{0.1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, "c", key)
+	assert.Greater(t, score, 0.99)
+	key, _, err = cache.Nearest(ctx, "other", []float32{0, 1})
+	require.NoError(t, err)
+	assert.Empty(t, key)
+}
+
+func TestServiceResponseCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"reply %d"}}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`, calls.Add(1))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.APIKey = "sk-test"
+	config.BaseURL = server.URL
+	config.Cache.Enabled = true
+	config.Cache.Semantic.Enabled = true
+	config.Cache.Semantic.Threshold = 0.9
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	service.SetEmbedder(NewHashEmbedder(256))
+	require.NoError(t, service.Initialize(config))
+	ctx := context.Background()
+
+	ask := func(content string) *ChatResponse {
+		resp, err := service.Chat(ctx, &ChatRequest{Messages: []Message{{Role: "user", Content: content}}})
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := ask("How do I reset my password?")
+	assert.Equal(t, "reply 1", first.Message.Content)
+	assert.Equal(t, 8, first.Usage.TotalTokens)
+
+	// 完全相同的请求命中缓存，不消耗 token
+	exact := ask("How do I reset my password?")
+	assert.Equal(t, "reply 1", exact.Message.Content)
+	assert.Equal(t, "hit", exact.Metadata["cache"])
+	assert.Zero(t, exact.Usage.TotalTokens)
+
+	// 语义相近的提问命中语义缓存
+	semantic := ask("how do i reset my password")
+	assert.Equal(t, "reply 1", semantic.Message.Content)
+	assert.Equal(t, "semanticHit", semantic.Metadata["cache"])
+
+	// 同一系统提示词下的不同问题不会返回缓存的回复
+	other := ask("Which departments are disabled?")
+	assert.Equal(t, "reply 2", other.Message.Content)
+	assert.Equal(t, int32(2), calls.Load())
+
+	stats, err := service.GetSystemStats(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, stats.Cache)
+	assert.Equal(t, int64(1), stats.Cache.Hits)
+	assert.Equal(t, int64(1), stats.Cache.SemanticHits)
+	assert.Equal(t, int64(2), stats.Cache.Misses)
+	assert.Equal(t, 2, stats.Cache.Count)
+	assert.InDelta(t, 0.5, stats.Cache.HitRatio, 1e-9)
+}
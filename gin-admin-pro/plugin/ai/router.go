@@ -0,0 +1,555 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoAvailableProvider 没有可服务该请求的提供商（模型或标签不匹配，或全部熔断）
+	ErrNoAvailableProvider = errors.New("no available ai provider")
+	// ErrModelManageUnsupported 提供商不支持管理本地模型
+	ErrModelManageUnsupported = errors.New("model management is not supported by provider")
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常
+	CircuitOpen     = "open"      // 熔断中，不再转发请求
+	CircuitHalfOpen = "half_open" // 熔断超时后放行一个探测请求
+)
+
+// RouteConfig 多提供商路由中的一个提供商
+type RouteConfig struct {
+	Name     string   `yaml:"name" mapstructure:"name"`         // 路由名称，默认同 Provider，同一提供商配置多次时需区分
+	Provider string   `yaml:"provider" mapstructure:"provider"` // openai、claude、deepseek、qwen、ollama 或网关名称
+	APIKey   string   `yaml:"apiKey" mapstructure:"apiKey"`
+	BaseURL  string   `yaml:"baseUrl" mapstructure:"baseUrl"` // 为空时使用提供商默认地址
+	Model    string   `yaml:"model" mapstructure:"model"`     // 请求未指定模型时使用，为空时使用提供商默认模型
+	Models   []string `yaml:"models" mapstructure:"models"`   // 可服务的模型，为空时使用提供商的模型目录
+	Tags     []string `yaml:"tags" mapstructure:"tags"`       // 路由标签，请求携带标签时只选择包含全部标签的提供商
+	Weight   int      `yaml:"weight" mapstructure:"weight"`   // 权重，0 按 1 处理
+}
+
+// CircuitBreakerConfig 熔断配置，连续失败 FailureThreshold 次后熔断 OpenTimeout
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `yaml:"failureThreshold" mapstructure:"failureThreshold"`
+	OpenTimeout      time.Duration `yaml:"openTimeout" mapstructure:"openTimeout"`
+}
+
+// ProviderHealth 提供商健康状态
+type ProviderHealth struct {
+	Name     string        `json:"name"`
+	Provider string        `json:"provider"`
+	Healthy  bool          `json:"healthy"`
+	Circuit  string        `json:"circuit"`
+	Failures int           `json:"failures"` // 连续失败次数
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+}
+
+// circuitBreaker 按连续失败次数熔断，熔断超时后半开放行一个探测请求，成功则恢复
+type circuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	state       string
+	failures    int
+	openedAt    time.Time
+	probing     bool // 半开状态下探测请求是否未结束
+	lastError   string
+	now         func() time.Time
+}
+
+// newCircuitBreaker 创建熔断器
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:   config.FailureThreshold,
+		openTimeout: config.OpenTimeout,
+		state:       CircuitClosed,
+		now:         time.Now,
+	}
+}
+
+// allow 是否允许转发请求
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success 请求成功，恢复正常
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+// failure 请求失败，连续失败达到阈值或探测失败时熔断
+func (b *circuitBreaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// release 请求被取消，结果不计入熔断
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// snapshot 当前状态
+func (b *circuitBreaker) snapshot() (string, int, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == CircuitOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		state = CircuitHalfOpen
+	}
+	return state, b.failures, b.lastError
+}
+
+// route 路由中的提供商
+type route struct {
+	config   RouteConfig
+	provider AIProvider
+	models   []string
+	serving  map[string]bool
+	breaker  *circuitBreaker
+}
+
+// serves 是否可服务指定模型，model 为空时使用路由的默认模型
+func (r *route) serves(model string) bool {
+	return model == "" || r.serving[model]
+}
+
+// matches 是否包含全部标签
+func (r *route) matches(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, routeTag := range r.config.Tags {
+			if routeTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Router 多提供商路由，实现 AIProvider。按模型和标签筛选提供商，按权重随机排序，
+// 遇到可重试错误时转移到下一个提供商，连续失败的提供商熔断一段时间
+type Router struct {
+	routes []*route
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRouter 创建多提供商路由，factory 根据提供商名称创建提供商
+func NewRouter(routes []RouteConfig, breaker CircuitBreakerConfig, factory func(provider string) (AIProvider, error)) (*Router, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("at least one route is required")
+	}
+
+	router := &Router{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	for _, config := range routes {
+		provider, err := factory(config.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", routeName(config), err)
+		}
+		router.routes = append(router.routes, &route{
+			config:   config,
+			provider: provider,
+			breaker:  newCircuitBreaker(breaker),
+		})
+	}
+	return router, nil
+}
+
+// Initialize 使用路由的 apiKey、baseUrl、model 覆盖通用配置后初始化各提供商
+func (r *Router) Initialize(config *Config) error {
+	for _, rt := range r.routes {
+		routeConfig := *config
+		routeConfig.Provider = rt.config.Provider
+		routeConfig.APIKey = rt.config.APIKey
+		routeConfig.BaseURL = rt.config.BaseURL
+		routeConfig.Model = rt.config.Model
+		routeConfig.Routes = nil
+		if err := rt.provider.Initialize(&routeConfig); err != nil {
+			return fmt.Errorf("route %s: %w", routeName(rt.config), err)
+		}
+
+		// Ollama 等提供商的模型目录需要请求服务端，只在初始化时获取一次
+		rt.models = rt.config.Models
+		if len(rt.models) == 0 {
+			rt.models = rt.provider.GetModels()
+		}
+		rt.serving = make(map[string]bool, len(rt.models))
+		for _, model := range rt.models {
+			rt.serving[model] = true
+		}
+	}
+	return nil
+}
+
+// Validate 验证全部提供商
+func (r *Router) Validate() error {
+	for _, rt := range r.routes {
+		if err := rt.provider.Validate(); err != nil {
+			return fmt.Errorf("route %s: %w", routeName(rt.config), err)
+		}
+	}
+	return nil
+}
+
+// Chat 按路由顺序尝试提供商，可重试错误时转移到下一个
+func (r *Router) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	routes, err := r.candidates(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lastResp *ChatResponse
+		lastErr  = ErrNoAvailableProvider
+	)
+	for _, rt := range routes {
+		if !rt.breaker.allow() {
+			continue
+		}
+
+		resp, err := rt.provider.Chat(ctx, request)
+		if ctx.Err() != nil {
+			rt.breaker.release()
+			return resp, err
+		}
+		failure := failoverError(resp, err)
+		if failure == nil {
+			// 不可重试的错误（如参数错误）说明提供商可用
+			rt.breaker.success()
+			if resp != nil && resp.Error == nil {
+				if resp.Metadata == nil {
+					resp.Metadata = make(map[string]interface{})
+				}
+				resp.Metadata["provider"] = routeName(rt.config)
+			}
+			return resp, err
+		}
+
+		rt.breaker.failure(failure)
+		log.Printf("AI provider %s failed, trying next: %v", routeName(rt.config), failure)
+		lastResp, lastErr = resp, err
+	}
+
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	return nil, lastErr
+}
+
+// ChatStream 按路由顺序尝试提供商，只在开始输出前转移，输出过程中的错误计入熔断
+func (r *Router) ChatStream(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+	routes, err := r.candidates(request)
+	if err != nil {
+		return nil, err
+	}
+
+	lastErr := ErrNoAvailableProvider
+	for _, rt := range routes {
+		if !rt.breaker.allow() {
+			continue
+		}
+
+		chunks, err := rt.provider.ChatStream(ctx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				rt.breaker.release()
+				return nil, err
+			}
+			failure := failoverError(nil, err)
+			if failure == nil {
+				rt.breaker.success()
+				return nil, err
+			}
+			rt.breaker.failure(failure)
+			log.Printf("AI provider %s failed, trying next: %v", routeName(rt.config), failure)
+			lastErr = err
+			continue
+		}
+
+		return r.observeStream(ctx, rt, chunks), nil
+	}
+	return nil, lastErr
+}
+
+// observeStream 转发流式响应并在结束后记录熔断结果
+func (r *Router) observeStream(ctx context.Context, rt *route, chunks <-chan *ChatResponse) <-chan *ChatResponse {
+	var failure error
+	return relayStream(ctx, chunks, func(chunk *ChatResponse) []*ChatResponse {
+		if chunk.Error != nil && chunk.Error.Retryable {
+			failure = chunk.Error
+		}
+		if chunk.Done && chunk.Error == nil {
+			if chunk.Metadata == nil {
+				chunk.Metadata = make(map[string]interface{})
+			}
+			chunk.Metadata["provider"] = routeName(rt.config)
+		}
+		return []*ChatResponse{chunk}
+	}, func() []*ChatResponse {
+		switch {
+		case failure != nil:
+			rt.breaker.failure(failure)
+		case ctx.Err() != nil:
+			rt.breaker.release()
+		default:
+			rt.breaker.success()
+		}
+		return nil
+	})
+}
+
+// GetModels 全部提供商可服务的模型
+func (r *Router) GetModels() []string {
+	seen := make(map[string]bool)
+	var models []string
+	for _, rt := range r.routes {
+		for _, model := range rt.models {
+			if !seen[model] {
+				seen[model] = true
+				models = append(models, model)
+			}
+		}
+	}
+	return models
+}
+
+// GetModelInfo 获取模型信息，model 为空时使用第一个提供商的默认模型
+func (r *Router) GetModelInfo(model string) (*ModelInfo, error) {
+	for _, rt := range r.routes {
+		if rt.serves(model) {
+			return rt.provider.GetModelInfo(model)
+		}
+	}
+	return nil, fmt.Errorf("model not found: %s", model)
+}
+
+// GetUsage 汇总各提供商的使用统计，不支持查询的提供商跳过
+func (r *Router) GetUsage(ctx context.Context, startTime, endTime time.Time) (*Usage, error) {
+	total := &Usage{}
+	var lastErr error
+	supported := false
+	for _, rt := range r.routes {
+		usage, err := rt.provider.GetUsage(ctx, startTime, endTime)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		supported = true
+		total.PromptTokens += usage.PromptTokens
+		total.CompletionTokens += usage.CompletionTokens
+		total.TotalTokens += usage.TotalTokens
+		total.Cost += usage.Cost
+	}
+	if !supported {
+		return nil, lastErr
+	}
+	return total, nil
+}
+
+// GetCost 汇总各提供商的成本，不支持查询的提供商跳过
+func (r *Router) GetCost(ctx context.Context, startTime, endTime time.Time) (float64, error) {
+	usage, err := r.GetUsage(ctx, startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Cost, nil
+}
+
+// HealthCheck 检查全部提供商，全部不可用时返回错误，部分不可用只记录日志
+func (r *Router) HealthCheck(ctx context.Context) error {
+	var failed []string
+	health := r.Health(ctx)
+	for _, item := range health {
+		if !item.Healthy {
+			failed = append(failed, fmt.Sprintf("%s: %s", item.Name, item.Error))
+		}
+	}
+
+	if len(failed) == len(health) {
+		return fmt.Errorf("all ai providers are unhealthy: %s", strings.Join(failed, "; "))
+	}
+	if len(failed) > 0 {
+		log.Printf("AI providers degraded: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Health 并发检查各提供商，返回健康状态和熔断状态
+func (r *Router) Health(ctx context.Context) []ProviderHealth {
+	health := make([]ProviderHealth, len(r.routes))
+
+	var wg sync.WaitGroup
+	for i, rt := range r.routes {
+		wg.Add(1)
+		go func(i int, rt *route) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := rt.provider.HealthCheck(ctx)
+			health[i] = ProviderHealth{
+				Name:     routeName(rt.config),
+				Provider: rt.config.Provider,
+				Healthy:  err == nil,
+				Latency:  time.Since(start),
+			}
+			state, failures, lastError := rt.breaker.snapshot()
+			health[i].Circuit = state
+			health[i].Failures = failures
+			if err != nil {
+				health[i].Error = err.Error()
+			} else if state != CircuitClosed {
+				health[i].Error = lastError
+			}
+		}(i, rt)
+	}
+	wg.Wait()
+
+	return health
+}
+
+// ListModels 列出第一个支持模型管理的提供商（如 Ollama）的本地模型
+func (r *Router) ListModels(ctx context.Context) ([]LocalModel, error) {
+	manager, ok := r.modelManager()
+	if !ok {
+		return nil, ErrModelManageUnsupported
+	}
+	return manager.ListModels(ctx)
+}
+
+// PullModel 通过第一个支持模型管理的提供商下载模型
+func (r *Router) PullModel(ctx context.Context, name string, progress func(*PullProgress)) error {
+	manager, ok := r.modelManager()
+	if !ok {
+		return ErrModelManageUnsupported
+	}
+	return manager.PullModel(ctx, name, progress)
+}
+
+// 私有方法
+
+// candidates 按模型和标签筛选提供商，按权重随机排序
+func (r *Router) candidates(request *ChatRequest) ([]*route, error) {
+	var (
+		matched []*route
+		weights []int
+	)
+	for _, rt := range r.routes {
+		if rt.serves(request.Model) && rt.matches(request.Tags) {
+			matched = append(matched, rt)
+			weights = append(weights, max(rt.config.Weight, 1))
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%w: model %q, tags %v", ErrNoAvailableProvider, request.Model, request.Tags)
+	}
+
+	// 加权随机不放回抽样，权重越大越靠前
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := make([]*route, 0, len(matched))
+	for len(matched) > 0 {
+		total := 0
+		for _, weight := range weights {
+			total += weight
+		}
+		pick := r.rand.Intn(total)
+		i := 0
+		for ; pick >= weights[i]; i++ {
+			pick -= weights[i]
+		}
+		ordered = append(ordered, matched[i])
+		matched = append(matched[:i], matched[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return ordered, nil
+}
+
+// modelManager 第一个支持模型管理的提供商
+func (r *Router) modelManager() (ModelManager, bool) {
+	for _, rt := range r.routes {
+		if manager, ok := rt.provider.(ModelManager); ok {
+			return manager, true
+		}
+	}
+	return nil, false
+}
+
+// failoverError 判断是否需要转移到下一个提供商：可重试的 APIError 和网络错误需要转移，
+// 参数错误等不可重试错误直接返回
+func failoverError(resp *ChatResponse, err error) error {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.Retryable {
+			return nil
+		}
+		return err
+	}
+	if resp != nil && resp.Error != nil && resp.Error.Retryable {
+		return resp.Error
+	}
+	return nil
+}
+
+// routeName 路由名称
+func routeName(config RouteConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return config.Provider
+}
@@ -0,0 +1,274 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpstream 模拟的 OpenAI 兼容上游，status 非 200 时返回错误
+type fakeUpstream struct {
+	name   string
+	status atomic.Int32
+	calls  atomic.Int32
+	server *httptest.Server
+}
+
+// newFakeUpstream 创建模拟上游，回复内容为上游名称
+func newFakeUpstream(t *testing.T, name string) *fakeUpstream {
+	upstream := &fakeUpstream{name: name}
+	upstream.status.Store(http.StatusOK)
+	upstream.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			if status := int(upstream.status.Load()); status != http.StatusOK {
+				w.WriteHeader(status)
+			}
+			fmt.Fprint(w, `{"data":[]}`)
+			return
+		}
+
+		upstream.calls.Add(1)
+		if status := int(upstream.status.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"code":"upstream_error","message":"%s failed"}}`, name)
+			return
+		}
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"%s\"}}]}\n\ndata: [DONE]\n\n", name)
+			return
+		}
+		fmt.Fprintf(w, `{"model":"m","choices":[{"message":{"role":"assistant","content":"%s"},"finish_reason":"stop"}]}`, name)
+	}))
+	t.Cleanup(upstream.server.Close)
+	return upstream
+}
+
+// newTestRouter 以网关方式接入模拟上游并创建路由
+func newTestRouter(t *testing.T, routes []RouteConfig, upstreams ...*fakeUpstream) *Router {
+	config := DefaultConfig()
+	config.Enabled = true
+	config.Model = ""
+	config.Gateways = make(map[string]*GatewayConfig, len(upstreams))
+	for _, upstream := range upstreams {
+		config.Gateways[upstream.name] = &GatewayConfig{
+			BaseURL: upstream.server.URL,
+			Models:  []GatewayModel{{ID: upstream.name + "-model"}, {ID: "shared-model"}},
+		}
+	}
+	config.Routes = routes
+	config.CircuitBreaker = CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}
+	require.NoError(t, config.Validate())
+
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	router, ok := service.Provider().(*Router)
+	require.True(t, ok)
+	return router
+}
+
+func chatText(t *testing.T, provider AIProvider, request *ChatRequest) string {
+	if len(request.Messages) == 0 {
+		request.Messages = []Message{{Role: "user", Content: "hi"}}
+	}
+	resp, err := provider.Chat(context.Background(), request)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	return resp.Message.Content
+}
+
+func TestRouterFailover(t *testing.T) {
+	primary := newFakeUpstream(t, "primary")
+	backup := newFakeUpstream(t, "backup")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "primary", Weight: 1000000},
+		{Provider: "backup", Weight: 1},
+	}, primary, backup)
+
+	// 5xx 和 429 转移到下一个提供商
+	primary.status.Store(http.StatusServiceUnavailable)
+	resp, err := router.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "backup", resp.Message.Content)
+	assert.Equal(t, "backup", resp.Metadata["provider"])
+
+	primary.status.Store(http.StatusTooManyRequests)
+	assert.Equal(t, "backup", chatText(t, router, &ChatRequest{}))
+
+	// 连续失败达到阈值后熔断，不再请求主提供商
+	calls := primary.calls.Load()
+	assert.Equal(t, "backup", chatText(t, router, &ChatRequest{}))
+	assert.Equal(t, calls, primary.calls.Load())
+
+	// 参数错误不转移，原样返回
+	primary.status.Store(http.StatusOK)
+	backup.status.Store(http.StatusBadRequest)
+	resp, err = router.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Error.StatusCode)
+
+	// 全部不可用时返回最后一个错误
+	backup.status.Store(http.StatusBadGateway)
+	resp, err = router.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.True(t, resp.Error.Retryable)
+}
+
+func TestRouterCircuitBreaker(t *testing.T) {
+	primary := newFakeUpstream(t, "primary")
+	backup := newFakeUpstream(t, "backup")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "primary", Weight: 1000000},
+		{Provider: "backup", Weight: 1},
+	}, primary, backup)
+
+	now := time.Now()
+	breaker := router.routes[0].breaker
+	breaker.now = func() time.Time { return now }
+
+	primary.status.Store(http.StatusInternalServerError)
+	chatText(t, router, &ChatRequest{})
+	chatText(t, router, &ChatRequest{})
+	state, failures, _ := breaker.snapshot()
+	assert.Equal(t, CircuitOpen, state)
+	assert.Equal(t, 2, failures)
+
+	// 熔断超时后半开，探测失败重新熔断
+	now = now.Add(time.Minute)
+	calls := primary.calls.Load()
+	assert.Equal(t, "backup", chatText(t, router, &ChatRequest{}))
+	assert.Equal(t, calls+1, primary.calls.Load())
+	state, _, _ = breaker.snapshot()
+	assert.Equal(t, CircuitOpen, state)
+
+	// 探测成功后恢复
+	now = now.Add(time.Minute)
+	primary.status.Store(http.StatusOK)
+	assert.Equal(t, "primary", chatText(t, router, &ChatRequest{}))
+	state, failures, _ = breaker.snapshot()
+	assert.Equal(t, CircuitClosed, state)
+	assert.Zero(t, failures)
+}
+
+func TestRouterSelection(t *testing.T) {
+	cloud := newFakeUpstream(t, "cloud")
+	local := newFakeUpstream(t, "local")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "cloud", Tags: []string{"smart"}, Weight: 1},
+		{Name: "local-fast", Provider: "local", Models: []string{"local-model", "shared-model"}, Tags: []string{"fast", "private"}, Weight: 1},
+	}, cloud, local)
+
+	assert.Equal(t, []string{"cloud-model", "shared-model", "local-model"}, router.GetModels())
+
+	// 按模型选择
+	assert.Equal(t, "cloud", chatText(t, router, &ChatRequest{Model: "cloud-model"}))
+	assert.Equal(t, "local", chatText(t, router, &ChatRequest{Model: "local-model"}))
+
+	// 按标签选择，需包含全部标签
+	assert.Equal(t, "local", chatText(t, router, &ChatRequest{Tags: []string{"fast", "private"}}))
+	assert.Equal(t, "cloud", chatText(t, router, &ChatRequest{Model: "shared-model", Tags: []string{"smart"}}))
+
+	_, err := router.Chat(context.Background(), &ChatRequest{Model: "gpt-4"})
+	assert.ErrorIs(t, err, ErrNoAvailableProvider)
+	_, err = router.Chat(context.Background(), &ChatRequest{Model: "cloud-model", Tags: []string{"private"}})
+	assert.ErrorIs(t, err, ErrNoAvailableProvider)
+}
+
+func TestRouterWeights(t *testing.T) {
+	heavy := newFakeUpstream(t, "heavy")
+	light := newFakeUpstream(t, "light")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "heavy", Weight: 9},
+		{Provider: "light", Weight: 1},
+	}, heavy, light)
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		routes, err := router.candidates(&ChatRequest{})
+		require.NoError(t, err)
+		require.Len(t, routes, 2)
+		counts[routeName(routes[0].config)]++
+	}
+	assert.InDelta(t, 900, counts["heavy"], 60)
+	assert.InDelta(t, 100, counts["light"], 60)
+}
+
+func TestRouterStreamFailover(t *testing.T) {
+	primary := newFakeUpstream(t, "primary")
+	backup := newFakeUpstream(t, "backup")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "primary", Weight: 1000000},
+		{Provider: "backup", Weight: 1},
+	}, primary, backup)
+
+	chunks, err := router.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", collectDeltas(chunks))
+
+	// 开始输出前失败时转移
+	primary.status.Store(http.StatusServiceUnavailable)
+	chunks, err = router.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "backup", collectDeltas(chunks))
+	_, failures, _ := router.routes[0].breaker.snapshot()
+	assert.Equal(t, 1, failures)
+}
+
+func TestRouterHealth(t *testing.T) {
+	primary := newFakeUpstream(t, "primary")
+	backup := newFakeUpstream(t, "backup")
+	router := newTestRouter(t, []RouteConfig{
+		{Provider: "primary"},
+		{Provider: "backup"},
+	}, primary, backup)
+
+	primary.status.Store(http.StatusServiceUnavailable)
+	assert.NoError(t, router.HealthCheck(context.Background()))
+
+	health := router.Health(context.Background())
+	require.Len(t, health, 2)
+	assert.Equal(t, "primary", health[0].Name)
+	assert.False(t, health[0].Healthy)
+	assert.NotEmpty(t, health[0].Error)
+	assert.True(t, health[1].Healthy)
+	assert.Equal(t, CircuitClosed, health[1].Circuit)
+
+	// 全部不可用时健康检查失败
+	backup.status.Store(http.StatusServiceUnavailable)
+	assert.ErrorContains(t, router.HealthCheck(context.Background()), "all ai providers are unhealthy")
+
+	// 路由不包含 Ollama 时不支持模型管理
+	_, err := router.ListModels(context.Background())
+	assert.ErrorIs(t, err, ErrModelManageUnsupported)
+}
+
+func TestRouterConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	config.Provider = ""
+	config.Routes = []RouteConfig{{Provider: "ollama"}, {Provider: "ollama"}}
+	assert.ErrorContains(t, config.Validate(), "duplicate route name")
+
+	config.Routes[1].Name = "ollama-backup"
+	assert.NoError(t, config.Validate())
+
+	config.Routes[1].Weight = -1
+	assert.Error(t, config.Validate())
+
+	config.Routes = []RouteConfig{{Model: "qwen2.5"}}
+	assert.ErrorContains(t, config.Validate(), "provider is required")
+}
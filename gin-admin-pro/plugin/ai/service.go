@@ -2,24 +2,55 @@ package ai
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 )
 
+var (
+	// ErrServiceNotReady 服务未初始化或已停止
+	ErrServiceNotReady = errors.New("service not ready")
+	// ErrStreamingDisabled 未启用流式响应
+	ErrStreamingDisabled = errors.New("streaming is disabled")
+	// ErrImageInputDisabled 未启用图片输入
+	ErrImageInputDisabled = errors.New("image input is disabled")
+	// ErrConversationNotFound 对话不存在
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrMessageNotFound 消息不存在
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrRateLimited 用户请求过于频繁
+	ErrRateLimited = errors.New("ai request rate limited")
+	// ErrTokenQuotaExceeded 用户当天 token 用量已达上限
+	ErrTokenQuotaExceeded = errors.New("daily token quota exceeded")
+	// ErrCostQuotaExceeded 用户当天费用已达上限
+	ErrCostQuotaExceeded = errors.New("daily cost quota exceeded")
+	// ErrContentBlocked 提问包含被禁止的内容
+	ErrContentBlocked = errors.New("content blocked by filter")
+)
+
 // DefaultAIService 默认AI服务实现
 type DefaultAIService struct {
-	config        *Config
-	provider      AIProvider
-	cache         CacheService
-	metrics       MetricsService
-	conversations map[string]*Conversation
-	users         map[string]*UserStats
-
-	// 中间件
-	middlewares  []Middleware
-	errorHandler ErrorHandler
+	config   *Config
+	provider AIProvider
+	cache    ResponseCache
+	metrics  MetricsService
+	// embedder 语义缓存使用的向量模型
+	embedder Embedder
+	store    ConversationStore
+
+	// 中间件：内置中间件在 Initialize 时按配置创建，位于自定义中间件外层
+	builtinMiddlewares []Middleware
+	middlewares        []Middleware
+	errorHandler       ErrorHandler
+	limitStore         LimitStore
+	auditLogger        AuditLogger
+	usageRecorder      UsageRecorder
+
+	// 工具：启用函数调用时自动执行模型请求的已注册工具
+	tools *ToolRegistry
 
 	// 插件
 	plugins []Plugin
@@ -46,17 +77,26 @@ func NewDefaultAIService(config *Config) (*DefaultAIService, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	service := &DefaultAIService{
-		config:        config,
-		conversations: make(map[string]*Conversation),
-		users:         make(map[string]*UserStats),
-		middlewares:   []Middleware{},
-		plugins:       []Plugin{},
-		ctx:           ctx,
-		cancel:        cancel,
+		config:      config,
+		store:       NewMemoryConversationStore(),
+		middlewares: []Middleware{},
+		limitStore:  NewMemoryLimitStore(),
+		auditLogger: logAuditLogger{},
+		plugins:     []Plugin{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	// 创建AI提供商，配置了多提供商路由时由路由选择提供商
+	var (
+		provider AIProvider
+		err      error
+	)
+	if len(config.Routes) > 0 {
+		provider, err = NewRouter(config.Routes, config.CircuitBreaker, service.createProvider)
+	} else {
+		provider, err = service.createProvider(config.Provider)
 	}
-
-	// 创建AI提供商
-	provider, err := service.createProvider(config.Provider)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("create provider failed: %w", err)
@@ -69,9 +109,9 @@ func NewDefaultAIService(config *Config) (*DefaultAIService, error) {
 
 	service.provider = provider
 
-	// 创建缓存服务
+	// 创建缓存服务，多实例部署时通过 SetResponseCache 替换为共享缓存
 	if config.Cache.Enabled {
-		service.cache = NewMemoryCache(config.Cache)
+		service.cache = NewMemoryResponseCache(config.Cache.MaxSize)
 	}
 
 	// 创建指标服务
@@ -98,8 +138,13 @@ func (s *DefaultAIService) Initialize(config *Config) error {
 		}
 	}
 
+	s.builtinMiddlewares = s.buildMiddlewares()
 	s.ready = true
-	log.Printf("AI service initialized with provider: %s", config.Provider)
+	if len(config.Routes) > 0 {
+		log.Printf("AI service initialized with %d routed providers", len(config.Routes))
+	} else {
+		log.Printf("AI service initialized with provider: %s", config.Provider)
+	}
 
 	return nil
 }
@@ -159,7 +204,10 @@ func (s *DefaultAIService) IsReady() bool {
 // Chat 聊天接口
 func (s *DefaultAIService) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
 	if !s.IsReady() {
-		return nil, fmt.Errorf("service not ready")
+		return nil, ErrServiceNotReady
+	}
+	if !s.config.EnableImageInput && hasImages(request.Messages) {
+		return nil, ErrImageInputDisabled
 	}
 
 	// 记录请求开始
@@ -168,23 +216,27 @@ func (s *DefaultAIService) Chat(ctx context.Context, request *ChatRequest) (*Cha
 	// 执行中间件链
 	response, err := s.executeMiddleware(ctx, request, func(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 		// 检查缓存
-		if s.cache != nil {
-			cacheKey := s.generateCacheKey(req)
-			if cached, exists := s.cache.Get(cacheKey); exists {
-				if cachedResponse, ok := cached.(*ChatResponse); ok {
-					log.Printf("Cache hit for request: %s", cacheKey)
-					return cachedResponse, nil
-				}
-			}
+		lookup := s.lookupCache(ctx, req)
+		if lookup.response != nil {
+			return lookup.response, nil
 		}
 
-		// 执行AI提供商
-		resp, err := s.provider.Chat(ctx, req)
+		// 历史超出上下文窗口时裁剪或概括较早的消息
+		fitted, summaryUsage := s.fitContext(ctx, req)
+
+		// 执行AI提供商，模型调用已注册工具时执行工具后继续对话
+		resp, err := s.chatWithTools(ctx, fitted)
+		if resp != nil {
+			resp.Usage = addUsage(summaryUsage, resp.Usage)
+		}
 		if err != nil {
 			// 错误处理
 			if s.errorHandler != nil {
 				apiErr := s.errorHandler.Handle(ctx, err, req)
 				if apiErr != nil {
+					if resp == nil {
+						resp = &ChatResponse{Timestamp: time.Now()}
+					}
 					resp.Error = apiErr
 					return resp, nil
 				}
@@ -193,10 +245,7 @@ func (s *DefaultAIService) Chat(ctx context.Context, request *ChatRequest) (*Cha
 		}
 
 		// 缓存响应
-		if s.cache != nil && resp.Error == nil {
-			cacheKey := s.generateCacheKey(req)
-			s.cache.Set(cacheKey, resp, s.config.Cache.TTL)
-		}
+		s.storeCache(ctx, lookup, resp)
 
 		// 处理插件
 		if len(s.plugins) > 0 {
@@ -237,43 +286,40 @@ func (s *DefaultAIService) Chat(ctx context.Context, request *ChatRequest) (*Cha
 // ChatStream 流式聊天接口
 func (s *DefaultAIService) ChatStream(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
 	if !s.IsReady() {
-		return nil, fmt.Errorf("service not ready")
+		return nil, ErrServiceNotReady
 	}
 
 	if !s.config.EnableStreaming {
-		return nil, fmt.Errorf("streaming is disabled")
+		return nil, ErrStreamingDisabled
+	}
+	if !s.config.EnableImageInput && hasImages(request.Messages) {
+		return nil, ErrImageInputDisabled
 	}
 
 	// 启用流式响应
 	streamRequest := *request
 	streamRequest.Stream = true
 
-	return s.provider.ChatStream(ctx, &streamRequest)
+	return chainStream(s.currentMiddlewares(), s.streamWithContext)(ctx, &streamRequest)
 }
 
 // CreateConversation 创建对话
 func (s *DefaultAIService) CreateConversation(ctx context.Context, userID string, metadata map[string]interface{}) (*Conversation, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+	now := time.Now()
 	conversation := &Conversation{
 		ID:        s.generateID(),
 		UserID:    userID,
 		Title:     s.generateTitle(metadata),
 		Messages:  []Message{},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 		Metadata:  metadata,
-		Status:    "active",
+		Status:    ConversationStatusActive,
 	}
 
-	s.conversations[conversation.ID] = conversation
-
-	// 更新用户统计
-	s.updateUserStats(userID, func(stats *UserStats) {
-		stats.ConversationCount++
-		stats.LastActiveAt = time.Now()
-	})
+	if err := s.store.CreateConversation(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("create conversation failed: %w", err)
+	}
 
 	log.Printf("Created conversation %s for user %s", conversation.ID, userID)
 	return conversation, nil
@@ -281,96 +327,36 @@ func (s *DefaultAIService) CreateConversation(ctx context.Context, userID string
 
 // GetConversation 获取对话
 func (s *DefaultAIService) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return nil, fmt.Errorf("conversation not found: %s", conversationID)
-	}
-
-	return conversation, nil
+	return s.store.GetConversation(ctx, conversationID)
 }
 
 // UpdateConversation 更新对话
 func (s *DefaultAIService) UpdateConversation(ctx context.Context, conversationID string, metadata map[string]interface{}) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return fmt.Errorf("conversation not found: %s", conversationID)
-	}
-
-	if metadata != nil {
-		if conversation.Metadata == nil {
-			conversation.Metadata = make(map[string]interface{})
-		}
-		for k, v := range metadata {
-			conversation.Metadata[k] = v
-		}
+	if err := s.store.UpdateConversation(ctx, conversationID, metadata); err != nil {
+		return err
 	}
 
-	conversation.UpdatedAt = time.Now()
-
 	log.Printf("Updated conversation %s", conversationID)
 	return nil
 }
 
 // DeleteConversation 删除对话
 func (s *DefaultAIService) DeleteConversation(ctx context.Context, conversationID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return fmt.Errorf("conversation not found: %s", conversationID)
+	if err := s.store.DeleteConversation(ctx, conversationID); err != nil {
+		return err
 	}
 
-	// 标记为删除
-	conversation.Status = "deleted"
-	delete(s.conversations, conversationID)
-
 	log.Printf("Deleted conversation %s", conversationID)
 	return nil
 }
 
-// ListConversations 列出对话
+// ListConversations 列出对话，按更新时间倒序
 func (s *DefaultAIService) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var userConversations []*Conversation
-	for _, conversation := range s.conversations {
-		if conversation.UserID == userID && conversation.Status == "active" {
-			userConversations = append(userConversations, conversation)
-		}
-	}
-
-	// 简单分页（实际应该按时间排序）
-	total := len(userConversations)
-	if offset >= total {
-		return []*Conversation{}, nil
-	}
-
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-
-	return userConversations[offset:end], nil
+	return s.store.ListConversations(ctx, userID, limit, offset)
 }
 
 // AddMessage 添加消息
 func (s *DefaultAIService) AddMessage(ctx context.Context, conversationID string, message *Message) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return fmt.Errorf("conversation not found: %s", conversationID)
-	}
-
 	// 设置消息ID和时间戳
 	if message.ID == "" {
 		message.ID = s.generateID()
@@ -379,159 +365,122 @@ func (s *DefaultAIService) AddMessage(ctx context.Context, conversationID string
 		message.Timestamp = time.Now()
 	}
 
-	conversation.Messages = append(conversation.Messages, *message)
-	conversation.UpdatedAt = time.Now()
-
-	// 更新统计
-	conversation.TotalMessages++
-	conversation.TotalTokens += message.TokenUsed
-
-	// 更新用户统计
-	s.updateUserStats(conversation.UserID, func(stats *UserStats) {
-		stats.TotalMessages++
-		stats.TotalTokens += message.TokenUsed
-		stats.LastActiveAt = time.Now()
-	})
+	if err := s.store.AddMessage(ctx, conversationID, message); err != nil {
+		return err
+	}
 
 	log.Printf("Added message %s to conversation %s", message.ID, conversationID)
 	return nil
 }
 
-// GetMessages 获取消息
+// GetMessages 获取消息，按发送顺序分页
 func (s *DefaultAIService) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return nil, fmt.Errorf("conversation not found: %s", conversationID)
-	}
-
-	// 构建消息指针列表
-	messages := make([]*Message, len(conversation.Messages))
-	for i := range conversation.Messages {
-		messages[i] = &conversation.Messages[i]
-	}
-
-	// 简单分页
-	total := len(messages)
-	if offset >= total {
-		return []*Message{}, nil
-	}
-
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-
-	return messages[offset:end], nil
+	return s.store.GetMessages(ctx, conversationID, limit, offset)
 }
 
 // DeleteMessage 删除消息
 func (s *DefaultAIService) DeleteMessage(ctx context.Context, conversationID, messageID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	conversation, exists := s.conversations[conversationID]
-	if !exists {
-		return fmt.Errorf("conversation not found: %s", conversationID)
+	if err := s.store.DeleteMessage(ctx, conversationID, messageID); err != nil {
+		return err
 	}
 
-	// 查找并删除消息
-	for i, message := range conversation.Messages {
-		if message.ID == messageID {
-			conversation.Messages = append(conversation.Messages[:i], conversation.Messages[i+1:]...)
-			conversation.UpdatedAt = time.Now()
-			log.Printf("Deleted message %s from conversation %s", messageID, conversationID)
-			return nil
-		}
-	}
-
-	return fmt.Errorf("message not found: %s", messageID)
+	log.Printf("Deleted message %s from conversation %s", messageID, conversationID)
+	return nil
 }
 
-// SearchConversations 搜索对话
+// SearchConversations 按标题和消息内容搜索对话
 func (s *DefaultAIService) SearchConversations(ctx context.Context, userID, query string, limit, offset int) ([]*Conversation, error) {
-	// 简化实现，实际应该使用搜索引擎
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var results []*Conversation
-	for _, conversation := range s.conversations {
-		if conversation.UserID == userID && conversation.Status == "active" {
-			// 简单的标题匹配
-			if query == "" || contains(conversation.Title, query) {
-				results = append(results, conversation)
-			}
-		}
-	}
-
-	// 分页
-	total := len(results)
-	if offset >= total {
-		return []*Conversation{}, nil
-	}
-
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-
-	return results[offset:end], nil
+	return s.store.SearchConversations(ctx, userID, query, limit, offset)
 }
 
 // GetUserStats 获取用户统计
 func (s *DefaultAIService) GetUserStats(ctx context.Context, userID string) (*UserStats, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	return s.store.GetUserStats(ctx, userID)
+}
 
-	stats, exists := s.users[userID]
-	if !exists {
-		return &UserStats{
-			UserID: userID,
-		}, nil
+// GetSystemStats 获取系统统计，24小时内有对话更新的用户计为活跃用户
+func (s *DefaultAIService) GetSystemStats(ctx context.Context) (*SystemStats, error) {
+	stats, err := s.store.GetSystemStats(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
 	}
-
+	if s.cache != nil {
+		cacheStats, err := s.cache.Stats(ctx)
+		if err != nil {
+			log.Printf("AI cache stats failed: %v", err)
+		}
+		stats.Cache = cacheStats
+	}
+	stats.Timestamp = time.Now()
 	return stats, nil
 }
 
-// GetSystemStats 获取系统统计
-func (s *DefaultAIService) GetSystemStats(ctx context.Context) (*SystemStats, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// SetConversationStore 设置对话存储，需在处理请求前调用
+func (s *DefaultAIService) SetConversationStore(store ConversationStore) {
+	s.store = store
+}
 
-	stats := &SystemStats{
-		TotalUsers:         len(s.users),
-		TotalConversations: 0,
-		TotalMessages:      0,
-		TotalTokens:        0,
-		Timestamp:          time.Now(),
-	}
+// SetLimitStore 设置限流和配额计数存储，需在 Initialize 之前调用，多实例部署时应使用共享存储
+func (s *DefaultAIService) SetLimitStore(store LimitStore) {
+	s.limitStore = store
+}
 
-	for _, conversation := range s.conversations {
-		if conversation.Status == "active" {
-			stats.TotalConversations++
-			stats.TotalMessages += conversation.TotalMessages
-			stats.TotalTokens += conversation.TotalTokens
-		}
+// SetResponseCache 设置响应缓存，需在 Initialize 之前调用，多实例部署时应使用共享缓存。
+// 未启用缓存配置时不生效
+func (s *DefaultAIService) SetResponseCache(cache ResponseCache) {
+	if s.config.Cache.Enabled {
+		s.cache = cache
 	}
+}
 
-	// 计算活跃用户数
-	activeThreshold := time.Now().Add(-24 * time.Hour)
-	for _, userStats := range s.users {
-		if userStats.LastActiveAt.After(activeThreshold) {
-			stats.ActiveUsers++
-		}
-	}
+// SetEmbedder 设置语义缓存使用的向量模型，需在 Initialize 之前调用
+func (s *DefaultAIService) SetEmbedder(embedder Embedder) {
+	s.embedder = embedder
+}
 
-	return stats, nil
+// SetUsageRecorder 设置用量记录器，需在 Initialize 之前调用，设置后记录每次模型调用的用量
+func (s *DefaultAIService) SetUsageRecorder(recorder UsageRecorder) {
+	s.usageRecorder = recorder
 }
 
-// 添加中间件
+// SetAuditLogger 设置审计日志记录器，需在 Initialize 之前调用
+func (s *DefaultAIService) SetAuditLogger(logger AuditLogger) {
+	s.auditLogger = logger
+}
+
+// AddMiddleware 添加自定义中间件，按添加顺序由外向内执行
 func (s *DefaultAIService) AddMiddleware(middleware Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.middlewares = append(s.middlewares, middleware)
 }
 
+// Provider 获取当前模型提供商
+func (s *DefaultAIService) Provider() AIProvider {
+	return s.provider
+}
+
+// ProviderHealth 检查提供商健康状态，配置多提供商路由时返回每个提供商的状态和熔断状态
+func (s *DefaultAIService) ProviderHealth(ctx context.Context) []ProviderHealth {
+	if router, ok := s.provider.(*Router); ok {
+		return router.Health(ctx)
+	}
+
+	start := time.Now()
+	err := s.provider.HealthCheck(ctx)
+	health := ProviderHealth{
+		Name:     s.config.Provider,
+		Provider: s.config.Provider,
+		Healthy:  err == nil,
+		Circuit:  CircuitClosed,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return []ProviderHealth{health}
+}
+
 // 设置错误处理器
 func (s *DefaultAIService) SetErrorHandler(handler ErrorHandler) {
 	s.errorHandler = handler
@@ -559,30 +508,175 @@ func (s *DefaultAIService) createProvider(provider string) (AIProvider, error) {
 	case "ollama":
 		return NewOllamaProvider(), nil
 	default:
+		if gateway, exists := s.config.Gateways[provider]; exists && gateway != nil {
+			return NewGatewayProvider(provider, gateway), nil
+		}
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
 }
 
 // executeMiddleware 执行中间件链
-func (s *DefaultAIService) executeMiddleware(ctx context.Context, request *ChatRequest, handler func(context.Context, *ChatRequest) (*ChatResponse, error)) (*ChatResponse, error) {
-	// 如果没有中间件，直接执行处理器
-	if len(s.middlewares) == 0 {
-		return handler(ctx, request)
+func (s *DefaultAIService) executeMiddleware(ctx context.Context, request *ChatRequest, handler ChatHandler) (*ChatResponse, error) {
+	return chainChat(s.currentMiddlewares(), handler)(ctx, request)
+}
+
+// currentMiddlewares 返回内置中间件和自定义中间件，审计位于最外层以记录被拦截的请求
+func (s *DefaultAIService) currentMiddlewares() []Middleware {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	middlewares := make([]Middleware, 0, len(s.builtinMiddlewares)+len(s.middlewares))
+	middlewares = append(middlewares, s.builtinMiddlewares...)
+	return append(middlewares, s.middlewares...)
+}
+
+// buildMiddlewares 按配置创建内置中间件：审计、内容过滤、限流、配额、用量计量
+func (s *DefaultAIService) buildMiddlewares() []Middleware {
+	var middlewares []Middleware
+	if s.config.Audit.Enabled {
+		middlewares = append(middlewares, NewAuditMiddleware(s.auditLogger, s.config.Audit.MaxContentLength))
+	}
+	if s.config.ContentFilter.Enabled && len(s.config.ContentFilter.Keywords) > 0 {
+		middlewares = append(middlewares, NewContentFilterMiddleware(s.config.ContentFilter.Keywords, s.config.ContentFilter.Replacement))
+	}
+	if s.config.RateLimit.Enabled {
+		middlewares = append(middlewares, NewRateLimitMiddleware(s.limitStore, s.config.RateLimit.Requests, s.config.RateLimit.Window))
 	}
 
-	// 简化实现：直接执行处理器
-	return handler(ctx, request)
+	costLimit := 0.0
+	if s.config.EnableCostLimit {
+		costLimit = s.config.DailyCostLimit
+	}
+	if s.config.DailyTokenLimit > 0 || costLimit > 0 {
+		middlewares = append(middlewares, NewQuotaMiddleware(s.limitStore, s.config.DailyTokenLimit, costLimit, s.modelInfo))
+	}
+	if s.usageRecorder != nil {
+		middlewares = append(middlewares, NewUsageMiddleware(s.usageRecorder, s.config.Provider, s.config.Model, s.modelInfo))
+	}
+	return middlewares
+}
+
+// modelInfo 获取模型信息，model 为空时使用默认模型，用于按单价计算费用
+func (s *DefaultAIService) modelInfo(model string) (*ModelInfo, error) {
+	if model == "" {
+		model = s.config.Model
+	}
+	return s.provider.GetModelInfo(model)
 }
 
-// generateCacheKey 生成缓存键
-func (s *DefaultAIService) generateCacheKey(request *ChatRequest) string {
-	// 简化实现，实际应该包含请求的哈希
-	return fmt.Sprintf("chat:%s:%s", request.Model, request.SystemPrompt)
+// cacheLookup 一次缓存查询的结果，未命中时保留缓存键和提问向量用于写入缓存
+type cacheLookup struct {
+	key      string
+	scope    string
+	vector   []float32
+	response *ChatResponse
 }
 
-// generateID 生成唯一ID
+// lookupCache 先按请求哈希精确匹配，未命中且启用语义缓存时按提问向量匹配上下文相同的请求。
+// 缓存读取失败不影响对话，按未命中处理
+func (s *DefaultAIService) lookupCache(ctx context.Context, req *ChatRequest) *cacheLookup {
+	lookup := &cacheLookup{}
+	if s.cache == nil || req.Stream {
+		return lookup
+	}
+
+	model := s.getModel(req)
+	lookup.key = CacheKey(model, req)
+	if cached, err := s.cache.Get(ctx, lookup.key); err != nil {
+		log.Printf("AI cache get failed: %v", err)
+	} else if cached != nil {
+		lookup.response = cachedResponse(cached, CacheHit)
+		s.recordCache(ctx, CacheHit)
+		return lookup
+	}
+
+	if s.config.Cache.Semantic.Enabled && s.embedder != nil {
+		scope, question := cacheScope(model, req)
+		if question != "" {
+			vectors, err := s.embedder.Embed(ctx, []string{question})
+			if err != nil {
+				log.Printf("AI cache embed failed: %v", err)
+			} else {
+				lookup.scope, lookup.vector = scope, vectors[0]
+				if response := s.nearestCached(ctx, scope, lookup.vector); response != nil {
+					lookup.response = cachedResponse(response, CacheSemanticHit)
+					s.recordCache(ctx, CacheSemanticHit)
+					return lookup
+				}
+			}
+		}
+	}
+
+	s.recordCache(ctx, CacheMiss)
+	return lookup
+}
+
+// nearestCached 查找语义相近且仍在缓存中的回复
+func (s *DefaultAIService) nearestCached(ctx context.Context, scope string, vector []float32) *ChatResponse {
+	key, score, err := s.cache.Nearest(ctx, scope, vector)
+	if err != nil {
+		log.Printf("AI semantic cache search failed: %v", err)
+		return nil
+	}
+	if key == "" || score < s.config.Cache.Semantic.Threshold {
+		return nil
+	}
+	cached, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("AI cache get failed: %v", err)
+		return nil
+	}
+	return cached
+}
+
+// storeCache 缓存成功的回复；执行过工具的回复包含按调用用户权限查询的数据，不缓存
+func (s *DefaultAIService) storeCache(ctx context.Context, lookup *cacheLookup, resp *ChatResponse) {
+	if lookup.key == "" || resp.Error != nil {
+		return
+	}
+	if steps, _ := resp.Metadata["toolSteps"].([]FunctionCall); len(steps) > 0 {
+		return
+	}
+
+	ttl := s.config.Cache.TTL
+	if err := s.cache.Set(ctx, lookup.key, resp, ttl); err != nil {
+		log.Printf("AI cache set failed: %v", err)
+		return
+	}
+	if lookup.vector != nil {
+		if err := s.cache.AddVector(ctx, lookup.scope, lookup.key, lookup.vector, ttl); err != nil {
+			log.Printf("AI semantic cache add failed: %v", err)
+		}
+	}
+}
+
+// recordCache 记录缓存命中统计
+func (s *DefaultAIService) recordCache(ctx context.Context, result CacheResult) {
+	if err := s.cache.Record(ctx, result); err != nil {
+		log.Printf("AI cache record failed: %v", err)
+	}
+}
+
+// cachedResponse 复制缓存的回复，命中缓存不消耗 token，用量按 0 计入配额
+func cachedResponse(cached *ChatResponse, result CacheResult) *ChatResponse {
+	response := *cached
+	response.Usage = Usage{}
+	response.Timestamp = time.Now()
+	response.Metadata = make(map[string]interface{}, len(cached.Metadata)+1)
+	for key, value := range cached.Metadata {
+		response.Metadata[key] = value
+	}
+	response.Metadata["cache"] = string(result)
+	return &response
+}
+
+// generateID 生成唯一ID：毫秒时间戳前缀保证大致有序，随机后缀避免多实例冲突
 func (s *DefaultAIService) generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%012x%x", time.Now().UnixMilli(), suffix)
 }
 
 // generateTitle 生成对话标题
@@ -612,18 +706,6 @@ func (s *DefaultAIService) getStatus(response *ChatResponse, err error) string {
 	return "success"
 }
 
-// updateUserStats 更新用户统计
-func (s *DefaultAIService) updateUserStats(userID string, updateFunc func(*UserStats)) {
-	stats, exists := s.users[userID]
-	if !exists {
-		stats = &UserStats{
-			UserID: userID,
-		}
-		s.users[userID] = stats
-	}
-	updateFunc(stats)
-}
-
 // backgroundTasks 后台任务
 func (s *DefaultAIService) backgroundTasks() {
 	defer s.wg.Done()
@@ -643,36 +725,3 @@ func (s *DefaultAIService) backgroundTasks() {
 		}
 	}
 }
-
-// contains 字符串包含检查
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
-		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-			func() bool {
-				for i := 0; i <= len(s)-len(substr); i++ {
-					if s[i:i+len(substr)] == substr {
-						return true
-					}
-				}
-				return false
-			}()))
-}
-
-// middlewareAdapter 中间件适配器
-type middlewareAdapter struct {
-	handler func(context.Context, *ChatRequest) (*ChatResponse, error)
-}
-
-func (a *middlewareAdapter) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
-	return a.handler(ctx, request)
-}
-
-// middlewareWrapper 中间件包装器
-type middlewareWrapper struct {
-	middleware Middleware
-	next       AIService
-}
-
-func (w *middlewareWrapper) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
-	return w.middleware.Process(ctx, request, w.next)
-}
@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// 对话状态
+const (
+	ConversationStatusActive   = "active"
+	ConversationStatusArchived = "archived"
+)
+
+// ConversationStore 对话存储接口，DefaultAIService 通过它持久化对话和消息。
+// 默认使用内存实现，生产环境使用 MongoDB 实现以便重启后保留、多实例共享。
+type ConversationStore interface {
+	// CreateConversation 保存新对话，ID 由调用方生成
+	CreateConversation(ctx context.Context, conversation *Conversation) error
+	// GetConversation 获取对话及其全部消息，不存在时返回 ErrConversationNotFound
+	GetConversation(ctx context.Context, conversationID string) (*Conversation, error)
+	// UpdateConversation 合并元数据并刷新更新时间
+	UpdateConversation(ctx context.Context, conversationID string, metadata map[string]interface{}) error
+	// SaveSummary 保存对话摘要，替换之前的摘要
+	SaveSummary(ctx context.Context, conversationID string, summary *ConversationSummary) error
+	// DeleteConversation 删除对话及其消息
+	DeleteConversation(ctx context.Context, conversationID string) error
+	// ListConversations 按更新时间倒序列出用户的对话（不含消息）
+	ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error)
+	// SearchConversations 按标题和消息内容搜索用户的对话（不含消息）
+	SearchConversations(ctx context.Context, userID, query string, limit, offset int) ([]*Conversation, error)
+
+	// AddMessage 追加消息并累加对话的消息数和 token 数，消息 ID 和时间由调用方设置
+	AddMessage(ctx context.Context, conversationID string, message *Message) error
+	// GetMessages 按发送顺序分页获取消息
+	GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error)
+	// DeleteMessage 删除消息并清除对话摘要（摘要可能包含已删除的内容），不存在时返回 ErrMessageNotFound
+	DeleteMessage(ctx context.Context, conversationID, messageID string) error
+
+	// GetUserStats 统计用户的对话使用情况
+	GetUserStats(ctx context.Context, userID string) (*UserStats, error)
+	// GetSystemStats 统计全部对话使用情况，activeSince 之后有更新的用户计为活跃用户
+	GetSystemStats(ctx context.Context, activeSince time.Time) (*SystemStats, error)
+}
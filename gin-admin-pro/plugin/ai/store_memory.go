@@ -0,0 +1,299 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryConversationStore 内存对话存储，进程重启后丢失，用于测试和单机开发
+type MemoryConversationStore struct {
+	mu            sync.RWMutex
+	conversations map[string]*Conversation
+}
+
+// NewMemoryConversationStore 创建内存对话存储
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{
+		conversations: make(map[string]*Conversation),
+	}
+}
+
+// CreateConversation 保存新对话
+func (s *MemoryConversationStore) CreateConversation(ctx context.Context, conversation *Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.conversations[conversation.ID]; exists {
+		return fmt.Errorf("conversation already exists: %s", conversation.ID)
+	}
+	s.conversations[conversation.ID] = copyConversation(conversation, true)
+	return nil
+}
+
+// GetConversation 获取对话及其全部消息
+func (s *MemoryConversationStore) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	return copyConversation(conversation, true), nil
+}
+
+// UpdateConversation 合并元数据并刷新更新时间
+func (s *MemoryConversationStore) UpdateConversation(ctx context.Context, conversationID string, metadata map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+
+	if len(metadata) > 0 {
+		if conversation.Metadata == nil {
+			conversation.Metadata = make(map[string]interface{}, len(metadata))
+		}
+		for k, v := range metadata {
+			conversation.Metadata[k] = v
+		}
+	}
+	conversation.UpdatedAt = time.Now()
+	return nil
+}
+
+// SaveSummary 保存对话摘要
+func (s *MemoryConversationStore) SaveSummary(ctx context.Context, conversationID string, summary *ConversationSummary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	copied := *summary
+	conversation.Summary = &copied
+	return nil
+}
+
+// DeleteConversation 删除对话及其消息
+func (s *MemoryConversationStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.conversations[conversationID]; !exists {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	delete(s.conversations, conversationID)
+	return nil
+}
+
+// ListConversations 按更新时间倒序列出用户的对话
+func (s *MemoryConversationStore) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
+	return s.filterConversations(userID, limit, offset, func(*Conversation) bool { return true }), nil
+}
+
+// SearchConversations 按标题和消息内容搜索用户的对话，忽略大小写
+func (s *MemoryConversationStore) SearchConversations(ctx context.Context, userID, query string, limit, offset int) ([]*Conversation, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	return s.filterConversations(userID, limit, offset, func(conversation *Conversation) bool {
+		if query == "" || strings.Contains(strings.ToLower(conversation.Title), query) {
+			return true
+		}
+		for _, message := range conversation.Messages {
+			if strings.Contains(strings.ToLower(message.Content), query) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// AddMessage 追加消息并累加统计
+func (s *MemoryConversationStore) AddMessage(ctx context.Context, conversationID string, message *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+
+	conversation.Messages = append(conversation.Messages, copyMessage(*message))
+	conversation.TotalMessages++
+	conversation.TotalTokens += message.TokenUsed
+	conversation.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetMessages 按发送顺序分页获取消息
+func (s *MemoryConversationStore) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+
+	start, end := pageBounds(len(conversation.Messages), limit, offset)
+	messages := make([]*Message, 0, end-start)
+	for _, message := range conversation.Messages[start:end] {
+		copied := copyMessage(message)
+		messages = append(messages, &copied)
+	}
+	return messages, nil
+}
+
+// DeleteMessage 删除消息并扣减统计
+func (s *MemoryConversationStore) DeleteMessage(ctx context.Context, conversationID, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, exists := s.conversations[conversationID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+
+	for i, message := range conversation.Messages {
+		if message.ID == messageID {
+			conversation.Messages = append(conversation.Messages[:i], conversation.Messages[i+1:]...)
+			conversation.TotalMessages--
+			conversation.TotalTokens -= message.TokenUsed
+			conversation.Summary = nil
+			conversation.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMessageNotFound, messageID)
+}
+
+// GetUserStats 统计用户的对话使用情况
+func (s *MemoryConversationStore) GetUserStats(ctx context.Context, userID string) (*UserStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &UserStats{UserID: userID}
+	for _, conversation := range s.conversations {
+		if conversation.UserID != userID {
+			continue
+		}
+		stats.ConversationCount++
+		stats.TotalMessages += conversation.TotalMessages
+		stats.TotalTokens += conversation.TotalTokens
+		if conversation.UpdatedAt.After(stats.LastActiveAt) {
+			stats.LastActiveAt = conversation.UpdatedAt
+		}
+	}
+	return stats, nil
+}
+
+// GetSystemStats 统计全部对话使用情况
+func (s *MemoryConversationStore) GetSystemStats(ctx context.Context, activeSince time.Time) (*SystemStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &SystemStats{}
+	users := make(map[string]bool)
+	for _, conversation := range s.conversations {
+		active := conversation.UpdatedAt.After(activeSince)
+		users[conversation.UserID] = users[conversation.UserID] || active
+
+		if conversation.Status != ConversationStatusActive {
+			continue
+		}
+		stats.TotalConversations++
+		stats.TotalMessages += conversation.TotalMessages
+		stats.TotalTokens += conversation.TotalTokens
+	}
+
+	stats.TotalUsers = len(users)
+	for _, active := range users {
+		if active {
+			stats.ActiveUsers++
+		}
+	}
+	return stats, nil
+}
+
+// filterConversations 筛选用户的活跃对话，按更新时间倒序分页，返回不含消息的副本
+func (s *MemoryConversationStore) filterConversations(userID string, limit, offset int, match func(*Conversation) bool) []*Conversation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*Conversation
+	for _, conversation := range s.conversations {
+		if conversation.UserID == userID && conversation.Status == ConversationStatusActive && match(conversation) {
+			matched = append(matched, conversation)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	start, end := pageBounds(len(matched), limit, offset)
+	result := make([]*Conversation, 0, end-start)
+	for _, conversation := range matched[start:end] {
+		result = append(result, copyConversation(conversation, false))
+	}
+	return result
+}
+
+// pageBounds 计算分页区间，limit <= 0 表示不限制
+func pageBounds(total, limit, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return total, total
+	}
+	if limit <= 0 || limit > total-offset {
+		return offset, total
+	}
+	return offset, offset + limit
+}
+
+// copyConversation 复制对话，避免调用方修改存储中的数据
+func copyConversation(conversation *Conversation, withMessages bool) *Conversation {
+	copied := *conversation
+	copied.Metadata = copyMetadata(conversation.Metadata)
+	if conversation.Summary != nil {
+		summary := *conversation.Summary
+		copied.Summary = &summary
+	}
+	copied.Messages = []Message{}
+	if withMessages {
+		copied.Messages = make([]Message, len(conversation.Messages))
+		for i, message := range conversation.Messages {
+			copied.Messages[i] = copyMessage(message)
+		}
+	}
+	return &copied
+}
+
+// copyMessage 复制消息
+func copyMessage(message Message) Message {
+	message.Metadata = copyMetadata(message.Metadata)
+	return message
+}
+
+// copyMetadata 浅复制元数据
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,493 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gin-admin-pro/plugin/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDB 集合名
+const (
+	ConversationCollection = "ai_conversations"
+	MessageCollection      = "ai_messages"
+)
+
+// conversationDoc 对话文档，消息单独存放在消息集合
+type conversationDoc struct {
+	ID            string                 `bson:"_id"`
+	UserID        string                 `bson:"userId"`
+	Title         string                 `bson:"title"`
+	Status        string                 `bson:"status"`
+	Metadata      map[string]interface{} `bson:"metadata,omitempty"`
+	TotalMessages int                    `bson:"totalMessages"`
+	TotalTokens   int                    `bson:"totalTokens"`
+	MessageSeq    int64                  `bson:"messageSeq"` // 消息序号，只增不减，保证消息顺序
+	Summary       *summaryDoc            `bson:"summary,omitempty"`
+	CreatedAt     time.Time              `bson:"createdAt"`
+	UpdatedAt     time.Time              `bson:"updatedAt"`
+}
+
+// summaryDoc 对话摘要
+type summaryDoc struct {
+	Content       string    `bson:"content"`
+	LastMessageID string    `bson:"lastMessageId"`
+	MessageCount  int       `bson:"messageCount"`
+	UpdatedAt     time.Time `bson:"updatedAt"`
+}
+
+// messageDoc 消息文档
+type messageDoc struct {
+	ID             string                 `bson:"_id"`
+	ConversationID string                 `bson:"conversationId"`
+	UserID         string                 `bson:"userId"` // 冗余对话所属用户，用于按用户搜索消息
+	Seq            int64                  `bson:"seq"`
+	Role           string                 `bson:"role"`
+	Content        string                 `bson:"content"`
+	Timestamp      time.Time              `bson:"timestamp"`
+	TokenUsed      int                    `bson:"tokenUsed"`
+	Metadata       map[string]interface{} `bson:"metadata,omitempty"`
+	ImageURL       string                 `bson:"imageUrl,omitempty"`
+	AudioURL       string                 `bson:"audioUrl,omitempty"`
+	FunctionCall   *FunctionCall          `bson:"functionCall,omitempty"`
+}
+
+// MongoConversationStore MongoDB 对话存储
+type MongoConversationStore struct {
+	conversations *mongo.Collection
+	messages      *mongo.Collection
+}
+
+// NewMongoConversationStore 创建 MongoDB 对话存储并确保索引存在
+func NewMongoConversationStore(ctx context.Context, client *mongodb.Client) (*MongoConversationStore, error) {
+	store := &MongoConversationStore{
+		conversations: client.GetCollection(ConversationCollection),
+		messages:      client.GetCollection(MessageCollection),
+	}
+	if err := store.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("create ai conversation indexes failed: %w", err)
+	}
+	return store, nil
+}
+
+// EnsureIndexes 创建索引：
+// 对话集合按 userId+updatedAt 列表、按 updatedAt 统计活跃用户、按标题全文搜索；
+// 消息集合按 conversationId+seq 分页、按消息内容全文搜索。
+func (s *MongoConversationStore) EnsureIndexes(ctx context.Context) error {
+	// 全文索引不使用语言词干，language_override 指向不存在的字段避免文档字段干扰
+	textOptions := func(name string) *options.IndexOptions {
+		return options.Index().SetName(name).SetDefaultLanguage("none").SetLanguageOverride("textLanguage")
+	}
+
+	if _, err := s.conversations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "updatedAt", Value: -1}}, Options: options.Index().SetName("idx_user_updated")},
+		{Keys: bson.D{{Key: "updatedAt", Value: -1}}, Options: options.Index().SetName("idx_updated")},
+		{Keys: bson.D{{Key: "title", Value: "text"}}, Options: textOptions("idx_title_text")},
+	}); err != nil {
+		return err
+	}
+
+	_, err := s.messages.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "conversationId", Value: 1}, {Key: "seq", Value: 1}}, Options: options.Index().SetName("idx_conversation_seq").SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetName("idx_user")},
+		{Keys: bson.D{{Key: "content", Value: "text"}}, Options: textOptions("idx_content_text")},
+	})
+	return err
+}
+
+// CreateConversation 保存新对话
+func (s *MongoConversationStore) CreateConversation(ctx context.Context, conversation *Conversation) error {
+	doc := conversationDoc{
+		ID:            conversation.ID,
+		UserID:        conversation.UserID,
+		Title:         conversation.Title,
+		Status:        conversation.Status,
+		Metadata:      conversation.Metadata,
+		TotalMessages: conversation.TotalMessages,
+		TotalTokens:   conversation.TotalTokens,
+		CreatedAt:     conversation.CreatedAt,
+		UpdatedAt:     conversation.UpdatedAt,
+	}
+	_, err := s.conversations.InsertOne(ctx, doc)
+	return err
+}
+
+// GetConversation 获取对话及其全部消息
+func (s *MongoConversationStore) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	doc, err := s.findConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.findMessages(ctx, conversationID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation := doc.toConversation()
+	conversation.Messages = make([]Message, len(messages))
+	for i, message := range messages {
+		conversation.Messages[i] = *message
+	}
+	return conversation, nil
+}
+
+// UpdateConversation 合并元数据并刷新更新时间
+func (s *MongoConversationStore) UpdateConversation(ctx context.Context, conversationID string, metadata map[string]interface{}) error {
+	set := bson.M{"updatedAt": time.Now()}
+	for k, v := range metadata {
+		set["metadata."+k] = v
+	}
+
+	result, err := s.conversations.UpdateByID(ctx, conversationID, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	return nil
+}
+
+// SaveSummary 保存对话摘要，不刷新对话的更新时间
+func (s *MongoConversationStore) SaveSummary(ctx context.Context, conversationID string, summary *ConversationSummary) error {
+	result, err := s.conversations.UpdateByID(ctx, conversationID, bson.M{"$set": bson.M{"summary": summaryDoc{
+		Content:       summary.Content,
+		LastMessageID: summary.LastMessageID,
+		MessageCount:  summary.MessageCount,
+		UpdatedAt:     summary.UpdatedAt,
+	}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	return nil
+}
+
+// DeleteConversation 删除对话及其消息
+func (s *MongoConversationStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	result, err := s.conversations.DeleteOne(ctx, bson.M{"_id": conversationID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+
+	_, err = s.messages.DeleteMany(ctx, bson.M{"conversationId": conversationID})
+	return err
+}
+
+// ListConversations 按更新时间倒序列出用户的对话
+func (s *MongoConversationStore) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
+	return s.findConversations(ctx, bson.M{"userId": userID, "status": ConversationStatusActive}, limit, offset)
+}
+
+// SearchConversations 按标题和消息内容搜索用户的对话。
+// MongoDB 全文索引按空格分词，不支持中文等无空格语言，查询包含此类字符时改用正则匹配。
+func (s *MongoConversationStore) SearchConversations(ctx context.Context, userID, query string, limit, offset int) ([]*Conversation, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return s.ListConversations(ctx, userID, limit, offset)
+	}
+
+	var conversationFilter, messageFilter bson.M
+	if needsRegexSearch(query) {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		conversationFilter = bson.M{"userId": userID, "title": pattern}
+		messageFilter = bson.M{"userId": userID, "content": pattern}
+	} else {
+		conversationFilter = bson.M{"userId": userID, "$text": bson.M{"$search": query}}
+		messageFilter = bson.M{"userId": userID, "$text": bson.M{"$search": query}}
+	}
+
+	titleIDs, err := s.conversations.Distinct(ctx, "_id", conversationFilter)
+	if err != nil {
+		return nil, err
+	}
+	messageIDs, err := s.messages.Distinct(ctx, "conversationId", messageFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := append(titleIDs, messageIDs...)
+	if len(ids) == 0 {
+		return []*Conversation{}, nil
+	}
+	return s.findConversations(ctx, bson.M{
+		"_id":    bson.M{"$in": ids},
+		"userId": userID,
+		"status": ConversationStatusActive,
+	}, limit, offset)
+}
+
+// AddMessage 追加消息并累加统计
+func (s *MongoConversationStore) AddMessage(ctx context.Context, conversationID string, message *Message) error {
+	// 先原子递增序号和统计，再以新序号写入消息
+	var doc conversationDoc
+	err := s.conversations.FindOneAndUpdate(ctx,
+		bson.M{"_id": conversationID},
+		bson.M{
+			"$inc": bson.M{"messageSeq": 1, "totalMessages": 1, "totalTokens": message.TokenUsed},
+			"$set": bson.M{"updatedAt": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.messages.InsertOne(ctx, messageDoc{
+		ID:             message.ID,
+		ConversationID: conversationID,
+		UserID:         doc.UserID,
+		Seq:            doc.MessageSeq,
+		Role:           message.Role,
+		Content:        message.Content,
+		Timestamp:      message.Timestamp,
+		TokenUsed:      message.TokenUsed,
+		Metadata:       message.Metadata,
+		ImageURL:       message.ImageURL,
+		AudioURL:       message.AudioURL,
+		FunctionCall:   message.FunctionCall,
+	})
+	if err != nil {
+		// 回滚统计，序号保留空洞不影响顺序
+		_, _ = s.conversations.UpdateByID(ctx, conversationID, bson.M{
+			"$inc": bson.M{"totalMessages": -1, "totalTokens": -message.TokenUsed},
+		})
+		return err
+	}
+	return nil
+}
+
+// GetMessages 按发送顺序分页获取消息
+func (s *MongoConversationStore) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
+	if _, err := s.findConversation(ctx, conversationID); err != nil {
+		return nil, err
+	}
+	return s.findMessages(ctx, conversationID, limit, offset)
+}
+
+// DeleteMessage 删除消息并扣减统计
+func (s *MongoConversationStore) DeleteMessage(ctx context.Context, conversationID, messageID string) error {
+	var doc messageDoc
+	err := s.messages.FindOneAndDelete(ctx, bson.M{"_id": messageID, "conversationId": conversationID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, err := s.findConversation(ctx, conversationID); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", ErrMessageNotFound, messageID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.conversations.UpdateByID(ctx, conversationID, bson.M{
+		"$inc":   bson.M{"totalMessages": -1, "totalTokens": -doc.TokenUsed},
+		"$set":   bson.M{"updatedAt": time.Now()},
+		"$unset": bson.M{"summary": ""},
+	})
+	return err
+}
+
+// GetUserStats 统计用户的对话使用情况
+func (s *MongoConversationStore) GetUserStats(ctx context.Context, userID string) (*UserStats, error) {
+	cursor, err := s.conversations.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"conversations": bson.M{"$sum": 1},
+			"messages":      bson.M{"$sum": "$totalMessages"},
+			"tokens":        bson.M{"$sum": "$totalTokens"},
+			"lastActiveAt":  bson.M{"$max": "$updatedAt"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Conversations int       `bson:"conversations"`
+		Messages      int       `bson:"messages"`
+		Tokens        int       `bson:"tokens"`
+		LastActiveAt  time.Time `bson:"lastActiveAt"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	stats := &UserStats{UserID: userID}
+	if len(rows) > 0 {
+		stats.ConversationCount = rows[0].Conversations
+		stats.TotalMessages = rows[0].Messages
+		stats.TotalTokens = rows[0].Tokens
+		stats.LastActiveAt = rows[0].LastActiveAt
+	}
+	return stats, nil
+}
+
+// GetSystemStats 按用户聚合后统计全部对话使用情况
+func (s *MongoConversationStore) GetSystemStats(ctx context.Context, activeSince time.Time) (*SystemStats, error) {
+	cursor, err := s.conversations.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$userId",
+			"conversations": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", ConversationStatusActive}}, 1, 0}}},
+			"messages":      bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", ConversationStatusActive}}, "$totalMessages", 0}}},
+			"tokens":        bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", ConversationStatusActive}}, "$totalTokens", 0}}},
+			"lastActiveAt":  bson.M{"$max": "$updatedAt"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Conversations int       `bson:"conversations"`
+		Messages      int       `bson:"messages"`
+		Tokens        int       `bson:"tokens"`
+		LastActiveAt  time.Time `bson:"lastActiveAt"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	stats := &SystemStats{TotalUsers: len(rows)}
+	for _, row := range rows {
+		stats.TotalConversations += row.Conversations
+		stats.TotalMessages += row.Messages
+		stats.TotalTokens += row.Tokens
+		if row.LastActiveAt.After(activeSince) {
+			stats.ActiveUsers++
+		}
+	}
+	return stats, nil
+}
+
+// findConversation 查询对话文档
+func (s *MongoConversationStore) findConversation(ctx context.Context, conversationID string) (*conversationDoc, error) {
+	var doc conversationDoc
+	err := s.conversations.FindOne(ctx, bson.M{"_id": conversationID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// findConversations 按更新时间倒序分页查询对话
+func (s *MongoConversationStore) findConversations(ctx context.Context, filter bson.M, limit, offset int) ([]*Conversation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}})
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := s.conversations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []conversationDoc
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	conversations := make([]*Conversation, len(docs))
+	for i := range docs {
+		conversations[i] = docs[i].toConversation()
+	}
+	return conversations, nil
+}
+
+// findMessages 按序号分页查询消息
+func (s *MongoConversationStore) findMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}})
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := s.messages.Find(ctx, bson.M{"conversationId": conversationID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []messageDoc
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, len(docs))
+	for i, doc := range docs {
+		messages[i] = &Message{
+			ID:           doc.ID,
+			Role:         doc.Role,
+			Content:      doc.Content,
+			Timestamp:    doc.Timestamp,
+			TokenUsed:    doc.TokenUsed,
+			Metadata:     doc.Metadata,
+			ImageURL:     doc.ImageURL,
+			AudioURL:     doc.AudioURL,
+			FunctionCall: doc.FunctionCall,
+		}
+	}
+	return messages, nil
+}
+
+// toConversation 转换为对话，不含消息
+func (d *conversationDoc) toConversation() *Conversation {
+	conversation := &Conversation{
+		ID:            d.ID,
+		UserID:        d.UserID,
+		Title:         d.Title,
+		Messages:      []Message{},
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+		Metadata:      d.Metadata,
+		TotalMessages: d.TotalMessages,
+		TotalTokens:   d.TotalTokens,
+		Status:        d.Status,
+	}
+	if d.Summary != nil {
+		conversation.Summary = &ConversationSummary{
+			Content:       d.Summary.Content,
+			LastMessageID: d.Summary.LastMessageID,
+			MessageCount:  d.Summary.MessageCount,
+			UpdatedAt:     d.Summary.UpdatedAt,
+		}
+	}
+	return conversation
+}
+
+// needsRegexSearch 查询包含中日韩等不以空格分词的字符时，全文索引无法匹配
+func needsRegexSearch(query string) bool {
+	for _, r := range query {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStoreConversation(id, userID, title string, updatedAt time.Time) *Conversation {
+	return &Conversation{
+		ID:        id,
+		UserID:    userID,
+		Title:     title,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+		Status:    ConversationStatusActive,
+	}
+}
+
+func TestMemoryStoreListOrder(t *testing.T) {
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("c%d", i)
+		require.NoError(t, store.CreateConversation(ctx, newStoreConversation(id, "u1", id, base.Add(time.Duration(i)*time.Minute))))
+	}
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("other", "u2", "other", base)))
+
+	// 追加消息后对话排到最前
+	require.NoError(t, store.AddMessage(ctx, "c0", &Message{ID: "m1", Role: "user", Content: "hi"}))
+
+	list, err := store.ListConversations(ctx, "u1", 2, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "c0", list[0].ID)
+	assert.Equal(t, "c2", list[1].ID)
+	assert.Empty(t, list[0].Messages)
+
+	list, err = store.ListConversations(ctx, "u1", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "c1", list[0].ID)
+}
+
+func TestMemoryStoreMessages(t *testing.T) {
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c1", "u1", "test", time.Now())))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.AddMessage(ctx, "c1", &Message{ID: fmt.Sprintf("m%d", i), Role: "user", Content: fmt.Sprintf("message %d", i), TokenUsed: 10}))
+	}
+
+	messages, err := store.GetMessages(ctx, "c1", 2, 3)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "m3", messages[0].ID)
+	assert.Equal(t, "m4", messages[1].ID)
+
+	require.NoError(t, store.DeleteMessage(ctx, "c1", "m1"))
+	err = store.DeleteMessage(ctx, "c1", "m1")
+	assert.True(t, errors.Is(err, ErrMessageNotFound))
+
+	conversation, err := store.GetConversation(ctx, "c1")
+	require.NoError(t, err)
+	assert.Len(t, conversation.Messages, 4)
+	assert.Equal(t, 4, conversation.TotalMessages)
+	assert.Equal(t, 40, conversation.TotalTokens)
+
+	// 返回值为副本，修改不影响存储
+	conversation.Messages[0].Content = "changed"
+	stored, err := store.GetConversation(ctx, "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "message 0", stored.Messages[0].Content)
+
+	_, err = store.GetMessages(ctx, "missing", 10, 0)
+	assert.True(t, errors.Is(err, ErrConversationNotFound))
+}
+
+func TestMemoryStoreSummary(t *testing.T) {
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c1", "u1", "test", time.Now())))
+	require.NoError(t, store.AddMessage(ctx, "c1", &Message{ID: "m1", Role: "user", Content: "hi"}))
+
+	summary := &ConversationSummary{Content: "打招呼", LastMessageID: "m1", MessageCount: 1, UpdatedAt: time.Now()}
+	require.NoError(t, store.SaveSummary(ctx, "c1", summary))
+	summary.Content = "changed"
+
+	conversation, err := store.GetConversation(ctx, "c1")
+	require.NoError(t, err)
+	require.NotNil(t, conversation.Summary)
+	assert.Equal(t, "打招呼", conversation.Summary.Content)
+	assert.Equal(t, "m1", conversation.Summary.LastMessageID)
+
+	// 删除消息后摘要可能包含已删除的内容，随之清除
+	require.NoError(t, store.DeleteMessage(ctx, "c1", "m1"))
+	conversation, err = store.GetConversation(ctx, "c1")
+	require.NoError(t, err)
+	assert.Nil(t, conversation.Summary)
+
+	err = store.SaveSummary(ctx, "missing", summary)
+	assert.True(t, errors.Is(err, ErrConversationNotFound))
+}
+
+func TestMemoryStoreSearch(t *testing.T) {
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c1", "u1", "Go 并发", now)))
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c2", "u1", "周报", now)))
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c3", "u2", "Go 泛型", now)))
+	require.NoError(t, store.AddMessage(ctx, "c2", &Message{ID: "m1", Role: "user", Content: "帮我整理 goroutine 的用法"}))
+
+	results, err := store.SearchConversations(ctx, "u1", "GO", 10, 0)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(results))
+	for _, conversation := range results {
+		ids = append(ids, conversation.ID)
+	}
+	assert.ElementsMatch(t, []string{"c1", "c2"}, ids)
+
+	results, err = store.SearchConversations(ctx, "u1", "整理", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "c2", results[0].ID)
+}
+
+func TestMemoryStoreStats(t *testing.T) {
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c1", "u1", "a", now)))
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c2", "u1", "b", now)))
+	require.NoError(t, store.CreateConversation(ctx, newStoreConversation("c3", "u2", "c", now.Add(-48*time.Hour))))
+	require.NoError(t, store.AddMessage(ctx, "c1", &Message{ID: "m1", Role: "user", TokenUsed: 5}))
+	require.NoError(t, store.AddMessage(ctx, "c2", &Message{ID: "m2", Role: "assistant", TokenUsed: 7}))
+
+	userStats, err := store.GetUserStats(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, userStats.ConversationCount)
+	assert.Equal(t, 2, userStats.TotalMessages)
+	assert.Equal(t, 12, userStats.TotalTokens)
+
+	systemStats, err := store.GetSystemStats(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, systemStats.TotalUsers)
+	assert.Equal(t, 1, systemStats.ActiveUsers)
+	assert.Equal(t, 3, systemStats.TotalConversations)
+	assert.Equal(t, 12, systemStats.TotalTokens)
+}
+
+func TestDefaultAIServiceConversationStore(t *testing.T) {
+	service := createTestService(t)
+	store := NewMemoryConversationStore()
+	service.SetConversationStore(store)
+	ctx := context.Background()
+
+	conversation, err := service.CreateConversation(ctx, "u1", map[string]interface{}{"title": "persisted"})
+	require.NoError(t, err)
+	require.NoError(t, service.AddMessage(ctx, conversation.ID, &Message{Role: "user", Content: "hello"}))
+
+	stored, err := store.GetConversation(ctx, conversation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "persisted", stored.Title)
+	require.Len(t, stored.Messages, 1)
+	assert.NotEmpty(t, stored.Messages[0].ID)
+	assert.False(t, stored.Messages[0].Timestamp.IsZero())
+}
+
+func TestNeedsRegexSearch(t *testing.T) {
+	assert.False(t, needsRegexSearch("goroutine leak"))
+	assert.True(t, needsRegexSearch("并发"))
+	assert.True(t, needsRegexSearch("go 并发"))
+	assert.True(t, needsRegexSearch("カタカナ"))
+}
@@ -0,0 +1,26 @@
+package ai
+
+import "unicode"
+
+// EstimateTokens 粗略估算文本的 token 数：中日韩字符按 1 个字符 1 个 token，
+// 其他字符按 4 个字符 1 个 token，用于提供商未返回用量时的配额计算
+func EstimateTokens(text string) int {
+	var cjk, others int
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// estimateMessagesTokens 估算消息列表的 token 数，每条消息额外计入角色等格式开销，图片按固定值计算
+func estimateMessagesTokens(messages []Message) int {
+	total := 0
+	for _, message := range messages {
+		total += EstimateTokens(message.Content) + 4 + len(messageImages(message))*imageTokenEstimate
+	}
+	return total
+}
@@ -0,0 +1,456 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrToolIterationsExceeded 工具调用轮数超过 MaxToolIterations
+var ErrToolIterationsExceeded = errors.New("tool call iterations exceeded")
+
+// toolNamePattern 工具名称规则，与各厂商函数名限制的交集一致
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ToolHandler 工具处理函数，args 为模型生成并已按参数 Schema 校验的参数，
+// 返回值序列化为 JSON 后作为函数结果发送给模型
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+
+// Tool 可供模型调用的工具
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters 参数的 JSON Schema，为空时表示无参数
+	Parameters map[string]interface{}
+	// Permission 权限标识，为空表示不限制，由调用方按用户权限筛选可用工具
+	Permission string
+	Handler    ToolHandler
+}
+
+// Definition 转换为发送给模型的函数定义
+func (t *Tool) Definition() FunctionDefinition {
+	return FunctionDefinition{
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters:  t.Parameters,
+	}
+}
+
+// ToolRegistry 工具注册表，按注册顺序保存工具
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*Tool
+	names []string
+}
+
+// NewToolRegistry 创建工具注册表
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]*Tool)}
+}
+
+// Register 注册工具，名称重复时返回错误
+func (r *ToolRegistry) Register(tool *Tool) error {
+	if !toolNamePattern.MatchString(tool.Name) {
+		return fmt.Errorf("invalid tool name %q", tool.Name)
+	}
+	if tool.Handler == nil {
+		return fmt.Errorf("tool %s: handler is required", tool.Name)
+	}
+	if tool.Parameters == nil {
+		tool.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if schemaType, _ := tool.Parameters["type"].(string); schemaType != "object" {
+		return fmt.Errorf("tool %s: parameters must be an object schema", tool.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[tool.Name]; exists {
+		return fmt.Errorf("tool %s already registered", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	r.names = append(r.names, tool.Name)
+	return nil
+}
+
+// Get 获取工具
+func (r *ToolRegistry) Get(name string) (*Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// List 按注册顺序列出工具
+func (r *ToolRegistry) List() []*Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]*Tool, 0, len(r.names))
+	for _, name := range r.names {
+		tools = append(tools, r.tools[name])
+	}
+	return tools
+}
+
+// Definitions 返回 allow 允许的工具的函数定义，allow 为 nil 时返回全部
+func (r *ToolRegistry) Definitions(allow func(*Tool) bool) []FunctionDefinition {
+	var definitions []FunctionDefinition
+	for _, tool := range r.List() {
+		if allow == nil || allow(tool) {
+			definitions = append(definitions, tool.Definition())
+		}
+	}
+	return definitions
+}
+
+// Execute 校验参数并执行工具，执行结果或错误写入返回的函数调用，错误会发送给模型以便其修正参数
+func (r *ToolRegistry) Execute(ctx context.Context, call *FunctionCall) *FunctionCall {
+	result := &FunctionCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments}
+
+	tool, ok := r.Get(call.Name)
+	if !ok {
+		result.Error = fmt.Sprintf("unknown tool %s", call.Name)
+		return result
+	}
+	args := call.Arguments
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	if err := validateArguments(tool.Parameters, args); err != nil {
+		result.Error = fmt.Sprintf("invalid arguments: %v", err)
+		return result
+	}
+
+	value, err := runTool(ctx, tool, args)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Result = value
+	return result
+}
+
+// SetToolRegistry 设置工具注册表，启用函数调用时自动执行请求中声明的已注册工具
+func (s *DefaultAIService) SetToolRegistry(registry *ToolRegistry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = registry
+}
+
+// ToolRegistry 获取工具注册表，未设置时为 nil
+func (s *DefaultAIService) ToolRegistry() *ToolRegistry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tools
+}
+
+// 私有方法
+
+// toolLoopEnabled 请求是否需要执行工具循环
+func (s *DefaultAIService) toolLoopEnabled(request *ChatRequest) (*ToolRegistry, bool) {
+	registry := s.ToolRegistry()
+	return registry, registry != nil && s.config.EnableFunctionCalling && len(request.Functions) > 0
+}
+
+// chatWithTools 调用模型，回复为已注册工具的调用时执行工具并将结果发回模型，直到模型给出最终回复。
+// 多轮的用量累加到最终回复中，执行过的工具调用记录在 Metadata["toolSteps"]
+func (s *DefaultAIService) chatWithTools(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	registry, enabled := s.toolLoopEnabled(request)
+	if !enabled {
+		return s.provider.Chat(ctx, request)
+	}
+
+	req := *request
+	req.Messages = append([]Message(nil), request.Messages...)
+	var (
+		usage Usage
+		steps []FunctionCall
+	)
+	for round := 0; ; round++ {
+		resp, err := s.provider.Chat(ctx, &req)
+		if err != nil || resp.Error != nil {
+			return resp, err
+		}
+		usage = addUsage(usage, resp.Usage)
+
+		calls := pendingToolCalls(resp, &req, registry)
+		if len(calls) == 0 {
+			resp.Usage = usage
+			if len(steps) > 0 {
+				if resp.Metadata == nil {
+					resp.Metadata = make(map[string]interface{})
+				}
+				resp.Metadata["toolSteps"] = steps
+			}
+			return resp, nil
+		}
+		if round >= s.maxToolIterations() {
+			return nil, fmt.Errorf("%w: %d", ErrToolIterationsExceeded, s.maxToolIterations())
+		}
+
+		messages, executed := executeToolCalls(ctx, registry, resp.Message.Content, calls)
+		req.Messages = append(req.Messages, messages...)
+		steps = append(steps, executed...)
+	}
+}
+
+// streamWithTools 流式版本的工具循环：转发文本增量，拦截工具调用分片并执行工具后发起下一轮流式请求。
+// 每执行一个工具发送一个 Metadata["toolCall"] 分片，用量分片累加之前各轮的用量
+func (s *DefaultAIService) streamWithTools(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error) {
+	registry, enabled := s.toolLoopEnabled(request)
+	if !enabled {
+		return s.provider.ChatStream(ctx, request)
+	}
+
+	req := *request
+	req.Messages = append([]Message(nil), request.Messages...)
+	chunks, err := s.provider.ChatStream(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *ChatResponse)
+	go func() {
+		defer close(out)
+
+		send := func(chunk *ChatResponse) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		drain := func(in <-chan *ChatResponse) {
+			go func() {
+				for range in {
+				}
+			}()
+		}
+
+		var previous Usage // 之前各轮的用量
+		for round := 0; ; round++ {
+			var (
+				calls   []FunctionCall
+				content string
+				usage   Usage
+			)
+			for chunk := range chunks {
+				if chunk.Usage.TotalTokens > 0 {
+					usage = chunk.Usage
+					chunk.Usage = addUsage(previous, chunk.Usage)
+				}
+				if pending := pendingToolCalls(chunk, &req, registry); len(pending) > 0 {
+					calls = pending
+					continue
+				}
+				content += chunk.Delta
+				if !send(chunk) {
+					drain(chunks)
+					return
+				}
+			}
+			if len(calls) == 0 {
+				return
+			}
+			if round >= s.maxToolIterations() {
+				send(&ChatResponse{
+					Error: &APIError{
+						Code:    "tool_iterations_exceeded",
+						Message: fmt.Sprintf("%v: %d", ErrToolIterationsExceeded, s.maxToolIterations()),
+					},
+					Stream:    true,
+					Done:      true,
+					Timestamp: time.Now(),
+				})
+				return
+			}
+
+			messages, executed := executeToolCalls(ctx, registry, content, calls)
+			req.Messages = append(req.Messages, messages...)
+			for i := range executed {
+				if !send(&ChatResponse{Stream: true, Metadata: map[string]interface{}{"toolCall": executed[i]}, Timestamp: time.Now()}) {
+					return
+				}
+			}
+
+			previous = addUsage(previous, usage)
+			chunks, err = s.provider.ChatStream(ctx, &req)
+			if err != nil {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					apiErr = &APIError{Code: "stream_error", Message: err.Error()}
+				}
+				send(&ChatResponse{Error: apiErr, Stream: true, Done: true, Timestamp: time.Now()})
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// maxToolIterations 最多执行的工具调用轮数
+func (s *DefaultAIService) maxToolIterations() int {
+	if s.config.MaxToolIterations > 0 {
+		return s.config.MaxToolIterations
+	}
+	return 5
+}
+
+// pendingToolCalls 回复中需要执行的工具调用。只处理请求中声明且已注册的工具，
+// 有任一调用不满足时返回 nil，由调用方自行处理函数调用
+func pendingToolCalls(resp *ChatResponse, request *ChatRequest, registry *ToolRegistry) []FunctionCall {
+	var calls []FunctionCall
+	if multiple, ok := resp.Metadata["toolCalls"].([]FunctionCall); ok {
+		calls = multiple
+	} else if resp.Message.FunctionCall != nil {
+		calls = []FunctionCall{*resp.Message.FunctionCall}
+	}
+
+	for _, call := range calls {
+		if _, ok := registry.Get(call.Name); !ok || !declaresFunction(request, call.Name) {
+			return nil
+		}
+	}
+	return calls
+}
+
+// declaresFunction 请求是否声明了指定函数
+func declaresFunction(request *ChatRequest, name string) bool {
+	for _, function := range request.Functions {
+		if function.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// executeToolCalls 依次执行工具调用，返回追加到对话中的助手调用消息和函数结果消息。
+// 每个调用单独成对，各厂商格式均可按 ID 对应；content 为模型在调用工具前输出的文本
+func executeToolCalls(ctx context.Context, registry *ToolRegistry, content string, calls []FunctionCall) ([]Message, []FunctionCall) {
+	messages := make([]Message, 0, len(calls)*2)
+	executed := make([]FunctionCall, 0, len(calls))
+	for i := range calls {
+		call := calls[i]
+		assistant := Message{Role: "assistant", FunctionCall: &FunctionCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments}}
+		if i == 0 {
+			assistant.Content = content
+		}
+
+		result := registry.Execute(ctx, &call)
+		if result.Error != "" {
+			log.Printf("AI tool %s failed: %s", call.Name, result.Error)
+		}
+		messages = append(messages, assistant, Message{Role: "function", FunctionCall: result})
+		executed = append(executed, *result)
+	}
+	return messages, executed
+}
+
+// runTool 执行工具处理函数，处理函数 panic 时转换为错误
+func runTool(ctx context.Context, tool *Tool, args map[string]interface{}) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tool %s panicked: %v", tool.Name, r)
+		}
+	}()
+	return tool.Handler(ctx, args)
+}
+
+// addUsage 累加用量
+func addUsage(a, b Usage) Usage {
+	return Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+		Cost:             a.Cost + b.Cost,
+	}
+}
+
+// validateArguments 按 JSON Schema 校验参数：必填项、属性类型和枚举值，未声明的属性不做限制
+func validateArguments(schema map[string]interface{}, args map[string]interface{}) error {
+	for _, name := range schemaStrings(schema["required"]) {
+		if value, ok := args[name]; !ok || value == nil {
+			return fmt.Errorf("%s is required", name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, value := range args {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok || value == nil {
+			continue
+		}
+		if err := validateValue(property, value); err != nil {
+			return fmt.Errorf("%s %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateValue 校验单个参数值
+func validateValue(property map[string]interface{}, value interface{}) error {
+	switch property["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return errors.New("must be a string")
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return errors.New("must be an integer")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return errors.New("must be a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return errors.New("must be an array")
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return errors.New("must be an object")
+		}
+	}
+
+	enum, ok := property["enum"].([]interface{})
+	if !ok || len(enum) == 0 {
+		return nil
+	}
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %v", enum)
+}
+
+// schemaStrings 读取 Schema 中的字符串数组，兼容 []string 和 JSON 解码得到的 []interface{}
+func schemaStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countUsersTool 测试用工具：按状态统计用户数
+func countUsersTool(calls *atomic.Int32) *Tool {
+	return &Tool{
+		Name:        "count_users",
+		Description: "统计用户数",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"dept":   map[string]interface{}{"type": "string"},
+				"status": map[string]interface{}{"type": "integer", "enum": []interface{}{0, 1}},
+			},
+			"required": []string{"dept"},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			calls.Add(1)
+			return map[string]interface{}{"dept": args["dept"], "total": 3}, nil
+		},
+	}
+}
+
+// newToolTestService 创建启用函数调用的服务，handler 按请求序号返回模型回复
+func newToolTestService(t *testing.T, registry *ToolRegistry, handler func(round int, messages []map[string]interface{}, w http.ResponseWriter)) *DefaultAIService {
+	var round atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		handler(int(round.Add(1)), body.Messages, w)
+	}))
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.Provider = "deepseek"
+	config.APIKey = "sk-test"
+	config.BaseURL = server.URL
+	config.Model = ""
+	config.EnableFunctionCalling = true
+	config.MaxToolIterations = 2
+	service, err := NewDefaultAIService(config)
+	require.NoError(t, err)
+	service.SetToolRegistry(registry)
+	require.NoError(t, service.Initialize(config))
+	return service
+}
+
+// toolCallReply 模型请求调用 count_users 的回复
+func toolCallReply(w http.ResponseWriter, id, arguments string) {
+	fmt.Fprintf(w, `{"model":"deepseek-chat","choices":[{"message":{"role":"assistant","content":"","tool_calls":[{"id":"%s","type":"function","function":{"name":"count_users","arguments":%q}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, id, arguments)
+}
+
+func TestToolRegistry(t *testing.T) {
+	registry := NewToolRegistry()
+	var calls atomic.Int32
+	require.NoError(t, registry.Register(countUsersTool(&calls)))
+	assert.Error(t, registry.Register(countUsersTool(&calls)))
+	assert.Error(t, registry.Register(&Tool{Name: "bad name", Handler: countUsersTool(&calls).Handler}))
+	assert.Error(t, registry.Register(&Tool{Name: "no_handler"}))
+	require.NoError(t, registry.Register(&Tool{
+		Name:       "admin_only",
+		Permission: "system:user:list",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			panic("boom")
+		},
+	}))
+
+	definitions := registry.Definitions(func(tool *Tool) bool { return tool.Permission == "" })
+	require.Len(t, definitions, 1)
+	assert.Equal(t, "count_users", definitions[0].Name)
+	assert.Len(t, registry.Definitions(nil), 2)
+
+	ctx := context.Background()
+	result := registry.Execute(ctx, &FunctionCall{ID: "1", Name: "count_users", Arguments: map[string]interface{}{"dept": "销售部", "status": float64(0)}})
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "1", result.ID)
+	assert.Equal(t, 3, result.Result.(map[string]interface{})["total"])
+
+	// 参数校验失败和未知工具不执行处理函数
+	assert.Contains(t, registry.Execute(ctx, &FunctionCall{Name: "count_users"}).Error, "dept is required")
+	assert.Contains(t, registry.Execute(ctx, &FunctionCall{Name: "count_users", Arguments: map[string]interface{}{"dept": "x", "status": float64(2)}}).Error, "must be one of")
+	assert.Contains(t, registry.Execute(ctx, &FunctionCall{Name: "count_users", Arguments: map[string]interface{}{"dept": "x", "status": 1.5}}).Error, "must be an integer")
+	assert.Contains(t, registry.Execute(ctx, &FunctionCall{Name: "missing"}).Error, "unknown tool")
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 处理函数 panic 转换为错误
+	assert.Contains(t, registry.Execute(ctx, &FunctionCall{Name: "admin_only"}).Error, "panicked")
+}
+
+func TestChatToolLoop(t *testing.T) {
+	registry := NewToolRegistry()
+	var calls atomic.Int32
+	require.NoError(t, registry.Register(countUsersTool(&calls)))
+
+	service := newToolTestService(t, registry, func(round int, messages []map[string]interface{}, w http.ResponseWriter) {
+		switch round {
+		case 1:
+			toolCallReply(w, "call_1", `{"dept":"销售部","status":0}`)
+		case 2:
+			// 工具结果以 tool 消息发回模型，第一条为系统提示词
+			require.Len(t, messages, 4)
+			assert.Equal(t, "call_1", messages[2]["tool_calls"].([]interface{})[0].(map[string]interface{})["id"])
+			assert.Equal(t, "tool", messages[3]["role"])
+			assert.Equal(t, "call_1", messages[3]["tool_call_id"])
+			assert.JSONEq(t, `{"dept":"销售部","total":3}`, messages[3]["content"].(string))
+			fmt.Fprint(w, `{"model":"deepseek-chat","choices":[{"message":{"role":"assistant","content":"销售部有 3 个禁用用户"},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":8,"total_tokens":38}}`)
+		}
+	})
+
+	request := &ChatRequest{
+		UserID:    "1",
+		Messages:  []Message{{Role: "user", Content: "销售部有多少禁用用户"}},
+		Functions: registry.Definitions(nil),
+	}
+	resp, err := service.Chat(context.Background(), request)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "销售部有 3 个禁用用户", resp.Message.Content)
+	assert.Equal(t, 53, resp.Usage.TotalTokens)
+	assert.Equal(t, int32(1), calls.Load())
+	steps := resp.Metadata["toolSteps"].([]FunctionCall)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "count_users", steps[0].Name)
+	// 原请求的消息不被修改
+	assert.Len(t, request.Messages, 1)
+}
+
+func TestChatToolLoopGuards(t *testing.T) {
+	registry := NewToolRegistry()
+	var calls atomic.Int32
+	require.NoError(t, registry.Register(countUsersTool(&calls)))
+
+	// 模型一直调用工具时超过最大轮数后停止
+	service := newToolTestService(t, registry, func(round int, messages []map[string]interface{}, w http.ResponseWriter) {
+		toolCallReply(w, fmt.Sprintf("call_%d", round), `{"dept":"销售部"}`)
+	})
+	_, err := service.Chat(context.Background(), &ChatRequest{
+		Messages:  []Message{{Role: "user", Content: "hi"}},
+		Functions: registry.Definitions(nil),
+	})
+	assert.True(t, errors.Is(err, ErrToolIterationsExceeded))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// 请求未声明工具时不执行，函数调用原样返回
+	calls.Store(0)
+	resp, err := service.Chat(context.Background(), &ChatRequest{
+		Messages:  []Message{{Role: "user", Content: "hi"}},
+		Functions: []FunctionDefinition{{Name: "other_tool"}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Message.FunctionCall)
+	assert.Equal(t, "function_call", resp.Finish)
+	assert.Zero(t, calls.Load())
+}
+
+func TestChatStreamToolLoop(t *testing.T) {
+	registry := NewToolRegistry()
+	var calls atomic.Int32
+	require.NoError(t, registry.Register(countUsersTool(&calls)))
+
+	service := newToolTestService(t, registry, func(round int, messages []map[string]interface{}, w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch round {
+		case 1:
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"我查一下。"}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"count_users","arguments":"{\"dept\":\"销售部\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}
+
+data: [DONE]
+
+`)
+		case 2:
+			require.Len(t, messages, 4)
+			assert.Equal(t, "我查一下。", messages[2]["content"])
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"共 3 人"},"finish_reason":"stop"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":30,"completion_tokens":8,"total_tokens":38}}
+
+data: [DONE]
+
+`)
+		}
+	})
+
+	chunks, err := service.ChatStream(context.Background(), &ChatRequest{
+		Messages:  []Message{{Role: "user", Content: "销售部有多少人"}},
+		Functions: registry.Definitions(nil),
+	})
+	require.NoError(t, err)
+
+	var (
+		content string
+		usage   Usage
+		tools   []string
+	)
+	for _, chunk := range collectStream(t, chunks) {
+		require.Nil(t, chunk.Error)
+		assert.Nil(t, chunk.Message.FunctionCall)
+		content += chunk.Delta
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+		if call, ok := chunk.Metadata["toolCall"].(FunctionCall); ok {
+			tools = append(tools, call.Name)
+		}
+	}
+	assert.Equal(t, "我查一下。共 3 人", content)
+	assert.Equal(t, []string{"count_users"}, tools)
+	assert.Equal(t, 53, usage.TotalTokens)
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -17,6 +18,8 @@ type Message struct {
 	// 多模态支持
 	ImageURL string `json:"imageUrl,omitempty"`
 	AudioURL string `json:"audioUrl,omitempty"`
+	// Images 图片输入，与 ImageURL 一起发送给模型，需启用 EnableImageInput
+	Images []Image `json:"images,omitempty"`
 
 	// 函数调用
 	FunctionCall *FunctionCall `json:"functionCall,omitempty"`
@@ -26,8 +29,16 @@ type Message struct {
 	DeltaContent string `json:"deltaContent,omitempty"`
 }
 
-// FunctionCall 函数调用
+// Image 图片输入：Data 非空或 URL 为 data URL 时以 base64 内联发送，否则发送 URL（需模型服务可访问）
+type Image struct {
+	URL      string `json:"url,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// FunctionCall 函数调用，ID 为提供商分配的调用标识，返回结果时需原样携带
 type FunctionCall struct {
+	ID        string                 `json:"id,omitempty"`
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
 	Result    interface{}            `json:"result,omitempty"`
@@ -51,6 +62,17 @@ type Conversation struct {
 
 	// 状态
 	Status string `json:"status"` // active, archived, deleted
+
+	// Summary 较早消息的滚动摘要，历史超出上下文窗口时由模型生成
+	Summary *ConversationSummary `json:"summary,omitempty"`
+}
+
+// ConversationSummary 对话摘要，概括从第一条消息到 LastMessageID 的内容
+type ConversationSummary struct {
+	Content       string    `json:"content"`
+	LastMessageID string    `json:"lastMessageId"`
+	MessageCount  int       `json:"messageCount"`
+	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
 // ChatRequest 聊天请求
@@ -71,6 +93,9 @@ type ChatRequest struct {
 	UserID    string                 `json:"userId,omitempty"`
 	SessionID string                 `json:"sessionId,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+
+	// Tags 路由标签，配置多提供商路由时只选择包含全部标签的提供商
+	Tags []string `json:"tags,omitempty"`
 }
 
 // FunctionDefinition 函数定义
@@ -127,6 +152,14 @@ type APIError struct {
 	RetryAfter time.Duration `json:"retryAfter,omitempty"`
 }
 
+// Error 实现 error 接口，便于提供商错误沿错误链返回
+func (e *APIError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("ai api error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("ai api error (code %s): %s", e.Code, e.Message)
+}
+
 // AIProvider AI提供商接口
 type AIProvider interface {
 	// 基础方法
@@ -216,6 +249,8 @@ type SystemStats struct {
 	ActiveRequests     int           `json:"activeRequests"`
 	AvgResponseTime    time.Duration `json:"avgResponseTime"`
 	Timestamp          time.Time     `json:"timestamp"`
+	// Cache 响应缓存统计，未启用缓存时为空
+	Cache *CacheStats `json:"cache,omitempty"`
 }
 
 // CacheService 缓存服务接口
@@ -229,11 +264,13 @@ type CacheService interface {
 
 // CacheStats 缓存统计
 type CacheStats struct {
-	Hits        int64   `json:"hits"`
-	Misses      int64   `json:"misses"`
-	HitRatio    float64 `json:"hitRatio"`
-	Count       int     `json:"count"`
-	MemoryUsage int64   `json:"memoryUsage"`
+	Hits int64 `json:"hits"`
+	// SemanticHits 语义缓存命中次数，HitRatio 包含语义命中
+	SemanticHits int64   `json:"semanticHits"`
+	Misses       int64   `json:"misses"`
+	HitRatio     float64 `json:"hitRatio"`
+	Count        int     `json:"count"`
+	MemoryUsage  int64   `json:"memoryUsage"`
 }
 
 // MetricsService 指标服务接口
@@ -273,9 +310,17 @@ type ErrorHandler interface {
 	Handle(ctx context.Context, err error, request *ChatRequest) *APIError
 }
 
-// Middleware 中间件接口
+// ChatHandler 聊天处理函数
+type ChatHandler func(ctx context.Context, request *ChatRequest) (*ChatResponse, error)
+
+// StreamHandler 流式聊天处理函数
+type StreamHandler func(ctx context.Context, request *ChatRequest) (<-chan *ChatResponse, error)
+
+// Middleware 中间件接口，按添加顺序由外向内包裹 Chat 和 ChatStream，
+// 调用 next 进入下一层，不调用则拦截请求
 type Middleware interface {
-	Process(ctx context.Context, request *ChatRequest, next AIService) (*ChatResponse, error)
+	Chat(ctx context.Context, request *ChatRequest, next ChatHandler) (*ChatResponse, error)
+	ChatStream(ctx context.Context, request *ChatRequest, next StreamHandler) (<-chan *ChatResponse, error)
 }
 
 // Plugin 插件接口
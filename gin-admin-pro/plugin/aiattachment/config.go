@@ -0,0 +1,39 @@
+package aiattachment
+
+// Config 对话附件配置
+type Config struct {
+	// MaxImageSize 图片的最大字节数，模型服务对单张图片通常限制在 5MB 左右
+	MaxImageSize int64 `yaml:"maxImageSize" json:"maxImageSize"`
+	// MaxDocumentSize 文档的最大字节数
+	MaxDocumentSize int64 `yaml:"maxDocumentSize" json:"maxDocumentSize"`
+	// MaxDocumentRunes 文档正文随消息发送的最大字符数，超出部分截断
+	MaxDocumentRunes int `yaml:"maxDocumentRunes" json:"maxDocumentRunes"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		MaxImageSize:     5 * 1024 * 1024,  // 5MB
+		MaxDocumentSize:  10 * 1024 * 1024, // 10MB
+		MaxDocumentRunes: 20000,
+	}
+}
+
+// normalize 未配置的项使用默认值
+func (c *Config) normalize() *Config {
+	defaults := DefaultConfig()
+	if c == nil {
+		return defaults
+	}
+	merged := *c
+	if merged.MaxImageSize <= 0 {
+		merged.MaxImageSize = defaults.MaxImageSize
+	}
+	if merged.MaxDocumentSize <= 0 {
+		merged.MaxDocumentSize = defaults.MaxDocumentSize
+	}
+	if merged.MaxDocumentRunes <= 0 {
+		merged.MaxDocumentRunes = defaults.MaxDocumentRunes
+	}
+	return &merged
+}
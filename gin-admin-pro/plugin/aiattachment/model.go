@@ -0,0 +1,31 @@
+package aiattachment
+
+import "time"
+
+// 附件类型
+const (
+	// TypeImage 图片，以 base64 发送给模型识别
+	TypeImage = "image"
+	// TypeDocument 文本文档，提取正文后随消息发送
+	TypeDocument = "document"
+)
+
+// Attachment 对话附件，上传后在对话消息中按ID引用，只能由上传者使用
+type Attachment struct {
+	ID          uint   `gorm:"primarykey" json:"id"`
+	Type        string `gorm:"size:20;not null" json:"type"`
+	Name        string `gorm:"size:255;not null" json:"name"`
+	FileKey     string `gorm:"size:512;not null" json:"-"`
+	FileURL     string `gorm:"size:512" json:"url"`
+	ContentType string `gorm:"size:100" json:"contentType"`
+	Size        int64  `json:"size"`
+	// Content 文档正文，超出 MaxDocumentRunes 的部分已截断，图片为空
+	Content   string    `gorm:"type:mediumtext" json:"-"`
+	CreateBy  uint      `gorm:"index" json:"createBy"`
+	CreatedAt time.Time `json:"createTime"`
+}
+
+// TableName 设置表名
+func (Attachment) TableName() string {
+	return "ai_attachment"
+}
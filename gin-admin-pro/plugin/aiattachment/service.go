@@ -0,0 +1,142 @@
+package aiattachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gin-admin-pro/plugin/knowledge"
+	"gin-admin-pro/plugin/oss"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnsupportedType 不支持的附件格式
+	ErrUnsupportedType = errors.New("unsupported attachment type")
+	// ErrFileTooLarge 附件超过大小限制
+	ErrFileTooLarge = errors.New("attachment is too large")
+)
+
+// imageTypes 支持的图片类型，按文件内容识别，与主流视觉模型支持的格式一致
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Service 对话附件服务：原文件保存到对象存储，文档正文保存在数据库中
+type Service struct {
+	db      *gorm.DB
+	storage oss.OSSInterface
+	config  *Config
+}
+
+// NewService 创建对话附件服务实例，config 为 nil 时使用默认配置
+func NewService(db *gorm.DB, storage oss.OSSInterface, config *Config) *Service {
+	return &Service{db: db, storage: storage, config: config.normalize()}
+}
+
+// Migrate 创建附件表
+func (s *Service) Migrate() error {
+	return s.db.AutoMigrate(&Attachment{})
+}
+
+// Upload 上传附件：按文件内容识别图片，其他文件按扩展名作为文本文档提取正文
+func (s *Service) Upload(ctx context.Context, filename string, reader io.Reader, userID uint) (*Attachment, error) {
+	maxSize := max(s.config.MaxImageSize, s.config.MaxDocumentSize)
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(filename)
+	attachment := &Attachment{
+		Name:        name,
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+		CreateBy:    userID,
+	}
+	switch {
+	case imageTypes[attachment.ContentType]:
+		if attachment.Size > s.config.MaxImageSize {
+			return nil, ErrFileTooLarge
+		}
+		attachment.Type = TypeImage
+	case knowledge.IsSupported(name):
+		if attachment.Size > s.config.MaxDocumentSize {
+			return nil, ErrFileTooLarge
+		}
+		text, err := knowledge.ExtractText(name, data)
+		if err != nil {
+			return nil, err
+		}
+		attachment.Type = TypeDocument
+		attachment.Content = truncateRunes(text, s.config.MaxDocumentRunes)
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	attachment.FileKey = fmt.Sprintf("ai/%d/%d_%s", userID, time.Now().UnixNano(), strings.ReplaceAll(name, " ", "_"))
+	fileURL, err := s.storage.UploadFile(attachment.FileKey, bytes.NewReader(data), attachment.Size, attachment.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	attachment.FileURL = fileURL
+	if err := s.db.WithContext(ctx).Create(attachment).Error; err != nil {
+		_ = s.storage.DeleteFile(attachment.FileKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetAttachments 按ID顺序获取用户上传的附件，有附件不存在或不属于该用户时返回 gorm.ErrRecordNotFound
+func (s *Service) GetAttachments(ctx context.Context, userID uint, ids []uint) ([]Attachment, error) {
+	var list []Attachment
+	if err := s.db.WithContext(ctx).Where("id IN ? AND create_by = ?", ids, userID).Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]Attachment, len(list))
+	for _, attachment := range list {
+		byID[attachment.ID] = attachment
+	}
+	attachments := make([]Attachment, 0, len(ids))
+	for _, id := range ids {
+		attachment, ok := byID[id]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// LoadImage 从对象存储读取图片内容
+func (s *Service) LoadImage(attachment *Attachment) ([]byte, error) {
+	if attachment.Type != TypeImage {
+		return nil, ErrUnsupportedType
+	}
+	file, err := s.storage.GetFile(attachment.FileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, s.config.MaxImageSize))
+}
+
+// truncateRunes 截断到最多 n 个字符
+func truncateRunes(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n])
+}
@@ -0,0 +1,36 @@
+package aiattachment
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gin-admin-pro/plugin/knowledge"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadValidation(t *testing.T) {
+	service := NewService(nil, nil, &Config{MaxImageSize: 16, MaxDocumentSize: 64})
+	ctx := context.Background()
+
+	// 图片按内容识别，扩展名不影响
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("0", 16)
+	_, err := service.Upload(ctx, "screenshot.txt", strings.NewReader(png), 1)
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+
+	_, err = service.Upload(ctx, "report.pdf", strings.NewReader("%PDF-1.4"), 1)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = service.Upload(ctx, "error.log", strings.NewReader(strings.Repeat("a", 65)), 1)
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+	_, err = service.Upload(ctx, "error.log", strings.NewReader("\xff\xfe"), 1)
+	assert.ErrorIs(t, err, knowledge.ErrInvalidEncoding)
+}
+
+func TestConfigNormalize(t *testing.T) {
+	assert.Equal(t, DefaultConfig(), (*Config)(nil).normalize())
+	config := (&Config{MaxDocumentRunes: 100}).normalize()
+	assert.Equal(t, 100, config.MaxDocumentRunes)
+	assert.Equal(t, DefaultConfig().MaxImageSize, config.MaxImageSize)
+	assert.Equal(t, "你好", truncateRunes("你好世界", 2))
+}
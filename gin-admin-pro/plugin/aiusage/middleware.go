@@ -0,0 +1,58 @@
+package aiusage
+
+import (
+	"context"
+	"strconv"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+)
+
+// BudgetChecker 查询用户所属部门的当月预算状态
+type BudgetChecker interface {
+	CheckBudget(ctx context.Context, userID uint) (*BudgetStatus, error)
+}
+
+// BudgetMiddleware 部门预算中间件，用户所属部门当月费用超出 block 预算时拒绝请求，
+// 语音网关等直接调用 AI 服务的入口同样受预算限制；未携带 UserID 的内部调用不受限制
+type BudgetMiddleware struct {
+	checker BudgetChecker
+}
+
+var _ aiplugin.Middleware = (*BudgetMiddleware)(nil)
+
+// NewBudgetMiddleware 创建部门预算中间件
+func NewBudgetMiddleware(checker BudgetChecker) *BudgetMiddleware {
+	return &BudgetMiddleware{checker: checker}
+}
+
+// Chat 检查部门预算
+func (m *BudgetMiddleware) Chat(ctx context.Context, request *aiplugin.ChatRequest, next aiplugin.ChatHandler) (*aiplugin.ChatResponse, error) {
+	if err := m.check(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+	return next(ctx, request)
+}
+
+// ChatStream 检查部门预算
+func (m *BudgetMiddleware) ChatStream(ctx context.Context, request *aiplugin.ChatRequest, next aiplugin.StreamHandler) (<-chan *aiplugin.ChatResponse, error) {
+	if err := m.check(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+	return next(ctx, request)
+}
+
+// check 预算超出且设置为拒绝时返回 ErrBudgetExceeded
+func (m *BudgetMiddleware) check(ctx context.Context, userID string) error {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil || id == 0 {
+		return nil
+	}
+	status, err := m.checker.CheckBudget(ctx, uint(id))
+	if err != nil {
+		return err
+	}
+	if status != nil && status.Blocked() {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
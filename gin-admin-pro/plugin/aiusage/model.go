@@ -0,0 +1,85 @@
+package aiusage
+
+import "time"
+
+// 预算超出后的处理方式
+const (
+	// BudgetActionBlock 超出预算后拒绝部门成员的 AI 请求
+	BudgetActionBlock = "block"
+	// BudgetActionWarn 超出预算后仍允许请求，回复中附带提醒
+	BudgetActionWarn = "warn"
+)
+
+// UsageLog AI 用量记录，每次模型调用一条，费用单位为美元
+type UsageLog struct {
+	ID               uint      `gorm:"primarykey" json:"id"`
+	UserID           uint      `gorm:"index:idx_ai_usage_user_time,priority:1" json:"userId"`
+	DeptID           uint      `gorm:"index:idx_ai_usage_dept_time,priority:1" json:"deptId"`
+	ConversationID   string    `gorm:"size:64" json:"conversationId"`
+	Provider         string    `gorm:"size:50" json:"provider"`
+	Model            string    `gorm:"size:100;index" json:"model"`
+	Stream           bool      `json:"stream"`
+	Cached           bool      `json:"cached"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	TotalTokens      int       `json:"totalTokens"`
+	Cost             float64   `gorm:"type:decimal(14,6)" json:"cost"`
+	LatencyMs        int64     `json:"latencyMs"`
+	Error            string    `gorm:"size:500" json:"error"`
+	CreatedAt        time.Time `gorm:"index;index:idx_ai_usage_user_time,priority:2;index:idx_ai_usage_dept_time,priority:2" json:"createTime"`
+}
+
+// TableName 表名
+func (UsageLog) TableName() string {
+	return "ai_usage_log"
+}
+
+// DeptBudget 部门每月 AI 费用预算，单位为美元
+type DeptBudget struct {
+	ID           uint    `gorm:"primarykey" json:"id"`
+	DeptID       uint    `gorm:"not null;uniqueIndex" json:"deptId"`
+	MonthlyLimit float64 `gorm:"type:decimal(14,2);not null" json:"monthlyLimit"`
+	// Action 超出预算后的处理方式：block/warn
+	Action string `gorm:"size:10;not null;default:block" json:"action"`
+	// WarnRatio 费用达到预算的该比例时提醒，默认 0.8
+	WarnRatio float64   `gorm:"type:decimal(4,2);default:0.8" json:"warnRatio"`
+	Remark    string    `gorm:"size:500" json:"remark"`
+	CreateBy  uint      `json:"createBy"`
+	UpdateBy  uint      `json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
+}
+
+// TableName 表名
+func (DeptBudget) TableName() string {
+	return "ai_dept_budget"
+}
+
+// BudgetStatus 部门当月预算使用情况
+type BudgetStatus struct {
+	Budget *DeptBudget `json:"budget"`
+	// Month 统计月份，格式 2006-01
+	Month string  `json:"month"`
+	Spent float64 `json:"spent"`
+	// Exceeded 当月费用已达到预算
+	Exceeded bool `json:"exceeded"`
+	// Warning 当月费用已达到提醒比例
+	Warning bool `json:"warning"`
+}
+
+// Blocked 是否拒绝请求
+func (s *BudgetStatus) Blocked() bool {
+	return s.Exceeded && s.Budget.Action == BudgetActionBlock
+}
+
+// newBudgetStatus 根据当月费用计算预算状态
+func newBudgetStatus(budget *DeptBudget, month time.Time, spent float64) *BudgetStatus {
+	status := &BudgetStatus{
+		Budget: budget,
+		Month:  month.Format("2006-01"),
+		Spent:  spent,
+	}
+	status.Exceeded = spent >= budget.MonthlyLimit
+	status.Warning = status.Exceeded || (budget.WarnRatio > 0 && spent >= budget.MonthlyLimit*budget.WarnRatio)
+	return status
+}
@@ -0,0 +1,93 @@
+package aiusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	month := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	budget := &DeptBudget{DeptID: 1, MonthlyLimit: 100, Action: BudgetActionBlock, WarnRatio: 0.8}
+
+	status := newBudgetStatus(budget, month, 50)
+	assert.Equal(t, "2026-03", status.Month)
+	assert.False(t, status.Warning)
+	assert.False(t, status.Blocked())
+
+	status = newBudgetStatus(budget, month, 80)
+	assert.True(t, status.Warning)
+	assert.False(t, status.Exceeded)
+
+	status = newBudgetStatus(budget, month, 100)
+	assert.True(t, status.Exceeded)
+	assert.True(t, status.Blocked())
+
+	// 仅提醒的预算超出后不拒绝请求
+	warn := &DeptBudget{DeptID: 2, MonthlyLimit: 100, Action: BudgetActionWarn}
+	status = newBudgetStatus(warn, month, 99)
+	assert.False(t, status.Warning)
+	status = newBudgetStatus(warn, month, 120)
+	assert.True(t, status.Warning)
+	assert.False(t, status.Blocked())
+}
+
+func TestPeriodExpr(t *testing.T) {
+	expr, err := periodExpr("mysql", "")
+	require.NoError(t, err)
+	assert.Equal(t, "DATE_FORMAT(created_at, '%Y-%m-%d')", expr)
+
+	expr, err = periodExpr("postgres", PeriodMonth)
+	require.NoError(t, err)
+	assert.Equal(t, "TO_CHAR(created_at, 'YYYY-MM')", expr)
+
+	_, err = periodExpr("mysql", "week")
+	assert.Error(t, err)
+
+	start, end := monthRange(time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+// budgetChecker 按用户返回固定的预算状态
+type budgetChecker map[uint]*BudgetStatus
+
+func (c budgetChecker) CheckBudget(ctx context.Context, userID uint) (*BudgetStatus, error) {
+	return c[userID], nil
+}
+
+func TestBudgetMiddleware(t *testing.T) {
+	month := time.Now()
+	middleware := NewBudgetMiddleware(budgetChecker{
+		1: newBudgetStatus(&DeptBudget{DeptID: 1, MonthlyLimit: 10, Action: BudgetActionBlock}, month, 12),
+		2: newBudgetStatus(&DeptBudget{DeptID: 2, MonthlyLimit: 10, Action: BudgetActionWarn}, month, 12),
+	})
+
+	calls := 0
+	next := func(ctx context.Context, request *aiplugin.ChatRequest) (*aiplugin.ChatResponse, error) {
+		calls++
+		return &aiplugin.ChatResponse{}, nil
+	}
+	stream := func(ctx context.Context, request *aiplugin.ChatRequest) (<-chan *aiplugin.ChatResponse, error) {
+		calls++
+		return nil, nil
+	}
+
+	_, err := middleware.Chat(context.Background(), &aiplugin.ChatRequest{UserID: "1"}, next)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	_, err = middleware.ChatStream(context.Background(), &aiplugin.ChatRequest{UserID: "1"}, stream)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, 0, calls)
+
+	// 仅提醒的预算、未设置预算的部门和内部调用不拒绝
+	for _, userID := range []string{"2", "3", ""} {
+		_, err = middleware.Chat(context.Background(), &aiplugin.ChatRequest{UserID: userID}, next)
+		assert.NoError(t, err, userID)
+	}
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,324 @@
+package aiusage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBudgetExceeded 部门当月 AI 费用已超出预算
+var ErrBudgetExceeded = errors.New("department monthly ai budget exceeded")
+
+// 汇总维度
+const (
+	GroupByUser  = "user"
+	GroupByDept  = "dept"
+	GroupByModel = "model"
+)
+
+// 汇总周期
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// DeptResolver 查询用户当前所属部门
+type DeptResolver func(ctx context.Context, userID uint) (uint, error)
+
+// LogQuery 用量记录查询条件，时间范围为 [Start, End)
+type LogQuery struct {
+	UserID uint
+	DeptID uint
+	Model  string
+	Start  time.Time
+	End    time.Time
+}
+
+// SummaryQuery 用量汇总查询条件，时间范围为 [Start, End)
+type SummaryQuery struct {
+	LogQuery
+	GroupBy string
+	Period  string
+}
+
+// SummaryRow 一个周期内一个维度的用量汇总，按维度只填写 UserID、DeptID 或 Model 中的一项
+type SummaryRow struct {
+	Period           string  `json:"period"`
+	UserID           uint    `json:"userId,omitempty"`
+	DeptID           uint    `json:"deptId,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"promptTokens"`
+	CompletionTokens int64   `json:"completionTokens"`
+	TotalTokens      int64   `json:"totalTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Service AI 用量服务：记录每次模型调用，按用户、部门、模型汇总，并按部门月度预算限制
+type Service struct {
+	db          *gorm.DB
+	resolveDept DeptResolver
+}
+
+// NewService 创建用量服务，resolveDept 用于记录调用用户所属部门
+func NewService(db *gorm.DB, resolveDept DeptResolver) *Service {
+	return &Service{db: db, resolveDept: resolveDept}
+}
+
+// Migrate 创建用量和预算表
+func (s *Service) Migrate() error {
+	return s.db.AutoMigrate(&UsageLog{}, &DeptBudget{})
+}
+
+// Record 保存一次模型调用的用量，实现 ai.UsageRecorder；写入失败只记录日志，不影响对话
+func (s *Service) Record(ctx context.Context, record *aiplugin.UsageRecord) {
+	userID, _ := strconv.ParseUint(record.UserID, 10, 64)
+	usage := &UsageLog{
+		UserID:           uint(userID),
+		ConversationID:   record.ConversationID,
+		Provider:         record.Provider,
+		Model:            record.Model,
+		Stream:           record.Stream,
+		Cached:           record.Cached,
+		PromptTokens:     record.PromptTokens,
+		CompletionTokens: record.CompletionTokens,
+		TotalTokens:      record.TotalTokens,
+		Cost:             record.Cost,
+		LatencyMs:        record.Latency.Milliseconds(),
+		Error:            truncate(record.Error, 500),
+		CreatedAt:        record.Timestamp,
+	}
+	if usage.UserID > 0 && s.resolveDept != nil {
+		deptID, err := s.resolveDept(ctx, usage.UserID)
+		if err != nil {
+			log.Printf("AI usage resolve dept failed: %v", err)
+		}
+		usage.DeptID = deptID
+	}
+	if err := s.db.WithContext(ctx).Create(usage).Error; err != nil {
+		log.Printf("AI usage record failed: %v", err)
+	}
+}
+
+// GetLogs 分页查询用量记录，按时间倒序
+func (s *Service) GetLogs(query *LogQuery, page, pageSize int) ([]UsageLog, int64, error) {
+	db := s.filter(query)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []UsageLog
+	err := db.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
+
+// Summary 按周期和维度汇总用量，按周期、费用倒序
+func (s *Service) Summary(query *SummaryQuery) ([]SummaryRow, error) {
+	var column string
+	switch query.GroupBy {
+	case GroupByUser:
+		column = "user_id"
+	case GroupByDept:
+		column = "dept_id"
+	case GroupByModel:
+		column = "model"
+	default:
+		return nil, fmt.Errorf("unsupported group by: %s", query.GroupBy)
+	}
+	period, err := periodExpr(s.db.Dialector.Name(), query.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []SummaryRow
+	err = s.filter(&query.LogQuery).
+		Select(period + " AS period, " + column + ", COUNT(*) AS calls, " +
+			"SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, " +
+			"SUM(total_tokens) AS total_tokens, SUM(cost) AS cost").
+		Group(period + ", " + column).
+		Order("period DESC, cost DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// filter 按查询条件过滤用量记录
+func (s *Service) filter(query *LogQuery) *gorm.DB {
+	db := s.db.Model(&UsageLog{})
+	if query.UserID > 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.DeptID > 0 {
+		db = db.Where("dept_id = ?", query.DeptID)
+	}
+	if query.Model != "" {
+		db = db.Where("model = ?", query.Model)
+	}
+	if !query.Start.IsZero() {
+		db = db.Where("created_at >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("created_at < ?", query.End)
+	}
+	return db
+}
+
+// periodExpr 按数据库方言返回汇总周期的 SQL 表达式
+func periodExpr(dialect, period string) (string, error) {
+	layouts := map[string]map[string]string{
+		"mysql":    {PeriodDay: "DATE_FORMAT(created_at, '%Y-%m-%d')", PeriodMonth: "DATE_FORMAT(created_at, '%Y-%m')"},
+		"postgres": {PeriodDay: "TO_CHAR(created_at, 'YYYY-MM-DD')", PeriodMonth: "TO_CHAR(created_at, 'YYYY-MM')"},
+		"sqlite":   {PeriodDay: "STRFTIME('%Y-%m-%d', created_at)", PeriodMonth: "STRFTIME('%Y-%m', created_at)"},
+	}
+	exprs, ok := layouts[dialect]
+	if !ok {
+		exprs = layouts["mysql"]
+	}
+	if period == "" {
+		period = PeriodDay
+	}
+	expr, ok := exprs[period]
+	if !ok {
+		return "", fmt.Errorf("unsupported period: %s", period)
+	}
+	return expr, nil
+}
+
+// MonthCost 部门指定月份的费用合计
+func (s *Service) MonthCost(ctx context.Context, deptID uint, month time.Time) (float64, error) {
+	start, end := monthRange(month)
+	var cost float64
+	err := s.db.WithContext(ctx).Model(&UsageLog{}).
+		Select("COALESCE(SUM(cost), 0)").
+		Where("dept_id = ? AND created_at >= ? AND created_at < ?", deptID, start, end).
+		Scan(&cost).Error
+	return cost, err
+}
+
+// MonthCosts 各部门指定月份的费用合计
+func (s *Service) MonthCosts(ctx context.Context, month time.Time) (map[uint]float64, error) {
+	start, end := monthRange(month)
+	var rows []struct {
+		DeptID uint
+		Cost   float64
+	}
+	err := s.db.WithContext(ctx).Model(&UsageLog{}).
+		Select("dept_id, SUM(cost) AS cost").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("dept_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		costs[row.DeptID] = row.Cost
+	}
+	return costs, nil
+}
+
+// GetBudgets 获取全部部门预算
+func (s *Service) GetBudgets() ([]DeptBudget, error) {
+	var budgets []DeptBudget
+	err := s.db.Order("dept_id").Find(&budgets).Error
+	return budgets, err
+}
+
+// GetBudget 获取部门预算，未设置时返回 nil
+func (s *Service) GetBudget(ctx context.Context, deptID uint) (*DeptBudget, error) {
+	var budget DeptBudget
+	err := s.db.WithContext(ctx).Where("dept_id = ?", deptID).First(&budget).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// SaveBudget 设置部门预算，部门已有预算时覆盖
+func (s *Service) SaveBudget(budget *DeptBudget) error {
+	if budget.Action == "" {
+		budget.Action = BudgetActionBlock
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dept_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"monthly_limit", "action", "warn_ratio", "remark", "update_by", "updated_at"}),
+	}).Create(budget).Error
+}
+
+// DeleteBudget 删除部门预算
+func (s *Service) DeleteBudget(id uint) error {
+	result := s.db.Delete(&DeptBudget{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CheckBudget 检查用户所属部门当月预算，部门未设置预算时返回 nil
+func (s *Service) CheckBudget(ctx context.Context, userID uint) (*BudgetStatus, error) {
+	if s.resolveDept == nil {
+		return nil, nil
+	}
+	deptID, err := s.resolveDept(ctx, userID)
+	if err != nil || deptID == 0 {
+		return nil, err
+	}
+	budget, err := s.GetBudget(ctx, deptID)
+	if err != nil || budget == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	spent, err := s.MonthCost(ctx, deptID, now)
+	if err != nil {
+		return nil, err
+	}
+	return newBudgetStatus(budget, now, spent), nil
+}
+
+// BudgetStatuses 全部部门预算及指定月份的使用情况
+func (s *Service) BudgetStatuses(ctx context.Context, month time.Time) ([]*BudgetStatus, error) {
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.MonthCosts(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*BudgetStatus, len(budgets))
+	for i := range budgets {
+		statuses[i] = newBudgetStatus(&budgets[i], month, costs[budgets[i].DeptID])
+	}
+	return statuses, nil
+}
+
+// monthRange 月份的起止时间 [start, end)
+func monthRange(month time.Time) (time.Time, time.Time) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// truncate 按字符截断
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length])
+}
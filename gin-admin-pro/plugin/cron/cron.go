@@ -323,17 +323,8 @@ func (cm *CronManager) AddJob(config *JobConfig) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	if err := cm.AddJobFromConfig(config); err != nil {
-		return err
-	}
-
-	// 如果管理器已启动且任务启用，立即调度
-	if cm.started && config.Enabled {
-		job := cm.jobs[config.ID]
-		return cm.scheduleJob(job)
-	}
-
-	return nil
+	// 管理器已启动且任务启用时，AddJobFromConfig 会立即调度
+	return cm.AddJobFromConfig(config)
 }
 
 // RemoveJob 移除任务
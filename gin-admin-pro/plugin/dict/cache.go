@@ -0,0 +1,52 @@
+package dict
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// dataCacheKey 字典数据缓存键
+func (s *Service) dataCacheKey(dictType string) string {
+	return s.config.CachePrefix + "data:" + dictType
+}
+
+// allCacheKey 全量字典数据精简列表缓存键
+func (s *Service) allCacheKey() string {
+	return s.config.CachePrefix + "data:_all"
+}
+
+// getCache 读取缓存，未命中或未启用缓存时返回 false
+func (s *Service) getCache(key string, obj interface{}) bool {
+	if s.cache == nil {
+		return false
+	}
+	return s.cache.GetJSON(context.Background(), key, obj) == nil
+}
+
+// setCache 写入缓存，失败时仅记录日志
+func (s *Service) setCache(key string, obj interface{}) {
+	if s.cache == nil {
+		return
+	}
+	expiration := time.Duration(s.config.CacheExpire) * time.Second
+	if err := s.cache.SetJSON(context.Background(), key, obj, expiration); err != nil {
+		log.Printf("Failed to set dict cache %s: %v", key, err)
+	}
+}
+
+// evictDictCache 清除指定字典类型及全量列表的缓存
+func (s *Service) evictDictCache(dictTypes ...string) {
+	if s.cache == nil {
+		return
+	}
+	keys := []string{s.allCacheKey()}
+	for _, dictType := range dictTypes {
+		if dictType != "" {
+			keys = append(keys, s.dataCacheKey(dictType))
+		}
+	}
+	if err := s.cache.Del(context.Background(), keys...); err != nil {
+		log.Printf("Failed to evict dict cache %v: %v", keys, err)
+	}
+}
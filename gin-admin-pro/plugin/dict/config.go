@@ -1,6 +1,10 @@
 package dict
 
 import (
+	"sync"
+
+	"gin-admin-pro/plugin/redis"
+
 	"gorm.io/gorm"
 )
 
@@ -40,8 +44,11 @@ func DefaultConfig() *Config {
 
 // Plugin 字典插件
 type Plugin struct {
-	config *Config
-	db     *gorm.DB
+	config  *Config
+	db      *gorm.DB
+	cache   redis.Cache
+	service *Service
+	once    sync.Once
 }
 
 // NewPlugin 创建字典插件
@@ -70,12 +77,24 @@ func (p *Plugin) IsEnabled() bool {
 	return p.config.Enabled
 }
 
-// GetService 获取字典服务
+// SetCache 设置字典缓存（如 redis.NewRedisCache），需在 GetService 之前调用；未设置时使用内存缓存
+func (p *Plugin) SetCache(cache redis.Cache) {
+	p.cache = cache
+}
+
+// GetService 获取字典服务（单例，保证缓存共享）
 func (p *Plugin) GetService() *Service {
 	if !p.IsEnabled() {
 		return nil
 	}
-	return NewService(p.db)
+	p.once.Do(func() {
+		cache := p.cache
+		if cache == nil && p.config.EnableCache {
+			cache = redis.NewMemoryCache()
+		}
+		p.service = NewCachedService(p.db, cache, p.config)
+	})
+	return p.service
 }
 
 // AutoMigrate 自动迁移数据库表
@@ -1,36 +1,50 @@
 package dict
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
+	"gin-admin-pro/plugin/redis"
+
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrDictTypeExists 字典类型标识已存在
+	ErrDictTypeExists = errors.New("字典类型已存在")
+	// ErrDictTypeHasData 字典类型下存在字典数据
+	ErrDictTypeHasData = errors.New("该字典类型下存在字典数据，无法删除")
+	// ErrDictTypeUnavailable 字典类型不存在或已禁用
+	ErrDictTypeUnavailable = errors.New("字典类型不存在或已禁用")
+)
+
 // DictType 字典类型
 type DictType struct {
-	ID       int    `gorm:"primarykey" json:"id"`
-	Name     string `gorm:"size:100;not null" json:"name"`
-	Type     string `gorm:"size:100;not null;uniqueIndex" json:"type"`
-	Status   int    `gorm:"default:1" json:"status"` // 0-禁用 1-启用
-	Remark   string `gorm:"size:500" json:"remark"`
-	CreateBy uint   `json:"createBy"`
-	UpdateBy uint   `json:"updateBy"`
-	CreateAt uint   `json:"createAt"`
-	UpdateAt uint   `json:"updateAt"`
+	ID        int       `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Type      string    `gorm:"size:100;not null;uniqueIndex" json:"type"`
+	Status    int       `gorm:"default:1" json:"status" dict:"system_status"` // 0-禁用 1-启用
+	Remark    string    `gorm:"size:500" json:"remark"`
+	CreateBy  uint      `json:"createBy"`
+	UpdateBy  uint      `json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
 }
 
 // DictData 字典数据
 type DictData struct {
-	ID       int    `gorm:"primarykey" json:"id"`
-	DictSort int    `gorm:"default:0" json:"dictSort"`
-	Label    string `gorm:"size:100;not null" json:"label"`
-	Value    string `gorm:"size:100;not null" json:"value"`
-	DictType string `gorm:"size:100;not null;index" json:"dictType"`
-	Status   int    `gorm:"default:1" json:"status"` // 0-禁用 1-启用
-	Remark   string `gorm:"size:500" json:"remark"`
-	CreateBy uint   `json:"createBy"`
-	UpdateBy uint   `json:"updateBy"`
-	CreateAt uint   `json:"createAt"`
-	UpdateAt uint   `json:"updateAt"`
+	ID        int       `gorm:"primarykey" json:"id"`
+	DictSort  int       `gorm:"default:0" json:"dictSort"`
+	Label     string    `gorm:"size:100;not null" json:"label"`
+	Value     string    `gorm:"size:100;not null" json:"value"`
+	DictType  string    `gorm:"size:100;not null;index" json:"dictType"`
+	Status    int       `gorm:"default:1" json:"status" dict:"system_status"` // 0-禁用 1-启用
+	Remark    string    `gorm:"size:500" json:"remark"`
+	CreateBy  uint      `json:"createBy"`
+	UpdateBy  uint      `json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
 }
 
 // TableName 设置表名
@@ -42,14 +56,34 @@ func (DictData) TableName() string {
 	return "system_dict_data"
 }
 
+// DictDataSimple 字典数据精简信息（前端启动时全量加载）
+type DictDataSimple struct {
+	DictType string `json:"dictType"`
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+}
+
 // Service 字典服务
 type Service struct {
-	db *gorm.DB
+	db     *gorm.DB
+	cache  redis.Cache
+	config *Config
 }
 
-// NewService 创建字典服务
+// NewService 创建字典服务（不使用缓存）
 func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+	return &Service{db: db, config: DefaultConfig()}
+}
+
+// NewCachedService 创建带缓存的字典服务，cache 为空或未启用缓存时直接查询数据库
+func NewCachedService(db *gorm.DB, cache redis.Cache, config *Config) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if !config.EnableCache {
+		cache = nil
+	}
+	return &Service{db: db, cache: cache, config: config}
 }
 
 // GetDictTypes 获取字典类型列表
@@ -115,14 +149,19 @@ func (s *Service) CreateDictType(dictType *DictType) error {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("字典类型 %s 已存在", dictType.Type)
+		return fmt.Errorf("%w：%s", ErrDictTypeExists, dictType.Type)
 	}
 
-	return s.db.Create(dictType).Error
+	return createWithStatus(s.db, dictType, &dictType.Status)
 }
 
-// UpdateDictType 更新字典类型
+// UpdateDictType 更新字典类型，类型标识变更时同步更新字典数据
 func (s *Service) UpdateDictType(dictType *DictType) error {
+	var old DictType
+	if err := s.db.First(&old, dictType.ID).Error; err != nil {
+		return err
+	}
+
 	// 检查类型是否被其他记录使用
 	if dictType.Type != "" {
 		var count int64
@@ -131,11 +170,29 @@ func (s *Service) UpdateDictType(dictType *DictType) error {
 			return err
 		}
 		if count > 0 {
-			return fmt.Errorf("字典类型 %s 已存在", dictType.Type)
+			return fmt.Errorf("%w：%s", ErrDictTypeExists, dictType.Type)
+		}
+	} else {
+		dictType.Type = old.Type
+	}
+	dictType.CreateBy = old.CreateBy
+	dictType.CreatedAt = old.CreatedAt
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(dictType).Error; err != nil {
+			return err
+		}
+		if dictType.Type != old.Type {
+			return tx.Model(&DictData{}).Where("dict_type = ?", old.Type).Update("dict_type", dictType.Type).Error
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return s.db.Save(dictType).Error
+	s.evictDictCache(old.Type, dictType.Type)
+	return nil
 }
 
 // DeleteDictType 删除字典类型
@@ -147,7 +204,7 @@ func (s *Service) DeleteDictType(id int) error {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("该字典类型下存在字典数据，无法删除")
+		return ErrDictTypeHasData
 	}
 
 	return s.db.Delete(&DictType{}, id).Error
@@ -197,76 +254,152 @@ func (s *Service) GetDictDataByID(id int) (*DictData, error) {
 	return &dictData, nil
 }
 
-// GetDictDataByType 根据字典类型获取所有启用的字典数据
+// GetDictDataByType 根据字典类型获取所有启用的字典数据（优先读取缓存）
 func (s *Service) GetDictDataByType(dictType string) ([]DictData, error) {
 	var dictData []DictData
+	if s.getCache(s.dataCacheKey(dictType), &dictData) {
+		return dictData, nil
+	}
+
 	err := s.db.Where("dict_type = ? AND status = 1", dictType).
 		Order("dict_sort ASC, id ASC").
 		Find(&dictData).Error
 	if err != nil {
 		return nil, err
 	}
+
+	s.setCache(s.dataCacheKey(dictType), dictData)
 	return dictData, nil
 }
 
+// GetAllDictDataSimple 获取全部启用的字典数据精简列表（优先读取缓存）
+func (s *Service) GetAllDictDataSimple() ([]DictDataSimple, error) {
+	var list []DictDataSimple
+	if s.getCache(s.allCacheKey(), &list) {
+		return list, nil
+	}
+
+	err := s.db.Model(&DictData{}).
+		Select("system_dict_data.dict_type, system_dict_data.value, system_dict_data.label").
+		Joins("JOIN system_dict_type ON system_dict_type.type = system_dict_data.dict_type AND system_dict_type.status = 1").
+		Where("system_dict_data.status = 1").
+		Order("system_dict_data.dict_type ASC, system_dict_data.dict_sort ASC, system_dict_data.id ASC").
+		Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.setCache(s.allCacheKey(), list)
+	return list, nil
+}
+
 // CreateDictData 创建字典数据
 func (s *Service) CreateDictData(dictData *DictData) error {
 	// 检查字典类型是否存在
 	var dictType DictType
 	err := s.db.Where("type = ? AND status = 1", dictData.DictType).First(&dictType).Error
 	if err != nil {
-		return fmt.Errorf("字典类型 %s 不存在或已禁用", dictData.DictType)
+		return fmt.Errorf("%w：%s", ErrDictTypeUnavailable, dictData.DictType)
+	}
+
+	if err := createWithStatus(s.db, dictData, &dictData.Status); err != nil {
+		return err
 	}
 
-	return s.db.Create(dictData).Error
+	s.evictDictCache(dictData.DictType)
+	return nil
 }
 
 // UpdateDictData 更新字典数据
 func (s *Service) UpdateDictData(dictData *DictData) error {
+	var old DictData
+	if err := s.db.First(&old, dictData.ID).Error; err != nil {
+		return err
+	}
+
 	// 检查字典类型是否存在
 	if dictData.DictType != "" {
 		var dictType DictType
 		err := s.db.Where("type = ? AND status = 1", dictData.DictType).First(&dictType).Error
 		if err != nil {
-			return fmt.Errorf("字典类型 %s 不存在或已禁用", dictData.DictType)
+			return fmt.Errorf("%w：%s", ErrDictTypeUnavailable, dictData.DictType)
 		}
+	} else {
+		dictData.DictType = old.DictType
 	}
+	dictData.CreateBy = old.CreateBy
+	dictData.CreatedAt = old.CreatedAt
 
-	return s.db.Save(dictData).Error
+	if err := s.db.Save(dictData).Error; err != nil {
+		return err
+	}
+
+	s.evictDictCache(old.DictType, dictData.DictType)
+	return nil
 }
 
 // DeleteDictData 删除字典数据
 func (s *Service) DeleteDictData(id int) error {
-	return s.db.Delete(&DictData{}, id).Error
+	var old DictData
+	if err := s.db.First(&old, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(&DictData{}, id).Error; err != nil {
+		return err
+	}
+
+	s.evictDictCache(old.DictType)
+	return nil
 }
 
 // GetDictValueByLabel 根据字典类型和标签获取值
 func (s *Service) GetDictValueByLabel(dictType, label string) (string, error) {
-	var dictData DictData
-	err := s.db.Where("dict_type = ? AND label = ? AND status = 1", dictType, label).
-		First(&dictData).Error
+	dictData, err := s.GetDictDataByType(dictType)
 	if err != nil {
 		return "", err
 	}
-	return dictData.Value, nil
+	for _, item := range dictData {
+		if item.Label == label {
+			return item.Value, nil
+		}
+	}
+	return "", gorm.ErrRecordNotFound
 }
 
 // GetDictLabelByValue 根据字典类型和值获取标签
 func (s *Service) GetDictLabelByValue(dictType, value string) (string, error) {
-	var dictData DictData
-	err := s.db.Where("dict_type = ? AND value = ? AND status = 1", dictType, value).
-		First(&dictData).Error
+	dictData, err := s.GetDictDataByType(dictType)
 	if err != nil {
 		return "", err
 	}
-	return dictData.Label, nil
+	for _, item := range dictData {
+		if item.Value == value {
+			return item.Label, nil
+		}
+	}
+	return "", gorm.ErrRecordNotFound
 }
 
-// RefreshDictCache 刷新字典缓存
+// RefreshDictCache 刷新字典缓存：清除全部字典缓存并重新加载
 func (s *Service) RefreshDictCache() error {
-	// 这里可以实现缓存刷新逻辑
-	// 比如清除Redis中的字典缓存
-	return nil
+	if s.cache == nil {
+		return nil
+	}
+
+	var types []string
+	if err := s.db.Model(&DictType{}).Pluck("type", &types).Error; err != nil {
+		return err
+	}
+	s.evictDictCache(types...)
+
+	for _, dictType := range types {
+		if _, err := s.GetDictDataByType(dictType); err != nil {
+			return err
+		}
+	}
+	_, err := s.GetAllDictDataSimple()
+	return err
 }
 
 // ExportDictType 导出字典类型
@@ -323,7 +456,23 @@ func (s *Service) ValidateDictType(dictType string) error {
 		return err
 	}
 	if count == 0 {
-		return fmt.Errorf("字典类型 %s 不存在或已禁用", dictType)
+		return fmt.Errorf("%w：%s", ErrDictTypeUnavailable, dictType)
 	}
 	return nil
 }
+
+// createWithStatus 创建记录并保留传入的状态。status 列带默认值，GORM 创建时会把 0 替换为默认值 1，
+// 禁用状态需在同一事务中单独写回
+func createWithStatus(db *gorm.DB, value interface{}, status *int) error {
+	want := *status
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(value).Error; err != nil {
+			return err
+		}
+		if *status == want {
+			return nil
+		}
+		*status = want
+		return tx.Model(value).UpdateColumn("status", want).Error
+	})
+}
@@ -2,6 +2,8 @@ package dict
 
 import (
 	"testing"
+
+	"gin-admin-pro/plugin/redis"
 )
 
 func TestGetDataScopeName(t *testing.T) {
@@ -119,3 +121,75 @@ func TestService(t *testing.T) {
 		t.Error("Disabled plugin service should be nil")
 	}
 }
+
+func TestCachedService(t *testing.T) {
+	cache := redis.NewMemoryCache()
+	service := NewCachedService(nil, cache, DefaultConfig())
+
+	if service.dataCacheKey("system_status") != "dict:data:system_status" {
+		t.Errorf("Unexpected data cache key: %s", service.dataCacheKey("system_status"))
+	}
+
+	// 预置缓存，命中时不访问数据库
+	service.setCache(service.dataCacheKey("system_status"), []DictData{
+		{ID: 1, Label: "启用", Value: "1", DictType: "system_status", Status: 1},
+		{ID: 2, Label: "禁用", Value: "0", DictType: "system_status", Status: 1},
+	})
+	service.setCache(service.allCacheKey(), []DictDataSimple{
+		{DictType: "system_status", Label: "启用", Value: "1"},
+	})
+
+	data, err := service.GetDictDataByType("system_status")
+	if err != nil {
+		t.Fatalf("GetDictDataByType should hit cache: %v", err)
+	}
+	if len(data) != 2 {
+		t.Errorf("Expected 2 dict data, got %d", len(data))
+	}
+
+	label, err := service.GetDictLabelByValue("system_status", "0")
+	if err != nil || label != "禁用" {
+		t.Errorf("GetDictLabelByValue = %s, %v", label, err)
+	}
+
+	value, err := service.GetDictValueByLabel("system_status", "启用")
+	if err != nil || value != "1" {
+		t.Errorf("GetDictValueByLabel = %s, %v", value, err)
+	}
+
+	if _, err := service.GetDictLabelByValue("system_status", "9"); err == nil {
+		t.Error("GetDictLabelByValue should fail for unknown value")
+	}
+
+	list, err := service.GetAllDictDataSimple()
+	if err != nil || len(list) != 1 {
+		t.Errorf("GetAllDictDataSimple should hit cache, got %v, %v", list, err)
+	}
+
+	// 写操作后清除缓存
+	service.evictDictCache("system_status")
+	var cached []DictData
+	if service.getCache(service.dataCacheKey("system_status"), &cached) {
+		t.Error("Dict data cache should be evicted")
+	}
+	if service.getCache(service.allCacheKey(), &list) {
+		t.Error("Simple list cache should be evicted")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	service := NewCachedService(nil, redis.NewMemoryCache(), &Config{EnableCache: false, CachePrefix: "dict:"})
+	if service.cache != nil {
+		t.Error("Cache should be nil when disabled")
+	}
+
+	// 未启用缓存时刷新为空操作
+	if err := service.RefreshDictCache(); err != nil {
+		t.Errorf("RefreshDictCache should be no-op without cache, got %v", err)
+	}
+
+	plugin := NewPlugin(nil, nil)
+	if plugin.GetService() != plugin.GetService() {
+		t.Error("Plugin service should be a singleton")
+	}
+}
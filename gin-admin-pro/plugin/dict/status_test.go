@@ -0,0 +1,72 @@
+package dict
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 GORM 生成的 SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+// dryRunPool 不连接数据库的连接池，支持开启事务
+type dryRunPool struct{ gorm.ConnPool }
+
+func (p dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+// newDryRunDB 不连接数据库的 GORM 实例，只生成 SQL
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunPool{}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	require.NoError(t, err)
+	return db, recorder
+}
+
+func TestCreateDisabled(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	service := NewService(db)
+
+	// 状态 0 不被列默认值覆盖
+	dictType := &DictType{ID: 5, Name: "性别", Type: "user_sex", Status: 0}
+	require.NoError(t, service.CreateDictType(dictType))
+	assert.Equal(t, 0, dictType.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "system_dict_type" SET "status"=0 WHERE "id" = 5`)
+
+	recorder.statements = nil
+	dictData := &DictData{ID: 6, Label: "男", Value: "1", DictType: "user_sex", Status: 0}
+	require.NoError(t, service.CreateDictData(dictData))
+	assert.Equal(t, 0, dictData.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "system_dict_data" SET "status"=0 WHERE "id" = 6`)
+
+	// 启用状态只需插入
+	recorder.statements = nil
+	require.NoError(t, service.CreateDictType(&DictType{ID: 7, Name: "状态", Type: "common", Status: 1}))
+	for _, statement := range recorder.statements {
+		assert.NotContains(t, statement, "UPDATE", statement)
+	}
+}
@@ -0,0 +1,363 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRequest 假 Elasticsearch 收到的请求
+type fakeRequest struct {
+	Method      string
+	Path        string
+	Query       string
+	ContentType string
+	Body        string
+}
+
+// newFakeClient 启动按 "METHOD path" 路由的假 Elasticsearch，未配置的路由返回 404
+func newFakeClient(t *testing.T, routes map[string]func(w http.ResponseWriter, r *http.Request)) (*Client, *[]fakeRequest) {
+	var requests []fakeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, fakeRequest{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Query:       r.URL.RawQuery,
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        string(body),
+		})
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		if handler, ok := routes[r.Method+" "+r.URL.Path]; ok {
+			handler(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":{"type":"index_not_found_exception","reason":"no such index"},"status":404}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := DefaultConfig()
+	cfg.Addresses = []string{server.URL}
+	cfg.Username = "elastic"
+	cfg.Password = "secret"
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	return client, &requests
+}
+
+func reply(status int, body string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestClientDisabled(t *testing.T) {
+	client, err := NewClient(&Config{Enabled: false})
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.Ping(), ErrDisabled)
+	_, err = client.Search(context.Background(), "users", &SearchRequest{})
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = NewClient(&Config{Enabled: true})
+	assert.ErrorIs(t, err, ErrNoAddress)
+}
+
+func TestClientAuthAndHealth(t *testing.T) {
+	client, _ := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"GET /_cluster/health": func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || username != "elastic" || password != "secret" {
+				reply(http.StatusUnauthorized, `{"error":{"type":"security_exception","reason":"missing authentication"}}`)(w, r)
+				return
+			}
+			reply(http.StatusOK, `{"cluster_name":"test","status":"yellow"}`)(w, r)
+		},
+	})
+
+	health, err := client.Health()
+	require.NoError(t, err)
+	assert.Equal(t, "警告", GetHealthStatus(health["status"].(string)))
+}
+
+func TestClientRetry(t *testing.T) {
+	attempts := 0
+	client, _ := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"GET /": func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				reply(http.StatusServiceUnavailable, `{}`)(w, r)
+				return
+			}
+			reply(http.StatusOK, `{"version":{"number":"8.13.0"}}`)(w, r)
+		},
+	})
+	require.NoError(t, client.Ping())
+	assert.Equal(t, 3, attempts)
+
+	// 超过重试次数返回最后一次的错误
+	client.config.MaxRetries = 0
+	attempts = 0
+	var respErr *ResponseError
+	require.ErrorAs(t, client.Ping(), &respErr)
+	assert.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+
+	// 节点不可用时切换到下一个节点
+	client.config.MaxRetries = 1
+	client.config.Addresses = append([]string{"http://127.0.0.1:1"}, client.config.Addresses...)
+	client.next.Store(0)
+	attempts = 2
+	assert.NoError(t, client.Ping())
+}
+
+func TestIndexManagement(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"PUT /users_v2":                 reply(http.StatusOK, `{"acknowledged":true}`),
+		"PUT /users_v1":                 reply(http.StatusBadRequest, `{"error":{"type":"resource_already_exists_exception","reason":"index [users_v1] already exists"},"status":400}`),
+		"HEAD /users_v1":                reply(http.StatusOK, ``),
+		"PUT /_index_template/users":    reply(http.StatusOK, `{"acknowledged":true}`),
+		"GET /_alias/users":             reply(http.StatusOK, `{"users_v1":{"aliases":{"users":{}}}}`),
+		"POST /_aliases":                reply(http.StatusOK, `{"acknowledged":true}`),
+		"DELETE /_index_template/users": reply(http.StatusNotFound, `{"error":{"type":"resource_not_found_exception","reason":"index template matching [users] not found"},"status":404}`),
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateIndex(ctx, "users_v2", map[string]interface{}{"settings": map[string]interface{}{"number_of_shards": 1}}))
+	err := client.CreateIndex(ctx, "users_v1", nil)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Contains(t, err.Error(), "already exists")
+
+	exists, err := client.IndexExists(ctx, "users_v1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = client.IndexExists(ctx, "users_v3")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, client.PutIndexTemplate(ctx, "users", map[string]interface{}{"index_patterns": []string{"users_*"}}))
+	assert.ErrorIs(t, client.DeleteIndexTemplate(ctx, "users"), ErrNotFound)
+
+	indices, err := client.GetAliasIndices(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, indices)
+
+	require.NoError(t, client.SwitchAlias(ctx, "users", "users_v2"))
+	last := (*requests)[len(*requests)-1]
+	assert.JSONEq(t, `{"actions":[
+		{"remove":{"index":"users_v1","alias":"users"}},
+		{"add":{"index":"users_v2","alias":"users"}}
+	]}`, last.Body)
+}
+
+func TestDocuments(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"PUT /users/_doc/1":    reply(http.StatusCreated, `{"_id":"1","result":"created"}`),
+		"GET /users/_doc/1":    reply(http.StatusOK, `{"_id":"1","found":true,"_source":{"username":"admin"}}`),
+		"GET /users/_doc/2":    reply(http.StatusNotFound, `{"_id":"2","found":false}`),
+		"DELETE /users/_doc/1": reply(http.StatusOK, `{"result":"deleted"}`),
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.IndexDocument(ctx, "users", "1", map[string]string{"username": "admin"}))
+	assert.JSONEq(t, `{"username":"admin"}`, (*requests)[0].Body)
+	assert.Equal(t, "application/json", (*requests)[0].ContentType)
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	require.NoError(t, client.GetDocument(ctx, "users", "1", &user))
+	assert.Equal(t, "admin", user.Username)
+	assert.ErrorIs(t, client.GetDocument(ctx, "users", "2", &user), ErrNotFound)
+	require.NoError(t, client.DeleteDocument(ctx, "users", "1"))
+}
+
+func TestBulk(t *testing.T) {
+	var lines []map[string]interface{}
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"POST /_bulk": func(w http.ResponseWriter, r *http.Request) {
+			lines = nil
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				lines = append(lines, line)
+			}
+			reply(http.StatusOK, `{"took":3,"errors":true,"items":[
+				{"index":{"_index":"users","_id":"1","status":201,"result":"created"}},
+				{"update":{"_index":"users","_id":"2","status":404,"error":{"type":"document_missing_exception","reason":"[2]: document missing"}}},
+				{"delete":{"_index":"users","_id":"3","status":200,"result":"deleted"}}
+			]}`)(w, r)
+		},
+	})
+	ctx := context.Background()
+
+	resp, err := client.Bulk(ctx, []BulkAction{
+		{Op: BulkIndex, Index: "users", ID: "1", Doc: map[string]string{"username": "admin"}},
+		{Op: BulkUpdate, Index: "users", ID: "2", Doc: map[string]int{"status": 1}},
+		{Op: BulkDelete, Index: "users", ID: "3"},
+	})
+	require.NotNil(t, resp)
+	assert.Len(t, resp.Items, 3)
+	assert.Equal(t, "application/x-ndjson", (*requests)[0].ContentType)
+	assert.Equal(t, []map[string]interface{}{
+		{"index": map[string]interface{}{"_index": "users", "_id": "1"}},
+		{"username": "admin"},
+		{"update": map[string]interface{}{"_index": "users", "_id": "2"}},
+		{"doc": map[string]interface{}{"status": float64(1)}},
+		{"delete": map[string]interface{}{"_index": "users", "_id": "3"}},
+	}, lines)
+
+	var bulkErr *BulkError
+	require.ErrorAs(t, err, &bulkErr)
+	require.Len(t, bulkErr.Failed, 1)
+	assert.Equal(t, "2", bulkErr.Failed[0].ID)
+	assert.ErrorIs(t, bulkErr.Errors()[0], ErrNotFound)
+}
+
+func TestBulkIndexer(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"POST /_bulk":          reply(http.StatusOK, `{"took":1,"errors":false,"items":[]}`),
+		"POST /users/_refresh": reply(http.StatusOK, `{}`),
+	})
+	ctx := context.Background()
+
+	// 达到批量大小时提交，Flush 提交剩余操作，开启 Refresh 时提交后刷新索引
+	indexer := client.NewBulkIndexer(2)
+	indexer.Refresh = true
+	for i := 0; i < 3; i++ {
+		require.NoError(t, indexer.Add(ctx, BulkAction{Index: "users", ID: strconv.Itoa(i), Doc: map[string]int{"id": i}}))
+	}
+	require.Len(t, *requests, 2)
+	assert.Equal(t, 4, strings.Count((*requests)[0].Body, "\n"))
+
+	require.NoError(t, indexer.Flush(ctx))
+	require.Len(t, *requests, 4)
+	assert.Equal(t, "/_bulk", (*requests)[2].Path)
+	assert.Equal(t, 2, strings.Count((*requests)[2].Body, "\n"))
+	assert.Equal(t, "/users/_refresh", (*requests)[3].Path)
+
+	// 没有待提交的操作时不发送请求
+	require.NoError(t, indexer.Flush(ctx))
+	assert.Len(t, *requests, 4)
+}
+
+func TestSearch(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"POST /users,depts/_search": reply(http.StatusOK, `{"took":2,"timed_out":false,"hits":{
+			"total":{"value":25,"relation":"eq"},"max_score":null,
+			"hits":[
+				{"_index":"users","_id":"1","_score":null,"_source":{"username":"admin"},"highlight":{"username":["<b>admin</b>"]},"sort":[1700000000000,"1"]},
+				{"_index":"users","_id":"2","_score":null,"_source":{"username":"administrator"},"sort":[1700000000001,"2"]}
+			]},
+			"aggregations":{"by_status":{"buckets":[{"key":1,"doc_count":20},{"key":0,"doc_count":5}]}}}`),
+		"POST /users/_count": reply(http.StatusOK, `{"count":25}`),
+	})
+	ctx := context.Background()
+
+	result, err := client.Search(ctx, "users,depts", &SearchRequest{
+		Query:        map[string]interface{}{"match": map[string]interface{}{"username": "admin"}},
+		From:         20,
+		Size:         2,
+		Sort:         []interface{}{map[string]string{"created_at": "desc"}, "_id"},
+		Source:       []string{"username"},
+		Highlight:    &Highlight{Fields: []string{"username"}, PreTags: []string{"<b>"}, PostTags: []string{"</b>"}, Encoder: "html"},
+		Aggregations: map[string]interface{}{"by_status": map[string]interface{}{"terms": map[string]string{"field": "status"}}},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query":{"match":{"username":"admin"}},
+		"from":20,"size":2,
+		"sort":[{"created_at":"desc"},"_id"],
+		"_source":["username"],
+		"highlight":{"fields":{"username":{}},"pre_tags":["<b>"],"post_tags":["</b>"],"encoder":"html"},
+		"aggs":{"by_status":{"terms":{"field":"status"}}}
+	}`, (*requests)[0].Body)
+
+	assert.Equal(t, 25, result.Hits.Total.Value)
+	require.Len(t, result.Hits.Hits, 2)
+	assert.Equal(t, []string{"<b>admin</b>"}, result.Hits.Hits[0].Highlight["username"])
+	var user struct {
+		Username string `json:"username"`
+	}
+	require.NoError(t, result.Hits.Hits[1].Decode(&user))
+	assert.Equal(t, "administrator", user.Username)
+
+	buckets, err := result.Terms("by_status")
+	require.NoError(t, err)
+	assert.Equal(t, []Bucket{{Key: float64(1), DocCount: 20}, {Key: float64(0), DocCount: 5}}, buckets)
+	assert.ErrorIs(t, result.Aggregation("missing", &buckets), ErrNotFound)
+
+	// search_after 翻页时不发送 from
+	_, err = client.Search(ctx, "users,depts", &SearchRequest{From: 20, Size: 2, SearchAfter: result.NextSearchAfter()})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size":2,"search_after":[1700000000001,"2"]}`, (*requests)[1].Body)
+
+	count, err := client.Count(ctx, "users", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 25, count)
+
+	_, err = client.Search(ctx, "missing", &SearchRequest{Size: -1})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.JSONEq(t, `{"size":0}`, (*requests)[3].Body)
+}
+
+func TestScroll(t *testing.T) {
+	pages := []string{
+		`{"hits":{"hits":[{"_id":"2"}]},"_scroll_id":"scroll-2"}`,
+		`{"hits":{"hits":[]},"_scroll_id":"scroll-3"}`,
+	}
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"POST /users/_search": reply(http.StatusOK, `{"hits":{"hits":[{"_id":"1"}]},"_scroll_id":"scroll-1"}`),
+		"POST /_search/scroll": func(w http.ResponseWriter, r *http.Request) {
+			reply(http.StatusOK, pages[0])(w, r)
+			pages = pages[1:]
+		},
+		"DELETE /_search/scroll": reply(http.StatusOK, `{"succeeded":true}`),
+	})
+	ctx := context.Background()
+
+	result, err := client.Scroll(ctx, "users", &SearchRequest{Size: 1}, "1m")
+	require.NoError(t, err)
+	assert.Equal(t, "scroll=1m", (*requests)[0].Query)
+
+	var ids []string
+	for len(result.Hits.Hits) > 0 {
+		for _, hit := range result.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		result, err = client.ScrollNext(ctx, result.ScrollID, "1m")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"1", "2"}, ids)
+	assert.JSONEq(t, `{"scroll":"1m","scroll_id":"scroll-1"}`, (*requests)[1].Body)
+
+	require.NoError(t, client.ClearScroll(ctx, result.ScrollID))
+	assert.JSONEq(t, `{"scroll_id":["scroll-3"]}`, (*requests)[3].Body)
+}
+
+func TestCreateDefaultIndexes(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]func(http.ResponseWriter, *http.Request){
+		"HEAD /gin_admin_users":    reply(http.StatusOK, ``),
+		"PUT /gin_admin_oper_logs": reply(http.StatusOK, `{"acknowledged":true}`),
+	})
+	plugin := NewPlugin(client.GetConfig())
+
+	// 已存在的索引跳过，只创建缺少的索引
+	require.NoError(t, plugin.CreateDefaultIndexes(context.Background()))
+	var methods []string
+	for _, req := range *requests {
+		methods = append(methods, req.Method+" "+req.Path)
+	}
+	assert.Equal(t, []string{"HEAD /gin_admin_users", "HEAD /gin_admin_oper_logs", "PUT /gin_admin_oper_logs"}, methods)
+}
@@ -0,0 +1,234 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 批量操作类型
+const (
+	BulkIndex  = "index"
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// IndexDocument 索引文档，docID 为空时由 Elasticsearch 生成ID
+func (c *Client) IndexDocument(ctx context.Context, index string, docID string, doc interface{}) error {
+	if docID == "" {
+		return c.perform(ctx, http.MethodPost, "/"+escape(index)+"/_doc", nil, doc, nil)
+	}
+	return c.perform(ctx, http.MethodPut, "/"+escape(index)+"/_doc/"+escape(docID), nil, doc, nil)
+}
+
+// GetDocument 获取文档并将 _source 解析到 result，文档不存在时返回 ErrNotFound
+func (c *Client) GetDocument(ctx context.Context, index string, docID string, result interface{}) error {
+	var resp struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := c.perform(ctx, http.MethodGet, "/"+escape(index)+"/_doc/"+escape(docID), nil, nil, &resp); err != nil {
+		return err
+	}
+	if !resp.Found {
+		return ErrNotFound
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Source, result)
+}
+
+// DeleteDocument 删除文档，文档不存在时返回 ErrNotFound
+func (c *Client) DeleteDocument(ctx context.Context, index string, docID string) error {
+	return c.perform(ctx, http.MethodDelete, "/"+escape(index)+"/_doc/"+escape(docID), nil, nil, nil)
+}
+
+// DeleteByQuery 删除匹配查询条件的文档，返回删除数量
+func (c *Client) DeleteByQuery(ctx context.Context, index string, query map[string]interface{}) (int, error) {
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	body := map[string]interface{}{"query": query}
+	if err := c.perform(ctx, http.MethodPost, "/"+escape(index)+"/_delete_by_query", nil, body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Deleted, nil
+}
+
+// BulkAction 批量操作中的一项，Op 为 BulkIndex、BulkCreate、BulkUpdate 或 BulkDelete
+type BulkAction struct {
+	Op    string
+	Index string
+	ID    string
+	// Doc 文档内容，BulkUpdate 时为部分更新的字段，BulkDelete 时忽略
+	Doc interface{}
+}
+
+// BulkResponse 批量操作结果
+type BulkResponse struct {
+	Took   int                         `json:"took"`
+	Errors bool                        `json:"errors"`
+	Items  []map[string]BulkItemResult `json:"items"`
+}
+
+// BulkItemResult 批量操作中单项的结果
+type BulkItemResult struct {
+	Index  string     `json:"_index"`
+	ID     string     `json:"_id"`
+	Status int        `json:"status"`
+	Result string     `json:"result"`
+	Error  *bulkCause `json:"error,omitempty"`
+}
+
+type bulkCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// BulkError 批量操作中部分失败，Failed 为失败项
+type BulkError struct {
+	Failed []BulkItemResult
+}
+
+// Error 实现 error 接口
+func (e *BulkError) Error() string {
+	first := e.Failed[0]
+	return fmt.Sprintf("elasticsearch: %d bulk items failed, first %s/%s: %s", len(e.Failed), first.Index, first.ID, first.Error.Reason)
+}
+
+// Errors 将失败项转换为 ResponseError
+func (e *BulkError) Errors() []*ResponseError {
+	errs := make([]*ResponseError, len(e.Failed))
+	for i, item := range e.Failed {
+		errs[i] = &ResponseError{StatusCode: item.Status, Type: item.Error.Type, Reason: item.Error.Reason}
+	}
+	return errs
+}
+
+// Bulk 批量执行索引、更新、删除操作，有操作失败时同时返回结果和 *BulkError
+func (c *Client) Bulk(ctx context.Context, actions []BulkAction) (*BulkResponse, error) {
+	if len(actions) == 0 {
+		return &BulkResponse{}, nil
+	}
+
+	var buf bytes.Buffer
+	for _, action := range actions {
+		if err := encodeBulkAction(&buf, action); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp BulkResponse
+	if err := c.performRaw(ctx, http.MethodPost, "/_bulk", nil, "application/x-ndjson", buf.Bytes(), &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Errors {
+		return &resp, nil
+	}
+
+	bulkErr := &BulkError{}
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				bulkErr.Failed = append(bulkErr.Failed, result)
+			}
+		}
+	}
+	if len(bulkErr.Failed) == 0 {
+		return &resp, nil
+	}
+	return &resp, bulkErr
+}
+
+// encodeBulkAction 按 NDJSON 格式写入操作行和文档行
+func encodeBulkAction(buf *bytes.Buffer, action BulkAction) error {
+	meta := map[string]string{"_index": action.Index}
+	if action.ID != "" {
+		meta["_id"] = action.ID
+	}
+
+	op := action.Op
+	if op == "" {
+		op = BulkIndex
+	}
+	line, err := json.Marshal(map[string]interface{}{op: meta})
+	if err != nil {
+		return err
+	}
+	buf.Write(line)
+	buf.WriteByte('\n')
+
+	var doc interface{}
+	switch op {
+	case BulkDelete:
+		return nil
+	case BulkUpdate:
+		doc = map[string]interface{}{"doc": action.Doc}
+	default:
+		doc = action.Doc
+	}
+	line, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	buf.Write(line)
+	buf.WriteByte('\n')
+	return nil
+}
+
+// BulkIndexer 批量写入辅助，累计到 BatchSize 条时自动提交
+type BulkIndexer struct {
+	client    *Client
+	batchSize int
+	actions   []BulkAction
+	// Refresh 提交时刷新索引，使文档立即可被搜索
+	Refresh bool
+}
+
+// NewBulkIndexer 创建批量写入辅助，batchSize 不大于 0 时为 500
+func (c *Client) NewBulkIndexer(batchSize int) *BulkIndexer {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &BulkIndexer{client: c, batchSize: batchSize}
+}
+
+// Add 添加一项操作，达到批量大小时提交
+func (b *BulkIndexer) Add(ctx context.Context, action BulkAction) error {
+	b.actions = append(b.actions, action)
+	if len(b.actions) < b.batchSize {
+		return nil
+	}
+	return b.Flush(ctx)
+}
+
+// Flush 提交尚未写入的操作
+func (b *BulkIndexer) Flush(ctx context.Context) error {
+	if len(b.actions) == 0 {
+		return nil
+	}
+	actions := b.actions
+	b.actions = nil
+	if _, err := b.client.Bulk(ctx, actions); err != nil {
+		return err
+	}
+	if !b.Refresh {
+		return nil
+	}
+
+	indices := make(map[string]bool)
+	for _, action := range actions {
+		indices[action.Index] = true
+	}
+	names := make([]string, 0, len(indices))
+	for index := range indices {
+		names = append(names, url.PathEscape(index))
+	}
+	return b.client.perform(ctx, http.MethodPost, "/"+strings.Join(names, ",")+"/_refresh", nil, nil, nil)
+}
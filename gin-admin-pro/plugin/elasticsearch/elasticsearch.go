@@ -0,0 +1,220 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrDisabled 未启用 Elasticsearch
+	ErrDisabled = errors.New("elasticsearch is disabled")
+	// ErrNotFound 索引、文档、别名或滚动上下文不存在
+	ErrNotFound = errors.New("elasticsearch resource not found")
+	// ErrConflict 文档版本冲突或索引已存在
+	ErrConflict = errors.New("elasticsearch resource conflict")
+	// ErrNoAddress 未配置 Elasticsearch 地址
+	ErrNoAddress = errors.New("elasticsearch addresses not configured")
+)
+
+// ResponseError Elasticsearch 返回的错误，可用 errors.Is 判断 ErrNotFound、ErrConflict
+type ResponseError struct {
+	StatusCode int
+	// Type 错误类型，如 index_not_found_exception
+	Type   string
+	Reason string
+}
+
+// Error 实现 error 接口
+func (e *ResponseError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("elasticsearch: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("elasticsearch: HTTP %d %s: %s", e.StatusCode, e.Type, e.Reason)
+}
+
+// Is 404 视为 ErrNotFound，409 和索引已存在视为 ErrConflict
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || e.Type == "resource_already_exists_exception"
+	}
+	return false
+}
+
+// Client Elasticsearch REST 客户端，请求按配置的地址轮询，节点不可用时切换到下一个节点
+type Client struct {
+	config     *Config
+	httpClient *http.Client
+	next       atomic.Uint32
+}
+
+// NewClient 创建Elasticsearch客户端，不会立即连接，可调用 Ping 检查连接
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	client := &Client{config: cfg}
+	if !cfg.Enabled {
+		return client, nil
+	}
+	if len(cfg.Addresses) == 0 {
+		return nil, ErrNoAddress
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	client.httpClient = &http.Client{Timeout: timeout}
+	return client, nil
+}
+
+// GetConfig 获取配置
+func (c *Client) GetConfig() *Config {
+	return c.config
+}
+
+// IsEnabled 是否启用
+func (c *Client) IsEnabled() bool {
+	return c.config.Enabled
+}
+
+// Ping 测试连接
+func (c *Client) Ping() error {
+	return c.perform(context.Background(), http.MethodGet, "/", nil, nil, nil)
+}
+
+// Health 获取集群健康状态
+func (c *Client) Health() (map[string]interface{}, error) {
+	var health map[string]interface{}
+	if err := c.perform(context.Background(), http.MethodGet, "/_cluster/health", nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// perform 发送 JSON 请求并解析响应，body 为 nil 时不发送请求体，result 为 nil 时丢弃响应
+func (c *Client) perform(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	return c.performRaw(ctx, method, path, query, "application/json", payload, result)
+}
+
+// performRaw 发送请求，节点不可用或返回 502/503/504/429 时换下一个节点重试，最多重试 MaxRetries 次
+func (c *Client) performRaw(ctx context.Context, method, path string, query url.Values, contentType string, payload []byte, result interface{}) error {
+	if !c.IsEnabled() {
+		return ErrDisabled
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= max(c.config.MaxRetries, 0); attempt++ {
+		resp, err := c.send(ctx, method, path, query, contentType, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			lastErr = parseError(resp.StatusCode, data)
+			if retryable(resp.StatusCode) {
+				continue
+			}
+			return lastErr
+		}
+		if result == nil || len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, result)
+	}
+	return lastErr
+}
+
+// send 向下一个节点发送一次请求
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, payload []byte) (*http.Response, error) {
+	addresses := c.config.Addresses
+	address := strings.TrimRight(addresses[int(c.next.Add(1)-1)%len(addresses)], "/")
+	target := address + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryable 节点繁忙或不可用，换节点重试
+func retryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseError 解析错误响应 {"error": {"type", "reason"}, "status"}，HEAD 请求和文档不存在时没有 error 字段
+func parseError(statusCode int, data []byte) error {
+	respErr := &ResponseError{StatusCode: statusCode}
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &body) != nil || len(body.Error) == 0 {
+		return respErr
+	}
+
+	var cause struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(body.Error, &cause) == nil {
+		respErr.Type, respErr.Reason = cause.Type, cause.Reason
+	} else {
+		// 部分接口的 error 为字符串
+		_ = json.Unmarshal(body.Error, &respErr.Reason)
+	}
+	return respErr
+}
+
+// escape 转义路径中的索引名、文档ID等
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
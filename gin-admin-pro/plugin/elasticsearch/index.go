@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// CreateIndex 创建索引，body 包含 settings、mappings、aliases，索引已存在时返回 ErrConflict
+func (c *Client) CreateIndex(ctx context.Context, index string, body map[string]interface{}) error {
+	return c.perform(ctx, http.MethodPut, "/"+escape(index), nil, body, nil)
+}
+
+// DeleteIndex 删除索引
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	return c.perform(ctx, http.MethodDelete, "/"+escape(index), nil, nil, nil)
+}
+
+// IndexExists 检查索引或别名是否存在
+func (c *Client) IndexExists(ctx context.Context, index string) (bool, error) {
+	err := c.perform(ctx, http.MethodHead, "/"+escape(index), nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Refresh 刷新索引，使刚写入的文档可被搜索
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	return c.perform(ctx, http.MethodPost, "/"+escape(index)+"/_refresh", nil, nil, nil)
+}
+
+// PutIndexTemplate 创建或更新索引模板，body 包含 index_patterns、template、priority 等
+func (c *Client) PutIndexTemplate(ctx context.Context, name string, body map[string]interface{}) error {
+	return c.perform(ctx, http.MethodPut, "/_index_template/"+escape(name), nil, body, nil)
+}
+
+// DeleteIndexTemplate 删除索引模板
+func (c *Client) DeleteIndexTemplate(ctx context.Context, name string) error {
+	return c.perform(ctx, http.MethodDelete, "/_index_template/"+escape(name), nil, nil, nil)
+}
+
+// PutAlias 为索引添加别名
+func (c *Client) PutAlias(ctx context.Context, index, alias string) error {
+	return c.perform(ctx, http.MethodPut, "/"+escape(index)+"/_alias/"+escape(alias), nil, nil, nil)
+}
+
+// DeleteAlias 删除索引别名
+func (c *Client) DeleteAlias(ctx context.Context, index, alias string) error {
+	return c.perform(ctx, http.MethodDelete, "/"+escape(index)+"/_alias/"+escape(alias), nil, nil, nil)
+}
+
+// GetAliasIndices 获取别名指向的索引，别名不存在时返回空
+func (c *Client) GetAliasIndices(ctx context.Context, alias string) ([]string, error) {
+	var result map[string]interface{}
+	err := c.perform(ctx, http.MethodGet, "/_alias/"+escape(alias), nil, nil, &result)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// SwitchAlias 原子地将别名切换到新索引，并从原来指向的索引上移除，用于重建索引后无缝切换
+func (c *Client) SwitchAlias(ctx context.Context, alias, index string) error {
+	current, err := c.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	actions := make([]map[string]interface{}, 0, len(current)+1)
+	for _, old := range current {
+		if old == index {
+			continue
+		}
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{"index": old, "alias": alias},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": index, "alias": alias},
+	})
+	return c.perform(ctx, http.MethodPost, "/_aliases", nil, map[string]interface{}{"actions": actions}, nil)
+}
@@ -0,0 +1,179 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+)
+
+// Plugin Elasticsearch插件
+type Plugin struct {
+	config *Config
+	client *Client
+}
+
+// NewPlugin 创建Elasticsearch插件
+func NewPlugin(cfg *Config) *Plugin {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	client, _ := NewClient(cfg)
+
+	return &Plugin{
+		config: cfg,
+		client: client,
+	}
+}
+
+// GetConfig 获取配置
+func (p *Plugin) GetConfig() *Config {
+	return p.config
+}
+
+// GetClient 获取客户端
+func (p *Plugin) GetClient() *Client {
+	return p.client
+}
+
+// IsEnabled 是否启用
+func (p *Plugin) IsEnabled() bool {
+	return p.config.Enabled
+}
+
+// Init 初始化插件
+func (p *Plugin) Init() error {
+	if !p.IsEnabled() {
+		return nil
+	}
+	if p.client == nil {
+		return ErrNoAddress
+	}
+
+	// 测试连接
+	if err := p.client.Ping(); err != nil {
+		return fmt.Errorf("failed to connect to elasticsearch: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDefaultIndexes 创建默认索引，已存在的索引跳过
+func (p *Plugin) CreateDefaultIndexes(ctx context.Context) error {
+	if !p.IsEnabled() {
+		return nil
+	}
+	if p.client == nil {
+		return ErrNoAddress
+	}
+
+	// 创建用户索引
+	userMapping := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"username": map[string]interface{}{
+					"type": "text",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{
+							"type": "keyword",
+						},
+					},
+				},
+				"nickname": map[string]interface{}{
+					"type": "text",
+				},
+				"email": map[string]interface{}{
+					"type": "keyword",
+				},
+				"mobile": map[string]interface{}{
+					"type": "keyword",
+				},
+				"status": map[string]interface{}{
+					"type": "integer",
+				},
+				"dept_id": map[string]interface{}{
+					"type": "integer",
+				},
+				"created_at": map[string]interface{}{
+					"type": "date",
+				},
+			},
+		},
+	}
+
+	if err := p.ensureIndex(ctx, p.config.DefaultIndexPrefix+"_users", userMapping); err != nil {
+		return fmt.Errorf("failed to create users index: %w", err)
+	}
+
+	// 创建操作日志索引
+	operLogMapping := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"title": map[string]interface{}{
+					"type": "text",
+				},
+				"business_type": map[string]interface{}{
+					"type": "integer",
+				},
+				"method": map[string]interface{}{
+					"type": "keyword",
+				},
+				"oper_name": map[string]interface{}{
+					"type": "keyword",
+				},
+				"dept_name": map[string]interface{}{
+					"type": "keyword",
+				},
+				"oper_url": map[string]interface{}{
+					"type": "keyword",
+				},
+				"oper_ip": map[string]interface{}{
+					"type": "ip",
+				},
+				"oper_location": map[string]interface{}{
+					"type": "keyword",
+				},
+				"status": map[string]interface{}{
+					"type": "integer",
+				},
+				"error_msg": map[string]interface{}{
+					"type": "text",
+				},
+				"oper_time": map[string]interface{}{
+					"type": "date",
+				},
+				"cost_time": map[string]interface{}{
+					"type": "long",
+				},
+			},
+		},
+	}
+
+	if err := p.ensureIndex(ctx, p.config.DefaultIndexPrefix+"_oper_logs", operLogMapping); err != nil {
+		return fmt.Errorf("failed to create oper_logs index: %w", err)
+	}
+
+	return nil
+}
+
+// ensureIndex 索引不存在时创建
+func (p *Plugin) ensureIndex(ctx context.Context, index string, body map[string]interface{}) error {
+	exists, err := p.client.IndexExists(ctx, index)
+	if err != nil || exists {
+		return err
+	}
+	return p.client.CreateIndex(ctx, index, body)
+}
+
+// GetHealthStatus 获取健康状态描述
+func GetHealthStatus(status string) string {
+	switch status {
+	case "green":
+		return "健康"
+	case "yellow":
+		return "警告"
+	case "red":
+		return "异常"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,239 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// SearchRequest 搜索请求，Query 等字段使用 Elasticsearch 查询 DSL
+type SearchRequest struct {
+	Query map[string]interface{}
+	// From 分页偏移，与 SearchAfter 同时使用时忽略
+	From int
+	// Size 返回条数，为 0 时使用 Elasticsearch 默认值 10，小于 0 时不返回命中只计算聚合
+	Size int
+	Sort []interface{}
+	// Source 返回的字段，为空时返回全部
+	Source       []string
+	Highlight    *Highlight
+	Aggregations map[string]interface{}
+	// SearchAfter 上一页最后一条的排序值，用于深度分页，需要指定 Sort
+	SearchAfter []interface{}
+	// TrackTotalHits 精确统计超过 10000 的命中总数
+	TrackTotalHits bool
+}
+
+// Highlight 高亮设置
+type Highlight struct {
+	Fields []string
+	// PreTags、PostTags 高亮标签，为空时使用 <em></em>
+	PreTags  []string
+	PostTags []string
+	// FragmentSize 片段长度，为 0 时使用默认值
+	FragmentSize int
+	// NumberOfFragments 片段数量，为 0 时使用默认值
+	NumberOfFragments int
+	// Encoder 片段编码，为 html 时先转义原文再加高亮标签；为空时不转义，片段不能直接作为 HTML 渲染
+	Encoder string
+}
+
+// body 转换为搜索请求体
+func (r *SearchRequest) body() map[string]interface{} {
+	body := map[string]interface{}{}
+	if r.Query != nil {
+		body["query"] = r.Query
+	}
+	switch {
+	case r.Size < 0:
+		body["size"] = 0
+	case r.Size > 0:
+		body["size"] = r.Size
+	}
+	if len(r.SearchAfter) > 0 {
+		body["search_after"] = r.SearchAfter
+	} else if r.From > 0 {
+		body["from"] = r.From
+	}
+	if len(r.Sort) > 0 {
+		body["sort"] = r.Sort
+	}
+	if len(r.Source) > 0 {
+		body["_source"] = r.Source
+	}
+	if r.Highlight != nil {
+		body["highlight"] = r.Highlight.body()
+	}
+	if len(r.Aggregations) > 0 {
+		body["aggs"] = r.Aggregations
+	}
+	if r.TrackTotalHits {
+		body["track_total_hits"] = true
+	}
+	return body
+}
+
+func (h *Highlight) body() map[string]interface{} {
+	field := map[string]interface{}{}
+	if h.FragmentSize > 0 {
+		field["fragment_size"] = h.FragmentSize
+	}
+	if h.NumberOfFragments > 0 {
+		field["number_of_fragments"] = h.NumberOfFragments
+	}
+	fields := make(map[string]interface{}, len(h.Fields))
+	for _, name := range h.Fields {
+		fields[name] = field
+	}
+
+	body := map[string]interface{}{"fields": fields}
+	if len(h.PreTags) > 0 {
+		body["pre_tags"] = h.PreTags
+	}
+	if len(h.PostTags) > 0 {
+		body["post_tags"] = h.PostTags
+	}
+	if h.Encoder != "" {
+		body["encoder"] = h.Encoder
+	}
+	return body
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Took     int    `json:"took"`
+	TimedOut bool   `json:"timed_out"`
+	ScrollID string `json:"_scroll_id,omitempty"`
+	Hits     Hits   `json:"hits"`
+	// Aggregations 聚合结果，按聚合名称保存原始 JSON
+	Aggregations map[string]json.RawMessage `json:"aggregations,omitempty"`
+}
+
+// Hits 命中结果
+type Hits struct {
+	Total    Total    `json:"total"`
+	MaxScore *float64 `json:"max_score"`
+	Hits     []Hit    `json:"hits"`
+}
+
+// Total 总数
+type Total struct {
+	Value    int    `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// Hit 单个命中结果
+type Hit struct {
+	Index     string              `json:"_index"`
+	ID        string              `json:"_id"`
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+	Sort      []interface{}       `json:"sort,omitempty"`
+}
+
+// Decode 将 _source 解析到 v
+func (h *Hit) Decode(v interface{}) error {
+	return json.Unmarshal(h.Source, v)
+}
+
+// Bucket 桶聚合中的一个桶
+type Bucket struct {
+	Key      interface{} `json:"key"`
+	DocCount int         `json:"doc_count"`
+}
+
+// Terms 读取 terms 等桶聚合的结果，聚合不存在时返回空
+func (r *SearchResult) Terms(name string) ([]Bucket, error) {
+	raw, ok := r.Aggregations[name]
+	if !ok {
+		return nil, nil
+	}
+	var agg struct {
+		Buckets []Bucket `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		return nil, err
+	}
+	return agg.Buckets, nil
+}
+
+// Aggregation 将聚合结果解析到 v，聚合不存在时返回 ErrNotFound
+func (r *SearchResult) Aggregation(name string, v interface{}) error {
+	raw, ok := r.Aggregations[name]
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// NextSearchAfter 返回下一页的 SearchAfter，没有命中时返回 nil
+func (r *SearchResult) NextSearchAfter() []interface{} {
+	if len(r.Hits.Hits) == 0 {
+		return nil
+	}
+	return r.Hits.Hits[len(r.Hits.Hits)-1].Sort
+}
+
+// SearchDocuments 使用原始查询 DSL 搜索文档，index 可以是索引、别名或逗号分隔的多个索引
+func (c *Client) SearchDocuments(ctx context.Context, index string, query map[string]interface{}) (*SearchResult, error) {
+	var result SearchResult
+	if err := c.perform(ctx, http.MethodPost, "/"+escapeList(index)+"/_search", nil, query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Search 搜索文档，支持分页、高亮、聚合和 search_after
+func (c *Client) Search(ctx context.Context, index string, req *SearchRequest) (*SearchResult, error) {
+	return c.SearchDocuments(ctx, index, req.body())
+}
+
+// Count 统计匹配查询的文档数量，query 为 nil 时统计全部
+func (c *Client) Count(ctx context.Context, index string, query map[string]interface{}) (int, error) {
+	var body interface{}
+	if query != nil {
+		body = map[string]interface{}{"query": query}
+	}
+	var resp struct {
+		Count int `json:"count"`
+	}
+	if err := c.perform(ctx, http.MethodPost, "/"+escapeList(index)+"/_count", nil, body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// Scroll 开始滚动查询，keepAlive 为滚动上下文保留时间，如 "1m"，结果的 ScrollID 用于 ScrollNext
+func (c *Client) Scroll(ctx context.Context, index string, req *SearchRequest, keepAlive string) (*SearchResult, error) {
+	var result SearchResult
+	query := url.Values{"scroll": {keepAlive}}
+	if err := c.perform(ctx, http.MethodPost, "/"+escapeList(index)+"/_search", query, req.body(), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ScrollNext 获取滚动查询的下一批结果，没有命中时表示已读完，滚动上下文过期时返回 ErrNotFound
+func (c *Client) ScrollNext(ctx context.Context, scrollID, keepAlive string) (*SearchResult, error) {
+	var result SearchResult
+	body := map[string]interface{}{"scroll": keepAlive, "scroll_id": scrollID}
+	if err := c.perform(ctx, http.MethodPost, "/_search/scroll", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClearScroll 释放滚动上下文
+func (c *Client) ClearScroll(ctx context.Context, scrollIDs ...string) error {
+	if len(scrollIDs) == 0 {
+		return nil
+	}
+	return c.perform(ctx, http.MethodDelete, "/_search/scroll", nil, map[string]interface{}{"scroll_id": scrollIDs}, nil)
+}
+
+// escapeList 转义逗号分隔的多个索引名
+func escapeList(indices string) string {
+	return (&url.URL{Path: indices}).EscapedPath()
+}
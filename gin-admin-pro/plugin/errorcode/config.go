@@ -22,6 +22,10 @@ type Config struct {
 	MaxPageSize int `yaml:"maxPageSize" json:"maxPageSize"`
 	// 是否启用错误码验证
 	EnableValidation bool `yaml:"enableValidation" json:"enableValidation"`
+	// 默认语言，错误码表 message 字段使用该语言
+	DefaultLocale string `yaml:"defaultLocale" json:"defaultLocale"`
+	// 支持的语言列表，根据 Accept-Language 在其中匹配
+	Locales []string `yaml:"locales" json:"locales"`
 }
 
 // DefaultConfig 默认配置
@@ -35,6 +39,8 @@ func DefaultConfig() *Config {
 		DefaultPageSize:    20,
 		MaxPageSize:        100,
 		EnableValidation:   true,
+		DefaultLocale:      LocaleZhCN,
+		Locales:            []string{LocaleZhCN, LocaleEnUS},
 	}
 }
 
@@ -84,7 +90,7 @@ func (p *Plugin) AutoMigrate() error {
 		return nil
 	}
 
-	return p.db.AutoMigrate(&ErrorCode{})
+	return p.db.AutoMigrate(&ErrorCode{}, &ErrorCodeMessage{})
 }
 
 // Init 初始化插件
@@ -139,5 +145,14 @@ func (p *Plugin) loadPredefinedErrorCodes() error {
 		}
 	}
 
+	// 内置的多语言消息只补充缺失项，不覆盖管理员修改过的内容
+	for _, message := range GetPredefinedErrorMessages() {
+		err := p.db.Where("code = ? AND locale = ?", message.Code, message.Locale).
+			FirstOrCreate(&message).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -1,24 +1,33 @@
 package errorcode
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+var (
+	ErrCodeExists    = errors.New("错误码已存在")
+	ErrInvalidLocale = errors.New("不支持的语言")
+)
+
 // ErrorCode 错误码
 type ErrorCode struct {
-	ID       int    `gorm:"primarykey" json:"id"`
-	Type     string `gorm:"size:100;not null" json:"type"`    // 错误类型
-	Code     int    `gorm:"not null;uniqueIndex" json:"code"` // 错误码
-	Name     string `gorm:"size:100;not null" json:"name"`    // 错误名称
-	Message  string `gorm:"size:500;not null" json:"message"` // 错误消息
-	Solution string `gorm:"size:1000" json:"solution"`        // 解决方案
-	Status   int    `gorm:"default:1" json:"status"`          // 状态 0-禁用 1-启用
-	Remark   string `gorm:"size:500" json:"remark"`           // 备注
-	CreateBy uint   `json:"createBy"`
-	UpdateBy uint   `json:"updateBy"`
-	CreateAt uint   `json:"createAt"`
-	UpdateAt uint   `json:"updateAt"`
+	ID        int       `gorm:"primarykey" json:"id"`
+	Type      string    `gorm:"size:100;not null" json:"type"`                // 错误类型
+	Code      int       `gorm:"not null;uniqueIndex" json:"code"`             // 错误码
+	Name      string    `gorm:"size:100;not null" json:"name"`                // 错误名称
+	Message   string    `gorm:"size:500;not null" json:"message"`             // 错误消息
+	Solution  string    `gorm:"size:1000" json:"solution"`                    // 解决方案
+	Status    int       `gorm:"default:1" json:"status" dict:"system_status"` // 状态 0-禁用 1-启用
+	Remark    string    `gorm:"size:500" json:"remark"`                       // 备注
+	CreateBy  uint      `json:"createBy"`
+	UpdateBy  uint      `json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
 }
 
 // TableName 设置表名
@@ -30,6 +39,9 @@ func (ErrorCode) TableName() string {
 type Service struct {
 	db     *gorm.DB
 	config *Config
+	// locale 当前语言，为空时使用默认语言，通过 WithLocale 设置
+	locale string
+	cache  *messageCache
 }
 
 // NewService 创建错误码服务
@@ -40,6 +52,7 @@ func NewService(db *gorm.DB, config *Config) *Service {
 	return &Service{
 		db:     db,
 		config: config,
+		cache:  &messageCache{},
 	}
 }
 
@@ -109,10 +122,26 @@ func (s *Service) CreateErrorCode(errorCode *ErrorCode) error {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("错误码 %d 已存在", errorCode.Code)
+		return fmt.Errorf("%w：%d", ErrCodeExists, errorCode.Code)
 	}
 
-	return s.db.Create(errorCode).Error
+	// GORM 创建时把状态 0 替换为列默认值，禁用的错误码需在同一事务中写回状态
+	status := errorCode.Status
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(errorCode).Error; err != nil {
+			return err
+		}
+		if errorCode.Status == status {
+			return nil
+		}
+		errorCode.Status = status
+		return tx.Model(errorCode).UpdateColumn("status", status).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.InvalidateCache()
+	return nil
 }
 
 // UpdateErrorCode 更新错误码
@@ -124,38 +153,99 @@ func (s *Service) UpdateErrorCode(errorCode *ErrorCode) error {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("错误码 %d 已存在", errorCode.Code)
+		return fmt.Errorf("%w：%d", ErrCodeExists, errorCode.Code)
+	}
+
+	old, err := s.GetErrorCodeByID(errorCode.ID)
+	if err != nil {
+		return err
 	}
 
-	return s.db.Save(errorCode).Error
+	// 错误码值变更时同步迁移多语言消息
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if old.Code != errorCode.Code {
+			err := tx.Model(&ErrorCodeMessage{}).Where("code = ?", old.Code).
+				Update("code", errorCode.Code).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(errorCode).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.InvalidateCache()
+	return nil
 }
 
-// DeleteErrorCode 删除错误码
+// DeleteErrorCode 删除错误码及其多语言消息
 func (s *Service) DeleteErrorCode(id int) error {
-	return s.db.Delete(&ErrorCode{}, id).Error
+	errorCode, err := s.GetErrorCodeByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code = ?", errorCode.Code).Delete(&ErrorCodeMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&ErrorCode{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.InvalidateCache()
+	return nil
 }
 
-// GetErrorMessage 获取错误消息
+// GetErrorMessage 获取当前语言的错误消息模板
+// 查找顺序：数据库当前语言 -> 内置当前语言 -> 数据库默认语言 -> 内置默认语言
 func (s *Service) GetErrorMessage(code int) string {
-	if s.db == nil {
-		return s.getDefaultErrorMessage(code)
+	locale := s.GetLocale()
+	defaultLocale := s.defaultLocale()
+
+	if message, ok := s.lookupMessage(code, locale); ok {
+		return message
 	}
-	errorCode, err := s.GetErrorCodeByCode(code)
-	if err != nil {
-		return s.getDefaultErrorMessage(code)
+	if locale != defaultLocale {
+		if message, ok := builtinMessages[locale][code]; ok {
+			return message
+		}
+		if message, ok := s.lookupMessage(code, defaultLocale); ok {
+			return message
+		}
 	}
-	return errorCode.Message
+	return s.getDefaultErrorMessage(code)
 }
 
-// GetErrorMessageWithParams 获取带参数的错误消息
+// GetErrorMessageWithParams 获取带参数的错误消息（使用当前语言）
 func (s *Service) GetErrorMessageWithParams(code int, params ...interface{}) string {
-	message := s.GetErrorMessage(code)
+	return FormatMessage(s.GetErrorMessage(code), params...)
+}
+
+// FormatMessage 填充消息模板参数，未传参数时去掉模板中的占位符，模板没有占位符时忽略参数
+func FormatMessage(message string, params ...interface{}) string {
+	if !strings.Contains(message, "%") {
+		return message
+	}
 	if len(params) > 0 {
 		return fmt.Sprintf(message, params...)
 	}
-	return message
+	return placeholderReplacer.Replace(message)
+}
+
+// placeholderReplacer 去掉占位符及其前面的分隔符
+var placeholderReplacer = strings.NewReplacer("：%s", "", ": %s", "", "%s", "", "%v", "", "%d", "")
+
+// DefaultMessage 获取内置的默认语言错误消息模板，不访问数据库
+func DefaultMessage(code int) string {
+	return defaultService.getDefaultErrorMessage(code)
 }
 
+// defaultService 无数据库的默认服务，用于获取内置消息
+var defaultService = NewService(nil, nil)
+
 // GetErrorSolution 获取解决方案
 func (s *Service) GetErrorSolution(code int) string {
 	errorCode, err := s.GetErrorCodeByCode(code)
@@ -233,6 +323,7 @@ func (s *Service) ImportErrorCodes(errorCodes []ErrorCode) (int, int, error) {
 		}
 	}
 
+	s.InvalidateCache()
 	return successCount, errorCount, nil
 }
 
@@ -243,58 +334,10 @@ func (s *Service) GetErrorCodeTypes() ([]string, error) {
 	return types, err
 }
 
-// getDefaultValue 获取默认错误消息
+// getDefaultErrorMessage 获取默认语言的内置错误消息，与预定义错误码保持一致
 func (s *Service) getDefaultErrorMessage(code int) string {
-	// 检查预定义错误码
-	switch code {
-	case CodeUnknownError:
-		return "未知错误，请联系管理员"
-	case CodeParamError:
-		return "请求参数错误：%s"
-	case CodeDataNotFound:
-		return "数据不存在"
-	case CodeDataExists:
-		return "数据已存在"
-	case CodeOperationFailed:
-		return "操作失败"
-	case CodePermissionDenied:
-		return "权限不足"
-	case CodeTokenExpired:
-		return "Token已过期"
-	case CodeTokenInvalid:
-		return "Token无效"
-	case CodeRateLimitExceeded:
-		return "请求频率超限"
-	case CodeUserNotFound:
-		return "用户不存在"
-	case CodeUserExists:
-		return "用户已存在"
-	case CodeUserDisabled:
-		return "用户已被禁用"
-	case CodePasswordError:
-		return "密码错误"
-	case CodeAccountLocked:
-		return "账户已被锁定"
-	case CodeLoginRequired:
-		return "请先登录"
-	case CodeRoleNotFound:
-		return "角色不存在"
-	case CodeRoleExists:
-		return "角色已存在"
-	case CodeRoleInUse:
-		return "角色正在使用中"
-	case CodePermissionNotFound:
-		return "权限不存在"
-	case CodePermissionExists:
-		return "权限已存在"
-	case CodeBusinessError:
-		return "业务错误"
-	case CodeDataInvalid:
-		return "数据无效"
-	case CodeConfigError:
-		return "配置错误"
-	case CodeServiceUnavailable:
-		return "服务不可用"
+	if message, ok := predefinedMessages[code]; ok {
+		return message
 	}
 
 	// 根据错误码范围返回默认消息
@@ -325,17 +368,19 @@ const (
 	CodeRateLimitExceeded = 1009 // 请求频率超限
 
 	// 用户相关错误码 (2000-2999)
-	CodeUserNotFound  = 2001 // 用户不存在
-	CodeUserExists    = 2002 // 用户已存在
-	CodeUserDisabled  = 2003 // 用户已禁用
-	CodePasswordError = 2004 // 密码错误
-	CodeAccountLocked = 2005 // 账户已锁定
-	CodeLoginRequired = 2006 // 需要登录
+	CodeUserNotFound       = 2001 // 用户不存在
+	CodeUserExists         = 2002 // 用户已存在
+	CodeUserDisabled       = 2003 // 用户已禁用
+	CodePasswordError      = 2004 // 密码错误
+	CodeAccountLocked      = 2005 // 账户已锁定
+	CodeLoginRequired      = 2006 // 需要登录
+	CodeInvalidCredentials = 2007 // 用户名或密码错误
 
 	// 角色相关错误码 (3000-3999)
 	CodeRoleNotFound = 3001 // 角色不存在
 	CodeRoleExists   = 3002 // 角色已存在
 	CodeRoleInUse    = 3003 // 角色正在使用
+	CodeRoleBuiltin  = 3004 // 内置角色
 
 	// 权限相关错误码 (4000-4999)
 	CodePermissionNotFound = 4001 // 权限不存在
@@ -346,6 +391,22 @@ const (
 	CodeDataInvalid        = 5002 // 数据无效
 	CodeConfigError        = 5003 // 配置错误
 	CodeServiceUnavailable = 5004 // 服务不可用
+
+	// 系统管理业务错误码 (5100-5199)
+	CodeDeptParentSelf           = 5101 // 部门上级为自身
+	CodeDeptCircularParent       = 5102 // 部门循环引用
+	CodeDeptCannotDelete         = 5103 // 部门无法删除
+	CodeMenuParentSelf           = 5104 // 菜单上级为自身
+	CodeMenuCircularParent       = 5105 // 菜单循环引用
+	CodeMenuCannotDelete         = 5106 // 菜单无法删除
+	CodeDictTypeHasData          = 5107 // 字典类型存在数据
+	CodeRestoreDeptMissing       = 5108 // 恢复时所属部门不存在
+	CodeRestoreParentDeptMissing = 5109 // 恢复时上级部门不存在
+	CodeRestoreDeptNameExists    = 5110 // 恢复时部门名称重复
+	CodeRestoreParentMenuMissing = 5111 // 恢复时上级菜单不存在
+	CodePurgeDeptHasChildren     = 5112 // 彻底删除时部门存在子部门
+	CodePurgeDeptHasUsers        = 5113 // 彻底删除时部门存在用户
+	CodePurgeMenuHasChildren     = 5114 // 彻底删除时菜单存在子菜单
 )
 
 // GetPredefinedErrorCodes 获取预定义错误码列表
@@ -370,11 +431,13 @@ func GetPredefinedErrorCodes() []ErrorCode {
 		{Type: "user", Code: CodePasswordError, Name: "密码错误", Message: "密码错误，请重新输入", Status: 1},
 		{Type: "user", Code: CodeAccountLocked, Name: "账户已锁定", Message: "账户已锁定，请联系管理员", Status: 1},
 		{Type: "user", Code: CodeLoginRequired, Name: "需要登录", Message: "请先登录后再进行操作", Status: 1},
+		{Type: "user", Code: CodeInvalidCredentials, Name: "用户名或密码错误", Message: "用户名或密码错误", Status: 1},
 
 		// 角色相关错误码
 		{Type: "role", Code: CodeRoleNotFound, Name: "角色不存在", Message: "角色不存在", Status: 1},
 		{Type: "role", Code: CodeRoleExists, Name: "角色已存在", Message: "角色已存在：%s", Status: 1},
 		{Type: "role", Code: CodeRoleInUse, Name: "角色正在使用", Message: "角色正在使用中，无法删除", Status: 1},
+		{Type: "role", Code: CodeRoleBuiltin, Name: "内置角色", Message: "内置角色，无法删除", Status: 1},
 
 		// 权限相关错误码
 		{Type: "permission", Code: CodePermissionNotFound, Name: "权限不存在", Message: "权限不存在", Status: 1},
@@ -385,9 +448,34 @@ func GetPredefinedErrorCodes() []ErrorCode {
 		{Type: "business", Code: CodeDataInvalid, Name: "数据无效", Message: "数据无效：%s", Status: 1},
 		{Type: "business", Code: CodeConfigError, Name: "配置错误", Message: "配置错误：%s", Status: 1},
 		{Type: "business", Code: CodeServiceUnavailable, Name: "服务不可用", Message: "服务暂时不可用，请稍后再试", Status: 1},
+
+		// 系统管理业务错误码
+		{Type: "business", Code: CodeDeptParentSelf, Name: "部门上级为自身", Message: "不能将部门设置为自己的子部门", Status: 1},
+		{Type: "business", Code: CodeDeptCircularParent, Name: "部门循环引用", Message: "不能将部门设置为自己的子部门，会造成循环引用", Status: 1},
+		{Type: "business", Code: CodeDeptCannotDelete, Name: "部门无法删除", Message: "部门不存在或存在子部门，无法删除", Status: 1},
+		{Type: "business", Code: CodeMenuParentSelf, Name: "菜单上级为自身", Message: "不能将菜单设置为自己的子菜单", Status: 1},
+		{Type: "business", Code: CodeMenuCircularParent, Name: "菜单循环引用", Message: "不能将菜单设置为自己的子菜单，会造成循环引用", Status: 1},
+		{Type: "business", Code: CodeMenuCannotDelete, Name: "菜单无法删除", Message: "菜单不存在或存在子菜单，无法删除", Status: 1},
+		{Type: "business", Code: CodeDictTypeHasData, Name: "字典类型存在数据", Message: "该字典类型下存在字典数据，无法删除", Status: 1},
+		{Type: "business", Code: CodeRestoreDeptMissing, Name: "所属部门不存在", Message: "所属部门不存在或已删除，请先恢复部门", Status: 1},
+		{Type: "business", Code: CodeRestoreParentDeptMissing, Name: "上级部门不存在", Message: "上级部门不存在或已删除，请先恢复上级部门", Status: 1},
+		{Type: "business", Code: CodeRestoreDeptNameExists, Name: "部门名称重复", Message: "同级下已存在相同名称的部门", Status: 1},
+		{Type: "business", Code: CodeRestoreParentMenuMissing, Name: "上级菜单不存在", Message: "上级菜单不存在或已删除，请先恢复上级菜单", Status: 1},
+		{Type: "business", Code: CodePurgeDeptHasChildren, Name: "部门存在子部门", Message: "部门下存在子部门，无法彻底删除", Status: 1},
+		{Type: "business", Code: CodePurgeDeptHasUsers, Name: "部门存在用户", Message: "部门下存在用户，无法彻底删除", Status: 1},
+		{Type: "business", Code: CodePurgeMenuHasChildren, Name: "菜单存在子菜单", Message: "菜单下存在子菜单，无法彻底删除", Status: 1},
 	}
 }
 
+// predefinedMessages 预定义错误码的默认语言消息
+var predefinedMessages = func() map[int]string {
+	messages := make(map[int]string)
+	for _, code := range GetPredefinedErrorCodes() {
+		messages[code.Code] = code.Message
+	}
+	return messages
+}()
+
 // GetErrorTypeName 获取错误类型名称
 func GetErrorTypeName(errorType string) string {
 	typeNames := map[string]string{
@@ -1,6 +1,7 @@
 package errorcode
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -258,6 +259,8 @@ func TestErrorCodeConstants(t *testing.T) {
 		{"CodeDataInvalid", CodeDataInvalid, 5002},
 		{"CodeConfigError", CodeConfigError, 5003},
 		{"CodeServiceUnavailable", CodeServiceUnavailable, 5004},
+		{"CodeDeptParentSelf", CodeDeptParentSelf, 5101},
+		{"CodePurgeMenuHasChildren", CodePurgeMenuHasChildren, 5114},
 	}
 
 	for _, tt := range tests {
@@ -268,3 +271,87 @@ func TestErrorCodeConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchLocale(t *testing.T) {
+	service := NewService(nil, DefaultConfig())
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", LocaleZhCN},
+		{"en-US", LocaleEnUS},
+		{"en", LocaleEnUS},
+		{"en-GB,en;q=0.9", LocaleEnUS},
+		{"zh-TW", LocaleZhCN},
+		{"fr-FR,en;q=0.8,zh;q=0.9", LocaleZhCN},
+		{"fr-FR,en;q=0.9,zh;q=0.8", LocaleEnUS},
+		{"en;q=0,zh-CN", LocaleZhCN},
+		{"en_us", LocaleEnUS},
+		{"ja-JP", LocaleZhCN},
+		{"*", LocaleZhCN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if result := service.MatchLocale(tt.header); result != tt.expected {
+				t.Errorf("MatchLocale(%q) = %v, want %v", tt.header, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	service := NewService(nil, DefaultConfig())
+
+	en := service.WithLocale("en-US,en;q=0.9")
+	if en.GetLocale() != LocaleEnUS {
+		t.Fatalf("GetLocale() = %v, want %v", en.GetLocale(), LocaleEnUS)
+	}
+	if service.GetLocale() != LocaleZhCN {
+		t.Errorf("WithLocale should not modify the original service, got %v", service.GetLocale())
+	}
+
+	message := en.GetErrorMessageWithParams(CodeParamError, "username")
+	if message != "Invalid request parameter: username" {
+		t.Errorf("GetErrorMessageWithParams() = %v", message)
+	}
+
+	// 非默认语言未配置的错误码回退到默认语言
+	message = en.GetErrorMessage(999)
+	if message != "未知错误 999" {
+		t.Errorf("GetErrorMessage() fallback = %v", message)
+	}
+
+	// 未传参数时去掉占位符
+	if message := service.GetErrorMessageWithParams(CodeParamError); message != "请求参数错误" {
+		t.Errorf("GetErrorMessageWithParams() without params = %v", message)
+	}
+	if message := en.GetErrorMessageWithParams(CodeDataNotFound); message != "Data not found" {
+		t.Errorf("GetErrorMessageWithParams() without params = %v", message)
+	}
+}
+
+func TestGetPredefinedErrorMessages(t *testing.T) {
+	messages := GetPredefinedErrorMessages()
+	if len(messages) != len(GetPredefinedErrorCodes()) {
+		t.Errorf("GetPredefinedErrorMessages() returned %d messages, want %d", len(messages), len(GetPredefinedErrorCodes()))
+	}
+	for _, message := range messages {
+		if message.Locale != LocaleEnUS || message.Message == "" {
+			t.Errorf("unexpected predefined message %+v", message)
+		}
+	}
+}
+
+func TestSaveErrorCodeMessagesInvalidLocale(t *testing.T) {
+	service := NewService(nil, nil)
+
+	// 格式错误、超出列长度、不在支持列表中的语言均在访问数据库前拒绝
+	for _, locale := range []string{"", "<script>", "en_US", "en-US-aaaaaaaaaaaaaaaa", "fr-FR"} {
+		err := service.SaveErrorCodeMessages(CodeParamError, map[string]string{locale: "message"})
+		if !errors.Is(err, ErrInvalidLocale) {
+			t.Errorf("SaveErrorCodeMessages(%q) error = %v, want ErrInvalidLocale", locale, err)
+		}
+	}
+}
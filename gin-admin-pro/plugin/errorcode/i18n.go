@@ -0,0 +1,390 @@
+package errorcode
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 内置语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEnUS = "en-US"
+)
+
+// maxLocaleLength 语言标签最大长度，与 ErrorCodeMessage.Locale 列长度一致
+const maxLocaleLength = 20
+
+// localePattern BCP 47 语言标签，如 zh-CN、en-US、zh-Hant-TW
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ErrorCodeMessage 错误码多语言消息，默认语言的消息保存在错误码表的 message 字段
+type ErrorCodeMessage struct {
+	ID        int       `gorm:"primarykey" json:"id"`
+	Code      int       `gorm:"not null;uniqueIndex:idx_code_locale" json:"code"`           // 错误码
+	Locale    string    `gorm:"size:20;not null;uniqueIndex:idx_code_locale" json:"locale"` // 语言，如 en-US
+	Message   string    `gorm:"size:500;not null" json:"message"`                           // 消息模板
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
+}
+
+// TableName 设置表名
+func (ErrorCodeMessage) TableName() string {
+	return "system_error_code_message"
+}
+
+// WithLocale 返回使用指定语言的服务副本，共享数据库连接和缓存。
+// 传入的语言会在支持的语言中匹配，支持 Accept-Language 格式。
+func (s *Service) WithLocale(locale string) *Service {
+	clone := *s
+	clone.locale = s.MatchLocale(locale)
+	return &clone
+}
+
+// GetLocale 获取当前语言
+func (s *Service) GetLocale() string {
+	if s.locale == "" {
+		return s.defaultLocale()
+	}
+	return s.locale
+}
+
+// GetLocales 获取支持的语言列表
+func (s *Service) GetLocales() []string {
+	if len(s.config.Locales) == 0 {
+		return []string{s.defaultLocale()}
+	}
+	return s.config.Locales
+}
+
+// defaultLocale 获取默认语言
+func (s *Service) defaultLocale() string {
+	if s.config.DefaultLocale == "" {
+		return LocaleZhCN
+	}
+	return s.config.DefaultLocale
+}
+
+// MatchLocale 根据 Accept-Language 匹配支持的语言，如 "en,zh-CN;q=0.8" 匹配 en-US。
+// 先按权重精确匹配，再按主语言匹配，均未匹配时返回默认语言。
+func (s *Service) MatchLocale(acceptLanguage string) string {
+	tags := parseAcceptLanguage(acceptLanguage)
+	locales := s.GetLocales()
+
+	for _, tag := range tags {
+		if tag == "*" {
+			return s.defaultLocale()
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		for _, locale := range locales {
+			localeBase, _, _ := strings.Cut(locale, "-")
+			if strings.EqualFold(base, localeBase) {
+				return locale
+			}
+		}
+	}
+	return s.defaultLocale()
+}
+
+// parseAcceptLanguage 解析 Accept-Language，按权重从高到低返回语言标签
+func parseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+		if tag == "" {
+			continue
+		}
+
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = v
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, weight: weight})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].weight > tags[j].weight
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// GetErrorCodeMessages 获取错误码的多语言消息（不含默认语言）
+func (s *Service) GetErrorCodeMessages(code int) ([]ErrorCodeMessage, error) {
+	var messages []ErrorCodeMessage
+	err := s.db.Where("code = ?", code).Order("locale ASC").Find(&messages).Error
+	return messages, err
+}
+
+// SaveErrorCodeMessages 保存错误码的多语言消息，messages 为 语言 -> 消息模板，
+// 消息为空表示删除该语言；默认语言的消息写入错误码表。语言须为支持的语言，否则返回 ErrInvalidLocale
+func (s *Service) SaveErrorCodeMessages(code int, messages map[string]string) error {
+	for locale := range messages {
+		if err := s.validateLocale(locale); err != nil {
+			return err
+		}
+	}
+
+	errorCode, err := s.getErrorCodeByCodeAnyStatus(code)
+	if err != nil {
+		return err
+	}
+
+	defaultLocale := s.defaultLocale()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for locale, message := range messages {
+			if locale == defaultLocale {
+				if message == "" {
+					continue
+				}
+				if err := tx.Model(errorCode).Update("message", message).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if message == "" {
+				if err := tx.Where("code = ? AND locale = ?", code, locale).Delete(&ErrorCodeMessage{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			var existing ErrorCodeMessage
+			err := tx.Where("code = ? AND locale = ?", code, locale).First(&existing).Error
+			switch {
+			case err == gorm.ErrRecordNotFound:
+				if err := tx.Create(&ErrorCodeMessage{Code: code, Locale: locale, Message: message}).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			default:
+				if err := tx.Model(&existing).Update("message", message).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.InvalidateCache()
+	return nil
+}
+
+// validateLocale 校验语言标签格式、长度，并要求在支持的语言列表中
+func (s *Service) validateLocale(locale string) error {
+	if len(locale) > maxLocaleLength || !localePattern.MatchString(locale) || !slices.Contains(s.GetLocales(), locale) {
+		return fmt.Errorf("%w：%q", ErrInvalidLocale, locale)
+	}
+	return nil
+}
+
+// getErrorCodeByCodeAnyStatus 根据错误码获取记录（包含禁用的）
+func (s *Service) getErrorCodeByCodeAnyStatus(code int) (*ErrorCode, error) {
+	var errorCode ErrorCode
+	if err := s.db.Where("code = ?", code).First(&errorCode).Error; err != nil {
+		return nil, err
+	}
+	return &errorCode, nil
+}
+
+// messageCache 启用的错误码消息快照：错误码 -> 语言 -> 消息模板
+type messageCache struct {
+	mu       sync.RWMutex
+	messages map[int]map[string]string
+	loadedAt time.Time
+}
+
+// InvalidateCache 清除消息缓存，下次获取消息时重新加载
+func (s *Service) InvalidateCache() {
+	s.cache.mu.Lock()
+	s.cache.messages = nil
+	s.cache.mu.Unlock()
+}
+
+// lookupMessage 从数据库（或缓存）查找错误码指定语言的消息
+func (s *Service) lookupMessage(code int, locale string) (string, bool) {
+	if s.db == nil {
+		return "", false
+	}
+
+	if !s.config.EnableCache {
+		return s.queryMessage(code, locale)
+	}
+
+	messages := s.loadMessages()
+	message, ok := messages[code][locale]
+	return message, ok
+}
+
+// queryMessage 直接查询数据库，未启用缓存时使用
+func (s *Service) queryMessage(code int, locale string) (string, bool) {
+	errorCode, err := s.GetErrorCodeByCode(code)
+	if err != nil {
+		return "", false
+	}
+	if locale == s.defaultLocale() {
+		return errorCode.Message, true
+	}
+
+	var message ErrorCodeMessage
+	if err := s.db.Where("code = ? AND locale = ?", code, locale).First(&message).Error; err != nil {
+		return "", false
+	}
+	return message.Message, true
+}
+
+// loadMessages 获取消息快照，过期或被清除时从数据库重新加载
+func (s *Service) loadMessages() map[int]map[string]string {
+	expire := time.Duration(s.config.CacheExpire) * time.Second
+
+	s.cache.mu.RLock()
+	messages := s.cache.messages
+	fresh := messages != nil && (expire <= 0 || time.Since(s.cache.loadedAt) < expire)
+	s.cache.mu.RUnlock()
+	if fresh {
+		return messages
+	}
+
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	if s.cache.messages != nil && (expire <= 0 || time.Since(s.cache.loadedAt) < expire) {
+		return s.cache.messages
+	}
+
+	loaded, err := s.queryAllMessages()
+	if err != nil {
+		log.Printf("Failed to load error code messages: %v", err)
+		// 加载失败时沿用旧数据，避免每次请求都访问数据库
+		if s.cache.messages == nil {
+			s.cache.messages = map[int]map[string]string{}
+		}
+		s.cache.loadedAt = time.Now()
+		return s.cache.messages
+	}
+
+	s.cache.messages = loaded
+	s.cache.loadedAt = time.Now()
+	return loaded
+}
+
+// queryAllMessages 查询全部启用的错误码及其多语言消息
+func (s *Service) queryAllMessages() (map[int]map[string]string, error) {
+	var errorCodes []ErrorCode
+	if err := s.db.Select("code", "message").Where("status = 1").Find(&errorCodes).Error; err != nil {
+		return nil, err
+	}
+
+	defaultLocale := s.defaultLocale()
+	messages := make(map[int]map[string]string, len(errorCodes))
+	for _, errorCode := range errorCodes {
+		messages[errorCode.Code] = map[string]string{defaultLocale: errorCode.Message}
+	}
+
+	var localized []ErrorCodeMessage
+	if err := s.db.Find(&localized).Error; err != nil {
+		return nil, err
+	}
+	for _, message := range localized {
+		if byLocale, ok := messages[message.Code]; ok {
+			byLocale[message.Locale] = message.Message
+		}
+	}
+	return messages, nil
+}
+
+// builtinMessages 内置的非默认语言消息，数据库未配置时使用
+var builtinMessages = map[string]map[int]string{
+	LocaleEnUS: {
+		CodeSuccess:            "Success",
+		CodeUnknownError:       "Unknown error, please contact the administrator",
+		CodeParamError:         "Invalid request parameter: %s",
+		CodeDataNotFound:       "Data not found: %s",
+		CodeDataExists:         "Data already exists: %s",
+		CodeOperationFailed:    "Operation failed: %s",
+		CodePermissionDenied:   "Permission denied",
+		CodeTokenExpired:       "Login expired, please log in again",
+		CodeTokenInvalid:       "Invalid login, please log in again",
+		CodeRateLimitExceeded:  "Too many requests, please try again later",
+		CodeUserNotFound:       "User not found",
+		CodeUserExists:         "User already exists: %s",
+		CodeUserDisabled:       "User is disabled",
+		CodePasswordError:      "Incorrect password, please try again",
+		CodeAccountLocked:      "Account is locked, please contact the administrator",
+		CodeLoginRequired:      "Please log in first",
+		CodeInvalidCredentials: "Incorrect username or password",
+		CodeRoleNotFound:       "Role not found",
+		CodeRoleExists:         "Role already exists: %s",
+		CodeRoleInUse:          "Role is in use and cannot be deleted",
+		CodeRoleBuiltin:        "Built-in role cannot be deleted",
+		CodePermissionNotFound: "Permission not found",
+		CodePermissionExists:   "Permission already exists: %s",
+		CodeBusinessError:      "Business error: %s",
+		CodeDataInvalid:        "Invalid data: %s",
+		CodeConfigError:        "Configuration error: %s",
+		CodeServiceUnavailable: "Service temporarily unavailable, please try again later",
+
+		CodeDeptParentSelf:           "A department cannot be its own parent",
+		CodeDeptCircularParent:       "A department cannot be moved under its own sub-department",
+		CodeDeptCannotDelete:         "Department not found or has sub-departments and cannot be deleted",
+		CodeMenuParentSelf:           "A menu cannot be its own parent",
+		CodeMenuCircularParent:       "A menu cannot be moved under its own sub-menu",
+		CodeMenuCannotDelete:         "Menu not found or has sub-menus and cannot be deleted",
+		CodeDictTypeHasData:          "Dictionary type has dictionary data and cannot be deleted",
+		CodeRestoreDeptMissing:       "Department not found or deleted, please restore the department first",
+		CodeRestoreParentDeptMissing: "Parent department not found or deleted, please restore it first",
+		CodeRestoreDeptNameExists:    "A department with the same name already exists under the same parent",
+		CodeRestoreParentMenuMissing: "Parent menu not found or deleted, please restore it first",
+		CodePurgeDeptHasChildren:     "Department has sub-departments and cannot be permanently deleted",
+		CodePurgeDeptHasUsers:        "Department has users and cannot be permanently deleted",
+		CodePurgeMenuHasChildren:     "Menu has sub-menus and cannot be permanently deleted",
+	},
+}
+
+// GetPredefinedErrorMessages 获取预定义错误码的多语言消息
+func GetPredefinedErrorMessages() []ErrorCodeMessage {
+	var messages []ErrorCodeMessage
+	for locale, byCode := range builtinMessages {
+		for code, message := range byCode {
+			messages = append(messages, ErrorCodeMessage{Code: code, Locale: locale, Message: message})
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Locale != messages[j].Locale {
+			return messages[i].Locale < messages[j].Locale
+		}
+		return messages[i].Code < messages[j].Code
+	})
+	return messages
+}
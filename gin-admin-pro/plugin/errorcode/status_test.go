@@ -0,0 +1,60 @@
+package errorcode
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 GORM 生成的 SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+// dryRunPool 不连接数据库的连接池，支持开启事务
+type dryRunPool struct{ gorm.ConnPool }
+
+func (p dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+func TestCreateDisabledErrorCode(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunPool{}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	require.NoError(t, err)
+	service := NewService(db, nil)
+
+	// 状态 0 不被列默认值覆盖
+	errorCode := &ErrorCode{ID: 5, Type: "business", Code: 9001, Name: "DEMO", Message: "示例", Status: 0}
+	require.NoError(t, service.CreateErrorCode(errorCode))
+	assert.Equal(t, 0, errorCode.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "system_error_code" SET "status"=0 WHERE "id" = 5`)
+
+	// 启用状态只需插入
+	recorder.statements = nil
+	require.NoError(t, service.CreateErrorCode(&ErrorCode{ID: 6, Type: "business", Code: 9002, Name: "DEMO2", Message: "示例", Status: 1}))
+	for _, statement := range recorder.statements {
+		assert.NotContains(t, statement, "UPDATE", statement)
+	}
+}
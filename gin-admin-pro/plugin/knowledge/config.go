@@ -0,0 +1,60 @@
+package knowledge
+
+// Config 知识库配置
+type Config struct {
+	// ChunkSize 文档切片的最大字符数
+	ChunkSize int `yaml:"chunkSize" json:"chunkSize"`
+	// ChunkOverlap 相邻切片重叠的字符数，避免语句在切分处断开后丢失上下文
+	ChunkOverlap int `yaml:"chunkOverlap" json:"chunkOverlap"`
+	// TopK 对话时检索的切片数
+	TopK int `yaml:"topK" json:"topK"`
+	// MinScore 最低相似度（余弦相似度），低于该值的切片不作为参考资料
+	MinScore float64 `yaml:"minScore" json:"minScore"`
+	// MaxFileSize 上传文档的最大字节数
+	MaxFileSize int64 `yaml:"maxFileSize" json:"maxFileSize"`
+	// IndexType 向量索引类型：hnsw/ivfflat
+	IndexType string `yaml:"indexType" json:"indexType"`
+	// Collection 切片所在的向量集合
+	Collection string `yaml:"collection" json:"collection"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		ChunkSize:    500,
+		ChunkOverlap: 50,
+		TopK:         4,
+		MinScore:     0.3,
+		MaxFileSize:  10 * 1024 * 1024, // 10MB
+		IndexType:    "hnsw",
+		Collection:   "ai_knowledge_vector",
+	}
+}
+
+// normalize 未配置的项使用默认值
+func (c *Config) normalize() *Config {
+	defaults := DefaultConfig()
+	if c == nil {
+		return defaults
+	}
+	merged := *c
+	if merged.ChunkSize <= 0 {
+		merged.ChunkSize = defaults.ChunkSize
+	}
+	if merged.ChunkOverlap < 0 || merged.ChunkOverlap >= merged.ChunkSize {
+		merged.ChunkOverlap = min(defaults.ChunkOverlap, merged.ChunkSize/2)
+	}
+	if merged.TopK <= 0 {
+		merged.TopK = defaults.TopK
+	}
+	if merged.MaxFileSize <= 0 {
+		merged.MaxFileSize = defaults.MaxFileSize
+	}
+	if merged.IndexType == "" {
+		merged.IndexType = defaults.IndexType
+	}
+	if merged.Collection == "" {
+		merged.Collection = defaults.Collection
+	}
+	return &merged
+}
@@ -0,0 +1,213 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/vectorstore"
+)
+
+// 切片集合的标量字段
+const (
+	fieldKnowledgeBaseID = "knowledge_base_id"
+	fieldDocumentID      = "document_id"
+	fieldDocumentName    = "document_name"
+	fieldSeq             = "seq"
+	fieldContent         = "content"
+)
+
+// maxChunksPerDocument 单个文档的最大切片数，切片ID为 文档ID*maxChunksPerDocument+序号
+const maxChunksPerDocument = 1 << 20
+
+// Engine 知识库索引和检索引擎：切分文档、向量化并写入向量存储，按问题检索相关切片
+type Engine struct {
+	embedder aiplugin.Embedder
+	store    vectorstore.VectorStore
+	config   *Config
+}
+
+// NewEngine 创建知识库引擎，切片保存在 store 的 config.Collection 集合中，config 为 nil 时使用默认配置
+func NewEngine(embedder aiplugin.Embedder, store vectorstore.VectorStore, config *Config) *Engine {
+	return &Engine{
+		embedder: embedder,
+		store:    store,
+		config:   config.normalize(),
+	}
+}
+
+// Config 知识库配置
+func (e *Engine) Config() *Config {
+	return e.config
+}
+
+// EmbeddingModel 向量模型名称
+func (e *Engine) EmbeddingModel() string {
+	return e.embedder.Model()
+}
+
+// Migrate 创建切片集合及向量索引，集合已存在时不修改。
+// 向量维度写入集合结构，更换维度不同的向量模型后需删除集合并重建知识库
+func (e *Engine) Migrate(ctx context.Context) error {
+	fields := []vectorstore.Field{
+		{Name: fieldKnowledgeBaseID, Type: vectorstore.FieldInt64},
+		{Name: fieldDocumentID, Type: vectorstore.FieldInt64},
+		{Name: fieldDocumentName, Type: vectorstore.FieldVarChar, MaxLength: 1024},
+		{Name: fieldSeq, Type: vectorstore.FieldInt64},
+		{Name: fieldContent, Type: vectorstore.FieldVarChar, MaxLength: 65535},
+	}
+	err := e.store.CreateCollection(ctx, e.config.Collection, &vectorstore.Schema{
+		Dimension: e.embedder.Dimension(),
+		Metric:    vectorstore.MetricCosine,
+		Index:     indexType(e.config.IndexType),
+		Fields:    fields,
+	})
+	if err != nil {
+		return fmt.Errorf("create knowledge collection failed: %w", err)
+	}
+	return nil
+}
+
+// Index 切分文档正文并写入向量，重复索引时先删除文档原有切片，返回切片数
+func (e *Engine) Index(ctx context.Context, doc *Document, text string) (int, error) {
+	pieces := SplitText(text, e.config.ChunkSize, e.config.ChunkOverlap)
+	if len(pieces) == 0 {
+		return 0, ErrEmptyDocument
+	}
+	if len(pieces) >= maxChunksPerDocument {
+		return 0, fmt.Errorf("document has too many chunks: %d", len(pieces))
+	}
+
+	// 切片前加上文档名，让只提到文档主题的问题也能命中
+	inputs := make([]string, len(pieces))
+	for i, piece := range pieces {
+		inputs[i] = doc.Name + "\n" + piece
+	}
+	vectors, err := e.embedder.Embed(ctx, inputs)
+	if err != nil {
+		return 0, fmt.Errorf("embed document failed: %w", err)
+	}
+	if len(vectors) != len(pieces) {
+		return 0, fmt.Errorf("embedding count mismatch: want %d, got %d", len(pieces), len(vectors))
+	}
+
+	records := make([]vectorstore.Record, len(pieces))
+	for i, piece := range pieces {
+		records[i] = vectorstore.Record{
+			ID:     int64(doc.ID)*maxChunksPerDocument + int64(i),
+			Vector: vectors[i],
+			Fields: map[string]interface{}{
+				fieldKnowledgeBaseID: int64(doc.KnowledgeBaseID),
+				fieldDocumentID:      int64(doc.ID),
+				fieldDocumentName:    doc.Name,
+				fieldSeq:             int64(i),
+				fieldContent:         piece,
+			},
+		}
+	}
+
+	if err := e.RemoveDocument(ctx, doc.ID); err != nil {
+		return 0, err
+	}
+	if err := e.store.Upsert(ctx, e.config.Collection, records); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+// Retrieve 在指定知识库中检索与问题最相关的切片，最多 TopK 个，过滤相似度低于 MinScore 的切片
+func (e *Engine) Retrieve(ctx context.Context, knowledgeBaseIDs []uint, query string) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if len(knowledgeBaseIDs) == 0 || query == "" {
+		return nil, nil
+	}
+
+	vectors, err := e.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query failed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedding count mismatch: want 1, got %d", len(vectors))
+	}
+
+	ids := make([]int64, len(knowledgeBaseIDs))
+	for i, id := range knowledgeBaseIDs {
+		ids[i] = int64(id)
+	}
+	hits, err := e.store.Search(ctx, e.config.Collection, &vectorstore.SearchRequest{
+		Vector:       vectors[0],
+		TopK:         e.config.TopK,
+		Filter:       map[string]interface{}{fieldKnowledgeBaseID: ids},
+		OutputFields: []string{fieldKnowledgeBaseID, fieldDocumentID, fieldDocumentName, fieldSeq, fieldContent},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var relevant []SearchResult
+	for _, hit := range hits {
+		if float64(hit.Score) < e.config.MinScore {
+			continue
+		}
+		relevant = append(relevant, SearchResult{
+			Chunk: Chunk{
+				ID:              uint(hit.ID),
+				KnowledgeBaseID: uint(intField(hit.Fields, fieldKnowledgeBaseID)),
+				DocumentID:      uint(intField(hit.Fields, fieldDocumentID)),
+				DocumentName:    stringField(hit.Fields, fieldDocumentName),
+				Seq:             int(intField(hit.Fields, fieldSeq)),
+				Content:         stringField(hit.Fields, fieldContent),
+			},
+			Score: float64(hit.Score),
+		})
+	}
+	return relevant, nil
+}
+
+// RemoveDocument 删除文档的切片
+func (e *Engine) RemoveDocument(ctx context.Context, documentID uint) error {
+	return e.store.DeleteByFilter(ctx, e.config.Collection, map[string]interface{}{fieldDocumentID: int64(documentID)})
+}
+
+// RemoveKnowledgeBase 删除知识库的切片
+func (e *Engine) RemoveKnowledgeBase(ctx context.Context, knowledgeBaseID uint) error {
+	return e.store.DeleteByFilter(ctx, e.config.Collection, map[string]interface{}{fieldKnowledgeBaseID: int64(knowledgeBaseID)})
+}
+
+// indexType 配置的索引类型转换为向量存储的索引类型，无法识别时原样返回，由创建集合时报错
+func indexType(name string) vectorstore.IndexType {
+	switch strings.ToLower(name) {
+	case "hnsw":
+		return vectorstore.IndexHNSW
+	case "ivfflat", "ivf_flat":
+		return vectorstore.IndexIVFFlat
+	}
+	return vectorstore.IndexType(name)
+}
+
+// intField 读取整数字段，兼容各存储返回的整数、浮点数和 json.Number
+func intField(fields map[string]interface{}, name string) int64 {
+	if number, ok := fields[name].(json.Number); ok {
+		value, _ := number.Int64()
+		return value
+	}
+	v := reflect.ValueOf(fields[name])
+	switch {
+	case v.CanInt():
+		return v.Int()
+	case v.CanUint():
+		return int64(v.Uint())
+	case v.CanFloat():
+		return int64(v.Float())
+	}
+	return 0
+}
+
+// stringField 读取字符串字段
+func stringField(fields map[string]interface{}, name string) string {
+	value, _ := fields[name].(string)
+	return value
+}
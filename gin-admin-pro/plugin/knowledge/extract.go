@@ -0,0 +1,68 @@
+package knowledge
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedDocument 不支持的文档格式
+var ErrUnsupportedDocument = errors.New("unsupported document format")
+
+// ErrEmptyDocument 文档没有可索引的文本
+var ErrEmptyDocument = errors.New("document has no text")
+
+// ErrInvalidEncoding 文档不是 UTF-8 编码
+var ErrInvalidEncoding = errors.New("document must be UTF-8 encoded")
+
+// SupportedExts 支持的文档扩展名，均为文本格式，PDF、Word 等需先转换为文本或 Markdown
+var SupportedExts = []string{".txt", ".md", ".markdown", ".csv", ".json", ".log", ".html", ".htm"}
+
+var (
+	// htmlBlockPattern 不含正文的 HTML 块
+	htmlBlockPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	// htmlBreakPattern 产生换行的 HTML 标签
+	htmlBreakPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])[^>]*>`)
+	// htmlTagPattern HTML 标签
+	htmlTagPattern = regexp.MustCompile(`(?s)<[^>]+>`)
+)
+
+// IsSupported 文件名是否为支持的文档格式
+func IsSupported(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, supported := range SupportedExts {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractText 按文件扩展名提取文档正文，HTML 去除标签，其他文本格式原样使用
+func ExtractText(filename string, data []byte) (string, error) {
+	if !IsSupported(filename) {
+		return "", ErrUnsupportedDocument
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return "", ErrInvalidEncoding
+	}
+
+	text := string(data)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".html", ".htm":
+		text = htmlBlockPattern.ReplaceAllString(text, "")
+		text = htmlBreakPattern.ReplaceAllString(text, "\n\n")
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+	}
+
+	text = normalizeSpace(text)
+	if text == "" {
+		return "", ErrEmptyDocument
+	}
+	return text, nil
+}
@@ -0,0 +1,147 @@
+package knowledge
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	aiplugin "gin-admin-pro/plugin/ai"
+	"gin-admin-pro/plugin/vectorstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSplitText(t *testing.T) {
+	// 短段落合并为一个切片
+	assert.Equal(t, []string{"第一段。\n第二段。"}, SplitText("第一段。\n\n第二段。", 20, 0))
+
+	// 超长段落在句末切分，相邻切片重叠
+	text := strings.Repeat("甲", 8) + "。" + strings.Repeat("乙", 8) + "。" + strings.Repeat("丙", 8) + "。"
+	chunks := SplitText(text, 12, 3)
+	require.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, runeLen(chunk), 12)
+	}
+	assert.True(t, strings.HasPrefix(chunks[1], "甲。\n"))
+	assert.Contains(t, chunks[1], "乙乙乙乙乙乙乙乙。")
+
+	// 单句超长时按字符截断
+	chunks = SplitText(strings.Repeat("a", 25), 10, 0)
+	assert.Equal(t, []string{strings.Repeat("a", 10), strings.Repeat("a", 10), strings.Repeat("a", 5)}, chunks)
+
+	assert.Empty(t, SplitText("  \n\n ", 10, 0))
+}
+
+func TestExtractText(t *testing.T) {
+	text, err := ExtractText("manual.md", []byte("\xef\xbb\xbf# 标题\r\n\r\n\r\n正文   内容\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# 标题\n\n正文 内容", text)
+
+	text, err = ExtractText("page.HTML", []byte(`<html><head><title>x</title></head><body><script>alert(1)</script><p>重置&amp;密码</p><p>联系管理员</p></body></html>`))
+	require.NoError(t, err)
+	assert.Equal(t, "重置&密码\n\n联系管理员", text)
+
+	_, err = ExtractText("manual.pdf", []byte("%PDF"))
+	assert.ErrorIs(t, err, ErrUnsupportedDocument)
+	_, err = ExtractText("empty.txt", []byte(" \n "))
+	assert.ErrorIs(t, err, ErrEmptyDocument)
+	_, err = ExtractText("gbk.txt", []byte{0xc4, 0xe3})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestEngineIndexAndRetrieve(t *testing.T) {
+	engine := NewEngine(aiplugin.NewHashEmbedder(512), vectorstore.NewMemoryStore(), &Config{ChunkSize: 40, TopK: 2, MinScore: 0.1})
+	ctx := context.Background()
+	require.NoError(t, engine.Migrate(ctx))
+
+	manual := &Document{ID: 1, KnowledgeBaseID: 1, Name: "员工手册.md"}
+	count, err := engine.Index(ctx, manual, "请假流程：员工请假需提前在系统提交申请，由部门负责人审批。\n\n报销流程：发票需在一个月内提交财务部审核。")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	other := &Document{ID: 2, KnowledgeBaseID: 2, Name: "其他.md"}
+	_, err = engine.Index(ctx, other, "请假流程：其他知识库中的内容。")
+	require.NoError(t, err)
+
+	results, err := engine.Retrieve(ctx, []uint{1}, "报销发票要多久内提交？")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Contains(t, results[0].Content, "报销流程")
+	assert.Equal(t, "员工手册.md", results[0].DocumentName)
+	for _, result := range results {
+		assert.Equal(t, uint(1), result.KnowledgeBaseID)
+	}
+
+	// 相似度过低的切片不返回
+	results, err = engine.Retrieve(ctx, []uint{1}, "quantum chromodynamics")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// 重新索引替换原有切片，删除文档后不再命中
+	count, err = engine.Index(ctx, manual, "报销流程：发票需在三个月内提交。")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	results, err = engine.Retrieve(ctx, []uint{1}, "报销发票")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Content, "三个月")
+
+	require.NoError(t, engine.RemoveDocument(ctx, manual.ID))
+	results, err = engine.Retrieve(ctx, []uint{1, 2}, "请假流程")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, uint(2), results[0].DocumentID)
+
+	// 删除知识库后其切片不再命中
+	require.NoError(t, engine.RemoveKnowledgeBase(ctx, 2))
+	results, err = engine.Retrieve(ctx, []uint{1, 2}, "请假流程")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	assert.Equal(t, vectorstore.IndexIVFFlat, indexType("ivfflat"))
+	assert.Equal(t, vectorstore.IndexHNSW, indexType("HNSW"))
+}
+
+// sqlRecorder 记录 GORM 生成的 SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+// dryRunPool 不连接数据库的连接池，支持开启事务
+type dryRunPool struct{ gorm.ConnPool }
+
+func (p dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+func TestCreateDisabledKnowledgeBase(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunPool{}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	require.NoError(t, err)
+	engine := NewEngine(aiplugin.NewHashEmbedder(8), vectorstore.NewMemoryStore(), nil)
+	service := NewService(db, engine, nil)
+
+	kb := &KnowledgeBase{ID: 3, Name: "内部资料", Status: 0}
+	require.NoError(t, service.CreateKnowledgeBase(kb))
+	assert.Equal(t, 0, kb.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "ai_knowledge_base" SET "status"=0 WHERE "id" = 3`)
+}
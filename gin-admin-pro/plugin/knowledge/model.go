@@ -0,0 +1,67 @@
+package knowledge
+
+import "time"
+
+// 文档状态
+const (
+	DocumentIndexing = 0 // 索引中
+	DocumentReady    = 1 // 可用
+	DocumentFailed   = 2 // 索引失败
+)
+
+// KnowledgeBase 知识库
+type KnowledgeBase struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	Name           string    `gorm:"size:100;not null" json:"name"`
+	Description    string    `gorm:"size:500" json:"description"`
+	EmbeddingModel string    `gorm:"size:100" json:"embeddingModel"`               // 创建时使用的向量模型
+	Status         int       `gorm:"default:1" json:"status" dict:"system_status"` // 0-禁用 1-启用
+	DocumentCount  int       `gorm:"default:0" json:"documentCount"`
+	CreateBy       uint      `json:"createBy"`
+	UpdateBy       uint      `json:"updateBy"`
+	CreatedAt      time.Time `json:"createTime"`
+	UpdatedAt      time.Time `json:"updateTime"`
+}
+
+// Document 知识库文档，原文件保存在对象存储中
+type Document struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	KnowledgeBaseID uint      `gorm:"not null;index" json:"knowledgeBaseId"`
+	Name            string    `gorm:"size:255;not null" json:"name"`
+	FileKey         string    `gorm:"size:500" json:"-"`
+	FileURL         string    `gorm:"size:500" json:"fileUrl"`
+	Size            int64     `json:"size"`
+	ContentType     string    `gorm:"size:100" json:"contentType"`
+	ChunkCount      int       `gorm:"default:0" json:"chunkCount"`
+	Status          int       `gorm:"default:0" json:"status"` // 0-索引中 1-可用 2-索引失败
+	Error           string    `gorm:"size:500" json:"error,omitempty"`
+	CreateBy        uint      `json:"createBy"`
+	CreatedAt       time.Time `json:"createTime"`
+	UpdatedAt       time.Time `json:"updateTime"`
+}
+
+// TableName 设置表名
+func (KnowledgeBase) TableName() string {
+	return "ai_knowledge_base"
+}
+
+func (Document) TableName() string {
+	return "ai_knowledge_document"
+}
+
+// Chunk 文档切片及其向量
+type Chunk struct {
+	ID              uint      `json:"id"`
+	KnowledgeBaseID uint      `json:"knowledgeBaseId"`
+	DocumentID      uint      `json:"documentId"`
+	DocumentName    string    `json:"documentName"`
+	Seq             int       `json:"seq"` // 在文档中的序号，从 0 开始
+	Content         string    `json:"content"`
+	Embedding       []float32 `json:"-"`
+}
+
+// SearchResult 检索结果，Score 为余弦相似度，越大越相关
+type SearchResult struct {
+	Chunk
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,264 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gin-admin-pro/plugin/oss"
+
+	"gorm.io/gorm"
+)
+
+// ErrDocumentTooLarge 文档超过大小限制
+var ErrDocumentTooLarge = errors.New("document is too large")
+
+// Service 知识库服务，知识库和文档信息保存在数据库中，原文件保存在对象存储中
+type Service struct {
+	db      *gorm.DB
+	engine  *Engine
+	storage oss.OSSInterface
+}
+
+// NewService 创建知识库服务实例
+func NewService(db *gorm.DB, engine *Engine, storage oss.OSSInterface) *Service {
+	return &Service{db: db, engine: engine, storage: storage}
+}
+
+// Migrate 创建知识库和文档表，以及切片所在的向量集合
+func (s *Service) Migrate() error {
+	if err := s.db.AutoMigrate(&KnowledgeBase{}, &Document{}); err != nil {
+		return err
+	}
+	return s.engine.Migrate(context.Background())
+}
+
+// Engine 知识库引擎
+func (s *Service) Engine() *Engine {
+	return s.engine
+}
+
+// GetKnowledgeBases 分页获取知识库，status 为 -1 时不按状态筛选
+func (s *Service) GetKnowledgeBases(page, pageSize int, name string, status int) ([]KnowledgeBase, int64, error) {
+	var (
+		list  []KnowledgeBase
+		total int64
+	)
+	query := s.db.Model(&KnowledgeBase{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	if status != -1 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// GetKnowledgeBase 根据ID获取知识库
+func (s *Service) GetKnowledgeBase(id uint) (*KnowledgeBase, error) {
+	var kb KnowledgeBase
+	if err := s.db.First(&kb, id).Error; err != nil {
+		return nil, err
+	}
+	return &kb, nil
+}
+
+// CreateKnowledgeBase 创建知识库，记录当前使用的向量模型
+func (s *Service) CreateKnowledgeBase(kb *KnowledgeBase) error {
+	kb.EmbeddingModel = s.engine.EmbeddingModel()
+	status := kb.Status
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(kb).Error; err != nil {
+			return err
+		}
+		// GORM 创建时把状态 0 替换为列默认值，禁用的知识库需写回状态，否则对话时会被检索
+		if kb.Status == status {
+			return nil
+		}
+		kb.Status = status
+		return tx.Model(kb).UpdateColumn("status", status).Error
+	})
+}
+
+// UpdateKnowledgeBase 更新知识库名称、描述和状态
+func (s *Service) UpdateKnowledgeBase(kb *KnowledgeBase) error {
+	result := s.db.Model(&KnowledgeBase{}).Where("id = ?", kb.ID).Updates(map[string]interface{}{
+		"name":        kb.Name,
+		"description": kb.Description,
+		"status":      kb.Status,
+		"update_by":   kb.UpdateBy,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteKnowledgeBase 删除知识库及其文档、切片和原文件
+func (s *Service) DeleteKnowledgeBase(ctx context.Context, id uint) error {
+	if _, err := s.GetKnowledgeBase(id); err != nil {
+		return err
+	}
+
+	var docs []Document
+	if err := s.db.Where("knowledge_base_id = ?", id).Find(&docs).Error; err != nil {
+		return err
+	}
+	if err := s.engine.RemoveKnowledgeBase(ctx, id); err != nil {
+		return err
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("knowledge_base_id = ?", id).Delete(&Document{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&KnowledgeBase{}, id).Error
+	}); err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		s.deleteFile(&doc)
+	}
+	return nil
+}
+
+// GetDocuments 分页获取知识库文档
+func (s *Service) GetDocuments(knowledgeBaseID uint, page, pageSize int, name string) ([]Document, int64, error) {
+	var (
+		list  []Document
+		total int64
+	)
+	query := s.db.Model(&Document{}).Where("knowledge_base_id = ?", knowledgeBaseID)
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// UploadDocument 上传文档：保存原文件到对象存储，提取正文并建立索引。
+// 索引失败时文档状态为失败并记录原因，可删除后重新上传
+func (s *Service) UploadDocument(ctx context.Context, knowledgeBaseID uint, filename string, reader io.Reader, contentType string, userID uint) (*Document, error) {
+	if _, err := s.GetKnowledgeBase(knowledgeBaseID); err != nil {
+		return nil, err
+	}
+	if !IsSupported(filename) {
+		return nil, ErrUnsupportedDocument
+	}
+
+	maxSize := s.engine.Config().MaxFileSize
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrDocumentTooLarge
+	}
+	text, err := ExtractText(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(filename)
+	key := fmt.Sprintf("knowledge/%d/%s_%s", knowledgeBaseID, time.Now().Format("20060102150405"), strings.ReplaceAll(name, " ", "_"))
+	fileURL, err := s.storage.UploadFile(key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		KnowledgeBaseID: knowledgeBaseID,
+		Name:            name,
+		FileKey:         key,
+		FileURL:         fileURL,
+		Size:            int64(len(data)),
+		ContentType:     contentType,
+		Status:          DocumentIndexing,
+		CreateBy:        userID,
+	}
+	if err := s.db.Create(doc).Error; err != nil {
+		s.deleteFile(doc)
+		return nil, err
+	}
+	if err := s.db.Model(&KnowledgeBase{}).Where("id = ?", knowledgeBaseID).
+		UpdateColumn("document_count", gorm.Expr("document_count + 1")).Error; err != nil {
+		return nil, err
+	}
+
+	count, indexErr := s.engine.Index(ctx, doc, text)
+	updates := map[string]interface{}{"status": DocumentReady, "chunk_count": count, "error": ""}
+	if indexErr != nil {
+		message := indexErr.Error()
+		if len([]rune(message)) > 500 {
+			message = string([]rune(message)[:500])
+		}
+		updates = map[string]interface{}{"status": DocumentFailed, "chunk_count": 0, "error": message}
+	}
+	if err := s.db.Model(doc).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(doc, doc.ID).Error; err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// DeleteDocument 删除文档及其切片和原文件
+func (s *Service) DeleteDocument(ctx context.Context, id uint) error {
+	var doc Document
+	if err := s.db.First(&doc, id).Error; err != nil {
+		return err
+	}
+	if err := s.engine.RemoveDocument(ctx, id); err != nil {
+		return err
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Document{}, id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&KnowledgeBase{}).Where("id = ? AND document_count > 0", doc.KnowledgeBaseID).
+			UpdateColumn("document_count", gorm.Expr("document_count - 1")).Error
+	}); err != nil {
+		return err
+	}
+	s.deleteFile(&doc)
+	return nil
+}
+
+// Retrieve 在启用的知识库中检索与问题最相关的切片，禁用或不存在的知识库被忽略
+func (s *Service) Retrieve(ctx context.Context, knowledgeBaseIDs []uint, query string) ([]SearchResult, error) {
+	if len(knowledgeBaseIDs) == 0 {
+		return nil, nil
+	}
+	var enabled []uint
+	if err := s.db.Model(&KnowledgeBase{}).Where("id IN ? AND status = ?", knowledgeBaseIDs, 1).Pluck("id", &enabled).Error; err != nil {
+		return nil, err
+	}
+	return s.engine.Retrieve(ctx, enabled, query)
+}
+
+// deleteFile 删除文档原文件，失败不影响删除结果
+func (s *Service) deleteFile(doc *Document) {
+	if doc.FileKey != "" {
+		_ = s.storage.DeleteFile(doc.FileKey)
+	}
+}
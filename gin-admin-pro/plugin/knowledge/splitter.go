@@ -0,0 +1,141 @@
+package knowledge
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sentenceEnds 句末标点，切片优先在段落、其次在句末断开
+const sentenceEnds = "。！？；.!?;"
+
+// SplitText 将文本切分为不超过 size 个字符的切片，相邻切片重叠 overlap 个字符。
+// 优先在段落边界切分，段落过长时在句末切分，单句过长时按字符截断
+func SplitText(text string, size, overlap int) []string {
+	if size <= 0 {
+		return nil
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	// 先切成不超过 size 的片段，再合并相邻片段
+	var pieces []string
+	for _, paragraph := range splitParagraphs(text) {
+		if runeLen(paragraph) <= size {
+			pieces = append(pieces, paragraph)
+			continue
+		}
+		for _, sentence := range splitSentences(paragraph) {
+			runes := []rune(sentence)
+			for len(runes) > size {
+				pieces = append(pieces, string(runes[:size]))
+				runes = runes[size:]
+			}
+			if len(runes) > 0 {
+				pieces = append(pieces, string(runes))
+			}
+		}
+	}
+
+	var (
+		chunks  []string
+		current []rune
+	)
+	for _, piece := range pieces {
+		runes := []rune(piece)
+		sep := 0
+		if len(current) > 0 {
+			sep = 1
+		}
+		if len(current)+sep+len(runes) <= size {
+			if sep > 0 {
+				current = append(current, '\n')
+			}
+			current = append(current, runes...)
+			continue
+		}
+
+		chunks = append(chunks, string(current))
+		// 新切片以上一切片的末尾开头，重叠部分加当前片段超长时缩短重叠
+		tail := current[max(len(current)-overlap, 0):]
+		if len(tail)+1+len(runes) > size {
+			tail = tail[min(len(tail), len(tail)+1+len(runes)-size):]
+		}
+		current = append([]rune{}, tail...)
+		if len(current) > 0 {
+			current = append(current, '\n')
+		}
+		current = append(current, runes...)
+	}
+	if len(strings.TrimSpace(string(current))) > 0 {
+		chunks = append(chunks, string(current))
+	}
+	return chunks
+}
+
+// splitParagraphs 按空行切分段落，去除段落首尾空白
+func splitParagraphs(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var paragraphs []string
+	for _, block := range strings.Split(text, "\n\n") {
+		if block = strings.TrimSpace(block); block != "" {
+			paragraphs = append(paragraphs, block)
+		}
+	}
+	return paragraphs
+}
+
+// splitSentences 在句末标点和换行处切分，标点保留在句尾
+func splitSentences(paragraph string) []string {
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+	flush := func() {
+		if sentence := strings.TrimSpace(current.String()); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		current.Reset()
+	}
+	for _, r := range paragraph {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+		if strings.ContainsRune(sentenceEnds, r) {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
+
+// runeLen 字符数
+func runeLen(text string) int {
+	return len([]rune(text))
+}
+
+// normalizeSpace 合并连续空白，保留换行以区分段落
+func normalizeSpace(text string) string {
+	var builder strings.Builder
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	blank := 0
+	for _, line := range lines {
+		line = strings.Join(strings.FieldsFunc(line, unicode.IsSpace), " ")
+		if line == "" {
+			blank++
+			continue
+		}
+		if builder.Len() > 0 {
+			if blank > 0 {
+				builder.WriteString("\n\n")
+			} else {
+				builder.WriteString("\n")
+			}
+		}
+		builder.WriteString(line)
+		blank = 0
+	}
+	return builder.String()
+}
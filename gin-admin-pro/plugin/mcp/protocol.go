@@ -0,0 +1,99 @@
+package mcp
+
+import "encoding/json"
+
+// LatestProtocolVersion 支持的最新 MCP 协议版本
+const LatestProtocolVersion = "2025-06-18"
+
+// supportedVersions 支持的协议版本，客户端请求的版本不在其中时协商为最新版本
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// MCP 方法
+const (
+	methodInitialize  = "initialize"
+	methodInitialized = "notifications/initialized"
+	methodPing        = "ping"
+	methodToolsList   = "tools/list"
+	methodToolsCall   = "tools/call"
+)
+
+// JSON-RPC 错误码
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	// codeUnauthorized 服务端自定义错误码，stdio 方式凭证失效时返回
+	codeUnauthorized = -32001
+)
+
+// request JSON-RPC 请求或通知，通知没有 ID
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification 是否为通知或客户端发来的响应，二者都不需要回复
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
+// response JSON-RPC 响应
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError JSON-RPC 错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Implementation 服务端名称和版本
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// initializeParams initialize 请求参数
+type initializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+// initializeResult initialize 响应
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      Implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// listToolsResult tools/list 响应，工具一次全部返回，不分页
+type listToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// callToolParams tools/call 请求参数
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// callToolResult tools/call 响应，工具执行失败时 IsError 为 true，错误信息作为内容返回给模型
+type callToolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// textContent 文本内容
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
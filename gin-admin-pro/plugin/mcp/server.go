@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+)
+
+var (
+	// ErrUnauthorized 凭证无效
+	ErrUnauthorized = errors.New("invalid credential")
+	// ErrForbidden 凭证有效但所属用户不允许使用，如用户已禁用
+	ErrForbidden = errors.New("forbidden")
+	// ErrToolNotFound 工具不存在或调用用户无权使用
+	ErrToolNotFound = errors.New("tool not found")
+)
+
+// Tool 提供给客户端的工具
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// InputSchema 参数的 JSON Schema
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// ToolProvider 工具来源，ctx 为 Authenticator 返回的已认证上下文
+type ToolProvider interface {
+	// ListTools 列出调用方可用的工具
+	ListTools(ctx context.Context) ([]Tool, error)
+	// CallTool 执行工具，返回值序列化为 JSON 后返回给客户端；工具不存在时返回 ErrToolNotFound
+	CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+}
+
+// Authenticator 校验客户端凭证，返回携带调用方身份的上下文，凭证无效时返回 ErrUnauthorized，
+// 用户不允许使用时返回 ErrForbidden，其他错误视为服务端内部错误
+type Authenticator func(ctx context.Context, credential string) (context.Context, error)
+
+// Server MCP（Model Context Protocol）服务端，通过 stdio 或 Streamable HTTP 向 AI 客户端提供工具
+type Server struct {
+	info           Implementation
+	instructions   string
+	tools          ToolProvider
+	authenticate   Authenticator
+	allowedOrigins []string
+}
+
+// NewServer 创建 MCP 服务端
+func NewServer(info Implementation, tools ToolProvider, authenticate Authenticator) *Server {
+	return &Server{
+		info:         info,
+		tools:        tools,
+		authenticate: authenticate,
+	}
+}
+
+// SetInstructions 设置 initialize 时返回给客户端的使用说明
+func (s *Server) SetInstructions(instructions string) {
+	s.instructions = instructions
+}
+
+// SetAllowedOrigins 设置允许的浏览器来源，HTTP 请求带 Origin 头且不在其中时拒绝，防止 DNS 重绑定攻击
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.allowedOrigins = origins
+}
+
+// Authenticate 校验凭证，返回携带调用方身份的上下文
+func (s *Server) Authenticate(ctx context.Context, credential string) (context.Context, error) {
+	if credential == "" {
+		return nil, ErrUnauthorized
+	}
+	return s.authenticate(ctx, credential)
+}
+
+// HandleMessage 处理一条 JSON-RPC 消息或批量消息，ctx 需已认证。通知和客户端响应不需要回复，返回 nil
+func (s *Server) HandleMessage(ctx context.Context, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil || len(batch) == 0 {
+			return encode(errorResponse(nil, codeInvalidRequest, "invalid batch"))
+		}
+		var responses []*response
+		for _, message := range batch {
+			if resp := s.handle(ctx, message); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return encode(responses)
+	}
+
+	if resp := s.handle(ctx, data); resp != nil {
+		return encode(resp)
+	}
+	return nil
+}
+
+// handle 处理单条消息
+func (s *Server) handle(ctx context.Context, data []byte) *response {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, codeParseError, "parse error")
+	}
+	if req.Method == "" {
+		// 客户端对服务端请求的响应，服务端不发送请求，直接忽略
+		return nil
+	}
+	if req.JSONRPC != "2.0" {
+		return errorResponse(req.ID, codeInvalidRequest, "jsonrpc must be 2.0")
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// dispatch 按方法处理请求
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, *rpcError) {
+	switch req.Method {
+	case methodInitialize:
+		var params initializeParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		version := LatestProtocolVersion
+		if slices.Contains(supportedVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return &initializeResult{
+			ProtocolVersion: version,
+			Capabilities:    map[string]interface{}{"tools": map[string]interface{}{"listChanged": false}},
+			ServerInfo:      s.info,
+			Instructions:    s.instructions,
+		}, nil
+
+	case methodInitialized:
+		return nil, nil
+
+	case methodPing:
+		return struct{}{}, nil
+
+	case methodToolsList:
+		tools, err := s.tools.ListTools(ctx)
+		if err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		if tools == nil {
+			tools = []Tool{}
+		}
+		return &listToolsResult{Tools: tools}, nil
+
+	case methodToolsCall:
+		var params callToolParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.Arguments == nil {
+			params.Arguments = map[string]interface{}{}
+		}
+		return s.callTool(ctx, &params)
+
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// callTool 执行工具，工具本身的错误以 isError 结果返回，便于模型根据错误修正参数
+func (s *Server) callTool(ctx context.Context, params *callToolParams) (interface{}, *rpcError) {
+	value, err := s.tools.CallTool(ctx, params.Name, params.Arguments)
+	if errors.Is(err, ErrToolNotFound) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+	if err != nil {
+		return &callToolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+
+	text, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("MCP tool %s returned unencodable result: %v", params.Name, err)
+		return nil, &rpcError{Code: codeInternalError, Message: "encode result failed"}
+	}
+	return &callToolResult{Content: []textContent{{Type: "text", Text: string(text)}}}, nil
+}
+
+// decodeParams 解析请求参数
+func decodeParams(raw json.RawMessage, params interface{}) *rpcError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
+
+// errorResponse 创建错误响应，无法解析请求 ID 时 ID 为 null
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+// encode 序列化响应
+func encode(value interface{}) []byte {
+	data, _ := json.Marshal(value)
+	return data
+}
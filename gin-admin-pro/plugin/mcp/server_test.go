@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userKey 上下文中测试用户的键
+type userKey struct{}
+
+// fakeTools 测试用工具：echo 返回参数和调用用户，fail 总是失败
+type fakeTools struct{}
+
+func (fakeTools) ListTools(ctx context.Context) ([]Tool, error) {
+	return []Tool{
+		{Name: "echo", InputSchema: map[string]interface{}{"type": "object"}},
+		{Name: "fail", InputSchema: map[string]interface{}{"type": "object"}},
+	}, nil
+}
+
+func (fakeTools) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "echo":
+		return map[string]interface{}{"user": ctx.Value(userKey{}), "args": args}, nil
+	case "fail":
+		return nil, errors.New("没有权限：system:user:list")
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+}
+
+// newTestServer 创建测试服务端，凭证 good 认证为用户 alice，disabled 为已禁用用户，broken 模拟数据库故障
+func newTestServer() *Server {
+	server := NewServer(Implementation{Name: "test", Version: "1.0.0"}, fakeTools{}, func(ctx context.Context, credential string) (context.Context, error) {
+		switch credential {
+		case "good":
+			return context.WithValue(ctx, userKey{}, "alice"), nil
+		case "disabled":
+			return nil, fmt.Errorf("%w: 用户已被禁用", ErrForbidden)
+		case "broken":
+			return nil, errors.New("dial tcp 10.0.0.5:3306: connection refused")
+		default:
+			return nil, ErrUnauthorized
+		}
+	})
+	server.SetAllowedOrigins([]string{"http://localhost:6274"})
+	return server
+}
+
+// post 发送 HTTP 请求并返回响应
+func post(t *testing.T, server *Server, credential, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPToolsFlow(t *testing.T) {
+	server := newTestServer()
+
+	w := post(t, server, "good", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"client","version":"1"}}}`, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var initResp struct {
+		Result initializeResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &initResp))
+	assert.Equal(t, "2025-03-26", initResp.Result.ProtocolVersion)
+	assert.Equal(t, "test", initResp.Result.ServerInfo.Name)
+	assert.Contains(t, initResp.Result.Capabilities, "tools")
+
+	// 通知不需要回复
+	w = post(t, server, "good", `{"jsonrpc":"2.0","method":"notifications/initialized"}`, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, nil)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{"tools":[{"name":"echo","inputSchema":{"type":"object"}},{"name":"fail","inputSchema":{"type":"object"}}]}}`, w.Body.String())
+
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"echo","arguments":{"q":"x"}}}`, nil)
+	var callResp struct {
+		Result callToolResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &callResp))
+	require.Len(t, callResp.Result.Content, 1)
+	assert.False(t, callResp.Result.IsError)
+	assert.JSONEq(t, `{"user":"alice","args":{"q":"x"}}`, callResp.Result.Content[0].Text)
+
+	// 工具执行失败以 isError 结果返回，未知工具返回 JSON-RPC 错误
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"fail"}}`, nil)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":{"content":[{"type":"text","text":"没有权限：system:user:list"}],"isError":true}}`, w.Body.String())
+
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`, nil)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"unknown tool: missing"}}`, w.Body.String())
+
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":5,"method":"resources/list"}`, nil)
+	assert.Contains(t, w.Body.String(), `"code":-32601`)
+
+	w = post(t, server, "good", `{"jsonrpc":"2.0","id":6,`, nil)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`, w.Body.String())
+
+	// 批量消息
+	w = post(t, server, "good", `[{"jsonrpc":"2.0","id":7,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"}]`, nil)
+	assert.JSONEq(t, `[{"jsonrpc":"2.0","id":7,"result":{}}]`, w.Body.String())
+}
+
+func TestHTTPRejects(t *testing.T) {
+	server := newTestServer()
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+
+	w := post(t, server, "", ping, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(t, http.StatusUnauthorized, post(t, server, "bad", ping, nil).Code)
+	w = post(t, server, "disabled", ping, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "用户已被禁用")
+
+	// 内部错误不向客户端暴露原始信息
+	w = post(t, server, "broken", ping, nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+
+	assert.Equal(t, http.StatusForbidden, post(t, server, "good", ping, map[string]string{"Origin": "http://evil.example"}).Code)
+	assert.Equal(t, http.StatusOK, post(t, server, "good", ping, map[string]string{"Origin": "http://localhost:6274"}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(t, server, "good", ping, map[string]string{"MCP-Protocol-Version": "1999-01-01"}).Code)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestServeStdio(t *testing.T) {
+	server := newTestServer()
+	_, err := server.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2099-01-01"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+	}, "\n"))
+	var out bytes.Buffer
+	require.NoError(t, server.ServeStdio(context.Background(), "good", in, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"protocolVersion":"`+LatestProtocolVersion+`"`)
+	assert.Contains(t, lines[1], `\"user\":\"alice\"`)
+}
+
+func TestServeStdioReauthenticate(t *testing.T) {
+	// 第二条消息前凭证失效（如令牌过期或用户被禁用）
+	calls := 0
+	server := NewServer(Implementation{Name: "test", Version: "1.0.0"}, fakeTools{}, func(ctx context.Context, credential string) (context.Context, error) {
+		calls++
+		if calls > 1 {
+			return nil, ErrUnauthorized
+		}
+		return context.WithValue(ctx, userKey{}, "alice"), nil
+	})
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	}, "\n"))
+	var out bytes.Buffer
+	err := server.ServeStdio(context.Background(), "good", in, &out)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, lines[0])
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"error":{"code":-32001,"message":"invalid credential"}}`, lines[1])
+
+	// 内部错误只影响当前消息
+	broken := newTestServer()
+	out.Reset()
+	err = broken.ServeStdio(context.Background(), "broken", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), &out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"internal error"}}`, out.String())
+}
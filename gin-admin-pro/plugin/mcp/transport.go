@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxMessageSize 单条消息的最大字节数
+const maxMessageSize = 4 << 20
+
+// ServeStdio 按 stdio 传输处理消息：每行一条 JSON-RPC 消息，回复逐行写入 out。
+// 每条消息处理前使用 credential 重新认证，令牌过期或用户被禁用后回复错误并返回；in 关闭或 ctx 取消时返回
+func (s *Server) ServeStdio(ctx context.Context, credential string, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	writer := bufio.NewWriter(out)
+	write := func(reply []byte) error {
+		if _, err := writer.Write(append(reply, '\n')); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		authCtx, err := s.Authenticate(ctx, credential)
+		if err != nil {
+			var req request
+			_ = json.Unmarshal(line, &req)
+			if isCredentialError(err) {
+				if writeErr := write(encode(errorResponse(req.ID, codeUnauthorized, credentialErrorMessage(err)))); writeErr != nil {
+					return writeErr
+				}
+				return err
+			}
+			// 数据库等临时故障只影响本条消息
+			log.Printf("MCP authenticate failed: %v", err)
+			if err := write(encode(errorResponse(req.ID, codeInternalError, "internal error"))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reply := s.HandleMessage(authCtx, line)
+		if reply == nil {
+			continue
+		}
+		if err := write(reply); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP 按 Streamable HTTP 传输处理消息。服务端无状态、不主动推送，
+// POST 请求直接返回 JSON 响应，GET 和 DELETE 返回 405；每个请求通过 Authorization: Bearer 头认证
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !slices.Contains(s.allowedOrigins, origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if version := r.Header.Get("MCP-Protocol-Version"); version != "" && !slices.Contains(supportedVersions, version) {
+		http.Error(w, "unsupported protocol version: "+version, http.StatusBadRequest)
+		return
+	}
+
+	credential, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	ctx, err := s.Authenticate(r.Context(), strings.TrimSpace(credential))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnauthorized):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, credentialErrorMessage(err), http.StatusUnauthorized)
+		case errors.Is(err, ErrForbidden):
+			http.Error(w, credentialErrorMessage(err), http.StatusForbidden)
+		default:
+			// 数据库等内部错误只记录日志，不返回给客户端
+			log.Printf("MCP authenticate failed: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxMessageSize {
+		http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	reply := s.HandleMessage(ctx, body)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(reply)
+}
+
+// isCredentialError 是否为凭证无效或用户不允许使用，重试不会成功
+func isCredentialError(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden)
+}
+
+// credentialErrorMessage 返回给客户端的认证错误信息，不包含原始错误
+func credentialErrorMessage(err error) string {
+	if errors.Is(err, ErrForbidden) {
+		return ErrForbidden.Error()
+	}
+	return ErrUnauthorized.Error()
+}
@@ -0,0 +1,344 @@
+package milvus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-admin-pro/plugin/vectorstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRequest 假 Milvus 收到的请求
+type fakeRequest struct {
+	Path          string
+	Authorization string
+	Body          map[string]interface{}
+}
+
+// newFakeClient 启动按路径路由的假 Milvus，handler 返回响应中的 data，未配置的路径返回集合不存在
+func newFakeClient(t *testing.T, routes map[string]string) (*Client, *[]fakeRequest) {
+	var requests []fakeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		request := fakeRequest{Path: strings.TrimPrefix(r.URL.Path, "/v2/vectordb"), Authorization: r.Header.Get("Authorization")}
+		require.NoError(t, json.Unmarshal(data, &request.Body))
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if body, ok := routes[request.Path]; ok {
+			io.WriteString(w, body)
+			return
+		}
+		io.WriteString(w, `{"code":100,"message":"collection not found[database=gin_admin][collection=missing]"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := DefaultConfig()
+	cfg.Address = server.URL
+	cfg.Username = "root"
+	cfg.Password = "Milvus"
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	return client, &requests
+}
+
+// jsonOf 序列化后再解析，便于与请求体比较
+func jsonOf(t *testing.T, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var result interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+func TestConfigBaseURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:19530", DefaultConfig().baseURL())
+	assert.Equal(t, "https://milvus.example.com", (&Config{Address: "https://milvus.example.com/"}).baseURL())
+	assert.Equal(t, "http://10.0.0.1:19531", (&Config{Address: "10.0.0.1:19531", Port: 19530}).baseURL())
+	assert.Equal(t, "key", (&Config{Username: "root", Password: "x", Token: "key"}).token())
+	assert.Equal(t, "root:x", (&Config{Username: "root", Password: "x"}).token())
+}
+
+func TestClientErrors(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/collections/list": `{"code":0,"data":["docs"]}`,
+		"/collections/has":  `{"code":1802,"message":"database not found[database=gin_admin]"}`,
+	})
+	ctx := context.Background()
+
+	// 请求带数据库名和认证令牌
+	require.NoError(t, client.Ping())
+	assert.Equal(t, "Bearer root:Milvus", (*requests)[0].Authorization)
+	assert.Equal(t, "gin_admin", (*requests)[0].Body["dbName"])
+
+	_, err := client.HasCollection(ctx, "docs")
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, 1802, respErr.Code)
+	assert.NotErrorIs(t, err, ErrCollectionNotFound)
+
+	_, err = client.DescribeCollection(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
+
+func TestClientRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"code":0,"data":{"has":true}}`)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Address = server.URL
+	client, _ := NewClient(cfg)
+	has, err := client.HasCollection(context.Background(), "docs")
+	require.NoError(t, err)
+	assert.True(t, has)
+	assert.Equal(t, 3, attempts)
+
+	// 超过重试次数返回最后一次错误
+	attempts = -10
+	_, err = client.HasCollection(context.Background(), "docs")
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+}
+
+func TestCollections(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/collections/create": `{"code":0,"data":{}}`,
+		"/collections/describe": `{"code":0,"data":{"collectionName":"docs","load":"LoadStateLoaded","fields":[
+			{"name":"id","type":"Int64","primaryKey":true},
+			{"name":"vector","type":"FloatVector","params":[{"key":"dim","value":"3"}]},
+			{"name":"title","type":"VarChar","params":[{"key":"max_length","value":"200"},{"key":"mmap.enabled","value":"false"}]}]}}`,
+		"/collections/get_stats": `{"code":0,"data":{"rowCount":42}}`,
+		"/indexes/create":        `{"code":0,"data":{}}`,
+		"/indexes/describe":      `{"code":0,"data":[{"fieldName":"vector","indexName":"vector","indexType":"HNSW","metricType":"COSINE","indexState":"Finished","totalRows":42,"indexedRows":42}]}`,
+		"/collections/load":      `{"code":0,"data":{}}`,
+		"/collections/drop":      `{"code":0,"data":{}}`,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateCollection(ctx, &CollectionSchema{
+		Name: "docs",
+		Fields: []Field{
+			{Name: "id", DataType: DataTypeInt64, IsPrimary: true},
+			{Name: "vector", DataType: DataTypeFloatVector, Dim: 3},
+			{Name: "title", DataType: DataTypeVarChar, MaxLength: 200},
+		},
+	}, HNSWIndex("vector", MetricCosine, 16, 200)))
+	assert.Equal(t, jsonOf(t, map[string]interface{}{
+		"dbName":         "gin_admin",
+		"collectionName": "docs",
+		"schema": map[string]interface{}{
+			"autoId":             false,
+			"enableDynamicField": false,
+			"fields": []interface{}{
+				map[string]interface{}{"fieldName": "id", "dataType": "Int64", "isPrimary": true},
+				map[string]interface{}{"fieldName": "vector", "dataType": "FloatVector", "elementTypeParams": map[string]interface{}{"dim": "3"}},
+				map[string]interface{}{"fieldName": "title", "dataType": "VarChar", "elementTypeParams": map[string]interface{}{"max_length": "200"}},
+			},
+		},
+		"indexParams": []interface{}{map[string]interface{}{
+			"fieldName":  "vector",
+			"indexName":  "vector",
+			"metricType": "COSINE",
+			"params":     map[string]interface{}{"index_type": "HNSW", "M": 16, "efConstruction": 200},
+		}},
+	}), jsonOf(t, (*requests)[0].Body))
+
+	info, err := client.DescribeCollection(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, "LoadStateLoaded", info.LoadState)
+	assert.Equal(t, Field{Name: "title", DataType: DataTypeVarChar, MaxLength: 200}, info.Fields[2])
+
+	stats, err := client.GetCollectionStats(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, &CollectionStats{CollectionName: "docs", RowCount: 42, Dimension: 3}, stats)
+
+	require.NoError(t, client.CreateIndex(ctx, "docs", IVFFlatIndex("vector", MetricL2, 128)))
+	last := (*requests)[len(*requests)-1]
+	assert.Equal(t, "/indexes/create", last.Path)
+	assert.Equal(t, jsonOf(t, map[string]interface{}{"index_type": "IVF_FLAT", "nlist": 128}),
+		last.Body["indexParams"].([]interface{})[0].(map[string]interface{})["params"])
+
+	indexes, err := client.DescribeIndex(ctx, "docs", "vector")
+	require.NoError(t, err)
+	assert.Equal(t, "Finished", indexes[0].IndexState)
+
+	require.NoError(t, client.LoadCollection(ctx, "docs"))
+	require.NoError(t, client.DropCollection(ctx, "docs"))
+}
+
+func TestEntities(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/entities/insert": `{"code":0,"data":{"insertCount":2,"insertIds":[1,2]}}`,
+		"/entities/upsert": `{"code":0,"data":{"upsertCount":1,"upsertIds":[1]}}`,
+		"/entities/delete": `{"code":0,"data":{}}`,
+		"/entities/query":  `{"code":0,"data":[{"id":9007199254740993,"title":"a"}]}`,
+		"/entities/get":    `{"code":0,"data":[{"id":1,"title":"a"}]}`,
+	})
+	ctx := context.Background()
+
+	n, err := client.Insert(ctx, "docs", []Row{
+		{"id": 1, "vector": []float32{0.1, 0.2}, "title": "a"},
+		{"id": 2, "vector": []float32{0.3, 0.4}, "title": "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, (*requests)[0].Body["data"], 2)
+
+	n, err = client.Upsert(ctx, "docs", []Row{{"id": 1, "vector": []float32{0.5, 0.6}, "title": "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NoError(t, client.Delete(ctx, "docs", IDsExpr("id", []int64{1, 2})))
+	assert.Equal(t, "id in [1, 2]", (*requests)[2].Body["filter"])
+
+	// 大整数主键不丢失精度
+	rows, err := client.Query(ctx, "docs", &QueryRequest{Filter: `title == "a"`, OutputFields: []string{"title"}, Limit: 10})
+	require.NoError(t, err)
+	id, ok := rows[0].Int64("id")
+	assert.True(t, ok)
+	assert.Equal(t, int64(9007199254740993), id)
+	assert.Equal(t, float64(10), (*requests)[3].Body["limit"])
+
+	rows, err = client.Get(ctx, "docs", []int64{1}, "title")
+	require.NoError(t, err)
+	assert.Equal(t, "a", rows[0]["title"])
+
+	// 空数据不发送请求
+	n, err = client.Insert(ctx, "docs", nil)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, *requests, 5)
+}
+
+func TestSearch(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/entities/search": `{"code":0,"cost":0,"data":[{"id":3,"distance":0.92,"title":"a","category":"news"},{"id":5,"distance":0.81,"title":"b","category":"news"}]}`,
+	})
+
+	hits, err := client.Search(context.Background(), "docs", &SearchRequest{
+		Vector:       []float32{0.1, 0.2},
+		AnnsField:    "vector",
+		Filter:       `category == "news"`,
+		Limit:        2,
+		OutputFields: []string{"title", "category"},
+		Params:       map[string]interface{}{"ef": 64},
+	})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, int64(3), hits[0].ID)
+	assert.InDelta(t, 0.92, hits[0].Distance, 1e-6)
+	assert.Equal(t, Row{"title": "a", "category": "news"}, hits[0].Fields)
+
+	assert.Equal(t, jsonOf(t, map[string]interface{}{
+		"dbName":         "gin_admin",
+		"collectionName": "docs",
+		"data":           [][]float32{{0.1, 0.2}},
+		"annsField":      "vector",
+		"filter":         `category == "news"`,
+		"limit":          2,
+		"outputFields":   []string{"title", "category"},
+		"searchParams":   map[string]interface{}{"params": map[string]interface{}{"ef": 64}},
+	}), jsonOf(t, (*requests)[0].Body))
+}
+
+func TestVectorStore(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/collections/has":    `{"code":0,"data":{"has":false}}`,
+		"/collections/create": `{"code":0,"data":{}}`,
+		"/entities/upsert":    `{"code":0,"data":{"upsertCount":1}}`,
+		"/entities/search":    `{"code":0,"data":[{"id":7,"distance":1.5,"title":"x"}]}`,
+		"/entities/delete":    `{"code":0,"data":{}}`,
+	})
+	ctx := context.Background()
+	var store vectorstore.VectorStore = client.VectorStore()
+
+	require.NoError(t, store.CreateCollection(ctx, "docs", &vectorstore.Schema{
+		Dimension: 2,
+		Metric:    vectorstore.MetricL2,
+		Index:     vectorstore.IndexIVFFlat,
+		Fields:    []vectorstore.Field{{Name: "title", Type: vectorstore.FieldVarChar, MaxLength: 100}},
+	}))
+	create := (*requests)[1].Body
+	fields := create["schema"].(map[string]interface{})["fields"].([]interface{})
+	assert.Len(t, fields, 3)
+	assert.Equal(t, jsonOf(t, map[string]interface{}{
+		"fieldName": "vector", "indexName": "vector", "metricType": "L2",
+		"params": map[string]interface{}{"index_type": "IVF_FLAT", "nlist": ivfNlist},
+	}), create["indexParams"].([]interface{})[0])
+
+	require.NoError(t, store.Upsert(ctx, "docs", []vectorstore.Record{{ID: 7, Vector: []float32{1, 2}, Fields: map[string]interface{}{"title": "x"}}}))
+	assert.Equal(t, jsonOf(t, []Row{{"id": 7, "vector": []float32{1, 2}, "title": "x"}}), (*requests)[2].Body["data"])
+
+	hits, err := store.Search(ctx, "docs", &vectorstore.SearchRequest{
+		Vector:       []float32{1, 2},
+		TopK:         5,
+		Filter:       map[string]interface{}{"title": "x", "year": []int{2023, 2024}},
+		OutputFields: []string{"title"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []vectorstore.Hit{{ID: 7, Score: 1.5, Fields: Row{"title": "x"}}}, hits)
+	assert.Equal(t, `title == "x" and year in [2023, 2024]`, (*requests)[3].Body["filter"])
+
+	require.NoError(t, store.Delete(ctx, "docs", []int64{7, 8}))
+	assert.Equal(t, "id in [7, 8]", (*requests)[4].Body["filter"])
+	require.NoError(t, store.DeleteByFilter(ctx, "docs", map[string]interface{}{"doc_id": []int64{3, 4}}))
+	assert.Equal(t, "doc_id in [3, 4]", (*requests)[5].Body["filter"])
+	assert.ErrorIs(t, store.DeleteByFilter(ctx, "docs", nil), vectorstore.ErrEmptyFilter)
+
+	// 删除不存在的集合不报错
+	require.NoError(t, store.DropCollection(ctx, "missing"))
+	assert.ErrorIs(t, store.CreateCollection(ctx, "bad-name", &vectorstore.Schema{Dimension: 2}), vectorstore.ErrInvalidName)
+}
+
+func TestFilterExpr(t *testing.T) {
+	expr, err := FilterExpr(map[string]interface{}{"title": `say "hi"`, "score": 0.5, "active": true})
+	require.NoError(t, err)
+	assert.Equal(t, `active == true and score == 0.5 and title == "say \"hi\""`, expr)
+
+	expr, err = FilterExpr(nil)
+	require.NoError(t, err)
+	assert.Empty(t, expr)
+
+	_, err = FilterExpr(map[string]interface{}{"title || 1": 1})
+	assert.ErrorIs(t, err, vectorstore.ErrInvalidName)
+	_, err = FilterExpr(map[string]interface{}{"meta": map[string]int{}})
+	assert.Error(t, err)
+}
+
+func TestPluginCollections(t *testing.T) {
+	client, requests := newFakeClient(t, map[string]string{
+		"/collections/list":      `{"code":0,"data":["user_embeddings"]}`,
+		"/collections/has":       `{"code":0,"data":{"has":true}}`,
+		"/collections/describe":  `{"code":0,"data":{"collectionName":"user_embeddings","fields":[{"name":"vector","type":"FloatVector","params":[{"key":"dim","value":128}]}]}}`,
+		"/collections/get_stats": `{"code":0,"data":{"rowCount":3}}`,
+	})
+	plugin := NewPlugin(client.GetConfig())
+	require.NoError(t, plugin.Init())
+
+	// 集合已存在时跳过
+	require.NoError(t, plugin.CreateDefaultCollections(context.Background()))
+	for _, request := range *requests {
+		assert.NotEqual(t, "/collections/create", request.Path)
+	}
+
+	info, err := plugin.GetCollectionInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, info["totalCollections"])
+	assert.Equal(t, []map[string]interface{}{{"name": "user_embeddings", "rowCount": int64(3), "dimension": 128}}, info["collections"])
+}
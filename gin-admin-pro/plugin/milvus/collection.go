@@ -0,0 +1,314 @@
+package milvus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// DataType 字段类型
+type DataType string
+
+const (
+	DataTypeInt64       DataType = "Int64"
+	DataTypeInt32       DataType = "Int32"
+	DataTypeFloat       DataType = "Float"
+	DataTypeDouble      DataType = "Double"
+	DataTypeBool        DataType = "Bool"
+	DataTypeVarChar     DataType = "VarChar"
+	DataTypeJSON        DataType = "JSON"
+	DataTypeFloatVector DataType = "FloatVector"
+)
+
+// MetricType 向量相似度度量
+type MetricType string
+
+const (
+	MetricCosine MetricType = "COSINE"
+	MetricL2     MetricType = "L2"
+	MetricIP     MetricType = "IP"
+)
+
+// IndexType 向量索引类型
+type IndexType string
+
+const (
+	IndexFlat      IndexType = "FLAT"
+	IndexHNSW      IndexType = "HNSW"
+	IndexIVFFlat   IndexType = "IVF_FLAT"
+	IndexIVFSQ8    IndexType = "IVF_SQ8"
+	IndexAutoIndex IndexType = "AUTOINDEX"
+)
+
+// Field 字段定义
+type Field struct {
+	Name        string
+	DataType    DataType
+	Description string
+	IsPrimary   bool
+	// Dim 向量维度，仅向量字段使用
+	Dim int
+	// MaxLength VarChar 的最大长度
+	MaxLength int
+}
+
+// CollectionSchema 集合结构
+type CollectionSchema struct {
+	Name        string
+	Description string
+	// AutoID 主键由 Milvus 生成，写入时不传主键
+	AutoID bool
+	// EnableDynamicField 允许写入未定义的字段
+	EnableDynamicField bool
+	Fields             []Field
+}
+
+// Index 索引定义
+type Index struct {
+	FieldName string
+	// IndexName 为空时使用字段名
+	IndexName  string
+	IndexType  IndexType
+	MetricType MetricType
+	// Params 索引参数，如 HNSW 的 M、efConstruction，IVF 的 nlist
+	Params map[string]interface{}
+}
+
+// HNSWIndex HNSW 索引，m 为每个节点的最大连接数，efConstruction 为构建时的候选数，为 0 时使用 Milvus 默认值
+func HNSWIndex(field string, metric MetricType, m, efConstruction int) Index {
+	params := map[string]interface{}{}
+	if m > 0 {
+		params["M"] = m
+	}
+	if efConstruction > 0 {
+		params["efConstruction"] = efConstruction
+	}
+	return Index{FieldName: field, IndexType: IndexHNSW, MetricType: metric, Params: params}
+}
+
+// IVFFlatIndex IVF_FLAT 索引，nlist 为聚类数量，为 0 时使用 Milvus 默认值
+func IVFFlatIndex(field string, metric MetricType, nlist int) Index {
+	params := map[string]interface{}{}
+	if nlist > 0 {
+		params["nlist"] = nlist
+	}
+	return Index{FieldName: field, IndexType: IndexIVFFlat, MetricType: metric, Params: params}
+}
+
+// CollectionInfo 集合信息
+type CollectionInfo struct {
+	Name        string
+	Description string
+	Fields      []Field
+	// LoadState 加载状态，如 LoadStateLoaded、LoadStateNotLoad
+	LoadState string
+}
+
+// IndexInfo 索引信息
+type IndexInfo struct {
+	FieldName  string `json:"fieldName"`
+	IndexName  string `json:"indexName"`
+	IndexType  string `json:"indexType"`
+	MetricType string `json:"metricType"`
+	// IndexState 构建状态，Finished 表示完成
+	IndexState  string `json:"indexState"`
+	TotalRows   int64  `json:"totalRows"`
+	IndexedRows int64  `json:"indexedRows"`
+	FailReason  string `json:"failReason"`
+}
+
+// CollectionStats 集合统计信息
+type CollectionStats struct {
+	CollectionName string `json:"collectionName"`
+	RowCount       int64  `json:"rowCount"`
+	// Dimension 第一个向量字段的维度
+	Dimension int `json:"dimension"`
+}
+
+// ListCollections 列出所有集合
+func (c *Client) ListCollections(ctx context.Context) ([]string, error) {
+	var names []string
+	if err := c.perform(ctx, "/collections/list", nil, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// HasCollection 检查集合是否存在
+func (c *Client) HasCollection(ctx context.Context, collectionName string) (bool, error) {
+	var result struct {
+		Has bool `json:"has"`
+	}
+	err := c.perform(ctx, "/collections/has", map[string]interface{}{"collectionName": collectionName}, &result)
+	return result.Has, err
+}
+
+// CreateCollection 创建集合。指定索引时同时创建索引并加载集合，之后即可搜索；
+// 未指定索引时需调用 CreateIndex 和 LoadCollection
+func (c *Client) CreateCollection(ctx context.Context, schema *CollectionSchema, indexes ...Index) error {
+	fields := make([]map[string]interface{}, len(schema.Fields))
+	for i, field := range schema.Fields {
+		item := map[string]interface{}{
+			"fieldName": field.Name,
+			"dataType":  field.DataType,
+		}
+		if field.IsPrimary {
+			item["isPrimary"] = true
+		}
+		if field.Description != "" {
+			item["description"] = field.Description
+		}
+		params := map[string]interface{}{}
+		if field.Dim > 0 {
+			params["dim"] = strconv.Itoa(field.Dim)
+		}
+		if field.MaxLength > 0 {
+			params["max_length"] = strconv.Itoa(field.MaxLength)
+		}
+		if len(params) > 0 {
+			item["elementTypeParams"] = params
+		}
+		fields[i] = item
+	}
+
+	body := map[string]interface{}{
+		"collectionName": schema.Name,
+		"schema": map[string]interface{}{
+			"autoId":             schema.AutoID,
+			"enableDynamicField": schema.EnableDynamicField,
+			"fields":             fields,
+		},
+	}
+	if schema.Description != "" {
+		body["description"] = schema.Description
+	}
+	if len(indexes) > 0 {
+		body["indexParams"] = indexParams(indexes)
+	}
+	return c.perform(ctx, "/collections/create", body, nil)
+}
+
+// DropCollection 删除集合
+func (c *Client) DropCollection(ctx context.Context, collectionName string) error {
+	return c.perform(ctx, "/collections/drop", map[string]interface{}{"collectionName": collectionName}, nil)
+}
+
+// DescribeCollection 获取集合结构和加载状态
+func (c *Client) DescribeCollection(ctx context.Context, collectionName string) (*CollectionInfo, error) {
+	var result struct {
+		CollectionName string `json:"collectionName"`
+		Description    string `json:"description"`
+		Fields         []struct {
+			Name        string `json:"name"`
+			Type        string `json:"type"`
+			Description string `json:"description"`
+			PrimaryKey  bool   `json:"primaryKey"`
+			Params      []struct {
+				Key   string      `json:"key"`
+				Value interface{} `json:"value"`
+			} `json:"params"`
+		} `json:"fields"`
+		Load string `json:"load"`
+	}
+	if err := c.perform(ctx, "/collections/describe", map[string]interface{}{"collectionName": collectionName}, &result); err != nil {
+		return nil, err
+	}
+
+	info := &CollectionInfo{Name: result.CollectionName, Description: result.Description, LoadState: result.Load}
+	for _, item := range result.Fields {
+		field := Field{Name: item.Name, DataType: DataType(item.Type), Description: item.Description, IsPrimary: item.PrimaryKey}
+		for _, param := range item.Params {
+			value, _ := strconv.Atoi(fmt.Sprint(param.Value))
+			switch param.Key {
+			case "dim":
+				field.Dim = value
+			case "max_length":
+				field.MaxLength = value
+			}
+		}
+		info.Fields = append(info.Fields, field)
+	}
+	return info, nil
+}
+
+// GetCollectionStats 获取集合记录数和向量维度
+func (c *Client) GetCollectionStats(ctx context.Context, collectionName string) (*CollectionStats, error) {
+	info, err := c.DescribeCollection(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	stats := &CollectionStats{CollectionName: collectionName}
+	for _, field := range info.Fields {
+		if field.DataType == DataTypeFloatVector {
+			stats.Dimension = field.Dim
+			break
+		}
+	}
+
+	var result struct {
+		RowCount int64 `json:"rowCount"`
+	}
+	if err := c.perform(ctx, "/collections/get_stats", map[string]interface{}{"collectionName": collectionName}, &result); err != nil {
+		return nil, err
+	}
+	stats.RowCount = result.RowCount
+	return stats, nil
+}
+
+// LoadCollection 将集合加载到内存，加载后才能搜索和查询
+func (c *Client) LoadCollection(ctx context.Context, collectionName string) error {
+	return c.perform(ctx, "/collections/load", map[string]interface{}{"collectionName": collectionName}, nil)
+}
+
+// ReleaseCollection 从内存释放集合
+func (c *Client) ReleaseCollection(ctx context.Context, collectionName string) error {
+	return c.perform(ctx, "/collections/release", map[string]interface{}{"collectionName": collectionName}, nil)
+}
+
+// CreateIndex 创建索引，索引在后台构建，可通过 DescribeIndex 查看进度
+func (c *Client) CreateIndex(ctx context.Context, collectionName string, indexes ...Index) error {
+	return c.perform(ctx, "/indexes/create", map[string]interface{}{
+		"collectionName": collectionName,
+		"indexParams":    indexParams(indexes),
+	}, nil)
+}
+
+// DescribeIndex 获取索引信息和构建进度
+func (c *Client) DescribeIndex(ctx context.Context, collectionName, indexName string) ([]IndexInfo, error) {
+	var infos []IndexInfo
+	err := c.perform(ctx, "/indexes/describe", map[string]interface{}{
+		"collectionName": collectionName,
+		"indexName":      indexName,
+	}, &infos)
+	return infos, err
+}
+
+// DropIndex 删除索引，集合需先释放
+func (c *Client) DropIndex(ctx context.Context, collectionName, indexName string) error {
+	return c.perform(ctx, "/indexes/drop", map[string]interface{}{
+		"collectionName": collectionName,
+		"indexName":      indexName,
+	}, nil)
+}
+
+// indexParams 转换为接口的索引参数
+func indexParams(indexes []Index) []map[string]interface{} {
+	items := make([]map[string]interface{}, len(indexes))
+	for i, index := range indexes {
+		name := index.IndexName
+		if name == "" {
+			name = index.FieldName
+		}
+		params := map[string]interface{}{"index_type": index.IndexType}
+		for key, value := range index.Params {
+			params[key] = value
+		}
+		items[i] = map[string]interface{}{
+			"fieldName":  index.FieldName,
+			"indexName":  name,
+			"metricType": index.MetricType,
+			"params":     params,
+		}
+	}
+	return items
+}
@@ -0,0 +1,125 @@
+package milvus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Row 一条记录，键为字段名，向量字段的值为 []float32
+type Row map[string]interface{}
+
+// Insert 插入记录，返回插入的条数。Milvus 不校验主键唯一，重复插入会产生重复记录
+func (c *Client) Insert(ctx context.Context, collectionName string, rows []Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	var result struct {
+		InsertCount int `json:"insertCount"`
+	}
+	err := c.perform(ctx, "/entities/insert", map[string]interface{}{
+		"collectionName": collectionName,
+		"data":           rows,
+	}, &result)
+	return result.InsertCount, err
+}
+
+// Upsert 写入记录，主键已存在时覆盖，返回写入的条数
+func (c *Client) Upsert(ctx context.Context, collectionName string, rows []Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	var result struct {
+		UpsertCount int `json:"upsertCount"`
+	}
+	err := c.perform(ctx, "/entities/upsert", map[string]interface{}{
+		"collectionName": collectionName,
+		"data":           rows,
+	}, &result)
+	return result.UpsertCount, err
+}
+
+// Delete 删除满足过滤表达式的记录，如 id in [1, 2]、category == "news"
+func (c *Client) Delete(ctx context.Context, collectionName, filter string) error {
+	if strings.TrimSpace(filter) == "" {
+		return fmt.Errorf("milvus: delete requires a filter expression")
+	}
+	return c.perform(ctx, "/entities/delete", map[string]interface{}{
+		"collectionName": collectionName,
+		"filter":         filter,
+	}, nil)
+}
+
+// QueryRequest 标量查询请求
+type QueryRequest struct {
+	// Filter 过滤表达式
+	Filter       string
+	OutputFields []string
+	Limit        int
+	Offset       int
+}
+
+// Query 按过滤表达式查询记录
+func (c *Client) Query(ctx context.Context, collectionName string, req *QueryRequest) ([]Row, error) {
+	body := map[string]interface{}{
+		"collectionName": collectionName,
+		"filter":         req.Filter,
+	}
+	if len(req.OutputFields) > 0 {
+		body["outputFields"] = req.OutputFields
+	}
+	if req.Limit > 0 {
+		body["limit"] = req.Limit
+	}
+	if req.Offset > 0 {
+		body["offset"] = req.Offset
+	}
+
+	var rows []Row
+	if err := c.perform(ctx, "/entities/query", body, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Get 按主键获取记录
+func (c *Client) Get(ctx context.Context, collectionName string, ids []int64, outputFields ...string) ([]Row, error) {
+	body := map[string]interface{}{
+		"collectionName": collectionName,
+		"id":             ids,
+	}
+	if len(outputFields) > 0 {
+		body["outputFields"] = outputFields
+	}
+
+	var rows []Row
+	if err := c.perform(ctx, "/entities/get", body, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Int64 读取整数字段，字段不存在或不是整数时返回 false
+func (r Row) Int64(field string) (int64, bool) {
+	switch value := r[field].(type) {
+	case json.Number:
+		id, err := value.Int64()
+		return id, err == nil
+	case int64:
+		return value, true
+	case int:
+		return int64(value), true
+	}
+	return 0, false
+}
+
+// IDsExpr 主键在 ids 中的过滤表达式
+func IDsExpr(field string, ids []int64) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprintf("%s in [%s]", field, strings.Join(values, ", "))
+}
@@ -0,0 +1,177 @@
+package milvus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDisabled 未启用 Milvus
+	ErrDisabled = errors.New("milvus is disabled")
+	// ErrCollectionNotFound 集合不存在
+	ErrCollectionNotFound = errors.New("milvus collection not found")
+)
+
+// codeCollectionNotFound Milvus 集合不存在的错误码
+const codeCollectionNotFound = 100
+
+// ResponseError Milvus 返回的错误，可用 errors.Is 判断 ErrCollectionNotFound
+type ResponseError struct {
+	// StatusCode HTTP 状态码，Milvus 业务错误通常为 200
+	StatusCode int
+	// Code Milvus 错误码
+	Code    int
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *ResponseError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("milvus: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("milvus: code %d: %s", e.Code, e.Message)
+}
+
+// Is 错误码 100 视为 ErrCollectionNotFound
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrCollectionNotFound &&
+		(e.Code == codeCollectionNotFound || strings.Contains(e.Message, "collection not found"))
+}
+
+// Client Milvus RESTful（v2）客户端
+type Client struct {
+	config     *Config
+	httpClient *http.Client
+}
+
+// NewClient 创建Milvus客户端，不会立即连接，可调用 Ping 检查连接
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	client := &Client{config: cfg}
+	if !cfg.Enabled {
+		return client, nil
+	}
+
+	timeout := cfg.GetTimeout()
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	client.httpClient = &http.Client{Timeout: timeout}
+	return client, nil
+}
+
+// GetConfig 获取配置
+func (c *Client) GetConfig() *Config {
+	return c.config
+}
+
+// IsEnabled 是否启用
+func (c *Client) IsEnabled() bool {
+	return c.config.Enabled
+}
+
+// Ping 测试连接
+func (c *Client) Ping() error {
+	_, err := c.ListCollections(context.Background())
+	return err
+}
+
+// response RESTful 接口的响应
+type response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// perform 调用 /v2/vectordb 下的接口，请求体自动带上数据库名，data 解析到 result，result 为 nil 时丢弃
+func (c *Client) perform(ctx context.Context, path string, body map[string]interface{}, result interface{}) error {
+	if !c.IsEnabled() {
+		return ErrDisabled
+	}
+
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	if c.config.Database != "" {
+		body["dbName"] = c.config.Database
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= max(c.config.MaxRetries, 0); attempt++ {
+		resp, err := c.send(ctx, path, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = &ResponseError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+			if retryable(resp.StatusCode) {
+				continue
+			}
+			return lastErr
+		}
+
+		var res response
+		if err := json.Unmarshal(data, &res); err != nil {
+			return fmt.Errorf("milvus: invalid response: %w", err)
+		}
+		// 早期版本成功时返回 200
+		if res.Code != 0 && res.Code != http.StatusOK {
+			return &ResponseError{StatusCode: resp.StatusCode, Code: res.Code, Message: res.Message}
+		}
+		if result == nil || len(res.Data) == 0 {
+			return nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(res.Data))
+		decoder.UseNumber()
+		return decoder.Decode(result)
+	}
+	return lastErr
+}
+
+// send 发送一次请求
+func (c *Client) send(ctx context.Context, path string, payload []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.baseURL()+"/v2/vectordb"+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token := c.config.token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryable 服务繁忙或不可用时重试
+func retryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package milvus
+
+import (
+	"context"
+	"fmt"
+
+	"gin-admin-pro/plugin/vectorstore"
+)
+
+// Plugin Milvus插件
+type Plugin struct {
+	config *Config
+	client *Client
+}
+
+// NewPlugin 创建Milvus插件
+func NewPlugin(cfg *Config) *Plugin {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	client, _ := NewClient(cfg)
+
+	return &Plugin{
+		config: cfg,
+		client: client,
+	}
+}
+
+// GetConfig 获取配置
+func (p *Plugin) GetConfig() *Config {
+	return p.config
+}
+
+// GetClient 获取客户端
+func (p *Plugin) GetClient() *Client {
+	return p.client
+}
+
+// IsEnabled 是否启用
+func (p *Plugin) IsEnabled() bool {
+	return p.config.Enabled
+}
+
+// Init 初始化插件
+func (p *Plugin) Init() error {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	// 测试连接
+	if err := p.client.Ping(); err != nil {
+		return fmt.Errorf("failed to connect to milvus: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDefaultCollections 创建默认集合，已存在的集合跳过
+func (p *Plugin) CreateDefaultCollections(ctx context.Context) error {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	store := p.client.VectorStore()
+	collections := []struct {
+		name   string
+		schema *vectorstore.Schema
+	}{
+		// 用户嵌入向量集合
+		{"user_embeddings", &vectorstore.Schema{Dimension: 128}},
+		// 内容嵌入向量集合
+		{"content_embeddings", &vectorstore.Schema{
+			Dimension: 768,
+			Fields: []vectorstore.Field{
+				{Name: "title", Type: vectorstore.FieldVarChar, MaxLength: 512},
+				{Name: "category", Type: vectorstore.FieldVarChar, MaxLength: 64},
+			},
+		}},
+		// 商品嵌入向量集合
+		{"product_embeddings", &vectorstore.Schema{
+			Dimension: 256,
+			Fields: []vectorstore.Field{
+				{Name: "category", Type: vectorstore.FieldVarChar, MaxLength: 64},
+				{Name: "price", Type: vectorstore.FieldDouble},
+			},
+		}},
+	}
+	for _, collection := range collections {
+		if err := store.CreateCollection(ctx, collection.name, collection.schema); err != nil {
+			return fmt.Errorf("failed to create collection %s: %w", collection.name, err)
+		}
+	}
+	return nil
+}
+
+// GetCollectionInfo 获取集合信息
+func (p *Plugin) GetCollectionInfo(ctx context.Context) (map[string]interface{}, error) {
+	if !p.IsEnabled() {
+		return nil, ErrDisabled
+	}
+
+	collections, err := p.client.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var collectionInfos []map[string]interface{}
+	for _, name := range collections {
+		stats, err := p.client.GetCollectionStats(ctx, name)
+		if err != nil {
+			continue
+		}
+
+		collectionInfos = append(collectionInfos, map[string]interface{}{
+			"name":      stats.CollectionName,
+			"rowCount":  stats.RowCount,
+			"dimension": stats.Dimension,
+		})
+	}
+
+	return map[string]interface{}{
+		"totalCollections": len(collections),
+		"collections":      collectionInfos,
+	}, nil
+}
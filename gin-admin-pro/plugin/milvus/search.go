@@ -0,0 +1,93 @@
+package milvus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SearchRequest 向量检索请求
+type SearchRequest struct {
+	Vector []float32
+	// AnnsField 检索的向量字段
+	AnnsField string
+	// Filter 标量过滤表达式，如 category == "news" and year > 2020
+	Filter string
+	Limit  int
+	Offset int
+	// OutputFields 随结果返回的字段
+	OutputFields []string
+	// MetricType 为空时使用索引的度量
+	MetricType MetricType
+	// Params 检索参数，如 HNSW 的 ef，IVF 的 nprobe
+	Params map[string]interface{}
+}
+
+// Hit 检索结果
+type Hit struct {
+	// ID 主键字段 id 的值，主键使用其他名称时在 Fields 中
+	ID int64
+	// Distance 相似度分数，COSINE 和 IP 越大越相似，L2 越小越相似
+	Distance float32
+	// Fields 请求的输出字段
+	Fields Row
+}
+
+// Search 检索与向量最相近的记录，按相似程度排序
+func (c *Client) Search(ctx context.Context, collectionName string, req *SearchRequest) ([]Hit, error) {
+	if len(req.Vector) == 0 {
+		return nil, fmt.Errorf("milvus: search vector is empty")
+	}
+	body := map[string]interface{}{
+		"collectionName": collectionName,
+		"data":           [][]float32{req.Vector},
+		"limit":          max(req.Limit, 1),
+	}
+	if req.AnnsField != "" {
+		body["annsField"] = req.AnnsField
+	}
+	if req.Filter != "" {
+		body["filter"] = req.Filter
+	}
+	if req.Offset > 0 {
+		body["offset"] = req.Offset
+	}
+	if len(req.OutputFields) > 0 {
+		body["outputFields"] = req.OutputFields
+	}
+	if req.MetricType != "" || len(req.Params) > 0 {
+		searchParams := map[string]interface{}{}
+		if req.MetricType != "" {
+			searchParams["metricType"] = req.MetricType
+		}
+		if len(req.Params) > 0 {
+			searchParams["params"] = req.Params
+		}
+		body["searchParams"] = searchParams
+	}
+
+	var rows []Row
+	if err := c.perform(ctx, "/entities/search", body, &rows); err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(rows))
+	for i, row := range rows {
+		hit := Hit{Fields: Row{}}
+		for key, value := range row {
+			switch key {
+			case "distance":
+				if number, ok := value.(json.Number); ok {
+					distance, _ := number.Float64()
+					hit.Distance = float32(distance)
+				}
+			case "id":
+				hit.ID, _ = row.Int64(key)
+			default:
+				hit.Fields[key] = value
+			}
+		}
+		hits[i] = hit
+	}
+	return hits, nil
+}
@@ -0,0 +1,186 @@
+package milvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gin-admin-pro/plugin/vectorstore"
+)
+
+// 向量存储创建集合时的索引参数
+const (
+	hnswM              = 16
+	hnswEfConstruction = 200
+	ivfNlist           = 128
+)
+
+// VectorStore 基于 Milvus 的向量存储，实现 vectorstore.VectorStore
+type VectorStore struct {
+	client *Client
+}
+
+var _ vectorstore.VectorStore = (*VectorStore)(nil)
+
+// VectorStore 创建向量存储
+func (c *Client) VectorStore() *VectorStore {
+	return &VectorStore{client: c}
+}
+
+// CreateCollection 创建集合和向量索引并加载，集合已存在时不修改
+func (s *VectorStore) CreateCollection(ctx context.Context, name string, schema *vectorstore.Schema) error {
+	if err := vectorstore.ValidateName(name); err != nil {
+		return err
+	}
+	if err := schema.Normalize(); err != nil {
+		return err
+	}
+	exists, err := s.client.HasCollection(ctx, name)
+	if err != nil || exists {
+		return err
+	}
+
+	fields := []Field{
+		{Name: vectorstore.IDField, DataType: DataTypeInt64, IsPrimary: true},
+		{Name: vectorstore.VectorField, DataType: DataTypeFloatVector, Dim: schema.Dimension},
+	}
+	for _, field := range schema.Fields {
+		fields = append(fields, Field{Name: field.Name, DataType: DataType(field.Type), MaxLength: field.MaxLength})
+	}
+
+	index := HNSWIndex(vectorstore.VectorField, MetricType(schema.Metric), hnswM, hnswEfConstruction)
+	if schema.Index == vectorstore.IndexIVFFlat {
+		index = IVFFlatIndex(vectorstore.VectorField, MetricType(schema.Metric), ivfNlist)
+	}
+	return s.client.CreateCollection(ctx, &CollectionSchema{Name: name, Fields: fields}, index)
+}
+
+// DropCollection 删除集合，集合不存在时不报错
+func (s *VectorStore) DropCollection(ctx context.Context, name string) error {
+	err := s.client.DropCollection(ctx, name)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// HasCollection 集合是否存在
+func (s *VectorStore) HasCollection(ctx context.Context, name string) (bool, error) {
+	return s.client.HasCollection(ctx, name)
+}
+
+// Upsert 写入记录，ID 已存在时覆盖
+func (s *VectorStore) Upsert(ctx context.Context, collection string, records []vectorstore.Record) error {
+	rows := make([]Row, len(records))
+	for i, record := range records {
+		row := make(Row, len(record.Fields)+2)
+		for key, value := range record.Fields {
+			row[key] = value
+		}
+		row[vectorstore.IDField] = record.ID
+		row[vectorstore.VectorField] = record.Vector
+		rows[i] = row
+	}
+	_, err := s.client.Upsert(ctx, collection, rows)
+	return err
+}
+
+// Search 检索与向量最相近的 TopK 条记录
+func (s *VectorStore) Search(ctx context.Context, collection string, req *vectorstore.SearchRequest) ([]vectorstore.Hit, error) {
+	filter, err := FilterExpr(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := s.client.Search(ctx, collection, &SearchRequest{
+		Vector:       req.Vector,
+		AnnsField:    vectorstore.VectorField,
+		Filter:       filter,
+		Limit:        req.TopK,
+		OutputFields: req.OutputFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]vectorstore.Hit, len(hits))
+	for i, hit := range hits {
+		results[i] = vectorstore.Hit{ID: hit.ID, Score: hit.Distance, Fields: hit.Fields}
+	}
+	return results, nil
+}
+
+// Delete 按 ID 删除记录
+func (s *VectorStore) Delete(ctx context.Context, collection string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.client.Delete(ctx, collection, IDsExpr(vectorstore.IDField, ids))
+}
+
+// DeleteByFilter 删除满足标量字段条件的记录
+func (s *VectorStore) DeleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
+	if len(filter) == 0 {
+		return vectorstore.ErrEmptyFilter
+	}
+	expr, err := FilterExpr(filter)
+	if err != nil {
+		return err
+	}
+	return s.client.Delete(ctx, collection, expr)
+}
+
+// FilterExpr 将字段等值条件转换为过滤表达式，值为切片时使用 in，多个条件以 and 连接
+func FilterExpr(filter map[string]interface{}) (string, error) {
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		if err := vectorstore.ValidateName(name); err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	conditions := make([]string, 0, len(names))
+	for _, name := range names {
+		value := reflect.ValueOf(filter[name])
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			items := make([]string, value.Len())
+			for i := range items {
+				literal, err := exprLiteral(value.Index(i).Interface())
+				if err != nil {
+					return "", err
+				}
+				items[i] = literal
+			}
+			conditions = append(conditions, fmt.Sprintf("%s in [%s]", name, strings.Join(items, ", ")))
+			continue
+		}
+		literal, err := exprLiteral(filter[name])
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, fmt.Sprintf("%s == %s", name, literal))
+	}
+	return strings.Join(conditions, " and "), nil
+}
+
+// exprLiteral 表达式中的常量，字符串加引号并转义
+func exprLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("milvus: unsupported filter value %T", value)
+}
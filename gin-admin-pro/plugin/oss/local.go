@@ -51,17 +51,13 @@ func (ls *LocalStorage) UploadFile(key string, reader io.Reader, size int64, con
 	}
 
 	// 生成文件路径
-	datePath := time.Now().Format("2006/01/02")
-	fullDir := filepath.Join(ls.config.Path, datePath)
+	fullPath := ls.objectPath(key)
 
 	// 创建目录
-	if err := os.MkdirAll(fullDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return "", fmt.Errorf("创建文件目录失败: %v", err)
 	}
 
-	// 完整文件路径
-	fullPath := filepath.Join(fullDir, key)
-
 	// 创建文件
 	file, err := os.Create(fullPath)
 	if err != nil {
@@ -94,12 +90,29 @@ func (ls *LocalStorage) DeleteFile(key string) error {
 	return os.Remove(fullPath)
 }
 
+// GetFile 读取文件内容
+func (ls *LocalStorage) GetFile(key string) (io.ReadCloser, error) {
+	return os.Open(ls.getFullPath(key))
+}
+
 // GetFileURL 获取文件访问URL
 func (ls *LocalStorage) GetFileURL(key string) string {
+	if strings.Contains(key, "/") {
+		return "/uploads/" + key
+	}
 	datePath := time.Now().Format("2006/01/02")
 	return fmt.Sprintf("/uploads/%s/%s", datePath, key)
 }
 
+// objectPath 获取上传文件的保存路径：带目录的 key 按原路径保存，便于按 key 读取和删除；
+// 只有文件名的 key 保存在当天的日期目录下
+func (ls *LocalStorage) objectPath(key string) string {
+	if strings.Contains(key, "/") {
+		return filepath.Join(ls.config.Path, filepath.FromSlash(key))
+	}
+	return filepath.Join(ls.config.Path, time.Now().Format("2006/01/02"), key)
+}
+
 // IsExists 检查文件是否存在
 func (ls *LocalStorage) IsExists(key string) (bool, error) {
 	fullPath := ls.getFullPath(key)
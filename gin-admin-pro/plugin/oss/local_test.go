@@ -0,0 +1,66 @@
+package oss
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-admin-pro/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLocalStorage 以临时目录为存储根目录创建本地存储
+func newTestLocalStorage(t *testing.T) (*LocalStorage, string) {
+	root := t.TempDir()
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{Upload: config.UploadConfig{MaxSize: 1024 * 1024, Path: root}}
+	t.Cleanup(func() { config.GlobalConfig = previous })
+
+	storage, err := NewLocalStorage()
+	require.NoError(t, err)
+	return storage, root
+}
+
+func TestLocalStorageNestedKey(t *testing.T) {
+	storage, root := newTestLocalStorage(t)
+	content := "# 使用手册\n\n重置密码请联系管理员。"
+
+	// 知识库文档的 key 带目录，按原路径保存
+	key := "knowledge/1/20250120103000_manual.md"
+	url, err := storage.UploadFile(key, strings.NewReader(content), int64(len(content)), "text/markdown")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/"+key, url)
+
+	data, err := os.ReadFile(filepath.Join(root, "knowledge", "1", "20250120103000_manual.md"))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	exists, err := storage.IsExists(key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, storage.DeleteFile(key))
+	exists, err = storage.IsExists(key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLocalStorageFilenameKey(t *testing.T) {
+	storage, root := newTestLocalStorage(t)
+
+	// 只有文件名的 key 保存在当天的日期目录下
+	datePath := time.Now().Format("2006/01/02")
+	url, err := storage.UploadFile("avatar.png", strings.NewReader("png"), 3, "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/"+datePath+"/avatar.png", url)
+	assert.FileExists(t, filepath.Join(root, filepath.FromSlash(datePath), "avatar.png"))
+
+	// 大小不一致时不保留文件
+	_, err = storage.UploadFile("broken.png", strings.NewReader("png"), 4, "image/png")
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(root, filepath.FromSlash(datePath), "broken.png"))
+}
@@ -40,6 +40,11 @@ func (ms *MinIOStorage) DeleteFile(key string) error {
 	return fmt.Errorf("MinIO存储暂未实现，请使用本地存储")
 }
 
+// GetFile 读取文件内容
+func (ms *MinIOStorage) GetFile(key string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("MinIO存储暂未实现，请使用本地存储")
+}
+
 // GetFileURL 获取文件访问URL
 func (ms *MinIOStorage) GetFileURL(key string) string {
 	return "/minio-not-implemented"
@@ -10,6 +10,9 @@ type OSSInterface interface {
 	// DeleteFile 删除文件
 	DeleteFile(key string) error
 
+	// GetFile 读取文件内容，调用方负责关闭
+	GetFile(key string) (io.ReadCloser, error)
+
 	// GetFileURL 获取文件访问URL
 	GetFileURL(key string) string
 
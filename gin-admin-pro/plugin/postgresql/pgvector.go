@@ -1,9 +1,14 @@
 package postgresql
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Vector 向量类型
@@ -27,6 +32,52 @@ func (v Vector) String() string {
 	return sb.String()
 }
 
+// Value 实现 driver.Valuer，以 pgvector 文本格式 [1,2,3] 写入 vector 列
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// Scan 实现 sql.Scanner，读取 pgvector 文本格式的 vector 列
+func (v *Vector) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	default:
+		return fmt.Errorf("cannot scan %T into Vector", src)
+	}
+
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return fmt.Errorf("invalid vector: %q", text)
+	}
+	text = strings.TrimSpace(text[1 : len(text)-1])
+	if text == "" {
+		*v = Vector{}
+		return nil
+	}
+
+	parts := strings.Split(text, ",")
+	vec := make(Vector, len(parts))
+	for i, part := range parts {
+		val, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return fmt.Errorf("invalid vector element %q: %w", part, err)
+		}
+		vec[i] = float32(val)
+	}
+	*v = vec
+	return nil
+}
+
 // Size 返回向量维度
 func (v Vector) Size() int {
 	return len(v)
@@ -150,15 +201,15 @@ func (v *VectorFunc) CreateVectorIndex(table, column string, dimension int, inde
 	switch strings.ToLower(indexType) {
 	case "ivfflat":
 		// IVFFlat 索引
-		indexSQL = fmt.Sprintf("CREATE INDEX %s ON %s USING ivfflat (%s vector_cosine_ops) WITH (lists = 100)",
+		indexSQL = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING ivfflat (%s vector_cosine_ops) WITH (lists = 100)",
 			indexName, table, column)
 	case "hnsw":
 		// HNSW 索引
-		indexSQL = fmt.Sprintf("CREATE INDEX %s ON %s USING hnsw (%s vector_cosine_ops)",
+		indexSQL = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (%s vector_cosine_ops)",
 			indexName, table, column)
 	default:
 		// 默认使用 IVFFlat
-		indexSQL = fmt.Sprintf("CREATE INDEX %s ON %s USING ivfflat (%s vector_cosine_ops) WITH (lists = 100)",
+		indexSQL = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING ivfflat (%s vector_cosine_ops) WITH (lists = 100)",
 			indexName, table, column)
 	}
 
@@ -184,3 +235,17 @@ func (v *VectorFunc) SimilaritySearch(table, column string, queryVec Vector, lim
 	return fmt.Sprintf("SELECT *, %s as distance FROM %s ORDER BY %s %s LIMIT %d",
 		distanceCol, table, distanceCol, orderBy, limit)
 }
+
+// CosineSimilarityExpr 与参数向量的余弦相似度表达式，向量通过 ? 占位符传入
+func (v *VectorFunc) CosineSimilarityExpr(column string) string {
+	return fmt.Sprintf("1 - (%s <=> ?)", column)
+}
+
+// NearestNeighbors 按余弦距离升序查询与 queryVec 最相近的 limit 条记录，
+// db 中可预先设置表、查询列和筛选条件，向量以参数传入
+func (v *VectorFunc) NearestNeighbors(db *gorm.DB, column string, queryVec Vector, limit int) *gorm.DB {
+	return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:  fmt.Sprintf("%s <=> ?", column),
+		Vars: []interface{}{queryVec},
+	}}).Limit(limit)
+}
@@ -61,6 +61,19 @@ func TestVectorOperations(t *testing.T) {
 		assert.Equal(t, float32(1.0), similarity)
 	})
 
+	t.Run("Vector value and scan", func(t *testing.T) {
+		value, err := Vector{1.5, -2.0}.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, "[1.500000,-2.000000]", value)
+
+		var vec Vector
+		assert.NoError(t, vec.Scan([]byte("[1.5, -2,3e-1]")))
+		assert.Equal(t, Vector{1.5, -2.0, 0.3}, vec)
+		assert.NoError(t, vec.Scan("[]"))
+		assert.Empty(t, vec)
+		assert.Error(t, vec.Scan("1,2"))
+	})
+
 	t.Run("Euclidean distance", func(t *testing.T) {
 		vec1 := Vector{0.0, 0.0}
 		vec2 := Vector{3.0, 4.0}
@@ -0,0 +1,346 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gin-admin-pro/plugin/vectorstore"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 向量存储创建集合时的索引参数
+const (
+	hnswM              = 16
+	hnswEfConstruction = 64
+	ivfLists           = 100
+)
+
+// pgvector 各度量对应的操作符类和距离操作符
+var metricOps = map[vectorstore.Metric]struct {
+	opclass  string
+	operator string
+}{
+	vectorstore.MetricCosine: {"vector_cosine_ops", "<=>"},
+	vectorstore.MetricL2:     {"vector_l2_ops", "<->"},
+	vectorstore.MetricIP:     {"vector_ip_ops", "<#>"},
+}
+
+// VectorStore 基于 pgvector 的向量存储，实现 vectorstore.VectorStore。
+// 每个集合对应一张表，包含 id、vector 和标量字段列
+type VectorStore struct {
+	db *gorm.DB
+	// metrics 集合名称到度量的缓存，由向量索引的操作符类得出
+	metrics sync.Map
+}
+
+var _ vectorstore.VectorStore = (*VectorStore)(nil)
+
+// NewVectorStore 创建向量存储
+func NewVectorStore(db *gorm.DB) *VectorStore {
+	return &VectorStore{db: db}
+}
+
+// VectorStore 创建向量存储
+func (c *Client) VectorStore() *VectorStore {
+	return NewVectorStore(c.GetDB())
+}
+
+// CreateCollection 创建表和向量索引，表已存在时不修改
+func (s *VectorStore) CreateCollection(ctx context.Context, name string, schema *vectorstore.Schema) error {
+	if err := vectorstore.ValidateName(name); err != nil {
+		return err
+	}
+	if err := schema.Normalize(); err != nil {
+		return err
+	}
+
+	columns := []string{
+		fmt.Sprintf("%s bigint PRIMARY KEY", quoteIdent(vectorstore.IDField)),
+		fmt.Sprintf("%s vector(%d) NOT NULL", quoteIdent(vectorstore.VectorField), schema.Dimension),
+	}
+	for _, field := range schema.Fields {
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(field.Name), columnType(field)))
+	}
+
+	method := fmt.Sprintf("hnsw (%s %s) WITH (m = %d, ef_construction = %d)",
+		quoteIdent(vectorstore.VectorField), metricOps[schema.Metric].opclass, hnswM, hnswEfConstruction)
+	if schema.Index == vectorstore.IndexIVFFlat {
+		method = fmt.Sprintf("ivfflat (%s %s) WITH (lists = %d)",
+			quoteIdent(vectorstore.VectorField), metricOps[schema.Metric].opclass, ivfLists)
+	}
+
+	// 两条语句均可重复执行，中途失败时重新调用即可补齐索引
+	db := s.db.WithContext(ctx)
+	if err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdent(name), strings.Join(columns, ", "))).Error; err != nil {
+		return err
+	}
+	err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING %s",
+		quoteIdent(vectorIndexName(name)), quoteIdent(name), method)).Error
+	if err != nil {
+		return err
+	}
+	s.metrics.Delete(name)
+	return nil
+}
+
+// DropCollection 删除表，表不存在时不报错
+func (s *VectorStore) DropCollection(ctx context.Context, name string) error {
+	if err := vectorstore.ValidateName(name); err != nil {
+		return err
+	}
+	s.metrics.Delete(name)
+	return s.db.WithContext(ctx).Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdent(name))).Error
+}
+
+// HasCollection 表是否存在
+func (s *VectorStore) HasCollection(ctx context.Context, name string) (bool, error) {
+	if err := vectorstore.ValidateName(name); err != nil {
+		return false, err
+	}
+	return s.db.WithContext(ctx).Migrator().HasTable(name), nil
+}
+
+// Upsert 写入记录，ID 已存在时覆盖整行，记录中缺少的标量字段置为 NULL
+func (s *VectorStore) Upsert(ctx context.Context, collection string, records []vectorstore.Record) error {
+	if err := vectorstore.ValidateName(collection); err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	columns := map[string]bool{}
+	for _, record := range records {
+		for key := range record.Fields {
+			if err := vectorstore.ValidateName(key); err != nil {
+				return err
+			}
+			if key == vectorstore.IDField || key == vectorstore.VectorField {
+				return fmt.Errorf("%w: field %q is reserved", vectorstore.ErrInvalidSchema, key)
+			}
+			columns[key] = true
+		}
+	}
+	updates := []string{vectorstore.VectorField}
+	for column := range columns {
+		updates = append(updates, column)
+	}
+	sort.Strings(updates[1:])
+
+	rows := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		row := map[string]interface{}{
+			vectorstore.IDField:     record.ID,
+			vectorstore.VectorField: Vector(record.Vector),
+		}
+		for column := range columns {
+			value, err := columnValue(record.Fields[column])
+			if err != nil {
+				return fmt.Errorf("field %q: %w", column, err)
+			}
+			row[column] = value
+		}
+		rows[i] = row
+	}
+
+	return s.db.WithContext(ctx).Table(collection).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: vectorstore.IDField}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).Create(&rows).Error
+}
+
+// Search 按向量索引的度量检索与向量最相近的 TopK 条记录
+func (s *VectorStore) Search(ctx context.Context, collection string, req *vectorstore.SearchRequest) ([]vectorstore.Hit, error) {
+	if err := vectorstore.ValidateName(collection); err != nil {
+		return nil, err
+	}
+	if len(req.Vector) == 0 {
+		return nil, fmt.Errorf("postgresql: search vector is empty")
+	}
+
+	db := s.db.WithContext(ctx)
+	metric := s.metric(db, collection)
+	distance := fmt.Sprintf("%s %s ?", quoteIdent(vectorstore.VectorField), metricOps[metric].operator)
+	score := distance
+	switch metric {
+	case vectorstore.MetricCosine:
+		score = fmt.Sprintf("1 - (%s)", distance)
+	case vectorstore.MetricIP:
+		// <#> 返回负内积
+		score = fmt.Sprintf("-(%s)", distance)
+	}
+
+	selects := []string{quoteIdent(vectorstore.IDField), fmt.Sprintf("%s AS %s", score, quoteIdent("_score"))}
+	for _, field := range req.OutputFields {
+		if err := vectorstore.ValidateName(field); err != nil {
+			return nil, err
+		}
+		selects = append(selects, quoteIdent(field))
+	}
+
+	vector := Vector(req.Vector)
+	query := db.Table(collection).Select(strings.Join(selects, ", "), vector)
+	query, err := applyFilter(query, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+	err = query.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: distance, Vars: []interface{}{vector}}}).
+		Limit(max(req.TopK, 1)).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]vectorstore.Hit, len(rows))
+	for i, row := range rows {
+		hit := vectorstore.Hit{Fields: map[string]interface{}{}}
+		for key, value := range row {
+			switch key {
+			case vectorstore.IDField:
+				hit.ID, _ = value.(int64)
+			case "_score":
+				hit.Score = float32(toFloat(value))
+			default:
+				hit.Fields[key] = value
+			}
+		}
+		hits[i] = hit
+	}
+	return hits, nil
+}
+
+// Delete 按 ID 删除记录
+func (s *VectorStore) Delete(ctx context.Context, collection string, ids []int64) error {
+	if err := vectorstore.ValidateName(collection); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).
+		Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN ?", quoteIdent(collection), quoteIdent(vectorstore.IDField)), ids).
+		Error
+}
+
+// DeleteByFilter 删除满足标量字段条件的记录
+func (s *VectorStore) DeleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
+	if err := vectorstore.ValidateName(collection); err != nil {
+		return err
+	}
+	if len(filter) == 0 {
+		return vectorstore.ErrEmptyFilter
+	}
+	query, err := applyFilter(s.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	return query.Delete(nil).Error
+}
+
+// metric 由向量索引的操作符类得出集合的度量，没有索引时按余弦相似度检索
+func (s *VectorStore) metric(db *gorm.DB, collection string) vectorstore.Metric {
+	if metric, ok := s.metrics.Load(collection); ok {
+		return metric.(vectorstore.Metric)
+	}
+
+	var indexDef string
+	err := db.Raw("SELECT indexdef FROM pg_indexes WHERE tablename = ? AND indexname = ?",
+		collection, vectorIndexName(collection)).Scan(&indexDef).Error
+	if err != nil || indexDef == "" {
+		return vectorstore.MetricCosine
+	}
+
+	metric := vectorstore.MetricCosine
+	for m, ops := range metricOps {
+		if strings.Contains(indexDef, ops.opclass) {
+			metric = m
+		}
+	}
+	s.metrics.Store(collection, metric)
+	return metric
+}
+
+// applyFilter 添加标量字段的等值条件，值为切片时使用 IN
+func applyFilter(db *gorm.DB, filter map[string]interface{}) (*gorm.DB, error) {
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		if err := vectorstore.ValidateName(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := filter[name]
+		kind := reflect.ValueOf(value).Kind()
+		if kind == reflect.Slice || kind == reflect.Array {
+			db = db.Where(fmt.Sprintf("%s IN ?", quoteIdent(name)), value)
+			continue
+		}
+		db = db.Where(fmt.Sprintf("%s = ?", quoteIdent(name)), value)
+	}
+	return db, nil
+}
+
+// columnType 标量字段对应的列类型
+func columnType(field vectorstore.Field) string {
+	switch field.Type {
+	case vectorstore.FieldInt64:
+		return "bigint"
+	case vectorstore.FieldDouble:
+		return "double precision"
+	case vectorstore.FieldBool:
+		return "boolean"
+	case vectorstore.FieldVarChar:
+		return fmt.Sprintf("varchar(%d)", field.MaxLength)
+	default:
+		return "jsonb"
+	}
+}
+
+// columnValue 标量字段写入的值，对象和数组序列化为 JSON
+func columnValue(value interface{}) (interface{}, error) {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return value, nil
+}
+
+// toFloat 读取数值列，非数值返回 0
+func toFloat(value interface{}) float64 {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	case v.CanFloat():
+		return v.Float()
+	}
+	return 0
+}
+
+// vectorIndexName 集合向量索引的名称
+func vectorIndexName(collection string) string {
+	return fmt.Sprintf("idx_%s_%s", collection, vectorstore.VectorField)
+}
+
+// quoteIdent 引用已校验的标识符
+func quoteIdent(name string) string {
+	return `"` + name + `"`
+}
@@ -0,0 +1,120 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-admin-pro/plugin/vectorstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 GORM 生成的 SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// newDryRunStore 不连接数据库的向量存储，只生成 SQL
+func newDryRunStore(t *testing.T) (*VectorStore, *sqlRecorder) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	require.NoError(t, err)
+	return NewVectorStore(db), recorder
+}
+
+func TestVectorStoreCreateCollection(t *testing.T) {
+	store, recorder := newDryRunStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateCollection(ctx, "docs", &vectorstore.Schema{
+		Dimension: 3,
+		Metric:    vectorstore.MetricL2,
+		Fields: []vectorstore.Field{
+			{Name: "title", Type: vectorstore.FieldVarChar, MaxLength: 200},
+			{Name: "price", Type: vectorstore.FieldDouble},
+			{Name: "meta", Type: vectorstore.FieldJSON},
+		},
+	}))
+	assert.Contains(t, recorder.statements, `CREATE TABLE IF NOT EXISTS "docs" ("id" bigint PRIMARY KEY, "vector" vector(3) NOT NULL, "title" varchar(200), "price" double precision, "meta" jsonb)`)
+	assert.Contains(t, recorder.statements, `CREATE INDEX IF NOT EXISTS "idx_docs_vector" ON "docs" USING hnsw ("vector" vector_l2_ops) WITH (m = 16, ef_construction = 64)`)
+
+	recorder.statements = nil
+	require.NoError(t, store.CreateCollection(ctx, "docs", &vectorstore.Schema{Dimension: 3, Index: vectorstore.IndexIVFFlat}))
+	assert.Contains(t, recorder.statements, `CREATE INDEX IF NOT EXISTS "idx_docs_vector" ON "docs" USING ivfflat ("vector" vector_cosine_ops) WITH (lists = 100)`)
+
+	assert.ErrorIs(t, store.CreateCollection(ctx, `docs"; DROP TABLE users; --`, &vectorstore.Schema{Dimension: 3}), vectorstore.ErrInvalidName)
+	assert.ErrorIs(t, store.CreateCollection(ctx, "docs", &vectorstore.Schema{}), vectorstore.ErrInvalidSchema)
+}
+
+func TestVectorStoreWrite(t *testing.T) {
+	store, recorder := newDryRunStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Upsert(ctx, "docs", []vectorstore.Record{
+		{ID: 1, Vector: []float32{1, 2}, Fields: map[string]interface{}{"title": "a", "meta": map[string]int{"x": 1}}},
+		{ID: 2, Vector: []float32{3, 4}, Fields: map[string]interface{}{"title": "b"}},
+	}))
+	require.Len(t, recorder.statements, 1)
+	assert.Equal(t, `INSERT INTO "docs" ("id","meta","title","vector") VALUES (1,'{"x":1}','a','[1.000000,2.000000]'),(2,NULL,'b','[3.000000,4.000000]') ON CONFLICT ("id") DO UPDATE SET "vector"="excluded"."vector","meta"="excluded"."meta","title"="excluded"."title"`,
+		recorder.statements[0])
+
+	assert.ErrorIs(t, store.Upsert(ctx, "docs", []vectorstore.Record{{ID: 1, Fields: map[string]interface{}{"id": 2}}}), vectorstore.ErrInvalidSchema)
+
+	recorder.statements = nil
+	require.NoError(t, store.Delete(ctx, "docs", []int64{1, 2}))
+	require.NoError(t, store.Delete(ctx, "docs", nil))
+	require.NoError(t, store.DeleteByFilter(ctx, "docs", map[string]interface{}{"doc_id": []int64{3, 4}, "lang": "zh"}))
+	require.NoError(t, store.DropCollection(ctx, "docs"))
+	assert.Equal(t, []string{
+		`DELETE FROM "docs" WHERE "id" IN (1,2)`,
+		`DELETE FROM "docs" WHERE "doc_id" IN (3,4) AND "lang" = 'zh'`,
+		`DROP TABLE IF EXISTS "docs"`,
+	}, recorder.statements)
+	assert.ErrorIs(t, store.DeleteByFilter(ctx, "docs", nil), vectorstore.ErrEmptyFilter)
+}
+
+func TestVectorStoreSearch(t *testing.T) {
+	store, recorder := newDryRunStore(t)
+	ctx := context.Background()
+	req := &vectorstore.SearchRequest{
+		Vector:       []float32{1, 2},
+		TopK:         5,
+		Filter:       map[string]interface{}{"category": "news", "year": []int{2023, 2024}},
+		OutputFields: []string{"title"},
+	}
+
+	// 没有索引信息时按余弦相似度检索
+	_, err := store.Search(ctx, "docs", req)
+	require.NoError(t, err)
+	last := recorder.statements[len(recorder.statements)-1]
+	assert.Equal(t, `SELECT "id", 1 - ("vector" <=> '[1.000000,2.000000]') AS "_score", "title" FROM "docs" WHERE "category" = 'news' AND "year" IN (2023,2024) ORDER BY "vector" <=> '[1.000000,2.000000]' LIMIT 5`, last)
+
+	store.metrics.Store("products", vectorstore.MetricIP)
+	_, err = store.Search(ctx, "products", &vectorstore.SearchRequest{Vector: []float32{1, 2}})
+	require.NoError(t, err)
+	last = recorder.statements[len(recorder.statements)-1]
+	assert.True(t, strings.HasPrefix(last, `SELECT "id", -("vector" <#> '[1.000000,2.000000]') AS "_score" FROM "products" ORDER BY "vector" <#>`), last)
+	assert.True(t, strings.HasSuffix(last, "LIMIT 1"), last)
+
+	_, err = store.Search(ctx, "docs", &vectorstore.SearchRequest{Vector: []float32{1}, Filter: map[string]interface{}{"a = 1 or 1": 1}})
+	assert.ErrorIs(t, err, vectorstore.ErrInvalidName)
+	_, err = store.Search(ctx, "docs", &vectorstore.SearchRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package prompt
+
+import "time"
+
+// Variable 提示词变量，内容中以 {{name}} 引用
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Default 未传值时使用的默认值
+	Default string `json:"default,omitempty"`
+	// Required 必填变量未传值且无默认值时渲染失败
+	Required bool `json:"required,omitempty"`
+}
+
+// Template 提示词模板，Content、Variables 为当前生效版本的内容
+type Template struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"size:500" json:"description"`
+	Content     string     `gorm:"type:text" json:"content"`
+	Variables   []Variable `gorm:"type:text;serializer:json" json:"variables"`
+	// Version 当前生效的版本号
+	Version   int       `gorm:"default:1" json:"version"`
+	Status    int       `gorm:"default:1" json:"status" dict:"system_status"` // 0-禁用 1-启用
+	CreateBy  uint      `json:"createBy"`
+	UpdateBy  uint      `json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
+}
+
+// TemplateVersion 提示词模板的历史版本，发布后不可修改
+type TemplateVersion struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	TemplateID uint       `gorm:"not null;uniqueIndex:uk_ai_prompt_version,priority:1" json:"templateId"`
+	Version    int        `gorm:"not null;uniqueIndex:uk_ai_prompt_version,priority:2" json:"version"`
+	Content    string     `gorm:"type:text" json:"content"`
+	Variables  []Variable `gorm:"type:text;serializer:json" json:"variables"`
+	Remark     string     `gorm:"size:500" json:"remark"`
+	CreateBy   uint       `json:"createBy"`
+	CreatedAt  time.Time  `json:"createTime"`
+}
+
+// Assistant 助手：人设提示词、模型、工具和知识库的组合，对话时按助手ID引用
+type Assistant struct {
+	ID          uint   `gorm:"primarykey" json:"id"`
+	Name        string `gorm:"size:100;not null" json:"name"`
+	Description string `gorm:"size:500" json:"description"`
+	Avatar      string `gorm:"size:512" json:"avatar"`
+	TemplateID  uint   `gorm:"not null;index" json:"templateId"`
+	// TemplateVersion 固定使用的模板版本，0 表示跟随模板当前版本
+	TemplateVersion int `json:"templateVersion"`
+	// Variables 渲染模板时的变量值，对话请求中的同名变量优先
+	Variables map[string]string `gorm:"type:text;serializer:json" json:"variables"`
+	// Model 为空时使用默认模型
+	Model       string  `gorm:"size:100" json:"model"`
+	Temperature float64 `json:"temperature"`
+	MaxTokens   int     `json:"maxTokens"`
+	// Tools 可调用的工具名称，为空时不提供工具；调用时仍按用户权限过滤
+	Tools []string `gorm:"type:text;serializer:json" json:"tools"`
+	// KnowledgeBaseIDs 对话时检索的知识库
+	KnowledgeBaseIDs []uint    `gorm:"type:text;serializer:json" json:"knowledgeBaseIds"`
+	Status           int       `gorm:"default:1" json:"status" dict:"system_status"` // 0-禁用 1-启用
+	CreateBy         uint      `json:"createBy"`
+	UpdateBy         uint      `json:"updateBy"`
+	CreatedAt        time.Time `json:"createTime"`
+	UpdatedAt        time.Time `json:"updateTime"`
+}
+
+// ResolvedAssistant 对话使用的助手配置，SystemPrompt 为渲染后的系统提示词
+type ResolvedAssistant struct {
+	*Assistant
+	SystemPrompt string `json:"systemPrompt"`
+	// Version 实际使用的模板版本
+	Version int `json:"version"`
+}
+
+// TableName 设置表名
+func (Template) TableName() string {
+	return "ai_prompt_template"
+}
+
+func (TemplateVersion) TableName() string {
+	return "ai_prompt_template_version"
+}
+
+func (Assistant) TableName() string {
+	return "ai_assistant"
+}
@@ -0,0 +1,75 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrMissingVariable 必填变量未传值
+var ErrMissingVariable = errors.New("missing prompt variable")
+
+// variablePattern 匹配 {{name}}，变量名由字母、数字、下划线和点组成，两侧可有空格
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\}\}`)
+
+// ExtractVariables 按出现顺序返回内容中引用的变量名，已去重
+func ExtractVariables(content string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range variablePattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// MergeVariables 将内容中引用但未定义的变量补充为可选变量，并去除未引用的定义
+func MergeVariables(content string, variables []Variable) []Variable {
+	defined := make(map[string]Variable, len(variables))
+	for _, variable := range variables {
+		defined[variable.Name] = variable
+	}
+	names := ExtractVariables(content)
+	merged := make([]Variable, 0, len(names))
+	for _, name := range names {
+		variable, ok := defined[name]
+		if !ok {
+			variable = Variable{Name: name}
+		}
+		merged = append(merged, variable)
+	}
+	return merged
+}
+
+// Render 替换内容中的变量：依次使用传入值、默认值，必填变量均未提供时返回 ErrMissingVariable，
+// 未定义的变量替换为空
+func Render(content string, variables []Variable, values map[string]string) (string, error) {
+	defined := make(map[string]Variable, len(variables))
+	for _, variable := range variables {
+		defined[variable.Name] = variable
+	}
+
+	var missing []string
+	rendered := variablePattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := variablePattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok && value != "" {
+			return value
+		}
+		variable := defined[name]
+		if variable.Default != "" {
+			return variable.Default
+		}
+		if variable.Required && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingVariable, strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
@@ -0,0 +1,42 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractVariables(t *testing.T) {
+	content := "你是{{ company }}的{{role}}，用{{language}}回答。{{role}}不回答与{{company}}无关的问题。{{ 1bad }}"
+	assert.Equal(t, []string{"company", "role", "language"}, ExtractVariables(content))
+
+	// 合并后只保留内容中引用的变量，未定义的补充为可选变量
+	merged := MergeVariables(content, []Variable{
+		{Name: "role", Default: "客服", Required: true},
+		{Name: "unused"},
+	})
+	assert.Equal(t, []Variable{{Name: "company"}, {Name: "role", Default: "客服", Required: true}, {Name: "language"}}, merged)
+}
+
+func TestRender(t *testing.T) {
+	content := "你是{{company}}的{{ role }}，用{{language}}回答。"
+	variables := []Variable{
+		{Name: "company", Required: true},
+		{Name: "role", Default: "客服"},
+		{Name: "language", Default: "中文"},
+	}
+
+	rendered, err := Render(content, variables, map[string]string{"company": "示例科技", "language": "英文"})
+	require.NoError(t, err)
+	assert.Equal(t, "你是示例科技的客服，用英文回答。", rendered)
+
+	// 未定义的变量替换为空
+	rendered, err = Render("{{company}}{{unknown}}", variables, map[string]string{"company": "A"})
+	require.NoError(t, err)
+	assert.Equal(t, "A", rendered)
+
+	_, err = Render(content+"{{company}}", variables, map[string]string{"company": ""})
+	assert.ErrorIs(t, err, ErrMissingVariable)
+	assert.EqualError(t, err, "missing prompt variable: company")
+}
@@ -0,0 +1,332 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrTemplateInUse 模板被助手引用，不能删除
+	ErrTemplateInUse = errors.New("prompt template is used by assistants")
+	// ErrVersionNotFound 模板版本不存在
+	ErrVersionNotFound = errors.New("prompt template version not found")
+	// ErrAssistantDisabled 助手已禁用
+	ErrAssistantDisabled = errors.New("assistant is disabled")
+)
+
+// Service 提示词服务，管理提示词模板及其版本和助手
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建提示词服务实例
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Migrate 创建模板、版本和助手表
+func (s *Service) Migrate() error {
+	return s.db.AutoMigrate(&Template{}, &TemplateVersion{}, &Assistant{})
+}
+
+// GetTemplates 分页获取模板，status 为 -1 时不按状态筛选
+func (s *Service) GetTemplates(page, pageSize int, name string, status int) ([]Template, int64, error) {
+	var (
+		list  []Template
+		total int64
+	)
+	query := s.db.Model(&Template{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	if status != -1 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// GetTemplate 根据ID获取模板
+func (s *Service) GetTemplate(id uint) (*Template, error) {
+	var template Template
+	if err := s.db.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateTemplate 创建模板并发布第 1 个版本，变量定义按内容中引用的变量补全
+func (s *Service) CreateTemplate(template *Template, remark string) error {
+	template.Variables = MergeVariables(template.Content, template.Variables)
+	template.Version = 1
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := createWithStatus(tx, template, &template.Status); err != nil {
+			return err
+		}
+		return tx.Create(&TemplateVersion{
+			TemplateID: template.ID,
+			Version:    1,
+			Content:    template.Content,
+			Variables:  template.Variables,
+			Remark:     remark,
+			CreateBy:   template.CreateBy,
+		}).Error
+	})
+}
+
+// UpdateTemplate 更新模板名称、描述和状态，内容通过发布新版本修改
+func (s *Service) UpdateTemplate(template *Template) error {
+	result := s.db.Model(&Template{}).Where("id = ?", template.ID).Updates(map[string]interface{}{
+		"name":        template.Name,
+		"description": template.Description,
+		"status":      template.Status,
+		"update_by":   template.UpdateBy,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteTemplate 删除模板及其全部版本，被助手引用时返回 ErrTemplateInUse
+func (s *Service) DeleteTemplate(id uint) error {
+	if _, err := s.GetTemplate(id); err != nil {
+		return err
+	}
+	var count int64
+	if err := s.db.Model(&Assistant{}).Where("template_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTemplateInUse
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&TemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Template{}, id).Error
+	})
+}
+
+// GetVersions 获取模板的全部版本，按版本号倒序
+func (s *Service) GetVersions(templateID uint) ([]TemplateVersion, error) {
+	var versions []TemplateVersion
+	err := s.db.Where("template_id = ?", templateID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// GetVersion 获取模板的指定版本
+func (s *Service) GetVersion(templateID uint, version int) (*TemplateVersion, error) {
+	var v TemplateVersion
+	err := s.db.Where("template_id = ? AND version = ?", templateID, version).First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// PublishVersion 发布新版本并设为当前版本，返回新版本
+func (s *Service) PublishVersion(templateID uint, content string, variables []Variable, remark string, userID uint) (*TemplateVersion, error) {
+	version := &TemplateVersion{
+		TemplateID: templateID,
+		Content:    content,
+		Variables:  MergeVariables(content, variables),
+		Remark:     remark,
+		CreateBy:   userID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定模板行，避免并发发布得到相同的版本号
+		var template Template
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, templateID).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&TemplateVersion{}).Where("template_id = ?", templateID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		return s.activate(tx, templateID, version, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// ActivateVersion 将模板当前版本切换为指定的历史版本，用于回滚
+func (s *Service) ActivateVersion(templateID uint, version int, userID uint) error {
+	if _, err := s.GetTemplate(templateID); err != nil {
+		return err
+	}
+	v, err := s.GetVersion(templateID, version)
+	if err != nil {
+		return err
+	}
+	return s.activate(s.db, templateID, v, userID)
+}
+
+// activate 将版本内容写入模板
+func (s *Service) activate(tx *gorm.DB, templateID uint, version *TemplateVersion, userID uint) error {
+	return tx.Model(&Template{ID: templateID}).Select("content", "variables", "version", "update_by").Updates(&Template{
+		Content:   version.Content,
+		Variables: version.Variables,
+		Version:   version.Version,
+		UpdateBy:  userID,
+	}).Error
+}
+
+// GetAssistants 分页获取助手，status 为 -1 时不按状态筛选
+func (s *Service) GetAssistants(page, pageSize int, name string, status int) ([]Assistant, int64, error) {
+	var (
+		list  []Assistant
+		total int64
+	)
+	query := s.db.Model(&Assistant{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	if status != -1 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// GetAssistant 根据ID获取助手
+func (s *Service) GetAssistant(id uint) (*Assistant, error) {
+	var assistant Assistant
+	if err := s.db.First(&assistant, id).Error; err != nil {
+		return nil, err
+	}
+	return &assistant, nil
+}
+
+// CreateAssistant 创建助手
+func (s *Service) CreateAssistant(assistant *Assistant) error {
+	if err := s.checkTemplate(assistant); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return createWithStatus(tx, assistant, &assistant.Status)
+	})
+}
+
+// UpdateAssistant 更新助手
+func (s *Service) UpdateAssistant(assistant *Assistant) error {
+	if _, err := s.GetAssistant(assistant.ID); err != nil {
+		return err
+	}
+	if err := s.checkTemplate(assistant); err != nil {
+		return err
+	}
+	return s.db.Model(&Assistant{ID: assistant.ID}).
+		Select("name", "description", "avatar", "template_id", "template_version", "variables", "model",
+			"temperature", "max_tokens", "tools", "knowledge_base_ids", "status", "update_by").
+		Updates(assistant).Error
+}
+
+// DeleteAssistant 删除助手
+func (s *Service) DeleteAssistant(id uint) error {
+	result := s.db.Delete(&Assistant{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResolveAssistant 获取启用的助手并渲染系统提示词，values 中的变量优先于助手设置的变量
+func (s *Service) ResolveAssistant(ctx context.Context, id uint, values map[string]string) (*ResolvedAssistant, error) {
+	var assistant Assistant
+	if err := s.db.WithContext(ctx).First(&assistant, id).Error; err != nil {
+		return nil, err
+	}
+	if assistant.Status != 1 {
+		return nil, ErrAssistantDisabled
+	}
+
+	var (
+		content   string
+		variables []Variable
+		version   int
+	)
+	if assistant.TemplateVersion > 0 {
+		v, err := s.GetVersion(assistant.TemplateID, assistant.TemplateVersion)
+		if err != nil {
+			return nil, err
+		}
+		content, variables, version = v.Content, v.Variables, v.Version
+	} else {
+		template, err := s.GetTemplate(assistant.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		content, variables, version = template.Content, template.Variables, template.Version
+	}
+
+	merged := make(map[string]string, len(assistant.Variables)+len(values))
+	for name, value := range assistant.Variables {
+		merged[name] = value
+	}
+	for name, value := range values {
+		if value != "" {
+			merged[name] = value
+		}
+	}
+	systemPrompt, err := Render(content, variables, merged)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolvedAssistant{Assistant: &assistant, SystemPrompt: systemPrompt, Version: version}, nil
+}
+
+// checkTemplate 校验助手引用的模板和版本存在
+func (s *Service) checkTemplate(assistant *Assistant) error {
+	if _, err := s.GetTemplate(assistant.TemplateID); err != nil {
+		return err
+	}
+	if assistant.TemplateVersion > 0 {
+		if _, err := s.GetVersion(assistant.TemplateID, assistant.TemplateVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createWithStatus 在事务中创建记录并保留传入的状态：status 列带默认值，GORM 创建时会把 0 替换为 1
+func createWithStatus(tx *gorm.DB, value interface{}, status *int) error {
+	want := *status
+	if err := tx.Create(value).Error; err != nil {
+		return err
+	}
+	if *status == want {
+		return nil
+	}
+	*status = want
+	return tx.Model(value).UpdateColumn("status", want).Error
+}
@@ -0,0 +1,56 @@
+package prompt
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 GORM 生成的 SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+// dryRunPool 不连接数据库的连接池，支持开启事务
+type dryRunPool struct{ gorm.ConnPool }
+
+func (p dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+func TestCreateDisabled(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunPool{}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	require.NoError(t, err)
+	service := NewService(db)
+
+	template := &Template{ID: 2, Name: "客服", Content: "你是{{company}}的客服", Status: 0}
+	require.NoError(t, service.CreateTemplate(template, ""))
+	assert.Equal(t, 0, template.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "ai_prompt_template" SET "status"=0 WHERE "id" = 2`)
+
+	assistant := &Assistant{ID: 4, Name: "客服助手", TemplateID: 2, Status: 0}
+	require.NoError(t, service.CreateAssistant(assistant))
+	assert.Equal(t, 0, assistant.Status)
+	assert.Contains(t, recorder.statements, `UPDATE "ai_assistant" SET "status"=0 WHERE "id" = 4`)
+}
@@ -0,0 +1,86 @@
+package recyclebin
+
+import (
+	"gorm.io/gorm"
+)
+
+// Config 回收站配置
+type Config struct {
+	// 是否启用回收站
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// 回收站数据保留天数，超过后由定时任务彻底删除（0表示不自动清理）
+	RetentionDays int `yaml:"retentionDays" json:"retentionDays"`
+	// 自动清理任务的Cron表达式
+	PurgeCron string `yaml:"purgeCron" json:"purgeCron"`
+	// 单次自动清理的最大记录数
+	PurgeBatchSize int `yaml:"purgeBatchSize" json:"purgeBatchSize"`
+	// 默认分页大小
+	DefaultPageSize int `yaml:"defaultPageSize" json:"defaultPageSize"`
+	// 最大分页大小
+	MaxPageSize int `yaml:"maxPageSize" json:"maxPageSize"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:         true,
+		RetentionDays:   30,          // 保留30天
+		PurgeCron:       "0 3 * * *", // 每天凌晨3点执行
+		PurgeBatchSize:  500,
+		DefaultPageSize: 10,
+		MaxPageSize:     100,
+	}
+}
+
+// Plugin 回收站插件
+type Plugin struct {
+	config   *Config
+	db       *gorm.DB
+	registry *Registry
+}
+
+// NewPlugin 创建回收站插件
+func NewPlugin(db *gorm.DB, cfg *Config) *Plugin {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Plugin{
+		config:   cfg,
+		db:       db,
+		registry: NewRegistry(),
+	}
+}
+
+// GetConfig 获取配置
+func (p *Plugin) GetConfig() *Config {
+	return p.config
+}
+
+// GetDB 获取数据库连接
+func (p *Plugin) GetDB() *gorm.DB {
+	return p.db
+}
+
+// GetRegistry 获取模型注册表
+func (p *Plugin) GetRegistry() *Registry {
+	return p.registry
+}
+
+// IsEnabled 是否启用
+func (p *Plugin) IsEnabled() bool {
+	return p.config.Enabled
+}
+
+// GetService 获取回收站服务
+func (p *Plugin) GetService() *Service {
+	if !p.IsEnabled() {
+		return nil
+	}
+	return NewService(p.db, p.config, p.registry)
+}
+
+// Init 初始化插件
+func (p *Plugin) Init() error {
+	// 回收站基于各业务表的 deleted_at 字段，无需额外建表
+	return nil
+}
@@ -0,0 +1,113 @@
+package recyclebin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gin-admin-pro/plugin/cron"
+)
+
+// PurgeHandlerName 自动清理任务处理器名称
+const PurgeHandlerName = "recycle_bin_purge"
+
+// PurgeJobHandler 回收站自动清理任务处理器
+type PurgeJobHandler struct {
+	service *Service
+}
+
+// NewPurgeJobHandler 创建回收站自动清理任务处理器
+func NewPurgeJobHandler(service *Service) *PurgeJobHandler {
+	return &PurgeJobHandler{service: service}
+}
+
+// RegisterCronJob 向定时任务管理器注册自动清理处理器及任务，保留期为0时任务执行时跳过
+func RegisterCronJob(cm *cron.CronManager, service *Service) error {
+	if cm == nil || service == nil {
+		return nil
+	}
+
+	if err := cm.GetRegistry().RegisterHandler(PurgeHandlerName, NewPurgeJobHandler(service)); err != nil {
+		return err
+	}
+
+	return cm.AddJob(&cron.JobConfig{
+		ID:          PurgeHandlerName,
+		Name:        "回收站自动清理",
+		Description: "彻底删除超过保留期的回收站数据",
+		Enabled:     true,
+		Cron:        service.config.PurgeCron,
+		Handler:     PurgeHandlerName,
+		Timeout:     10 * time.Minute,
+		Singleton:   true,
+	})
+}
+
+// Handle 执行任务，可通过参数 retentionDays 覆盖配置的保留天数
+func (h *PurgeJobHandler) Handle(ctx context.Context, job cron.Job, params map[string]interface{}) error {
+	retention := h.service.GetRetention()
+	if days, ok := params["retentionDays"]; ok {
+		d, err := toInt(days)
+		if err != nil {
+			return err
+		}
+		retention = time.Duration(d) * 24 * time.Hour
+	}
+	if retention <= 0 {
+		log.Printf("Recycle bin purge job %s skipped: retention disabled", job.GetID())
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result, err := h.service.PurgeExpired(time.Now().Add(-retention))
+	for name, count := range result {
+		log.Printf("Recycle bin purge job %s purged %d %s record(s)", job.GetID(), count, name)
+	}
+	return err
+}
+
+// OnStart 任务开始
+func (h *PurgeJobHandler) OnStart(ctx context.Context, job cron.Job) error {
+	log.Printf("Recycle bin purge job %s started", job.GetID())
+	return nil
+}
+
+// OnComplete 任务完成
+func (h *PurgeJobHandler) OnComplete(ctx context.Context, job cron.Job, err error) error {
+	if err != nil {
+		log.Printf("Recycle bin purge job %s completed with error: %v", job.GetID(), err)
+	} else {
+		log.Printf("Recycle bin purge job %s completed successfully", job.GetID())
+	}
+	return nil
+}
+
+// OnError 任务失败
+func (h *PurgeJobHandler) OnError(ctx context.Context, job cron.Job, err error) error {
+	log.Printf("Recycle bin purge job %s failed: %v", job.GetID(), err)
+	return nil
+}
+
+// OnRetry 任务重试
+func (h *PurgeJobHandler) OnRetry(ctx context.Context, job cron.Job, attempt int, err error) error {
+	log.Printf("Recycle bin purge job %s retry attempt %d, last error: %v", job.GetID(), attempt, err)
+	return nil
+}
+
+// toInt 转换任务参数为整数
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	default:
+		return 0, fmt.Errorf("invalid retentionDays param: %v", v)
+	}
+}
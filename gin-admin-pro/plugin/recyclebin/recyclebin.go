@@ -0,0 +1,394 @@
+package recyclebin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTypeNotRegistered 回收站类型未注册
+	ErrTypeNotRegistered = errors.New("回收站类型未注册")
+	// ErrEmptyIDs 未指定记录ID
+	ErrEmptyIDs = errors.New("请选择要操作的记录")
+	// ErrRecordNotDeleted 记录不在回收站中
+	ErrRecordNotDeleted = errors.New("记录不存在或未被删除")
+)
+
+// Entry 回收站模型注册项
+type Entry struct {
+	// 类型标识，如 user、role
+	Name string
+	// 显示名称，如 用户、角色
+	Title string
+	// 返回模型指针，如 &system.User{}
+	Model func() interface{}
+	// 列表展示及关键字搜索使用的列
+	LabelColumn string
+	// 恢复前的依赖检查，返回错误则拒绝恢复
+	CheckRestore func(tx *gorm.DB, id uint) error
+	// 彻底删除前的清理（如关联表），与删除处于同一事务
+	BeforePurge func(tx *gorm.DB, ids []uint) error
+}
+
+// Registry 回收站模型注册表
+type Registry struct {
+	entries map[string]*Entry
+	mu      sync.RWMutex
+}
+
+// NewRegistry 创建模型注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]*Entry),
+	}
+}
+
+// Register 注册模型
+func (r *Registry) Register(entry *Entry) error {
+	if entry == nil || entry.Name == "" {
+		return fmt.Errorf("回收站类型标识不能为空")
+	}
+	if entry.Model == nil {
+		return fmt.Errorf("回收站类型 %s 未指定模型", entry.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[entry.Name]; exists {
+		return fmt.Errorf("回收站类型 %s 已注册", entry.Name)
+	}
+	if entry.Title == "" {
+		entry.Title = entry.Name
+	}
+	if entry.LabelColumn == "" {
+		entry.LabelColumn = "id"
+	}
+	r.entries[entry.Name] = entry
+	return nil
+}
+
+// Get 获取注册项
+func (r *Registry) Get(name string) (*Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, ErrTypeNotRegistered
+	}
+	return entry, nil
+}
+
+// List 获取全部注册项（按类型标识排序）
+func (r *Registry) List() []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Record 回收站记录
+type Record struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// TypeOption 回收站类型选项
+type TypeOption struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+}
+
+// Service 回收站服务
+type Service struct {
+	db            *gorm.DB
+	config        *Config
+	registry      *Registry
+	retentionFunc func() int
+}
+
+// NewService 创建回收站服务
+func NewService(db *gorm.DB, config *Config, registry *Registry) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return &Service{
+		db:       db,
+		config:   config,
+		registry: registry,
+	}
+}
+
+// GetRegistry 获取模型注册表
+func (s *Service) GetRegistry() *Registry {
+	return s.registry
+}
+
+// GetTypeOptions 获取回收站类型选项
+func (s *Service) GetTypeOptions() []TypeOption {
+	entries := s.registry.List()
+	options := make([]TypeOption, 0, len(entries))
+	for _, entry := range entries {
+		options = append(options, TypeOption{Name: entry.Name, Title: entry.Title})
+	}
+	return options
+}
+
+// GetDeletedPage 分页获取已删除记录
+func (s *Service) GetDeletedPage(name string, page, pageSize int, keyword string) ([]Record, int64, error) {
+	entry, err := s.registry.Get(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, pageSize = s.normalizePage(page, pageSize)
+
+	query := s.db.Unscoped().Model(entry.Model()).Where("deleted_at IS NOT NULL")
+	if keyword != "" {
+		query = query.Where(entry.LabelColumn+" LIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ID        uint
+		Label     string
+		DeletedAt time.Time
+	}
+	err = query.Select(fmt.Sprintf("id, %s AS label, deleted_at", entry.LabelColumn)).
+		Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, Record{
+			ID:        row.ID,
+			Type:      entry.Name,
+			Label:     row.Label,
+			DeletedAt: row.DeletedAt,
+		})
+	}
+	return records, total, nil
+}
+
+// Restore 恢复已删除记录
+func (s *Service) Restore(name string, ids []uint) error {
+	entry, err := s.registry.Get(name)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrEmptyIDs
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkDeleted(tx, entry, ids); err != nil {
+			return err
+		}
+
+		if entry.CheckRestore == nil {
+			return s.restore(tx, entry, ids)
+		}
+
+		// 逐条做依赖检查，例如用户所属部门必须存在
+		return restoreInOrder(uniqueIDs(ids), func(id uint) error {
+			return entry.CheckRestore(tx, id)
+		}, func(id uint) error {
+			return s.restore(tx, entry, []uint{id})
+		})
+	})
+}
+
+// restore 在事务中恢复记录
+func (s *Service) restore(tx *gorm.DB, entry *Entry, ids []uint) error {
+	return tx.Unscoped().Model(entry.Model()).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Update("deleted_at", nil).Error
+}
+
+// Purge 彻底删除回收站中的记录
+func (s *Service) Purge(name string, ids []uint) (int64, error) {
+	entry, err := s.registry.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, ErrEmptyIDs
+	}
+
+	var affected int64
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkDeleted(tx, entry, ids); err != nil {
+			return err
+		}
+		n, err := s.purge(tx, entry, ids)
+		affected = n
+		return err
+	})
+	return affected, err
+}
+
+// PurgeExpired 彻底删除超过保留期的记录，返回各类型删除的数量
+func (s *Service) PurgeExpired(before time.Time) (map[string]int64, error) {
+	result := make(map[string]int64)
+	batchSize := s.config.PurgeBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultConfig().PurgeBatchSize
+	}
+
+	for _, entry := range s.registry.List() {
+		var ids []uint
+		err := s.db.Unscoped().Model(entry.Model()).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return result, fmt.Errorf("查询过期%s失败：%w", entry.Title, err)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		var affected int64
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			n, err := s.purge(tx, entry, ids)
+			affected = n
+			return err
+		})
+		if err != nil {
+			return result, fmt.Errorf("清理过期%s失败：%w", entry.Title, err)
+		}
+		result[entry.Name] = affected
+	}
+
+	return result, nil
+}
+
+// SetRetentionFunc 设置保留天数来源（如运行时参数配置），未设置时使用 Config.RetentionDays
+func (s *Service) SetRetentionFunc(fn func() int) {
+	s.retentionFunc = fn
+}
+
+// GetRetention 获取保留时长，0表示不自动清理
+func (s *Service) GetRetention() time.Duration {
+	days := s.config.RetentionDays
+	if s.retentionFunc != nil {
+		days = s.retentionFunc()
+	}
+	if days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// purge 在事务中彻底删除记录
+func (s *Service) purge(tx *gorm.DB, entry *Entry, ids []uint) (int64, error) {
+	if entry.BeforePurge != nil {
+		if err := entry.BeforePurge(tx, ids); err != nil {
+			return 0, err
+		}
+	}
+
+	result := tx.Unscoped().
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Delete(entry.Model())
+	return result.RowsAffected, result.Error
+}
+
+// checkDeleted 检查记录均处于已删除状态
+func (s *Service) checkDeleted(tx *gorm.DB, entry *Entry, ids []uint) error {
+	var count int64
+	err := tx.Unscoped().Model(entry.Model()).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count != int64(len(uniqueIDs(ids))) {
+		return ErrRecordNotDeleted
+	}
+	return nil
+}
+
+// normalizePage 规范分页参数
+func (s *Service) normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = s.config.DefaultPageSize
+	}
+	if s.config.MaxPageSize > 0 && pageSize > s.config.MaxPageSize {
+		pageSize = s.config.MaxPageSize
+	}
+	return page, pageSize
+}
+
+// restoreInOrder 通过检查的记录立即恢复，同批次中的上级记录恢复后下级记录即可通过检查，
+// 直到全部恢复或剩余记录均无法通过检查，此时返回第一条失败的原因
+func restoreInOrder(ids []uint, check, restore func(id uint) error) error {
+	pending := ids
+	for len(pending) > 0 {
+		var (
+			remaining []uint
+			firstErr  error
+		)
+		for _, id := range pending {
+			if err := check(id); err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("记录[%d]无法恢复：%w", id, err)
+				}
+				remaining = append(remaining, id)
+				continue
+			}
+			if err := restore(id); err != nil {
+				return err
+			}
+		}
+		if len(remaining) == len(pending) {
+			return firstErr
+		}
+		pending = remaining
+	}
+	return nil
+}
+
+// uniqueIDs 去重ID列表
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]struct{}, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
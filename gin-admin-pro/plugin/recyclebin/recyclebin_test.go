@@ -0,0 +1,217 @@
+package recyclebin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testModel struct {
+	ID uint
+}
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	if !config.Enabled {
+		t.Error("Default config should be enabled")
+	}
+
+	if config.RetentionDays != 30 {
+		t.Errorf("Default retention days should be 30, got %d", config.RetentionDays)
+	}
+
+	if config.PurgeCron == "" {
+		t.Error("Default purge cron should not be empty")
+	}
+
+	if config.PurgeBatchSize <= 0 {
+		t.Error("Default purge batch size should be positive")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	plugin := NewPlugin(nil, nil)
+
+	if !plugin.IsEnabled() {
+		t.Error("Plugin should be enabled by default")
+	}
+
+	if plugin.GetRegistry() == nil {
+		t.Error("Registry should not be nil")
+	}
+
+	if plugin.GetService() == nil {
+		t.Error("Service should not be nil when plugin is enabled")
+	}
+
+	plugin2 := NewPlugin(nil, &Config{Enabled: false})
+	if plugin2.GetService() != nil {
+		t.Error("Service should be nil when plugin is disabled")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(&Entry{Name: "user", Model: func() interface{} { return &testModel{} }}); err != nil {
+		t.Fatalf("Register should succeed: %v", err)
+	}
+
+	if err := registry.Register(&Entry{Name: "dept", Title: "部门", LabelColumn: "name", Model: func() interface{} { return &testModel{} }}); err != nil {
+		t.Fatalf("Register should succeed: %v", err)
+	}
+
+	// 重复注册
+	if err := registry.Register(&Entry{Name: "user", Model: func() interface{} { return &testModel{} }}); err == nil {
+		t.Error("Duplicate register should fail")
+	}
+
+	// 缺少模型
+	if err := registry.Register(&Entry{Name: "menu"}); err == nil {
+		t.Error("Register without model should fail")
+	}
+
+	entry, err := registry.Get("user")
+	if err != nil {
+		t.Fatalf("Get should succeed: %v", err)
+	}
+	if entry.Title != "user" {
+		t.Errorf("Title should default to name, got %s", entry.Title)
+	}
+	if entry.LabelColumn != "id" {
+		t.Errorf("Label column should default to id, got %s", entry.LabelColumn)
+	}
+
+	if _, err := registry.Get("post"); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("Get unregistered type should return ErrTypeNotRegistered, got %v", err)
+	}
+
+	entries := registry.List()
+	if len(entries) != 2 || entries[0].Name != "dept" || entries[1].Name != "user" {
+		t.Errorf("List should be sorted by name, got %v", entries)
+	}
+
+	service := NewService(nil, nil, registry)
+	options := service.GetTypeOptions()
+	if len(options) != 2 || options[0].Title != "部门" {
+		t.Errorf("Unexpected type options: %v", options)
+	}
+}
+
+func TestServiceValidation(t *testing.T) {
+	registry := NewRegistry()
+	_ = registry.Register(&Entry{Name: "user", Model: func() interface{} { return &testModel{} }})
+	service := NewService(nil, nil, registry)
+
+	if err := service.Restore("post", []uint{1}); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("Restore unregistered type should fail, got %v", err)
+	}
+
+	if err := service.Restore("user", nil); !errors.Is(err, ErrEmptyIDs) {
+		t.Errorf("Restore without ids should fail, got %v", err)
+	}
+
+	if _, err := service.Purge("user", nil); !errors.Is(err, ErrEmptyIDs) {
+		t.Errorf("Purge without ids should fail, got %v", err)
+	}
+}
+
+func TestGetRetention(t *testing.T) {
+	service := NewService(nil, &Config{RetentionDays: 7}, nil)
+	if service.GetRetention() != 7*24*time.Hour {
+		t.Errorf("Retention should be 7 days, got %v", service.GetRetention())
+	}
+
+	service = NewService(nil, &Config{RetentionDays: 0}, nil)
+	if service.GetRetention() != 0 {
+		t.Errorf("Retention should be disabled, got %v", service.GetRetention())
+	}
+
+	service.SetRetentionFunc(func() int { return 3 })
+	if service.GetRetention() != 3*24*time.Hour {
+		t.Errorf("Retention func should override config, got %v", service.GetRetention())
+	}
+
+	if err := RegisterCronJob(nil, service); err != nil {
+		t.Errorf("Register cron job without manager should be no-op, got %v", err)
+	}
+}
+
+func TestNormalizePage(t *testing.T) {
+	service := NewService(nil, DefaultConfig(), nil)
+
+	tests := []struct {
+		page, pageSize         int
+		wantPage, wantPageSize int
+	}{
+		{0, 0, 1, 10},
+		{2, 20, 2, 20},
+		{1, 1000, 1, 100},
+	}
+
+	for _, tt := range tests {
+		page, pageSize := service.normalizePage(tt.page, tt.pageSize)
+		if page != tt.wantPage || pageSize != tt.wantPageSize {
+			t.Errorf("normalizePage(%d, %d) = (%d, %d), want (%d, %d)",
+				tt.page, tt.pageSize, page, pageSize, tt.wantPage, tt.wantPageSize)
+		}
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	ids := uniqueIDs([]uint{1, 2, 2, 3, 1})
+	if len(ids) != 3 {
+		t.Errorf("uniqueIDs should remove duplicates, got %v", ids)
+	}
+}
+
+func TestRestoreInOrder(t *testing.T) {
+	// 3 的上级是 2，2 的上级是 1，同批次按任意顺序提交均可恢复
+	parents := map[uint]uint{1: 0, 2: 1, 3: 2}
+	restored := map[uint]bool{}
+	var order []uint
+	check := func(id uint) error {
+		if parent := parents[id]; parent != 0 && !restored[parent] {
+			return errors.New("请先恢复上级部门")
+		}
+		return nil
+	}
+	restore := func(id uint) error {
+		restored[id] = true
+		order = append(order, id)
+		return nil
+	}
+
+	if err := restoreInOrder([]uint{3, 2, 1}, check, restore); err != nil {
+		t.Fatalf("restoreInOrder should succeed: %v", err)
+	}
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Errorf("Parents should be restored first, got %v", order)
+	}
+
+	// 上级不在批次中且未恢复时返回检查失败的原因
+	restored = map[uint]bool{}
+	order = nil
+	err := restoreInOrder([]uint{3, 2}, check, restore)
+	if err == nil || !strings.Contains(err.Error(), "记录[3]无法恢复") {
+		t.Errorf("restoreInOrder should report the first failure, got %v", err)
+	}
+	if len(order) != 0 {
+		t.Errorf("Nothing should be restored, got %v", order)
+	}
+}
+
+func TestToInt(t *testing.T) {
+	for _, v := range []interface{}{7, int64(7), float64(7)} {
+		n, err := toInt(v)
+		if err != nil || n != 7 {
+			t.Errorf("toInt(%v) = %d, %v", v, n, err)
+		}
+	}
+
+	if _, err := toInt("7"); err == nil {
+		t.Error("toInt should reject string")
+	}
+}
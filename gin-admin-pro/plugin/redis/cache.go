@@ -188,8 +188,8 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
 		return "", fmt.Errorf("key not found")
 	}
 
+	// 读锁下不能删除，过期项由 cleanup 统一清理
 	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
-		delete(c.data, key)
 		return "", fmt.Errorf("key expired")
 	}
 
@@ -235,7 +235,6 @@ func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
 	}
 
 	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
-		delete(c.data, key)
 		return false, nil
 	}
 
@@ -274,7 +273,6 @@ func (c *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error
 
 	ttl := time.Until(item.expiration)
 	if ttl <= 0 {
-		delete(c.data, key)
 		return -2, nil
 	}
 
@@ -341,3 +341,22 @@ func (c *Client) FlushDB(ctx context.Context) error {
 func (c *Client) FlushAll(ctx context.Context) error {
 	return c.client.FlushAll(ctx).Err()
 }
+
+// Publish 发布消息
+func (c *Client) Publish(ctx context.Context, channel string, message interface{}) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe 订阅频道
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (*redis.PubSub, error) {
+	switch v := c.client.(type) {
+	case *redis.Client:
+		return v.Subscribe(ctx, channels...), nil
+	case *redis.ClusterClient:
+		return v.Subscribe(ctx, channels...), nil
+	case *redis.Ring:
+		return v.Subscribe(ctx, channels...), nil
+	default:
+		return nil, fmt.Errorf("subscribe is not supported by %T", c.client)
+	}
+}
@@ -0,0 +1,138 @@
+package sysconfig
+
+import (
+	"sync"
+
+	"gin-admin-pro/plugin/redis"
+
+	"gorm.io/gorm"
+)
+
+// Config 参数配置插件配置
+type Config struct {
+	// 是否启用参数配置
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// 是否启用缓存
+	EnableCache bool `yaml:"enableCache" json:"enableCache"`
+	// 缓存过期时间（秒）
+	CacheExpire int `yaml:"cacheExpire" json:"cacheExpire"`
+	// 缓存前缀
+	CachePrefix string `yaml:"cachePrefix" json:"cachePrefix"`
+	// 是否启用变更通知（基于Redis发布订阅，多实例同步）
+	EnableNotify bool `yaml:"enableNotify" json:"enableNotify"`
+	// 变更通知频道
+	NotifyChannel string `yaml:"notifyChannel" json:"notifyChannel"`
+	// 默认分页大小
+	DefaultPageSize int `yaml:"defaultPageSize" json:"defaultPageSize"`
+	// 最大分页大小
+	MaxPageSize int `yaml:"maxPageSize" json:"maxPageSize"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:         true,
+		EnableCache:     true,
+		CacheExpire:     3600, // 1小时
+		CachePrefix:     "sys_config:",
+		EnableNotify:    true,
+		NotifyChannel:   "sys_config:change",
+		DefaultPageSize: 20,
+		MaxPageSize:     100,
+	}
+}
+
+// Plugin 参数配置插件
+type Plugin struct {
+	config      *Config
+	db          *gorm.DB
+	redisClient *redis.Client
+	service     *Service
+	once        sync.Once
+}
+
+// NewPlugin 创建参数配置插件，redisClient 为空时使用内存缓存且不做跨实例通知
+func NewPlugin(db *gorm.DB, redisClient *redis.Client, cfg *Config) *Plugin {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Plugin{
+		config:      cfg,
+		db:          db,
+		redisClient: redisClient,
+	}
+}
+
+// GetConfig 获取配置
+func (p *Plugin) GetConfig() *Config {
+	return p.config
+}
+
+// GetDB 获取数据库连接
+func (p *Plugin) GetDB() *gorm.DB {
+	return p.db
+}
+
+// IsEnabled 是否启用
+func (p *Plugin) IsEnabled() bool {
+	return p.config.Enabled
+}
+
+// GetService 获取参数配置服务（单例，变更监听器挂在服务实例上）
+func (p *Plugin) GetService() *Service {
+	if !p.IsEnabled() {
+		return nil
+	}
+	p.once.Do(func() {
+		p.service = NewService(p.db, p.redisClient, p.config)
+	})
+	return p.service
+}
+
+// AutoMigrate 自动迁移数据库表
+func (p *Plugin) AutoMigrate() error {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	return p.db.AutoMigrate(&SysConfig{})
+}
+
+// Init 初始化插件
+func (p *Plugin) Init() error {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	// 自动迁移数据库表
+	if err := p.AutoMigrate(); err != nil {
+		return err
+	}
+
+	// 初始化内置参数
+	return p.initDefaultData()
+}
+
+// initDefaultData 初始化内置参数
+func (p *Plugin) initDefaultData() error {
+	service := p.GetService()
+	if service == nil {
+		return nil
+	}
+
+	for _, item := range GetBuiltinConfigs() {
+		var count int64
+		if err := p.db.Model(&SysConfig{}).Where("config_key = ?", item.ConfigKey).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		cfg := item
+		if err := service.CreateConfig(&cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
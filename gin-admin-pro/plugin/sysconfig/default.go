@@ -0,0 +1,48 @@
+package sysconfig
+
+import "sync/atomic"
+
+// defaultService 全局参数配置服务
+var defaultService atomic.Pointer[Service]
+
+// SetDefaultService 设置全局参数配置服务
+func SetDefaultService(s *Service) {
+	defaultService.Store(s)
+}
+
+// GetDefaultService 获取全局参数配置服务，未初始化时返回 nil
+func GetDefaultService() *Service {
+	return defaultService.Load()
+}
+
+// GetString 从全局服务读取字符串参数，服务未初始化时返回默认值
+func GetString(key, defaultValue string) string {
+	if s := GetDefaultService(); s != nil {
+		return s.GetString(key, defaultValue)
+	}
+	return defaultValue
+}
+
+// GetInt 从全局服务读取整数参数，服务未初始化时返回默认值
+func GetInt(key string, defaultValue int) int {
+	if s := GetDefaultService(); s != nil {
+		return s.GetInt(key, defaultValue)
+	}
+	return defaultValue
+}
+
+// GetBool 从全局服务读取布尔参数，服务未初始化时返回默认值
+func GetBool(key string, defaultValue bool) bool {
+	if s := GetDefaultService(); s != nil {
+		return s.GetBool(key, defaultValue)
+	}
+	return defaultValue
+}
+
+// GetJSON 从全局服务读取JSON参数，服务未初始化时返回 ErrConfigNotFound
+func GetJSON(key string, obj interface{}) error {
+	if s := GetDefaultService(); s != nil {
+		return s.GetJSON(key, obj)
+	}
+	return ErrConfigNotFound
+}
@@ -0,0 +1,125 @@
+package sysconfig
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// 变更动作
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ChangeEvent 参数变更事件
+type ChangeEvent struct {
+	Action    string    `json:"action"`
+	Key       string    `json:"key"`
+	OldValue  string    `json:"oldValue"`
+	Value     string    `json:"value"`
+	Source    string    `json:"source"` // 发起变更的实例ID
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Listener 参数变更监听器
+type Listener func(event ChangeEvent)
+
+// Watch 监听指定参数的变更，key 为空时监听全部参数
+func (s *Service) Watch(key string, listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners[key] = append(s.listeners[key], listener)
+}
+
+// StartNotifyListener 订阅其他实例的变更通知，直到 ctx 结束
+func (s *Service) StartNotifyListener(ctx context.Context) error {
+	if !s.config.EnableNotify || s.redisClient == nil {
+		return nil
+	}
+
+	pubsub, err := s.redisClient.Subscribe(ctx, s.config.NotifyChannel)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var event ChangeEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("Invalid config change event: %v", err)
+					continue
+				}
+				// 本实例发起的变更已在本地处理
+				if event.Source == s.instanceID {
+					continue
+				}
+				s.evict(event.Key)
+				s.dispatch(event)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// afterChange 参数变更后清除缓存、通知本地监听器并广播到其他实例
+func (s *Service) afterChange(event ChangeEvent) {
+	event.Source = s.instanceID
+	event.Timestamp = time.Now()
+
+	s.evict(event.Key)
+	s.dispatch(event)
+
+	if s.config.EnableNotify && s.redisClient != nil {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		if err := s.redisClient.Publish(context.Background(), s.config.NotifyChannel, payload); err != nil {
+			log.Printf("Failed to publish config change event for %s: %v", event.Key, err)
+		}
+	}
+}
+
+// dispatch 通知本地监听器
+func (s *Service) dispatch(event ChangeEvent) {
+	s.mu.RLock()
+	listeners := make([]Listener, 0, len(s.listeners[event.Key])+len(s.listeners[""]))
+	listeners = append(listeners, s.listeners[event.Key]...)
+	listeners = append(listeners, s.listeners[""]...)
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Config change listener panic for %s: %v", event.Key, r)
+				}
+			}()
+			listener(event)
+		}()
+	}
+}
+
+// newInstanceID 生成实例ID
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,360 @@
+package sysconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-admin-pro/plugin/redis"
+
+	"gorm.io/gorm"
+)
+
+// 参数类型
+const (
+	TypeBuiltin = 1 // 内置参数
+	TypeCustom  = 2 // 自定义参数
+)
+
+// 内置参数键
+const (
+	KeyUserInitPassword    = "system.user.init-password"
+	KeyRecycleBinRetention = "system.recycle-bin.retention-days"
+)
+
+var (
+	// ErrConfigNotFound 参数配置不存在
+	ErrConfigNotFound = errors.New("参数配置不存在")
+	// ErrConfigKeyExists 参数键名已存在
+	ErrConfigKeyExists = errors.New("参数键名已存在")
+	// ErrConfigBuiltin 内置参数不允许删除或修改键名
+	ErrConfigBuiltin = errors.New("内置参数不允许删除或修改键名")
+)
+
+// SysConfig 参数配置
+type SysConfig struct {
+	ID        int       `gorm:"primarykey" json:"id"`
+	Category  string    `gorm:"size:50" json:"category"`
+	Type      int       `gorm:"default:2" json:"type"` // 1-内置 2-自定义
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	ConfigKey string    `gorm:"size:100;not null;uniqueIndex" json:"key"`
+	Value     string    `gorm:"size:500;not null" json:"value"`
+	Visible   bool      `gorm:"not null" json:"visible"` // 是否允许前端按键名读取
+	Remark    string    `gorm:"size:500" json:"remark"`
+	CreateBy  int       `gorm:"default:0" json:"createBy"`
+	UpdateBy  int       `gorm:"default:0" json:"updateBy"`
+	CreatedAt time.Time `json:"createTime"`
+	UpdatedAt time.Time `json:"updateTime"`
+}
+
+// TableName 表名
+func (SysConfig) TableName() string {
+	return "infra_config"
+}
+
+// Service 参数配置服务
+type Service struct {
+	db          *gorm.DB
+	config      *Config
+	cache       redis.Cache
+	redisClient *redis.Client
+	instanceID  string
+	listeners   map[string][]Listener
+	mu          sync.RWMutex
+}
+
+// NewService 创建参数配置服务
+func NewService(db *gorm.DB, redisClient *redis.Client, config *Config) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	s := &Service{
+		db:          db,
+		config:      config,
+		redisClient: redisClient,
+		instanceID:  newInstanceID(),
+		listeners:   make(map[string][]Listener),
+	}
+
+	if config.EnableCache {
+		if redisClient != nil {
+			s.cache = redis.NewRedisCache(redisClient)
+		} else {
+			s.cache = redis.NewMemoryCache()
+		}
+	}
+
+	return s
+}
+
+// GetConfigPage 获取参数配置分页列表
+func (s *Service) GetConfigPage(page, pageSize int, name, key string, configType int) ([]SysConfig, int64, error) {
+	var configs []SysConfig
+	var total int64
+
+	query := s.db.Model(&SysConfig{})
+
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	if key != "" {
+		query = query.Where("config_key LIKE ?", "%"+key+"%")
+	}
+	if configType > 0 {
+		query = query.Where("type = ?", configType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = s.config.DefaultPageSize
+	}
+	if s.config.MaxPageSize > 0 && pageSize > s.config.MaxPageSize {
+		pageSize = s.config.MaxPageSize
+	}
+
+	err := query.Order("id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&configs).Error
+	return configs, total, err
+}
+
+// GetConfigByID 根据ID获取参数配置
+func (s *Service) GetConfigByID(id int) (*SysConfig, error) {
+	var cfg SysConfig
+	if err := s.db.First(&cfg, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetConfigByKey 根据键名获取参数配置
+func (s *Service) GetConfigByKey(key string) (*SysConfig, error) {
+	var cfg SysConfig
+	if err := s.db.Where("config_key = ?", key).First(&cfg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// CreateConfig 创建参数配置
+func (s *Service) CreateConfig(cfg *SysConfig) error {
+	cfg.ConfigKey = strings.TrimSpace(cfg.ConfigKey)
+	if cfg.ConfigKey == "" {
+		return fmt.Errorf("参数键名不能为空")
+	}
+
+	var count int64
+	if err := s.db.Model(&SysConfig{}).Where("config_key = ?", cfg.ConfigKey).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrConfigKeyExists
+	}
+	if cfg.Type == 0 {
+		cfg.Type = TypeCustom
+	}
+
+	if err := s.db.Create(cfg).Error; err != nil {
+		return err
+	}
+
+	s.afterChange(ChangeEvent{Action: ActionCreate, Key: cfg.ConfigKey, Value: cfg.Value})
+	return nil
+}
+
+// UpdateConfig 更新参数配置
+func (s *Service) UpdateConfig(cfg *SysConfig) error {
+	old, err := s.GetConfigByID(cfg.ID)
+	if err != nil {
+		return err
+	}
+
+	cfg.ConfigKey = strings.TrimSpace(cfg.ConfigKey)
+	if cfg.ConfigKey == "" {
+		cfg.ConfigKey = old.ConfigKey
+	}
+	if cfg.ConfigKey != old.ConfigKey {
+		if old.Type == TypeBuiltin {
+			return ErrConfigBuiltin
+		}
+		var count int64
+		if err := s.db.Model(&SysConfig{}).Where("config_key = ? AND id != ?", cfg.ConfigKey, cfg.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrConfigKeyExists
+		}
+	}
+
+	// 内置参数类型不可修改
+	cfg.Type = old.Type
+	cfg.CreateBy = old.CreateBy
+	cfg.CreatedAt = old.CreatedAt
+
+	if err := s.db.Save(cfg).Error; err != nil {
+		return err
+	}
+
+	if cfg.ConfigKey != old.ConfigKey {
+		s.afterChange(ChangeEvent{Action: ActionDelete, Key: old.ConfigKey, OldValue: old.Value})
+		s.afterChange(ChangeEvent{Action: ActionCreate, Key: cfg.ConfigKey, Value: cfg.Value})
+	} else if cfg.Value != old.Value {
+		s.afterChange(ChangeEvent{Action: ActionUpdate, Key: cfg.ConfigKey, OldValue: old.Value, Value: cfg.Value})
+	}
+	return nil
+}
+
+// DeleteConfig 删除参数配置
+func (s *Service) DeleteConfig(id int) error {
+	old, err := s.GetConfigByID(id)
+	if err != nil {
+		return err
+	}
+	if old.Type == TypeBuiltin {
+		return ErrConfigBuiltin
+	}
+
+	if err := s.db.Delete(&SysConfig{}, id).Error; err != nil {
+		return err
+	}
+
+	s.afterChange(ChangeEvent{Action: ActionDelete, Key: old.ConfigKey, OldValue: old.Value})
+	return nil
+}
+
+// GetValue 获取参数值（优先读取缓存）
+func (s *Service) GetValue(key string) (string, error) {
+	ctx := context.Background()
+
+	if s.cache != nil {
+		if val, err := s.cache.Get(ctx, s.cacheKey(key)); err == nil {
+			return val, nil
+		}
+	}
+
+	cfg, err := s.GetConfigByKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if s.cache != nil {
+		_ = s.cache.Set(ctx, s.cacheKey(key), cfg.Value, time.Duration(s.config.CacheExpire)*time.Second)
+	}
+	return cfg.Value, nil
+}
+
+// GetString 获取字符串参数，不存在时返回默认值
+func (s *Service) GetString(key, defaultValue string) string {
+	val, err := s.GetValue(key)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// GetInt 获取整数参数，不存在或格式错误时返回默认值
+func (s *Service) GetInt(key string, defaultValue int) int {
+	val, err := s.GetValue(key)
+	if err != nil {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// GetBool 获取布尔参数，不存在或格式错误时返回默认值
+func (s *Service) GetBool(key string, defaultValue bool) bool {
+	val, err := s.GetValue(key)
+	if err != nil {
+		return defaultValue
+	}
+	b, ok := parseBool(val)
+	if !ok {
+		return defaultValue
+	}
+	return b
+}
+
+// GetJSON 获取JSON参数并解析到 obj
+func (s *Service) GetJSON(key string, obj interface{}) error {
+	val, err := s.GetValue(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(val), obj); err != nil {
+		return fmt.Errorf("参数 %s 不是合法的JSON：%w", key, err)
+	}
+	return nil
+}
+
+// RefreshCache 刷新参数缓存
+func (s *Service) RefreshCache() error {
+	if s.cache == nil {
+		return nil
+	}
+
+	var keys []string
+	if err := s.db.Model(&SysConfig{}).Pluck("config_key", &keys).Error; err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cacheKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		cacheKeys = append(cacheKeys, s.cacheKey(key))
+	}
+	return s.cache.Del(context.Background(), cacheKeys...)
+}
+
+// evict 清除指定参数缓存
+func (s *Service) evict(key string) {
+	if s.cache != nil {
+		_ = s.cache.Del(context.Background(), s.cacheKey(key))
+	}
+}
+
+// cacheKey 生成缓存键
+func (s *Service) cacheKey(key string) string {
+	return s.config.CachePrefix + key
+}
+
+// parseBool 解析布尔值，兼容 1/0、true/false、yes/no、on/off
+func parseBool(val string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "1", "true", "yes", "on", "y":
+		return true, true
+	case "0", "false", "no", "off", "n":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// GetBuiltinConfigs 获取内置参数
+func GetBuiltinConfigs() []SysConfig {
+	return []SysConfig{
+		{Category: "用户", Type: TypeBuiltin, Name: "用户管理-账号初始密码", ConfigKey: KeyUserInitPassword, Value: "123456", Visible: false, Remark: "创建用户未指定密码时使用"},
+		{Category: "系统", Type: TypeBuiltin, Name: "回收站-保留天数", ConfigKey: KeyRecycleBinRetention, Value: "30", Visible: true, Remark: "回收站数据超过保留天数后自动彻底删除，0表示不自动清理"},
+	}
+}
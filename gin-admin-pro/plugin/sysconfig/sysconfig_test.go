@@ -0,0 +1,202 @@
+package sysconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	if !config.Enabled {
+		t.Error("Default config should be enabled")
+	}
+
+	if !config.EnableCache {
+		t.Error("Cache should be enabled by default")
+	}
+
+	if config.CachePrefix != "sys_config:" {
+		t.Errorf("Default cache prefix should be sys_config:, got %s", config.CachePrefix)
+	}
+
+	if config.NotifyChannel == "" {
+		t.Error("Default notify channel should not be empty")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	plugin := NewPlugin(nil, nil, nil)
+
+	if !plugin.IsEnabled() {
+		t.Error("Plugin should be enabled by default")
+	}
+
+	// 服务为单例，保证监听器不丢失
+	if plugin.GetService() != plugin.GetService() {
+		t.Error("GetService should return the same instance")
+	}
+
+	plugin2 := NewPlugin(nil, nil, &Config{Enabled: false})
+	if plugin2.GetService() != nil {
+		t.Error("Service should be nil when plugin is disabled")
+	}
+}
+
+func TestTableName(t *testing.T) {
+	if (SysConfig{}).TableName() != "infra_config" {
+		t.Errorf("Unexpected table name: %s", SysConfig{}.TableName())
+	}
+}
+
+// newCachedService 创建仅使用内存缓存的服务，参数值直接写入缓存
+func newCachedService(t *testing.T, values map[string]string) *Service {
+	service := NewService(nil, nil, DefaultConfig())
+	for key, value := range values {
+		if err := service.cache.Set(context.Background(), service.cacheKey(key), value, time.Minute); err != nil {
+			t.Fatalf("Set cache failed: %v", err)
+		}
+	}
+	return service
+}
+
+func TestTypedGetters(t *testing.T) {
+	service := newCachedService(t, map[string]string{
+		"app.name":    "gin-admin",
+		"app.size":    " 42 ",
+		"app.bad":     "abc",
+		"app.enabled": "on",
+		"app.json":    `{"a":1,"b":["x","y"]}`,
+	})
+
+	if v := service.GetString("app.name", "def"); v != "gin-admin" {
+		t.Errorf("GetString = %s, want gin-admin", v)
+	}
+
+	if v := service.GetInt("app.size", 0); v != 42 {
+		t.Errorf("GetInt = %d, want 42", v)
+	}
+
+	if v := service.GetInt("app.bad", 7); v != 7 {
+		t.Errorf("GetInt with invalid value should return default, got %d", v)
+	}
+
+	if v := service.GetBool("app.enabled", false); !v {
+		t.Error("GetBool should parse on as true")
+	}
+
+	if v := service.GetBool("app.bad", true); !v {
+		t.Error("GetBool with invalid value should return default")
+	}
+
+	var obj struct {
+		A int      `json:"a"`
+		B []string `json:"b"`
+	}
+	if err := service.GetJSON("app.json", &obj); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+	if obj.A != 1 || len(obj.B) != 2 {
+		t.Errorf("Unexpected JSON value: %+v", obj)
+	}
+
+	if err := service.GetJSON("app.name", &obj); err == nil {
+		t.Error("GetJSON should fail for non JSON value")
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+		ok    bool
+	}{
+		{"1", true, true},
+		{"TRUE", true, true},
+		{"yes", true, true},
+		{"0", false, true},
+		{"off", false, true},
+		{"maybe", false, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseBool(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseBool(%q) = (%v, %v), want (%v, %v)", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWatchAndAfterChange(t *testing.T) {
+	service := newCachedService(t, map[string]string{"app.size": "1"})
+
+	var keyEvents, allEvents []ChangeEvent
+	service.Watch("app.size", func(event ChangeEvent) {
+		keyEvents = append(keyEvents, event)
+	})
+	service.Watch("", func(event ChangeEvent) {
+		allEvents = append(allEvents, event)
+	})
+	service.Watch("app.size", func(event ChangeEvent) {
+		panic("listener panic should be recovered")
+	})
+
+	service.afterChange(ChangeEvent{Action: ActionUpdate, Key: "app.size", OldValue: "1", Value: "2"})
+	service.afterChange(ChangeEvent{Action: ActionCreate, Key: "app.other", Value: "x"})
+
+	if len(keyEvents) != 1 || keyEvents[0].Value != "2" {
+		t.Errorf("Key listener should receive one event, got %v", keyEvents)
+	}
+
+	if len(allEvents) != 2 {
+		t.Errorf("Global listener should receive all events, got %d", len(allEvents))
+	}
+
+	if keyEvents[0].Source != service.instanceID {
+		t.Error("Event source should be the local instance")
+	}
+
+	// 变更后缓存应被清除
+	if _, err := service.cache.Get(context.Background(), service.cacheKey("app.size")); err == nil {
+		t.Error("Cache should be evicted after change")
+	}
+}
+
+func TestDefaultService(t *testing.T) {
+	SetDefaultService(nil)
+
+	if v := GetString("app.name", "def"); v != "def" {
+		t.Errorf("GetString without default service should return default, got %s", v)
+	}
+	if v := GetInt("app.size", 3); v != 3 {
+		t.Errorf("GetInt without default service should return default, got %d", v)
+	}
+	if err := GetJSON("app.json", &struct{}{}); err != ErrConfigNotFound {
+		t.Errorf("GetJSON without default service should return ErrConfigNotFound, got %v", err)
+	}
+
+	SetDefaultService(newCachedService(t, map[string]string{"app.enabled": "true"}))
+	defer SetDefaultService(nil)
+
+	if !GetBool("app.enabled", false) {
+		t.Error("GetBool should read from default service")
+	}
+}
+
+func TestBuiltinConfigs(t *testing.T) {
+	keys := make(map[string]bool)
+	for _, cfg := range GetBuiltinConfigs() {
+		if cfg.Type != TypeBuiltin {
+			t.Errorf("Builtin config %s should have builtin type", cfg.ConfigKey)
+		}
+		if keys[cfg.ConfigKey] {
+			t.Errorf("Duplicate builtin config key %s", cfg.ConfigKey)
+		}
+		keys[cfg.ConfigKey] = true
+	}
+
+	if !keys[KeyUserInitPassword] {
+		t.Error("Init password should be a builtin config")
+	}
+}
@@ -0,0 +1,237 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// MemoryStore 内存向量存储，逐条计算相似度，用于测试和少量数据
+type MemoryStore struct {
+	mu          sync.RWMutex
+	collections map[string]*memoryCollection
+}
+
+// memoryCollection 内存集合
+type memoryCollection struct {
+	schema  Schema
+	records map[int64]Record
+}
+
+var _ VectorStore = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建内存向量存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{collections: make(map[string]*memoryCollection)}
+}
+
+// CreateCollection 创建集合，集合已存在时不修改
+func (s *MemoryStore) CreateCollection(ctx context.Context, name string, schema *Schema) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := schema.Normalize(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; !ok {
+		s.collections[name] = &memoryCollection{schema: *schema, records: make(map[int64]Record)}
+	}
+	return nil
+}
+
+// DropCollection 删除集合，集合不存在时不报错
+func (s *MemoryStore) DropCollection(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.collections, name)
+	return nil
+}
+
+// HasCollection 集合是否存在
+func (s *MemoryStore) HasCollection(ctx context.Context, name string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.collections[name]
+	return ok, nil
+}
+
+// Upsert 写入记录，ID 已存在时覆盖
+func (s *MemoryStore) Upsert(ctx context.Context, collection string, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if len(record.Vector) != c.schema.Dimension {
+			return fmt.Errorf("%w: want %d-dimension vector, got %d", ErrInvalidSchema, c.schema.Dimension, len(record.Vector))
+		}
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+// Search 按集合的度量检索与向量最相近的 TopK 条记录
+func (s *MemoryStore) Search(ctx context.Context, collection string, req *SearchRequest) ([]Hit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Vector) != c.schema.Dimension {
+		return nil, fmt.Errorf("%w: want %d-dimension vector, got %d", ErrInvalidSchema, c.schema.Dimension, len(req.Vector))
+	}
+
+	var hits []Hit
+	for _, record := range c.records {
+		if !matchFilter(record.Fields, req.Filter) {
+			continue
+		}
+		hit := Hit{ID: record.ID, Score: score(c.schema.Metric, record.Vector, req.Vector), Fields: map[string]interface{}{}}
+		for _, field := range req.OutputFields {
+			if value, ok := record.Fields[field]; ok {
+				hit.Fields[field] = value
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	// L2 距离越小越相似，其他度量越大越相似；分数相同时按 ID 排序保证结果稳定
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			if c.schema.Metric == MetricL2 {
+				return hits[i].Score < hits[j].Score
+			}
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if topK := max(req.TopK, 1); len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+// Delete 按 ID 删除记录
+func (s *MemoryStore) Delete(ctx context.Context, collection string, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(c.records, id)
+	}
+	return nil
+}
+
+// DeleteByFilter 删除满足条件的记录
+func (s *MemoryStore) DeleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
+	if len(filter) == 0 {
+		return ErrEmptyFilter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for id, record := range c.records {
+		if matchFilter(record.Fields, filter) {
+			delete(c.records, id)
+		}
+	}
+	return nil
+}
+
+// collection 获取集合，调用方需持有锁
+func (s *MemoryStore) collection(name string) (*memoryCollection, error) {
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("vectorstore: collection %q not found", name)
+	}
+	return c, nil
+}
+
+// matchFilter 记录是否满足全部条件，条件值为切片时表示取值在其中
+func matchFilter(fields map[string]interface{}, filter map[string]interface{}) bool {
+	for name, want := range filter {
+		value := reflect.ValueOf(want)
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			if !equalValue(fields[name], want) {
+				return false
+			}
+			continue
+		}
+
+		matched := false
+		for i := 0; i < value.Len() && !matched; i++ {
+			matched = equalValue(fields[name], value.Index(i).Interface())
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue 比较字段值，数值按大小比较，不区分整数和浮点类型
+func equalValue(a, b interface{}) bool {
+	x, xok := toFloat(a)
+	y, yok := toFloat(b)
+	if xok && yok {
+		return x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toFloat 数值转换为 float64，非数值返回 false
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// score 按度量计算两个向量的分数
+func score(metric Metric, a, b []float32) float32 {
+	var dot, normA, normB, distance float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+		distance += (x - y) * (x - y)
+	}
+
+	switch metric {
+	case MetricL2:
+		return float32(math.Sqrt(distance))
+	case MetricIP:
+		return float32(dot)
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,176 @@
+// Package vectorstore 定义与后端无关的向量存储接口，Milvus 和 PostgreSQL pgvector 均实现该接口，
+// 调用方只依赖接口即可切换存储
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// 每个集合固定的主键和向量字段
+const (
+	// IDField 主键字段，int64
+	IDField = "id"
+	// VectorField 向量字段，float32 数组
+	VectorField = "vector"
+)
+
+var (
+	// ErrInvalidName 集合或字段名称不合法
+	ErrInvalidName = errors.New("vectorstore: invalid name")
+	// ErrInvalidSchema 集合结构不合法
+	ErrInvalidSchema = errors.New("vectorstore: invalid schema")
+	// ErrEmptyFilter 按条件删除时未指定条件，避免误删全部记录
+	ErrEmptyFilter = errors.New("vectorstore: filter is empty")
+)
+
+// Metric 相似度度量
+type Metric string
+
+const (
+	// MetricCosine 余弦相似度，分数越大越相似
+	MetricCosine Metric = "COSINE"
+	// MetricL2 欧氏距离，分数越小越相似
+	MetricL2 Metric = "L2"
+	// MetricIP 内积，分数越大越相似
+	MetricIP Metric = "IP"
+)
+
+// IndexType 向量索引类型
+type IndexType string
+
+const (
+	// IndexHNSW 图索引，召回率高，构建较慢
+	IndexHNSW IndexType = "HNSW"
+	// IndexIVFFlat 倒排聚类索引，构建快，需要一定数据量后效果较好
+	IndexIVFFlat IndexType = "IVF_FLAT"
+)
+
+// FieldType 标量字段类型
+type FieldType string
+
+const (
+	FieldInt64   FieldType = "Int64"
+	FieldDouble  FieldType = "Double"
+	FieldBool    FieldType = "Bool"
+	FieldVarChar FieldType = "VarChar"
+	// FieldJSON JSON 字段，值为对象或数组
+	FieldJSON FieldType = "JSON"
+)
+
+// Field 标量字段，用于过滤和随结果返回
+type Field struct {
+	Name string
+	Type FieldType
+	// MaxLength VarChar 的最大长度
+	MaxLength int
+}
+
+// Schema 集合结构，主键 id 和向量字段 vector 固定存在，Fields 为附加的标量字段
+type Schema struct {
+	Dimension int
+	// Metric 相似度度量，为空时使用余弦相似度
+	Metric Metric
+	// Index 向量索引类型，为空时使用 HNSW
+	Index  IndexType
+	Fields []Field
+}
+
+// Record 一条向量记录
+type Record struct {
+	ID     int64
+	Vector []float32
+	// Fields 标量字段的值
+	Fields map[string]interface{}
+}
+
+// SearchRequest 向量检索请求
+type SearchRequest struct {
+	Vector []float32
+	TopK   int
+	// Filter 标量字段过滤条件，值为切片时表示取值在其中，多个条件同时满足
+	Filter map[string]interface{}
+	// OutputFields 随结果返回的标量字段
+	OutputFields []string
+}
+
+// Hit 检索结果
+type Hit struct {
+	ID int64
+	// Score 相似度分数，余弦和内积越大越相似，L2 为距离，越小越相似
+	Score  float32
+	Fields map[string]interface{}
+}
+
+// VectorStore 向量存储
+type VectorStore interface {
+	// CreateCollection 创建集合及向量索引，集合已存在时不修改
+	CreateCollection(ctx context.Context, name string, schema *Schema) error
+	// DropCollection 删除集合，集合不存在时不报错
+	DropCollection(ctx context.Context, name string) error
+	// HasCollection 集合是否存在
+	HasCollection(ctx context.Context, name string) (bool, error)
+	// Upsert 写入记录，ID 已存在时覆盖
+	Upsert(ctx context.Context, collection string, records []Record) error
+	// Search 检索与向量最相近的 TopK 条记录，按相似程度排序
+	Search(ctx context.Context, collection string, req *SearchRequest) ([]Hit, error)
+	// Delete 按 ID 删除记录
+	Delete(ctx context.Context, collection string, ids []int64) error
+	// DeleteByFilter 删除满足标量字段条件的记录，条件格式与 SearchRequest.Filter 相同，条件为空时返回 ErrEmptyFilter
+	DeleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error
+}
+
+// namePattern 集合和字段名称，同时满足 Milvus 和 PostgreSQL 标识符的要求
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// ValidateName 校验集合或字段名称，只允许字母、数字和下划线且不以数字开头
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Normalize 校验集合结构并填充默认的度量和索引类型
+func (s *Schema) Normalize() error {
+	if s.Dimension <= 0 {
+		return fmt.Errorf("%w: dimension must be positive", ErrInvalidSchema)
+	}
+	switch s.Metric {
+	case "":
+		s.Metric = MetricCosine
+	case MetricCosine, MetricL2, MetricIP:
+	default:
+		return fmt.Errorf("%w: unsupported metric %q", ErrInvalidSchema, s.Metric)
+	}
+	switch s.Index {
+	case "":
+		s.Index = IndexHNSW
+	case IndexHNSW, IndexIVFFlat:
+	default:
+		return fmt.Errorf("%w: unsupported index %q", ErrInvalidSchema, s.Index)
+	}
+
+	seen := map[string]bool{IDField: true, VectorField: true}
+	for _, field := range s.Fields {
+		if err := ValidateName(field.Name); err != nil {
+			return err
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidSchema, field.Name)
+		}
+		seen[field.Name] = true
+		switch field.Type {
+		case FieldInt64, FieldDouble, FieldBool, FieldJSON:
+		case FieldVarChar:
+			if field.MaxLength <= 0 {
+				return fmt.Errorf("%w: field %q requires max length", ErrInvalidSchema, field.Name)
+			}
+		default:
+			return fmt.Errorf("%w: field %q has unsupported type %q", ErrInvalidSchema, field.Name, field.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package vectorstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"docs", "_tmp", "Doc_2"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "2docs", "doc-1", `a"b`, "a b"} {
+		assert.ErrorIs(t, ValidateName(name), ErrInvalidName, name)
+	}
+}
+
+func TestSchemaNormalize(t *testing.T) {
+	schema := &Schema{Dimension: 3, Fields: []Field{{Name: "title", Type: FieldVarChar, MaxLength: 100}}}
+	require.NoError(t, schema.Normalize())
+	assert.Equal(t, MetricCosine, schema.Metric)
+	assert.Equal(t, IndexHNSW, schema.Index)
+
+	invalid := []*Schema{
+		{},
+		{Dimension: 3, Metric: "HAMMING"},
+		{Dimension: 3, Index: "DISKANN"},
+		{Dimension: 3, Fields: []Field{{Name: "id", Type: FieldInt64}}},
+		{Dimension: 3, Fields: []Field{{Name: "title", Type: FieldVarChar}}},
+		{Dimension: 3, Fields: []Field{{Name: "tags", Type: "Array"}}},
+	}
+	for _, schema := range invalid {
+		assert.ErrorIs(t, schema.Normalize(), ErrInvalidSchema, "%+v", schema)
+	}
+	assert.ErrorIs(t, (&Schema{Dimension: 3, Fields: []Field{{Name: "a-b", Type: FieldBool}}}).Normalize(), ErrInvalidName)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	require.NoError(t, store.CreateCollection(ctx, "docs", &Schema{Dimension: 2, Fields: []Field{{Name: "group", Type: FieldInt64}}}))
+	exists, err := store.HasCollection(ctx, "docs")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, store.Upsert(ctx, "docs", []Record{
+		{ID: 1, Vector: []float32{1, 0}, Fields: map[string]interface{}{"group": int64(1)}},
+		{ID: 2, Vector: []float32{1, 1}, Fields: map[string]interface{}{"group": int64(1)}},
+		{ID: 3, Vector: []float32{0, 1}, Fields: map[string]interface{}{"group": int64(2)}},
+	}))
+	assert.ErrorIs(t, store.Upsert(ctx, "docs", []Record{{ID: 4, Vector: []float32{1}}}), ErrInvalidSchema)
+
+	// 按余弦相似度降序，过滤条件的数值类型与字段不同时按大小比较
+	hits, err := store.Search(ctx, "docs", &SearchRequest{Vector: []float32{1, 0}, TopK: 5, Filter: map[string]interface{}{"group": []int{1}}, OutputFields: []string{"group"}})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, int64(1), hits[0].ID)
+	assert.InDelta(t, 1, hits[0].Score, 1e-6)
+	assert.Equal(t, int64(1), hits[0].Fields["group"])
+	assert.Equal(t, int64(2), hits[1].ID)
+
+	assert.ErrorIs(t, store.DeleteByFilter(ctx, "docs", nil), ErrEmptyFilter)
+	require.NoError(t, store.DeleteByFilter(ctx, "docs", map[string]interface{}{"group": 1}))
+	require.NoError(t, store.Delete(ctx, "docs", []int64{3}))
+	hits, err = store.Search(ctx, "docs", &SearchRequest{Vector: []float32{1, 0}, TopK: 5})
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	require.NoError(t, store.DropCollection(ctx, "docs"))
+	_, err = store.Search(ctx, "docs", &SearchRequest{Vector: []float32{1, 0}})
+	assert.Error(t, err)
+}
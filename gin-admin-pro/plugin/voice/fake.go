@@ -0,0 +1,76 @@
+package voice
+
+import (
+	"context"
+	"sync"
+)
+
+// fakeOpusTOC 假音频帧的 TOC 字节：CELT 2.5ms 单帧，使测试中的播放节奏几乎不等待
+const fakeOpusTOC = 16 << 3
+
+// FakeASR 测试用语音识别，返回固定文本并记录收到的音频
+type FakeASR struct {
+	Text string
+	Err  error
+
+	mu     sync.Mutex
+	audios []*Audio
+}
+
+// Recognize 返回 Text
+func (a *FakeASR) Recognize(ctx context.Context, audio *Audio) (string, error) {
+	a.mu.Lock()
+	a.audios = append(a.audios, audio)
+	a.mu.Unlock()
+	return a.Text, a.Err
+}
+
+// Audios 已识别的音频
+func (a *FakeASR) Audios() []*Audio {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]*Audio(nil), a.audios...)
+}
+
+// FakeTTS 测试用语音合成，每个字符生成一帧，帧内容为 TOC 字节加字符本身
+type FakeTTS struct {
+	Err error
+
+	mu    sync.Mutex
+	texts []string
+}
+
+// Synthesize 按字符生成假音频帧
+func (t *FakeTTS) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	t.mu.Lock()
+	t.texts = append(t.texts, text)
+	t.mu.Unlock()
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	audio := &Audio{Params: t.OutputParams()}
+	for _, r := range text {
+		audio.Frames = append(audio.Frames, append([]byte{fakeOpusTOC}, string(r)...))
+	}
+	return audio, nil
+}
+
+// OutputParams 合成音频的参数
+func (t *FakeTTS) OutputParams() AudioParams {
+	return AudioParams{Format: "opus", SampleRate: 24000, Channels: 1, FrameDuration: 60}
+}
+
+// Texts 已合成的文本
+func (t *FakeTTS) Texts() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.texts...)
+}
+
+// FakeVAD 测试用语音活动检测，首字节之后有内容的帧视为语音，只有 TOC 字节的帧视为静音
+type FakeVAD struct{}
+
+// IsSpeech 判断一帧音频是否包含语音
+func (FakeVAD) IsSpeech(params AudioParams, frame []byte) bool {
+	return len(frame) > 1
+}
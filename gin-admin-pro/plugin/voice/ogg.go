@@ -0,0 +1,188 @@
+package voice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// opusFrameSamples 各 TOC 配置下单帧的采样数（按 48kHz 计），见 RFC 6716 3.1 节
+var opusFrameSamples = [32]int{
+	480, 960, 1920, 2880, 480, 960, 1920, 2880, 480, 960, 1920, 2880, // SILK
+	480, 960, 480, 960, // Hybrid
+	120, 240, 480, 960, 120, 240, 480, 960, 120, 240, 480, 960, 120, 240, 480, 960, // CELT
+}
+
+// packetSamples 按 TOC 字节计算 Opus 包的采样数（按 48kHz 计），无法解析时返回 0
+func packetSamples(packet []byte) int {
+	if len(packet) == 0 {
+		return 0
+	}
+	count := 1
+	switch packet[0] & 0x03 {
+	case 1, 2:
+		count = 2
+	case 3:
+		if len(packet) < 2 {
+			return 0
+		}
+		count = int(packet[1] & 0x3f)
+	}
+	return count * opusFrameSamples[packet[0]>>3]
+}
+
+// frameDuration 计算 Opus 包时长，无法解析时使用 fallbackMs
+func frameDuration(packet []byte, fallbackMs int) time.Duration {
+	if samples := packetSamples(packet); samples > 0 {
+		return time.Duration(samples) * time.Second / 48000
+	}
+	return time.Duration(fallbackMs) * time.Millisecond
+}
+
+// oggCRCTable Ogg 页校验使用的 CRC32 表（多项式 0x04c11db7，不反转）
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+func oggCRC(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// Ogg 页头标志
+const (
+	oggBOS = 0x02
+	oggEOS = 0x04
+)
+
+// encodeOgg 将 Opus 包封装为 Ogg Opus 文件（RFC 7845），每页一个包，用于上传识别接口
+func encodeOgg(audio *Audio) []byte {
+	var buf bytes.Buffer
+	const serial = 0x766f6963
+	var sequence uint32
+	writePage := func(packet []byte, granule int64, flags byte) {
+		lacing := make([]byte, 0, len(packet)/255+1)
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		header := make([]byte, 27, 27+len(lacing))
+		copy(header, "OggS")
+		header[5] = flags
+		binary.LittleEndian.PutUint64(header[6:14], uint64(granule))
+		binary.LittleEndian.PutUint32(header[14:18], serial)
+		binary.LittleEndian.PutUint32(header[18:22], sequence)
+		header[26] = byte(len(lacing))
+		header = append(header, lacing...)
+		page := append(header, packet...)
+		binary.LittleEndian.PutUint32(page[22:26], oggCRC(page))
+		buf.Write(page)
+		sequence++
+	}
+
+	channels := max(audio.Params.Channels, 1)
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = byte(channels)
+	binary.LittleEndian.PutUint32(head[12:16], uint32(audio.Params.SampleRate))
+	writePage(head, 0, oggBOS)
+
+	vendor := "gin-admin-pro"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:12], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+	writePage(tags, 0, 0)
+
+	var granule int64
+	for i, frame := range audio.Frames {
+		samples := packetSamples(frame)
+		if samples == 0 {
+			samples = audio.Params.FrameDuration * 48
+		}
+		granule += int64(samples)
+		var flags byte
+		if i == len(audio.Frames)-1 {
+			flags = oggEOS
+		}
+		writePage(frame, granule, flags)
+	}
+	return buf.Bytes()
+}
+
+// decodeOgg 解出 Ogg Opus 文件中的 Opus 包，只支持单个逻辑流
+func decodeOgg(data []byte) (*Audio, error) {
+	var (
+		packets [][]byte
+		pending []byte
+	)
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < 27 || string(data[offset:offset+4]) != "OggS" {
+			return nil, errors.New("invalid ogg page")
+		}
+		segments := int(data[offset+26])
+		bodyStart := offset + 27 + segments
+		if bodyStart > len(data) {
+			return nil, errors.New("truncated ogg page header")
+		}
+		lacing := data[offset+27 : bodyStart]
+		position := bodyStart
+		for _, size := range lacing {
+			end := position + int(size)
+			if end > len(data) {
+				return nil, errors.New("truncated ogg page body")
+			}
+			pending = append(pending, data[position:end]...)
+			position = end
+			if size < 255 {
+				packets = append(packets, pending)
+				pending = nil
+			}
+		}
+		offset = position
+	}
+
+	if len(packets) < 2 || !bytes.HasPrefix(packets[0], []byte("OpusHead")) || len(packets[0]) < 19 {
+		return nil, errors.New("not an ogg opus stream")
+	}
+	head := packets[0]
+	audio := &Audio{
+		Params: AudioParams{
+			Format:     "opus",
+			Channels:   int(head[9]),
+			SampleRate: int(binary.LittleEndian.Uint32(head[12:16])),
+		},
+		Frames: packets[2:],
+	}
+	if audio.Params.SampleRate == 0 {
+		audio.Params.SampleRate = 48000
+	}
+	if len(audio.Frames) > 0 {
+		duration := frameDuration(audio.Frames[0], 0)
+		if duration == 0 {
+			return nil, errors.New("invalid opus packet in ogg stream")
+		}
+		audio.Params.FrameDuration = max(int(duration/time.Millisecond), 1)
+	}
+	return audio, nil
+}
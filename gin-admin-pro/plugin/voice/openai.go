@@ -0,0 +1,197 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin-admin-pro/plugin/ai"
+)
+
+// OpenAIASRConfig OpenAI 兼容语音识别接口（/audio/transcriptions）配置
+type OpenAIASRConfig struct {
+	BaseURL  string        `yaml:"baseUrl" mapstructure:"baseUrl"`
+	APIKey   string        `yaml:"apiKey" mapstructure:"apiKey"`
+	Model    string        `yaml:"model" mapstructure:"model"`
+	Language string        `yaml:"language" mapstructure:"language"` // ISO-639-1 语言代码，为空时自动识别
+	Timeout  time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// OpenAIASR OpenAI 兼容语音识别，设备上传的 Opus 包封装为 Ogg 文件后提交
+type OpenAIASR struct {
+	client *http.Client
+	config OpenAIASRConfig
+}
+
+// NewOpenAIASR 创建 OpenAI 兼容语音识别实例，未配置的项使用 OpenAI whisper-1
+func NewOpenAIASR(config OpenAIASRConfig) *OpenAIASR {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.openai.com/v1"
+	}
+	if config.Model == "" {
+		config.Model = "whisper-1"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &OpenAIASR{client: &http.Client{Timeout: config.Timeout}, config: config}
+}
+
+// Recognize 识别一句话的音频
+func (a *OpenAIASR) Recognize(ctx context.Context, audio *Audio) (string, error) {
+	if len(audio.Frames) == 0 {
+		return "", nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fields := map[string]string{"model": a.config.Model, "response_format": "json"}
+	if a.config.Language != "" {
+		fields["language"] = a.config.Language
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("write form failed: %w", err)
+		}
+	}
+	part, err := writer.CreateFormFile("file", "audio.ogg")
+	if err != nil {
+		return "", fmt.Errorf("write form failed: %w", err)
+	}
+	if _, err := part.Write(encodeOgg(audio)); err != nil {
+		return "", fmt.Errorf("write form failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("write form failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(a.config.BaseURL, "/")+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	respBody, err := doRequest(a.client, req, a.config.APIKey)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// OpenAITTSConfig OpenAI 兼容语音合成接口（/audio/speech）配置
+type OpenAITTSConfig struct {
+	BaseURL string        `yaml:"baseUrl" mapstructure:"baseUrl"`
+	APIKey  string        `yaml:"apiKey" mapstructure:"apiKey"`
+	Model   string        `yaml:"model" mapstructure:"model"`
+	Voice   string        `yaml:"voice" mapstructure:"voice"`
+	Speed   float64       `yaml:"speed" mapstructure:"speed"` // 0.25-4，0 表示使用接口默认值
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// OpenAITTS OpenAI 兼容语音合成，以 opus 格式请求并从 Ogg 文件中取出 Opus 包
+type OpenAITTS struct {
+	client *http.Client
+	config OpenAITTSConfig
+}
+
+// NewOpenAITTS 创建 OpenAI 兼容语音合成实例，未配置的项使用 OpenAI tts-1 和 alloy 音色
+func NewOpenAITTS(config OpenAITTSConfig) *OpenAITTS {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.openai.com/v1"
+	}
+	if config.Model == "" {
+		config.Model = "tts-1"
+	}
+	if config.Voice == "" {
+		config.Voice = "alloy"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &OpenAITTS{client: &http.Client{Timeout: config.Timeout}, config: config}
+}
+
+// OutputParams 合成音频的参数，OpenAI 的 Opus 输出为 24kHz 单声道
+func (t *OpenAITTS) OutputParams() AudioParams {
+	return AudioParams{Format: "opus", SampleRate: 24000, Channels: 1, FrameDuration: 60}
+}
+
+// Synthesize 合成一句文本
+func (t *OpenAITTS) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	payload := map[string]interface{}{
+		"model":           t.config.Model,
+		"voice":           t.config.Voice,
+		"input":           text,
+		"response_format": "opus",
+	}
+	if t.config.Speed > 0 {
+		payload["speed"] = t.config.Speed
+	}
+	reqBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(t.config.BaseURL, "/")+"/audio/speech", bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	respBody, err := doRequest(t.client, req, t.config.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	audio, err := decodeOgg(respBody)
+	if err != nil {
+		return nil, fmt.Errorf("decode speech failed: %w", err)
+	}
+	return audio, nil
+}
+
+// doRequest 发送请求并读取响应，非 200 响应转换为 ai.APIError
+func doRequest(client *http.Client, req *http.Request, apiKey string) ([]byte, error) {
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &ai.APIError{
+			Code:       fmt.Sprintf("HTTP_%d", resp.StatusCode),
+			Message:    string(body),
+			StatusCode: resp.StatusCode,
+			Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+		var result struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &result) == nil && result.Error != nil && result.Error.Message != "" {
+			apiErr.Message = result.Error.Message
+		}
+		return nil, apiErr
+	}
+	return body, nil
+}
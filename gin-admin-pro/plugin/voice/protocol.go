@@ -0,0 +1,118 @@
+package voice
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 消息类型
+const (
+	TypeHello  = "hello"
+	TypeListen = "listen"
+	TypeAbort  = "abort"
+	TypeSTT    = "stt"
+	TypeLLM    = "llm"
+	TypeTTS    = "tts"
+)
+
+// listen 消息的状态
+const (
+	ListenStart  = "start"
+	ListenStop   = "stop"
+	ListenDetect = "detect" // 设备检测到唤醒词
+)
+
+// 监听模式
+const (
+	ModeAuto     = "auto"     // 设备持续上传音频，由服务端 VAD 判断说完
+	ModeManual   = "manual"   // 按键说话，设备发送 listen stop 表示说完
+	ModeRealtime = "realtime" // 与自动模式相同，且播放回复时设备仍在上传音频
+)
+
+// tts 消息的状态
+const (
+	TTSStart         = "start"
+	TTSSentenceStart = "sentence_start"
+	TTSSentenceEnd   = "sentence_end"
+	TTSStop          = "stop"
+)
+
+// Message 文本消息，设备和服务端共用，按 Type 使用不同字段
+type Message struct {
+	Type        string       `json:"type"`
+	SessionID   string       `json:"session_id,omitempty"`
+	Version     int          `json:"version,omitempty"`
+	Transport   string       `json:"transport,omitempty"`
+	AudioParams *AudioParams `json:"audio_params,omitempty"`
+	State       string       `json:"state,omitempty"`
+	Mode        string       `json:"mode,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	Emotion     string       `json:"emotion,omitempty"`
+	Reason      string       `json:"reason,omitempty"`
+}
+
+// 二进制帧的负载类型（协议版本 2、3）
+const (
+	payloadOpus = 0
+	payloadJSON = 1
+)
+
+// errUnsupportedPayload 二进制帧不是 Opus 音频
+var errUnsupportedPayload = errors.New("unsupported binary payload")
+
+// decodeAudio 按协议版本解出二进制帧中的 Opus 包。
+// 版本 1 为裸 Opus 包；版本 2 帧头为 version(2) type(2) reserved(4) timestamp(4) size(4)；
+// 版本 3 帧头为 type(1) reserved(1) size(2)，均为大端序
+func decodeAudio(version int, data []byte) ([]byte, error) {
+	switch version {
+	case 2:
+		if len(data) < 16 {
+			return nil, fmt.Errorf("binary frame too short: %d", len(data))
+		}
+		if binary.BigEndian.Uint16(data[2:4]) != payloadOpus {
+			return nil, errUnsupportedPayload
+		}
+		size := int(binary.BigEndian.Uint32(data[12:16]))
+		if size > len(data)-16 {
+			return nil, fmt.Errorf("binary payload size %d exceeds frame", size)
+		}
+		return data[16 : 16+size], nil
+	case 3:
+		if len(data) < 4 {
+			return nil, fmt.Errorf("binary frame too short: %d", len(data))
+		}
+		if data[0] != payloadOpus {
+			return nil, errUnsupportedPayload
+		}
+		size := int(binary.BigEndian.Uint16(data[2:4]))
+		if size > len(data)-4 {
+			return nil, fmt.Errorf("binary payload size %d exceeds frame", size)
+		}
+		return data[4 : 4+size], nil
+	default:
+		return data, nil
+	}
+}
+
+// encodeAudio 按协议版本封装下发的 Opus 包，timestamp 为毫秒
+func encodeAudio(version int, packet []byte, timestamp uint32) []byte {
+	switch version {
+	case 2:
+		frame := make([]byte, 16+len(packet))
+		binary.BigEndian.PutUint16(frame[0:2], 2)
+		binary.BigEndian.PutUint16(frame[2:4], payloadOpus)
+		binary.BigEndian.PutUint32(frame[8:12], timestamp)
+		binary.BigEndian.PutUint32(frame[12:16], uint32(len(packet)))
+		copy(frame[16:], packet)
+		return frame
+	case 3:
+		frame := make([]byte, 4+len(packet))
+		frame[0] = payloadOpus
+		binary.BigEndian.PutUint16(frame[2:4], uint16(len(packet)))
+		copy(frame[4:], packet)
+		return frame
+	default:
+		return packet
+	}
+}
@@ -0,0 +1,117 @@
+package voice
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gin-admin-pro/plugin/ai"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrUnauthorized 设备令牌无效
+var ErrUnauthorized = errors.New("invalid device token")
+
+// Authenticator 设备认证，返回语音对话所属的用户ID
+type Authenticator func(r *http.Request) (string, error)
+
+// Server 小智设备 WebSocket 语音网关：接收设备上传的 Opus 音频，经 ASR 识别后调用 AI 对话，
+// 回复经 TTS 合成后以 Opus 音频下发
+type Server struct {
+	aiService    ai.AIService
+	asr          ASR
+	tts          TTS
+	vad          VAD
+	config       *Config
+	authenticate Authenticator
+	upgrader     websocket.Upgrader
+}
+
+// NewServer 创建语音网关，vad 为 nil 时自动模式只能在录音超过最长时长后识别，设备应使用手动模式
+func NewServer(aiService ai.AIService, asr ASR, tts TTS, vad VAD, config *Config) *Server {
+	s := &Server{
+		aiService: aiService,
+		asr:       asr,
+		tts:       tts,
+		vad:       vad,
+		config:    config.withDefaults(),
+		upgrader: websocket.Upgrader{
+			// 设备不发送 Origin，连接通过令牌认证
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+	s.authenticate = s.tokenAuthenticator
+	return s
+}
+
+// SetAuthenticator 设置设备认证，替换默认的令牌认证
+func (s *Server) SetAuthenticator(authenticate Authenticator) {
+	s.authenticate = authenticate
+}
+
+// tokenAuthenticator 按配置的令牌认证设备，对话属于 Config.UserID；未配置令牌时拒绝所有连接
+func (s *Server) tokenAuthenticator(r *http.Request) (string, error) {
+	if len(s.config.Tokens) == 0 {
+		return "", ErrUnauthorized
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" {
+		// 浏览器 WebSocket 无法设置请求头，允许通过查询参数携带
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return "", ErrUnauthorized
+	}
+	for _, allowed := range s.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			return s.config.UserID, nil
+		}
+	}
+	return "", ErrUnauthorized
+}
+
+// ServeHTTP 认证设备并升级为 WebSocket 连接，连接关闭前不返回
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade 失败时已写入错误响应
+		return
+	}
+
+	version, _ := strconv.Atoi(r.Header.Get("Protocol-Version"))
+	sess := &session{
+		server:   s,
+		conn:     conn,
+		id:       newSessionID(),
+		userID:   userID,
+		deviceID: r.Header.Get("Device-Id"),
+		version:  version,
+		input:    AudioParams{Format: "opus", SampleRate: 16000, Channels: 1, FrameDuration: 60},
+		endpoint: endpointDetector{silence: s.config.SilenceTimeout},
+	}
+	if sess.deviceID == "" {
+		sess.deviceID = r.URL.Query().Get("device-id")
+	}
+	if err := sess.run(r.Context()); err != nil {
+		log.Printf("voice session %s (device %s) closed: %v", sess.id, sess.deviceID, err)
+	}
+}
+
+// newSessionID 生成会话ID
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,311 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-admin-pro/plugin/ai"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// silentFrame 60ms 的 SILK 包（只有 TOC 字节），FakeVAD 视为静音
+var silentFrame = []byte{3 << 3}
+
+// speechFrame 60ms 的 SILK 包，FakeVAD 视为语音
+var speechFrame = []byte{3 << 3, 'a', 'b'}
+
+type testGateway struct {
+	aiService *ai.DefaultAIService
+	asr       *FakeASR
+	tts       *FakeTTS
+	url       string
+}
+
+// newTestGateway 创建使用假 ASR/TTS/VAD 的语音网关，AI 对话请求由 handler 处理；未配置令牌时使用 device-token
+func newTestGateway(t *testing.T, config *Config, handler http.HandlerFunc) *testGateway {
+	if config == nil {
+		config = &Config{}
+	}
+	if len(config.Tokens) == 0 {
+		config.Tokens = []string{"device-token"}
+	}
+
+	llm := httptest.NewServer(handler)
+	t.Cleanup(llm.Close)
+
+	cfg := ai.DefaultConfig()
+	cfg.APIKey = "sk-test"
+	cfg.BaseURL = llm.URL
+	aiService, err := ai.NewDefaultAIService(cfg)
+	require.NoError(t, err)
+	require.NoError(t, aiService.Initialize(cfg))
+
+	gateway := &testGateway{aiService: aiService, asr: &FakeASR{Text: "今天天气怎么样"}, tts: &FakeTTS{}}
+	server := httptest.NewServer(NewServer(aiService, gateway.asr, gateway.tts, FakeVAD{}, config))
+	t.Cleanup(server.Close)
+	gateway.url = "ws" + strings.TrimPrefix(server.URL, "http") + "/xiaozhi/v1/"
+	return gateway
+}
+
+// dial 以设备身份连接并完成握手
+func (g *testGateway) dial(t *testing.T, version int) (*websocket.Conn, *Message) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer device-token")
+	header.Set("Device-Id", "aa:bb:cc:dd:ee:ff")
+	header.Set("Protocol-Version", fmt.Sprint(version))
+	conn, _, err := websocket.DefaultDialer.Dial(g.url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	sendJSON(t, conn, map[string]interface{}{
+		"type":         "hello",
+		"version":      version,
+		"transport":    "websocket",
+		"audio_params": map[string]interface{}{"format": "opus", "sample_rate": 16000, "channels": 1, "frame_duration": 60},
+	})
+	events := readUntil(t, conn, version, TypeHello)
+	return conn, events[0].msg
+}
+
+func sendJSON(t *testing.T, conn *websocket.Conn, msg map[string]interface{}) {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, data))
+}
+
+func sendAudio(t *testing.T, conn *websocket.Conn, version int, frames ...[]byte) {
+	for _, frame := range frames {
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, encodeAudio(version, frame, 0)))
+	}
+}
+
+// event 设备收到的一条消息
+type event struct {
+	msg   *Message
+	audio []byte
+}
+
+// readUntil 读取消息直到收到指定类型的消息（tts 类型时为 tts stop）
+func readUntil(t *testing.T, conn *websocket.Conn, version int, until string) []event {
+	var events []event
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		messageType, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		if messageType == websocket.BinaryMessage {
+			packet, err := decodeAudio(version, data)
+			require.NoError(t, err)
+			events = append(events, event{audio: packet})
+			continue
+		}
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		events = append(events, event{msg: &msg})
+		if msg.Type == until && (until != TypeTTS || msg.State == TTSStop) {
+			return events
+		}
+	}
+}
+
+// describe 将消息序列转换为便于断言的文本
+func describe(events []event) []string {
+	var lines []string
+	for _, e := range events {
+		switch {
+		case e.audio != nil:
+			lines = append(lines, "audio:"+string(e.audio[1:]))
+		case e.msg.Type == TypeTTS:
+			lines = append(lines, strings.TrimSuffix("tts:"+e.msg.State+":"+e.msg.Text, ":"))
+		default:
+			lines = append(lines, e.msg.Type+":"+e.msg.Text)
+		}
+	}
+	return lines
+}
+
+func TestGatewayManualListen(t *testing.T) {
+	var received []map[string]interface{}
+	gateway := newTestGateway(t, &Config{Tokens: []string{"device-token"}, UserID: "1"}, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = body.Messages
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"**晴天**。适合出门😀！"}}]}`)
+	})
+
+	conn, hello := gateway.dial(t, 1)
+	assert.NotEmpty(t, hello.SessionID)
+	assert.Equal(t, "websocket", hello.Transport)
+	assert.Equal(t, 24000, hello.AudioParams.SampleRate)
+
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "start", "mode": "manual"})
+	sendAudio(t, conn, 1, speechFrame, silentFrame, speechFrame)
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "stop"})
+
+	events := readUntil(t, conn, 1, TypeTTS)
+	assert.Equal(t, []string{
+		"stt:今天天气怎么样",
+		"tts:start",
+		"tts:sentence_start:晴天。",
+		"audio:晴", "audio:天", "audio:。",
+		"tts:sentence_end:晴天。",
+		"tts:sentence_start:适合出门！",
+		"audio:适", "audio:合", "audio:出", "audio:门", "audio:！",
+		"tts:sentence_end:适合出门！",
+		"tts:stop",
+	}, describe(events))
+	for _, e := range events {
+		if e.msg != nil {
+			assert.Equal(t, hello.SessionID, e.msg.SessionID)
+		}
+	}
+
+	// 手动模式不做 VAD，说完前的静音帧一并识别
+	audios := gateway.asr.Audios()
+	require.Len(t, audios, 1)
+	assert.Len(t, audios[0].Frames, 3)
+	assert.Equal(t, 16000, audios[0].Params.SampleRate)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "system", received[0]["role"])
+	assert.Contains(t, received[0]["content"], "语音助手")
+	assert.Equal(t, "今天天气怎么样", received[1]["content"])
+
+	// 第二轮携带上一轮的上下文，对话记录保存在同一个对话中
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "start", "mode": "manual"})
+	sendAudio(t, conn, 1, speechFrame)
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "stop"})
+	readUntil(t, conn, 1, TypeTTS)
+	require.Len(t, received, 4)
+	assert.Equal(t, "**晴天**。适合出门😀！", received[2]["content"])
+
+	conversations, err := gateway.aiService.ListConversations(context.Background(), "1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, conversations, 1)
+	messages, err := gateway.aiService.GetMessages(context.Background(), conversations[0].ID, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, messages, 4)
+}
+
+func TestGatewayAutoListen(t *testing.T) {
+	gateway := newTestGateway(t, &Config{SilenceTimeout: 300 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"好的"}}]}`)
+	})
+
+	// 协议版本 3 的音频帧带 4 字节帧头
+	conn, _ := gateway.dial(t, 3)
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "start", "mode": "auto"})
+	// 说话前的静音不触发识别，说话后静音 300ms 视为说完
+	sendAudio(t, conn, 3, silentFrame, silentFrame, speechFrame, speechFrame, silentFrame, silentFrame, silentFrame, silentFrame, silentFrame)
+
+	events := readUntil(t, conn, 3, TypeTTS)
+	assert.Equal(t, []string{
+		"stt:今天天气怎么样",
+		"tts:start",
+		"tts:sentence_start:好的",
+		"audio:好", "audio:的",
+		"tts:sentence_end:好的",
+		"tts:stop",
+	}, describe(events))
+	audios := gateway.asr.Audios()
+	require.Len(t, audios, 1)
+	assert.Len(t, audios[0].Frames, 9)
+	assert.Equal(t, []string{"好的"}, gateway.tts.Texts())
+
+	// 未配置用户时不保存对话
+	conversations, err := gateway.aiService.ListConversations(context.Background(), "", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, conversations)
+}
+
+func TestGatewayAbort(t *testing.T) {
+	gateway := newTestGateway(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		// 读完请求体后才能感知客户端断开
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	})
+
+	conn, _ := gateway.dial(t, 1)
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "start", "mode": "manual"})
+	sendAudio(t, conn, 1, speechFrame)
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "stop"})
+	readUntil(t, conn, 1, TypeSTT)
+
+	sendJSON(t, conn, map[string]interface{}{"type": "abort", "reason": "wake_word_detected"})
+	events := readUntil(t, conn, 1, TypeTTS)
+	assert.Equal(t, []string{"tts:start", "tts:stop"}, describe(events))
+	assert.Empty(t, gateway.tts.Texts())
+}
+
+func TestGatewayHandshake(t *testing.T) {
+	gateway := newTestGateway(t, &Config{Tokens: []string{"device-token"}}, func(w http.ResponseWriter, r *http.Request) {})
+
+	// 令牌错误
+	header := http.Header{}
+	header.Set("Authorization", "Bearer wrong")
+	_, resp, err := websocket.DefaultDialer.Dial(gateway.url, header)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 未配置令牌时拒绝所有连接
+	server := httptest.NewServer(NewServer(gateway.aiService, gateway.asr, gateway.tts, FakeVAD{}, &Config{UserID: "1"}))
+	defer server.Close()
+	_, resp, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/xiaozhi/v1/", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 握手前发送其他消息时断开连接
+	header.Set("Authorization", "Bearer device-token")
+	conn, _, err := websocket.DefaultDialer.Dial(gateway.url, header)
+	require.NoError(t, err)
+	defer conn.Close()
+	sendJSON(t, conn, map[string]interface{}{"type": "listen", "state": "start"})
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = conn.ReadMessage()
+	assert.Error(t, err)
+}
+
+func TestOggRoundTrip(t *testing.T) {
+	audio := &Audio{
+		Params: AudioParams{Format: "opus", SampleRate: 16000, Channels: 1, FrameDuration: 60},
+		Frames: [][]byte{speechFrame, append([]byte{3 << 3}, make([]byte, 600)...), silentFrame},
+	}
+	decoded, err := decodeOgg(encodeOgg(audio))
+	require.NoError(t, err)
+	assert.Equal(t, audio.Frames, decoded.Frames)
+	assert.Equal(t, audio.Params, decoded.Params)
+	assert.Equal(t, 180*time.Millisecond, decoded.Duration())
+
+	_, err = decodeOgg([]byte("not ogg"))
+	assert.Error(t, err)
+}
+
+func TestPacketSamples(t *testing.T) {
+	assert.Equal(t, 2880, packetSamples([]byte{3 << 3}))         // SILK 60ms
+	assert.Equal(t, 1920, packetSamples([]byte{31<<3 | 1}))      // CELT 20ms x2
+	assert.Equal(t, 360, packetSamples([]byte{16<<3 | 3, 3}))    // CELT 2.5ms x3
+	assert.Equal(t, 0, packetSamples([]byte{16<<3 | 3}))         // 缺少帧数
+	assert.Equal(t, 20*time.Millisecond, frameDuration(nil, 20)) // 无法解析时使用默认时长
+	assert.True(t, NewPacketSizeVAD(0).IsSpeech(AudioParams{}, make([]byte, 50)))
+	assert.False(t, NewPacketSizeVAD(0).IsSpeech(AudioParams{}, []byte{3 << 3, 1, 2}))
+}
+
+func TestSplitSentences(t *testing.T) {
+	assert.Equal(t, []string{"第一句。", "第二句！", "Pi is 3.14.", "Done"},
+		splitSentences("第一句。第二句！\n\nPi is 3.14. Done"))
+	assert.Equal(t, []string{"好"}, splitSentences("。。好"))
+	assert.Equal(t, "重点：晴天", cleanSpeech("## 重点：**晴天**😀"))
+}
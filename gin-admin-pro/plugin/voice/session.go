@@ -0,0 +1,437 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gin-admin-pro/plugin/ai"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxMessageSize 设备单条消息的最大字节数
+	maxMessageSize = 64 * 1024
+	// writeTimeout 单条消息的写超时
+	writeTimeout = 10 * time.Second
+	// prebuffer 下发音频时领先播放进度的时长，用于抵消网络抖动
+	prebuffer = 200 * time.Millisecond
+	// fallbackReply 对话失败时朗读的回复
+	fallbackReply = "抱歉，我刚才没有处理好，请再说一次。"
+)
+
+// session 一个设备连接。读消息、录音和轮次管理都在 run 所在的 goroutine 中进行，
+// 每轮回复在独立的 goroutine 中执行，新一轮开始或设备打断时取消上一轮
+type session struct {
+	server   *Server
+	conn     *websocket.Conn
+	id       string
+	userID   string
+	deviceID string
+	version  int // 二进制帧协议版本
+	writeMu  sync.Mutex
+
+	greeted   bool
+	input     AudioParams // 设备上传的音频参数
+	mode      string
+	listening bool
+	frames    [][]byte
+	endpoint  endpointDetector
+
+	cancel context.CancelFunc // 当前轮次
+	done   chan struct{}
+
+	// 以下字段只在轮次 goroutine 中访问，轮次依次执行
+	history        []ai.Message
+	conversationID string
+}
+
+// run 处理设备消息直到连接关闭
+func (s *session) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.stopTurn()
+		s.conn.Close()
+	}()
+
+	s.conn.SetReadLimit(maxMessageSize)
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.server.config.HelloTimeout)); err != nil {
+		return err
+	}
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if s.greeted {
+			if err := s.conn.SetReadDeadline(time.Now().Add(s.server.config.IdleTimeout)); err != nil {
+				return err
+			}
+		}
+
+		switch messageType {
+		case websocket.TextMessage:
+			if err := s.handleText(ctx, data); err != nil {
+				return err
+			}
+		case websocket.BinaryMessage:
+			s.handleAudio(ctx, data)
+		}
+	}
+}
+
+// handleText 处理文本消息，握手前收到其他消息时返回错误断开连接
+func (s *session) handleText(ctx context.Context, data []byte) error {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("voice session %s: invalid message: %v", s.id, err)
+		return nil
+	}
+	if !s.greeted && msg.Type != TypeHello {
+		return fmt.Errorf("expect hello, got %q", msg.Type)
+	}
+
+	switch msg.Type {
+	case TypeHello:
+		return s.hello(&msg)
+	case TypeListen:
+		switch msg.State {
+		case ListenStart:
+			s.startListening(msg.Mode)
+		case ListenStop:
+			if s.listening {
+				s.listening = false
+				s.finishUtterance(ctx)
+			}
+		case ListenDetect:
+			log.Printf("voice session %s: wake word detected: %s", s.id, msg.Text)
+		}
+	case TypeAbort:
+		s.stopTurn()
+	}
+	return nil
+}
+
+// hello 记录设备音频参数并回复握手
+func (s *session) hello(msg *Message) error {
+	if params := msg.AudioParams; params != nil {
+		if params.Format != "" && params.Format != "opus" {
+			return fmt.Errorf("unsupported audio format: %s", params.Format)
+		}
+		if params.SampleRate > 0 {
+			s.input.SampleRate = params.SampleRate
+		}
+		if params.Channels > 0 {
+			s.input.Channels = params.Channels
+		}
+		if params.FrameDuration > 0 {
+			s.input.FrameDuration = params.FrameDuration
+		}
+	}
+	if s.version == 0 {
+		s.version = msg.Version
+	}
+	s.greeted = true
+
+	output := s.server.tts.OutputParams()
+	return s.send(&Message{Type: TypeHello, Transport: "websocket", AudioParams: &output})
+}
+
+// startListening 开始录音，自动和实时模式由 VAD 判断说完
+func (s *session) startListening(mode string) {
+	if mode == "" {
+		mode = ModeAuto
+	}
+	s.mode = mode
+	s.listening = true
+	s.frames = nil
+	s.endpoint.reset()
+	s.endpoint.vad = nil
+	if mode != ModeManual {
+		s.endpoint.vad = s.server.vad
+	}
+}
+
+// handleAudio 录音中收到的音频帧
+func (s *session) handleAudio(ctx context.Context, data []byte) {
+	if !s.greeted || !s.listening {
+		return
+	}
+	packet, err := decodeAudio(s.version, data)
+	if err != nil {
+		if !errors.Is(err, errUnsupportedPayload) {
+			log.Printf("voice session %s: %v", s.id, err)
+		}
+		return
+	}
+	if len(packet) == 0 {
+		return
+	}
+
+	s.frames = append(s.frames, packet)
+	ended := s.endpoint.feed(s.input, packet)
+	if ended || s.endpoint.stored >= s.server.config.MaxListenDuration {
+		// 实时模式下设备播放回复时仍在录音
+		if s.mode != ModeRealtime {
+			s.listening = false
+		}
+		s.finishUtterance(ctx)
+	}
+}
+
+// finishUtterance 一句话说完，开始新一轮回复
+func (s *session) finishUtterance(ctx context.Context) {
+	frames := s.frames
+	s.frames = nil
+	s.endpoint.reset()
+	if len(frames) == 0 {
+		return
+	}
+
+	s.stopTurn()
+	turnCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.cancel, s.done = cancel, done
+	audio := &Audio{Params: s.input, Frames: frames}
+	go func() {
+		defer close(done)
+		defer cancel()
+		s.respond(turnCtx, audio)
+	}()
+}
+
+// stopTurn 取消当前轮次并等待其结束
+func (s *session) stopTurn() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.cancel, s.done = nil, nil
+}
+
+// respond 识别语音、调用 AI 对话并逐句合成下发，结束或被打断时通知设备停止播放
+func (s *session) respond(ctx context.Context, audio *Audio) {
+	text, err := s.server.asr.Recognize(ctx, audio)
+	if err != nil || text == "" {
+		if err != nil && ctx.Err() == nil {
+			log.Printf("voice session %s: recognize failed: %v", s.id, err)
+		}
+		s.sendTTS(TTSStop, "")
+		return
+	}
+	if err := s.send(&Message{Type: TypeSTT, Text: text}); err != nil {
+		return
+	}
+	s.sendTTS(TTSStart, "")
+	defer s.sendTTS(TTSStop, "")
+
+	reply, err := s.chat(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("voice session %s: chat failed: %v", s.id, err)
+		reply = fallbackReply
+	}
+
+	var player playback
+	for _, sentence := range splitSentences(cleanSpeech(reply)) {
+		s.sendTTS(TTSSentenceStart, sentence)
+		speech, err := s.server.tts.Synthesize(ctx, sentence)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("voice session %s: synthesize failed: %v", s.id, err)
+			}
+			return
+		}
+		if err := s.play(ctx, &player, speech); err != nil {
+			return
+		}
+		s.sendTTS(TTSSentenceEnd, sentence)
+	}
+	// 等设备播放完再通知停止，避免自动模式下设备录到自己的声音
+	_ = player.wait(ctx, 0)
+}
+
+// chat 调用 AI 对话，成功后记入上下文并保存对话记录
+func (s *session) chat(ctx context.Context, text string) (string, error) {
+	userMessage := ai.Message{Role: "user", Content: text, Timestamp: time.Now()}
+	messages := append(append([]ai.Message(nil), s.history...), userMessage)
+	resp, err := s.server.aiService.Chat(ctx, &ai.ChatRequest{
+		ConversationID: s.conversationID,
+		Messages:       messages,
+		Model:          s.server.config.Model,
+		SystemPrompt:   s.server.config.SystemPrompt,
+		UserID:         s.userID,
+		SessionID:      s.id,
+		Metadata:       map[string]interface{}{"source": "voice", "deviceId": s.deviceID},
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	reply := resp.Message.Content
+	assistantMessage := ai.Message{Role: "assistant", Content: reply, Timestamp: time.Now(), TokenUsed: resp.Usage.CompletionTokens}
+	s.history = append(messages, assistantMessage)
+	if len(s.history) > s.server.config.MaxHistory {
+		s.history = s.history[len(s.history)-s.server.config.MaxHistory:]
+	}
+	s.saveMessages(ctx, &userMessage, &assistantMessage)
+	return reply, nil
+}
+
+// saveMessages 将一问一答保存到对话记录，未配置用户时不保存
+func (s *session) saveMessages(ctx context.Context, messages ...*ai.Message) {
+	if s.userID == "" {
+		return
+	}
+	if s.conversationID == "" {
+		conv, err := s.server.aiService.CreateConversation(ctx, s.userID, map[string]interface{}{
+			"title":    "语音对话",
+			"source":   "voice",
+			"deviceId": s.deviceID,
+		})
+		if err != nil {
+			log.Printf("voice session %s: create conversation failed: %v", s.id, err)
+			return
+		}
+		s.conversationID = conv.ID
+	}
+	for _, message := range messages {
+		if err := s.server.aiService.AddMessage(ctx, s.conversationID, message); err != nil {
+			log.Printf("voice session %s: save message failed: %v", s.id, err)
+			return
+		}
+	}
+}
+
+// playback 下发进度，按音频时长控制发送速度，避免设备缓冲溢出
+type playback struct {
+	start  time.Time
+	queued time.Duration
+}
+
+// wait 等待直到已下发的音频领先播放进度不超过 ahead
+func (p *playback) wait(ctx context.Context, ahead time.Duration) error {
+	delay := p.queued - time.Since(p.start) - ahead
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// play 按播放节奏下发一句话的音频
+func (s *session) play(ctx context.Context, player *playback, audio *Audio) error {
+	for _, frame := range audio.Frames {
+		// 设备已播完之前的音频（如合成较慢），重新计时
+		if elapsed := time.Since(player.start); player.start.IsZero() || elapsed > player.queued {
+			player.start, player.queued = time.Now(), 0
+		}
+		if err := player.wait(ctx, prebuffer); err != nil {
+			return err
+		}
+		timestamp := uint32(player.queued / time.Millisecond)
+		if err := s.write(websocket.BinaryMessage, encodeAudio(s.version, frame, timestamp)); err != nil {
+			return err
+		}
+		player.queued += frameDuration(frame, audio.Params.FrameDuration)
+	}
+	return nil
+}
+
+// sendTTS 下发播放状态
+func (s *session) sendTTS(state, text string) {
+	_ = s.send(&Message{Type: TypeTTS, State: state, Text: text})
+}
+
+// send 下发文本消息
+func (s *session) send(msg *Message) error {
+	msg.SessionID = s.id
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.write(websocket.TextMessage, data)
+}
+
+// write 写入一条消息，轮次 goroutine 和读 goroutine 可能同时写
+func (s *session) write(messageType int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(messageType, data)
+}
+
+// markdownReplacer 去除朗读时无意义的 Markdown 标记
+var markdownReplacer = strings.NewReplacer("**", "", "__", "", "`", "", "#", "", "> ", "", "- ", "", "* ", "")
+
+// cleanSpeech 去除回复中的 Markdown 标记和表情符号
+func cleanSpeech(text string) string {
+	text = markdownReplacer.Replace(text)
+	text = strings.Map(func(r rune) rune {
+		if r >= 0x1F000 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF || r == 0xFE0F {
+			return -1
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(text)
+}
+
+// splitSentences 按句末标点和换行切分文本，逐句合成以缩短首句延迟
+func splitSentences(text string) []string {
+	var (
+		sentences []string
+		current   []rune
+	)
+	flush := func() {
+		sentence := strings.TrimSpace(string(current))
+		current = current[:0]
+		// 只有标点的片段不朗读
+		if strings.IndexFunc(sentence, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			sentences = append(sentences, sentence)
+		}
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		current = append(current, r)
+		switch r {
+		case '。', '！', '？', '；', '!', '?', ';':
+			flush()
+		case '.':
+			// 英文句点后为空白或结尾时才断句，避免切开小数和缩写
+			if i == len(runes)-1 || unicode.IsSpace(runes[i+1]) {
+				flush()
+			}
+		}
+	}
+	flush()
+	return sentences
+}
@@ -0,0 +1,65 @@
+package voice
+
+import "time"
+
+// PacketSizeVAD 按 Opus 包大小判断是否为语音。
+// 设备以 VBR 编码时静音帧远小于语音帧，该方法无需解码，但受环境噪声和编码参数影响，
+// 对准确性有要求时应接入基于 PCM 的 VAD（如 Silero VAD 服务）
+type PacketSizeVAD struct {
+	// MinBytes 每 20ms 音频的最小语音字节数，按实际帧时长等比换算
+	MinBytes int
+}
+
+// NewPacketSizeVAD 创建按包大小判断的 VAD，minBytes 不大于 0 时使用 16
+func NewPacketSizeVAD(minBytes int) *PacketSizeVAD {
+	if minBytes <= 0 {
+		minBytes = 16
+	}
+	return &PacketSizeVAD{MinBytes: minBytes}
+}
+
+// IsSpeech 判断一帧音频是否包含语音
+func (v *PacketSizeVAD) IsSpeech(params AudioParams, frame []byte) bool {
+	duration := frameDuration(frame, params.FrameDuration)
+	if duration <= 0 {
+		return false
+	}
+	threshold := int(int64(v.MinBytes) * int64(duration) / int64(20*time.Millisecond))
+	return len(frame) >= max(threshold, 1)
+}
+
+// endpointDetector 按 VAD 结果判断一句话是否说完：检测到语音后静音超过 silence 视为说完
+type endpointDetector struct {
+	vad     VAD
+	silence time.Duration
+
+	heard  bool
+	quiet  time.Duration
+	stored time.Duration
+}
+
+// reset 开始新的一句话
+func (d *endpointDetector) reset() {
+	d.heard = false
+	d.quiet = 0
+	d.stored = 0
+}
+
+// feed 输入一帧音频，返回是否说完
+func (d *endpointDetector) feed(params AudioParams, frame []byte) bool {
+	duration := frameDuration(frame, params.FrameDuration)
+	d.stored += duration
+	if d.vad == nil {
+		return false
+	}
+	if d.vad.IsSpeech(params, frame) {
+		d.heard = true
+		d.quiet = 0
+		return false
+	}
+	if !d.heard {
+		return false
+	}
+	d.quiet += duration
+	return d.quiet >= d.silence
+}
@@ -0,0 +1,111 @@
+package voice
+
+import (
+	"context"
+	"time"
+)
+
+// AudioParams 音频参数，与小智协议 hello 消息中的 audio_params 一致
+type AudioParams struct {
+	Format        string `json:"format"` // 目前只支持 opus
+	SampleRate    int    `json:"sample_rate"`
+	Channels      int    `json:"channels"`
+	FrameDuration int    `json:"frame_duration"` // 每帧时长（毫秒）
+}
+
+// Audio 一段 Opus 音频，每个元素为一个 Opus 包
+type Audio struct {
+	Params AudioParams
+	Frames [][]byte
+}
+
+// Duration 音频时长，按 Opus 包头计算，无法解析的包按 Params.FrameDuration 计算
+func (a *Audio) Duration() time.Duration {
+	var total time.Duration
+	for _, frame := range a.Frames {
+		total += frameDuration(frame, a.Params.FrameDuration)
+	}
+	return total
+}
+
+// ASR 语音识别
+type ASR interface {
+	// Recognize 识别一句话的音频，未识别到内容时返回空字符串
+	Recognize(ctx context.Context, audio *Audio) (string, error)
+}
+
+// TTS 语音合成
+type TTS interface {
+	// Synthesize 合成一句文本，返回 Opus 音频
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+	// OutputParams 合成音频的参数，握手时告知设备
+	OutputParams() AudioParams
+}
+
+// VAD 语音活动检测，用于自动模式下判断用户说完
+type VAD interface {
+	// IsSpeech 判断一帧音频是否包含语音
+	IsSpeech(params AudioParams, frame []byte) bool
+}
+
+// Config 语音网关配置
+type Config struct {
+	// Tokens 设备访问令牌，设备以 Authorization: Bearer <token> 携带，为空时默认认证拒绝所有连接
+	Tokens []string `yaml:"tokens" mapstructure:"tokens"`
+	// UserID 语音对话所属用户，用于限流、配额、部门预算、用量统计和保存对话记录，为空时不保存对话
+	UserID string `yaml:"userId" mapstructure:"userId"`
+	// SystemPrompt 语音对话的系统提示词，回复会被朗读，应要求简短口语化
+	SystemPrompt string `yaml:"systemPrompt" mapstructure:"systemPrompt"`
+	// Model 对话模型，为空时使用 AI 服务的默认模型
+	Model string `yaml:"model" mapstructure:"model"`
+	// MaxHistory 每个连接保留的上下文消息数
+	MaxHistory int `yaml:"maxHistory" mapstructure:"maxHistory"`
+	// SilenceTimeout 自动模式下检测到语音后静音多久视为说完
+	SilenceTimeout time.Duration `yaml:"silenceTimeout" mapstructure:"silenceTimeout"`
+	// MaxListenDuration 单句最长录音时长，超过后立即识别
+	MaxListenDuration time.Duration `yaml:"maxListenDuration" mapstructure:"maxListenDuration"`
+	// HelloTimeout 连接后等待设备 hello 的时长
+	HelloTimeout time.Duration `yaml:"helloTimeout" mapstructure:"helloTimeout"`
+	// IdleTimeout 多久未收到设备消息后断开连接
+	IdleTimeout time.Duration `yaml:"idleTimeout" mapstructure:"idleTimeout"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		SystemPrompt:      "你是语音助手小智。回复会被朗读给用户，请用简短的口语回答，不使用 Markdown、列表、表情和代码。",
+		MaxHistory:        10,
+		SilenceTimeout:    800 * time.Millisecond,
+		MaxListenDuration: 60 * time.Second,
+		HelloTimeout:      10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
+// withDefaults 补全未配置的项
+func (c *Config) withDefaults() *Config {
+	defaults := DefaultConfig()
+	if c == nil {
+		return defaults
+	}
+	merged := *c
+	if merged.SystemPrompt == "" {
+		merged.SystemPrompt = defaults.SystemPrompt
+	}
+	if merged.MaxHistory <= 0 {
+		merged.MaxHistory = defaults.MaxHistory
+	}
+	if merged.SilenceTimeout <= 0 {
+		merged.SilenceTimeout = defaults.SilenceTimeout
+	}
+	if merged.MaxListenDuration <= 0 {
+		merged.MaxListenDuration = defaults.MaxListenDuration
+	}
+	if merged.HelloTimeout <= 0 {
+		merged.HelloTimeout = defaults.HelloTimeout
+	}
+	if merged.IdleTimeout <= 0 {
+		merged.IdleTimeout = defaults.IdleTimeout
+	}
+	return &merged
+}